@@ -24,6 +24,7 @@ func main() {
 	app.Register(NewQueueCommand)
 	app.Register(NewTempCommand)
 	app.Register(NewMigrateCommand)
+	app.Register(NewImportCommand)
 	app.Run()
 }
 
@@ -90,6 +91,32 @@ func NewMigrateCommand() core.Command {
 	}
 }
 
+func NewImportCommand() core.Command {
+	return core.Command{
+		Name:  "import",
+		Usage: "Import Command - 聊天记录导入",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "file",
+				Usage:    "存档文件（JSON 或聊天记录导出的 ZIP 压缩包）",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:  "senders",
+				Usage: "发送者映射文件，格式为 {\"原发送者ID\": 用户ID}",
+			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "仅校验并统计，不写入数据",
+			},
+		},
+		Action: func(ctx *cli.Context, c *config.Config) error {
+			logger.Init(c.Log.LogFilePath("app.log"), logger.LevelInfo, "import")
+			return mission.Import(ctx, NewImportInjector(c))
+		},
+	}
+}
+
 func NewTempCommand() core.Command {
 	return core.Command{
 		Name:  "temp",
@@ -78,3 +78,12 @@ func NewMigrateInjector(c *config.Config) *mission.MigrateProvider {
 		),
 	)
 }
+
+func NewImportInjector(c *config.Config) *mission.ImportProvider {
+	panic(
+		wire.Build(
+			providerSet,
+			mission.ImportProviderSet,
+		),
+	)
+}
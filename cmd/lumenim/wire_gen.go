@@ -12,6 +12,9 @@ import (
 	"github.com/gzydong/go-chat/internal/apis"
 	"github.com/gzydong/go-chat/internal/apis/handler"
 	"github.com/gzydong/go-chat/internal/apis/handler/admin"
+	"github.com/gzydong/go-chat/internal/apis/handler/admin/broadcast"
+	group3 "github.com/gzydong/go-chat/internal/apis/handler/admin/group"
+	"github.com/gzydong/go-chat/internal/apis/handler/admin/moderation"
 	"github.com/gzydong/go-chat/internal/apis/handler/admin/system"
 	"github.com/gzydong/go-chat/internal/apis/handler/admin/user"
 	"github.com/gzydong/go-chat/internal/apis/handler/open"
@@ -30,6 +33,7 @@ import (
 	"github.com/gzydong/go-chat/internal/mission/cron"
 	"github.com/gzydong/go-chat/internal/mission/queue"
 	"github.com/gzydong/go-chat/internal/mission/temp"
+	"github.com/gzydong/go-chat/internal/pkg/unfurl"
 	"github.com/gzydong/go-chat/internal/provider"
 	"github.com/gzydong/go-chat/internal/repository/cache"
 	"github.com/gzydong/go-chat/internal/repository/repo"
@@ -98,6 +102,60 @@ func NewHttpInjector(c *config.Config) *apis.Provider {
 		Redis:        client,
 	}
 	iAesUtil := provider.NewAesUtil(c)
+	sensitiveWord := repo.NewSensitiveWord(db)
+	moderationRecord := repo.NewModerationRecord(db)
+	noopModerationClassifier := &service.NoopModerationClassifier{}
+	relation := cache.NewRelation(client)
+	groupMember := cache.NewGroupMember(client)
+	repoGroupMember := repo.NewGroupMember(db, relation, groupMember)
+	pushMessage := &logic.PushMessage{
+		Redis: client,
+	}
+	messageStorage := cache.NewMessageStorage(client)
+	talkSyncEvent := repo.NewTalkSyncEvent(db)
+	sequence := cache.NewSequence(client)
+	repoSequence := repo.NewSequence(db, sequence)
+	vote := cache.NewVote(client)
+	groupVote := repo.NewGroupVote(db, vote)
+	talkUserMessage := repo.NewTalkRecordFriend(db)
+	talkGroupMessage := repo.NewTalkRecordGroup(db)
+	talkGroupMessageDel := repo.NewTalkRecordGroupDel(db)
+	talkGroupThread := repo.NewTalkGroupThread(db)
+	talkMessageArchive := repo.NewTalkMessageArchive(db)
+	talkRecordService := &service.TalkRecordService{
+		Source:                source,
+		TalkVoteCache:         vote,
+		TalkRecordsVoteRepo:   groupVote,
+		GroupMemberRepo:       repoGroupMember,
+		TalkRecordFriendRepo:  talkUserMessage,
+		TalkRecordGroupRepo:   talkGroupMessage,
+		TalkRecordsDeleteRepo: talkGroupMessageDel,
+		TalkGroupThreadRepo:   talkGroupThread,
+		TalkMessageArchive:    talkMessageArchive,
+	}
+	talkSyncService := &service.TalkSyncService{
+		Source:            source,
+		TalkSyncEventRepo: talkSyncEvent,
+		GroupMemberRepo:   repoGroupMember,
+		Sequence:          repoSequence,
+		TalkRecordService: talkRecordService,
+	}
+	talkService := &service.TalkService{
+		Source:          source,
+		GroupMemberRepo: repoGroupMember,
+		UserRepo:        users,
+		PushMessage:     pushMessage,
+		MessageStorage:  messageStorage,
+		TalkSyncService: talkSyncService,
+	}
+	moderationService := &service.ModerationService{
+		Source:               source,
+		SensitiveWordRepo:    sensitiveWord,
+		ModerationRecordRepo: moderationRecord,
+		UsersRepo:            users,
+		Classifier:           noopModerationClassifier,
+		TalkService:          talkService,
+	}
 	auth := &v1.Auth{
 		Config:              c,
 		Redis:               client,
@@ -114,15 +172,17 @@ func NewHttpInjector(c *config.Config) *apis.Provider {
 		Rsa:                 iRsa,
 		OauthService:        oAuthService,
 		AesUtil:             iAesUtil,
+		ModerationService:   moderationService,
 	}
 	organize := repo.NewOrganize(db)
 	v1User := &v1.User{
-		Redis:        client,
-		UsersRepo:    users,
-		OrganizeRepo: organize,
-		UserService:  userService,
-		SmsService:   smsService,
-		Rsa:          iRsa,
+		Redis:             client,
+		UsersRepo:         users,
+		OrganizeRepo:      organize,
+		UserService:       userService,
+		SmsService:        smsService,
+		Rsa:               iRsa,
+		ModerationService: moderationService,
 	}
 	department := repo.NewDepartment(db)
 	position := repo.NewPosition(db)
@@ -131,89 +191,219 @@ func NewHttpInjector(c *config.Config) *apis.Provider {
 		PositionRepo:   position,
 		OrganizeRepo:   organize,
 	}
-	messageStorage := cache.NewMessageStorage(client)
 	unreadStorage := cache.NewUnreadStorage(client)
 	contactRemark := cache.NewContactRemark(client)
-	relation := cache.NewRelation(client)
 	repoContact := repo.NewContact(db, contactRemark, relation)
 	repoGroup := repo.NewGroup(db)
-	groupMember := repo.NewGroupMember(db, relation)
-	pushMessage := &logic.PushMessage{
-		Redis: client,
-	}
-	talkService := &service.TalkService{
-		Source:          source,
-		GroupMemberRepo: groupMember,
-		UserRepo:        users,
-		PushMessage:     pushMessage,
-		MessageStorage:  messageStorage,
-	}
 	talkSession := repo.NewTalkSession(db)
 	talkSessionService := &service.TalkSessionService{
 		Source:          source,
 		TalkSessionRepo: talkSession,
+		TalkSyncService: talkSyncService,
 	}
-	sequence := cache.NewSequence(client)
-	repoSequence := repo.NewSequence(db, sequence)
+	userBlock := cache.NewUserBlock(client)
+	repoUserBlock := repo.NewUserBlock(db, userBlock)
 	groupService := &service.GroupService{
 		Source:          source,
 		GroupRepo:       repoGroup,
-		GroupMemberRepo: groupMember,
+		GroupMemberRepo: repoGroupMember,
 		Relation:        relation,
 		Sequence:        repoSequence,
 		PushMessage:     pushMessage,
+		UserBlockRepo:   repoUserBlock,
+	}
+	talkExpireSetting := repo.NewTalkExpireSetting(db)
+	talkMessageExpire := repo.NewTalkMessageExpire(db)
+	fileUpload := repo.NewFileUpload(db)
+	iFilesystem := provider.NewFilesystem(c)
+	serverStorage := cache.NewSidStorage(client)
+	talkMessageOutbox := repo.NewTalkMessageOutbox(db)
+	messageService := &message.Service{
+		Source:                source,
+		GroupMemberRepo:       repoGroupMember,
+		SplitUploadRepo:       fileUpload,
+		TalkRecordsVoteRepo:   groupVote,
+		UsersRepo:             users,
+		Filesystem:            iFilesystem,
+		UnreadStorage:         unreadStorage,
+		MessageStorage:        messageStorage,
+		ServerStorage:         serverStorage,
+		Sequence:              repoSequence,
+		RobotRepo:             robot,
+		PushMessage:           pushMessage,
+		TalkGroupThreadRepo:   talkGroupThread,
+		TalkExpireSettingRepo: talkExpireSetting,
+		TalkMessageExpireRepo: talkMessageExpire,
+		TalkMessageOutboxRepo: talkMessageOutbox,
+		TalkMessageArchive:    talkMessageArchive,
+	}
+	groupMuteService := &service.GroupMuteService{
+		Source:          source,
+		GroupRepo:       repoGroup,
+		GroupMemberRepo: repoGroupMember,
+		UsersRepo:       users,
+		PushMessage:     pushMessage,
+		MessageService:  messageService,
+	}
+	groupPermissionService := &service.GroupPermissionService{
+		GroupRepo:       repoGroup,
+		GroupMemberRepo: repoGroupMember,
+	}
+	groupAntiSpam := cache.NewGroupAntiSpam(client)
+	groupAntiSpamService := &service.GroupAntiSpamService{
+		GroupRepo:        repoGroup,
+		GroupMemberRepo:  repoGroupMember,
+		AntiSpamStorage:  groupAntiSpam,
+		GroupMuteService: groupMuteService,
+		GroupPermission:  groupPermissionService,
 	}
 	authService := &service.AuthService{
 		OrganizeRepo:    organize,
 		ContactRepo:     repoContact,
 		GroupRepo:       repoGroup,
-		GroupMemberRepo: groupMember,
+		GroupMemberRepo: repoGroupMember,
+		UserBlockRepo:   repoUserBlock,
+		AntiSpamService: groupAntiSpamService,
+	}
+	talkMessageExpireService := &service.TalkMessageExpireService{
+		Source:                source,
+		TalkExpireSettingRepo: talkExpireSetting,
+		TalkMessageExpireRepo: talkMessageExpire,
+		GroupMemberRepo:       repoGroupMember,
+		UserRepo:              users,
+		AuthService:           authService,
+		MessageService:        messageService,
+		TalkMessageArchive:    talkMessageArchive,
+		TalkSyncService:       talkSyncService,
+		PushMessage:           pushMessage,
+		Filesystem:            iFilesystem,
 	}
 	session := &talk.Session{
-		RedisLock:          redisLock,
-		MessageStorage:     messageStorage,
-		UnreadStorage:      unreadStorage,
-		ContactRemark:      contactRemark,
-		ContactRepo:        repoContact,
-		UsersRepo:          users,
-		GroupRepo:          repoGroup,
-		TalkService:        talkService,
-		TalkSessionService: talkSessionService,
-		UserService:        userService,
-		GroupService:       groupService,
-		AuthService:        authService,
+		RedisLock:                redisLock,
+		MessageStorage:           messageStorage,
+		UnreadStorage:            unreadStorage,
+		ContactRemark:            contactRemark,
+		ContactRepo:              repoContact,
+		UsersRepo:                users,
+		GroupRepo:                repoGroup,
+		TalkService:              talkService,
+		TalkSessionService:       talkSessionService,
+		UserService:              userService,
+		GroupService:             groupService,
+		AuthService:              authService,
+		TalkMessageExpireService: talkMessageExpireService,
+		TalkSyncService:          talkSyncService,
 	}
 	inMemoryRedEnvelopeService := service.NewInMemoryRedEnvelopeService()
-	iFilesystem := provider.NewFilesystem(c)
-	talkUserMessage := repo.NewTalkRecordFriend(db)
-	talkGroupMessage := repo.NewTalkRecordGroup(db)
-	vote := cache.NewVote(client)
-	groupVote := repo.NewGroupVote(db, vote)
-	talkGroupMessageDel := repo.NewTalkRecordGroupDel(db)
-	talkRecordService := &service.TalkRecordService{
-		Source:                source,
-		TalkVoteCache:         vote,
-		TalkRecordsVoteRepo:   groupVote,
-		GroupMemberRepo:       groupMember,
-		TalkRecordFriendRepo:  talkUserMessage,
-		TalkRecordGroupRepo:   talkGroupMessage,
-		TalkRecordsDeleteRepo: talkGroupMessageDel,
-	}
 	groupMemberService := &service.GroupMemberService{
 		Source:          source,
-		GroupMemberRepo: groupMember,
+		GroupMemberRepo: repoGroupMember,
 	}
 	talkMessage := &talk.Message{
 		TalkService:          talkService,
 		AuthService:          authService,
 		RedEnvelopeService:   inMemoryRedEnvelopeService,
 		Filesystem:           iFilesystem,
-		GroupMemberRepo:      groupMember,
+		GroupMemberRepo:      repoGroupMember,
 		TalkRecordFriendRepo: talkUserMessage,
 		TalkRecordGroupRepo:  talkGroupMessage,
 		TalkRecordsService:   talkRecordService,
 		GroupMemberService:   groupMemberService,
 	}
+	thread := &talk.Thread{
+		AuthService:        authService,
+		TalkRecordsService: talkRecordService,
+	}
+	iEngine := provider.NewSearchEngine(c)
+	talkSearchService := &service.TalkSearchService{
+		Source:            source,
+		Engine:            iEngine,
+		GroupMemberRepo:   repoGroupMember,
+		TalkRecordService: talkRecordService,
+	}
+	search := &talk.Search{
+		TalkSearchService: talkSearchService,
+	}
+	talkMessagePin := repo.NewTalkMessagePin(db)
+	talkMessagePinService := &service.TalkMessagePinService{
+		Source:             source,
+		TalkMessagePinRepo: talkMessagePin,
+		GroupMemberRepo:    repoGroupMember,
+		UserRepo:           users,
+		PushMessage:        pushMessage,
+		MessageService:     messageService,
+		TalkRecordService:  talkRecordService,
+		GroupPermission:    groupPermissionService,
+	}
+	pin := &talk.Pin{
+		TalkMessagePinService: talkMessagePinService,
+	}
+	talkScheduledMessage := repo.NewTalkScheduledMessage(db)
+	talkScheduleService := &service.TalkScheduleService{
+		Source:                   source,
+		TalkScheduledMessageRepo: talkScheduledMessage,
+		AuthService:              authService,
+		MessageService:           messageService,
+		ModerationService:        moderationService,
+		GroupPermission:          groupPermissionService,
+	}
+	schedule := &talk.Schedule{
+		TalkScheduleService: talkScheduleService,
+	}
+	expire := &talk.Expire{
+		TalkMessageExpireService: talkMessageExpireService,
+	}
+	sync := &talk.Sync{
+		TalkSyncService: talkSyncService,
+	}
+	groupRobot := repo.NewGroupRobot(db)
+	talkCardService := &service.TalkCardService{
+		Source:           source,
+		GroupRobotRepo:   groupRobot,
+		GroupMemberRepo:  repoGroupMember,
+		TalkGroupMessage: talkGroupMessage,
+		UsersRepo:        users,
+		MessageService:   messageService,
+		PushMessage:      pushMessage,
+	}
+	card := &talk.Card{
+		TalkCardService: talkCardService,
+	}
+	talkExport := repo.NewTalkExport(db)
+	producer := provider.NewNsqProducer(c)
+	talkExportService := &service.TalkExportService{
+		Source:             source,
+		TalkExportRepo:     talkExport,
+		UsersRepo:          users,
+		GroupRepo:          repoGroup,
+		GroupMemberRepo:    repoGroupMember,
+		TalkMessageArchive: talkMessageArchive,
+		Filesystem:         iFilesystem,
+		Producer:           producer,
+		PushMessage:        pushMessage,
+	}
+	export := &talk.Export{
+		TalkExportRepo:    talkExport,
+		TalkExportService: talkExportService,
+	}
+	talkFavorite := repo.NewTalkFavorite(db)
+	talkFavoriteService := &service.TalkFavoriteService{
+		Source:                source,
+		TalkFavoriteRepo:      talkFavorite,
+		GroupMemberRepo:       repoGroupMember,
+		TalkRecordsDeleteRepo: talkGroupMessageDel,
+		TalkMessageExpireRepo: talkMessageExpire,
+		TalkMessageArchive:    talkMessageArchive,
+		AuthService:           authService,
+		MessageService:        messageService,
+		ModerationService:     moderationService,
+	}
+	favorite := &talk.Favorite{
+		TalkFavoriteRepo:    talkFavorite,
+		UsersRepo:           users,
+		TalkFavoriteService: talkFavoriteService,
+		Filesystem:          iFilesystem,
+	}
 	emoticon := repo.NewEmoticon(db)
 	emoticonService := &service.EmoticonService{
 		Source:       source,
@@ -226,7 +416,6 @@ func NewHttpInjector(c *config.Config) *apis.Provider {
 		EmoticonService: emoticonService,
 		Filesystem:      iFilesystem,
 	}
-	fileUpload := repo.NewFileUpload(db)
 	fileSplitUploadService := &service.FileSplitUploadService{
 		Source:          source,
 		SplitUploadRepo: fileUpload,
@@ -244,71 +433,104 @@ func NewHttpInjector(c *config.Config) *apis.Provider {
 		Source:      source,
 		ContactRepo: repoContact,
 	}
-	serverStorage := cache.NewSidStorage(client)
-	messageService := &message.Service{
-		Source:              source,
-		GroupMemberRepo:     groupMember,
-		SplitUploadRepo:     fileUpload,
-		TalkRecordsVoteRepo: groupVote,
-		UsersRepo:           users,
-		Filesystem:          iFilesystem,
-		UnreadStorage:       unreadStorage,
-		MessageStorage:      messageStorage,
-		ServerStorage:       serverStorage,
-		Sequence:            repoSequence,
-		RobotRepo:           robot,
-		PushMessage:         pushMessage,
-	}
 	groupGroup := &group.Group{
 		RedisLock:          redisLock,
 		Repo:               source,
 		UsersRepo:          users,
 		GroupRepo:          repoGroup,
-		GroupMemberRepo:    groupMember,
+		GroupMemberRepo:    repoGroupMember,
 		GroupNoticeRepo:    groupNotice,
 		TalkSessionRepo:    talkSession,
 		GroupService:       groupService,
 		GroupMemberService: groupMemberService,
+		GroupMuteService:   groupMuteService,
+		GroupPermission:    groupPermissionService,
 		TalkSessionService: talkSessionService,
 		UserService:        userService,
 		ContactService:     contactService,
 		Message:            messageService,
+		ModerationService:  moderationService,
+	}
+	groupNoticeConfirm := repo.NewGroupNoticeConfirm(db)
+	groupNoticeService := &service.GroupNoticeService{
+		Source:                 source,
+		GroupNoticeRepo:        groupNotice,
+		GroupNoticeConfirmRepo: groupNoticeConfirm,
+		GroupMemberRepo:        repoGroupMember,
+		UsersRepo:              users,
+		RedisLock:              redisLock,
+		PushMessage:            pushMessage,
+		MessageService:         messageService,
+		ModerationService:      moderationService,
+		GroupPermission:        groupPermissionService,
 	}
 	notice := &group.Notice{
-		GroupMemberRepo:    groupMember,
 		GroupNoticeRepo:    groupNotice,
-		GroupMemberService: groupMemberService,
-		Message:            messageService,
 		UsersRepo:          users,
+		GroupNoticeService: groupNoticeService,
 	}
 	groupApplyStorage := cache.NewGroupApplyStorage(client)
 	groupApply := repo.NewGroupApply(db)
 	groupApplyService := &service.GroupApplyService{
-		Source:         source,
-		GroupApplyRepo: groupApply,
+		Source:            source,
+		GroupApplyRepo:    groupApply,
+		GroupMemberRepo:   repoGroupMember,
+		GroupApplyStorage: groupApplyStorage,
+		PushMessage:       pushMessage,
+	}
+	groupInviteLink := repo.NewGroupInviteLink(db)
+	groupJoinService := &service.GroupJoinService{
+		GroupRepo:           repoGroup,
+		GroupMemberRepo:     repoGroupMember,
+		GroupInviteLinkRepo: groupInviteLink,
+		GroupService:        groupService,
+		GroupApplyService:   groupApplyService,
+		GroupPermission:     groupPermissionService,
 	}
 	apply := &group.Apply{
 		Redis:              client,
 		GroupApplyStorage:  groupApplyStorage,
 		GroupRepo:          repoGroup,
 		GroupApplyRepo:     groupApply,
-		GroupMemberRepo:    groupMember,
+		GroupMemberRepo:    repoGroupMember,
 		GroupApplyService:  groupApplyService,
 		GroupMemberService: groupMemberService,
 		GroupService:       groupService,
-		PushMessage:        pushMessage,
+		GroupJoinService:   groupJoinService,
 	}
 	groupVoteService := &service.GroupVoteService{
 		Source:          source,
-		GroupMemberRepo: groupMember,
+		GroupMemberRepo: repoGroupMember,
 		GroupVoteRepo:   groupVote,
 		Sequence:        repoSequence,
+		PushMessage:     pushMessage,
 	}
 	vote2 := &group.Vote{
-		GroupMemberRepo:  groupMember,
+		GroupMemberRepo:  repoGroupMember,
 		GroupVoteRepo:    groupVote,
 		GroupVoteService: groupVoteService,
 		MessageService:   messageService,
+		GroupPermission:  groupPermissionService,
+	}
+	mute := &group.Mute{
+		GroupRepo:        repoGroup,
+		GroupMemberRepo:  repoGroupMember,
+		GroupMuteService: groupMuteService,
+	}
+	member := &group.Member{
+		GroupRepo:       repoGroup,
+		GroupMemberRepo: repoGroupMember,
+	}
+	join := &group.Join{
+		RedisLock:        redisLock,
+		GroupMemberRepo:  repoGroupMember,
+		GroupJoinService: groupJoinService,
+	}
+	permission := &group.Permission{
+		GroupPermission: groupPermissionService,
+	}
+	antiSpam := &group.AntiSpam{
+		GroupAntiSpamService: groupAntiSpamService,
 	}
 	userClient := cache.NewUserClient(client)
 	contactContact := &contact.Contact{
@@ -321,10 +543,12 @@ func NewHttpInjector(c *config.Config) *apis.Provider {
 		TalkListService: talkSessionService,
 		Message:         messageService,
 		UserClient:      userClient,
+		UserBlockRepo:   repoUserBlock,
 	}
 	contactApplyService := &service.ContactApplyService{
-		Source:      source,
-		PushMessage: pushMessage,
+		Source:        source,
+		PushMessage:   pushMessage,
+		UserBlockRepo: repoUserBlock,
 	}
 	contactApply := &contact.Apply{
 		ContactRepo:         repoContact,
@@ -344,6 +568,14 @@ func NewHttpInjector(c *config.Config) *apis.Provider {
 		ContactGroupService: contactGroupService,
 		ContactService:      contactService,
 	}
+	userBlockService := &service.UserBlockService{
+		Source:        source,
+		UsersRepo:     users,
+		UserBlockRepo: repoUserBlock,
+	}
+	block := &contact.Block{
+		UserBlockService: userBlockService,
+	}
 	articleAnnex := repo.NewArticleAnnex(db)
 	repoArticle := repo.NewArticle(db)
 	articleHistory := repo.NewArticleHistory(db)
@@ -366,6 +598,7 @@ func NewHttpInjector(c *config.Config) *apis.Provider {
 		ArticleService:      articleService,
 		ArticleAnnexService: articleAnnexService,
 		Filesystem:          iFilesystem,
+		ModerationService:   moderationService,
 	}
 	annex := &article.Annex{
 		ArticleAnnexRepo:    articleAnnex,
@@ -382,8 +615,11 @@ func NewHttpInjector(c *config.Config) *apis.Provider {
 		ArticleTagService: articleTagService,
 	}
 	publish := &talk.Publish{
-		AuthService:    authService,
-		MessageService: messageService,
+		AuthService:         authService,
+		MessageService:      messageService,
+		TalkScheduleService: talkScheduleService,
+		ModerationService:   moderationService,
+		GroupPermission:     groupPermissionService,
 	}
 	invite := &v1.Invite{
 		InviteCodeService: inviteCodeService,
@@ -398,15 +634,35 @@ func NewHttpInjector(c *config.Config) *apis.Provider {
 	wallet := &v1.Wallet{
 		WalletService: mockWalletService,
 	}
-	groupRobot := repo.NewGroupRobot(db)
 	groupRobotService := &service.GroupRobotService{
 		GroupRobotRepo:  groupRobot,
 		GroupRepo:       repoGroup,
-		GroupMemberRepo: groupMember,
+		GroupMemberRepo: repoGroupMember,
 		MessageService:  messageService,
+		TalkCardService: talkCardService,
+		GroupPermission: groupPermissionService,
 	}
 	v1GroupRobot := &v1.GroupRobot{
 		GroupRobotService: groupRobotService,
+		TalkCardService:   talkCardService,
+	}
+	report := repo.NewReport(db)
+	reportAudit := repo.NewReportAudit(db)
+	reportService := &service.ReportService{
+		Source:             source,
+		ReportRepo:         report,
+		ReportAuditRepo:    reportAudit,
+		UsersRepo:          users,
+		GroupRepo:          repoGroup,
+		GroupMemberRepo:    repoGroupMember,
+		TalkRecordService:  talkRecordService,
+		TalkService:        talkService,
+		GroupService:       groupService,
+		GroupMemberService: groupMemberService,
+		MessageService:     messageService,
+	}
+	v1Report := &v1.Report{
+		ReportService: reportService,
 	}
 	webV1 := &web.V1{
 		Common:       common,
@@ -415,6 +671,15 @@ func NewHttpInjector(c *config.Config) *apis.Provider {
 		Organize:     v1Organize,
 		Talk:         session,
 		TalkMessage:  talkMessage,
+		TalkThread:   thread,
+		TalkSearch:   search,
+		TalkPin:      pin,
+		TalkSchedule: schedule,
+		TalkExpire:   expire,
+		TalkSync:     sync,
+		TalkCard:     card,
+		TalkExport:   export,
+		TalkFavorite: favorite,
 		Emoticon:     v1Emoticon,
 		Upload:       upload,
 		Trtc:         trtc,
@@ -422,9 +687,15 @@ func NewHttpInjector(c *config.Config) *apis.Provider {
 		GroupNotice:  notice,
 		GroupApply:   apply,
 		GroupVote:    vote2,
+		GroupMute:    mute,
+		GroupMember:  member,
+		GroupPerm:    permission,
+		GroupJoin:    join,
+		GroupSpam:    antiSpam,
 		Contact:      contactContact,
 		ContactApply: contactApply,
 		ContactGroup: group2,
+		ContactBlock: block,
 		Article:      articleArticle,
 		ArticleAnnex: annex,
 		ArticleClass: class,
@@ -434,6 +705,7 @@ func NewHttpInjector(c *config.Config) *apis.Provider {
 		KYC:          kyc,
 		Wallet:       wallet,
 		GroupRobot:   v1GroupRobot,
+		Report:       v1Report,
 	}
 	webHandler := &web.Handler{
 		V1:       webV1,
@@ -474,15 +746,63 @@ func NewHttpInjector(c *config.Config) *apis.Provider {
 	userUser := &user.User{
 		UserRepo: users,
 	}
+	moderationModeration := &moderation.Moderation{
+		SensitiveWordRepo:    sensitiveWord,
+		ModerationRecordRepo: moderationRecord,
+		ModerationService:    moderationService,
+	}
+	moderationReport := &moderation.Report{
+		ReportRepo:      report,
+		ReportAuditRepo: reportAudit,
+		ReportService:   reportService,
+	}
+	broadcastJob := repo.NewBroadcastJob(db)
+	broadcastRecipient := repo.NewBroadcastRecipient(db)
+	broadcastService := &service.BroadcastService{
+		Source:                 source,
+		BroadcastJobRepo:       broadcastJob,
+		BroadcastRecipientRepo: broadcastRecipient,
+		UsersRepo:              users,
+		Producer:               producer,
+	}
+	broadcastBroadcast := &broadcast.Broadcast{
+		BroadcastJobRepo:       broadcastJob,
+		BroadcastRecipientRepo: broadcastRecipient,
+		BroadcastService:       broadcastService,
+	}
+	moderationExport := &moderation.Export{
+		TalkExportRepo:    talkExport,
+		TalkExportService: talkExportService,
+	}
+	talkImportService := &service.TalkImportService{
+		Source:    source,
+		UsersRepo: users,
+		GroupRepo: repoGroup,
+		Sequence:  repoSequence,
+	}
+	moderationImport := &moderation.Import{
+		TalkImportService: talkImportService,
+	}
+	groupGroup2 := &group3.Group{
+		GroupRepo:       repoGroup,
+		GroupMemberRepo: repoGroupMember,
+		GroupService:    groupService,
+	}
 	adminHandler := &admin.Handler{
-		Auth:      adminAuth,
-		Totp:      totp,
-		Admin:     systemAdmin,
-		Role:      role,
-		Resource:  resource,
-		Menu:      menu,
-		AdminRepo: repoAdmin,
-		User:      userUser,
+		Auth:       adminAuth,
+		Totp:       totp,
+		Admin:      systemAdmin,
+		Role:       role,
+		Resource:   resource,
+		Menu:       menu,
+		AdminRepo:  repoAdmin,
+		User:       userUser,
+		Moderation: moderationModeration,
+		Report:     moderationReport,
+		Broadcast:  broadcastBroadcast,
+		Export:     moderationExport,
+		Import:     moderationImport,
+		Group:      groupGroup2,
 	}
 	index := v1_2.NewIndex()
 	openV1 := &open.V1{
@@ -498,8 +818,9 @@ func NewHttpInjector(c *config.Config) *apis.Provider {
 	}
 	engine := router.NewRouter(c, handlerHandler, jwtTokenStorage)
 	apisProvider := &apis.Provider{
-		Config: c,
-		Engine: engine,
+		Config:            c,
+		Engine:            engine,
+		TalkSearchService: talkSearchService,
 	}
 	return apisProvider
 }
@@ -513,18 +834,23 @@ func NewCometInjector(c *config.Config) *comet.Provider {
 	vote := cache.NewVote(client)
 	groupVote := repo.NewGroupVote(db, vote)
 	relation := cache.NewRelation(client)
-	groupMember := repo.NewGroupMember(db, relation)
+	groupMember := cache.NewGroupMember(client)
+	repoGroupMember := repo.NewGroupMember(db, relation, groupMember)
 	talkUserMessage := repo.NewTalkRecordFriend(db)
 	talkGroupMessage := repo.NewTalkRecordGroup(db)
 	talkGroupMessageDel := repo.NewTalkRecordGroupDel(db)
+	talkGroupThread := repo.NewTalkGroupThread(db)
+	talkMessageArchive := repo.NewTalkMessageArchive(db)
 	talkRecordService := &service.TalkRecordService{
 		Source:                source,
 		TalkVoteCache:         vote,
 		TalkRecordsVoteRepo:   groupVote,
-		GroupMemberRepo:       groupMember,
+		GroupMemberRepo:       repoGroupMember,
 		TalkRecordFriendRepo:  talkUserMessage,
 		TalkRecordGroupRepo:   talkGroupMessage,
 		TalkRecordsDeleteRepo: talkGroupMessageDel,
+		TalkGroupThreadRepo:   talkGroupThread,
+		TalkMessageArchive:    talkMessageArchive,
 	}
 	contactRemark := cache.NewContactRemark(client)
 	repoContact := repo.NewContact(db, contactRemark, relation)
@@ -532,14 +858,18 @@ func NewCometInjector(c *config.Config) *comet.Provider {
 		Source:      source,
 		ContactRepo: repoContact,
 	}
+	userBlock := cache.NewUserBlock(client)
+	repoUserBlock := repo.NewUserBlock(db, userBlock)
 	consumeHandler := &consume.Handler{
-		Config:             c,
-		OrganizeRepo:       organize,
-		UserRepo:           users,
-		Source:             source,
-		TalkRecordsService: talkRecordService,
-		ContactService:     contactService,
-		GroupMemberRepo:    groupMember,
+		Config:              c,
+		OrganizeRepo:        organize,
+		UserRepo:            users,
+		Source:              source,
+		TalkRecordsService:  talkRecordService,
+		ContactService:      contactService,
+		GroupMemberRepo:     repoGroupMember,
+		TalkGroupThreadRepo: talkGroupThread,
+		UserBlockRepo:       repoUserBlock,
 	}
 	subscribe := &comet.Subscribe{
 		Redis:   client,
@@ -591,10 +921,182 @@ func NewCronInjector(c *config.Config) *mission.CronProvider {
 		WalletService:      mockWalletService,
 		RedEnvelopeService: inMemoryRedEnvelopeService,
 	}
+	client := provider.NewRedisClient(c)
+	source := repo.NewSource(db, client)
+	talkScheduledMessage := repo.NewTalkScheduledMessage(db)
+	organize := repo.NewOrganize(db)
+	contactRemark := cache.NewContactRemark(client)
+	relation := cache.NewRelation(client)
+	repoContact := repo.NewContact(db, contactRemark, relation)
+	repoGroup := repo.NewGroup(db)
+	groupMember := cache.NewGroupMember(client)
+	repoGroupMember := repo.NewGroupMember(db, relation, groupMember)
+	userBlock := cache.NewUserBlock(client)
+	repoUserBlock := repo.NewUserBlock(db, userBlock)
+	fileUpload := repo.NewFileUpload(db)
+	vote := cache.NewVote(client)
+	groupVote := repo.NewGroupVote(db, vote)
+	users := repo.NewUsers(db, client)
+	unreadStorage := cache.NewUnreadStorage(client)
+	messageStorage := cache.NewMessageStorage(client)
+	serverStorage := cache.NewSidStorage(client)
+	sequence := cache.NewSequence(client)
+	repoSequence := repo.NewSequence(db, sequence)
+	robot := repo.NewRobot(db)
+	pushMessage := &logic.PushMessage{
+		Redis: client,
+	}
+	talkGroupThread := repo.NewTalkGroupThread(db)
+	talkExpireSetting := repo.NewTalkExpireSetting(db)
+	talkMessageExpire := repo.NewTalkMessageExpire(db)
+	talkMessageOutbox := repo.NewTalkMessageOutbox(db)
+	talkMessageArchive := repo.NewTalkMessageArchive(db)
+	messageService := &message.Service{
+		Source:                source,
+		GroupMemberRepo:       repoGroupMember,
+		SplitUploadRepo:       fileUpload,
+		TalkRecordsVoteRepo:   groupVote,
+		UsersRepo:             users,
+		Filesystem:            iFilesystem,
+		UnreadStorage:         unreadStorage,
+		MessageStorage:        messageStorage,
+		ServerStorage:         serverStorage,
+		Sequence:              repoSequence,
+		RobotRepo:             robot,
+		PushMessage:           pushMessage,
+		TalkGroupThreadRepo:   talkGroupThread,
+		TalkExpireSettingRepo: talkExpireSetting,
+		TalkMessageExpireRepo: talkMessageExpire,
+		TalkMessageOutboxRepo: talkMessageOutbox,
+		TalkMessageArchive:    talkMessageArchive,
+	}
+	groupMuteService := &service.GroupMuteService{
+		Source:          source,
+		GroupRepo:       repoGroup,
+		GroupMemberRepo: repoGroupMember,
+		UsersRepo:       users,
+		PushMessage:     pushMessage,
+		MessageService:  messageService,
+	}
+	groupPermissionService := &service.GroupPermissionService{
+		GroupRepo:       repoGroup,
+		GroupMemberRepo: repoGroupMember,
+	}
+	groupAntiSpam := cache.NewGroupAntiSpam(client)
+	groupAntiSpamService := &service.GroupAntiSpamService{
+		GroupRepo:        repoGroup,
+		GroupMemberRepo:  repoGroupMember,
+		AntiSpamStorage:  groupAntiSpam,
+		GroupMuteService: groupMuteService,
+		GroupPermission:  groupPermissionService,
+	}
+	authService := &service.AuthService{
+		OrganizeRepo:    organize,
+		ContactRepo:     repoContact,
+		GroupRepo:       repoGroup,
+		GroupMemberRepo: repoGroupMember,
+		UserBlockRepo:   repoUserBlock,
+		AntiSpamService: groupAntiSpamService,
+	}
+	sensitiveWord := repo.NewSensitiveWord(db)
+	moderationRecord := repo.NewModerationRecord(db)
+	noopModerationClassifier := &service.NoopModerationClassifier{}
+	talkSyncEvent := repo.NewTalkSyncEvent(db)
+	talkUserMessage := repo.NewTalkRecordFriend(db)
+	talkGroupMessage := repo.NewTalkRecordGroup(db)
+	talkGroupMessageDel := repo.NewTalkRecordGroupDel(db)
+	talkRecordService := &service.TalkRecordService{
+		Source:                source,
+		TalkVoteCache:         vote,
+		TalkRecordsVoteRepo:   groupVote,
+		GroupMemberRepo:       repoGroupMember,
+		TalkRecordFriendRepo:  talkUserMessage,
+		TalkRecordGroupRepo:   talkGroupMessage,
+		TalkRecordsDeleteRepo: talkGroupMessageDel,
+		TalkGroupThreadRepo:   talkGroupThread,
+		TalkMessageArchive:    talkMessageArchive,
+	}
+	talkSyncService := &service.TalkSyncService{
+		Source:            source,
+		TalkSyncEventRepo: talkSyncEvent,
+		GroupMemberRepo:   repoGroupMember,
+		Sequence:          repoSequence,
+		TalkRecordService: talkRecordService,
+	}
+	talkService := &service.TalkService{
+		Source:          source,
+		GroupMemberRepo: repoGroupMember,
+		UserRepo:        users,
+		PushMessage:     pushMessage,
+		MessageStorage:  messageStorage,
+		TalkSyncService: talkSyncService,
+	}
+	moderationService := &service.ModerationService{
+		Source:               source,
+		SensitiveWordRepo:    sensitiveWord,
+		ModerationRecordRepo: moderationRecord,
+		UsersRepo:            users,
+		Classifier:           noopModerationClassifier,
+		TalkService:          talkService,
+	}
+	talkScheduleService := &service.TalkScheduleService{
+		Source:                   source,
+		TalkScheduledMessageRepo: talkScheduledMessage,
+		AuthService:              authService,
+		MessageService:           messageService,
+		ModerationService:        moderationService,
+		GroupPermission:          groupPermissionService,
+	}
+	dispatchScheduledMessage := &cron.DispatchScheduledMessage{
+		TalkScheduleService: talkScheduleService,
+	}
+	talkMessageExpireService := &service.TalkMessageExpireService{
+		Source:                source,
+		TalkExpireSettingRepo: talkExpireSetting,
+		TalkMessageExpireRepo: talkMessageExpire,
+		GroupMemberRepo:       repoGroupMember,
+		UserRepo:              users,
+		AuthService:           authService,
+		MessageService:        messageService,
+		TalkMessageArchive:    talkMessageArchive,
+		TalkSyncService:       talkSyncService,
+		PushMessage:           pushMessage,
+		Filesystem:            iFilesystem,
+	}
+	clearExpiredMessage := &cron.ClearExpiredMessage{
+		TalkMessageExpireService: talkMessageExpireService,
+	}
+	clearTalkExport := &cron.ClearTalkExport{
+		DB:         db,
+		Filesystem: iFilesystem,
+	}
+	archiveTalkMessage := &cron.ArchiveTalkMessage{
+		Config: c,
+		DB:     db,
+	}
+	releaseGroupMute := &cron.ReleaseGroupMute{
+		GroupMuteService: groupMuteService,
+	}
+	groupVoteService := &service.GroupVoteService{
+		Source:          source,
+		GroupMemberRepo: repoGroupMember,
+		GroupVoteRepo:   groupVote,
+		Sequence:        repoSequence,
+		PushMessage:     pushMessage,
+	}
+	closeGroupVote := &cron.CloseGroupVote{
+		GroupVoteService: groupVoteService,
+	}
 	crontab := &cron.Crontab{
 		ClearArticle:      clearArticle,
 		ClearTmpFile:      clearTmpFile,
 		ExpireRedEnvelope: expireRedEnvelope,
+		ScheduledMessage:  dispatchScheduledMessage,
+		ExpiredMessage:    clearExpiredMessage,
+		ClearTalkExport:   clearTalkExport,
+		ArchiveMessage:    archiveTalkMessage,
+		ReleaseGroupMute:  releaseGroupMute,
+		CloseGroupVote:    closeGroupVote,
 	}
 	cronProvider := &mission.CronProvider{
 		Config:  c,
@@ -616,38 +1118,106 @@ func NewQueueInjector(c *config.Config) *mission.QueueProvider {
 		IpAddressClient: ipaddressClient,
 	}
 	talkSession := repo.NewTalkSession(db)
+	talkSyncEvent := repo.NewTalkSyncEvent(db)
+	relation := cache.NewRelation(client)
+	groupMember := cache.NewGroupMember(client)
+	repoGroupMember := repo.NewGroupMember(db, relation, groupMember)
+	sequence := cache.NewSequence(client)
+	repoSequence := repo.NewSequence(db, sequence)
+	vote := cache.NewVote(client)
+	groupVote := repo.NewGroupVote(db, vote)
+	talkUserMessage := repo.NewTalkRecordFriend(db)
+	talkGroupMessage := repo.NewTalkRecordGroup(db)
+	talkGroupMessageDel := repo.NewTalkRecordGroupDel(db)
+	talkGroupThread := repo.NewTalkGroupThread(db)
+	talkMessageArchive := repo.NewTalkMessageArchive(db)
+	talkRecordService := &service.TalkRecordService{
+		Source:                source,
+		TalkVoteCache:         vote,
+		TalkRecordsVoteRepo:   groupVote,
+		GroupMemberRepo:       repoGroupMember,
+		TalkRecordFriendRepo:  talkUserMessage,
+		TalkRecordGroupRepo:   talkGroupMessage,
+		TalkRecordsDeleteRepo: talkGroupMessageDel,
+		TalkGroupThreadRepo:   talkGroupThread,
+		TalkMessageArchive:    talkMessageArchive,
+	}
+	talkSyncService := &service.TalkSyncService{
+		Source:            source,
+		TalkSyncEventRepo: talkSyncEvent,
+		GroupMemberRepo:   repoGroupMember,
+		Sequence:          repoSequence,
+		TalkRecordService: talkRecordService,
+	}
 	talkSessionService := &service.TalkSessionService{
 		Source:          source,
 		TalkSessionRepo: talkSession,
+		TalkSyncService: talkSyncService,
 	}
-	relation := cache.NewRelation(client)
-	groupMember := repo.NewGroupMember(db, relation)
 	fileUpload := repo.NewFileUpload(db)
-	vote := cache.NewVote(client)
-	groupVote := repo.NewGroupVote(db, vote)
 	users := repo.NewUsers(db, client)
 	iFilesystem := provider.NewFilesystem(c)
 	unreadStorage := cache.NewUnreadStorage(client)
 	messageStorage := cache.NewMessageStorage(client)
 	serverStorage := cache.NewSidStorage(client)
-	sequence := cache.NewSequence(client)
-	repoSequence := repo.NewSequence(db, sequence)
 	pushMessage := &logic.PushMessage{
 		Redis: client,
 	}
+	talkExpireSetting := repo.NewTalkExpireSetting(db)
+	talkMessageExpire := repo.NewTalkMessageExpire(db)
+	talkMessageOutbox := repo.NewTalkMessageOutbox(db)
 	messageService := &message.Service{
-		Source:              source,
-		GroupMemberRepo:     groupMember,
-		SplitUploadRepo:     fileUpload,
-		TalkRecordsVoteRepo: groupVote,
-		UsersRepo:           users,
-		Filesystem:          iFilesystem,
-		UnreadStorage:       unreadStorage,
-		MessageStorage:      messageStorage,
-		ServerStorage:       serverStorage,
-		Sequence:            repoSequence,
-		RobotRepo:           robot,
-		PushMessage:         pushMessage,
+		Source:                source,
+		GroupMemberRepo:       repoGroupMember,
+		SplitUploadRepo:       fileUpload,
+		TalkRecordsVoteRepo:   groupVote,
+		UsersRepo:             users,
+		Filesystem:            iFilesystem,
+		UnreadStorage:         unreadStorage,
+		MessageStorage:        messageStorage,
+		ServerStorage:         serverStorage,
+		Sequence:              repoSequence,
+		RobotRepo:             robot,
+		PushMessage:           pushMessage,
+		TalkGroupThreadRepo:   talkGroupThread,
+		TalkExpireSettingRepo: talkExpireSetting,
+		TalkMessageExpireRepo: talkMessageExpire,
+		TalkMessageOutboxRepo: talkMessageOutbox,
+		TalkMessageArchive:    talkMessageArchive,
+	}
+	organize := repo.NewOrganize(db)
+	contactRemark := cache.NewContactRemark(client)
+	repoContact := repo.NewContact(db, contactRemark, relation)
+	repoGroup := repo.NewGroup(db)
+	userBlock := cache.NewUserBlock(client)
+	repoUserBlock := repo.NewUserBlock(db, userBlock)
+	groupMuteService := &service.GroupMuteService{
+		Source:          source,
+		GroupRepo:       repoGroup,
+		GroupMemberRepo: repoGroupMember,
+		UsersRepo:       users,
+		PushMessage:     pushMessage,
+		MessageService:  messageService,
+	}
+	groupPermissionService := &service.GroupPermissionService{
+		GroupRepo:       repoGroup,
+		GroupMemberRepo: repoGroupMember,
+	}
+	groupAntiSpam := cache.NewGroupAntiSpam(client)
+	groupAntiSpamService := &service.GroupAntiSpamService{
+		GroupRepo:        repoGroup,
+		GroupMemberRepo:  repoGroupMember,
+		AntiSpamStorage:  groupAntiSpam,
+		GroupMuteService: groupMuteService,
+		GroupPermission:  groupPermissionService,
+	}
+	authService := &service.AuthService{
+		OrganizeRepo:    organize,
+		ContactRepo:     repoContact,
+		GroupRepo:       repoGroup,
+		GroupMemberRepo: repoGroupMember,
+		UserBlockRepo:   repoUserBlock,
+		AntiSpamService: groupAntiSpamService,
 	}
 	userLoginConsumer := &queue.UserLoginConsumer{
 		RobotRepo:          robot,
@@ -655,16 +1225,71 @@ func NewQueueInjector(c *config.Config) *mission.QueueProvider {
 		TalkSessionService: talkSessionService,
 		Message:            messageService,
 	}
+	linkPreviewStorage := cache.NewLinkPreviewStorage(client)
+	options := _wireOptionsValue
+	fetcher := unfurl.New(options)
+	linkPreviewConsumer := &queue.LinkPreviewConsumer{
+		Source:             source,
+		TalkUserMessage:    talkUserMessage,
+		TalkGroupMessage:   talkGroupMessage,
+		LinkPreviewStorage: linkPreviewStorage,
+		Fetcher:            fetcher,
+		PushMessage:        pushMessage,
+	}
+	broadcastJob := repo.NewBroadcastJob(db)
+	broadcastRecipient := repo.NewBroadcastRecipient(db)
+	producer := provider.NewNsqProducer(c)
+	broadcastConsumer := &queue.BroadcastConsumer{
+		Source:                 source,
+		BroadcastJobRepo:       broadcastJob,
+		BroadcastRecipientRepo: broadcastRecipient,
+		UsersRepo:              users,
+		GroupMemberRepo:        repoGroupMember,
+		UserBlockRepo:          repoUserBlock,
+		AuthService:            authService,
+		TalkUserMessage:        talkUserMessage,
+		TalkGroupMessage:       talkGroupMessage,
+		MessageService:         messageService,
+		Producer:               producer,
+	}
+	talkExport := repo.NewTalkExport(db)
+	talkExportService := &service.TalkExportService{
+		Source:             source,
+		TalkExportRepo:     talkExport,
+		UsersRepo:          users,
+		GroupRepo:          repoGroup,
+		GroupMemberRepo:    repoGroupMember,
+		TalkMessageArchive: talkMessageArchive,
+		Filesystem:         iFilesystem,
+		Producer:           producer,
+		PushMessage:        pushMessage,
+	}
+	talkExportConsumer := &queue.TalkExportConsumer{
+		Source:            source,
+		TalkExportService: talkExportService,
+	}
 	consumers := &queue.Consumers{
-		UserLoginConsumer: userLoginConsumer,
+		UserLoginConsumer:   userLoginConsumer,
+		LinkPreviewConsumer: linkPreviewConsumer,
+		BroadcastConsumer:   broadcastConsumer,
+		TalkExportConsumer:  talkExportConsumer,
+	}
+	messageOutboxRelay := &queue.MessageOutboxRelay{
+		MessageService: messageService,
 	}
 	queueProvider := &mission.QueueProvider{
-		Consumers: consumers,
-		Redis:     client,
+		Config:      c,
+		Consumers:   consumers,
+		OutboxRelay: messageOutboxRelay,
+		Redis:       client,
 	}
 	return queueProvider
 }
 
+var (
+	_wireOptionsValue = unfurl.Options{}
+)
+
 func NewTempInjector(c *config.Config) *mission.TempProvider {
 	db := provider.NewMySQLClient(c)
 	client := provider.NewRedisClient(c)
@@ -687,6 +1312,26 @@ func NewMigrateInjector(c *config.Config) *mission.MigrateProvider {
 	return migrateProvider
 }
 
+func NewImportInjector(c *config.Config) *mission.ImportProvider {
+	db := provider.NewMySQLClient(c)
+	client := provider.NewRedisClient(c)
+	source := repo.NewSource(db, client)
+	users := repo.NewUsers(db, client)
+	repoGroup := repo.NewGroup(db)
+	sequence := cache.NewSequence(client)
+	repoSequence := repo.NewSequence(db, sequence)
+	talkImportService := &service.TalkImportService{
+		Source:    source,
+		UsersRepo: users,
+		GroupRepo: repoGroup,
+		Sequence:  repoSequence,
+	}
+	importProvider := &mission.ImportProvider{
+		TalkImportService: talkImportService,
+	}
+	return importProvider
+}
+
 // wire.go:
 
 var providerSet = wire.NewSet(provider.ProviderSet, cache.ProviderSet, repo.ProviderSet, logic.ProviderSet, service.ProviderSet)
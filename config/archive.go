@@ -0,0 +1,7 @@
+package config
+
+// Archive 聊天记录归档配置
+type Archive struct {
+	Months    int `json:"months" yaml:"months"`         // 归档早于该月数的聊天记录，为 0 时不归档
+	BatchSize int `json:"batch_size" yaml:"batch_size"` // 每批迁移的记录数，默认 1000
+}
@@ -21,6 +21,8 @@ type Config struct {
 	Nsq        *Nsq        `json:"nsq" yaml:"nsq"`
 	OAuth      *OAuth      `json:"oauth" yaml:"oauth"`
 	Trtc       *Trtc       `json:"trtc" yaml:"trtc"`
+	Search     *Search     `json:"search" yaml:"search"`
+	Archive    *Archive    `json:"archive" yaml:"archive"`
 }
 
 type Server struct {
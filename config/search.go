@@ -0,0 +1,7 @@
+package config
+
+// Search 消息检索配置
+type Search struct {
+	Driver string `json:"driver" yaml:"driver"` // 检索引擎，默认 memory
+	Path   string `json:"path" yaml:"path"`     // 内嵌索引快照保存路径，为空时不持久化
+}
@@ -0,0 +1,245 @@
+package broadcast
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gzydong/go-chat/internal/entity"
+	"github.com/gzydong/go-chat/internal/pkg/core/errorx"
+	"github.com/gzydong/go-chat/internal/pkg/core/middleware"
+	"github.com/gzydong/go-chat/internal/repository/model"
+	"github.com/gzydong/go-chat/internal/repository/repo"
+	"github.com/gzydong/go-chat/internal/service"
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+)
+
+type Broadcast struct {
+	BroadcastJobRepo       *repo.BroadcastJob
+	BroadcastRecipientRepo *repo.BroadcastRecipient
+	BroadcastService       service.IBroadcastService
+}
+
+// Create 创建群发任务
+func (b *Broadcast) Create(ctx context.Context, in *CreateRequest) (*CreateResponse, error) {
+	opt := &service.BroadcastCreateOpt{
+		AdminId:     middleware.FormContextAuthId[entity.AdminClaims](ctx),
+		SenderId:    in.SenderId,
+		UserIds:     in.UserIds,
+		GroupIds:    in.GroupIds,
+		AllContacts: in.AllContacts,
+		MsgType:     in.MsgType,
+		Content:     in.Content,
+		Rate:        in.Rate,
+	}
+
+	if in.Image != nil {
+		opt.Image = &model.TalkRecordExtraImage{
+			Url:    in.Image.Url,
+			Width:  in.Image.Width,
+			Height: in.Image.Height,
+			Size:   in.Image.Size,
+		}
+	}
+
+	job, err := b.BroadcastService.Create(ctx, opt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CreateResponse{Id: job.Id, TotalCount: job.TotalCount}, nil
+}
+
+// List 群发任务列表
+func (b *Broadcast) List(ctx context.Context, in *ListRequest) (*ListResponse, error) {
+	total, items, err := b.BroadcastJobRepo.Pagination(ctx, in.Page, in.PageSize, func(tx *gorm.DB) *gorm.DB {
+		if in.Status > 0 {
+			tx = tx.Where("status = ?", in.Status)
+		}
+
+		if in.SenderId > 0 {
+			tx = tx.Where("sender_id = ?", in.SenderId)
+		}
+
+		return tx.Order("id desc")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ListResponse{
+		Items: lo.Map(items, func(item *model.BroadcastJob, _ int) *JobItem {
+			return newJobItem(item)
+		}),
+		Total: total,
+	}, nil
+}
+
+// Detail 群发任务详情及进度
+func (b *Broadcast) Detail(ctx context.Context, in *DetailRequest) (*JobItem, error) {
+	job, err := b.BroadcastJobRepo.FindById(ctx, in.Id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorx.New(400, "群发任务不存在")
+		}
+
+		return nil, err
+	}
+
+	return newJobItem(job), nil
+}
+
+// Recipients 群发任务接收者列表，可按状态筛选发送失败的接收者
+func (b *Broadcast) Recipients(ctx context.Context, in *RecipientsRequest) (*RecipientsResponse, error) {
+	total, items, err := b.BroadcastRecipientRepo.Pagination(ctx, in.Page, in.PageSize, func(tx *gorm.DB) *gorm.DB {
+		tx = tx.Where("job_id = ?", in.Id)
+
+		if in.Status > 0 {
+			tx = tx.Where("status = ?", in.Status)
+		}
+
+		return tx.Order("id asc")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &RecipientsResponse{
+		Items: lo.Map(items, func(item *model.BroadcastRecipient, _ int) *RecipientItem {
+			return &RecipientItem{
+				Id:        item.Id,
+				TalkMode:  item.TalkMode,
+				ToFromId:  item.ToFromId,
+				MsgId:     item.MsgId,
+				Status:    item.Status,
+				Error:     item.Error,
+				UpdatedAt: item.UpdatedAt.Format(time.DateTime),
+			}
+		}),
+		Total: total,
+	}, nil
+}
+
+// Cancel 取消群发任务
+func (b *Broadcast) Cancel(ctx context.Context, in *CancelRequest) (*CancelResponse, error) {
+	if err := b.BroadcastService.Cancel(ctx, in.Id); err != nil {
+		return nil, err
+	}
+
+	return &CancelResponse{}, nil
+}
+
+func newJobItem(item *model.BroadcastJob) *JobItem {
+	value := &JobItem{
+		Id:             item.Id,
+		AdminId:        item.AdminId,
+		SenderId:       item.SenderId,
+		MsgType:        item.MsgType,
+		Rate:           item.Rate,
+		Status:         item.Status,
+		TotalCount:     item.TotalCount,
+		SentCount:      item.SentCount,
+		FailedCount:    item.FailedCount,
+		CancelledCount: item.CancelledCount,
+		CreatedAt:      item.CreatedAt.Format(time.DateTime),
+	}
+
+	if item.TotalCount > 0 {
+		value.Progress = (item.SentCount + item.FailedCount + item.CancelledCount) * 100 / item.TotalCount
+	}
+
+	if item.StartedAt.Valid {
+		value.StartedAt = item.StartedAt.Time.Format(time.DateTime)
+	}
+
+	if item.FinishedAt.Valid {
+		value.FinishedAt = item.FinishedAt.Time.Format(time.DateTime)
+	}
+
+	return value
+}
+
+type CreateImage struct {
+	Url    string `json:"url" binding:"required,url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	Size   int    `json:"size"`
+}
+
+type CreateRequest struct {
+	SenderId    int          `json:"sender_id" binding:"required"` // 发送者用户ID
+	UserIds     []int        `json:"user_ids"`
+	GroupIds    []int        `json:"group_ids"`
+	AllContacts bool         `json:"all_contacts"` // 同时发送给发送者的全部好友
+	MsgType     int          `json:"msg_type" binding:"required,oneof=1 3"`
+	Content     string       `json:"content" binding:"max=5000"`
+	Image       *CreateImage `json:"image"`
+	Rate        int          `json:"rate" binding:"omitempty,min=1,max=50"` // 每秒发送条数，默认10
+}
+
+type CreateResponse struct {
+	Id         int `json:"id"`
+	TotalCount int `json:"total_count"`
+}
+
+type ListRequest struct {
+	Status   int `json:"status" binding:"omitempty,oneof=1 2 3 4"`
+	SenderId int `json:"sender_id"`
+	Page     int `json:"page" binding:"required,min=1"`
+	PageSize int `json:"page_size" binding:"required,min=1,max=100"`
+}
+
+type JobItem struct {
+	Id             int    `json:"id"`
+	AdminId        int    `json:"admin_id"`
+	SenderId       int    `json:"sender_id"`
+	MsgType        int    `json:"msg_type"`
+	Rate           int    `json:"rate"`
+	Status         int    `json:"status"`
+	TotalCount     int    `json:"total_count"`
+	SentCount      int    `json:"sent_count"`
+	FailedCount    int    `json:"failed_count"`
+	CancelledCount int    `json:"cancelled_count"`
+	Progress       int    `json:"progress"` // 完成百分比
+	StartedAt      string `json:"started_at"`
+	FinishedAt     string `json:"finished_at"`
+	CreatedAt      string `json:"created_at"`
+}
+
+type ListResponse struct {
+	Items []*JobItem `json:"items"`
+	Total int64      `json:"total"`
+}
+
+type DetailRequest struct {
+	Id int `json:"id" binding:"required"`
+}
+
+type RecipientsRequest struct {
+	Id       int `json:"id" binding:"required"`
+	Status   int `json:"status" binding:"omitempty,oneof=1 2 3 4 5"`
+	Page     int `json:"page" binding:"required,min=1"`
+	PageSize int `json:"page_size" binding:"required,min=1,max=100"`
+}
+
+type RecipientItem struct {
+	Id        int    `json:"id"`
+	TalkMode  int    `json:"talk_mode"`
+	ToFromId  int    `json:"to_from_id"`
+	MsgId     string `json:"msg_id"`
+	Status    int    `json:"status"`
+	Error     string `json:"error"` // 失败原因
+	UpdatedAt string `json:"updated_at"`
+}
+
+type RecipientsResponse struct {
+	Items []*RecipientItem `json:"items"`
+	Total int64            `json:"total"`
+}
+
+type CancelRequest struct {
+	Id int `json:"id" binding:"required"`
+}
+
+type CancelResponse struct{}
@@ -0,0 +1,61 @@
+package group
+
+import (
+	"context"
+
+	"github.com/gzydong/go-chat/internal/repository/model"
+	"github.com/gzydong/go-chat/internal/repository/repo"
+	"github.com/gzydong/go-chat/internal/service"
+)
+
+type Group struct {
+	GroupRepo       *repo.Group
+	GroupMemberRepo *repo.GroupMember
+	GroupService    service.IGroupService
+}
+
+// Detail 群容量信息
+func (g *Group) Detail(ctx context.Context, in *DetailRequest) (*DetailResponse, error) {
+	group, err := g.GroupRepo.FindById(ctx, in.GroupId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DetailResponse{
+		GroupId:     group.Id,
+		Name:        group.Name,
+		IsDismiss:   group.IsDismiss,
+		MaxNum:      group.MemberLimit(),
+		MemberCount: g.GroupMemberRepo.CountMemberTotal(ctx, group.Id),
+		MaxNumTiers: model.GroupMaxNumTiers,
+	}, nil
+}
+
+// UpdateMaxNum 调整群成员数量上限
+func (g *Group) UpdateMaxNum(ctx context.Context, in *UpdateMaxNumRequest) (*UpdateMaxNumResponse, error) {
+	if err := g.GroupService.UpdateMaxNum(ctx, in.GroupId, in.MaxNum); err != nil {
+		return nil, err
+	}
+
+	return &UpdateMaxNumResponse{}, nil
+}
+
+type DetailRequest struct {
+	GroupId int `json:"group_id" binding:"required,gt=0"`
+}
+
+type DetailResponse struct {
+	GroupId     int    `json:"group_id"`
+	Name        string `json:"name"`
+	IsDismiss   int    `json:"is_dismiss"`
+	MaxNum      int    `json:"max_num"`
+	MemberCount int64  `json:"member_count"`
+	MaxNumTiers []int  `json:"max_num_tiers"` // 可选的群成员上限档位
+}
+
+type UpdateMaxNumRequest struct {
+	GroupId int `json:"group_id" binding:"required,gt=0"`
+	MaxNum  int `json:"max_num" binding:"required,gt=0"`
+}
+
+type UpdateMaxNumResponse struct{}
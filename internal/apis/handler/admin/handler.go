@@ -1,18 +1,27 @@
 package admin
 
 import (
+	"github.com/gzydong/go-chat/internal/apis/handler/admin/broadcast"
+	"github.com/gzydong/go-chat/internal/apis/handler/admin/group"
+	"github.com/gzydong/go-chat/internal/apis/handler/admin/moderation"
 	"github.com/gzydong/go-chat/internal/apis/handler/admin/system"
 	"github.com/gzydong/go-chat/internal/apis/handler/admin/user"
 	"github.com/gzydong/go-chat/internal/repository/repo"
 )
 
 type Handler struct {
-	Auth      *Auth
-	Totp      *Totp
-	Admin     *system.Admin
-	Role      *system.Role
-	Resource  *system.Resource
-	Menu      *system.Menu
-	AdminRepo *repo.Admin
-	User      *user.User
+	Auth       *Auth
+	Totp       *Totp
+	Admin      *system.Admin
+	Role       *system.Role
+	Resource   *system.Resource
+	Menu       *system.Menu
+	AdminRepo  *repo.Admin
+	User       *user.User
+	Moderation *moderation.Moderation
+	Report     *moderation.Report
+	Broadcast  *broadcast.Broadcast
+	Export     *moderation.Export
+	Import     *moderation.Import
+	Group      *group.Group
 }
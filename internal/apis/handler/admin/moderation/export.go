@@ -0,0 +1,161 @@
+package moderation
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gzydong/go-chat/internal/entity"
+	"github.com/gzydong/go-chat/internal/pkg/core/errorx"
+	"github.com/gzydong/go-chat/internal/pkg/core/middleware"
+	"github.com/gzydong/go-chat/internal/repository/model"
+	"github.com/gzydong/go-chat/internal/repository/repo"
+	"github.com/gzydong/go-chat/internal/service"
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+)
+
+type Export struct {
+	TalkExportRepo    *repo.TalkExport
+	TalkExportService service.ITalkExportService
+}
+
+// Create 导出指定用户的聊天记录，用于合规调查
+func (e *Export) Create(ctx context.Context, in *ExportCreateRequest) (*ExportCreateResponse, error) {
+	startTime, err := time.ParseInLocation(time.DateTime, in.StartTime, time.Local)
+	if err != nil {
+		return nil, errorx.New(400, "开始时间格式错误")
+	}
+
+	endTime, err := time.ParseInLocation(time.DateTime, in.EndTime, time.Local)
+	if err != nil {
+		return nil, errorx.New(400, "结束时间格式错误")
+	}
+
+	export, err := e.TalkExportService.Create(ctx, &service.TalkExportCreateOpt{
+		RequesterType: model.TalkExportRequesterAdmin,
+		RequesterId:   middleware.FormContextAuthId[entity.AdminClaims](ctx),
+		UserId:        in.UserId,
+		TalkMode:      in.TalkMode,
+		ToFromId:      in.ToFromId,
+		StartTime:     startTime,
+		EndTime:       endTime,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ExportCreateResponse{Id: export.Id}, nil
+}
+
+// List 聊天记录导出列表
+func (e *Export) List(ctx context.Context, in *ExportListRequest) (*ExportListResponse, error) {
+	total, items, err := e.TalkExportRepo.Pagination(ctx, in.Page, in.PageSize, func(tx *gorm.DB) *gorm.DB {
+		tx = tx.Where("requester_type = ?", model.TalkExportRequesterAdmin)
+
+		if in.UserId > 0 {
+			tx = tx.Where("user_id = ?", in.UserId)
+		}
+
+		if in.Status > 0 {
+			tx = tx.Where("status = ?", in.Status)
+		}
+
+		return tx.Order("id desc")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ExportListResponse{
+		Items: lo.Map(items, func(item *model.TalkExport, _ int) *ExportItem {
+			return &ExportItem{
+				Id:          item.Id,
+				AdminId:     item.RequesterId,
+				UserId:      item.UserId,
+				TalkMode:    item.TalkMode,
+				ToFromId:    item.ToFromId,
+				StartTime:   item.StartTime.Format(time.DateTime),
+				EndTime:     item.EndTime.Format(time.DateTime),
+				Status:      item.Status,
+				RecordCount: item.RecordCount,
+				FileSize:    item.FileSize,
+				Error:       item.Error,
+				ExpiredAt:   lo.Ternary(item.ExpiredAt.Valid, item.ExpiredAt.Time.Format(time.DateTime), ""),
+				CreatedAt:   item.CreatedAt.Format(time.DateTime),
+			}
+		}),
+		Total: total,
+	}, nil
+}
+
+// Download 获取导出文件下载地址
+func (e *Export) Download(ctx context.Context, in *ExportDownloadRequest) (*ExportDownloadResponse, error) {
+	export, err := e.TalkExportRepo.FindById(ctx, in.Id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorx.New(400, "导出任务不存在")
+		}
+
+		return nil, err
+	}
+
+	if export.RequesterType != model.TalkExportRequesterAdmin {
+		return nil, entity.ErrPermissionDenied
+	}
+
+	url, err := e.TalkExportService.DownloadUrl(export)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ExportDownloadResponse{Url: url}, nil
+}
+
+type ExportCreateRequest struct {
+	UserId    int    `json:"user_id" binding:"required"`
+	TalkMode  int    `json:"talk_mode" binding:"omitempty,oneof=1 2"` // 为空时导出全部类型的会话
+	ToFromId  int    `json:"to_from_id"`                              // 为空时导出时间范围内的全部会话
+	StartTime string `json:"start_time" binding:"required"`           // 格式 2006-01-02 15:04:05
+	EndTime   string `json:"end_time" binding:"required"`             // 格式 2006-01-02 15:04:05
+}
+
+type ExportCreateResponse struct {
+	Id int `json:"id"`
+}
+
+type ExportListRequest struct {
+	UserId   int `json:"user_id"`
+	Status   int `json:"status" binding:"omitempty,oneof=1 2 3 4"`
+	Page     int `json:"page" binding:"required,min=1"`
+	PageSize int `json:"page_size" binding:"required,min=1,max=100"`
+}
+
+type ExportItem struct {
+	Id          int    `json:"id"`
+	AdminId     int    `json:"admin_id"`
+	UserId      int    `json:"user_id"`
+	TalkMode    int    `json:"talk_mode"`
+	ToFromId    int    `json:"to_from_id"`
+	StartTime   string `json:"start_time"`
+	EndTime     string `json:"end_time"`
+	Status      int    `json:"status"`
+	RecordCount int    `json:"record_count"`
+	FileSize    int64  `json:"file_size"`
+	Error       string `json:"error"`
+	ExpiredAt   string `json:"expired_at"`
+	CreatedAt   string `json:"created_at"`
+}
+
+type ExportListResponse struct {
+	Items []*ExportItem `json:"items"`
+	Total int64         `json:"total"`
+}
+
+type ExportDownloadRequest struct {
+	Id int `json:"id" binding:"required"`
+}
+
+type ExportDownloadResponse struct {
+	Url string `json:"url"`
+}
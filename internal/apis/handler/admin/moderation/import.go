@@ -0,0 +1,46 @@
+package moderation
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gzydong/go-chat/internal/pkg/core/errorx"
+	"github.com/gzydong/go-chat/internal/pkg/filesystem"
+	"github.com/gzydong/go-chat/internal/pkg/jsonutil"
+	"github.com/gzydong/go-chat/internal/service"
+)
+
+const importMaxFileSize = 100 << 20 // 存档文件最大100M
+
+type Import struct {
+	TalkImportService service.ITalkImportService
+}
+
+// Import 从上传的存档导入聊天记录，dry_run 为 true 时仅校验并统计
+func (i *Import) Import(c *gin.Context, in *ImportRequest) (*service.TalkImportReport, error) {
+	file, err := c.FormFile("file")
+	if err != nil {
+		return nil, errorx.New(400, "file 字段必传")
+	}
+
+	if file.Size > importMaxFileSize {
+		return nil, errorx.New(400, "存档文件大小不能超过100M")
+	}
+
+	opt := &service.TalkImportOpt{DryRun: in.DryRun}
+	if in.Senders != "" {
+		if err := jsonutil.Unmarshal(in.Senders, &opt.Senders); err != nil {
+			return nil, errorx.New(400, "发送者映射格式错误")
+		}
+	}
+
+	opt.Data, err = filesystem.ReadMultipartStream(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.TalkImportService.Import(c.Request.Context(), opt)
+}
+
+type ImportRequest struct {
+	DryRun  bool   `form:"dry_run"`
+	Senders string `form:"senders"` // 发送者映射 JSON，格式为 {"原发送者ID": 用户ID}
+}
@@ -0,0 +1,256 @@
+package moderation
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gzydong/go-chat/internal/entity"
+	"github.com/gzydong/go-chat/internal/pkg/core/errorx"
+	"github.com/gzydong/go-chat/internal/pkg/core/middleware"
+	"github.com/gzydong/go-chat/internal/repository/model"
+	"github.com/gzydong/go-chat/internal/repository/repo"
+	"github.com/gzydong/go-chat/internal/service"
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+)
+
+type Moderation struct {
+	SensitiveWordRepo    *repo.SensitiveWord
+	ModerationRecordRepo *repo.ModerationRecord
+	ModerationService    service.IModerationService
+}
+
+// WordList 敏感词列表
+func (m *Moderation) WordList(ctx context.Context, in *WordListRequest) (*WordListResponse, error) {
+	total, items, err := m.SensitiveWordRepo.Pagination(ctx, in.Page, in.PageSize, func(tx *gorm.DB) *gorm.DB {
+		if in.Keyword != "" {
+			tx = tx.Where("word like ?", "%"+in.Keyword+"%")
+		}
+
+		if in.Action > 0 {
+			tx = tx.Where("action = ?", in.Action)
+		}
+
+		return tx.Order("id desc")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &WordListResponse{
+		Items: lo.Map(items, func(item *model.SensitiveWord, _ int) *WordItem {
+			return &WordItem{
+				Id:        item.Id,
+				Word:      item.Word,
+				Action:    item.Action,
+				Status:    item.Status,
+				UpdatedAt: item.UpdatedAt.Format(time.DateTime),
+			}
+		}),
+		Total: total,
+	}, nil
+}
+
+// WordSave 新增或编辑敏感词，保存后立即重新加载当前节点的词库
+func (m *Moderation) WordSave(ctx context.Context, in *WordSaveRequest) (*WordSaveResponse, error) {
+	in.Word = strings.TrimSpace(in.Word)
+	if in.Word == "" {
+		return nil, errorx.New(400, "敏感词不能为空")
+	}
+
+	exist, err := m.SensitiveWordRepo.FindByWhere(ctx, "word = ?", in.Word)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if exist != nil && exist.Id != in.Id {
+		return nil, errorx.New(400, "敏感词已存在")
+	}
+
+	now := time.Now()
+	if in.Id == 0 {
+		item := &model.SensitiveWord{
+			Word:      in.Word,
+			Action:    in.Action,
+			Status:    in.Status,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+
+		if err := m.SensitiveWordRepo.Create(ctx, item); err != nil {
+			return nil, err
+		}
+
+		in.Id = item.Id
+	} else {
+		_, err := m.SensitiveWordRepo.UpdateById(ctx, in.Id, map[string]any{
+			"word":       in.Word,
+			"action":     in.Action,
+			"status":     in.Status,
+			"updated_at": now,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := m.ModerationService.Reload(ctx); err != nil {
+		return nil, err
+	}
+
+	return &WordSaveResponse{Id: in.Id}, nil
+}
+
+// WordDelete 删除敏感词
+func (m *Moderation) WordDelete(ctx context.Context, in *WordDeleteRequest) (*WordDeleteResponse, error) {
+	if err := m.SensitiveWordRepo.Delete(ctx, in.Id); err != nil {
+		return nil, err
+	}
+
+	if err := m.ModerationService.Reload(ctx); err != nil {
+		return nil, err
+	}
+
+	return &WordDeleteResponse{}, nil
+}
+
+// ReviewList 审核队列
+func (m *Moderation) ReviewList(ctx context.Context, in *ReviewListRequest) (*ReviewListResponse, error) {
+	total, items, err := m.ModerationRecordRepo.Pagination(ctx, in.Page, in.PageSize, func(tx *gorm.DB) *gorm.DB {
+		tx = tx.Where("status = ?", lo.Ternary(in.Status == 0, model.ModerationStatusPending, in.Status))
+
+		if in.Scene != "" {
+			tx = tx.Where("scene = ?", in.Scene)
+		}
+
+		if in.UserId > 0 {
+			tx = tx.Where("user_id = ?", in.UserId)
+		}
+
+		return tx.Order("id asc")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReviewListResponse{
+		Items: lo.Map(items, func(item *model.ModerationRecord, _ int) *ReviewItem {
+			value := &ReviewItem{
+				Id:           item.Id,
+				Scene:        item.Scene,
+				UserId:       item.UserId,
+				TargetId:     item.TargetId,
+				TalkMode:     item.TalkMode,
+				ToFromId:     item.ToFromId,
+				MsgId:        item.MsgId,
+				Content:      item.Content,
+				Words:        item.Words,
+				Label:        item.Label,
+				Status:       item.Status,
+				ReviewerId:   item.ReviewerId,
+				ReviewRemark: item.ReviewRemark,
+				CreatedAt:    item.CreatedAt.Format(time.DateTime),
+			}
+
+			if item.ReviewedAt.Valid {
+				value.ReviewedAt = item.ReviewedAt.Time.Format(time.DateTime)
+			}
+
+			return value
+		}),
+		Total: total,
+	}, nil
+}
+
+// Review 审核内容
+func (m *Moderation) Review(ctx context.Context, in *ReviewRequest) (*ReviewResponse, error) {
+	err := m.ModerationService.Review(ctx, &service.ModerationReviewOpt{
+		Id:       in.Id,
+		AdminId:  middleware.FormContextAuthId[entity.AdminClaims](ctx),
+		Approved: in.Status == model.ModerationStatusApproved,
+		Remark:   in.Remark,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReviewResponse{}, nil
+}
+
+type WordListRequest struct {
+	Keyword  string `json:"keyword"`
+	Action   int    `json:"action"`
+	Page     int    `json:"page" binding:"required,min=1"`
+	PageSize int    `json:"page_size" binding:"required,min=1,max=100"`
+}
+
+type WordItem struct {
+	Id        int    `json:"id"`
+	Word      string `json:"word"`
+	Action    int    `json:"action"`
+	Status    int    `json:"status"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+type WordListResponse struct {
+	Items []*WordItem `json:"items"`
+	Total int64       `json:"total"`
+}
+
+type WordSaveRequest struct {
+	Id     int    `json:"id"`
+	Word   string `json:"word" binding:"required,max=64"`
+	Action int    `json:"action" binding:"required,oneof=1 2 3"` // 1:替换 2:审核 3:拒绝
+	Status int    `json:"status" binding:"required,oneof=1 2"`   // 1:启用 2:停用
+}
+
+type WordSaveResponse struct {
+	Id int `json:"id"`
+}
+
+type WordDeleteRequest struct {
+	Id int `json:"id" binding:"required"`
+}
+
+type WordDeleteResponse struct{}
+
+type ReviewListRequest struct {
+	Scene    string `json:"scene"`
+	UserId   int    `json:"user_id"`
+	Status   int    `json:"status" binding:"omitempty,oneof=1 2 3"` // 默认查询待审核
+	Page     int    `json:"page" binding:"required,min=1"`
+	PageSize int    `json:"page_size" binding:"required,min=1,max=100"`
+}
+
+type ReviewItem struct {
+	Id           int    `json:"id"`
+	Scene        string `json:"scene"`
+	UserId       int    `json:"user_id"`
+	TargetId     int    `json:"target_id"`
+	TalkMode     int    `json:"talk_mode"`
+	ToFromId     int    `json:"to_from_id"`
+	MsgId        string `json:"msg_id"`
+	Content      string `json:"content"`
+	Words        string `json:"words"`
+	Label        string `json:"label"`
+	Status       int    `json:"status"`
+	ReviewerId   int    `json:"reviewer_id"`
+	ReviewRemark string `json:"review_remark"`
+	ReviewedAt   string `json:"reviewed_at"`
+	CreatedAt    string `json:"created_at"`
+}
+
+type ReviewListResponse struct {
+	Items []*ReviewItem `json:"items"`
+	Total int64         `json:"total"`
+}
+
+type ReviewRequest struct {
+	Id     int    `json:"id" binding:"required"`
+	Status int    `json:"status" binding:"required,oneof=2 3"` // 2:通过 3:驳回
+	Remark string `json:"remark" binding:"max=255"`
+}
+
+type ReviewResponse struct{}
@@ -0,0 +1,192 @@
+package moderation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/gzydong/go-chat/internal/entity"
+	"github.com/gzydong/go-chat/internal/pkg/core/errorx"
+	"github.com/gzydong/go-chat/internal/pkg/core/middleware"
+	"github.com/gzydong/go-chat/internal/pkg/jsonutil"
+	"github.com/gzydong/go-chat/internal/repository/model"
+	"github.com/gzydong/go-chat/internal/repository/repo"
+	"github.com/gzydong/go-chat/internal/service"
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+)
+
+type Report struct {
+	ReportRepo      *repo.Report
+	ReportAuditRepo *repo.ReportAudit
+	ReportService   service.IReportService
+}
+
+// List 举报列表
+func (r *Report) List(ctx context.Context, in *ReportListRequest) (*ReportListResponse, error) {
+	total, items, err := r.ReportRepo.Pagination(ctx, in.Page, in.PageSize, func(tx *gorm.DB) *gorm.DB {
+		tx = tx.Where("status = ?", lo.Ternary(in.Status == 0, model.ReportStatusPending, in.Status))
+
+		if in.TargetType > 0 {
+			tx = tx.Where("target_type = ?", in.TargetType)
+		}
+
+		if in.TargetId > 0 {
+			tx = tx.Where("target_id = ?", in.TargetId)
+		}
+
+		if in.Reason > 0 {
+			tx = tx.Where("reason = ?", in.Reason)
+		}
+
+		if in.UserId > 0 {
+			tx = tx.Where("user_id = ?", in.UserId)
+		}
+
+		return tx.Order("id asc")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReportListResponse{
+		Items: lo.Map(items, func(item *model.Report, _ int) *ReportItem {
+			return newReportItem(item)
+		}),
+		Total: total,
+	}, nil
+}
+
+// Detail 举报详情，包含证据消息快照及处理记录
+func (r *Report) Detail(ctx context.Context, in *ReportDetailRequest) (*ReportDetailResponse, error) {
+	report, err := r.ReportRepo.FindById(ctx, in.Id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorx.New(400, "举报记录不存在")
+		}
+
+		return nil, err
+	}
+
+	audits, err := r.ReportAuditRepo.FindAllByReportId(ctx, report.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReportDetailResponse{
+		ReportItem: newReportItem(report),
+		Evidence:   json.RawMessage(lo.Ternary(report.Evidence == "", "[]", report.Evidence)),
+		Audits: lo.Map(audits, func(item *model.ReportAudit, _ int) *ReportAuditItem {
+			return &ReportAuditItem{
+				Id:        item.Id,
+				AdminId:   item.AdminId,
+				Action:    item.Action,
+				Remark:    item.Remark,
+				Detail:    item.Detail,
+				CreatedAt: item.CreatedAt.Format(time.DateTime),
+			}
+		}),
+	}, nil
+}
+
+// Handle 处理举报
+func (r *Report) Handle(ctx context.Context, in *ReportHandleRequest) (*ReportHandleResponse, error) {
+	err := r.ReportService.Handle(ctx, &service.ReportHandleOpt{
+		ReportId: in.Id,
+		AdminId:  middleware.FormContextAuthId[entity.AdminClaims](ctx),
+		Actions:  in.Actions,
+		Remark:   in.Remark,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReportHandleResponse{}, nil
+}
+
+func newReportItem(item *model.Report) *ReportItem {
+	value := &ReportItem{
+		Id:          item.Id,
+		UserId:      item.UserId,
+		TargetType:  item.TargetType,
+		TargetId:    item.TargetId,
+		TalkMode:    item.TalkMode,
+		ToFromId:    item.ToFromId,
+		MsgIds:      make([]string, 0),
+		Reason:      item.Reason,
+		Description: item.Description,
+		Images:      make([]string, 0),
+		Status:      item.Status,
+		HandlerId:   item.HandlerId,
+		CreatedAt:   item.CreatedAt.Format(time.DateTime),
+	}
+
+	_ = jsonutil.Unmarshal(item.MsgIds, &value.MsgIds)
+	_ = jsonutil.Unmarshal(item.Images, &value.Images)
+
+	if item.HandledAt.Valid {
+		value.HandledAt = item.HandledAt.Time.Format(time.DateTime)
+	}
+
+	return value
+}
+
+type ReportListRequest struct {
+	Status     int `json:"status" binding:"omitempty,oneof=1 2 3"` // 默认查询待处理
+	TargetType int `json:"target_type" binding:"omitempty,oneof=1 2 3"`
+	TargetId   int `json:"target_id"`
+	Reason     int `json:"reason"`
+	UserId     int `json:"user_id"`
+	Page       int `json:"page" binding:"required,min=1"`
+	PageSize   int `json:"page_size" binding:"required,min=1,max=100"`
+}
+
+type ReportItem struct {
+	Id          int      `json:"id"`
+	UserId      int      `json:"user_id"`
+	TargetType  int      `json:"target_type"`
+	TargetId    int      `json:"target_id"`
+	TalkMode    int      `json:"talk_mode"`
+	ToFromId    int      `json:"to_from_id"`
+	MsgIds      []string `json:"msg_ids"`
+	Reason      int      `json:"reason"`
+	Description string   `json:"description"`
+	Images      []string `json:"images"`
+	Status      int      `json:"status"`
+	HandlerId   int      `json:"handler_id"`
+	HandledAt   string   `json:"handled_at"`
+	CreatedAt   string   `json:"created_at"`
+}
+
+type ReportListResponse struct {
+	Items []*ReportItem `json:"items"`
+	Total int64         `json:"total"`
+}
+
+type ReportDetailRequest struct {
+	Id int `json:"id" binding:"required"`
+}
+
+type ReportAuditItem struct {
+	Id        int    `json:"id"`
+	AdminId   int    `json:"admin_id"`
+	Action    string `json:"action"`
+	Remark    string `json:"remark"`
+	Detail    string `json:"detail"`
+	CreatedAt string `json:"created_at"`
+}
+
+type ReportDetailResponse struct {
+	*ReportItem
+	Evidence json.RawMessage    `json:"evidence"` // 证据消息快照
+	Audits   []*ReportAuditItem `json:"audits"`   // 处理记录
+}
+
+type ReportHandleRequest struct {
+	Id      int      `json:"id" binding:"required"`
+	Actions []string `json:"actions" binding:"required,min=1,dive,oneof=ignore revoke_message mute_member disable_user dismiss_group"`
+	Remark  string   `json:"remark" binding:"max=255"`
+}
+
+type ReportHandleResponse struct{}
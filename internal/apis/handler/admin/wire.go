@@ -2,6 +2,9 @@ package admin
 
 import (
 	"github.com/google/wire"
+	"github.com/gzydong/go-chat/internal/apis/handler/admin/broadcast"
+	"github.com/gzydong/go-chat/internal/apis/handler/admin/group"
+	"github.com/gzydong/go-chat/internal/apis/handler/admin/moderation"
 	"github.com/gzydong/go-chat/internal/apis/handler/admin/system"
 	"github.com/gzydong/go-chat/internal/apis/handler/admin/user"
 )
@@ -14,4 +17,10 @@ var ProviderSet = wire.NewSet(
 	wire.Struct(new(system.Resource), "*"),
 	wire.Struct(new(system.Menu), "*"),
 	wire.Struct(new(user.User), "*"),
+	wire.Struct(new(moderation.Moderation), "*"),
+	wire.Struct(new(moderation.Report), "*"),
+	wire.Struct(new(broadcast.Broadcast), "*"),
+	wire.Struct(new(moderation.Export), "*"),
+	wire.Struct(new(moderation.Import), "*"),
+	wire.Struct(new(group.Group), "*"),
 )
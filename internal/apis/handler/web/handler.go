@@ -16,6 +16,15 @@ type V1 struct {
 	Organize     *v1.Organize
 	Talk         *talk.Session
 	TalkMessage  *talk.Message
+	TalkThread   *talk.Thread
+	TalkSearch   *talk.Search
+	TalkPin      *talk.Pin
+	TalkSchedule *talk.Schedule
+	TalkExpire   *talk.Expire
+	TalkSync     *talk.Sync
+	TalkCard     *talk.Card
+	TalkExport   *talk.Export
+	TalkFavorite *talk.Favorite
 	Emoticon     *v1.Emoticon
 	Upload       *v1.Upload
 	Trtc         *v1.Trtc
@@ -23,9 +32,15 @@ type V1 struct {
 	GroupNotice  *group.Notice
 	GroupApply   *group.Apply
 	GroupVote    *group.Vote
+	GroupMute    *group.Mute
+	GroupMember  *group.Member
+	GroupPerm    *group.Permission
+	GroupJoin    *group.Join
+	GroupSpam    *group.AntiSpam
 	Contact      *contact.Contact
 	ContactApply *contact.Apply
 	ContactGroup *contact.Group
+	ContactBlock *contact.Block
 	Article      *article.Article
 	ArticleAnnex *article.Annex
 	ArticleClass *article.Class
@@ -35,6 +50,7 @@ type V1 struct {
 	KYC          *v1.KYC
 	Wallet       *v1.Wallet
 	GroupRobot   *v1.GroupRobot
+	Report       *v1.Report
 }
 
 type Handler struct {
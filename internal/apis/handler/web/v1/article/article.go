@@ -29,6 +29,7 @@ type Article struct {
 	ArticleService      service.IArticleService
 	ArticleAnnexService service.IArticleAnnexService
 	Filesystem          filesystem.IFilesystem
+	ModerationService   service.IModerationService
 }
 
 // Edit 文章编辑接口
@@ -47,11 +48,20 @@ func (a *Article) Edit(ctx context.Context, in *web.ArticleEditRequest) (*web.Ar
 
 	uid := session.GetAuthID()
 
+	moderation, err := a.ModerationService.Check(ctx, &service.ModerationCheckOpt{
+		Scene:   model.ModerationSceneArticleTitle,
+		UserId:  uid,
+		Content: in.Title,
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	opt := &service.ArticleEditOpt{
 		UserId:    uid,
 		ArticleId: int(in.ArticleId),
 		ClassId:   int(in.ClassifyId),
-		Title:     in.Title,
+		Title:     moderation.Content,
 		MdContent: in.MdContent,
 	}
 
@@ -72,6 +82,13 @@ func (a *Article) Edit(ctx context.Context, in *web.ArticleEditRequest) (*web.Ar
 		return nil, err
 	}
 
+	a.ModerationService.Flag(ctx, &service.ModerationFlagOpt{
+		Scene:    model.ModerationSceneArticleTitle,
+		UserId:   uid,
+		TargetId: info.Id,
+		Result:   moderation,
+	})
+
 	return &web.ArticleEditResponse{
 		ArticleId: int32(info.Id),
 		Title:     info.Title,
@@ -43,6 +43,7 @@ type Auth struct {
 	Rsa                 rsautil.IRsa
 	OauthService        service.IOAuthService
 	AesUtil             aesutil.IAesUtil
+	ModerationService   service.IModerationService
 }
 
 // Login 登录
@@ -173,8 +174,16 @@ func (a *Auth) Register(ctx context.Context, in *web.AuthRegisterRequest) (*web.
 		return nil, err
 	}
 
+	moderation, err := a.ModerationService.Check(ctx, &service.ModerationCheckOpt{
+		Scene:   model.ModerationSceneNickname,
+		Content: in.Nickname,
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	user, err := a.UserService.Register(ctx, &service.UserRegisterOpt{
-		Nickname: in.Nickname,
+		Nickname: moderation.Content,
 		Mobile:   in.Mobile,
 		Email:    in.Email,
 		Password: string(password),
@@ -185,6 +194,13 @@ func (a *Auth) Register(ctx context.Context, in *web.AuthRegisterRequest) (*web.
 		return nil, err
 	}
 
+	a.ModerationService.Flag(ctx, &service.ModerationFlagOpt{
+		Scene:    model.ModerationSceneNickname,
+		UserId:   user.Id,
+		TargetId: user.Id,
+		Result:   moderation,
+	})
+
 	// 使用邀请码（如果提供了）
 	if in.InviteCode != "" {
 		if err := a.InviteCodeService.UseInviteCode(ctx, in.InviteCode, user.Id); err != nil {
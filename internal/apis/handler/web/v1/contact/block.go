@@ -0,0 +1,109 @@
+package contact
+
+import (
+	"context"
+	"time"
+
+	"github.com/gzydong/go-chat/internal/entity"
+	"github.com/gzydong/go-chat/internal/pkg/core/middleware"
+	"github.com/gzydong/go-chat/internal/repository/model"
+	"github.com/gzydong/go-chat/internal/service"
+	"github.com/samber/lo"
+)
+
+type Block struct {
+	UserBlockService service.IUserBlockService
+}
+
+// Create 拉黑用户
+//
+//	@Summary		拉黑用户
+//	@Description	将用户加入黑名单，拉黑后双方无法私聊、添加好友，对方无法邀请你入群
+//	@Tags			联系人
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		contact.BlockCreateRequest	true	"拉黑用户请求"
+//	@Success		200		{object}	contact.BlockCreateResponse
+//	@Router			/api/v1/contact/block/create [post]
+//	@Security		Bearer
+func (b *Block) Create(ctx context.Context, in *BlockCreateRequest) (*BlockCreateResponse, error) {
+	uid := middleware.FormContextAuthId[entity.WebClaims](ctx)
+	if err := b.UserBlockService.Block(ctx, uid, in.UserId); err != nil {
+		return nil, err
+	}
+
+	return &BlockCreateResponse{}, nil
+}
+
+// Delete 取消拉黑
+//
+//	@Summary		取消拉黑
+//	@Description	将用户移出黑名单
+//	@Tags			联系人
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		contact.BlockDeleteRequest	true	"取消拉黑请求"
+//	@Success		200		{object}	contact.BlockDeleteResponse
+//	@Router			/api/v1/contact/block/delete [post]
+//	@Security		Bearer
+func (b *Block) Delete(ctx context.Context, in *BlockDeleteRequest) (*BlockDeleteResponse, error) {
+	uid := middleware.FormContextAuthId[entity.WebClaims](ctx)
+	if err := b.UserBlockService.Unblock(ctx, uid, in.UserId); err != nil {
+		return nil, err
+	}
+
+	return &BlockDeleteResponse{}, nil
+}
+
+// List 黑名单列表
+//
+//	@Summary		黑名单列表
+//	@Description	获取当前用户的黑名单，按拉黑时间倒序
+//	@Tags			联系人
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	contact.BlockListResponse
+//	@Router			/api/v1/contact/block/list [post]
+//	@Security		Bearer
+func (b *Block) List(ctx context.Context, _ *BlockListRequest) (*BlockListResponse, error) {
+	list, err := b.UserBlockService.List(ctx, middleware.FormContextAuthId[entity.WebClaims](ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	items := lo.Map(list, func(item *model.UserBlockItem, _ int) *BlockItem {
+		return &BlockItem{
+			UserId:    item.UserId,
+			Nickname:  item.Nickname,
+			Avatar:    item.Avatar,
+			CreatedAt: item.CreatedAt.Format(time.DateTime),
+		}
+	})
+
+	return &BlockListResponse{Items: items}, nil
+}
+
+type BlockCreateRequest struct {
+	UserId int `json:"user_id" binding:"required,gt=0"`
+}
+
+type BlockCreateResponse struct{}
+
+type BlockDeleteRequest struct {
+	UserId int `json:"user_id" binding:"required,gt=0"`
+}
+
+type BlockDeleteResponse struct{}
+
+type BlockListRequest struct{}
+
+type BlockItem struct {
+	UserId    int    `json:"user_id"`
+	Nickname  string `json:"nickname"`
+	Avatar    string `json:"avatar"`
+	CreatedAt string `json:"created_at"`
+}
+
+type BlockListResponse struct {
+	Items []*BlockItem `json:"items"`
+}
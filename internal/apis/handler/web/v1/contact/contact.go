@@ -28,6 +28,7 @@ type Contact struct {
 	TalkListService service.ITalkSessionService
 	Message         message2.IService
 	UserClient      *cache.UserClient
+	UserBlockRepo   *repo.UserBlock
 }
 
 // List 联系人列表接口
@@ -155,9 +156,12 @@ func (c *Contact) Detail(ctx context.Context, in *web.ContactDetailRequest) (*we
 		return resp, nil
 	}
 
+	// 存在拉黑关系时不展示在线状态
+	isBlocked := c.UserBlockRepo.IsEitherBlocked(ctx, uid, user.Id)
+
 	isQiYeMember, _ := c.OrganizeRepo.IsQiyeMember(ctx, uid, user.Id)
 	if isQiYeMember {
-		if c.UserClient.IsOnline(ctx, int64(in.UserId)) {
+		if !isBlocked && c.UserClient.IsOnline(ctx, int64(in.UserId)) {
 			resp.OnlineStatus = "Y"
 		}
 
@@ -176,7 +180,7 @@ func (c *Contact) Detail(ctx context.Context, in *web.ContactDetailRequest) (*we
 		resp.ContactGroupId = int32(contact.GroupId)
 		resp.ContactRemark = contact.Remark
 
-		if c.UserClient.IsOnline(ctx, int64(in.UserId)) {
+		if !isBlocked && c.UserClient.IsOnline(ctx, int64(in.UserId)) {
 			resp.OnlineStatus = "Y"
 		}
 	}
@@ -253,7 +257,7 @@ func (c *Contact) OnlineStatus(ctx context.Context, in *web.ContactOnlineStatusR
 
 	uid := middleware.FormContextAuthId[entity.WebClaims](ctx)
 	ok := c.ContactRepo.IsFriend(ctx, uid, int(in.UserId), true)
-	if ok && c.UserClient.IsOnline(ctx, int64(in.UserId)) {
+	if ok && !c.UserBlockRepo.IsEitherBlocked(ctx, uid, int(in.UserId)) && c.UserClient.IsOnline(ctx, int64(in.UserId)) {
 		resp.OnlineStatus = "Y"
 	}
 
@@ -0,0 +1,101 @@
+package group
+
+import (
+	"context"
+
+	"github.com/gzydong/go-chat/internal/entity"
+	"github.com/gzydong/go-chat/internal/pkg/core/middleware"
+	"github.com/gzydong/go-chat/internal/repository/model"
+	"github.com/gzydong/go-chat/internal/service"
+)
+
+type AntiSpam struct {
+	GroupAntiSpamService service.IGroupAntiSpamService
+}
+
+// Detail 群防刷屏设置
+//
+//	@Summary		群防刷屏设置
+//	@Description	获取慢速模式、消息长度限制、链接及文件限制和刷屏自动禁言设置
+//	@Tags			群组
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		group.AntiSpamDetailRequest	true	"群防刷屏设置请求"
+//	@Success		200		{object}	group.AntiSpamDetailResponse
+//	@Router			/api/v1/group/anti-spam/detail [post]
+//	@Security		Bearer
+func (a *AntiSpam) Detail(ctx context.Context, in *AntiSpamDetailRequest) (*AntiSpamDetailResponse, error) {
+	uid := middleware.FormContextAuthId[entity.WebClaims](ctx)
+
+	setting, err := a.GroupAntiSpamService.GetSetting(ctx, in.GroupId, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AntiSpamDetailResponse{
+		SlowMode:    setting.SlowMode,
+		MaxLength:   setting.MaxLength,
+		BlockLink:   setting.BlockLink,
+		BlockFile:   setting.BlockFile,
+		FloodLimit:  setting.FloodLimit,
+		FloodWindow: setting.FloodWindow,
+		FloodMute:   setting.FloodMute,
+	}, nil
+}
+
+// Update 修改群防刷屏设置
+//
+//	@Summary		修改群防刷屏设置
+//	@Description	设置仅对普通成员生效的慢速模式、消息长度限制、链接及文件限制和刷屏自动禁言
+//	@Tags			群组
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		group.AntiSpamUpdateRequest	true	"修改群防刷屏设置请求"
+//	@Success		200		{object}	group.AntiSpamUpdateResponse
+//	@Router			/api/v1/group/anti-spam/update [post]
+//	@Security		Bearer
+func (a *AntiSpam) Update(ctx context.Context, in *AntiSpamUpdateRequest) (*AntiSpamUpdateResponse, error) {
+	uid := middleware.FormContextAuthId[entity.WebClaims](ctx)
+
+	err := a.GroupAntiSpamService.UpdateSetting(ctx, in.GroupId, uid, &model.GroupAntiSpam{
+		SlowMode:    in.SlowMode,
+		MaxLength:   in.MaxLength,
+		BlockLink:   in.BlockLink,
+		BlockFile:   in.BlockFile,
+		FloodLimit:  in.FloodLimit,
+		FloodWindow: in.FloodWindow,
+		FloodMute:   in.FloodMute,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &AntiSpamUpdateResponse{}, nil
+}
+
+type AntiSpamDetailRequest struct {
+	GroupId int `json:"group_id" binding:"required,gt=0"`
+}
+
+type AntiSpamDetailResponse struct {
+	SlowMode    int  `json:"slow_mode"`    // 慢速模式间隔(秒)，0 表示关闭
+	MaxLength   int  `json:"max_length"`   // 文本消息最大长度，0 表示不限制
+	BlockLink   bool `json:"block_link"`   // 禁止发送链接
+	BlockFile   bool `json:"block_file"`   // 禁止发送文件
+	FloodLimit  int  `json:"flood_limit"`  // 刷屏阈值，0 表示关闭
+	FloodWindow int  `json:"flood_window"` // 刷屏统计时间窗口(秒)
+	FloodMute   int  `json:"flood_mute"`   // 刷屏自动禁言时长(秒)，0 表示使用默认时长
+}
+
+type AntiSpamUpdateRequest struct {
+	GroupId     int  `json:"group_id" binding:"required,gt=0"`
+	SlowMode    int  `json:"slow_mode" binding:"min=0"`
+	MaxLength   int  `json:"max_length" binding:"min=0"`
+	BlockLink   bool `json:"block_link"`
+	BlockFile   bool `json:"block_file"`
+	FloodLimit  int  `json:"flood_limit" binding:"min=0"`
+	FloodWindow int  `json:"flood_window" binding:"min=0"`
+	FloodMute   int  `json:"flood_mute" binding:"min=0"`
+}
+
+type AntiSpamUpdateResponse struct{}
@@ -6,9 +6,7 @@ import (
 
 	"github.com/gzydong/go-chat/api/pb/web/v1"
 	"github.com/gzydong/go-chat/internal/entity"
-	"github.com/gzydong/go-chat/internal/logic"
 	"github.com/gzydong/go-chat/internal/pkg/core/middleware"
-	"github.com/gzydong/go-chat/internal/pkg/jsonutil"
 	"github.com/gzydong/go-chat/internal/pkg/sliceutil"
 	"github.com/gzydong/go-chat/internal/pkg/timeutil"
 	"github.com/gzydong/go-chat/internal/repository/cache"
@@ -30,13 +28,13 @@ type Apply struct {
 	GroupApplyService  service.IGroupApplyService
 	GroupMemberService service.IGroupMemberService
 	GroupService       service.IGroupService
-	PushMessage        *logic.PushMessage
+	GroupJoinService   service.IGroupJoinService
 }
 
 // Create 创建群组申请接口
 //
 //	@Summary		申请入群
-//	@Description	申请加入群聊
+//	@Description	申请加入群聊，自由加入的群直接入群，需审核的群提交入群申请
 //	@Tags			群申请
 //	@Accept			json
 //	@Produce		json
@@ -47,53 +45,16 @@ type Apply struct {
 func (a Apply) Create(ctx context.Context, in *web.GroupApplyCreateRequest) (*web.GroupApplyCreateResponse, error) {
 	uid := middleware.FormContextAuthId[entity.WebClaims](ctx)
 
-	apply, err := a.GroupApplyRepo.FindByWhere(ctx, "group_id = ? and user_id = ? and status = ?", in.GroupId, uid, model.GroupApplyStatusWait)
-	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		return nil, err
-	}
-
-	applyId := 0
-	if apply == nil {
-		data := &model.GroupApply{
-			GroupId: int(in.GroupId),
-			UserId:  uid,
-			Status:  model.GroupApplyStatusWait,
-			Remark:  in.Remark,
-		}
-
-		err = a.GroupApplyRepo.Create(ctx, data)
-		if err == nil {
-			applyId = data.Id
-		}
-	} else {
-		applyId = apply.Id
-		data := map[string]any{
-			"remark":     in.Remark,
-			"updated_at": timeutil.DateTime(),
-		}
-
-		_, err = a.GroupApplyRepo.UpdateByWhere(ctx, data, "id = ?", apply.Id)
-	}
-
+	_, err := a.GroupJoinService.Join(ctx, &service.GroupJoinOpt{
+		UserId:  uid,
+		GroupId: int(in.GroupId),
+		Remark:  in.Remark,
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	find, err := a.GroupMemberRepo.FindByWhere(ctx, "group_id = ? and leader = ?", in.GroupId, model.GroupMemberLeaderOwner)
-	if err == nil && find != nil {
-		a.GroupApplyStorage.Incr(ctx, find.UserId)
-	}
-
-	_ = a.PushMessage.Push(ctx, entity.ImChannelChat, &entity.SubscribeMessage{
-		Event: entity.SubEventGroupApply,
-		Payload: jsonutil.Encode(entity.SubEventGroupApplyPayload{
-			GroupId: int(in.GroupId),
-			UserId:  uid,
-			ApplyId: applyId,
-		}),
-	})
-
-	return &web.GroupApplyCreateResponse{}, err
+	return &web.GroupApplyCreateResponse{}, nil
 }
 
 // Delete 删除群组申请接口
@@ -147,6 +108,7 @@ func (a Apply) Agree(ctx context.Context, in *web.GroupApplyAgreeRequest) (*web.
 			UserId:    uid,
 			GroupId:   apply.GroupId,
 			MemberIds: []int{apply.UserId},
+			IsApply:   true,
 		})
 
 		if err != nil {
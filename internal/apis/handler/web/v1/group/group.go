@@ -35,10 +35,13 @@ type Group struct {
 	TalkSessionRepo    *repo.TalkSession
 	GroupService       service.IGroupService
 	GroupMemberService service.IGroupMemberService
+	GroupMuteService   service.IGroupMuteService
+	GroupPermission    service.IGroupPermissionService
 	TalkSessionService service.ITalkSessionService
 	UserService        service.IUserService
 	ContactService     service.IContactService
 	Message            message.IService
+	ModerationService  service.IModerationService
 }
 
 // List 群列表接口
@@ -105,9 +108,18 @@ func (g Group) Create(ctx context.Context, in *web.GroupCreateRequest) (*web.Gro
 		return nil, errorx.New(400, fmt.Sprintf("群成员数量已达到%d上限！", model.GroupMemberMaxNum))
 	}
 
+	moderation, err := g.ModerationService.Check(ctx, &service.ModerationCheckOpt{
+		Scene:   model.ModerationSceneGroupName,
+		UserId:  uid,
+		Content: in.Name,
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	gid, err := g.GroupService.Create(ctx, &service.GroupCreateOpt{
 		UserId:    uid,
-		Name:      in.Name,
+		Name:      moderation.Content,
 		MemberIds: uids,
 	})
 
@@ -115,6 +127,13 @@ func (g Group) Create(ctx context.Context, in *web.GroupCreateRequest) (*web.Gro
 		return nil, err
 	}
 
+	g.ModerationService.Flag(ctx, &service.ModerationFlagOpt{
+		Scene:    model.ModerationSceneGroupName,
+		UserId:   uid,
+		TargetId: gid,
+		Result:   moderation,
+	})
+
 	return &web.GroupCreateResponse{GroupId: int32(gid)}, nil
 }
 
@@ -149,7 +168,7 @@ func (g Group) Detail(ctx context.Context, in *web.GroupDetailRequest) (*web.Gro
 		CreatedAt: timeutil.FormatDatetime(groupInfo.CreatedAt),
 		IsManager: uid == groupInfo.CreatorId,
 		IsDisturb: 0,
-		IsMute:    int32(groupInfo.IsMute),
+		IsMute:    int32(lo.Ternary(groupInfo.IsMuted(time.Now()), model.Yes, model.No)),
 		IsOvert:   int32(groupInfo.IsOvert),
 		VisitCard: g.GroupMemberRepo.GetMemberRemark(ctx, int(in.GroupId), uid),
 		Notice: &web.GroupDetailResponse_Notice{
@@ -209,6 +228,7 @@ func (g Group) MemberList(ctx context.Context, in *web.GroupMemberListRequest) (
 
 	list := g.GroupMemberRepo.GetMembers(ctx, int(in.GroupId))
 
+	now := time.Now()
 	items := make([]*web.GroupMemberListResponse_Item, 0)
 	for _, item := range list {
 		items = append(items, &web.GroupMemberListResponse_Item{
@@ -217,7 +237,7 @@ func (g Group) MemberList(ctx context.Context, in *web.GroupMemberListRequest) (
 			Avatar:   item.Avatar,
 			Gender:   int32(item.Gender),
 			Leader:   int32(item.Leader),
-			IsMute:   int32(item.IsMute),
+			IsMute:   int32(lo.Ternary(model.IsMuteActive(item.IsMute, item.MuteExpireAt, now), model.Yes, model.No)),
 			Remark:   item.UserCard,
 			Motto:    item.Motto,
 		})
@@ -282,10 +302,6 @@ func (g Group) Invite(ctx context.Context, in *web.GroupInviteRequest) (*web.Gro
 		return nil, errorx.New(400, "邀请好友列表不能为空")
 	}
 
-	if len(uids) > model.GroupMemberMaxNum {
-		return nil, errorx.New(400, fmt.Sprintf("当前群成员数量已达到%d上限！", model.GroupMemberMaxNum))
-	}
-
 	key := fmt.Sprintf("group_join:%d", in.GroupId)
 	if !g.RedisLock.Lock(ctx, key, 20) {
 		return nil, entity.ErrTooFrequentOperation
@@ -293,28 +309,10 @@ func (g Group) Invite(ctx context.Context, in *web.GroupInviteRequest) (*web.Gro
 
 	defer g.RedisLock.UnLock(ctx, key)
 
-	if !g.GroupMemberRepo.IsMember(ctx, int(in.GroupId), uid, true) {
-		return nil, entity.ErrPermissionDenied
-	}
-
-	group, err := g.GroupRepo.FindById(ctx, int(in.GroupId))
-	if err != nil {
+	if err := g.GroupPermission.Check(ctx, int(in.GroupId), uid, model.GroupPermInvite); err != nil {
 		return nil, err
 	}
 
-	if group != nil && group.IsDismiss == model.Yes {
-		return nil, entity.ErrGroupDismissed
-	}
-
-	count, err := g.GroupMemberRepo.FindCount(ctx, "group_id = ? and is_quit = ?", in.GroupId, model.No)
-	if err != nil {
-		return nil, err
-	}
-
-	if int(count)+len(uids) >= model.GroupMemberMaxNum {
-		return nil, entity.ErrGroupMemberLimit
-	}
-
 	if err := g.GroupService.Invite(ctx, &service.GroupInviteOpt{
 		UserId:    uid,
 		GroupId:   int(in.GroupId),
@@ -422,16 +420,24 @@ func (g Group) Secede(ctx context.Context, in *web.GroupSecedeRequest) (*web.Gro
 //	@Security		Bearer
 func (g Group) Setting(ctx context.Context, req *web.GroupSettingRequest) (*web.GroupSettingResponse, error) {
 	uid := middleware.FormContextAuthId[entity.WebClaims](ctx)
-	
-	// Check if user is the group master (creator/owner)
-	if !g.GroupMemberRepo.IsMaster(ctx, int(req.GroupId), uid) {
-		return nil, entity.ErrPermissionDenied
+
+	if err := g.GroupPermission.Check(ctx, int(req.GroupId), uid, model.GroupPermEditProfile); err != nil {
+		return nil, err
+	}
+
+	moderation, err := g.ModerationService.Check(ctx, &service.ModerationCheckOpt{
+		Scene:   model.ModerationSceneGroupName,
+		UserId:  uid,
+		Content: req.GroupName,
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// Prepare update data
 	data := make(map[string]any)
 	if len(req.GroupName) > 0 {
-		data["name"] = req.GroupName
+		data["name"] = moderation.Content
 	}
 	if len(req.Avatar) > 0 {
 		data["avatar"] = req.Avatar
@@ -442,11 +448,18 @@ func (g Group) Setting(ctx context.Context, req *web.GroupSettingRequest) (*web.
 	data["updated_at"] = time.Now()
 
 	// Update group settings
-	_, err := g.GroupRepo.UpdateById(ctx, int(req.GroupId), data)
+	_, err = g.GroupRepo.UpdateById(ctx, int(req.GroupId), data)
 	if err != nil {
 		return nil, err
 	}
 
+	g.ModerationService.Flag(ctx, &service.ModerationFlagOpt{
+		Scene:    model.ModerationSceneGroupName,
+		UserId:   uid,
+		TargetId: int(req.GroupId),
+		Result:   moderation,
+	})
+
 	return &web.GroupSettingResponse{}, nil
 }
 
@@ -675,44 +688,21 @@ func (g Group) NoSpeak(ctx context.Context, in *web.GroupNoSpeakRequest) (*web.G
 		return nil, entity.ErrPermissionDenied
 	}
 
-	status := lo.Ternary(in.Action == 1, model.Yes, model.No)
-
-	err := g.GroupMemberService.SetMuteStatus(ctx, int(in.GroupId), int(in.UserId), status)
-	if err != nil {
-		return nil, err
+	var err error
+	if in.Action == 1 {
+		err = g.GroupMuteService.Mute(ctx, &service.GroupMuteOpt{
+			OperatorId: uid,
+			GroupId:    int(in.GroupId),
+			UserId:     int(in.UserId),
+		})
+	} else {
+		err = g.GroupMuteService.Unmute(ctx, uid, int(in.GroupId), int(in.UserId))
 	}
 
-	members := make([]model.TalkRecordExtraGroupMember, 0)
-	g.Repo.Db().Model(&model.Users{}).Select("id as user_id", "nickname").Where("id = ?", in.UserId).Scan(&members)
-
-	user, err := g.UsersRepo.FindByIdWithCache(ctx, uid)
 	if err != nil {
 		return nil, err
 	}
 
-	data := message.CreateGroupMessageOption{
-		FromId:   uid,
-		ToFromId: int(in.GroupId),
-	}
-
-	if status == model.Yes {
-		data.MsgType = entity.ChatMsgSysGroupMemberMuted
-		data.Extra = jsonutil.Encode(model.TalkRecordExtraGroupMemberCancelMuted{
-			OwnerId:   uid,
-			OwnerName: user.Nickname,
-			Members:   members,
-		})
-	} else {
-		data.MsgType = entity.ChatMsgSysGroupMemberCancelMuted
-		data.Extra = jsonutil.Encode(model.TalkRecordExtraGroupMemberCancelMuted{
-			OwnerId:   uid,
-			OwnerName: user.Nickname,
-			Members:   members,
-		})
-	}
-
-	_ = g.Message.CreateGroupMessage(ctx, data)
-
 	return &web.GroupNoSpeakResponse{}, nil
 }
 
@@ -744,48 +734,19 @@ func (g Group) Mute(ctx context.Context, in *web.GroupMuteRequest) (*web.GroupMu
 		return nil, entity.ErrPermissionDenied
 	}
 
-	data := map[string]any{
-		"is_mute":    in.Action,
-		"updated_at": time.Now(),
-	}
-
-	affected, err := g.GroupRepo.UpdateByWhere(ctx, data, "id = ?", in.GroupId)
-	if err != nil {
-		return nil, err
-	}
-
-	if affected == 0 {
-		return &web.GroupMuteResponse{}, nil
+	if in.Action == model.Yes {
+		err = g.GroupMuteService.Mute(ctx, &service.GroupMuteOpt{
+			OperatorId: uid,
+			GroupId:    int(in.GroupId),
+		})
+	} else {
+		err = g.GroupMuteService.Unmute(ctx, uid, int(in.GroupId), 0)
 	}
 
-	user, err := g.UsersRepo.FindById(ctx, uid)
 	if err != nil {
 		return nil, err
 	}
 
-	var extra any
-	var msgType int
-	if in.Action == model.Yes {
-		msgType = entity.ChatMsgSysGroupMuted
-		extra = model.TalkRecordExtraGroupMuted{
-			OwnerId:   user.Id,
-			OwnerName: user.Nickname,
-		}
-	} else {
-		msgType = entity.ChatMsgSysGroupCancelMuted
-		extra = model.TalkRecordExtraGroupCancelMuted{
-			OwnerId:   user.Id,
-			OwnerName: user.Nickname,
-		}
-	}
-
-	_ = g.Message.CreateGroupMessage(ctx, message.CreateGroupMessageOption{
-		MsgType:  msgType,
-		FromId:   uid,
-		ToFromId: int(in.GroupId),
-		Extra:    jsonutil.Encode(extra),
-	})
-
 	return &web.GroupMuteResponse{}, nil
 }
 
@@ -0,0 +1,342 @@
+package group
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gzydong/go-chat/internal/entity"
+	"github.com/gzydong/go-chat/internal/pkg/core/errorx"
+	"github.com/gzydong/go-chat/internal/pkg/core/middleware"
+	"github.com/gzydong/go-chat/internal/pkg/jsonutil"
+	"github.com/gzydong/go-chat/internal/pkg/timeutil"
+	"github.com/gzydong/go-chat/internal/repository/cache"
+	"github.com/gzydong/go-chat/internal/repository/model"
+	"github.com/gzydong/go-chat/internal/repository/repo"
+	"github.com/gzydong/go-chat/internal/service"
+	"github.com/samber/lo"
+)
+
+type Join struct {
+	RedisLock        *cache.RedisLock
+	GroupMemberRepo  *repo.GroupMember
+	GroupJoinService service.IGroupJoinService
+}
+
+// LinkCreate 创建群邀请链接
+//
+//	@Summary		创建群邀请链接
+//	@Description	创建可分享的群邀请链接，可设置有效期及最大使用次数
+//	@Tags			群组
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		group.JoinLinkCreateRequest	true	"创建群邀请链接请求"
+//	@Success		200		{object}	group.JoinLinkItem
+//	@Router			/api/v1/group/invite-link/create [post]
+//	@Security		Bearer
+func (j *Join) LinkCreate(ctx context.Context, in *JoinLinkCreateRequest) (*JoinLinkItem, error) {
+	uid := middleware.FormContextAuthId[entity.WebClaims](ctx)
+
+	link, err := j.GroupJoinService.CreateLink(ctx, &service.GroupInviteLinkCreateOpt{
+		UserId:   uid,
+		GroupId:  in.GroupId,
+		Expire:   time.Duration(in.ExpireSeconds) * time.Second,
+		MaxUsage: in.MaxUsage,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toJoinLinkItem(link), nil
+}
+
+// LinkList 群邀请链接列表
+//
+//	@Summary		群邀请链接列表
+//	@Description	获取群有效的邀请链接，群主及管理员可查看全部链接
+//	@Tags			群组
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		group.JoinLinkListRequest	true	"群邀请链接列表请求"
+//	@Success		200		{object}	group.JoinLinkListResponse
+//	@Router			/api/v1/group/invite-link/list [post]
+//	@Security		Bearer
+func (j *Join) LinkList(ctx context.Context, in *JoinLinkListRequest) (*JoinLinkListResponse, error) {
+	uid := middleware.FormContextAuthId[entity.WebClaims](ctx)
+
+	list, err := j.GroupJoinService.ListLinks(ctx, in.GroupId, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	return &JoinLinkListResponse{
+		Items: lo.Map(list, func(item *model.GroupInviteLink, _ int) *JoinLinkItem {
+			return toJoinLinkItem(item)
+		}),
+	}, nil
+}
+
+// LinkRevoke 撤销群邀请链接
+//
+//	@Summary		撤销群邀请链接
+//	@Description	撤销后链接及对应二维码立即失效
+//	@Tags			群组
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		group.JoinLinkRevokeRequest	true	"撤销群邀请链接请求"
+//	@Success		200		{object}	group.JoinLinkRevokeResponse
+//	@Router			/api/v1/group/invite-link/revoke [post]
+//	@Security		Bearer
+func (j *Join) LinkRevoke(ctx context.Context, in *JoinLinkRevokeRequest) (*JoinLinkRevokeResponse, error) {
+	uid := middleware.FormContextAuthId[entity.WebClaims](ctx)
+
+	if err := j.GroupJoinService.RevokeLink(ctx, in.LinkId, uid); err != nil {
+		return nil, err
+	}
+
+	return &JoinLinkRevokeResponse{}, nil
+}
+
+// Qrcode 群二维码内容
+//
+//	@Summary		群二维码内容
+//	@Description	获取邀请链接对应的二维码内容，由客户端生成二维码图片
+//	@Tags			群组
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		group.JoinQrcodeRequest	true	"群二维码内容请求"
+//	@Success		200		{object}	group.JoinQrcodeResponse
+//	@Router			/api/v1/group/invite-link/qrcode [post]
+//	@Security		Bearer
+func (j *Join) Qrcode(ctx context.Context, in *JoinQrcodeRequest) (*JoinQrcodeResponse, error) {
+	uid := middleware.FormContextAuthId[entity.WebClaims](ctx)
+
+	link, group, err := j.GroupJoinService.FindLink(ctx, in.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	if !j.GroupMemberRepo.IsMember(ctx, group.Id, uid, true) {
+		return nil, entity.ErrPermissionDenied
+	}
+
+	return &JoinQrcodeResponse{
+		Token: link.Token,
+		Content: jsonutil.Encode(map[string]any{
+			"type":  "group_invite",
+			"token": link.Token,
+		}),
+		ExpireAt: formatExpireAt(link.ExpireAt),
+	}, nil
+}
+
+// Info 邀请链接预览
+//
+//	@Summary		邀请链接预览
+//	@Description	扫码或打开邀请链接后获取群基本信息及入群方式
+//	@Tags			群组
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		group.JoinInfoRequest	true	"邀请链接预览请求"
+//	@Success		200		{object}	group.JoinInfoResponse
+//	@Router			/api/v1/group/invite-link/info [post]
+//	@Security		Bearer
+func (j *Join) Info(ctx context.Context, in *JoinInfoRequest) (*JoinInfoResponse, error) {
+	uid := middleware.FormContextAuthId[entity.WebClaims](ctx)
+
+	_, group, err := j.GroupJoinService.FindLink(ctx, in.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &JoinInfoResponse{
+		GroupId:   group.Id,
+		GroupName: group.Name,
+		Avatar:    group.Avatar,
+		Profile:   group.Profile,
+		MemberNum: int(j.GroupMemberRepo.CountMemberTotal(ctx, group.Id)),
+		JoinMode:  group.GetJoinMode(),
+		IsMember:  j.GroupMemberRepo.IsMember(ctx, group.Id, uid, false),
+	}
+
+	if resp.JoinMode == model.GroupJoinModeQuestion {
+		resp.Question = group.JoinQuestion
+	}
+
+	return resp, nil
+}
+
+// Join 加入群聊
+//
+//	@Summary		加入群聊
+//	@Description	通过邀请链接或群ID加入群聊，需审核的群将提交入群申请
+//	@Tags			群组
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		group.JoinRequest	true	"加入群聊请求"
+//	@Success		200		{object}	group.JoinResponse
+//	@Router			/api/v1/group/join [post]
+//	@Security		Bearer
+func (j *Join) Join(ctx context.Context, in *JoinRequest) (*JoinResponse, error) {
+	uid := middleware.FormContextAuthId[entity.WebClaims](ctx)
+
+	if in.Token == "" && in.GroupId == 0 {
+		return nil, errorx.New(400, "邀请链接或群ID不能为空")
+	}
+
+	groupId := in.GroupId
+	if in.Token != "" {
+		_, group, err := j.GroupJoinService.FindLink(ctx, in.Token)
+		if err != nil {
+			return nil, err
+		}
+
+		groupId = group.Id
+	}
+
+	key := fmt.Sprintf("group_join:%d", groupId)
+	if !j.RedisLock.Lock(ctx, key, 20) {
+		return nil, entity.ErrTooFrequentOperation
+	}
+
+	defer j.RedisLock.UnLock(ctx, key)
+
+	status, err := j.GroupJoinService.Join(ctx, &service.GroupJoinOpt{
+		UserId:  uid,
+		GroupId: groupId,
+		Token:   in.Token,
+		Answer:  in.Answer,
+		Remark:  in.Remark,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &JoinResponse{GroupId: groupId, Status: status}, nil
+}
+
+// ModeUpdate 修改入群方式
+//
+//	@Summary		修改入群方式
+//	@Description	设置自由加入、需审核、仅限邀请或回答问题后加入
+//	@Tags			群组
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		group.JoinModeUpdateRequest	true	"修改入群方式请求"
+//	@Success		200		{object}	group.JoinModeUpdateResponse
+//	@Router			/api/v1/group/join-mode/update [post]
+//	@Security		Bearer
+func (j *Join) ModeUpdate(ctx context.Context, in *JoinModeUpdateRequest) (*JoinModeUpdateResponse, error) {
+	uid := middleware.FormContextAuthId[entity.WebClaims](ctx)
+
+	err := j.GroupJoinService.UpdateJoinMode(ctx, &service.GroupJoinModeOpt{
+		UserId:   uid,
+		GroupId:  in.GroupId,
+		Mode:     in.Mode,
+		Question: in.Question,
+		Answer:   in.Answer,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &JoinModeUpdateResponse{}, nil
+}
+
+func toJoinLinkItem(link *model.GroupInviteLink) *JoinLinkItem {
+	return &JoinLinkItem{
+		Id:         link.Id,
+		GroupId:    link.GroupId,
+		Token:      link.Token,
+		CreatorId:  link.CreatorId,
+		MaxUsage:   link.MaxUsage,
+		UsageCount: link.UsageCount,
+		ExpireAt:   formatExpireAt(link.ExpireAt),
+		CreatedAt:  timeutil.FormatDatetime(link.CreatedAt),
+	}
+}
+
+func formatExpireAt(expireAt *time.Time) string {
+	if expireAt == nil {
+		return ""
+	}
+
+	return timeutil.FormatDatetime(*expireAt)
+}
+
+type JoinLinkCreateRequest struct {
+	GroupId       int `json:"group_id" binding:"required,gt=0"`
+	ExpireSeconds int `json:"expire_seconds" binding:"min=0"` // 有效期(秒)，为 0 时永久有效
+	MaxUsage      int `json:"max_usage" binding:"min=0"`      // 最大使用次数，为 0 时不限制
+}
+
+type JoinLinkItem struct {
+	Id         int    `json:"id"`
+	GroupId    int    `json:"group_id"`
+	Token      string `json:"token"`
+	CreatorId  int    `json:"creator_id"`
+	MaxUsage   int    `json:"max_usage"`
+	UsageCount int    `json:"usage_count"`
+	ExpireAt   string `json:"expire_at"`
+	CreatedAt  string `json:"created_at"`
+}
+
+type JoinLinkListRequest struct {
+	GroupId int `json:"group_id" binding:"required,gt=0"`
+}
+
+type JoinLinkListResponse struct {
+	Items []*JoinLinkItem `json:"items"`
+}
+
+type JoinLinkRevokeRequest struct {
+	LinkId int `json:"link_id" binding:"required,gt=0"`
+}
+
+type JoinLinkRevokeResponse struct{}
+
+type JoinQrcodeRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type JoinQrcodeResponse struct {
+	Token    string `json:"token"`
+	Content  string `json:"content"`   // 二维码内容
+	ExpireAt string `json:"expire_at"` // 过期时间，为空表示永久有效
+}
+
+type JoinInfoRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type JoinInfoResponse struct {
+	GroupId   int    `json:"group_id"`
+	GroupName string `json:"group_name"`
+	Avatar    string `json:"avatar"`
+	Profile   string `json:"profile"`
+	MemberNum int    `json:"member_num"`
+	JoinMode  int    `json:"join_mode"` // 入群方式 1:自由加入 2:需审核 3:仅限邀请 4:回答问题
+	Question  string `json:"question"`  // 入群问题，仅回答问题入群时返回
+	IsMember  bool   `json:"is_member"`
+}
+
+type JoinRequest struct {
+	GroupId int    `json:"group_id" binding:"min=0"`
+	Token   string `json:"token"`
+	Answer  string `json:"answer" binding:"max=100"`
+	Remark  string `json:"remark" binding:"max=200"`
+}
+
+type JoinResponse struct {
+	GroupId int `json:"group_id"`
+	Status  int `json:"status"` // 1:已加入 2:已提交申请，等待审核
+}
+
+type JoinModeUpdateRequest struct {
+	GroupId  int    `json:"group_id" binding:"required,gt=0"`
+	Mode     int    `json:"mode" binding:"required,oneof=1 2 3 4"`
+	Question string `json:"question" binding:"max=100"`
+	Answer   string `json:"answer" binding:"max=100"`
+}
+
+type JoinModeUpdateResponse struct{}
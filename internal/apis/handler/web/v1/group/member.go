@@ -0,0 +1,91 @@
+package group
+
+import (
+	"context"
+	"time"
+
+	"github.com/gzydong/go-chat/internal/entity"
+	"github.com/gzydong/go-chat/internal/pkg/core/middleware"
+	"github.com/gzydong/go-chat/internal/repository/model"
+	"github.com/gzydong/go-chat/internal/repository/repo"
+	"github.com/samber/lo"
+)
+
+type Member struct {
+	GroupRepo       *repo.Group
+	GroupMemberRepo *repo.GroupMember
+}
+
+// Page 群成员分页列表
+//
+//	@Summary		群成员分页列表
+//	@Description	分页获取群成员列表，群主及管理员排在前面，适用于大群
+//	@Tags			群组
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		group.MemberPageRequest	true	"群成员分页列表请求"
+//	@Success		200		{object}	group.MemberPageResponse
+//	@Router			/api/v1/group/member/page [post]
+//	@Security		Bearer
+func (m *Member) Page(ctx context.Context, in *MemberPageRequest) (*MemberPageResponse, error) {
+	uid := middleware.FormContextAuthId[entity.WebClaims](ctx)
+
+	group, err := m.GroupRepo.FindById(ctx, in.GroupId)
+	if err != nil {
+		return nil, err
+	}
+
+	if group.IsDismiss == model.Yes {
+		return &MemberPageResponse{Items: []*MemberPageItem{}, MaxNum: group.MemberLimit()}, nil
+	}
+
+	if !m.GroupMemberRepo.IsMember(ctx, in.GroupId, uid, true) {
+		return nil, entity.ErrPermissionDenied
+	}
+
+	total, list, err := m.GroupMemberRepo.GetMembersPage(ctx, in.GroupId, in.Page, in.PageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return &MemberPageResponse{
+		Items: lo.Map(list, func(item *model.MemberItem, _ int) *MemberPageItem {
+			return &MemberPageItem{
+				UserId:   item.UserId,
+				Nickname: item.Nickname,
+				Avatar:   item.Avatar,
+				Gender:   item.Gender,
+				Leader:   item.Leader,
+				IsMute:   lo.Ternary(model.IsMuteActive(item.IsMute, item.MuteExpireAt, now), model.Yes, model.No),
+				Remark:   item.UserCard,
+				Motto:    item.Motto,
+			}
+		}),
+		Total:  total,
+		MaxNum: group.MemberLimit(),
+	}, nil
+}
+
+type MemberPageRequest struct {
+	GroupId  int `json:"group_id" binding:"required,gt=0"`
+	Page     int `json:"page" binding:"required,gt=0"`
+	PageSize int `json:"page_size" binding:"required,gt=0,lte=200"`
+}
+
+type MemberPageItem struct {
+	UserId   int    `json:"user_id"`
+	Nickname string `json:"nickname"`
+	Avatar   string `json:"avatar"`
+	Gender   int    `json:"gender"`
+	Leader   int    `json:"leader"`
+	IsMute   int    `json:"is_mute"`
+	Remark   string `json:"remark"`
+	Motto    string `json:"motto"`
+}
+
+type MemberPageResponse struct {
+	Items  []*MemberPageItem `json:"items"`
+	Total  int64             `json:"total"`
+	MaxNum int               `json:"max_num"` // 群成员数量上限
+}
@@ -3,35 +3,31 @@ package group
 import (
 	"context"
 	"errors"
-	"fmt"
-	"time"
 
 	"github.com/gzydong/go-chat/api/pb/web/v1"
 	"github.com/gzydong/go-chat/internal/entity"
 	"github.com/gzydong/go-chat/internal/pkg/core/middleware"
-	"github.com/gzydong/go-chat/internal/pkg/jsonutil"
+	"github.com/gzydong/go-chat/internal/pkg/timeutil"
 	"github.com/gzydong/go-chat/internal/repository/model"
 	"github.com/gzydong/go-chat/internal/repository/repo"
 	"github.com/gzydong/go-chat/internal/service"
-	"github.com/gzydong/go-chat/internal/service/message"
+	"github.com/samber/lo"
 	"gorm.io/gorm"
 )
 
 var _ web.IGroupNoticeHandler = (*Notice)(nil)
 
 type Notice struct {
-	GroupMemberRepo    *repo.GroupMember
 	GroupNoticeRepo    *repo.GroupNotice
-	GroupMemberService service.IGroupMemberService
-	Message            message.IService
 	UsersRepo          *repo.Users
+	GroupNoticeService service.IGroupNoticeService
 }
 
 // Edit 添加或编辑群公告
 // Edit 编辑群组公告接口
 //
 //	@Summary		编辑群公告
-//	@Description	创建或更新群组公告
+//	@Description	编辑群组当前展示的公告，群内暂无公告时创建公告
 //	@Tags			群公告
 //	@Accept			json
 //	@Produce		json
@@ -42,50 +38,300 @@ type Notice struct {
 func (c *Notice) Edit(ctx context.Context, in *web.GroupNoticeEditRequest) (*web.GroupNoticeEditResponse, error) {
 	uid := middleware.FormContextAuthId[entity.WebClaims](ctx)
 
-	if !c.GroupMemberRepo.IsMember(ctx, int(in.GroupId), uid, false) {
-		return nil, entity.ErrPermissionDenied
+	notice, err := c.GroupNoticeRepo.GetLatestNotice(ctx, int(in.GroupId))
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
 	}
 
-	notice, err := c.GroupNoticeRepo.FindByWhere(ctx, "group_id = ?", in.GroupId)
-	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+	opt := &service.GroupNoticePublishOpt{
+		UserId:  uid,
+		GroupId: int(in.GroupId),
+		Content: in.Content,
+	}
+
+	if notice != nil {
+		opt.NoticeId = notice.Id
+		opt.Title = notice.Title
+		opt.IsTop = notice.IsTop == model.Yes
+		opt.IsConfirm = notice.IsConfirm == model.Yes
+	}
+
+	if _, err := c.GroupNoticeService.Publish(ctx, opt); err != nil {
 		return nil, err
 	}
 
-	if notice == nil {
-		err = c.GroupNoticeRepo.Create(ctx, &model.GroupNotice{
-			GroupId:      int(in.GroupId),
-			CreatorId:    uid,
-			ModifyId:     uid,
-			Content:      in.Content,
-			ConfirmUsers: "[]",
-			IsConfirm:    2,
-		})
-	} else {
-		_, err = c.GroupNoticeRepo.UpdateByWhere(ctx, map[string]any{
-			"content":    in.Content,
-			"modify_id":  uid,
-			"updated_at": time.Now(),
-		}, "group_id = ?", in.GroupId)
+	return &web.GroupNoticeEditResponse{}, nil
+}
+
+// Publish 发布群公告
+//
+//	@Summary		发布群公告
+//	@Description	发布新公告或修改指定公告，可设置置顶及是否需要群成员确认
+//	@Tags			群公告
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		group.NoticePublishRequest	true	"发布群公告请求"
+//	@Success		200		{object}	group.NoticePublishResponse
+//	@Router			/api/v1/group-notice/publish [post]
+//	@Security		Bearer
+func (c *Notice) Publish(ctx context.Context, in *NoticePublishRequest) (*NoticePublishResponse, error) {
+	uid := middleware.FormContextAuthId[entity.WebClaims](ctx)
+
+	noticeId, err := c.GroupNoticeService.Publish(ctx, &service.GroupNoticePublishOpt{
+		UserId:    uid,
+		GroupId:   in.GroupId,
+		NoticeId:  in.NoticeId,
+		Title:     in.Title,
+		Content:   in.Content,
+		IsTop:     in.IsTop == model.Yes,
+		IsConfirm: in.IsConfirm == model.Yes,
+	})
+	if err != nil {
+		return nil, err
 	}
 
+	return &NoticePublishResponse{NoticeId: noticeId}, nil
+}
+
+// List 群公告列表
+//
+//	@Summary		群公告列表
+//	@Description	获取群公告历史，置顶公告在前
+//	@Tags			群公告
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		group.NoticeListRequest	true	"群公告列表请求"
+//	@Success		200		{object}	group.NoticeListResponse
+//	@Router			/api/v1/group-notice/list [post]
+//	@Security		Bearer
+func (c *Notice) List(ctx context.Context, in *NoticeListRequest) (*NoticeListResponse, error) {
+	uid := middleware.FormContextAuthId[entity.WebClaims](ctx)
+
+	list, err := c.GroupNoticeService.List(ctx, in.GroupId, uid)
 	if err != nil {
 		return nil, err
 	}
 
-	userInfo, err := c.UsersRepo.FindByIdWithCache(ctx, uid)
-	if err == nil {
-		_ = c.Message.CreateGroupMessage(ctx, message.CreateGroupMessageOption{
-			MsgType:  entity.ChatMsgTypeGroupNotice,
-			FromId:   uid,
-			ToFromId: int(in.GroupId),
-			Extra: jsonutil.Encode(model.TalkRecordExtraGroupNotice{
-				OwnerId:   uid,
-				OwnerName: userInfo.Nickname,
-				Title:     fmt.Sprintf("【%s】 更新了群公告", userInfo.Nickname),
-				Content:   in.Content,
-			}),
+	items := make([]*NoticeItem, 0, len(list))
+	for _, item := range list {
+		items = append(items, &NoticeItem{
+			Id:           item.Notice.Id,
+			CreatorId:    item.Notice.CreatorId,
+			ModifyId:     item.Notice.ModifyId,
+			Title:        item.Notice.Title,
+			Content:      item.Notice.Content,
+			IsTop:        item.Notice.IsTop,
+			IsConfirm:    item.Notice.IsConfirm,
+			IsConfirmed:  item.IsConfirmed,
+			ConfirmCount: item.ConfirmCount,
+			CreatedAt:    timeutil.FormatDatetime(item.Notice.CreatedAt),
+			UpdatedAt:    timeutil.FormatDatetime(item.Notice.UpdatedAt),
 		})
 	}
 
-	return &web.GroupNoticeEditResponse{}, nil
+	return &NoticeListResponse{Items: items}, nil
+}
+
+// Delete 删除群公告
+//
+//	@Summary		删除群公告
+//	@Description	删除指定群公告
+//	@Tags			群公告
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		group.NoticeDeleteRequest	true	"删除群公告请求"
+//	@Success		200		{object}	group.NoticeDeleteResponse
+//	@Router			/api/v1/group-notice/delete [post]
+//	@Security		Bearer
+func (c *Notice) Delete(ctx context.Context, in *NoticeDeleteRequest) (*NoticeDeleteResponse, error) {
+	uid := middleware.FormContextAuthId[entity.WebClaims](ctx)
+
+	if err := c.GroupNoticeService.Delete(ctx, in.GroupId, in.NoticeId, uid); err != nil {
+		return nil, err
+	}
+
+	return &NoticeDeleteResponse{}, nil
+}
+
+// Top 置顶群公告
+//
+//	@Summary		置顶群公告
+//	@Description	置顶或取消置顶群公告
+//	@Tags			群公告
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		group.NoticeTopRequest	true	"置顶群公告请求"
+//	@Success		200		{object}	group.NoticeTopResponse
+//	@Router			/api/v1/group-notice/top [post]
+//	@Security		Bearer
+func (c *Notice) Top(ctx context.Context, in *NoticeTopRequest) (*NoticeTopResponse, error) {
+	uid := middleware.FormContextAuthId[entity.WebClaims](ctx)
+
+	if err := c.GroupNoticeService.SetTop(ctx, in.GroupId, in.NoticeId, uid, in.Action == model.Yes); err != nil {
+		return nil, err
+	}
+
+	return &NoticeTopResponse{}, nil
+}
+
+// Confirm 确认群公告
+//
+//	@Summary		确认群公告
+//	@Description	群成员确认已阅读需确认的群公告
+//	@Tags			群公告
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		group.NoticeConfirmRequest	true	"确认群公告请求"
+//	@Success		200		{object}	group.NoticeConfirmResponse
+//	@Router			/api/v1/group-notice/confirm [post]
+//	@Security		Bearer
+func (c *Notice) Confirm(ctx context.Context, in *NoticeConfirmRequest) (*NoticeConfirmResponse, error) {
+	uid := middleware.FormContextAuthId[entity.WebClaims](ctx)
+
+	if err := c.GroupNoticeService.Confirm(ctx, in.GroupId, in.NoticeId, uid); err != nil {
+		return nil, err
+	}
+
+	return &NoticeConfirmResponse{}, nil
+}
+
+// Unconfirmed 未确认公告成员列表
+//
+//	@Summary		未确认公告成员列表
+//	@Description	获取尚未确认公告的群成员（仅限群主及管理员）
+//	@Tags			群公告
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		group.NoticeUnconfirmedRequest	true	"未确认公告成员列表请求"
+//	@Success		200		{object}	group.NoticeUnconfirmedResponse
+//	@Router			/api/v1/group-notice/unconfirmed [post]
+//	@Security		Bearer
+func (c *Notice) Unconfirmed(ctx context.Context, in *NoticeUnconfirmedRequest) (*NoticeUnconfirmedResponse, error) {
+	uid := middleware.FormContextAuthId[entity.WebClaims](ctx)
+
+	uids, err := c.GroupNoticeService.Unconfirmed(ctx, in.GroupId, in.NoticeId, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*NoticeUnconfirmedItem, 0, len(uids))
+	if len(uids) > 0 {
+		users, err := c.UsersRepo.FindByIds(ctx, lo.ToAnySlice(uids))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, user := range users {
+			items = append(items, &NoticeUnconfirmedItem{
+				UserId:   user.Id,
+				Nickname: user.Nickname,
+				Avatar:   user.Avatar,
+			})
+		}
+	}
+
+	return &NoticeUnconfirmedResponse{Items: items}, nil
+}
+
+// Remind 提醒确认群公告
+//
+//	@Summary		提醒确认群公告
+//	@Description	向尚未确认公告的群成员推送提醒（仅限群主及管理员）
+//	@Tags			群公告
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		group.NoticeRemindRequest	true	"提醒确认群公告请求"
+//	@Success		200		{object}	group.NoticeRemindResponse
+//	@Router			/api/v1/group-notice/remind [post]
+//	@Security		Bearer
+func (c *Notice) Remind(ctx context.Context, in *NoticeRemindRequest) (*NoticeRemindResponse, error) {
+	uid := middleware.FormContextAuthId[entity.WebClaims](ctx)
+
+	num, err := c.GroupNoticeService.Remind(ctx, in.GroupId, in.NoticeId, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	return &NoticeRemindResponse{Num: num}, nil
+}
+
+type NoticePublishRequest struct {
+	GroupId   int    `json:"group_id" binding:"required,gt=0"`
+	NoticeId  int    `json:"notice_id" binding:"min=0"` // 公告ID，为 0 时发布新公告
+	Title     string `json:"title" binding:"max=64"`
+	Content   string `json:"content" binding:"required"`
+	IsTop     int    `json:"is_top" binding:"omitempty,oneof=1 2"`     // 是否置顶[1:是;2:否;]
+	IsConfirm int    `json:"is_confirm" binding:"omitempty,oneof=1 2"` // 是否需群成员确认[1:是;2:否;]
+}
+
+type NoticePublishResponse struct {
+	NoticeId int `json:"notice_id"`
+}
+
+type NoticeListRequest struct {
+	GroupId int `json:"group_id" binding:"required,gt=0"`
+}
+
+type NoticeItem struct {
+	Id           int    `json:"id"`
+	CreatorId    int    `json:"creator_id"`
+	ModifyId     int    `json:"modify_id"`
+	Title        string `json:"title"`
+	Content      string `json:"content"`
+	IsTop        int    `json:"is_top"`
+	IsConfirm    int    `json:"is_confirm"`
+	IsConfirmed  bool   `json:"is_confirmed"`  // 当前用户是否已确认
+	ConfirmCount int    `json:"confirm_count"` // 已确认人数
+	CreatedAt    string `json:"created_at"`
+	UpdatedAt    string `json:"updated_at"`
+}
+
+type NoticeListResponse struct {
+	Items []*NoticeItem `json:"items"`
+}
+
+type NoticeDeleteRequest struct {
+	GroupId  int `json:"group_id" binding:"required,gt=0"`
+	NoticeId int `json:"notice_id" binding:"required,gt=0"`
+}
+
+type NoticeDeleteResponse struct{}
+
+type NoticeTopRequest struct {
+	GroupId  int `json:"group_id" binding:"required,gt=0"`
+	NoticeId int `json:"notice_id" binding:"required,gt=0"`
+	Action   int `json:"action" binding:"required,oneof=1 2"` // 1:置顶 2:取消置顶
+}
+
+type NoticeTopResponse struct{}
+
+type NoticeConfirmRequest struct {
+	GroupId  int `json:"group_id" binding:"required,gt=0"`
+	NoticeId int `json:"notice_id" binding:"required,gt=0"`
+}
+
+type NoticeConfirmResponse struct{}
+
+type NoticeUnconfirmedRequest struct {
+	GroupId  int `json:"group_id" binding:"required,gt=0"`
+	NoticeId int `json:"notice_id" binding:"required,gt=0"`
+}
+
+type NoticeUnconfirmedItem struct {
+	UserId   int    `json:"user_id"`
+	Nickname string `json:"nickname"`
+	Avatar   string `json:"avatar"`
+}
+
+type NoticeUnconfirmedResponse struct {
+	Items []*NoticeUnconfirmedItem `json:"items"`
+}
+
+type NoticeRemindRequest struct {
+	GroupId  int `json:"group_id" binding:"required,gt=0"`
+	NoticeId int `json:"notice_id" binding:"required,gt=0"`
+}
+
+type NoticeRemindResponse struct {
+	Num int `json:"num"` // 提醒人数
 }
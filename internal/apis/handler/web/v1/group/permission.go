@@ -0,0 +1,123 @@
+package group
+
+import (
+	"context"
+
+	"github.com/gzydong/go-chat/internal/entity"
+	"github.com/gzydong/go-chat/internal/pkg/core/middleware"
+	"github.com/gzydong/go-chat/internal/service"
+	"github.com/samber/lo"
+)
+
+type Permission struct {
+	GroupPermission service.IGroupPermissionService
+}
+
+// Detail 群权限设置
+//
+//	@Summary		群权限设置
+//	@Description	获取群权限开放范围及当前用户拥有的权限，群主可同时获取各管理员的权限项
+//	@Tags			群组
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		group.PermissionDetailRequest	true	"群权限设置请求"
+//	@Success		200		{object}	group.PermissionDetailResponse
+//	@Router			/api/v1/group/permission/detail [post]
+//	@Security		Bearer
+func (p *Permission) Detail(ctx context.Context, in *PermissionDetailRequest) (*PermissionDetailResponse, error) {
+	uid := middleware.FormContextAuthId[entity.WebClaims](ctx)
+
+	setting, err := p.GroupPermission.GetSetting(ctx, in.GroupId, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PermissionDetailResponse{
+		Scopes:      setting.Scopes,
+		Permissions: setting.Permissions,
+		Admins: lo.Map(setting.Admins, func(item *service.GroupAdminPermission, _ int) *PermissionAdminItem {
+			return &PermissionAdminItem{
+				UserId:      item.UserId,
+				Permissions: item.Permissions,
+			}
+		}),
+	}, nil
+}
+
+// Update 修改群权限开放范围
+//
+//	@Summary		修改群权限开放范围
+//	@Description	设置邀请成员、修改群资料、发布公告、置顶消息、@所有人、发起投票及管理机器人的开放范围（仅限群主）
+//	@Tags			群组
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		group.PermissionUpdateRequest	true	"修改群权限开放范围请求"
+//	@Success		200		{object}	group.PermissionUpdateResponse
+//	@Router			/api/v1/group/permission/update [post]
+//	@Security		Bearer
+func (p *Permission) Update(ctx context.Context, in *PermissionUpdateRequest) (*PermissionUpdateResponse, error) {
+	uid := middleware.FormContextAuthId[entity.WebClaims](ctx)
+
+	if err := p.GroupPermission.UpdateScopes(ctx, in.GroupId, uid, in.Scopes); err != nil {
+		return nil, err
+	}
+
+	return &PermissionUpdateResponse{}, nil
+}
+
+// AdminUpdate 设置管理员权限项
+//
+//	@Summary		设置管理员权限项
+//	@Description	为指定管理员分配可使用的管理权限（仅限群主）
+//	@Tags			群组
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		group.PermissionAdminUpdateRequest	true	"设置管理员权限项请求"
+//	@Success		200		{object}	group.PermissionAdminUpdateResponse
+//	@Router			/api/v1/group/permission/admin-update [post]
+//	@Security		Bearer
+func (p *Permission) AdminUpdate(ctx context.Context, in *PermissionAdminUpdateRequest) (*PermissionAdminUpdateResponse, error) {
+	uid := middleware.FormContextAuthId[entity.WebClaims](ctx)
+
+	err := p.GroupPermission.UpdateAdminPermissions(ctx, &service.GroupAdminPermissionOpt{
+		OperatorId:  uid,
+		GroupId:     in.GroupId,
+		UserId:      in.UserId,
+		Permissions: in.Permissions,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &PermissionAdminUpdateResponse{}, nil
+}
+
+type PermissionDetailRequest struct {
+	GroupId int `json:"group_id" binding:"required,gt=0"`
+}
+
+type PermissionAdminItem struct {
+	UserId      int      `json:"user_id"`
+	Permissions []string `json:"permissions"`
+}
+
+type PermissionDetailResponse struct {
+	Scopes      map[string]int         `json:"scopes"`      // 各权限项开放范围 1:全部成员 2:群主及管理员 3:仅群主
+	Permissions []string               `json:"permissions"` // 当前用户拥有的权限项
+	Admins      []*PermissionAdminItem `json:"admins"`      // 管理员权限项，仅群主可见
+}
+
+type PermissionUpdateRequest struct {
+	GroupId int            `json:"group_id" binding:"required,gt=0"`
+	Scopes  map[string]int `json:"scopes" binding:"required,min=1"`
+}
+
+type PermissionUpdateResponse struct{}
+
+type PermissionAdminUpdateRequest struct {
+	GroupId     int      `json:"group_id" binding:"required,gt=0"`
+	UserId      int      `json:"user_id" binding:"required,gt=0"`
+	Permissions []string `json:"permissions" binding:"required,min=1"`
+}
+
+type PermissionAdminUpdateResponse struct{}
@@ -10,10 +10,12 @@ import (
 	"github.com/gzydong/go-chat/internal/pkg/core/middleware"
 	"github.com/gzydong/go-chat/internal/pkg/jsonutil"
 	"github.com/gzydong/go-chat/internal/pkg/logger"
+	"github.com/gzydong/go-chat/internal/pkg/timeutil"
 	"github.com/gzydong/go-chat/internal/repository/model"
 	"github.com/gzydong/go-chat/internal/repository/repo"
 	"github.com/gzydong/go-chat/internal/service"
 	"github.com/gzydong/go-chat/internal/service/message"
+	"github.com/samber/lo"
 )
 
 var _ web.IGroupVoteHandler = (*Vote)(nil)
@@ -23,6 +25,7 @@ type Vote struct {
 	GroupVoteRepo    *repo.GroupVote
 	GroupVoteService service.IGroupVoteService
 	MessageService   message.IService
+	GroupPermission  service.IGroupPermissionService
 }
 
 // Create 创建投票
@@ -37,47 +40,88 @@ type Vote struct {
 //	@Router			/api/v1/group-vote/create [post]
 //	@Security		Bearer
 func (v *Vote) Create(ctx context.Context, in *web.GroupVoteCreateRequest) (*web.GroupVoteCreateResponse, error) {
-
 	uid := middleware.FormContextAuthId[entity.WebClaims](ctx)
 
-	if len(in.Options) <= 1 {
-		return nil, errorx.NewInvalidParams("options 选项必须大于1")
+	_, err := v.create(ctx, &service.GroupVoteCreateOpt{
+		UserId:        uid,
+		Title:         in.Title,
+		AnswerMode:    int(in.Mode),
+		AnswerOptions: in.Options,
+		IsAnonymous:   in.IsAnonymous == 1,
+		GroupId:       int(in.GroupId),
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	if len(in.Options) > 6 {
-		return nil, errorx.NewInvalidParams("options 选项不能超过6个")
-	}
+	return &web.GroupVoteCreateResponse{}, nil
+}
 
-	isAnonymous := in.IsAnonymous == 1
+// Publish 发起限时投票
+//
+//	@Summary		发起限时投票
+//	@Description	在群聊中发起投票，可设置截止时间及结果可见范围
+//	@Tags			群投票
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		group.VotePublishRequest	true	"发起限时投票请求"
+//	@Success		200		{object}	group.VotePublishResponse
+//	@Router			/api/v1/group-vote/publish [post]
+//	@Security		Bearer
+func (v *Vote) Publish(ctx context.Context, in *VotePublishRequest) (*VotePublishResponse, error) {
+	uid := middleware.FormContextAuthId[entity.WebClaims](ctx)
 
-	voteId, err := v.GroupVoteService.Create(ctx, &service.GroupVoteCreateOpt{
+	voteId, err := v.create(ctx, &service.GroupVoteCreateOpt{
 		UserId:        uid,
+		GroupId:       in.GroupId,
 		Title:         in.Title,
-		AnswerMode:    int(in.Mode),
+		AnswerMode:    in.Mode,
 		AnswerOptions: in.Options,
-		IsAnonymous:   isAnonymous,
-		GroupId:       int(in.GroupId),
+		IsAnonymous:   in.IsAnonymous == 1,
+		Duration:      time.Duration(in.Duration) * time.Second,
+		ResultVisible: in.ResultVisible,
 	})
 	if err != nil {
 		return nil, err
 	}
 
+	return &VotePublishResponse{VoteId: voteId}, nil
+}
+
+func (v *Vote) create(ctx context.Context, opt *service.GroupVoteCreateOpt) (int, error) {
+	if err := v.GroupPermission.Check(ctx, opt.GroupId, opt.UserId, model.GroupPermVote); err != nil {
+		return 0, err
+	}
+
+	if len(opt.AnswerOptions) <= 1 {
+		return 0, errorx.NewInvalidParams("options 选项必须大于1")
+	}
+
+	if len(opt.AnswerOptions) > 6 {
+		return 0, errorx.NewInvalidParams("options 选项不能超过6个")
+	}
+
+	voteId, err := v.GroupVoteService.Create(ctx, opt)
+	if err != nil {
+		return 0, err
+	}
+
 	if err := v.MessageService.CreateVoteMessage(ctx, message.CreateVoteMessage{
 		TalkMode: entity.ChatGroupMode,
-		FromId:   uid,
-		ToFromId: int(in.GroupId),
+		FromId:   opt.UserId,
+		ToFromId: opt.GroupId,
 		VoteId:   voteId,
 	}); err != nil {
 		logger.Errorf("创建投票消息失败：%v", err)
 	}
 
-	return &web.GroupVoteCreateResponse{}, nil
+	return voteId, nil
 }
 
 // Submit 提交投票
 //
 //	@Summary		提交投票
-//	@Description	在群聊中提交投票
+//	@Description	在群聊中提交投票，投票结束前可重新投票
 //	@Tags			群投票
 //	@Accept			json
 //	@Produce		json
@@ -112,16 +156,14 @@ func (v *Vote) Submit(ctx context.Context, in *web.GroupVoteSubmitRequest) (*web
 //	@Router			/api/v1/group-vote/detail [post]
 //	@Security		Bearer
 func (v *Vote) Detail(ctx context.Context, in *web.GroupVoteDetailRequest) (*web.GroupVoteDetailResponse, error) {
-	voteInfo, err := v.GroupVoteRepo.FindById(ctx, int(in.VoteId))
+	uid := middleware.FormContextAuthId[entity.WebClaims](ctx)
+
+	result, err := v.GroupVoteService.Result(ctx, int(in.VoteId), uid)
 	if err != nil {
 		return nil, err
 	}
 
-	uid := middleware.FormContextAuthId[entity.WebClaims](ctx)
-	if !v.GroupMemberRepo.IsMember(ctx, voteInfo.GroupId, uid, false) {
-		return nil, entity.ErrPermissionDenied
-	}
-
+	voteInfo := result.Vote
 	resp := &web.GroupVoteDetailResponse{
 		VoteId:        int32(voteInfo.Id),
 		Title:         voteInfo.Title,
@@ -131,7 +173,7 @@ func (v *Vote) Detail(ctx context.Context, in *web.GroupVoteDetailRequest) (*web
 		AnsweredNum:   int32(voteInfo.AnsweredNum),
 		IsAnonymous:   int32(voteInfo.IsAnonymous),
 		AnsweredUsers: make([]*web.GroupVoteDetailResponse_AnsweredUser, 0),
-		IsSubmit:      false,
+		IsSubmit:      result.IsSubmit,
 	}
 
 	var options []model.GroupVoteOption
@@ -146,12 +188,16 @@ func (v *Vote) Detail(ctx context.Context, in *web.GroupVoteDetailRequest) (*web
 		})
 	}
 
+	// 结果不可见时不返回各成员的投票选项
+	if !result.IsVisible {
+		return resp, nil
+	}
+
 	items, err := v.GroupVoteRepo.FindAllAnsweredUserList(ctx, voteInfo.Id)
 	if err != nil {
 		return nil, err
 	}
 
-	userId := uid
 	if len(items) > 0 {
 		hashMap := make(map[int]*web.GroupVoteDetailResponse_AnsweredUser)
 
@@ -168,14 +214,110 @@ func (v *Vote) Detail(ctx context.Context, in *web.GroupVoteDetailRequest) (*web
 			}
 		}
 
-		for id, item := range hashMap {
+		for _, item := range hashMap {
 			resp.AnsweredUsers = append(resp.AnsweredUsers, item)
-
-			if id == userId {
-				resp.IsSubmit = true
-			}
 		}
 	}
 
 	return resp, nil
 }
+
+// Result 投票结果
+//
+//	@Summary		投票结果
+//	@Description	获取投票进度及各选项票数，结果仅投票后可见的投票在投票或结束前不返回票数
+//	@Tags			群投票
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		group.VoteResultRequest	true	"投票结果请求"
+//	@Success		200		{object}	group.VoteResultResponse
+//	@Router			/api/v1/group-vote/result [post]
+//	@Security		Bearer
+func (v *Vote) Result(ctx context.Context, in *VoteResultRequest) (*VoteResultResponse, error) {
+	uid := middleware.FormContextAuthId[entity.WebClaims](ctx)
+
+	result, err := v.GroupVoteService.Result(ctx, in.VoteId, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	resp := &VoteResultResponse{
+		VoteId:        result.Vote.Id,
+		Status:        lo.Ternary(result.Vote.IsClosed(now), model.VoteStatusFinish, model.VoteStatusWait),
+		ResultVisible: result.Vote.ResultVisible,
+		AnswerNum:     result.Vote.AnswerNum,
+		AnsweredNum:   result.Vote.AnsweredNum,
+		IsSubmit:      result.IsSubmit,
+		IsVisible:     result.IsVisible,
+		MyOptions:     lo.Ternary(result.Options == nil, []string{}, result.Options),
+	}
+
+	if result.Vote.Deadline != nil {
+		resp.Deadline = timeutil.FormatDatetime(*result.Vote.Deadline)
+	}
+
+	if result.Statistics != nil {
+		resp.Options = result.Statistics.Options
+	}
+
+	return resp, nil
+}
+
+// Close 结束投票
+//
+//	@Summary		结束投票
+//	@Description	提前结束投票（仅限发起人、群主及管理员）
+//	@Tags			群投票
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		group.VoteCloseRequest	true	"结束投票请求"
+//	@Success		200		{object}	group.VoteCloseResponse
+//	@Router			/api/v1/group-vote/close [post]
+//	@Security		Bearer
+func (v *Vote) Close(ctx context.Context, in *VoteCloseRequest) (*VoteCloseResponse, error) {
+	uid := middleware.FormContextAuthId[entity.WebClaims](ctx)
+
+	if err := v.GroupVoteService.Close(ctx, in.VoteId, uid); err != nil {
+		return nil, err
+	}
+
+	return &VoteCloseResponse{}, nil
+}
+
+type VotePublishRequest struct {
+	GroupId       int      `json:"group_id" binding:"required,gt=0"`
+	Title         string   `json:"title" binding:"required,max=64"`
+	Mode          int      `json:"mode" binding:"required,oneof=1 2"`            // 投票模式[1:单选;2:多选;]
+	IsAnonymous   int      `json:"is_anonymous" binding:"omitempty,oneof=1 2"`   // 匿名投票[1:是;2:否;]
+	Options       []string `json:"options" binding:"required"`                   // 投票选项
+	Duration      int      `json:"duration" binding:"min=0"`                     // 投票时长(秒)，为 0 时不限时
+	ResultVisible int      `json:"result_visible" binding:"omitempty,oneof=1 2"` // 结果可见范围[1:始终可见;2:投票后或结束后可见;]
+}
+
+type VotePublishResponse struct {
+	VoteId int `json:"vote_id"`
+}
+
+type VoteResultRequest struct {
+	VoteId int `json:"vote_id" binding:"required,gt=0"`
+}
+
+type VoteResultResponse struct {
+	VoteId        int            `json:"vote_id"`
+	Status        int            `json:"status"` // 投票状态[1:投票中;2:已结束;]
+	Deadline      string         `json:"deadline"`
+	ResultVisible int            `json:"result_visible"`
+	AnswerNum     int            `json:"answer_num"`
+	AnsweredNum   int            `json:"answered_num"`
+	IsSubmit      bool           `json:"is_submit"`
+	IsVisible     bool           `json:"is_visible"` // 结果是否可见
+	MyOptions     []string       `json:"my_options"` // 当前用户的投票选项
+	Options       map[string]int `json:"options"`    // 各选项票数，结果不可见时为空
+}
+
+type VoteCloseRequest struct {
+	VoteId int `json:"vote_id" binding:"required,gt=0"`
+}
+
+type VoteCloseResponse struct{}
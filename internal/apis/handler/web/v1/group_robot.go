@@ -6,11 +6,13 @@ import (
 	"github.com/gzydong/go-chat/internal/entity"
 	"github.com/gzydong/go-chat/internal/pkg/core/errorx"
 	"github.com/gzydong/go-chat/internal/pkg/core/middleware"
+	"github.com/gzydong/go-chat/internal/repository/model"
 	"github.com/gzydong/go-chat/internal/service"
 )
 
 type GroupRobot struct {
 	GroupRobotService service.IGroupRobotService
+	TalkCardService   service.ITalkCardService
 }
 
 // CreateRobot 创建群机器人
@@ -75,6 +77,7 @@ func (g *GroupRobot) GetRobotList(ctx context.Context, req *GroupRobotListReques
 			RobotId:     int32(robot.Id),
 			RobotName:   robot.RobotName,
 			WebhookUrl:  robot.WebhookUrl,
+			CallbackUrl: robot.CallbackUrl,
 			Description: robot.Description,
 			Status:      int32(robot.Status),
 			CreatedAt:   robot.CreatedAt.Format("2006-01-02 15:04:05"),
@@ -124,11 +127,14 @@ func (g *GroupRobot) DeleteRobot(ctx context.Context, req *GroupRobotDeleteReque
 //	@Success		200		{object}	GroupRobotUpdateResponse
 //	@Router			/api/v1/group/robot/update [post]
 func (g *GroupRobot) UpdateRobot(ctx context.Context, req *GroupRobotUpdateRequest) (*GroupRobotUpdateResponse, error) {
+	session, _ := middleware.FormContext[entity.WebClaims](ctx)
+	userId := session.UserId
+
 	if req.RobotId <= 0 {
 		return nil, errorx.New(400, "机器人ID无效")
 	}
 
-	if err := g.GroupRobotService.UpdateRobot(ctx, int(req.RobotId), req.RobotName, req.Description); err != nil {
+	if err := g.GroupRobotService.UpdateRobot(ctx, int(req.RobotId), int(userId), req.RobotName, req.Description, req.CallbackUrl); err != nil {
 		return nil, err
 	}
 
@@ -163,16 +169,48 @@ func (g *GroupRobot) SendWebhookMessage(ctx context.Context, webhookUrl string,
 		return nil, errorx.New(400, "签名不能为空")
 	}
 
-	if err := g.GroupRobotService.SendWebhookMessage(ctx, webhookUrl, req.Timestamp, req.Signature, &req.Message); err != nil {
+	msgId, err := g.GroupRobotService.SendWebhookMessage(ctx, webhookUrl, req.Timestamp, req.Signature, &req.Message)
+	if err != nil {
 		return nil, err
 	}
 
 	return &WebhookMessageResponse{
 		Success: true,
 		Message: "消息发送成功",
+		MsgId:   msgId,
 	}, nil
 }
 
+// UpdateCard Webhook更新卡片消息
+//
+//	@Summary		Webhook更新卡片消息
+//	@Description	群机器人原地更新已发送的卡片消息
+//	@Tags			群机器人
+//	@Accept			json
+//	@Produce		json
+//	@Param			webhook_url	path		string					true	"Webhook URL"
+//	@Param			timestamp	header		string					true	"时间戳"
+//	@Param			signature	header		string					true	"签名"
+//	@Param			request		body		WebhookCardUpdateRequest	true	"卡片内容"
+//	@Success		200			{object}	WebhookCardUpdateResponse
+//	@Router			/api/v1/webhook/robot/{webhook_url}/card/update [post]
+func (g *GroupRobot) UpdateCard(ctx context.Context, webhookUrl string, req *WebhookCardUpdateRequest) (*WebhookCardUpdateResponse, error) {
+	if req.Timestamp == "" || req.Signature == "" {
+		return nil, errorx.New(400, "签名不能为空")
+	}
+
+	robot, err := g.GroupRobotService.FindWebhookRobot(ctx, webhookUrl, req.Timestamp, req.Signature)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := g.TalkCardService.Update(ctx, robot, req.MsgId, req.Card); err != nil {
+		return nil, err
+	}
+
+	return &WebhookCardUpdateResponse{Success: true}, nil
+}
+
 // GetRobotMessages 获取机器人消息记录
 //
 //	@Summary		获取机器人消息记录
@@ -239,6 +277,7 @@ type GroupRobotItem struct {
 	RobotId     int32  `json:"robot_id"`
 	RobotName   string `json:"robot_name"`
 	WebhookUrl  string `json:"webhook_url"`
+	CallbackUrl string `json:"callback_url"`
 	Description string `json:"description"`
 	Status      int32  `json:"status"`
 	CreatedAt   string `json:"created_at"`
@@ -256,6 +295,7 @@ type GroupRobotUpdateRequest struct {
 	RobotId     int32  `json:"robot_id"`
 	RobotName   string `json:"robot_name"`
 	Description string `json:"description"`
+	CallbackUrl string `json:"callback_url" binding:"omitempty,url,max=255"` // 卡片消息按钮点击回调地址
 }
 
 type GroupRobotUpdateResponse struct {
@@ -271,6 +311,18 @@ type WebhookSendRequest struct {
 type WebhookMessageResponse struct {
 	Success bool   `json:"success"`
 	Message string `json:"message"`
+	MsgId   string `json:"msg_id,omitempty"` // 卡片消息ID，用于更新卡片
+}
+
+type WebhookCardUpdateRequest struct {
+	Timestamp string                            `json:"-"` // From header
+	Signature string                            `json:"-"` // From header
+	MsgId     string                            `json:"msg_id" binding:"required"`
+	Card      *model.TalkRecordExtraInteractive `json:"card" binding:"required"`
+}
+
+type WebhookCardUpdateResponse struct {
+	Success bool `json:"success"`
 }
 
 type GroupRobotMessagesRequest struct {
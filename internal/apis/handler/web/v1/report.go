@@ -0,0 +1,58 @@
+package v1
+
+import (
+	"context"
+
+	"github.com/gzydong/go-chat/internal/entity"
+	"github.com/gzydong/go-chat/internal/pkg/core/middleware"
+	"github.com/gzydong/go-chat/internal/service"
+)
+
+type Report struct {
+	ReportService service.IReportService
+}
+
+// Create 提交举报
+//
+//	@Summary		提交举报
+//	@Description	举报消息、用户或群聊，附带的消息会保存快照作为证据
+//	@Tags			举报
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		v1.ReportCreateRequest	true	"举报请求"
+//	@Success		200		{object}	v1.ReportCreateResponse
+//	@Router			/api/v1/report/create [post]
+//	@Security		Bearer
+func (r *Report) Create(ctx context.Context, in *ReportCreateRequest) (*ReportCreateResponse, error) {
+	id, err := r.ReportService.Create(ctx, &service.ReportCreateOpt{
+		UserId:      middleware.FormContextAuthId[entity.WebClaims](ctx),
+		TargetType:  in.TargetType,
+		TargetId:    in.TargetId,
+		TalkMode:    in.TalkMode,
+		ToFromId:    in.ToFromId,
+		MsgIds:      in.MsgIds,
+		Reason:      in.Reason,
+		Description: in.Description,
+		Images:      in.Images,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReportCreateResponse{Id: id}, nil
+}
+
+type ReportCreateRequest struct {
+	TargetType  int      `json:"target_type" binding:"required,oneof=1 2 3"`                       // 1:消息 2:用户 3:群聊
+	TargetId    int      `json:"target_id" binding:"required_unless=TargetType 1"`                 // 举报用户或群聊时必填
+	TalkMode    int      `json:"talk_mode" binding:"required_if=TargetType 1,omitempty,oneof=1 2"` // 举报消息时必填
+	ToFromId    int      `json:"to_from_id" binding:"required_if=TargetType 1"`                    // 举报消息时必填
+	MsgIds      []string `json:"msg_ids" binding:"max=20"`                                         // 证据消息
+	Reason      int      `json:"reason" binding:"required,oneof=1 2 3 4 5 6"`                      // 1:垃圾广告 2:诈骗 3:色情 4:暴力违法 5:骚扰辱骂 6:其它
+	Description string   `json:"description" binding:"max=500"`                                    // 举报说明
+	Images      []string `json:"images" binding:"max=9,dive,url"`                                  // 举报截图
+}
+
+type ReportCreateResponse struct {
+	Id int `json:"id"`
+}
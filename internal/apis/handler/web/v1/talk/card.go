@@ -0,0 +1,46 @@
+package talk
+
+import (
+	"context"
+
+	"github.com/gzydong/go-chat/internal/entity"
+	"github.com/gzydong/go-chat/internal/pkg/core/middleware"
+	"github.com/gzydong/go-chat/internal/service"
+)
+
+type Card struct {
+	TalkCardService service.ITalkCardService
+}
+
+// Action 点击卡片按钮
+//
+//	@Summary		点击卡片按钮
+//	@Description	点击交互卡片消息的按钮，回调给发送卡片的群机器人，机器人可返回新的卡片内容原地更新
+//	@Tags			消息
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		talk.CardActionRequest	true	"点击请求"
+//	@Success		200		{object}	talk.CardActionResponse
+//	@Router			/api/v1/message/card/action [post]
+//	@Security		Bearer
+func (c *Card) Action(ctx context.Context, in *CardActionRequest) (*CardActionResponse, error) {
+	result, err := c.TalkCardService.Action(ctx, &service.TalkCardActionOpt{
+		UserId: middleware.FormContextAuthId[entity.WebClaims](ctx),
+		MsgId:  in.MsgId,
+		Action: in.Action,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &CardActionResponse{Toast: result.Toast}, nil
+}
+
+type CardActionRequest struct {
+	MsgId  string `json:"msg_id" binding:"required"`
+	Action string `json:"action" binding:"required,max=64"`
+}
+
+type CardActionResponse struct {
+	Toast string `json:"toast"` // 机器人返回的提示信息
+}
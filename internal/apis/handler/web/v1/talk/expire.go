@@ -0,0 +1,123 @@
+package talk
+
+import (
+	"context"
+
+	"github.com/gzydong/go-chat/internal/entity"
+	"github.com/gzydong/go-chat/internal/pkg/core/errorx"
+	"github.com/gzydong/go-chat/internal/pkg/core/middleware"
+	"github.com/gzydong/go-chat/internal/service"
+)
+
+type Expire struct {
+	TalkMessageExpireService service.ITalkMessageExpireService
+}
+
+// Setting 获取会话消息过期设置
+//
+//	@Summary		获取会话消息过期设置
+//	@Description	获取会话的定时销毁/阅后即焚设置，ttl 为 0 表示未开启
+//	@Tags			消息
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		talk.ExpireSettingRequest	true	"获取设置请求"
+//	@Success		200		{object}	talk.ExpireSettingResponse
+//	@Router			/api/v1/message/expire/setting [post]
+//	@Security		Bearer
+func (e *Expire) Setting(ctx context.Context, in *ExpireSettingRequest) (*ExpireSettingResponse, error) {
+	uid := middleware.FormContextAuthId[entity.WebClaims](ctx)
+
+	setting, err := e.TalkMessageExpireService.GetSetting(ctx, uid, in.TalkMode, in.ToFromId)
+	if err != nil {
+		return nil, err
+	}
+
+	if setting == nil {
+		return &ExpireSettingResponse{}, nil
+	}
+
+	return &ExpireSettingResponse{
+		Mode:      setting.Mode,
+		Ttl:       setting.Ttl,
+		UpdatedBy: setting.UpdatedBy,
+	}, nil
+}
+
+// UpdateSetting 修改会话消息过期设置
+//
+//	@Summary		修改会话消息过期设置
+//	@Description	开启或关闭会话的定时销毁/阅后即焚，仅对之后发送的消息生效，群聊仅群主及管理员可操作
+//	@Tags			消息
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		talk.ExpireUpdateSettingRequest	true	"修改设置请求"
+//	@Success		200		{object}	talk.ExpireUpdateSettingResponse
+//	@Router			/api/v1/message/expire/setting/update [post]
+//	@Security		Bearer
+func (e *Expire) UpdateSetting(ctx context.Context, in *ExpireUpdateSettingRequest) (*ExpireUpdateSettingResponse, error) {
+	if in.Ttl > 0 && in.Mode == 0 {
+		return nil, errorx.New(400, "请选择计时方式")
+	}
+
+	err := e.TalkMessageExpireService.UpdateSetting(ctx, &service.TalkExpireSettingOpt{
+		UserId:   middleware.FormContextAuthId[entity.WebClaims](ctx),
+		TalkMode: in.TalkMode,
+		ToFromId: in.ToFromId,
+		Mode:     in.Mode,
+		Ttl:      in.Ttl,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ExpireUpdateSettingResponse{}, nil
+}
+
+// Read 阅后即焚消息已读
+//
+//	@Summary		阅后即焚消息已读
+//	@Description	标记阅后即焚消息已读并开始销毁计时，msg_ids 为空时标记会话内全部消息
+//	@Tags			消息
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		talk.ExpireReadRequest	true	"已读请求"
+//	@Success		200		{object}	talk.ExpireReadResponse
+//	@Router			/api/v1/message/expire/read [post]
+//	@Security		Bearer
+func (e *Expire) Read(ctx context.Context, in *ExpireReadRequest) (*ExpireReadResponse, error) {
+	uid := middleware.FormContextAuthId[entity.WebClaims](ctx)
+
+	if err := e.TalkMessageExpireService.MarkRead(ctx, uid, in.TalkMode, in.ToFromId, in.MsgIds); err != nil {
+		return nil, err
+	}
+
+	return &ExpireReadResponse{}, nil
+}
+
+type ExpireSettingRequest struct {
+	TalkMode int `json:"talk_mode" binding:"required,oneof=1 2"`
+	ToFromId int `json:"to_from_id" binding:"required,gt=0"`
+}
+
+type ExpireSettingResponse struct {
+	Mode      int `json:"mode"` // 计时方式 1:发送后 2:首次阅读后
+	Ttl       int `json:"ttl"`  // 有效时长(秒)
+	UpdatedBy int `json:"updated_by"`
+}
+
+type ExpireUpdateSettingRequest struct {
+	TalkMode int `json:"talk_mode" binding:"required,oneof=1 2"`
+	ToFromId int `json:"to_from_id" binding:"required,gt=0"`
+	Mode     int `json:"mode" binding:"omitempty,oneof=1 2"` // 计时方式 1:发送后 2:首次阅读后
+	Ttl      int `json:"ttl" binding:"min=0"`                // 有效时长(秒)，0 表示关闭
+}
+
+type ExpireUpdateSettingResponse struct{}
+
+type ExpireReadRequest struct {
+	TalkMode int      `json:"talk_mode" binding:"required,oneof=1 2"`
+	ToFromId int      `json:"to_from_id" binding:"required,gt=0"`
+	MsgIds   []string `json:"msg_ids" binding:"max=100"`
+}
+
+type ExpireReadResponse struct{}
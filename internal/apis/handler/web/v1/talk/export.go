@@ -0,0 +1,175 @@
+package talk
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gzydong/go-chat/internal/entity"
+	"github.com/gzydong/go-chat/internal/pkg/core/errorx"
+	"github.com/gzydong/go-chat/internal/pkg/core/middleware"
+	"github.com/gzydong/go-chat/internal/repository/model"
+	"github.com/gzydong/go-chat/internal/repository/repo"
+	"github.com/gzydong/go-chat/internal/service"
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+)
+
+type Export struct {
+	TalkExportRepo    *repo.TalkExport
+	TalkExportService service.ITalkExportService
+}
+
+// Create 导出聊天记录
+//
+//	@Summary		导出聊天记录
+//	@Description	异步导出指定会话或时间范围内全部会话的聊天记录，完成后推送 im.talk.export 事件
+//	@Tags			消息
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		talk.ExportCreateRequest	true	"导出请求"
+//	@Success		200		{object}	talk.ExportCreateResponse
+//	@Router			/api/v1/talk/export/create [post]
+//	@Security		Bearer
+func (e *Export) Create(ctx context.Context, in *ExportCreateRequest) (*ExportCreateResponse, error) {
+	uid := middleware.FormContextAuthId[entity.WebClaims](ctx)
+
+	startTime, err := time.ParseInLocation(time.DateTime, in.StartTime, time.Local)
+	if err != nil {
+		return nil, errorx.New(400, "开始时间格式错误")
+	}
+
+	endTime, err := time.ParseInLocation(time.DateTime, in.EndTime, time.Local)
+	if err != nil {
+		return nil, errorx.New(400, "结束时间格式错误")
+	}
+
+	export, err := e.TalkExportService.Create(ctx, &service.TalkExportCreateOpt{
+		RequesterType: model.TalkExportRequesterUser,
+		RequesterId:   uid,
+		UserId:        uid,
+		TalkMode:      in.TalkMode,
+		ToFromId:      in.ToFromId,
+		StartTime:     startTime,
+		EndTime:       endTime,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ExportCreateResponse{Id: export.Id}, nil
+}
+
+// List 聊天记录导出列表
+//
+//	@Summary		导出列表
+//	@Description	获取最近的聊天记录导出任务
+//	@Tags			消息
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		talk.ExportListRequest	true	"请求"
+//	@Success		200		{object}	talk.ExportListResponse
+//	@Router			/api/v1/talk/export/list [post]
+//	@Security		Bearer
+func (e *Export) List(ctx context.Context, _ *ExportListRequest) (*ExportListResponse, error) {
+	uid := middleware.FormContextAuthId[entity.WebClaims](ctx)
+
+	items, err := e.TalkExportRepo.FindAll(ctx, func(db *gorm.DB) {
+		db.Where("requester_type = ? and requester_id = ?", model.TalkExportRequesterUser, uid).Order("id desc").Limit(20)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ExportListResponse{
+		Items: lo.Map(items, func(item *model.TalkExport, _ int) *ExportItem {
+			return &ExportItem{
+				Id:          item.Id,
+				TalkMode:    item.TalkMode,
+				ToFromId:    item.ToFromId,
+				StartTime:   item.StartTime.Format(time.DateTime),
+				EndTime:     item.EndTime.Format(time.DateTime),
+				Status:      item.Status,
+				RecordCount: item.RecordCount,
+				FileSize:    item.FileSize,
+				Error:       item.Error,
+				ExpiredAt:   lo.Ternary(item.ExpiredAt.Valid, item.ExpiredAt.Time.Format(time.DateTime), ""),
+				CreatedAt:   item.CreatedAt.Format(time.DateTime),
+			}
+		}),
+	}, nil
+}
+
+// Download 获取导出文件下载地址
+//
+//	@Summary		导出文件下载地址
+//	@Description	获取导出文件的签名下载地址，地址有效期24小时
+//	@Tags			消息
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		talk.ExportDownloadRequest	true	"请求"
+//	@Success		200		{object}	talk.ExportDownloadResponse
+//	@Router			/api/v1/talk/export/download [post]
+//	@Security		Bearer
+func (e *Export) Download(ctx context.Context, in *ExportDownloadRequest) (*ExportDownloadResponse, error) {
+	uid := middleware.FormContextAuthId[entity.WebClaims](ctx)
+
+	export, err := e.TalkExportRepo.FindById(ctx, in.Id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorx.New(400, "导出任务不存在")
+		}
+
+		return nil, err
+	}
+
+	if export.RequesterType != model.TalkExportRequesterUser || export.RequesterId != uid {
+		return nil, entity.ErrPermissionDenied
+	}
+
+	url, err := e.TalkExportService.DownloadUrl(export)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ExportDownloadResponse{Url: url}, nil
+}
+
+type ExportCreateRequest struct {
+	TalkMode  int    `json:"talk_mode" binding:"omitempty,oneof=1 2"` // 为空时导出全部类型的会话
+	ToFromId  int    `json:"to_from_id"`                              // 为空时导出时间范围内的全部会话
+	StartTime string `json:"start_time" binding:"required"`           // 格式 2006-01-02 15:04:05
+	EndTime   string `json:"end_time" binding:"required"`             // 格式 2006-01-02 15:04:05
+}
+
+type ExportCreateResponse struct {
+	Id int `json:"id"`
+}
+
+type ExportListRequest struct{}
+
+type ExportItem struct {
+	Id          int    `json:"id"`
+	TalkMode    int    `json:"talk_mode"`
+	ToFromId    int    `json:"to_from_id"`
+	StartTime   string `json:"start_time"`
+	EndTime     string `json:"end_time"`
+	Status      int    `json:"status"` // 导出状态[1:待导出;2:导出中;3:已完成;4:导出失败;]
+	RecordCount int    `json:"record_count"`
+	FileSize    int64  `json:"file_size"`
+	Error       string `json:"error"`
+	ExpiredAt   string `json:"expired_at"`
+	CreatedAt   string `json:"created_at"`
+}
+
+type ExportListResponse struct {
+	Items []*ExportItem `json:"items"`
+}
+
+type ExportDownloadRequest struct {
+	Id int `json:"id" binding:"required"`
+}
+
+type ExportDownloadResponse struct {
+	Url string `json:"url"`
+}
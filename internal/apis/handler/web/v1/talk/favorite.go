@@ -0,0 +1,330 @@
+package talk
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gzydong/go-chat/internal/entity"
+	"github.com/gzydong/go-chat/internal/pkg/core/errorx"
+	"github.com/gzydong/go-chat/internal/pkg/core/middleware"
+	"github.com/gzydong/go-chat/internal/pkg/filesystem"
+	"github.com/gzydong/go-chat/internal/pkg/jsonutil"
+	"github.com/gzydong/go-chat/internal/repository/model"
+	"github.com/gzydong/go-chat/internal/repository/repo"
+	"github.com/gzydong/go-chat/internal/service"
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+)
+
+type Favorite struct {
+	TalkFavoriteRepo    *repo.TalkFavorite
+	UsersRepo           *repo.Users
+	TalkFavoriteService service.ITalkFavoriteService
+	Filesystem          filesystem.IFilesystem
+}
+
+// Create 收藏消息
+//
+//	@Summary		收藏消息
+//	@Description	收藏会话中的消息，保存消息内容快照，原消息撤回或删除后仍可查看
+//	@Tags			消息
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		talk.FavoriteCreateRequest	true	"收藏消息请求"
+//	@Success		200		{object}	talk.FavoriteCreateResponse
+//	@Router			/api/v1/message/favorite/create [post]
+//	@Security		Bearer
+func (f *Favorite) Create(ctx context.Context, in *FavoriteCreateRequest) (*FavoriteCreateResponse, error) {
+	favorite, err := f.TalkFavoriteService.Create(ctx, &service.TalkFavoriteCreateOpt{
+		UserId:   middleware.FormContextAuthId[entity.WebClaims](ctx),
+		TalkMode: in.TalkMode,
+		ToFromId: in.ToFromId,
+		MsgId:    in.MsgId,
+		Tags:     in.Tags,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &FavoriteCreateResponse{Id: favorite.Id}, nil
+}
+
+// Delete 取消收藏
+//
+//	@Summary		取消收藏
+//	@Description	删除收藏的消息
+//	@Tags			消息
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		talk.FavoriteDeleteRequest	true	"取消收藏请求"
+//	@Success		200		{object}	talk.FavoriteDeleteResponse
+//	@Router			/api/v1/message/favorite/delete [post]
+//	@Security		Bearer
+func (f *Favorite) Delete(ctx context.Context, in *FavoriteDeleteRequest) (*FavoriteDeleteResponse, error) {
+	uid := middleware.FormContextAuthId[entity.WebClaims](ctx)
+	if err := f.TalkFavoriteService.Delete(ctx, uid, in.Id); err != nil {
+		return nil, err
+	}
+
+	return &FavoriteDeleteResponse{}, nil
+}
+
+// Tags 修改收藏标签
+//
+//	@Summary		修改收藏标签
+//	@Description	覆盖收藏的标签，传空列表时清空标签
+//	@Tags			消息
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		talk.FavoriteTagsRequest	true	"修改收藏标签请求"
+//	@Success		200		{object}	talk.FavoriteTagsResponse
+//	@Router			/api/v1/message/favorite/tags [post]
+//	@Security		Bearer
+func (f *Favorite) Tags(ctx context.Context, in *FavoriteTagsRequest) (*FavoriteTagsResponse, error) {
+	uid := middleware.FormContextAuthId[entity.WebClaims](ctx)
+	if err := f.TalkFavoriteService.UpdateTags(ctx, uid, in.Id, in.Tags); err != nil {
+		return nil, err
+	}
+
+	return &FavoriteTagsResponse{}, nil
+}
+
+// TagList 收藏标签列表
+//
+//	@Summary		收藏标签列表
+//	@Description	获取当前用户收藏中使用过的标签
+//	@Tags			消息
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	talk.FavoriteTagListResponse
+//	@Router			/api/v1/message/favorite/tag/list [post]
+//	@Security		Bearer
+func (f *Favorite) TagList(ctx context.Context, _ *FavoriteTagListRequest) (*FavoriteTagListResponse, error) {
+	tags, err := f.TalkFavoriteService.Tags(ctx, middleware.FormContextAuthId[entity.WebClaims](ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	return &FavoriteTagListResponse{Items: tags}, nil
+}
+
+// List 收藏列表
+//
+//	@Summary		收藏列表
+//	@Description	按收藏时间倒序获取收藏，支持按关键词、标签及消息类型筛选
+//	@Tags			消息
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		talk.FavoriteListRequest	true	"收藏列表请求"
+//	@Success		200		{object}	talk.FavoriteListResponse
+//	@Router			/api/v1/message/favorite/list [post]
+//	@Security		Bearer
+func (f *Favorite) List(ctx context.Context, in *FavoriteListRequest) (*FavoriteListResponse, error) {
+	list, err := f.TalkFavoriteService.List(ctx, &service.TalkFavoriteListOpt{
+		UserId:  middleware.FormContextAuthId[entity.WebClaims](ctx),
+		Keyword: in.Keyword,
+		Tag:     in.Tag,
+		MsgType: in.MsgType,
+		Cursor:  in.Cursor,
+		Limit:   in.Limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	nicknames := make(map[int]string)
+	if uids := lo.Uniq(lo.Map(list, func(item *model.TalkFavorite, _ int) any { return item.FromId })); len(uids) > 0 {
+		users, err := f.UsersRepo.FindByIds(ctx, uids)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, user := range users {
+			nicknames[user.Id] = user.Nickname
+		}
+	}
+
+	items := lo.Map(list, func(item *model.TalkFavorite, _ int) *FavoriteItem {
+		value := &FavoriteItem{
+			Id:        item.Id,
+			MsgType:   item.MsgType,
+			Extra:     item.Extra,
+			Tags:      make([]string, 0),
+			TalkMode:  item.TalkMode,
+			ToFromId:  item.ToFromId,
+			MsgId:     item.MsgId,
+			FromId:    item.FromId,
+			Nickname:  nicknames[item.FromId],
+			SendTime:  item.SendTime.Format(time.DateTime),
+			CreatedAt: item.CreatedAt.Format(time.DateTime),
+		}
+
+		if item.Tags != "" {
+			value.Tags = splitFavoriteTags(item.Tags)
+		}
+
+		return value
+	})
+
+	cursor := 0
+	if len(list) > 0 {
+		cursor = list[len(list)-1].Id
+	}
+
+	return &FavoriteListResponse{Items: items, Cursor: cursor}, nil
+}
+
+// Forward 转发收藏
+//
+//	@Summary		转发收藏
+//	@Description	将收藏的消息逐条发送到指定的好友或群聊
+//	@Tags			消息
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		talk.FavoriteForwardRequest	true	"转发收藏请求"
+//	@Success		200		{object}	talk.FavoriteForwardResponse
+//	@Router			/api/v1/message/favorite/forward [post]
+//	@Security		Bearer
+func (f *Favorite) Forward(ctx context.Context, in *FavoriteForwardRequest) (*FavoriteForwardResponse, error) {
+	err := f.TalkFavoriteService.Forward(ctx, &service.TalkFavoriteForwardOpt{
+		UserId:   middleware.FormContextAuthId[entity.WebClaims](ctx),
+		Ids:      in.Ids,
+		UserIds:  in.UserIds,
+		GroupIds: in.GroupIds,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &FavoriteForwardResponse{}, nil
+}
+
+// Download 下载收藏的文件
+//
+//	@Summary		下载收藏的文件
+//	@Description	下载收藏的文件消息中的文件，原消息删除后仍可下载
+//	@Tags			消息
+//	@Accept			json
+//	@Produce		octet-stream
+//	@Param			id	query		int	true	"收藏ID"
+//	@Success		200	{file}		binary
+//	@Router			/api/v1/message/favorite/file-download [get]
+//	@Security		Bearer
+func (f *Favorite) Download(ctx *gin.Context) error {
+	params := &FavoriteDownloadRequest{}
+	if err := ctx.ShouldBind(params); err != nil {
+		return errorx.New(400, err.Error())
+	}
+
+	uid := middleware.FormContextAuthId[entity.WebClaims](ctx.Request.Context())
+
+	favorite, err := f.TalkFavoriteRepo.FindByWhere(ctx, "id = ? and user_id = ?", params.Id, uid)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errorx.New(400, "收藏不存在")
+		}
+
+		return err
+	}
+
+	var fileInfo model.TalkRecordExtraFile
+	if favorite.MsgType != entity.ChatMsgTypeFile || jsonutil.Unmarshal(favorite.Extra, &fileInfo) != nil || fileInfo.Path == "" {
+		return errorx.New(400, "文件不存在")
+	}
+
+	switch f.Filesystem.Driver() {
+	case filesystem.LocalDriver:
+		filePath := f.Filesystem.(*filesystem.LocalFilesystem).Path(f.Filesystem.BucketPrivateName(), fileInfo.Path)
+		ctx.FileAttachment(filePath, fileInfo.Name)
+	case filesystem.MinioDriver:
+		ctx.Redirect(http.StatusFound, f.Filesystem.PrivateUrl(f.Filesystem.BucketPrivateName(), fileInfo.Path, fileInfo.Name, 60*time.Second))
+	default:
+		return errorx.New(400, "未知文件驱动类型")
+	}
+
+	return nil
+}
+
+func splitFavoriteTags(value string) []string {
+	items := make([]string, 0)
+	for _, tag := range strings.Split(value, ",") {
+		if tag != "" {
+			items = append(items, tag)
+		}
+	}
+
+	return items
+}
+
+type FavoriteCreateRequest struct {
+	TalkMode int      `json:"talk_mode" binding:"required,oneof=1 2"`
+	ToFromId int      `json:"to_from_id" binding:"required,gt=0"`
+	MsgId    string   `json:"msg_id" binding:"required"`
+	Tags     []string `json:"tags"`
+}
+
+type FavoriteCreateResponse struct {
+	Id int `json:"id"`
+}
+
+type FavoriteDeleteRequest struct {
+	Id int `json:"id" binding:"required"`
+}
+
+type FavoriteDeleteResponse struct{}
+
+type FavoriteTagsRequest struct {
+	Id   int      `json:"id" binding:"required"`
+	Tags []string `json:"tags"`
+}
+
+type FavoriteTagsResponse struct{}
+
+type FavoriteTagListRequest struct{}
+
+type FavoriteTagListResponse struct {
+	Items []string `json:"items"`
+}
+
+type FavoriteListRequest struct {
+	Keyword string `json:"keyword" binding:"max=50"`
+	Tag     string `json:"tag"`
+	MsgType int    `json:"msg_type"`
+	Cursor  int    `json:"cursor"`
+	Limit   int    `json:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+type FavoriteItem struct {
+	Id        int      `json:"id"`
+	MsgType   int      `json:"msg_type"`
+	Extra     string   `json:"extra"`
+	Tags      []string `json:"tags"`
+	TalkMode  int      `json:"talk_mode"`
+	ToFromId  int      `json:"to_from_id"`
+	MsgId     string   `json:"msg_id"`
+	FromId    int      `json:"from_id"`
+	Nickname  string   `json:"nickname"`
+	SendTime  string   `json:"send_time"`
+	CreatedAt string   `json:"created_at"`
+}
+
+type FavoriteListResponse struct {
+	Items  []*FavoriteItem `json:"items"`
+	Cursor int             `json:"cursor"` // 下一页游标，即本页最后一条收藏ID
+}
+
+type FavoriteForwardRequest struct {
+	Ids      []int `json:"ids" binding:"required,min=1,max=10"`
+	UserIds  []int `json:"user_ids"`
+	GroupIds []int `json:"group_ids"`
+}
+
+type FavoriteForwardResponse struct{}
+
+type FavoriteDownloadRequest struct {
+	Id int `form:"id" json:"id" binding:"required"`
+}
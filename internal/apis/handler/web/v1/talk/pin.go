@@ -0,0 +1,134 @@
+package talk
+
+import (
+	"context"
+	"time"
+
+	"github.com/gzydong/go-chat/internal/entity"
+	"github.com/gzydong/go-chat/internal/pkg/core/middleware"
+	"github.com/gzydong/go-chat/internal/service"
+	"github.com/samber/lo"
+)
+
+type Pin struct {
+	TalkMessagePinService service.ITalkMessagePinService
+}
+
+// Create 置顶消息
+//
+//	@Summary		置顶消息
+//	@Description	置顶会话中的消息，群聊仅群主及管理员可操作
+//	@Tags			消息
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		talk.PinRequest	true	"置顶消息请求"
+//	@Success		200		{object}	talk.PinResponse
+//	@Router			/api/v1/message/pin/create [post]
+//	@Security		Bearer
+func (p *Pin) Create(ctx context.Context, in *PinRequest) (*PinResponse, error) {
+	err := p.TalkMessagePinService.Pin(ctx, &service.TalkMessagePinOption{
+		UserId:   middleware.FormContextAuthId[entity.WebClaims](ctx),
+		TalkMode: in.TalkMode,
+		ToFromId: in.ToFromId,
+		MsgId:    in.MsgId,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &PinResponse{}, nil
+}
+
+// Delete 取消置顶消息
+//
+//	@Summary		取消置顶消息
+//	@Description	取消会话中已置顶的消息，群聊仅群主及管理员可操作
+//	@Tags			消息
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		talk.PinRequest	true	"取消置顶消息请求"
+//	@Success		200		{object}	talk.PinResponse
+//	@Router			/api/v1/message/pin/delete [post]
+//	@Security		Bearer
+func (p *Pin) Delete(ctx context.Context, in *PinRequest) (*PinResponse, error) {
+	err := p.TalkMessagePinService.Unpin(ctx, &service.TalkMessagePinOption{
+		UserId:   middleware.FormContextAuthId[entity.WebClaims](ctx),
+		TalkMode: in.TalkMode,
+		ToFromId: in.ToFromId,
+		MsgId:    in.MsgId,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &PinResponse{}, nil
+}
+
+// List 置顶消息列表
+//
+//	@Summary		置顶消息列表
+//	@Description	获取会话的置顶消息，最近置顶的在前
+//	@Tags			消息
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		talk.PinListRequest	true	"置顶消息列表请求"
+//	@Success		200		{object}	talk.PinListResponse
+//	@Router			/api/v1/message/pin/list [post]
+//	@Security		Bearer
+func (p *Pin) List(ctx context.Context, in *PinListRequest) (*PinListResponse, error) {
+	uid := middleware.FormContextAuthId[entity.WebClaims](ctx)
+
+	list, err := p.TalkMessagePinService.List(ctx, uid, in.TalkMode, in.ToFromId)
+	if err != nil {
+		return nil, err
+	}
+
+	items := lo.Map(list, func(item *service.TalkMessagePinItem, _ int) *PinItem {
+		return &PinItem{
+			MsgId:    item.MsgId,
+			Sequence: item.Sequence,
+			MsgType:  item.MsgType,
+			FromId:   item.FromId,
+			Nickname: item.Nickname,
+			Avatar:   item.Avatar,
+			SendTime: item.SendTime.Format(time.DateTime),
+			Extra:    item.Extra,
+			Quote:    item.Quote,
+			PinnedBy: item.PinnedBy,
+			PinnedAt: item.PinnedAt.Format(time.DateTime),
+		}
+	})
+
+	return &PinListResponse{Items: items}, nil
+}
+
+type PinRequest struct {
+	TalkMode int    `json:"talk_mode" binding:"required,oneof=1 2"`
+	ToFromId int    `json:"to_from_id" binding:"required,gt=0"`
+	MsgId    string `json:"msg_id" binding:"required"`
+}
+
+type PinResponse struct{}
+
+type PinListRequest struct {
+	TalkMode int `json:"talk_mode" binding:"required,oneof=1 2"`
+	ToFromId int `json:"to_from_id" binding:"required,gt=0"`
+}
+
+type PinItem struct {
+	MsgId    string `json:"msg_id"`
+	Sequence int    `json:"sequence"`
+	MsgType  int    `json:"msg_type"`
+	FromId   int    `json:"from_id"`
+	Nickname string `json:"nickname"`
+	Avatar   string `json:"avatar"`
+	SendTime string `json:"send_time"`
+	Extra    string `json:"extra"`
+	Quote    string `json:"quote"`
+	PinnedBy int    `json:"pinned_by"`
+	PinnedAt string `json:"pinned_at"`
+}
+
+type PinListResponse struct {
+	Items []*PinItem `json:"items"`
+}
@@ -3,6 +3,9 @@ package talk
 import (
 	"context"
 	"html"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
@@ -10,23 +13,35 @@ import (
 	"github.com/gzydong/go-chat/internal/pkg/core/errorx"
 	"github.com/gzydong/go-chat/internal/pkg/core/middleware"
 	"github.com/gzydong/go-chat/internal/pkg/logger"
+	"github.com/gzydong/go-chat/internal/pkg/strutil"
+	"github.com/gzydong/go-chat/internal/repository/model"
 	"github.com/gzydong/go-chat/internal/service"
 	"github.com/gzydong/go-chat/internal/service/message"
+	"github.com/samber/lo"
 )
 
 var mapping map[string]func(ctx *gin.Context) error
 
+const scheduleIdContextKey = "__SCHEDULE_ID__"
+
 type Publish struct {
-	AuthService    service.IAuthService
-	MessageService message.IService
+	AuthService         service.IAuthService
+	MessageService      message.IService
+	TalkScheduleService service.ITalkScheduleService
+	ModerationService   service.IModerationService
+	GroupPermission     service.IGroupPermissionService
 }
 
 type BaseMessageRequest struct {
-	Type     string `json:"type" binding:"required"`            // 消息类型 text:文本消息 image:图片消息 voice:语音消息 video:视频消息 file:文件消息 location:位置消息
-	TalkMode int    `json:"talk_mode" binding:"required,gt=0"`  // 对话类型 1:私聊 2:群聊
-	ToFromId int    `json:"to_from_id" binding:"required,gt=0"` // 接受者ID (好友ID或者群ID)
-	QuoteId  string `json:"quote_id"`                           // 引用的消息ID
-	MsgId    string `json:"msg_id"`                             // 消息ID
+	Type       string `json:"type" binding:"required"`                         // 消息类型 text:文本消息 image:图片消息 voice:语音消息 video:视频消息 file:文件消息 location:位置消息
+	TalkMode   int    `json:"talk_mode" binding:"required,gt=0"`               // 对话类型 1:私聊 2:群聊
+	ToFromId   int    `json:"to_from_id" binding:"required,gt=0"`              // 接受者ID (好友ID或者群ID)
+	QuoteId    string `json:"quote_id"`                                        // 引用的消息ID
+	MsgId      string `json:"msg_id"`                                          // 消息ID
+	RootMsgId  string `json:"root_msg_id"`                                     // 话题根消息ID(仅群聊，回复到话题)
+	SendAt     string `json:"send_at"`                                         // 定时发送时间，格式 2006-01-02 15:04:05，为空时立即发送
+	ExpireMode int    `json:"expire_mode" binding:"omitempty,oneof=1 2"`       // 消息过期计时方式 1:发送后 2:首次阅读后(阅后即焚)
+	ExpireTtl  int    `json:"expire_ttl" binding:"omitempty,min=5,max=604800"` // 消息有效时长(秒)，为空时使用会话设置
 }
 
 // Send 发送消息接口
@@ -50,13 +65,26 @@ func (c *Publish) Send(ctx *gin.Context) (any, error) {
 		return nil, errorx.New(400, "msg_id 长度必须为30个字符")
 	}
 
+	if in.RootMsgId != "" && in.TalkMode != entity.ChatGroupMode {
+		return nil, errorx.New(400, "仅群聊支持话题回复")
+	}
+
+	if in.SendAt != "" && !c.TalkScheduleService.IsSupported(in.Type) {
+		return nil, errorx.New(400, "该消息类型不支持定时发送")
+	}
+
 	uid := middleware.FormContextAuthId[entity.WebClaims](ctx.Request.Context())
-	if err := c.AuthService.IsAuth(ctx.Request.Context(), &service.AuthOption{
+	auth := &service.AuthOption{
 		TalkType:          in.TalkMode,
 		UserId:            uid,
 		ToFromId:          in.ToFromId,
 		IsVerifyGroupMute: true,
-	}); err != nil {
+		IsVerifyAntiSpam:  in.SendAt == "" && in.Type != "forward", // 定时消息在发送时校验，转发消息按目标群校验
+		MsgType:           in.Type,
+		Content:           messageContent(ctx),
+	}
+
+	if err := c.AuthService.IsAuth(ctx.Request.Context(), auth); err != nil {
 		return nil, err
 	}
 
@@ -65,9 +93,81 @@ func (c *Publish) Send(ctx *gin.Context) (any, error) {
 		return nil, err
 	}
 
+	if id, ok := ctx.Get(scheduleIdContextKey); ok {
+		return map[string]any{"status": "ok", "schedule_id": id}, nil
+	}
+
+	c.AuthService.RecordSend(ctx.Request.Context(), auth)
+
 	return map[string]string{"status": "ok"}, nil
 }
 
+// dispatch 立即发送消息，指定了 send_at 时保存为定时消息，到时由定时任务发送
+func (c *Publish) dispatch(ctx *gin.Context, in *BaseMessageRequest, option any, send func() error) error {
+	if in.SendAt == "" {
+		return send()
+	}
+
+	sendAt, err := time.ParseInLocation(time.DateTime, in.SendAt, time.Local)
+	if err != nil {
+		return errorx.New(400, "send_at 格式错误")
+	}
+
+	id, err := c.TalkScheduleService.Create(ctx.Request.Context(), &service.TalkScheduleCreateOpt{
+		UserId:   middleware.FormContextAuthId[entity.WebClaims](ctx.Request.Context()),
+		TalkMode: in.TalkMode,
+		ToFromId: in.ToFromId,
+		MsgType:  in.Type,
+		Payload:  option,
+		SendAt:   sendAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx.Set(scheduleIdContextKey, id)
+	return nil
+}
+
+// messageExpire 发送消息时指定的过期设置，未指定时使用会话设置
+func messageExpire(in *BaseMessageRequest) *message.MessageExpire {
+	if in.ExpireTtl <= 0 {
+		return nil
+	}
+
+	return &message.MessageExpire{
+		Mode: lo.Ternary(in.ExpireMode == 0, model.TalkExpireModeAfterSend, in.ExpireMode),
+		Ttl:  in.ExpireTtl,
+	}
+}
+
+// messageContent 提取消息中的文本内容，用于群防刷屏校验
+func messageContent(ctx *gin.Context) string {
+	in := &struct {
+		Body struct {
+			Content string `json:"content"`
+			Code    string `json:"code"`
+			Items   []struct {
+				Type    int    `json:"type"`
+				Content string `json:"content"`
+			} `json:"items"`
+		} `json:"body"`
+	}{}
+
+	if err := ctx.ShouldBindBodyWith(in, binding.JSON); err != nil {
+		return ""
+	}
+
+	contents := []string{in.Body.Content, in.Body.Code}
+	for _, item := range in.Body.Items {
+		if item.Type == entity.ChatMsgTypeText {
+			contents = append(contents, item.Content)
+		}
+	}
+
+	return strings.Join(lo.Compact(contents), "\n")
+}
+
 type onSendTextMessage struct {
 	BaseMessageRequest
 	Body struct {
@@ -84,20 +184,62 @@ func (c *Publish) onSendText(ctx *gin.Context) error {
 	}
 
 	uid := middleware.FormContextAuthId[entity.WebClaims](ctx.Request.Context())
-	err := c.MessageService.CreateTextMessage(ctx.Request.Context(), message.CreateTextMessage{
-		MsgId:    in.MsgId,
-		TalkMode: in.TalkMode,
-		FromId:   uid,
-		ToFromId: in.ToFromId,
-		Content:  html.EscapeString(in.Body.Content),
-		QuoteId:  in.QuoteId,
-		Mentions: in.Body.Mentions,
+
+	if in.TalkMode == entity.ChatGroupMode && slices.Contains(in.Body.Mentions, model.MentionAll) {
+		if err := c.GroupPermission.Check(ctx.Request.Context(), in.ToFromId, uid, model.GroupPermMentionAll); err != nil {
+			return ctx.Error(err)
+		}
+	}
+
+	// 定时消息在发送时审核
+	moderation := &service.ModerationResult{Content: in.Body.Content}
+	if in.SendAt == "" {
+		result, err := c.ModerationService.Check(ctx.Request.Context(), &service.ModerationCheckOpt{
+			Scene:   model.ModerationSceneMessage,
+			UserId:  uid,
+			Content: in.Body.Content,
+		})
+		if err != nil {
+			return ctx.Error(err)
+		}
+
+		moderation = result
+	}
+
+	// 需人工审核的消息正常发送，审核驳回后撤回
+	if moderation.IsReview() && in.MsgId == "" {
+		in.MsgId = strutil.NewMsgId()
+	}
+
+	option := message.CreateTextMessage{
+		MsgId:     in.MsgId,
+		TalkMode:  in.TalkMode,
+		FromId:    uid,
+		ToFromId:  in.ToFromId,
+		RootMsgId: in.RootMsgId,
+		Expire:    messageExpire(&in.BaseMessageRequest),
+		Content:   html.EscapeString(moderation.Content),
+		QuoteId:   in.QuoteId,
+		Mentions:  in.Body.Mentions,
+	}
+
+	err := c.dispatch(ctx, &in.BaseMessageRequest, option, func() error {
+		return c.MessageService.CreateTextMessage(ctx.Request.Context(), option)
 	})
 
 	if err != nil {
 		return ctx.Error(err)
 	}
 
+	c.ModerationService.Flag(ctx.Request.Context(), &service.ModerationFlagOpt{
+		Scene:    model.ModerationSceneMessage,
+		UserId:   uid,
+		TalkMode: in.TalkMode,
+		ToFromId: in.ToFromId,
+		MsgId:    in.MsgId,
+		Result:   moderation,
+	})
+
 	return nil
 }
 
@@ -119,16 +261,22 @@ func (c *Publish) onSendImage(ctx *gin.Context) error {
 	}
 
 	uid := middleware.FormContextAuthId[entity.WebClaims](ctx.Request.Context())
-	err := c.MessageService.CreateImageMessage(ctx.Request.Context(), message.CreateImageMessage{
-		MsgId:    in.MsgId,
-		TalkMode: in.TalkMode,
-		FromId:   uid,
-		ToFromId: in.ToFromId,
-		QuoteId:  in.QuoteId,
-		Url:      in.Body.Url,
-		Width:    in.Body.Width,
-		Height:   in.Body.Height,
-		Size:     in.Body.Size,
+	option := message.CreateImageMessage{
+		MsgId:     in.MsgId,
+		TalkMode:  in.TalkMode,
+		FromId:    uid,
+		ToFromId:  in.ToFromId,
+		RootMsgId: in.RootMsgId,
+		Expire:    messageExpire(&in.BaseMessageRequest),
+		QuoteId:   in.QuoteId,
+		Url:       in.Body.Url,
+		Width:     in.Body.Width,
+		Height:    in.Body.Height,
+		Size:      in.Body.Size,
+	}
+
+	err := c.dispatch(ctx, &in.BaseMessageRequest, option, func() error {
+		return c.MessageService.CreateImageMessage(ctx.Request.Context(), option)
 	})
 
 	if err != nil {
@@ -155,13 +303,19 @@ func (c *Publish) onSendVoice(ctx *gin.Context) error {
 	}
 
 	uid := middleware.FormContextAuthId[entity.WebClaims](ctx.Request.Context())
-	err := c.MessageService.CreateVoiceMessage(ctx.Request.Context(), message.CreateVoiceMessage{
-		TalkMode: in.TalkMode,
-		FromId:   uid,
-		ToFromId: in.ToFromId,
-		Url:      in.Body.Url,
-		Duration: in.Body.Duration,
-		Size:     in.Body.Size,
+	option := message.CreateVoiceMessage{
+		TalkMode:  in.TalkMode,
+		FromId:    uid,
+		ToFromId:  in.ToFromId,
+		RootMsgId: in.RootMsgId,
+		Expire:    messageExpire(&in.BaseMessageRequest),
+		Url:       in.Body.Url,
+		Duration:  in.Body.Duration,
+		Size:      in.Body.Size,
+	}
+
+	err := c.dispatch(ctx, &in.BaseMessageRequest, option, func() error {
+		return c.MessageService.CreateVoiceMessage(ctx.Request.Context(), option)
 	})
 	if err != nil {
 		return ctx.Error(err)
@@ -188,14 +342,20 @@ func (c *Publish) onSendVideo(ctx *gin.Context) error {
 	}
 
 	uid := middleware.FormContextAuthId[entity.WebClaims](ctx.Request.Context())
-	err := c.MessageService.CreateVideoMessage(ctx.Request.Context(), message.CreateVideoMessage{
-		TalkMode: in.TalkMode,
-		FromId:   uid,
-		ToFromId: in.ToFromId,
-		Url:      in.Body.Url,
-		Duration: in.Body.Duration,
-		Size:     in.Body.Size,
-		Cover:    in.Body.Cover,
+	option := message.CreateVideoMessage{
+		TalkMode:  in.TalkMode,
+		FromId:    uid,
+		ToFromId:  in.ToFromId,
+		RootMsgId: in.RootMsgId,
+		Expire:    messageExpire(&in.BaseMessageRequest),
+		Url:       in.Body.Url,
+		Duration:  in.Body.Duration,
+		Size:      in.Body.Size,
+		Cover:     in.Body.Cover,
+	}
+
+	err := c.dispatch(ctx, &in.BaseMessageRequest, option, func() error {
+		return c.MessageService.CreateVideoMessage(ctx.Request.Context(), option)
 	})
 	if err != nil {
 		return ctx.Error(err)
@@ -220,10 +380,12 @@ func (c *Publish) onSendFile(ctx *gin.Context) error {
 
 	uid := middleware.FormContextAuthId[entity.WebClaims](ctx.Request.Context())
 	err := c.MessageService.CreateFileMessage(ctx.Request.Context(), message.CreateFileMessage{
-		TalkMode: in.TalkMode,
-		FromId:   uid,
-		ToFromId: in.ToFromId,
-		UploadId: in.Body.UploadId,
+		TalkMode:  in.TalkMode,
+		FromId:    uid,
+		ToFromId:  in.ToFromId,
+		RootMsgId: in.RootMsgId,
+		Expire:    messageExpire(&in.BaseMessageRequest),
+		UploadId:  in.Body.UploadId,
 	})
 
 	if err != nil {
@@ -249,13 +411,19 @@ func (c *Publish) onSendCode(ctx *gin.Context) error {
 	}
 
 	uid := middleware.FormContextAuthId[entity.WebClaims](ctx.Request.Context())
-	err := c.MessageService.CreateCodeMessage(ctx.Request.Context(), message.CreateCodeMessage{
-		MsgId:    in.MsgId,
-		TalkMode: in.TalkMode,
-		FromId:   uid,
-		ToFromId: in.ToFromId,
-		Code:     in.Body.Code,
-		Lang:     in.Body.Lang,
+	option := message.CreateCodeMessage{
+		MsgId:     in.MsgId,
+		TalkMode:  in.TalkMode,
+		FromId:    uid,
+		ToFromId:  in.ToFromId,
+		RootMsgId: in.RootMsgId,
+		Expire:    messageExpire(&in.BaseMessageRequest),
+		Code:      in.Body.Code,
+		Lang:      in.Body.Lang,
+	}
+
+	err := c.dispatch(ctx, &in.BaseMessageRequest, option, func() error {
+		return c.MessageService.CreateCodeMessage(ctx.Request.Context(), option)
 	})
 	if err != nil {
 		return ctx.Error(err)
@@ -281,14 +449,20 @@ func (c *Publish) onSendLocation(ctx *gin.Context) error {
 	}
 
 	uid := middleware.FormContextAuthId[entity.WebClaims](ctx.Request.Context())
-	err := c.MessageService.CreateLocationMessage(ctx.Request.Context(), message.CreateLocationMessage{
+	option := message.CreateLocationMessage{
 		MsgId:       in.MsgId,
 		TalkMode:    in.TalkMode,
 		FromId:      uid,
 		ToFromId:    in.ToFromId,
+		RootMsgId:   in.RootMsgId,
+		Expire:      messageExpire(&in.BaseMessageRequest),
 		Longitude:   in.Body.Longitude,
 		Latitude:    in.Body.Latitude,
 		Description: in.Body.Description,
+	}
+
+	err := c.dispatch(ctx, &in.BaseMessageRequest, option, func() error {
+		return c.MessageService.CreateLocationMessage(ctx.Request.Context(), option)
 	})
 	if err != nil {
 		return ctx.Error(err)
@@ -319,18 +493,50 @@ func (c *Publish) onSendForward(ctx *gin.Context) error {
 	}
 
 	uid := middleware.FormContextAuthId[entity.WebClaims](ctx.Request.Context())
-	go func() {
-		err := c.MessageService.CreateForwardMessage(context.Background(), message.CreateForwardMessage{
-			TalkMode: in.TalkMode,
-			FromId:   uid,
-			ToFromId: in.ToFromId,
-			Action:   int(in.Body.Action),
-			MsgIds:   in.Body.MsgIds,
-			Gids:     in.Body.GroupIds,
-			Uids:     in.Body.UserIds,
-			UserId:   uid,
-		})
+	option := message.CreateForwardMessage{
+		TalkMode: in.TalkMode,
+		FromId:   uid,
+		ToFromId: in.ToFromId,
+		Action:   int(in.Body.Action),
+		MsgIds:   in.Body.MsgIds,
+		Gids:     in.Body.GroupIds,
+		Uids:     in.Body.UserIds,
+		UserId:   uid,
+	}
+
+	// 转发到群聊时按被转发消息的内容校验目标群的禁言及防刷屏限制
+	auths := make([]*service.AuthOption, 0, len(in.Body.GroupIds))
+	if len(in.Body.GroupIds) > 0 {
+		content, hasFile, err := c.MessageService.ForwardContent(ctx.Request.Context(), option)
 		if err != nil {
+			return ctx.Error(err)
+		}
+
+		for _, groupId := range lo.Uniq(in.Body.GroupIds) {
+			auth := &service.AuthOption{
+				TalkType:          entity.ChatGroupMode,
+				UserId:            uid,
+				ToFromId:          groupId,
+				IsVerifyGroupMute: true,
+				IsVerifyAntiSpam:  true,
+				MsgType:           lo.Ternary(hasFile, "file", in.Type),
+				Content:           content,
+			}
+
+			if err := c.AuthService.IsAuth(ctx.Request.Context(), auth); err != nil {
+				return ctx.Error(err)
+			}
+
+			auths = append(auths, auth)
+		}
+	}
+
+	for _, auth := range auths {
+		c.AuthService.RecordSend(ctx.Request.Context(), auth)
+	}
+
+	go func() {
+		if err := c.MessageService.CreateForwardMessage(context.Background(), option); err != nil {
 			logger.Errorf(err.Error())
 		}
 	}()
@@ -353,11 +559,17 @@ func (c *Publish) onSendEmoticon(ctx *gin.Context) error {
 	}
 
 	uid := middleware.FormContextAuthId[entity.WebClaims](ctx.Request.Context())
-	err := c.MessageService.CreateEmoticonMessage(ctx.Request.Context(), message.CreateEmoticonMessage{
+	option := message.CreateEmoticonMessage{
 		TalkMode:   in.TalkMode,
 		FromId:     uid,
 		ToFromId:   in.ToFromId,
+		RootMsgId:  in.RootMsgId,
+		Expire:     messageExpire(&in.BaseMessageRequest),
 		EmoticonId: in.Body.EmoticonId,
+	}
+
+	err := c.dispatch(ctx, &in.BaseMessageRequest, option, func() error {
+		return c.MessageService.CreateEmoticonMessage(ctx.Request.Context(), option)
 	})
 	if err != nil {
 		return ctx.Error(err)
@@ -381,12 +593,16 @@ func (c *Publish) onSendCard(ctx *gin.Context) error {
 	}
 
 	uid := middleware.FormContextAuthId[entity.WebClaims](ctx.Request.Context())
-	err := c.MessageService.CreateBusinessCardMessage(ctx.Request.Context(), message.CreateBusinessCardMessage{
+	option := message.CreateBusinessCardMessage{
 		MsgId:    in.MsgId,
 		TalkMode: in.TalkMode,
 		FromId:   uid,
 		ToFromId: in.ToFromId,
 		UserId:   in.Body.UserId,
+	}
+
+	err := c.dispatch(ctx, &in.BaseMessageRequest, option, func() error {
+		return c.MessageService.CreateBusinessCardMessage(ctx.Request.Context(), option)
 	})
 	if err != nil {
 		return ctx.Error(err)
@@ -421,13 +637,19 @@ func (c *Publish) onMixedMessage(ctx *gin.Context) error {
 	}
 
 	uid := middleware.FormContextAuthId[entity.WebClaims](ctx.Request.Context())
-	err := c.MessageService.CreateMixedMessage(ctx.Request.Context(), message.CreateMixedMessage{
+	option := message.CreateMixedMessage{
 		MsgId:       in.MsgId,
 		TalkMode:    in.TalkMode,
 		FromId:      uid,
 		ToFromId:    in.ToFromId,
+		RootMsgId:   in.RootMsgId,
+		Expire:      messageExpire(&in.BaseMessageRequest),
 		QuoteId:     in.QuoteId,
 		MessageList: items,
+	}
+
+	err := c.dispatch(ctx, &in.BaseMessageRequest, option, func() error {
+		return c.MessageService.CreateMixedMessage(ctx.Request.Context(), option)
 	})
 	if err != nil {
 		return ctx.Error(err)
@@ -0,0 +1,149 @@
+package talk
+
+import (
+	"context"
+	"time"
+
+	"github.com/gzydong/go-chat/internal/entity"
+	"github.com/gzydong/go-chat/internal/pkg/core/errorx"
+	"github.com/gzydong/go-chat/internal/pkg/core/middleware"
+	"github.com/gzydong/go-chat/internal/repository/model"
+	"github.com/gzydong/go-chat/internal/service"
+	"github.com/samber/lo"
+)
+
+type Schedule struct {
+	TalkScheduleService service.ITalkScheduleService
+}
+
+// List 定时消息列表
+//
+//	@Summary		定时消息列表
+//	@Description	分页获取当前用户创建的定时消息
+//	@Tags			消息
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		talk.ScheduleListRequest	true	"定时消息列表请求"
+//	@Success		200		{object}	talk.ScheduleListResponse
+//	@Router			/api/v1/message/schedule/list [post]
+//	@Security		Bearer
+func (s *Schedule) List(ctx context.Context, in *ScheduleListRequest) (*ScheduleListResponse, error) {
+	uid := middleware.FormContextAuthId[entity.WebClaims](ctx)
+
+	if in.Page <= 0 {
+		in.Page = 1
+	}
+
+	if in.PageSize <= 0 || in.PageSize > 100 {
+		in.PageSize = 20
+	}
+
+	total, list, err := s.TalkScheduleService.List(ctx, &service.TalkScheduleListOpt{
+		UserId:   uid,
+		Status:   in.Status,
+		Page:     in.Page,
+		PageSize: in.PageSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	items := lo.Map(list, func(item *model.TalkScheduledMessage, _ int) *ScheduleItem {
+		return &ScheduleItem{
+			Id:         item.Id,
+			TalkMode:   item.TalkMode,
+			ToFromId:   item.ToFromId,
+			Type:       item.MsgType,
+			Payload:    item.Payload,
+			SendAt:     item.SendAt.Format(time.DateTime),
+			Status:     item.Status,
+			FailReason: item.FailReason,
+			CreatedAt:  item.CreatedAt.Format(time.DateTime),
+		}
+	})
+
+	return &ScheduleListResponse{Items: items, Total: total}, nil
+}
+
+// Cancel 取消定时消息
+//
+//	@Summary		取消定时消息
+//	@Description	取消尚未发送的定时消息
+//	@Tags			消息
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		talk.ScheduleCancelRequest	true	"取消定时消息请求"
+//	@Success		200		{object}	talk.ScheduleCancelResponse
+//	@Router			/api/v1/message/schedule/cancel [post]
+//	@Security		Bearer
+func (s *Schedule) Cancel(ctx context.Context, in *ScheduleCancelRequest) (*ScheduleCancelResponse, error) {
+	uid := middleware.FormContextAuthId[entity.WebClaims](ctx)
+
+	if err := s.TalkScheduleService.Cancel(ctx, uid, in.Id); err != nil {
+		return nil, err
+	}
+
+	return &ScheduleCancelResponse{}, nil
+}
+
+// Reschedule 修改定时消息发送时间
+//
+//	@Summary		修改定时消息发送时间
+//	@Description	修改尚未发送的定时消息的发送时间
+//	@Tags			消息
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		talk.ScheduleRescheduleRequest	true	"修改发送时间请求"
+//	@Success		200		{object}	talk.ScheduleRescheduleResponse
+//	@Router			/api/v1/message/schedule/reschedule [post]
+//	@Security		Bearer
+func (s *Schedule) Reschedule(ctx context.Context, in *ScheduleRescheduleRequest) (*ScheduleRescheduleResponse, error) {
+	uid := middleware.FormContextAuthId[entity.WebClaims](ctx)
+
+	sendAt, err := time.ParseInLocation(time.DateTime, in.SendAt, time.Local)
+	if err != nil {
+		return nil, errorx.New(400, "send_at 格式错误")
+	}
+
+	if err := s.TalkScheduleService.Reschedule(ctx, uid, in.Id, sendAt); err != nil {
+		return nil, err
+	}
+
+	return &ScheduleRescheduleResponse{}, nil
+}
+
+type ScheduleListRequest struct {
+	Status   int `json:"status" binding:"omitempty,oneof=1 2 3 4 5"`
+	Page     int `json:"page"`
+	PageSize int `json:"page_size"`
+}
+
+type ScheduleItem struct {
+	Id         int    `json:"id"`
+	TalkMode   int    `json:"talk_mode"`
+	ToFromId   int    `json:"to_from_id"`
+	Type       string `json:"type"`
+	Payload    string `json:"payload"`
+	SendAt     string `json:"send_at"`
+	Status     int    `json:"status"`
+	FailReason string `json:"fail_reason"`
+	CreatedAt  string `json:"created_at"`
+}
+
+type ScheduleListResponse struct {
+	Items []*ScheduleItem `json:"items"`
+	Total int64           `json:"total"`
+}
+
+type ScheduleCancelRequest struct {
+	Id int `json:"id" binding:"required,gt=0"`
+}
+
+type ScheduleCancelResponse struct{}
+
+type ScheduleRescheduleRequest struct {
+	Id     int    `json:"id" binding:"required,gt=0"`
+	SendAt string `json:"send_at" binding:"required"` // 格式 2006-01-02 15:04:05
+}
+
+type ScheduleRescheduleResponse struct{}
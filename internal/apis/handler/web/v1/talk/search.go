@@ -0,0 +1,139 @@
+package talk
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/gzydong/go-chat/internal/entity"
+	"github.com/gzydong/go-chat/internal/pkg/core/errorx"
+	"github.com/gzydong/go-chat/internal/pkg/core/middleware"
+	"github.com/gzydong/go-chat/internal/repository/model"
+	"github.com/gzydong/go-chat/internal/service"
+	"github.com/samber/lo"
+)
+
+type Search struct {
+	TalkSearchService service.ITalkSearchService
+}
+
+// Records 全文检索聊天记录
+//
+//	@Summary		检索聊天记录
+//	@Description	按关键词检索当前用户可见的私聊及群聊消息，支持按会话、发送者、时间范围及消息类型过滤
+//	@Tags			消息
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		talk.SearchRecordsRequest	true	"检索请求"
+//	@Success		200		{object}	talk.SearchRecordsResponse
+//	@Router			/api/v1/message/search [post]
+//	@Security		Bearer
+func (s *Search) Records(ctx context.Context, in *SearchRecordsRequest) (*SearchRecordsResponse, error) {
+	uid := middleware.FormContextAuthId[entity.WebClaims](ctx)
+
+	in.Keyword = strings.TrimSpace(in.Keyword)
+	if in.Keyword == "" {
+		return nil, errorx.New(400, "请输入搜索关键词")
+	}
+
+	if in.ToFromId > 0 && in.TalkMode == 0 {
+		return nil, errorx.New(400, "按会话检索时需指定对话类型")
+	}
+
+	startTime, err := parseSearchTime(in.StartTime)
+	if err != nil {
+		return nil, errorx.New(400, "开始时间格式错误")
+	}
+
+	endTime, err := parseSearchTime(in.EndTime)
+	if err != nil {
+		return nil, errorx.New(400, "结束时间格式错误")
+	}
+
+	// 仅指定日期时包含当天全部消息
+	if len(in.EndTime) == len(time.DateOnly) {
+		endTime = endTime.Add(24*time.Hour - time.Second)
+	}
+
+	if in.Limit <= 0 || in.Limit > 100 {
+		in.Limit = 30
+	}
+
+	result, err := s.TalkSearchService.Search(ctx, &service.SearchTalkRecordsOpt{
+		UserId:    uid,
+		Keyword:   in.Keyword,
+		TalkMode:  in.TalkMode,
+		ToFromId:  in.ToFromId,
+		FromId:    in.FromId,
+		MsgTypes:  in.MsgTypes,
+		StartTime: startTime,
+		EndTime:   endTime,
+		Cursor:    in.Cursor,
+		Limit:     in.Limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	items := lo.Map(result.Items, func(item *model.TalkMessageRecord, _ int) *SearchRecordItem {
+		return &SearchRecordItem{
+			TalkMode: item.TalkMode,
+			ToFromId: item.ToFromId,
+			MsgId:    item.MsgId,
+			Sequence: item.Sequence,
+			MsgType:  item.MsgType,
+			FromId:   item.FromId,
+			Nickname: item.Nickname,
+			Avatar:   item.Avatar,
+			SendTime: item.SendTime.Format(time.DateTime),
+			Extra:    item.Extra,
+			Quote:    item.Quote,
+		}
+	})
+
+	return &SearchRecordsResponse{Items: items, Cursor: result.Cursor}, nil
+}
+
+// parseSearchTime 解析时间，支持日期或日期时间格式
+func parseSearchTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if len(value) == len(time.DateOnly) {
+		return time.ParseInLocation(time.DateOnly, value, time.Local)
+	}
+
+	return time.ParseInLocation(time.DateTime, value, time.Local)
+}
+
+type SearchRecordsRequest struct {
+	Keyword   string `json:"keyword" binding:"required,max=100"`
+	TalkMode  int    `json:"talk_mode" binding:"omitempty,oneof=1 2"`
+	ToFromId  int    `json:"to_from_id"`
+	FromId    int    `json:"from_id"`
+	MsgTypes  []int  `json:"msg_types"`
+	StartTime string `json:"start_time"` // 格式 2006-01-02 或 2006-01-02 15:04:05
+	EndTime   string `json:"end_time"`
+	Cursor    string `json:"cursor"`
+	Limit     int    `json:"limit"`
+}
+
+type SearchRecordItem struct {
+	TalkMode int    `json:"talk_mode"`
+	ToFromId int    `json:"to_from_id"`
+	MsgId    string `json:"msg_id"`
+	Sequence int    `json:"sequence"`
+	MsgType  int    `json:"msg_type"`
+	FromId   int    `json:"from_id"`
+	Nickname string `json:"nickname"`
+	Avatar   string `json:"avatar"`
+	SendTime string `json:"send_time"`
+	Extra    string `json:"extra"`
+	Quote    string `json:"quote"`
+}
+
+type SearchRecordsResponse struct {
+	Items  []*SearchRecordItem `json:"items"`
+	Cursor string              `json:"cursor"`
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/gzydong/go-chat/api/pb/web/v1"
 	"github.com/gzydong/go-chat/internal/entity"
 	"github.com/gzydong/go-chat/internal/pkg/core/middleware"
+	"github.com/gzydong/go-chat/internal/pkg/logger"
 	"github.com/gzydong/go-chat/internal/pkg/timeutil"
 	"github.com/gzydong/go-chat/internal/repository/cache"
 	"github.com/gzydong/go-chat/internal/repository/repo"
@@ -16,18 +17,20 @@ import (
 var _ web.ITalkHandler = (*Session)(nil)
 
 type Session struct {
-	RedisLock          *cache.RedisLock
-	MessageStorage     *cache.MessageStorage
-	UnreadStorage      *cache.UnreadStorage
-	ContactRemark      *cache.ContactRemark
-	ContactRepo        *repo.Contact
-	UsersRepo          *repo.Users
-	GroupRepo          *repo.Group
-	TalkService        service.ITalkService
-	TalkSessionService service.ITalkSessionService
-	UserService        service.IUserService
-	GroupService       service.IGroupService
-	AuthService        service.IAuthService
+	RedisLock                *cache.RedisLock
+	MessageStorage           *cache.MessageStorage
+	UnreadStorage            *cache.UnreadStorage
+	ContactRemark            *cache.ContactRemark
+	ContactRepo              *repo.Contact
+	UsersRepo                *repo.Users
+	GroupRepo                *repo.Group
+	TalkService              service.ITalkService
+	TalkSessionService       service.ITalkSessionService
+	UserService              service.IUserService
+	GroupService             service.IGroupService
+	AuthService              service.IAuthService
+	TalkMessageExpireService service.ITalkMessageExpireService
+	TalkSyncService          service.ITalkSyncService
 }
 
 // SessionCreate 会话创建接口
@@ -306,6 +309,18 @@ func (s *Session) SessionList(ctx context.Context, req *web.TalkSessionListReque
 //	@Security		Bearer
 func (s *Session) SessionClearUnreadNum(ctx context.Context, in *web.TalkSessionClearUnreadNumRequest) (*web.TalkSessionClearUnreadNumResponse, error) {
 	uid := middleware.FormContextAuthId[entity.WebClaims](ctx)
+
+	// 存在未读消息时记录已读位置，同步至其它设备
+	if s.UnreadStorage.Get(ctx, uid, int(in.TalkMode), int(in.ToFromId)) > 0 {
+		s.TalkSyncService.RecordRead(ctx, uid, int(in.TalkMode), int(in.ToFromId))
+	}
+
 	s.UnreadStorage.Reset(ctx, uid, int(in.TalkMode), int(in.ToFromId))
+
+	// 会话内的阅后即焚消息视为已读，开始销毁计时
+	if err := s.TalkMessageExpireService.MarkRead(ctx, uid, int(in.TalkMode), int(in.ToFromId), nil); err != nil {
+		logger.Errorf("clear unread mark expire read err: %s", err.Error())
+	}
+
 	return &web.TalkSessionClearUnreadNumResponse{}, nil
 }
@@ -0,0 +1,100 @@
+package talk
+
+import (
+	"context"
+	"time"
+
+	"github.com/gzydong/go-chat/internal/entity"
+	"github.com/gzydong/go-chat/internal/pkg/core/middleware"
+	"github.com/gzydong/go-chat/internal/service"
+	"github.com/samber/lo"
+)
+
+type Sync struct {
+	TalkSyncService service.ITalkSyncService
+}
+
+// Sync 多端增量同步
+//
+//	@Summary		增量同步
+//	@Description	返回同步位置之后的全部变更（新消息、撤回、删除、会话置顶/免打扰、已读位置），未传同步位置时仅返回当前最新位置
+//	@Tags			消息
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		talk.SyncRequest	true	"同步请求"
+//	@Success		200		{object}	talk.SyncResponse
+//	@Router			/api/v1/message/sync [post]
+//	@Security		Bearer
+func (s *Sync) Sync(ctx context.Context, in *SyncRequest) (*SyncResponse, error) {
+	uid := middleware.FormContextAuthId[entity.WebClaims](ctx)
+
+	if in.Limit <= 0 || in.Limit > 200 {
+		in.Limit = 100
+	}
+
+	result, err := s.TalkSyncService.Sync(ctx, &service.TalkSyncOpt{
+		UserId:     uid,
+		Checkpoint: in.Checkpoint,
+		Limit:      in.Limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	items := lo.Map(result.Items, func(item *service.TalkSyncItem, _ int) *SyncItem {
+		value := &SyncItem{
+			Event:    item.Event,
+			TalkMode: item.TalkMode,
+			ToFromId: item.ToFromId,
+		}
+
+		if item.Payload != nil {
+			value.MsgId = item.Payload.MsgId
+			value.MsgIds = item.Payload.MsgIds
+			value.Value = item.Payload.Value
+			value.Sequence = item.Payload.Sequence
+		}
+
+		if item.Message != nil {
+			value.Message = &SearchRecordItem{
+				TalkMode: item.Message.TalkMode,
+				ToFromId: item.Message.ToFromId,
+				MsgId:    item.Message.MsgId,
+				Sequence: item.Message.Sequence,
+				MsgType:  item.Message.MsgType,
+				FromId:   item.Message.FromId,
+				Nickname: item.Message.Nickname,
+				Avatar:   item.Message.Avatar,
+				SendTime: item.Message.SendTime.Format(time.DateTime),
+				Extra:    item.Message.Extra,
+				Quote:    item.Message.Quote,
+			}
+		}
+
+		return value
+	})
+
+	return &SyncResponse{Items: items, Checkpoint: result.Checkpoint, HasMore: result.HasMore}, nil
+}
+
+type SyncRequest struct {
+	Checkpoint string `json:"checkpoint"` // 上次同步返回的同步位置，首次同步传空
+	Limit      int    `json:"limit"`
+}
+
+type SyncItem struct {
+	Event    string            `json:"event"` // message:新消息 message.revoke:撤回 message.delete:删除 session.top:置顶 session.disturb:免打扰 session.read:已读
+	TalkMode int               `json:"talk_mode"`
+	ToFromId int               `json:"to_from_id"`
+	MsgId    string            `json:"msg_id,omitempty"`
+	MsgIds   []string          `json:"msg_ids,omitempty"`
+	Value    int               `json:"value,omitempty"`
+	Sequence int64             `json:"sequence,omitempty"`
+	Message  *SearchRecordItem `json:"message,omitempty"`
+}
+
+type SyncResponse struct {
+	Items      []*SyncItem `json:"items"`
+	Checkpoint string      `json:"checkpoint"`
+	HasMore    bool        `json:"has_more"`
+}
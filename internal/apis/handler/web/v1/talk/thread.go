@@ -0,0 +1,160 @@
+package talk
+
+import (
+	"context"
+	"time"
+
+	"github.com/gzydong/go-chat/internal/entity"
+	"github.com/gzydong/go-chat/internal/pkg/core/errorx"
+	"github.com/gzydong/go-chat/internal/pkg/core/middleware"
+	"github.com/gzydong/go-chat/internal/repository/model"
+	"github.com/gzydong/go-chat/internal/service"
+	"github.com/samber/lo"
+)
+
+type Thread struct {
+	AuthService        service.IAuthService
+	TalkRecordsService service.ITalkRecordService
+}
+
+// Records 获取话题回复记录
+//
+//	@Summary		获取话题回复记录
+//	@Description	按发送顺序分页获取群消息话题下的回复
+//	@Tags			消息
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		talk.ThreadRecordsRequest	true	"话题回复记录请求"
+//	@Success		200		{object}	talk.ThreadRecordsResponse
+//	@Router			/api/v1/message/thread/records [post]
+//	@Security		Bearer
+func (t *Thread) Records(ctx context.Context, in *ThreadRecordsRequest) (*ThreadRecordsResponse, error) {
+	uid := middleware.FormContextAuthId[entity.WebClaims](ctx)
+
+	if err := t.AuthService.IsAuth(ctx, &service.AuthOption{
+		TalkType: entity.ChatGroupMode,
+		UserId:   uid,
+		ToFromId: in.GroupId,
+	}); err != nil {
+		return nil, errorx.New(403, "暂无权限查看群消息")
+	}
+
+	if in.Limit <= 0 || in.Limit > 100 {
+		in.Limit = 30
+	}
+
+	records, err := t.TalkRecordsService.FindThreadRecords(ctx, &service.FindThreadRecordsOpt{
+		UserId:    uid,
+		GroupId:   in.GroupId,
+		RootMsgId: in.RootMsgId,
+		Cursor:    in.Cursor,
+		Limit:     in.Limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	cursor := in.Cursor
+	if length := len(records); length > 0 {
+		cursor = records[length-1].Sequence
+	}
+
+	items := lo.Map(records, func(item *model.TalkMessageRecord, _ int) *ThreadRecordItem {
+		return &ThreadRecordItem{
+			MsgId:     item.MsgId,
+			Sequence:  item.Sequence,
+			MsgType:   item.MsgType,
+			FromId:    item.FromId,
+			Nickname:  item.Nickname,
+			Avatar:    item.Avatar,
+			IsRevoked: item.IsRevoked,
+			SendTime:  item.SendTime.Format(time.DateTime),
+			Extra:     lo.Ternary(item.IsRevoked == model.Yes, "{}", item.Extra),
+			Quote:     item.Quote,
+		}
+	})
+
+	return &ThreadRecordsResponse{Items: items, Cursor: cursor}, nil
+}
+
+// Summary 批量获取根消息的话题回复汇总
+//
+//	@Summary		获取话题回复汇总
+//	@Description	批量获取根消息的回复数及最后回复信息
+//	@Tags			消息
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		talk.ThreadSummaryRequest	true	"话题汇总请求"
+//	@Success		200		{object}	talk.ThreadSummaryResponse
+//	@Router			/api/v1/message/thread/summary [post]
+//	@Security		Bearer
+func (t *Thread) Summary(ctx context.Context, in *ThreadSummaryRequest) (*ThreadSummaryResponse, error) {
+	uid := middleware.FormContextAuthId[entity.WebClaims](ctx)
+
+	if err := t.AuthService.IsAuth(ctx, &service.AuthOption{
+		TalkType: entity.ChatGroupMode,
+		UserId:   uid,
+		ToFromId: in.GroupId,
+	}); err != nil {
+		return nil, errorx.New(403, "暂无权限查看群消息")
+	}
+
+	list, err := t.TalkRecordsService.FindThreadSummaries(ctx, in.GroupId, in.MsgIds)
+	if err != nil {
+		return nil, err
+	}
+
+	items := lo.Map(list, func(item *model.TalkGroupThread, _ int) *ThreadSummaryItem {
+		return &ThreadSummaryItem{
+			RootMsgId:       item.RootMsgId,
+			ReplyCount:      item.ReplyCount,
+			LastReplyMsgId:  item.LastReplyMsgId,
+			LastReplyUserId: item.LastReplyUserId,
+			LastReplyAt:     item.LastReplyAt.Format(time.DateTime),
+		}
+	})
+
+	return &ThreadSummaryResponse{Items: items}, nil
+}
+
+type ThreadRecordsRequest struct {
+	GroupId   int    `json:"group_id" binding:"required,gt=0"`
+	RootMsgId string `json:"root_msg_id" binding:"required"`
+	Cursor    int    `json:"cursor"`
+	Limit     int    `json:"limit"`
+}
+
+type ThreadRecordItem struct {
+	MsgId     string `json:"msg_id"`
+	Sequence  int    `json:"sequence"`
+	MsgType   int    `json:"msg_type"`
+	FromId    int    `json:"from_id"`
+	Nickname  string `json:"nickname"`
+	Avatar    string `json:"avatar"`
+	IsRevoked int    `json:"is_revoked"`
+	SendTime  string `json:"send_time"`
+	Extra     string `json:"extra"`
+	Quote     string `json:"quote"`
+}
+
+type ThreadRecordsResponse struct {
+	Items  []*ThreadRecordItem `json:"items"`
+	Cursor int                 `json:"cursor"`
+}
+
+type ThreadSummaryRequest struct {
+	GroupId int      `json:"group_id" binding:"required,gt=0"`
+	MsgIds  []string `json:"msg_ids" binding:"required,max=100"`
+}
+
+type ThreadSummaryItem struct {
+	RootMsgId       string `json:"root_msg_id"`
+	ReplyCount      int    `json:"reply_count"`
+	LastReplyMsgId  string `json:"last_reply_msg_id"`
+	LastReplyUserId int    `json:"last_reply_user_id"`
+	LastReplyAt     string `json:"last_reply_at"`
+}
+
+type ThreadSummaryResponse struct {
+	Items []*ThreadSummaryItem `json:"items"`
+}
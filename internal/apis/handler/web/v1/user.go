@@ -11,6 +11,7 @@ import (
 	"github.com/gzydong/go-chat/internal/pkg/encrypt"
 	"github.com/gzydong/go-chat/internal/pkg/encrypt/rsautil"
 	"github.com/gzydong/go-chat/internal/pkg/timeutil"
+	"github.com/gzydong/go-chat/internal/repository/model"
 	"github.com/gzydong/go-chat/internal/repository/repo"
 	"github.com/gzydong/go-chat/internal/service"
 	"github.com/redis/go-redis/v9"
@@ -20,12 +21,13 @@ import (
 var _ web.IUserHandler = (*User)(nil)
 
 type User struct {
-	Redis        *redis.Client
-	UsersRepo    *repo.Users
-	OrganizeRepo *repo.Organize
-	UserService  service.IUserService
-	SmsService   service.ISmsService
-	Rsa          rsautil.IRsa
+	Redis             *redis.Client
+	UsersRepo         *repo.Users
+	OrganizeRepo      *repo.Organize
+	UserService       service.IUserService
+	SmsService        service.ISmsService
+	Rsa               rsautil.IRsa
+	ModerationService service.IModerationService
 }
 
 // Detail 获取登录用户详情接口
@@ -123,8 +125,17 @@ func (u *User) DetailUpdate(ctx context.Context, req *web.UserDetailUpdateReques
 
 	uid := session.UserId
 
-	_, err := u.UsersRepo.UpdateById(ctx, uid, map[string]any{
-		"nickname": strings.TrimSpace(strings.ReplaceAll(req.Nickname, " ", "")),
+	moderation, err := u.ModerationService.Check(ctx, &service.ModerationCheckOpt{
+		Scene:   model.ModerationSceneNickname,
+		UserId:  int(uid),
+		Content: strings.TrimSpace(strings.ReplaceAll(req.Nickname, " ", "")),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = u.UsersRepo.UpdateById(ctx, uid, map[string]any{
+		"nickname": moderation.Content,
 		"avatar":   req.Avatar,
 		"gender":   req.Gender,
 		"motto":    req.Motto,
@@ -136,6 +147,14 @@ func (u *User) DetailUpdate(ctx context.Context, req *web.UserDetailUpdateReques
 	}
 
 	_ = u.UsersRepo.ClearTableCache(ctx, int(uid))
+
+	u.ModerationService.Flag(ctx, &service.ModerationFlagOpt{
+		Scene:    model.ModerationSceneNickname,
+		UserId:   int(uid),
+		TargetId: int(uid),
+		Result:   moderation,
+	})
+
 	return &web.UserDetailUpdateResponse{}, nil
 }
 
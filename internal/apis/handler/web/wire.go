@@ -20,20 +20,36 @@ var ProviderSet = wire.NewSet(
 	wire.Struct(new(v1.KYC), "*"),
 	wire.Struct(new(v1.Wallet), "*"),
 	wire.Struct(new(v1.GroupRobot), "*"),
+	wire.Struct(new(v1.Report), "*"),
 	v1.NewTrtc,
 
 	wire.Struct(new(contact.Contact), "*"),
 	wire.Struct(new(contact.Apply), "*"),
 	wire.Struct(new(contact.Group), "*"),
+	wire.Struct(new(contact.Block), "*"),
 
 	wire.Struct(new(group.Group), "*"),
 	wire.Struct(new(group.Apply), "*"),
 	wire.Struct(new(group.Notice), "*"),
 	wire.Struct(new(group.Vote), "*"),
+	wire.Struct(new(group.Mute), "*"),
+	wire.Struct(new(group.Member), "*"),
+	wire.Struct(new(group.Permission), "*"),
+	wire.Struct(new(group.Join), "*"),
+	wire.Struct(new(group.AntiSpam), "*"),
 
 	wire.Struct(new(talk.Session), "*"),
 	wire.Struct(new(talk.Message), "*"),
 	wire.Struct(new(talk.Publish), "*"),
+	wire.Struct(new(talk.Thread), "*"),
+	wire.Struct(new(talk.Search), "*"),
+	wire.Struct(new(talk.Pin), "*"),
+	wire.Struct(new(talk.Favorite), "*"),
+	wire.Struct(new(talk.Schedule), "*"),
+	wire.Struct(new(talk.Expire), "*"),
+	wire.Struct(new(talk.Sync), "*"),
+	wire.Struct(new(talk.Card), "*"),
+	wire.Struct(new(talk.Export), "*"),
 
 	wire.Struct(new(article.Article), "*"),
 	wire.Struct(new(article.Annex), "*"),
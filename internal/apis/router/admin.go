@@ -8,6 +8,9 @@ import (
 	"github.com/google/uuid"
 	admin2 "github.com/gzydong/go-chat/api/pb/admin/v1"
 	"github.com/gzydong/go-chat/internal/apis/handler/admin"
+	"github.com/gzydong/go-chat/internal/apis/handler/admin/broadcast"
+	"github.com/gzydong/go-chat/internal/apis/handler/admin/group"
+	"github.com/gzydong/go-chat/internal/apis/handler/admin/moderation"
 	"github.com/gzydong/go-chat/internal/entity"
 	"github.com/gzydong/go-chat/internal/pkg/core/middleware"
 	"github.com/gzydong/go-chat/internal/pkg/jwtutil"
@@ -64,4 +67,162 @@ func registerAdminCustomApiRouter(resp *Interceptor, api gin.IRoutes, handler *a
 			"url": "https://www.cox.com/" + uuid.NewString(),
 		}, nil
 	}))
+
+	// 内容审核
+	api.POST("/backend/moderation/word/list", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req moderation.WordListRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.Moderation.WordList(c.Request.Context(), &req)
+	}))
+
+	api.POST("/backend/moderation/word/save", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req moderation.WordSaveRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.Moderation.WordSave(c.Request.Context(), &req)
+	}))
+
+	api.POST("/backend/moderation/word/delete", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req moderation.WordDeleteRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.Moderation.WordDelete(c.Request.Context(), &req)
+	}))
+
+	api.POST("/backend/moderation/review/list", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req moderation.ReviewListRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.Moderation.ReviewList(c.Request.Context(), &req)
+	}))
+
+	api.POST("/backend/moderation/review", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req moderation.ReviewRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.Moderation.Review(c.Request.Context(), &req)
+	}))
+
+	// 举报处理
+	api.POST("/backend/report/list", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req moderation.ReportListRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.Report.List(c.Request.Context(), &req)
+	}))
+
+	api.POST("/backend/report/detail", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req moderation.ReportDetailRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.Report.Detail(c.Request.Context(), &req)
+	}))
+
+	api.POST("/backend/report/handle", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req moderation.ReportHandleRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.Report.Handle(c.Request.Context(), &req)
+	}))
+
+	// 群发任务
+	api.POST("/backend/broadcast/create", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req broadcast.CreateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.Broadcast.Create(c.Request.Context(), &req)
+	}))
+
+	api.POST("/backend/broadcast/list", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req broadcast.ListRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.Broadcast.List(c.Request.Context(), &req)
+	}))
+
+	api.POST("/backend/broadcast/detail", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req broadcast.DetailRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.Broadcast.Detail(c.Request.Context(), &req)
+	}))
+
+	api.POST("/backend/broadcast/recipients", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req broadcast.RecipientsRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.Broadcast.Recipients(c.Request.Context(), &req)
+	}))
+
+	api.POST("/backend/broadcast/cancel", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req broadcast.CancelRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.Broadcast.Cancel(c.Request.Context(), &req)
+	}))
+
+	// 聊天记录导出
+	api.POST("/backend/talk/export/create", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req moderation.ExportCreateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.Export.Create(c.Request.Context(), &req)
+	}))
+
+	api.POST("/backend/talk/export/list", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req moderation.ExportListRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.Export.List(c.Request.Context(), &req)
+	}))
+
+	api.POST("/backend/talk/export/download", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req moderation.ExportDownloadRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.Export.Download(c.Request.Context(), &req)
+	}))
+
+	// 聊天记录导入
+	api.POST("/backend/talk/import", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req moderation.ImportRequest
+		if err := c.ShouldBind(&req); err != nil {
+			return nil, err
+		}
+		return handler.Import.Import(c, &req)
+	}))
+
+	// 群容量管理
+	api.POST("/backend/group/detail", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req group.DetailRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.Group.Detail(c.Request.Context(), &req)
+	}))
+
+	api.POST("/backend/group/max-num/update", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req group.UpdateMaxNumRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.Group.UpdateMaxNum(c.Request.Context(), &req)
+	}))
 }
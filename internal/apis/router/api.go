@@ -9,6 +9,9 @@ import (
 	_ "github.com/gzydong/go-chat/docs" // Import generated docs
 	"github.com/gzydong/go-chat/internal/apis/handler/web"
 	v1 "github.com/gzydong/go-chat/internal/apis/handler/web/v1"
+	"github.com/gzydong/go-chat/internal/apis/handler/web/v1/contact"
+	"github.com/gzydong/go-chat/internal/apis/handler/web/v1/group"
+	"github.com/gzydong/go-chat/internal/apis/handler/web/v1/talk"
 	"github.com/gzydong/go-chat/internal/entity"
 	"github.com/gzydong/go-chat/internal/pkg/core/middleware"
 	"github.com/gzydong/go-chat/internal/pkg/jwtutil"
@@ -81,6 +84,7 @@ func RegisterWebRoute(secret string, router *gin.Engine, handler *web.Handler, s
 	web2.RegisterInviteHandler(api, resp, handler.V1.Invite)
 
 	registerCustomApiRouter(resp, api, handler)
+	registerWebhookRouter(resp, router, handler)
 }
 
 func registerCustomApiRouter(resp *Interceptor, api gin.IRoutes, handler *web.Handler) {
@@ -121,6 +125,220 @@ func registerCustomApiRouter(resp *Interceptor, api gin.IRoutes, handler *web.Ha
 		return handler.V1.Message.Send(c)
 	}))
 
+	api.POST("/api/v1/message/thread/records", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req talk.ThreadRecordsRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.V1.TalkThread.Records(c.Request.Context(), &req)
+	}))
+
+	api.POST("/api/v1/message/thread/summary", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req talk.ThreadSummaryRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.V1.TalkThread.Summary(c.Request.Context(), &req)
+	}))
+
+	api.POST("/api/v1/message/search", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req talk.SearchRecordsRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.V1.TalkSearch.Records(c.Request.Context(), &req)
+	}))
+
+	api.POST("/api/v1/message/pin/create", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req talk.PinRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.V1.TalkPin.Create(c.Request.Context(), &req)
+	}))
+
+	api.POST("/api/v1/message/pin/delete", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req talk.PinRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.V1.TalkPin.Delete(c.Request.Context(), &req)
+	}))
+
+	api.POST("/api/v1/message/pin/list", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req talk.PinListRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.V1.TalkPin.List(c.Request.Context(), &req)
+	}))
+
+	api.POST("/api/v1/message/favorite/create", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req talk.FavoriteCreateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.V1.TalkFavorite.Create(c.Request.Context(), &req)
+	}))
+
+	api.POST("/api/v1/message/favorite/delete", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req talk.FavoriteDeleteRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.V1.TalkFavorite.Delete(c.Request.Context(), &req)
+	}))
+
+	api.POST("/api/v1/message/favorite/tags", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req talk.FavoriteTagsRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.V1.TalkFavorite.Tags(c.Request.Context(), &req)
+	}))
+
+	api.POST("/api/v1/message/favorite/tag/list", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		return handler.V1.TalkFavorite.TagList(c.Request.Context(), &talk.FavoriteTagListRequest{})
+	}))
+
+	api.POST("/api/v1/message/favorite/list", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req talk.FavoriteListRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.V1.TalkFavorite.List(c.Request.Context(), &req)
+	}))
+
+	api.POST("/api/v1/message/favorite/forward", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req talk.FavoriteForwardRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.V1.TalkFavorite.Forward(c.Request.Context(), &req)
+	}))
+
+	api.GET("/api/v1/message/favorite/file-download", func(c *gin.Context) {
+		if err := handler.V1.TalkFavorite.Download(c); err != nil {
+			resp.Error(c, err)
+		}
+	})
+
+	api.POST("/api/v1/contact/block/create", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req contact.BlockCreateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.V1.ContactBlock.Create(c.Request.Context(), &req)
+	}))
+
+	api.POST("/api/v1/contact/block/delete", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req contact.BlockDeleteRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.V1.ContactBlock.Delete(c.Request.Context(), &req)
+	}))
+
+	api.POST("/api/v1/contact/block/list", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		return handler.V1.ContactBlock.List(c.Request.Context(), &contact.BlockListRequest{})
+	}))
+
+	api.POST("/api/v1/message/schedule/list", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req talk.ScheduleListRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.V1.TalkSchedule.List(c.Request.Context(), &req)
+	}))
+
+	api.POST("/api/v1/message/schedule/cancel", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req talk.ScheduleCancelRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.V1.TalkSchedule.Cancel(c.Request.Context(), &req)
+	}))
+
+	api.POST("/api/v1/message/schedule/reschedule", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req talk.ScheduleRescheduleRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.V1.TalkSchedule.Reschedule(c.Request.Context(), &req)
+	}))
+
+	api.POST("/api/v1/message/expire/setting", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req talk.ExpireSettingRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.V1.TalkExpire.Setting(c.Request.Context(), &req)
+	}))
+
+	api.POST("/api/v1/message/expire/setting/update", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req talk.ExpireUpdateSettingRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.V1.TalkExpire.UpdateSetting(c.Request.Context(), &req)
+	}))
+
+	api.POST("/api/v1/message/expire/read", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req talk.ExpireReadRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.V1.TalkExpire.Read(c.Request.Context(), &req)
+	}))
+
+	api.POST("/api/v1/message/sync", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req talk.SyncRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.V1.TalkSync.Sync(c.Request.Context(), &req)
+	}))
+
+	api.POST("/api/v1/report/create", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req v1.ReportCreateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.V1.Report.Create(c.Request.Context(), &req)
+	}))
+
+	api.POST("/api/v1/talk/export/create", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req talk.ExportCreateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.V1.TalkExport.Create(c.Request.Context(), &req)
+	}))
+
+	api.POST("/api/v1/talk/export/list", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req talk.ExportListRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.V1.TalkExport.List(c.Request.Context(), &req)
+	}))
+
+	api.POST("/api/v1/talk/export/download", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req talk.ExportDownloadRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.V1.TalkExport.Download(c.Request.Context(), &req)
+	}))
+
+	api.POST("/api/v1/message/card/action", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req talk.CardActionRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.V1.TalkCard.Action(c.Request.Context(), &req)
+	}))
+
 	api.GET("/api/v1/trtc/user-sig", HandlerFunc(resp, func(c *gin.Context) (any, error) {
 		return handler.V1.Trtc.GetSignature(c)
 	}))
@@ -219,6 +437,206 @@ func registerCustomApiRouter(resp *Interceptor, api gin.IRoutes, handler *web.Ha
 		return handler.V1.Wallet.GetRedEnvelopeDetail(c.Request.Context(), &req)
 	}))
 
+	api.POST("/api/v1/group/mute/member", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req group.MuteMemberRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.V1.GroupMute.Member(c.Request.Context(), &req)
+	}))
+
+	api.POST("/api/v1/group/mute/all", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req group.MuteAllRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.V1.GroupMute.All(c.Request.Context(), &req)
+	}))
+
+	api.POST("/api/v1/group/member/page", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req group.MemberPageRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.V1.GroupMember.Page(c.Request.Context(), &req)
+	}))
+
+	api.POST("/api/v1/group/permission/detail", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req group.PermissionDetailRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.V1.GroupPerm.Detail(c.Request.Context(), &req)
+	}))
+
+	api.POST("/api/v1/group/permission/update", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req group.PermissionUpdateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.V1.GroupPerm.Update(c.Request.Context(), &req)
+	}))
+
+	api.POST("/api/v1/group/permission/admin-update", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req group.PermissionAdminUpdateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.V1.GroupPerm.AdminUpdate(c.Request.Context(), &req)
+	}))
+
+	api.POST("/api/v1/group/invite-link/create", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req group.JoinLinkCreateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.V1.GroupJoin.LinkCreate(c.Request.Context(), &req)
+	}))
+
+	api.POST("/api/v1/group/invite-link/list", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req group.JoinLinkListRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.V1.GroupJoin.LinkList(c.Request.Context(), &req)
+	}))
+
+	api.POST("/api/v1/group/invite-link/revoke", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req group.JoinLinkRevokeRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.V1.GroupJoin.LinkRevoke(c.Request.Context(), &req)
+	}))
+
+	api.POST("/api/v1/group/invite-link/qrcode", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req group.JoinQrcodeRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.V1.GroupJoin.Qrcode(c.Request.Context(), &req)
+	}))
+
+	api.POST("/api/v1/group/invite-link/info", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req group.JoinInfoRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.V1.GroupJoin.Info(c.Request.Context(), &req)
+	}))
+
+	api.POST("/api/v1/group/join", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req group.JoinRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.V1.GroupJoin.Join(c.Request.Context(), &req)
+	}))
+
+	api.POST("/api/v1/group/join-mode/update", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req group.JoinModeUpdateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.V1.GroupJoin.ModeUpdate(c.Request.Context(), &req)
+	}))
+
+	api.POST("/api/v1/group/anti-spam/detail", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req group.AntiSpamDetailRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.V1.GroupSpam.Detail(c.Request.Context(), &req)
+	}))
+
+	api.POST("/api/v1/group/anti-spam/update", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req group.AntiSpamUpdateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.V1.GroupSpam.Update(c.Request.Context(), &req)
+	}))
+
+	api.POST("/api/v1/group-notice/publish", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req group.NoticePublishRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.V1.GroupNotice.Publish(c.Request.Context(), &req)
+	}))
+
+	api.POST("/api/v1/group-notice/list", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req group.NoticeListRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.V1.GroupNotice.List(c.Request.Context(), &req)
+	}))
+
+	api.POST("/api/v1/group-notice/delete", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req group.NoticeDeleteRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.V1.GroupNotice.Delete(c.Request.Context(), &req)
+	}))
+
+	api.POST("/api/v1/group-notice/top", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req group.NoticeTopRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.V1.GroupNotice.Top(c.Request.Context(), &req)
+	}))
+
+	api.POST("/api/v1/group-notice/confirm", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req group.NoticeConfirmRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.V1.GroupNotice.Confirm(c.Request.Context(), &req)
+	}))
+
+	api.POST("/api/v1/group-notice/unconfirmed", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req group.NoticeUnconfirmedRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.V1.GroupNotice.Unconfirmed(c.Request.Context(), &req)
+	}))
+
+	api.POST("/api/v1/group-notice/remind", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req group.NoticeRemindRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.V1.GroupNotice.Remind(c.Request.Context(), &req)
+	}))
+
+	api.POST("/api/v1/group-vote/publish", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req group.VotePublishRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.V1.GroupVote.Publish(c.Request.Context(), &req)
+	}))
+
+	api.POST("/api/v1/group-vote/result", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req group.VoteResultRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.V1.GroupVote.Result(c.Request.Context(), &req)
+	}))
+
+	api.POST("/api/v1/group-vote/close", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req group.VoteCloseRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		return handler.V1.GroupVote.Close(c.Request.Context(), &req)
+	}))
+
 	// GroupRobot routes
 	api.POST("/api/v1/group/robot/create", HandlerFunc(resp, func(c *gin.Context) (any, error) {
 		var req v1.GroupRobotCreateRequest
@@ -259,9 +677,13 @@ func registerCustomApiRouter(resp *Interceptor, api gin.IRoutes, handler *web.Ha
 		}
 		return handler.V1.GroupRobot.GetRobotMessages(c.Request.Context(), &req)
 	}))
+}
 
-	// Webhook route (note: this doesn't require auth, so might need special handling)
-	api.POST("/api/v1/webhook/robot/:webhook_url", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+// registerWebhookRouter Webhook 由机器人调用，使用签名验证，不经过登录授权
+func registerWebhookRouter(resp *Interceptor, router *gin.Engine, handler *web.Handler) {
+	webhook := router.Group("/api/v1/webhook")
+
+	webhook.POST("/robot/:webhook_url", HandlerFunc(resp, func(c *gin.Context) (any, error) {
 		webhookUrl := c.Param("webhook_url")
 		var req v1.WebhookSendRequest
 		if err := c.ShouldBindJSON(&req); err != nil {
@@ -272,4 +694,14 @@ func registerCustomApiRouter(resp *Interceptor, api gin.IRoutes, handler *web.Ha
 		req.Signature = c.GetHeader("signature")
 		return handler.V1.GroupRobot.SendWebhookMessage(c.Request.Context(), webhookUrl, &req)
 	}))
+
+	webhook.POST("/robot/:webhook_url/card/update", HandlerFunc(resp, func(c *gin.Context) (any, error) {
+		var req v1.WebhookCardUpdateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, err
+		}
+		req.Timestamp = c.GetHeader("timestamp")
+		req.Signature = c.GetHeader("signature")
+		return handler.V1.GroupRobot.UpdateCard(c.Request.Context(), c.Param("webhook_url"), &req)
+	}))
 }
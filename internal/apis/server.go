@@ -68,6 +68,14 @@ func run(c chan os.Signal, eg *errgroup.Group, ctx context.Context, app *Provide
 		Handler: app.Engine,
 	}
 
+	workerCtx, workerCancel := context.WithCancel(ctx)
+
+	// 后台同步消息检索索引
+	eg.Go(func() error {
+		app.TalkSearchService.Run(workerCtx)
+		return nil
+	})
+
 	// 启动 http 服务
 	eg.Go(func() error {
 		err := serv.ListenAndServe()
@@ -82,6 +90,8 @@ func run(c chan os.Signal, eg *errgroup.Group, ctx context.Context, app *Provide
 		defer func() {
 			log.Println("Shutting down serv...")
 
+			workerCancel()
+
 			// 等待中断信号以优雅地关闭服务器（设置 5 秒的超时时间）
 			timeCtx, timeCancel := context.WithTimeout(context.Background(), 3*time.Second)
 			defer timeCancel()
@@ -9,11 +9,13 @@ import (
 	"github.com/gzydong/go-chat/internal/apis/handler/open"
 	"github.com/gzydong/go-chat/internal/apis/handler/web"
 	"github.com/gzydong/go-chat/internal/apis/router"
+	"github.com/gzydong/go-chat/internal/service"
 )
 
 type Provider struct {
-	Config *config.Config
-	Engine *gin.Engine
+	Config            *config.Config
+	Engine            *gin.Engine
+	TalkSearchService service.ITalkSearchService
 }
 
 var ProviderSet = wire.NewSet(
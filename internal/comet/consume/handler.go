@@ -16,14 +16,16 @@ import (
 var handlers map[string]func(ctx context.Context, data []byte)
 
 type Handler struct {
-	Config             *config.Config
-	OrganizeRepo       *repo.Organize
-	UserRepo           *repo.Users
-	Source             *repo.Source
-	TalkRecordsService service.ITalkRecordService
-	ContactService     service.IContactService
-	serv               longnet.IServer `wire:"-"`
-	GroupMemberRepo    *repo.GroupMember
+	Config              *config.Config
+	OrganizeRepo        *repo.Organize
+	UserRepo            *repo.Users
+	Source              *repo.Source
+	TalkRecordsService  service.ITalkRecordService
+	ContactService      service.IContactService
+	serv                longnet.IServer `wire:"-"`
+	GroupMemberRepo     *repo.GroupMember
+	TalkGroupThreadRepo *repo.TalkGroupThread
+	UserBlockRepo       *repo.UserBlock
 }
 
 func (h *Handler) init() {
@@ -32,10 +34,18 @@ func (h *Handler) init() {
 	handlers[entity.SubEventImMessage] = h.onConsumeTalk
 	handlers[entity.SubEventImMessageKeyboard] = h.onConsumeTalkKeyboard
 	handlers[entity.SubEventImMessageRevoke] = h.onConsumeTalkRevoke
+	handlers[entity.SubEventImMessageThread] = h.onConsumeTalkThread
+	handlers[entity.SubEventImMessagePin] = h.onConsumeTalkPin
+	handlers[entity.SubEventImMessageExpired] = h.onConsumeTalkExpired
+	handlers[entity.SubEventImMessageUpdate] = h.onConsumeTalkUpdate
 	handlers[entity.SubEventContactStatus] = h.onConsumeContactStatus
 	handlers[entity.SubEventContactApply] = h.onConsumeContactApply
 	handlers[entity.SubEventGroupJoin] = h.onConsumeGroupJoin
 	handlers[entity.SubEventGroupApply] = h.onConsumeGroupApply
+	handlers[entity.SubEventGroupMute] = h.onConsumeGroupMute
+	handlers[entity.SubEventGroupNotice] = h.onConsumeGroupNotice
+	handlers[entity.SubEventGroupVoteUpdate] = h.onConsumeGroupVoteUpdate
+	handlers[entity.SubEventTalkExport] = h.onConsumeTalkExport
 
 	// Call Signaling
 	handlers[entity.SubEventImCallInvite] = func(ctx context.Context, data []byte) {
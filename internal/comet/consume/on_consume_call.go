@@ -20,6 +20,12 @@ func (h *Handler) onConsumeTalkCall(ctx context.Context, body []byte, event stri
 
 	slog.Info("[CallEvent] Received call event", "event", event, "from_id", in.FromId, "to_id", in.ToId, "room_id", in.RoomId, "call_type", in.CallType)
 
+	// 拉黑后不再投递通话邀请及接听事件，拒绝、挂断事件照常投递以便结束进行中的通话
+	if (event == entity.SubEventImCallInvite || event == entity.SubEventImCallAccept) && h.UserBlockRepo.IsEitherBlocked(ctx, in.FromId, in.ToId) {
+		slog.Info("[CallEvent] Call event dropped by block list", "event", event, "from_id", in.FromId, "to_id", in.ToId)
+		return
+	}
+
 	pushEvent := ""
 	switch event {
 	case entity.SubEventImCallInvite:
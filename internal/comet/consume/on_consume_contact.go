@@ -10,6 +10,7 @@ import (
 	"github.com/gzydong/go-chat/internal/pkg/logger"
 	"github.com/gzydong/go-chat/internal/pkg/sliceutil"
 	"github.com/gzydong/go-chat/internal/repository/model"
+	"github.com/samber/lo"
 )
 
 // 用户上线或下线消息
@@ -26,6 +27,14 @@ func (h *Handler) onConsumeContactStatus(ctx context.Context, body []byte) {
 		contactIds = append(contactIds, ids...)
 	}
 
+	// 在线状态不推送给存在拉黑关系的用户
+	blockIds, _ := h.UserBlockRepo.FindRelatedIds(ctx, in.UserId)
+	if len(blockIds) > 0 {
+		contactIds = lo.Filter(contactIds, func(uid int64, _ int) bool {
+			return !lo.Contains(blockIds, int(uid))
+		})
+	}
+
 	data := Message(entity.PushEventContactStatus, in)
 	for _, uid := range sliceutil.Unique(contactIds) {
 		for _, session := range h.serv.SessionManager().GetSessions(uid) {
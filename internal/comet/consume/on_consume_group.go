@@ -10,6 +10,7 @@ import (
 	"github.com/gzydong/go-chat/internal/entity"
 	"github.com/gzydong/go-chat/internal/pkg/logger"
 	"github.com/gzydong/go-chat/internal/repository/model"
+	"github.com/gzydong/go-chat/internal/service"
 )
 
 // 加入群房间
@@ -85,3 +86,125 @@ func (h *Handler) onConsumeGroupApply(ctx context.Context, body []byte) {
 		}
 	}
 }
+
+// 群禁言状态变更通知
+func (h *Handler) onConsumeGroupMute(ctx context.Context, body []byte) {
+	var in entity.SubEventGroupMutePayload
+	if err := json.Unmarshal(body, &in); err != nil {
+		logger.Errorf("[ChatSubscribe] onConsumeGroupMute Unmarshal err: %s", err.Error())
+		return
+	}
+
+	payload := entity.ImGroupMutePayload{
+		GroupId:    in.GroupId,
+		UserId:     in.UserId,
+		OperatorId: in.OperatorId,
+		Type:       in.Type,
+		Reason:     in.Reason,
+	}
+
+	// 剩余时长按推送时计算，永久禁言为 -1
+	if in.Type == service.GroupMuteTypeMute {
+		payload.Remaining = -1
+		if in.ExpireAt > 0 {
+			expireAt := time.Unix(in.ExpireAt, 0)
+			payload.ExpireAt = expireAt.Format(time.DateTime)
+			payload.Remaining = max(int64(time.Until(expireAt).Seconds()), 0)
+		}
+	}
+
+	data := Message(entity.PushEventGroupMute, payload)
+	for _, uid := range h.GroupMemberRepo.GetMemberIds(ctx, in.GroupId) {
+		for _, session := range h.serv.SessionManager().GetSessions(int64(uid)) {
+			if err := session.Write(data); err != nil {
+				slog.Error("session write message error", "error", err)
+			}
+		}
+	}
+}
+
+// 群公告发布及确认提醒通知
+func (h *Handler) onConsumeGroupNotice(ctx context.Context, body []byte) {
+	var in entity.SubEventGroupNoticePayload
+	if err := json.Unmarshal(body, &in); err != nil {
+		logger.Errorf("[ChatSubscribe] onConsumeGroupNotice Unmarshal err: %s", err.Error())
+		return
+	}
+
+	var notice model.GroupNotice
+	if err := h.Source.Db().First(&notice, "id = ? and is_delete = ?", in.NoticeId, model.GroupNoticeIsDeleteNo).Error; err != nil {
+		return
+	}
+
+	uids := in.UserIds
+	if len(uids) == 0 {
+		uids = h.GroupMemberRepo.GetMemberIds(ctx, in.GroupId)
+	}
+
+	data := Message(entity.PushEventGroupNotice, entity.ImGroupNoticePayload{
+		GroupId:   notice.GroupId,
+		NoticeId:  notice.Id,
+		Type:      in.Type,
+		Title:     notice.Title,
+		Content:   notice.Content,
+		IsConfirm: notice.IsConfirm,
+		IsTop:     notice.IsTop,
+		CreatorId: notice.CreatorId,
+		UpdatedAt: notice.UpdatedAt.Format(time.DateTime),
+	})
+
+	for _, uid := range uids {
+		for _, session := range h.serv.SessionManager().GetSessions(int64(uid)) {
+			if err := session.Write(data); err != nil {
+				slog.Error("session write message error", "error", err)
+			}
+		}
+	}
+}
+
+// 群投票结果更新通知
+func (h *Handler) onConsumeGroupVoteUpdate(ctx context.Context, body []byte) {
+	var in entity.SubEventGroupVoteUpdatePayload
+	if err := json.Unmarshal(body, &in); err != nil {
+		logger.Errorf("[ChatSubscribe] onConsumeGroupVoteUpdate Unmarshal err: %s", err.Error())
+		return
+	}
+
+	payload := entity.ImGroupVoteUpdatePayload{
+		GroupId:     in.GroupId,
+		VoteId:      in.VoteId,
+		Status:      in.Status,
+		AnswerNum:   in.AnswerNum,
+		AnsweredNum: in.AnsweredNum,
+		Options:     in.Options,
+		IsVisible:   true,
+	}
+
+	visible := Message(entity.PushEventGroupVoteUpdate, payload)
+
+	// 结果仅投票后可见时，未投票成员只推送投票进度
+	hidden := visible
+	if in.ResultVisible == model.VoteResultVisibleAfterVote && in.Status != model.VoteStatusFinish {
+		payload.Options = nil
+		payload.IsVisible = false
+		hidden = Message(entity.PushEventGroupVoteUpdate, payload)
+	}
+
+	answered := make(map[int]struct{}, len(in.AnsweredUids))
+	for _, uid := range in.AnsweredUids {
+		answered[uid] = struct{}{}
+	}
+
+	for _, uid := range h.GroupMemberRepo.GetMemberIds(ctx, in.GroupId) {
+		data := hidden
+		if _, ok := answered[uid]; ok {
+			data = visible
+		}
+
+		for _, session := range h.serv.SessionManager().GetSessions(int64(uid)) {
+			if err := session.Write(data); err != nil {
+				slog.Error("session write message error", "error", err)
+			}
+		}
+	}
+}
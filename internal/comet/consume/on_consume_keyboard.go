@@ -18,6 +18,10 @@ func (h *Handler) onConsumeTalkKeyboard(ctx context.Context, body []byte) {
 		return
 	}
 
+	if h.UserBlockRepo.IsEitherBlocked(ctx, in.FromId, in.ToFromId) {
+		return
+	}
+
 	data := Message(entity.PushEventImMessageKeyboard, entity.ImMessageKeyboardPayload{
 		FromId:   in.FromId,
 		ToFromId: in.ToFromId,
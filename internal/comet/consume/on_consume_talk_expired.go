@@ -0,0 +1,39 @@
+package consume
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"github.com/gzydong/go-chat/internal/entity"
+	"github.com/gzydong/go-chat/internal/pkg/logger"
+)
+
+// 聊天消息过期销毁
+func (h *Handler) onConsumeTalkExpired(ctx context.Context, body []byte) {
+	var in entity.SubEventImMessageExpiredPayload
+	if err := json.Unmarshal(body, &in); err != nil {
+		logger.Errorf("[ChatSubscribe] onConsumeTalkExpired Unmarshal err: %s", err.Error())
+		return
+	}
+
+	data := Message(entity.PushEventImMessageExpired, entity.ImMessageExpiredPayload{
+		TalkMode: in.TalkMode,
+		ToFromId: in.ToFromId,
+		MsgIds:   in.MsgIds,
+	})
+
+	// 私聊消息仅推送给消息所属用户
+	uids := []int{in.UserId}
+	if in.TalkMode == entity.ChatGroupMode {
+		uids = h.GroupMemberRepo.GetMemberIds(ctx, in.ToFromId)
+	}
+
+	for _, uid := range uids {
+		for _, session := range h.serv.SessionManager().GetSessions(int64(uid)) {
+			if err := session.Write(data); err != nil {
+				slog.Error("session write message error", "error", err)
+			}
+		}
+	}
+}
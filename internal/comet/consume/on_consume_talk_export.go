@@ -0,0 +1,26 @@
+package consume
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"github.com/gzydong/go-chat/internal/entity"
+	"github.com/gzydong/go-chat/internal/pkg/logger"
+)
+
+// 聊天记录导出完成通知
+func (h *Handler) onConsumeTalkExport(_ context.Context, body []byte) {
+	var in entity.SubEventTalkExportPayload
+	if err := json.Unmarshal(body, &in); err != nil {
+		logger.Errorf("[ChatSubscribe] onConsumeTalkExport Unmarshal err: %s", err.Error())
+		return
+	}
+
+	data := Message(entity.PushEventTalkExport, in)
+	for _, session := range h.serv.SessionManager().GetSessions(int64(in.UserId)) {
+		if err := session.Write(data); err != nil {
+			slog.Error("session write message error", "error", err)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package consume
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"github.com/gzydong/go-chat/internal/entity"
+	"github.com/gzydong/go-chat/internal/pkg/logger"
+)
+
+// 聊天消息置顶
+func (h *Handler) onConsumeTalkPin(ctx context.Context, body []byte) {
+	var in entity.SubEventImMessagePinPayload
+	if err := json.Unmarshal(body, &in); err != nil {
+		logger.Errorf("[ChatSubscribe] onConsumeTalkPin Unmarshal err: %s", err.Error())
+		return
+	}
+
+	if in.TalkMode == entity.ChatPrivateMode {
+		// 私聊双方的消息ID不同，按原消息ID推送各自的消息ID
+		records, err := h.TalkRecordsService.FindAllPrivateRecordByOriMsgId(ctx, in.MsgId)
+		if err != nil {
+			logger.Errorf("onConsumeTalkPin FindAllPrivateRecordByOriMsgId err: %s", err.Error())
+			return
+		}
+
+		for _, record := range records {
+			data := Message(entity.PushEventImMessagePin, entity.ImMessagePinPayload{
+				TalkMode: entity.ChatPrivateMode,
+				FromId:   in.UserId,
+				ToFromId: record.ToFromId,
+				MsgId:    record.MsgId,
+				Type:     in.Type,
+			})
+
+			for _, session := range h.serv.SessionManager().GetSessions(int64(record.UserId)) {
+				if err := session.Write(data); err != nil {
+					slog.Error("session write message error", "error", err)
+				}
+			}
+		}
+	} else if in.TalkMode == entity.ChatGroupMode {
+		data := Message(entity.PushEventImMessagePin, entity.ImMessagePinPayload{
+			TalkMode: entity.ChatGroupMode,
+			FromId:   in.UserId,
+			ToFromId: in.ToFromId,
+			MsgId:    in.MsgId,
+			Type:     in.Type,
+		})
+
+		for _, uid := range h.GroupMemberRepo.GetMemberIds(ctx, in.ToFromId) {
+			for _, session := range h.serv.SessionManager().GetSessions(int64(uid)) {
+				if err := session.Write(data); err != nil {
+					slog.Error("session write message error", "error", err)
+				}
+			}
+		}
+	}
+}
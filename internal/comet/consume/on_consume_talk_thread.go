@@ -0,0 +1,86 @@
+package consume
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/gzydong/go-chat/internal/entity"
+	"github.com/gzydong/go-chat/internal/pkg/logger"
+	"github.com/gzydong/go-chat/internal/repository/model"
+)
+
+// 群消息话题回复
+// 话题参与者收到完整的回复消息，其他群成员只收到根消息的回复汇总，避免刷屏
+func (h *Handler) onConsumeTalkThread(ctx context.Context, body []byte) {
+	var in entity.SubEventImMessageThreadPayload
+	if err := json.Unmarshal(body, &in); err != nil {
+		logger.Errorf("[ChatSubscribe] onConsumeTalkThread Unmarshal err: %s", err.Error())
+		return
+	}
+
+	message := model.TalkGroupMessage{}
+	if err := json.Unmarshal([]byte(in.Message), &message); err != nil {
+		return
+	}
+
+	threads, err := h.TalkGroupThreadRepo.FindByRootMsgIds(ctx, in.GroupId, []string{in.RootMsgId})
+	if err != nil || len(threads) == 0 {
+		return
+	}
+
+	thread := threads[0]
+	summary := entity.ImMessageThreadPayload{
+		GroupId:         in.GroupId,
+		RootMsgId:       in.RootMsgId,
+		ReplyCount:      thread.ReplyCount,
+		LastReplyUserId: thread.LastReplyUserId,
+		LastReplyAt:     thread.LastReplyAt.Format(time.DateTime),
+	}
+
+	reply := &entity.ImMessagePayloadBody{
+		MsgId:     message.MsgId,
+		Sequence:  int(message.Sequence),
+		MsgType:   message.MsgType,
+		FromId:    message.FromId,
+		IsRevoked: message.IsRevoked,
+		SendTime:  message.SendTime.Format(time.DateTime),
+		Extra:     message.Extra,
+		Quote:     message.Quote,
+	}
+
+	if reply.FromId > 0 {
+		user, err := h.UserRepo.FindByIdWithCache(ctx, message.FromId)
+		if err != nil {
+			return
+		}
+
+		reply.Nickname = user.Nickname
+		reply.Avatar = user.Avatar
+	}
+
+	participants := make(map[int]struct{})
+	for _, uid := range h.TalkGroupThreadRepo.GetMemberIds(ctx, in.RootMsgId) {
+		participants[uid] = struct{}{}
+	}
+
+	full := summary
+	full.Message = reply
+
+	summaryMsg := Message(entity.PushEventImMessageThread, summary)
+	fullMsg := Message(entity.PushEventImMessageThread, full)
+
+	for _, uid := range h.GroupMemberRepo.GetMemberIds(ctx, in.GroupId) {
+		data := summaryMsg
+		if _, ok := participants[uid]; ok {
+			data = fullMsg
+		}
+
+		for _, session := range h.serv.SessionManager().GetSessions(int64(uid)) {
+			if err := session.Write(data); err != nil {
+				slog.Error("session write message error", "error", err)
+			}
+		}
+	}
+}
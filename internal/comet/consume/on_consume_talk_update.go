@@ -0,0 +1,58 @@
+package consume
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"github.com/gzydong/go-chat/internal/entity"
+	"github.com/gzydong/go-chat/internal/pkg/logger"
+)
+
+// 聊天消息内容更新
+func (h *Handler) onConsumeTalkUpdate(ctx context.Context, body []byte) {
+	var in entity.SubEventImMessageUpdatePayload
+	if err := json.Unmarshal(body, &in); err != nil {
+		logger.Errorf("[ChatSubscribe] onConsumeTalkUpdate Unmarshal err: %s", err.Error())
+		return
+	}
+
+	if in.TalkMode == entity.ChatPrivateMode {
+		// 私聊双方的消息ID不同，按原消息ID推送各自的消息ID
+		records, err := h.TalkRecordsService.FindAllPrivateRecordByOriMsgId(ctx, in.MsgId)
+		if err != nil {
+			logger.Errorf("onConsumeTalkUpdate FindAllPrivateRecordByOriMsgId err: %s", err.Error())
+			return
+		}
+
+		for _, record := range records {
+			data := Message(entity.PushEventImMessageUpdate, entity.ImMessageUpdatePayload{
+				TalkMode: entity.ChatPrivateMode,
+				ToFromId: record.ToFromId,
+				MsgId:    record.MsgId,
+				Extra:    json.RawMessage(in.Extra),
+			})
+
+			for _, session := range h.serv.SessionManager().GetSessions(int64(record.UserId)) {
+				if err := session.Write(data); err != nil {
+					slog.Error("session write message error", "error", err)
+				}
+			}
+		}
+	} else if in.TalkMode == entity.ChatGroupMode {
+		data := Message(entity.PushEventImMessageUpdate, entity.ImMessageUpdatePayload{
+			TalkMode: entity.ChatGroupMode,
+			ToFromId: in.ToFromId,
+			MsgId:    in.MsgId,
+			Extra:    json.RawMessage(in.Extra),
+		})
+
+		for _, uid := range h.GroupMemberRepo.GetMemberIds(ctx, in.ToFromId) {
+			for _, session := range h.serv.SessionManager().GetSessions(int64(uid)) {
+				if err := session.Write(data); err != nil {
+					slog.Error("session write message error", "error", err)
+				}
+			}
+		}
+	}
+}
@@ -27,4 +27,5 @@ var (
 	ErrNoteClassDefaultNotDelete = errorx.New(120005, "默认分类不允许删除")
 	ErrNoteClassUsedNotDelete    = errorx.New(120006, "分类已被使用不能删除")
 	ErrSmsChannelInvalid         = errorx.New(130001, "短信渠道无效")
+	ErrContentRejected           = errorx.New(140001, "内容包含违规信息，请修改后重试")
 )
@@ -84,6 +84,76 @@ type ImMessageRevokePayload struct {
 	Remark   string `json:"remark"`
 }
 
+// ImMessageThreadPayload im.message.thread
+// 话题参与者会收到完整的回复消息(Message)，其他群成员仅收到根消息的回复汇总
+type ImMessageThreadPayload struct {
+	GroupId         int                   `json:"group_id"`
+	RootMsgId       string                `json:"root_msg_id"`
+	ReplyCount      int                   `json:"reply_count"`
+	LastReplyUserId int                   `json:"last_reply_user_id"`
+	LastReplyAt     string                `json:"last_reply_at"`
+	Message         *ImMessagePayloadBody `json:"message,omitempty"`
+}
+
+// ImGroupMutePayload im.group.mute
+type ImGroupMutePayload struct {
+	GroupId    int    `json:"group_id"`
+	UserId     int    `json:"user_id"`     // 禁言成员ID，为 0 时表示全员禁言
+	OperatorId int    `json:"operator_id"` // 操作人ID，为 0 时表示到期自动解除
+	Type       int    `json:"type"`        // 1:禁言 2:解除禁言
+	Reason     string `json:"reason"`
+	ExpireAt   string `json:"expire_at"` // 禁言截止时间，永久禁言或解除禁言时为空
+	Remaining  int64  `json:"remaining"` // 禁言剩余秒数，永久禁言为 -1，解除禁言为 0
+}
+
+// ImGroupNoticePayload im.group.notice
+type ImGroupNoticePayload struct {
+	GroupId   int    `json:"group_id"`
+	NoticeId  int    `json:"notice_id"`
+	Type      int    `json:"type"` // 1:发布公告 2:提醒确认
+	Title     string `json:"title"`
+	Content   string `json:"content"`
+	IsConfirm int    `json:"is_confirm"` // 是否需确认[1:是;2:否;]
+	IsTop     int    `json:"is_top"`     // 是否置顶[1:是;2:否;]
+	CreatorId int    `json:"creator_id"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+// ImGroupVoteUpdatePayload im.group.vote.update
+type ImGroupVoteUpdatePayload struct {
+	GroupId     int            `json:"group_id"`
+	VoteId      int            `json:"vote_id"`
+	Status      int            `json:"status"` // 投票状态[1:投票中;2:已完成;]
+	AnswerNum   int            `json:"answer_num"`
+	AnsweredNum int            `json:"answered_num"`
+	Options     map[string]int `json:"options"` // 各选项票数，结果不可见时为空
+	IsVisible   bool           `json:"is_visible"`
+}
+
+// ImMessagePinPayload im.message.pin
+type ImMessagePinPayload struct {
+	TalkMode int    `json:"talk_mode"`
+	FromId   int    `json:"from_id"` // 操作人
+	ToFromId int    `json:"to_from_id"`
+	MsgId    string `json:"msg_id"`
+	Type     int    `json:"type"` // 1:置顶 2:取消置顶
+}
+
+// ImMessageExpiredPayload im.message.expired
+type ImMessageExpiredPayload struct {
+	TalkMode int      `json:"talk_mode"`
+	ToFromId int      `json:"to_from_id"`
+	MsgIds   []string `json:"msg_ids"`
+}
+
+// ImMessageUpdatePayload im.message.update
+type ImMessageUpdatePayload struct {
+	TalkMode int    `json:"talk_mode"`
+	ToFromId int    `json:"to_from_id"`
+	MsgId    string `json:"msg_id"`
+	Extra    any    `json:"extra"` // 更新后的消息内容
+}
+
 // ImCallPayload 通话事件
 type ImCallPayload struct {
 	FromUserId     int    `json:"from_user_id"`     // Match frontend expectation
@@ -1,17 +1,25 @@
 package entity
 
 const (
-	SubEventImMessage         = "sub.im.message"          // 对话消息通知
-	SubEventImMessageKeyboard = "sub.im.message.keyboard" // 键盘输入事件通知
-	SubEventImMessageRevoke   = "sub.im.message.revoke"   // 聊天消息撤销通知
-	SubEventContactStatus     = "sub.im.contact.status"   // 用户在线状态通知
-	SubEventContactApply      = "sub.im.contact.apply"    // 好友申请消息通知
-	SubEventGroupJoin         = "sub.im.group.join"       // 邀请加入群聊通知
-	SubEventGroupApply        = "sub.im.group.apply"      // 入群申请通知
-	SubEventImCallInvite      = "sub.im.call.invite"      // 通话邀请通知
-	SubEventImCallAccept      = "sub.im.call.accept"      // 接受通话通知
-	SubEventImCallReject      = "sub.im.call.reject"      // 拒绝通话通知
-	SubEventImCallHangup      = "sub.im.call.hangup"      // 挂断通话通知
+	SubEventImMessage         = "sub.im.message"           // 对话消息通知
+	SubEventImMessageKeyboard = "sub.im.message.keyboard"  // 键盘输入事件通知
+	SubEventImMessageRevoke   = "sub.im.message.revoke"    // 聊天消息撤销通知
+	SubEventImMessageThread   = "sub.im.message.thread"    // 群消息话题回复通知
+	SubEventImMessagePin      = "sub.im.message.pin"       // 消息置顶通知
+	SubEventImMessageExpired  = "sub.im.message.expired"   // 消息过期销毁通知
+	SubEventImMessageUpdate   = "sub.im.message.update"    // 消息内容更新通知
+	SubEventContactStatus     = "sub.im.contact.status"    // 用户在线状态通知
+	SubEventContactApply      = "sub.im.contact.apply"     // 好友申请消息通知
+	SubEventGroupJoin         = "sub.im.group.join"        // 邀请加入群聊通知
+	SubEventGroupApply        = "sub.im.group.apply"       // 入群申请通知
+	SubEventGroupMute         = "sub.im.group.mute"        // 群禁言状态变更通知
+	SubEventGroupNotice       = "sub.im.group.notice"      // 群公告发布及确认提醒通知
+	SubEventGroupVoteUpdate   = "sub.im.group.vote.update" // 群投票结果更新通知
+	SubEventImCallInvite      = "sub.im.call.invite"       // 通话邀请通知
+	SubEventImCallAccept      = "sub.im.call.accept"       // 接受通话通知
+	SubEventImCallReject      = "sub.im.call.reject"       // 拒绝通话通知
+	SubEventImCallHangup      = "sub.im.call.hangup"       // 挂断通话通知
+	SubEventTalkExport        = "sub.im.talk.export"       // 聊天记录导出完成通知
 )
 
 type SubEventImCallPayload struct {
@@ -45,6 +53,33 @@ type SubEventGroupApplyPayload struct {
 	ApplyId int `json:"apply_id"`
 }
 
+type SubEventGroupMutePayload struct {
+	GroupId    int    `json:"group_id"`
+	UserId     int    `json:"user_id"`     // 禁言成员ID，为 0 时表示全员禁言
+	OperatorId int    `json:"operator_id"` // 操作人ID，为 0 时表示到期自动解除
+	Type       int    `json:"type"`        // 1:禁言 2:解除禁言
+	Reason     string `json:"reason"`
+	ExpireAt   int64  `json:"expire_at"` // 禁言截止时间戳(秒)，为 0 时表示永久禁言
+}
+
+type SubEventGroupNoticePayload struct {
+	GroupId  int   `json:"group_id"`
+	NoticeId int   `json:"notice_id"`
+	Type     int   `json:"type"`     // 1:发布公告 2:提醒确认
+	UserIds  []int `json:"user_ids"` // 接收用户ID，为空时推送给全部群成员
+}
+
+type SubEventGroupVoteUpdatePayload struct {
+	GroupId       int            `json:"group_id"`
+	VoteId        int            `json:"vote_id"`
+	Status        int            `json:"status"` // 投票状态[1:投票中;2:已完成;]
+	AnswerNum     int            `json:"answer_num"`
+	AnsweredNum   int            `json:"answered_num"`
+	Options       map[string]int `json:"options"`        // 各选项票数
+	ResultVisible int            `json:"result_visible"` // 结果可见范围[1:始终可见;2:投票后或结束后可见;]
+	AnsweredUids  []int          `json:"answered_uids"`  // 已投票用户ID，结果仅投票后可见时用于区分推送内容
+}
+
 type SubEventContactApplyPayload struct {
 	ApplyId int `json:"apply_id"`
 	Type    int `json:"type"`
@@ -65,3 +100,38 @@ type SubEventTalkRevokePayload struct {
 	MsgId    string `json:"msg_id"`    // 消息ID
 	Remark   string `json:"remark"`
 }
+
+type SubEventImMessageThreadPayload struct {
+	GroupId   int    `json:"group_id"`    // 群ID
+	RootMsgId string `json:"root_msg_id"` // 话题根消息ID
+	Message   string `json:"message"`     // 回复消息 json 字符串
+}
+
+type SubEventImMessagePinPayload struct {
+	TalkMode int    `json:"talk_mode"`  // 1单聊 2群聊
+	UserId   int    `json:"user_id"`    // 操作人
+	ToFromId int    `json:"to_from_id"` // 好友ID或群ID
+	MsgId    string `json:"msg_id"`     // 消息ID(私聊为原消息ID)
+	Type     int    `json:"type"`       // 1:置顶 2:取消置顶
+}
+
+type SubEventImMessageExpiredPayload struct {
+	TalkMode int      `json:"talk_mode"`  // 1单聊 2群聊
+	UserId   int      `json:"user_id"`    // 私聊消息所属用户ID，群聊为0
+	ToFromId int      `json:"to_from_id"` // 好友ID或群ID
+	MsgIds   []string `json:"msg_ids"`    // 已销毁的消息ID
+}
+
+type SubEventImMessageUpdatePayload struct {
+	TalkMode int    `json:"talk_mode"`  // 1单聊 2群聊
+	ToFromId int    `json:"to_from_id"` // 好友ID或群ID
+	MsgId    string `json:"msg_id"`     // 消息ID(私聊为原消息ID)
+	Extra    string `json:"extra"`      // 更新后的消息内容 json 字符串
+}
+
+type SubEventTalkExportPayload struct {
+	UserId   int    `json:"user_id"`
+	ExportId int    `json:"export_id"`
+	Status   int    `json:"status"` // 导出状态[3:已完成;4:导出失败;]
+	Url      string `json:"url"`    // 下载地址
+}
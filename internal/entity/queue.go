@@ -1,5 +1,26 @@
 package entity
 
 const (
-	LoginTopic = "im.user.login"
+	LoginTopic       = "im.user.login"
+	LinkPreviewTopic = "im.message.link-preview"
+	BroadcastTopic   = "im.message.broadcast" // NSQ 主题
+	TalkExportTopic  = "im.talk.export"       // NSQ 主题
 )
+
+// LinkPreviewMessage 文本消息链接预览任务
+type LinkPreviewMessage struct {
+	TalkMode int      `json:"talk_mode"`  // 对话类型[1:私信;2:群聊;]
+	ToFromId int      `json:"to_from_id"` // 好友ID或群ID
+	MsgId    string   `json:"msg_id"`     // 消息ID，私聊为发送者的消息ID
+	Urls     []string `json:"urls"`       // 消息中的链接
+}
+
+// BroadcastJobMessage 群发任务，每条消息处理一批接收者，未完成时重新投递
+type BroadcastJobMessage struct {
+	JobId int `json:"job_id"` // 任务ID
+}
+
+// TalkExportJobMessage 聊天记录导出任务
+type TalkExportJobMessage struct {
+	ExportId int `json:"export_id"` // 导出任务ID
+}
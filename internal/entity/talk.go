@@ -7,16 +7,24 @@ const (
 )
 
 const (
-	PushEventImMessage         = "im.message"          // 对话消息推送
-	PushEventImMessageKeyboard = "im.message.keyboard" // 键盘输入事件推送
-	PushEventImMessageRevoke   = "im.message.revoke"   // 聊天消息撤销推送
-	PushEventContactApply      = "im.contact.apply"    // 好友申请消息推送
-	PushEventContactStatus     = "im.contact.status"   // 用户在线状态推送
-	PushEventGroupApply        = "im.group.apply"      // 用户在线状态推送
-	PushEventImCallInvite      = "im.call.invite"      // 通话邀请
-	PushEventImCallAccept      = "im.call.accept"      // 接受通话
-	PushEventImCallReject      = "im.call.reject"      // 拒绝通话
-	PushEventImCallHangup      = "im.call.hangup"      // 挂断通话
+	PushEventImMessage         = "im.message"           // 对话消息推送
+	PushEventImMessageKeyboard = "im.message.keyboard"  // 键盘输入事件推送
+	PushEventImMessageRevoke   = "im.message.revoke"    // 聊天消息撤销推送
+	PushEventImMessageThread   = "im.message.thread"    // 群消息话题回复推送
+	PushEventImMessagePin      = "im.message.pin"       // 消息置顶推送
+	PushEventImMessageExpired  = "im.message.expired"   // 消息过期销毁推送
+	PushEventImMessageUpdate   = "im.message.update"    // 消息内容更新推送
+	PushEventContactApply      = "im.contact.apply"     // 好友申请消息推送
+	PushEventContactStatus     = "im.contact.status"    // 用户在线状态推送
+	PushEventGroupApply        = "im.group.apply"       // 用户在线状态推送
+	PushEventGroupMute         = "im.group.mute"        // 群禁言状态变更推送
+	PushEventGroupNotice       = "im.group.notice"      // 群公告发布及确认提醒推送
+	PushEventGroupVoteUpdate   = "im.group.vote.update" // 群投票结果更新推送
+	PushEventImCallInvite      = "im.call.invite"       // 通话邀请
+	PushEventImCallAccept      = "im.call.accept"       // 接受通话
+	PushEventImCallReject      = "im.call.reject"       // 拒绝通话
+	PushEventImCallHangup      = "im.call.hangup"       // 挂断通话
+	PushEventTalkExport        = "im.talk.export"       // 聊天记录导出完成
 )
 
 // IM消息类型
@@ -39,6 +47,7 @@ const (
 	ChatMsgTypeRTCCall     = 14 // 音视频通话
 	ChatMsgTypeRedEnvelope = 15 // 红包消息
 	ChatMsgTypeTransfer    = 16 // 转账消息
+	ChatMsgTypeInteractive = 17 // 交互卡片消息
 
 	ChatMsgSysText                   = 1000 // 系统文本消息
 	ChatMsgSysGroupCreate            = 1101 // 创建群聊消息
@@ -70,6 +79,7 @@ var ChatMsgTypeMapping = map[int]string{
 	ChatMsgTypeRTCCall:               "[通话记录]",
 	ChatMsgTypeRedEnvelope:           "[红包]",
 	ChatMsgTypeTransfer:              "[转账]",
+	ChatMsgTypeInteractive:           "[卡片消息]",
 	ChatMsgSysText:                   "[系统消息]",
 	ChatMsgSysGroupCreate:            "[创建群消息]",
 	ChatMsgSysGroupMemberJoin:        "[加入群消息]",
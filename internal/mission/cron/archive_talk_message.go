@@ -0,0 +1,217 @@
+package cron
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/gzydong/go-chat/config"
+	"github.com/gzydong/go-chat/internal/entity"
+	"github.com/gzydong/go-chat/internal/pkg/core/crontab"
+	"github.com/gzydong/go-chat/internal/repository/model"
+	"github.com/gzydong/go-chat/internal/repository/repo"
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const talkArchiveDefaultBatchSize = 1000
+
+var _ crontab.ICrontab = (*ArchiveTalkMessage)(nil)
+
+// ArchiveTalkMessage 将早于指定月数的聊天记录按发送月份迁移到归档表，如 talk_user_message_archive_202401
+type ArchiveTalkMessage struct {
+	Config *config.Config
+	DB     *gorm.DB
+}
+
+func (a *ArchiveTalkMessage) Name() string {
+	return "talk.message.archive"
+}
+
+// Spec 配置定时任务规则
+// 每天凌晨3点执行
+func (a *ArchiveTalkMessage) Spec() string {
+	return "0 3 * * *"
+}
+
+func (a *ArchiveTalkMessage) Enable() bool {
+	return a.Config.Archive != nil && a.Config.Archive.Months > 0
+}
+
+func (a *ArchiveTalkMessage) Do(ctx context.Context) error {
+	size := a.Config.Archive.BatchSize
+	if size <= 0 {
+		size = talkArchiveDefaultBatchSize
+	}
+
+	cutoff := talkArchiveCutoff(time.Now(), a.Config.Archive.Months)
+
+	for talkMode, source := range map[int]string{
+		entity.ChatPrivateMode: model.TalkUserMessage{}.TableName(),
+		entity.ChatGroupMode:   model.TalkGroupMessage{}.TableName(),
+	} {
+		count, err := a.archive(ctx, talkMode, source, cutoff, size)
+		if err != nil {
+			return err
+		}
+
+		if count > 0 {
+			slog.InfoContext(ctx, "聊天记录归档完成", "table", source, "count", count)
+		}
+	}
+
+	return nil
+}
+
+// archive 分批迁移发送时间早于 cutoff 的记录，返回迁移的记录数
+func (a *ArchiveTalkMessage) archive(ctx context.Context, talkMode int, source string, cutoff time.Time, size int) (int, error) {
+	total := 0
+
+	for {
+		var rows []struct {
+			Id       int64
+			SendTime time.Time
+		}
+
+		err := a.DB.WithContext(ctx).Table(source).Select("id, send_time").
+			Where("send_time < ?", cutoff).
+			Order("send_time asc").Limit(size).Scan(&rows).Error
+		if err != nil {
+			return total, err
+		}
+
+		months := make(map[int][]int64)
+		for _, row := range rows {
+			month := talkArchiveMonth(row.SendTime)
+			months[month] = append(months[month], row.Id)
+		}
+
+		keys := make([]int, 0, len(months))
+		for month := range months {
+			keys = append(keys, month)
+		}
+
+		sort.Ints(keys)
+
+		for _, month := range keys {
+			if err := a.move(ctx, talkMode, source, month, months[month]); err != nil {
+				return total, err
+			}
+		}
+
+		total += len(rows)
+
+		if len(rows) < size {
+			return total, nil
+		}
+	}
+}
+
+// move 将记录迁移到对应月份的归档表，迁移与删除在同一事务中完成
+func (a *ArchiveTalkMessage) move(ctx context.Context, talkMode int, source string, month int, ids []int64) error {
+	table := fmt.Sprintf("%s_archive_%d", source, month)
+
+	// 归档表与原表结构一致，建表语句会隐式提交，需在事务外执行
+	if err := a.DB.WithContext(ctx).Exec(fmt.Sprintf("CREATE TABLE IF NOT EXISTS `%s` LIKE `%s`", table, source)).Error; err != nil {
+		return err
+	}
+
+	now := time.Now()
+	err := a.DB.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&model.TalkMessageArchive{
+		TalkMode:     talkMode,
+		Month:        month,
+		ArchiveTable: table,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}).Error
+	if err != nil {
+		return err
+	}
+
+	return a.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Exec(fmt.Sprintf("INSERT INTO `%s` SELECT * FROM `%s` WHERE id IN ?", table, source), ids)
+		if res.Error != nil {
+			return res.Error
+		}
+
+		if err := a.raiseSequence(tx, talkMode, source, ids); err != nil {
+			return err
+		}
+
+		if err := tx.Exec(fmt.Sprintf("DELETE FROM `%s` WHERE id IN ?", source), ids).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&model.TalkMessageArchive{}).
+			Where("talk_mode = ? and month = ?", talkMode, month).
+			Update("record_count", gorm.Expr("record_count + ?", res.RowsAffected)).Error
+	})
+}
+
+type talkArchiveSequence struct {
+	SourceId int32
+	Sequence int64
+}
+
+// raiseSequence 将号段表中已分配的最大ID提升至归档记录的最大时序ID
+// 号段按消息表中的最大时序ID对齐，会话记录全部归档后若号段表无记录，新消息的时序ID会从头分配并小于归档记录
+func (a *ArchiveTalkMessage) raiseSequence(tx *gorm.DB, talkMode int, source string, ids []int64) error {
+	column := lo.Ternary(talkMode == entity.ChatGroupMode, "group_id", "user_id")
+
+	var items []talkArchiveSequence
+	err := tx.Table(source).Select(column+" as source_id, max(sequence) as sequence").
+		Where("id IN ?", ids).Group(column).Scan(&items).Error
+	if err != nil {
+		return err
+	}
+
+	rows := talkArchiveSequences(talkMode, items, time.Now())
+	if len(rows) == 0 {
+		return nil
+	}
+
+	return tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "seq_type"}, {Name: "source_id"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"max_seq":    gorm.Expr("greatest(max_seq, values(max_seq))"),
+			"updated_at": gorm.Expr("values(updated_at)"),
+		}),
+	}).Create(&rows).Error
+}
+
+// talkArchiveSequences 生成归档会话的号段记录，私聊按用户、群聊按群分配时序ID
+func talkArchiveSequences(talkMode int, items []talkArchiveSequence, now time.Time) []*model.Sequence {
+	seqType := lo.Ternary[int32](talkMode == entity.ChatGroupMode, repo.SequenceTypeGroup, repo.SequenceTypeUser)
+
+	rows := make([]*model.Sequence, 0, len(items))
+	for _, item := range items {
+		if item.Sequence <= 0 {
+			continue
+		}
+
+		rows = append(rows, &model.Sequence{
+			SeqType:   seqType,
+			SourceId:  item.SourceId,
+			CurSeq:    item.Sequence,
+			MaxSeq:    item.Sequence,
+			Step:      repo.SequenceDefaultStep,
+			CreatedAt: now,
+			UpdatedAt: now,
+		})
+	}
+
+	return rows
+}
+
+// talkArchiveCutoff 归档截止时间，只归档完整的月份
+func talkArchiveCutoff(now time.Time, months int) time.Time {
+	return time.Date(now.Year(), now.Month()-time.Month(months), 1, 0, 0, 0, 0, now.Location())
+}
+
+// talkArchiveMonth 记录所属的归档月份，格式 200601
+func talkArchiveMonth(t time.Time) int {
+	return t.Year()*100 + int(t.Month())
+}
@@ -0,0 +1,55 @@
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gzydong/go-chat/internal/entity"
+	"github.com/gzydong/go-chat/internal/repository/repo"
+)
+
+func TestTalkArchiveCutoff(t *testing.T) {
+	tests := []struct {
+		now    time.Time
+		months int
+		want   time.Time
+	}{
+		{time.Date(2024, 5, 20, 10, 0, 0, 0, time.UTC), 1, time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{time.Date(2024, 5, 20, 10, 0, 0, 0, time.UTC), 12, time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{time.Date(2024, 3, 31, 23, 0, 0, 0, time.UTC), 5, time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		if got := talkArchiveCutoff(tt.now, tt.months); !got.Equal(tt.want) {
+			t.Errorf("talkArchiveCutoff(%s, %d) = %s, want %s", tt.now, tt.months, got, tt.want)
+		}
+	}
+}
+
+func TestTalkArchiveMonth(t *testing.T) {
+	if got := talkArchiveMonth(time.Date(2023, 9, 30, 23, 59, 59, 0, time.Local)); got != 202309 {
+		t.Errorf("talkArchiveMonth = %d, want 202309", got)
+	}
+}
+
+// 会话记录全部归档且号段表无记录时，号段需从归档记录的最大时序ID之后继续分配
+func TestTalkArchiveSequences(t *testing.T) {
+	now := time.Now()
+
+	rows := talkArchiveSequences(entity.ChatPrivateMode, []talkArchiveSequence{
+		{SourceId: 1, Sequence: 120},
+		{SourceId: 2, Sequence: 0},
+	}, now)
+	if len(rows) != 1 {
+		t.Fatalf("talkArchiveSequences() len = %d, want 1", len(rows))
+	}
+
+	if rows[0].SeqType != repo.SequenceTypeUser || rows[0].SourceId != 1 || rows[0].MaxSeq != 120 || rows[0].CurSeq != 120 {
+		t.Errorf("talkArchiveSequences() = %+v, want user 1 seeded at 120", rows[0])
+	}
+
+	rows = talkArchiveSequences(entity.ChatGroupMode, []talkArchiveSequence{{SourceId: 9, Sequence: 1724000000000000000}}, now)
+	if len(rows) != 1 || rows[0].SeqType != repo.SequenceTypeGroup || rows[0].MaxSeq != 1724000000000000000 {
+		t.Errorf("talkArchiveSequences() = %+v, want group 9 seeded at snowflake sequence", rows)
+	}
+}
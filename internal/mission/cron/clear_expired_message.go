@@ -0,0 +1,42 @@
+package cron
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/gzydong/go-chat/internal/pkg/core/crontab"
+	"github.com/gzydong/go-chat/internal/service"
+)
+
+var _ crontab.ICrontab = (*ClearExpiredMessage)(nil)
+
+type ClearExpiredMessage struct {
+	TalkMessageExpireService service.ITalkMessageExpireService
+}
+
+func (c *ClearExpiredMessage) Name() string {
+	return "talk.expired_message.clear"
+}
+
+// Spec 配置定时任务规则
+// 每分钟执行一次，销毁已过期的消息
+func (c *ClearExpiredMessage) Spec() string {
+	return "* * * * *"
+}
+
+func (c *ClearExpiredMessage) Enable() bool {
+	return true
+}
+
+func (c *ClearExpiredMessage) Do(ctx context.Context) error {
+	count, err := c.TalkMessageExpireService.PurgeExpired(ctx)
+	if err != nil {
+		return err
+	}
+
+	if count > 0 {
+		slog.InfoContext(ctx, "过期消息销毁完成", "count", count)
+	}
+
+	return nil
+}
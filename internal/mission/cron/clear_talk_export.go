@@ -0,0 +1,64 @@
+package cron
+
+import (
+	"context"
+	"time"
+
+	"github.com/gzydong/go-chat/internal/pkg/core/crontab"
+	"github.com/gzydong/go-chat/internal/pkg/filesystem"
+	"github.com/gzydong/go-chat/internal/repository/model"
+	"gorm.io/gorm"
+)
+
+var _ crontab.ICrontab = (*ClearTalkExport)(nil)
+
+// ClearTalkExport 删除已过期的聊天记录导出文件
+type ClearTalkExport struct {
+	DB         *gorm.DB
+	Filesystem filesystem.IFilesystem
+}
+
+// Spec 配置定时任务规则
+// 每天凌晨2点执行
+func (c *ClearTalkExport) Spec() string {
+	return "0 2 * * *"
+}
+
+func (c *ClearTalkExport) Name() string {
+	return "talk.export.clear"
+}
+
+func (c *ClearTalkExport) Enable() bool {
+	return true
+}
+
+func (c *ClearTalkExport) Do(ctx context.Context) error {
+	lastId, size := 0, 100
+
+	for {
+		items := make([]*model.TalkExport, 0)
+
+		err := c.DB.WithContext(ctx).Model(&model.TalkExport{}).
+			Where("id > ? and status = ? and file_path != '' and expired_at <= ?", lastId, model.TalkExportStatusCompleted, time.Now()).
+			Order("id asc").Limit(size).Scan(&items).Error
+		if err != nil {
+			return err
+		}
+
+		for _, item := range items {
+			if err := c.Filesystem.Delete(c.Filesystem.BucketPrivateName(), item.FilePath); err != nil {
+				continue
+			}
+
+			c.DB.WithContext(ctx).Model(&model.TalkExport{}).Where("id = ?", item.Id).Update("file_path", "")
+		}
+
+		if len(items) < size {
+			break
+		}
+
+		lastId = items[len(items)-1].Id
+	}
+
+	return nil
+}
@@ -0,0 +1,42 @@
+package cron
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/gzydong/go-chat/internal/pkg/core/crontab"
+	"github.com/gzydong/go-chat/internal/service"
+)
+
+var _ crontab.ICrontab = (*CloseGroupVote)(nil)
+
+type CloseGroupVote struct {
+	GroupVoteService service.IGroupVoteService
+}
+
+func (c *CloseGroupVote) Name() string {
+	return "group.vote.close"
+}
+
+// Spec 配置定时任务规则
+// 每分钟执行一次，结束已到截止时间的群投票
+func (c *CloseGroupVote) Spec() string {
+	return "* * * * *"
+}
+
+func (c *CloseGroupVote) Enable() bool {
+	return true
+}
+
+func (c *CloseGroupVote) Do(ctx context.Context) error {
+	count, err := c.GroupVoteService.CloseExpired(ctx)
+	if err != nil {
+		return err
+	}
+
+	if count > 0 {
+		slog.InfoContext(ctx, "到期群投票结束完成", "count", count)
+	}
+
+	return nil
+}
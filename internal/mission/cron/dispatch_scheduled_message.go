@@ -0,0 +1,42 @@
+package cron
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/gzydong/go-chat/internal/pkg/core/crontab"
+	"github.com/gzydong/go-chat/internal/service"
+)
+
+var _ crontab.ICrontab = (*DispatchScheduledMessage)(nil)
+
+type DispatchScheduledMessage struct {
+	TalkScheduleService service.ITalkScheduleService
+}
+
+func (c *DispatchScheduledMessage) Name() string {
+	return "talk.scheduled_message.dispatch"
+}
+
+// Spec 配置定时任务规则
+// 每分钟执行一次，发送已到时间的定时消息
+func (c *DispatchScheduledMessage) Spec() string {
+	return "* * * * *"
+}
+
+func (c *DispatchScheduledMessage) Enable() bool {
+	return true
+}
+
+func (c *DispatchScheduledMessage) Do(ctx context.Context) error {
+	count, err := c.TalkScheduleService.DispatchDue(ctx)
+	if err != nil {
+		return err
+	}
+
+	if count > 0 {
+		slog.InfoContext(ctx, "定时消息发送完成", "count", count)
+	}
+
+	return nil
+}
@@ -0,0 +1,42 @@
+package cron
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/gzydong/go-chat/internal/pkg/core/crontab"
+	"github.com/gzydong/go-chat/internal/service"
+)
+
+var _ crontab.ICrontab = (*ReleaseGroupMute)(nil)
+
+type ReleaseGroupMute struct {
+	GroupMuteService service.IGroupMuteService
+}
+
+func (c *ReleaseGroupMute) Name() string {
+	return "group.mute.release"
+}
+
+// Spec 配置定时任务规则
+// 每分钟执行一次，解除已到期的群成员禁言及全员禁言
+func (c *ReleaseGroupMute) Spec() string {
+	return "* * * * *"
+}
+
+func (c *ReleaseGroupMute) Enable() bool {
+	return true
+}
+
+func (c *ReleaseGroupMute) Do(ctx context.Context) error {
+	count, err := c.GroupMuteService.ReleaseExpired(ctx)
+	if err != nil {
+		return err
+	}
+
+	if count > 0 {
+		slog.InfoContext(ctx, "到期禁言解除完成", "count", count)
+	}
+
+	return nil
+}
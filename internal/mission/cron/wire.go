@@ -6,11 +6,23 @@ type Crontab struct {
 	ClearArticle      *ClearArticle
 	ClearTmpFile      *ClearTmpFile
 	ExpireRedEnvelope *ExpireRedEnvelope
+	ScheduledMessage  *DispatchScheduledMessage
+	ExpiredMessage    *ClearExpiredMessage
+	ClearTalkExport   *ClearTalkExport
+	ArchiveMessage    *ArchiveTalkMessage
+	ReleaseGroupMute  *ReleaseGroupMute
+	CloseGroupVote    *CloseGroupVote
 }
 
 var ProviderSet = wire.NewSet(
 	wire.Struct(new(ClearArticle), "*"),
 	wire.Struct(new(ClearTmpFile), "*"),
 	wire.Struct(new(ExpireRedEnvelope), "*"),
+	wire.Struct(new(DispatchScheduledMessage), "*"),
+	wire.Struct(new(ClearExpiredMessage), "*"),
+	wire.Struct(new(ClearTalkExport), "*"),
+	wire.Struct(new(ArchiveTalkMessage), "*"),
+	wire.Struct(new(ReleaseGroupMute), "*"),
+	wire.Struct(new(CloseGroupVote), "*"),
 	wire.Struct(new(Crontab), "*"),
 )
@@ -0,0 +1,47 @@
+package mission
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/gzydong/go-chat/internal/service"
+	"github.com/urfave/cli/v2"
+)
+
+type ImportProvider struct {
+	TalkImportService service.ITalkImportService
+}
+
+// Import 从存档导入聊天记录，导入结果以 JSON 格式输出
+func Import(ctx *cli.Context, app *ImportProvider) error {
+	data, err := os.ReadFile(ctx.String("file"))
+	if err != nil {
+		return err
+	}
+
+	opt := &service.TalkImportOpt{
+		Data:   data,
+		DryRun: ctx.Bool("dry-run"),
+	}
+
+	if name := ctx.String("senders"); name != "" {
+		content, err := os.ReadFile(name)
+		if err != nil {
+			return err
+		}
+
+		if err := json.Unmarshal(content, &opt.Senders); err != nil {
+			return fmt.Errorf("发送者映射文件格式错误: %s", err.Error())
+		}
+	}
+
+	report, err := app.TalkImportService.Import(ctx.Context, opt)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
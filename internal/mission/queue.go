@@ -5,18 +5,42 @@ import (
 	"log"
 	"time"
 
+	"github.com/gzydong/go-chat/config"
+	"github.com/gzydong/go-chat/internal/entity"
 	"github.com/gzydong/go-chat/internal/mission/queue"
+	"github.com/gzydong/go-chat/internal/pkg/core/consumer"
+	"github.com/nsqio/go-nsq"
 	"github.com/redis/go-redis/v9"
 	"github.com/urfave/cli/v2"
 )
 
 type QueueProvider struct {
-	Consumers *queue.Consumers
-	Redis     *redis.Client
+	Config      *config.Config
+	Consumers   *queue.Consumers
+	OutboxRelay *queue.MessageOutboxRelay
+	Redis       *redis.Client
 }
 
 func Queue(ctx *cli.Context, app *QueueProvider) error {
-	topics := []string{"im.user.login"}
+	topics := []string{entity.LoginTopic, entity.LinkPreviewTopic}
+
+	// 消息发件箱投递
+	go app.OutboxRelay.Run(ctx.Context)
+
+	// 群发及导出任务通过 NSQ 投递
+	if app.Config.Nsq != nil && app.Config.Nsq.Addr != "" {
+		nsqConsumer := consumer.NewConsumer(app.Config.Nsq.Addr, nsq.NewConfig())
+		nsqConsumer.Register("queue", app.Consumers.BroadcastConsumer)
+		nsqConsumer.Register("queue", app.Consumers.TalkExportConsumer)
+
+		go func() {
+			if err := nsqConsumer.Start(ctx.Context, "queue"); err != nil {
+				log.Printf("nsq consumer start err: %s", err.Error())
+			}
+		}()
+	} else {
+		log.Printf("nsq is not configured, broadcast and export jobs will not be processed")
+	}
 
 	sub := app.Redis.Subscribe(ctx.Context, topics...)
 
@@ -27,8 +51,15 @@ func Queue(ctx *cli.Context, app *QueueProvider) error {
 
 	for data := range sub.Channel(redis.WithChannelHealthCheckInterval(10 * time.Second)) {
 		switch data.Channel {
-		case "im.user.login":
+		case entity.LoginTopic:
 			_ = app.Consumers.UserLoginConsumer.Do(context.Background(), []byte(data.Payload), 1)
+		case entity.LinkPreviewTopic:
+			// 抓取网页耗时较长，避免阻塞其它消息的消费
+			go func(payload []byte) {
+				if err := app.Consumers.LinkPreviewConsumer.Do(context.Background(), payload, 1); err != nil {
+					log.Printf("link preview consume err: %s", err.Error())
+				}
+			}([]byte(data.Payload))
 		}
 	}
 
@@ -0,0 +1,274 @@
+package queue
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gzydong/go-chat/internal/entity"
+	"github.com/gzydong/go-chat/internal/pkg/core/consumer"
+	"github.com/gzydong/go-chat/internal/pkg/logger"
+	"github.com/gzydong/go-chat/internal/pkg/strutil"
+	"github.com/gzydong/go-chat/internal/repository/model"
+	"github.com/gzydong/go-chat/internal/repository/repo"
+	"github.com/gzydong/go-chat/internal/service"
+	"github.com/gzydong/go-chat/internal/service/message"
+	"github.com/nsqio/go-nsq"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+const (
+	broadcastChunkSeconds = 20              // 每条队列消息最多处理的时长，避免超过 NSQ 消息超时
+	broadcastCheckEvery   = 20              // 每发送多少条检查一次任务是否已取消
+	broadcastLockExpire   = 2 * time.Minute // 任务处理锁过期时间
+)
+
+var _ consumer.IConsumerHandle = (*BroadcastConsumer)(nil)
+
+// broadcastUnlockScript 仅释放自己持有的任务锁，避免锁过期后误删其它消费者的锁
+var broadcastUnlockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// BroadcastConsumer 按任务限速逐个发送群发消息，每条队列消息处理一批接收者，未完成时重新投递
+type BroadcastConsumer struct {
+	Source                 *repo.Source
+	BroadcastJobRepo       *repo.BroadcastJob
+	BroadcastRecipientRepo *repo.BroadcastRecipient
+	UsersRepo              *repo.Users
+	GroupMemberRepo        *repo.GroupMember
+	UserBlockRepo          *repo.UserBlock
+	AuthService            service.IAuthService
+	TalkUserMessage        *repo.TalkUserMessage
+	TalkGroupMessage       *repo.TalkGroupMessage
+	MessageService         message.IService
+	Producer               *nsq.Producer
+}
+
+func (b *BroadcastConsumer) Touch() bool {
+	return true
+}
+
+func (b *BroadcastConsumer) Topic() string {
+	return entity.BroadcastTopic
+}
+
+func (b *BroadcastConsumer) Channel() string {
+	return "default"
+}
+
+func (b *BroadcastConsumer) Do(ctx context.Context, msg []byte, _ uint16) error {
+	var in entity.BroadcastJobMessage
+	if err := json.Unmarshal(msg, &in); err != nil {
+		return nil
+	}
+
+	// 同一任务同时只允许一个消费者处理，加锁失败时由 NSQ 延迟重试
+	lockKey, token := fmt.Sprintf("im:message:broadcast-lock:%d", in.JobId), strutil.NewMsgId()
+	ok, err := b.Source.Redis().SetNX(ctx, lockKey, token, broadcastLockExpire).Result()
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return errors.New("broadcast job is processing")
+	}
+
+	next, err := b.process(ctx, in.JobId)
+	if e := broadcastUnlockScript.Run(ctx, b.Source.Redis(), []string{lockKey}, token).Err(); e != nil {
+		logger.Errorf("broadcast job %d unlock err: %s", in.JobId, e.Error())
+	}
+
+	if err != nil || !next {
+		return err
+	}
+
+	return b.Producer.Publish(entity.BroadcastTopic, msg)
+}
+
+// process 处理一批接收者，返回任务是否还有待发送的接收者
+func (b *BroadcastConsumer) process(ctx context.Context, jobId int) (bool, error) {
+	job, err := b.BroadcastJobRepo.FindById(ctx, jobId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	switch job.Status {
+	case model.BroadcastStatusPending:
+		_, err := b.BroadcastJobRepo.UpdateByWhere(ctx, map[string]any{
+			"status":     model.BroadcastStatusRunning,
+			"started_at": sql.NullTime{Time: time.Now(), Valid: true},
+		}, "id = ? and status = ?", job.Id, model.BroadcastStatusPending)
+		if err != nil {
+			return false, err
+		}
+	case model.BroadcastStatusRunning:
+	case model.BroadcastStatusCancelled:
+		return false, b.settle(ctx, job)
+	default:
+		return false, nil
+	}
+
+	items, err := b.BroadcastRecipientRepo.FindUnsent(ctx, job.Id, job.Rate*broadcastChunkSeconds)
+	if err != nil {
+		return false, err
+	}
+
+	if len(items) == 0 {
+		_, err := b.BroadcastJobRepo.UpdateByWhere(ctx, map[string]any{
+			"status":      model.BroadcastStatusCompleted,
+			"finished_at": sql.NullTime{Time: time.Now(), Valid: true},
+		}, "id = ? and status = ?", job.Id, model.BroadcastStatusRunning)
+		return false, err
+	}
+
+	ticker := time.NewTicker(time.Second / time.Duration(job.Rate))
+	defer ticker.Stop()
+
+	for i, item := range items {
+		if i > 0 && i%broadcastCheckEvery == 0 {
+			current, err := b.BroadcastJobRepo.FindById(ctx, job.Id)
+			if err != nil {
+				return false, err
+			}
+
+			if current.Status == model.BroadcastStatusCancelled {
+				return false, b.settle(ctx, job)
+			}
+		}
+
+		// 抢占接收者，已被取消的跳过
+		claimed, err := b.BroadcastRecipientRepo.UpdateByWhere(ctx, map[string]any{
+			"status": model.BroadcastRecipientSending,
+		}, "id = ? and status in ?", item.Id, []int{model.BroadcastRecipientPending, model.BroadcastRecipientSending})
+		if err != nil {
+			return false, err
+		}
+
+		if claimed == 0 {
+			continue
+		}
+
+		<-ticker.C
+
+		if err := b.send(ctx, job, item); err != nil {
+			b.finish(ctx, job.Id, item, model.BroadcastRecipientFailed, err.Error())
+		} else {
+			b.finish(ctx, job.Id, item, model.BroadcastRecipientSent, "")
+		}
+	}
+
+	return true, nil
+}
+
+// send 发送消息，上次中断前已发送成功的不再重复发送
+func (b *BroadcastConsumer) send(ctx context.Context, job *model.BroadcastJob, item *model.BroadcastRecipient) error {
+	if b.isSent(ctx, item) {
+		return nil
+	}
+
+	switch item.TalkMode {
+	case entity.ChatPrivateMode:
+		user, err := b.UsersRepo.FindById(ctx, item.ToFromId)
+		if err != nil || user.Status != model.UsersStatusNormal {
+			return errors.New("用户不存在或已停用")
+		}
+
+		// 群发的接收者不要求是发送者的好友，只校验黑名单
+		switch b.UserBlockRepo.FindBlocker(ctx, job.SenderId, item.ToFromId) {
+		case job.SenderId:
+			return errors.New("发送者已将对方加入黑名单")
+		case item.ToFromId:
+			return errors.New("对方已将发送者加入黑名单")
+		}
+	case entity.ChatGroupMode:
+		err := b.AuthService.IsAuth(ctx, &service.AuthOption{
+			TalkType:          entity.ChatGroupMode,
+			UserId:            job.SenderId,
+			ToFromId:          item.ToFromId,
+			IsVerifyGroupMute: true,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return b.MessageService.CreateMessage(ctx, message.CreateMessageOption{
+		MsgId:    item.MsgId,
+		TalkMode: item.TalkMode,
+		FromId:   job.SenderId,
+		ToFromId: item.ToFromId,
+		MsgType:  job.MsgType,
+		Extra:    job.Extra,
+	})
+}
+
+func (b *BroadcastConsumer) isSent(ctx context.Context, item *model.BroadcastRecipient) bool {
+	var err error
+	if item.TalkMode == entity.ChatPrivateMode {
+		_, err = b.TalkUserMessage.FindByMsgId(ctx, item.MsgId)
+	} else {
+		_, err = b.TalkGroupMessage.FindByMsgId(ctx, item.MsgId)
+	}
+
+	return err == nil
+}
+
+// finish 记录接收者的发送结果并累加任务计数
+func (b *BroadcastConsumer) finish(ctx context.Context, jobId int, item *model.BroadcastRecipient, status int, reason string) {
+	if len([]rune(reason)) > 255 {
+		reason = string([]rune(reason)[:255])
+	}
+
+	column := "sent_count"
+	switch status {
+	case model.BroadcastRecipientFailed:
+		column = "failed_count"
+	case model.BroadcastRecipientCancelled:
+		column = "cancelled_count"
+	}
+
+	err := b.Source.Db().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&model.BroadcastRecipient{}).
+			Where("id = ? and status = ?", item.Id, model.BroadcastRecipientSending).
+			Updates(map[string]any{"status": status, "error": reason})
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+
+		return tx.Model(&model.BroadcastJob{}).Where("id = ?", jobId).
+			Update(column, gorm.Expr(column+" + 1")).Error
+	})
+	if err != nil {
+		logger.Errorf("broadcast recipient %d finish err: %s", item.Id, err.Error())
+	}
+}
+
+// settle 任务取消后，确认上次中断时处于发送中的接收者的发送结果
+func (b *BroadcastConsumer) settle(ctx context.Context, job *model.BroadcastJob) error {
+	items, err := b.BroadcastRecipientRepo.FindAllByWhere(ctx, "job_id = ? and status = ?", job.Id, model.BroadcastRecipientSending)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		if b.isSent(ctx, item) {
+			b.finish(ctx, job.Id, item, model.BroadcastRecipientSent, "")
+		} else {
+			b.finish(ctx, job.Id, item, model.BroadcastRecipientCancelled, "")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,171 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/gzydong/go-chat/internal/entity"
+	"github.com/gzydong/go-chat/internal/logic"
+	"github.com/gzydong/go-chat/internal/pkg/core/consumer"
+	"github.com/gzydong/go-chat/internal/pkg/jsonutil"
+	"github.com/gzydong/go-chat/internal/pkg/logger"
+	"github.com/gzydong/go-chat/internal/pkg/unfurl"
+	"github.com/gzydong/go-chat/internal/repository/cache"
+	"github.com/gzydong/go-chat/internal/repository/model"
+	"github.com/gzydong/go-chat/internal/repository/repo"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+const (
+	linkPreviewCacheExpire = 24 * time.Hour
+	linkPreviewFailExpire  = 10 * time.Minute // 抓取失败的链接在此时间内不再抓取
+	linkPreviewConcurrency = 16
+)
+
+var _ consumer.IConsumerHandle = (*LinkPreviewConsumer)(nil)
+
+// linkPreviewWorkers 限制同时抓取的任务数
+var linkPreviewWorkers = make(chan struct{}, linkPreviewConcurrency)
+
+// LinkPreviewConsumer 抓取文本消息中链接的预览信息，补充到消息内容后推送更新
+type LinkPreviewConsumer struct {
+	Source             *repo.Source
+	TalkUserMessage    *repo.TalkUserMessage
+	TalkGroupMessage   *repo.TalkGroupMessage
+	LinkPreviewStorage *cache.LinkPreviewStorage
+	Fetcher            *unfurl.Fetcher
+	PushMessage        *logic.PushMessage
+}
+
+func (l *LinkPreviewConsumer) Touch() bool {
+	return false
+}
+
+func (l *LinkPreviewConsumer) Topic() string {
+	return entity.LinkPreviewTopic
+}
+
+func (l *LinkPreviewConsumer) Channel() string {
+	return "default"
+}
+
+func (l *LinkPreviewConsumer) Do(ctx context.Context, msg []byte, _ uint16) error {
+	var in entity.LinkPreviewMessage
+	if err := json.Unmarshal(msg, &in); err != nil {
+		return err
+	}
+
+	linkPreviewWorkers <- struct{}{}
+	defer func() { <-linkPreviewWorkers }()
+
+	previews := make([]*model.TalkRecordExtraLinkPreview, 0, len(in.Urls))
+	for _, url := range in.Urls {
+		if preview := l.preview(ctx, url); preview != nil {
+			previews = append(previews, preview)
+		}
+	}
+
+	if len(previews) == 0 {
+		return nil
+	}
+
+	return l.update(ctx, &in, previews)
+}
+
+// preview 优先读取缓存，未命中时抓取并缓存结果
+func (l *LinkPreviewConsumer) preview(ctx context.Context, url string) *model.TalkRecordExtraLinkPreview {
+	value, err := l.LinkPreviewStorage.Get(ctx, url)
+	if err == nil {
+		if value == "" {
+			return nil
+		}
+
+		var preview model.TalkRecordExtraLinkPreview
+		if err := jsonutil.Unmarshal(value, &preview); err == nil {
+			return &preview
+		}
+	} else if !errors.Is(err, redis.Nil) {
+		logger.Errorf("link preview cache get err: %s", err.Error())
+	}
+
+	result, err := l.Fetcher.Fetch(ctx, url)
+	if err != nil {
+		_ = l.LinkPreviewStorage.Set(ctx, url, "", linkPreviewFailExpire)
+		return nil
+	}
+
+	preview := &model.TalkRecordExtraLinkPreview{
+		Url:         result.Url,
+		Title:       result.Title,
+		Description: result.Description,
+		Image:       result.Image,
+		SiteName:    result.SiteName,
+	}
+
+	_ = l.LinkPreviewStorage.Set(ctx, url, jsonutil.Encode(preview), linkPreviewCacheExpire)
+	return preview
+}
+
+// update 将预览补充到消息内容中，私聊消息双方的副本一并更新，已撤回或已删除的消息不再更新
+func (l *LinkPreviewConsumer) update(ctx context.Context, in *entity.LinkPreviewMessage, previews []*model.TalkRecordExtraLinkPreview) error {
+	var (
+		msgId string
+		extra string
+		db    = l.Source.Db().WithContext(ctx)
+	)
+
+	switch in.TalkMode {
+	case entity.ChatPrivateMode:
+		record, err := l.TalkUserMessage.FindByWhere(ctx, "msg_id = ?", in.MsgId)
+		if err != nil {
+			return nil
+		}
+
+		msgId, extra = record.OrgMsgId, record.Extra
+	case entity.ChatGroupMode:
+		record, err := l.TalkGroupMessage.FindByMsgId(ctx, in.MsgId)
+		if err != nil {
+			return nil
+		}
+
+		msgId, extra = record.MsgId, record.Extra
+	default:
+		return nil
+	}
+
+	var body model.TalkRecordExtraText
+	if err := jsonutil.Unmarshal(extra, &body); err != nil {
+		return err
+	}
+
+	body.Previews = previews
+	extra = jsonutil.Encode(body)
+
+	var res *gorm.DB
+	if in.TalkMode == entity.ChatPrivateMode {
+		res = db.Model(&model.TalkUserMessage{}).Where("org_msg_id = ? and is_revoked = ?", msgId, model.No).Update("extra", extra)
+	} else {
+		res = db.Model(&model.TalkGroupMessage{}).Where("msg_id = ? and is_revoked = ?", msgId, model.No).Update("extra", extra)
+	}
+
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return nil
+	}
+
+	return l.PushMessage.Push(ctx, entity.ImTopicChat, &entity.SubscribeMessage{
+		Event: entity.SubEventImMessageUpdate,
+		Payload: jsonutil.Encode(entity.SubEventImMessageUpdatePayload{
+			TalkMode: in.TalkMode,
+			ToFromId: in.ToFromId,
+			MsgId:    msgId,
+			Extra:    extra,
+		}),
+	})
+}
@@ -0,0 +1,74 @@
+package queue
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/gzydong/go-chat/internal/service/message"
+)
+
+const (
+	outboxRelayBatchSize = 200
+	outboxRelayInterval  = time.Second
+	outboxClearInterval  = time.Hour
+	outboxRetention      = 24 * time.Hour // 已投递记录保留时长
+)
+
+// MessageOutboxRelay 消息发件箱投递任务
+// 定时投递发送时未能及时推送的消息，并清理已投递的记录
+type MessageOutboxRelay struct {
+	MessageService message.IService
+}
+
+func (m *MessageOutboxRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(outboxRelayInterval)
+	defer ticker.Stop()
+
+	lastClearAt := time.Time{}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		m.relay(ctx)
+
+		if time.Since(lastClearAt) >= outboxClearInterval {
+			lastClearAt = time.Now()
+			m.clear(ctx)
+		}
+	}
+}
+
+// relay 循环投递直到没有待投递的记录
+func (m *MessageOutboxRelay) relay(ctx context.Context) {
+	for ctx.Err() == nil {
+		count, err := m.MessageService.RelayOutbox(ctx, outboxRelayBatchSize)
+		if err != nil {
+			slog.Error("message outbox relay error", "error", err)
+			return
+		}
+
+		if count < outboxRelayBatchSize {
+			return
+		}
+	}
+}
+
+func (m *MessageOutboxRelay) clear(ctx context.Context) {
+	before := time.Now().Add(-outboxRetention)
+
+	for ctx.Err() == nil {
+		count, err := m.MessageService.ClearOutbox(ctx, before, 1000)
+		if err != nil {
+			slog.Error("message outbox clear error", "error", err)
+			return
+		}
+
+		if count < 1000 {
+			return
+		}
+	}
+}
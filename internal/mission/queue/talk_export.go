@@ -0,0 +1,57 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gzydong/go-chat/internal/entity"
+	"github.com/gzydong/go-chat/internal/pkg/core/consumer"
+	"github.com/gzydong/go-chat/internal/repository/repo"
+	"github.com/gzydong/go-chat/internal/service"
+)
+
+const talkExportLockExpire = 30 * time.Minute // 导出任务处理锁过期时间
+
+var _ consumer.IConsumerHandle = (*TalkExportConsumer)(nil)
+
+// TalkExportConsumer 执行聊天记录导出任务
+type TalkExportConsumer struct {
+	Source            *repo.Source
+	TalkExportService service.ITalkExportService
+}
+
+func (t *TalkExportConsumer) Touch() bool {
+	return true
+}
+
+func (t *TalkExportConsumer) Topic() string {
+	return entity.TalkExportTopic
+}
+
+func (t *TalkExportConsumer) Channel() string {
+	return "default"
+}
+
+func (t *TalkExportConsumer) Do(ctx context.Context, msg []byte, _ uint16) error {
+	var in entity.TalkExportJobMessage
+	if err := json.Unmarshal(msg, &in); err != nil {
+		return nil
+	}
+
+	lockKey := fmt.Sprintf("im:talk:export-lock:%d", in.ExportId)
+	ok, err := t.Source.Redis().SetNX(ctx, lockKey, 1, talkExportLockExpire).Result()
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return errors.New("talk export is processing")
+	}
+
+	defer t.Source.Redis().Del(ctx, lockKey)
+
+	return t.TalkExportService.Run(ctx, in.ExportId)
+}
@@ -1,12 +1,24 @@
 package queue
 
-import "github.com/google/wire"
+import (
+	"github.com/google/wire"
+	"github.com/gzydong/go-chat/internal/pkg/unfurl"
+)
 
 type Consumers struct {
-	UserLoginConsumer *UserLoginConsumer
+	UserLoginConsumer   *UserLoginConsumer
+	LinkPreviewConsumer *LinkPreviewConsumer
+	BroadcastConsumer   *BroadcastConsumer
+	TalkExportConsumer  *TalkExportConsumer
 }
 
 var ProviderSet = wire.NewSet(
 	wire.Struct(new(Consumers), "*"),
 	wire.Struct(new(UserLoginConsumer), "*"),
+	wire.Struct(new(LinkPreviewConsumer), "*"),
+	wire.Struct(new(BroadcastConsumer), "*"),
+	wire.Struct(new(TalkExportConsumer), "*"),
+	wire.Struct(new(MessageOutboxRelay), "*"),
+	wire.Value(unfurl.Options{}),
+	unfurl.New,
 )
@@ -101,6 +101,47 @@ CREATE TABLE IF NOT EXISTS `article_tag`
   COLLATE = utf8mb4_general_ci COMMENT ='文章标签表';;
 
 
+CREATE TABLE IF NOT EXISTS `broadcast_job`
+(
+    `id`              int unsigned     NOT NULL AUTO_INCREMENT,
+    `admin_id`        int unsigned     NOT NULL COMMENT '创建任务的管理员ID',
+    `sender_id`       int unsigned     NOT NULL COMMENT '发送者用户ID',
+    `msg_type`        int unsigned     NOT NULL COMMENT '消息类型',
+    `extra`           text             NOT NULL COMMENT '消息内容',
+    `rate`            int unsigned     NOT NULL DEFAULT '10' COMMENT '每秒发送条数',
+    `status`          tinyint unsigned NOT NULL DEFAULT '1' COMMENT '任务状态[1:待执行;2:执行中;3:已完成;4:已取消;]',
+    `total_count`     int unsigned     NOT NULL DEFAULT '0' COMMENT '接收者总数',
+    `sent_count`      int unsigned     NOT NULL DEFAULT '0' COMMENT '发送成功数',
+    `failed_count`    int unsigned     NOT NULL DEFAULT '0' COMMENT '发送失败数',
+    `cancelled_count` int unsigned     NOT NULL DEFAULT '0' COMMENT '取消发送数',
+    `started_at`      datetime                  DEFAULT NULL COMMENT '开始时间',
+    `finished_at`     datetime                  DEFAULT NULL COMMENT '结束时间',
+    `created_at`      datetime         NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `updated_at`      datetime         NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    PRIMARY KEY (`id`),
+    KEY `idx_status_id` (`status`, `id`) USING BTREE
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci COMMENT ='群发任务表';;
+
+CREATE TABLE IF NOT EXISTS `broadcast_recipient`
+(
+    `id`         int unsigned     NOT NULL AUTO_INCREMENT,
+    `job_id`     int unsigned     NOT NULL COMMENT '任务ID',
+    `talk_mode`  tinyint unsigned NOT NULL COMMENT '对话类型[1:私信;2:群聊;]',
+    `to_from_id` int unsigned     NOT NULL COMMENT '用户ID或群ID',
+    `msg_id`     varchar(64)      NOT NULL COMMENT '消息ID',
+    `status`     tinyint unsigned NOT NULL DEFAULT '1' COMMENT '发送状态[1:待发送;2:发送中;3:已发送;4:发送失败;5:已取消;]',
+    `error`      varchar(255)     NOT NULL DEFAULT '' COMMENT '失败原因',
+    `created_at` datetime         NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `updated_at` datetime         NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_job_id_talk_mode_to_from_id` (`job_id`, `talk_mode`, `to_from_id`) USING BTREE,
+    KEY `idx_job_id_status` (`job_id`, `status`) USING BTREE
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci COMMENT ='群发任务接收者表';;
+
 CREATE TABLE IF NOT EXISTS `contact`
 (
     `id`         int unsigned     NOT NULL AUTO_INCREMENT COMMENT '关系ID',
@@ -215,19 +256,27 @@ CREATE TABLE IF NOT EXISTS `file_upload`
 
 CREATE TABLE IF NOT EXISTS `group`
 (
-    `id`         int unsigned      NOT NULL AUTO_INCREMENT COMMENT '群ID',
-    `type`       tinyint unsigned  NOT NULL DEFAULT '1' COMMENT '群类型[1:普通群;2:企业群;]',
-    `name`       varchar(64)       NOT NULL DEFAULT '' COMMENT '群名称',
-    `profile`    varchar(128)      NOT NULL DEFAULT '' COMMENT '群介绍',
-    `avatar`     varchar(255)      NOT NULL DEFAULT '' COMMENT '群头像',
-    `max_num`    smallint unsigned NOT NULL DEFAULT '200' COMMENT '最大群成员数量',
-    `is_overt`   tinyint unsigned  NOT NULL DEFAULT '2' COMMENT '是否公开可见[1:是;2:否;]',
-    `is_mute`    tinyint unsigned  NOT NULL DEFAULT '2' COMMENT '是否全员禁言 [1:是;2:否;] 提示:不包含群主或管理员',
-    `is_dismiss` tinyint unsigned  NOT NULL DEFAULT '2' COMMENT '是否已解散[1:是;2:否;]',
-    `creator_id` int unsigned      NOT NULL COMMENT '创建者ID(群主ID)',
-    `created_at` datetime          NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
-    `updated_at` datetime          NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    `id`             int unsigned      NOT NULL AUTO_INCREMENT COMMENT '群ID',
+    `type`           tinyint unsigned  NOT NULL DEFAULT '1' COMMENT '群类型[1:普通群;2:企业群;]',
+    `name`           varchar(64)       NOT NULL DEFAULT '' COMMENT '群名称',
+    `profile`        varchar(128)      NOT NULL DEFAULT '' COMMENT '群介绍',
+    `avatar`         varchar(255)      NOT NULL DEFAULT '' COMMENT '群头像',
+    `max_num`        smallint unsigned NOT NULL DEFAULT '500' COMMENT '最大群成员数量',
+    `is_overt`       tinyint unsigned  NOT NULL DEFAULT '2' COMMENT '是否公开可见[1:是;2:否;]',
+    `is_mute`        tinyint unsigned  NOT NULL DEFAULT '2' COMMENT '是否全员禁言 [1:是;2:否;] 提示:不包含群主或管理员',
+    `mute_expire_at` datetime                   DEFAULT NULL COMMENT '全员禁言截止时间，为空表示永久禁言',
+    `mute_reason`    varchar(255)      NOT NULL DEFAULT '' COMMENT '全员禁言原因',
+    `permission_setting` varchar(512) NOT NULL DEFAULT '' COMMENT '群权限开放范围设置(JSON)，为空时使用默认范围',
+    `join_mode`      tinyint unsigned  NOT NULL DEFAULT '2' COMMENT '入群方式[1:自由加入;2:需审核;3:仅限邀请;4:回答问题;]',
+    `join_question`  varchar(255)      NOT NULL DEFAULT '' COMMENT '入群问题',
+    `join_answer`    varchar(255)      NOT NULL DEFAULT '' COMMENT '入群问题答案',
+    `anti_spam_setting` varchar(255)   NOT NULL DEFAULT '' COMMENT '防刷屏设置(JSON)，为空时不限制',
+    `is_dismiss`     tinyint unsigned  NOT NULL DEFAULT '2' COMMENT '是否已解散[1:是;2:否;]',
+    `creator_id`     int unsigned      NOT NULL COMMENT '创建者ID(群主ID)',
+    `created_at`     datetime          NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `updated_at`     datetime          NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
     PRIMARY KEY (`id`),
+    KEY `idx_mute_expire_at` (`mute_expire_at`) USING BTREE,
     KEY `idx_created_at` (`created_at`) USING BTREE,
     KEY `idx_updated_at` (`updated_at`) USING BTREE
 ) ENGINE = InnoDB
@@ -253,21 +302,44 @@ CREATE TABLE IF NOT EXISTS `group_apply`
   COLLATE = utf8mb4_general_ci COMMENT ='入群申请';;
 
 
+CREATE TABLE IF NOT EXISTS `group_invite_link`
+(
+    `id`          int unsigned     NOT NULL AUTO_INCREMENT COMMENT '自增ID',
+    `group_id`    int unsigned     NOT NULL COMMENT '群组ID',
+    `token`       varchar(64)      NOT NULL COMMENT '邀请链接标识',
+    `creator_id`  int unsigned     NOT NULL COMMENT '创建者ID',
+    `max_usage`   int unsigned     NOT NULL DEFAULT '0' COMMENT '最大使用次数，为 0 时不限制',
+    `usage_count` int unsigned     NOT NULL DEFAULT '0' COMMENT '已使用次数',
+    `status`      tinyint unsigned NOT NULL DEFAULT '1' COMMENT '状态[1:有效;2:已撤销;]',
+    `expire_at`   datetime                  DEFAULT NULL COMMENT '过期时间，为空表示永久有效',
+    `created_at`  datetime         NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `updated_at`  datetime         NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_token` (`token`) USING BTREE,
+    KEY `idx_group_id` (`group_id`) USING BTREE
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci COMMENT ='群邀请链接';;
+
 CREATE TABLE IF NOT EXISTS `group_member`
 (
-    `id`         int unsigned     NOT NULL AUTO_INCREMENT COMMENT '自增ID',
-    `group_id`   int unsigned     NOT NULL COMMENT '群组ID',
-    `user_id`    int unsigned     NOT NULL COMMENT '用户ID',
-    `leader`     tinyint unsigned NOT NULL DEFAULT '3' COMMENT '成员属性[1:群主;1:管理员;3:普通成员]',
-    `user_card`  varchar(64)      NOT NULL DEFAULT '' COMMENT '群名片',
-    `is_quit`    tinyint unsigned NOT NULL DEFAULT '2' COMMENT '是否退群[1:是;2:否;]',
-    `is_mute`    tinyint unsigned NOT NULL DEFAULT '2' COMMENT '是否禁言[1:是;2:否;]',
-    `join_time`  datetime                  DEFAULT NULL COMMENT '入群时间',
-    `created_at` datetime         NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
-    `updated_at` datetime         NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    `id`             int unsigned     NOT NULL AUTO_INCREMENT COMMENT '自增ID',
+    `group_id`       int unsigned     NOT NULL COMMENT '群组ID',
+    `user_id`        int unsigned     NOT NULL COMMENT '用户ID',
+    `leader`         tinyint unsigned NOT NULL DEFAULT '3' COMMENT '成员属性[1:群主;1:管理员;3:普通成员]',
+    `user_card`      varchar(64)      NOT NULL DEFAULT '' COMMENT '群名片',
+    `is_quit`        tinyint unsigned NOT NULL DEFAULT '2' COMMENT '是否退群[1:是;2:否;]',
+    `is_mute`        tinyint unsigned NOT NULL DEFAULT '2' COMMENT '是否禁言[1:是;2:否;]',
+    `mute_expire_at` datetime                  DEFAULT NULL COMMENT '禁言截止时间，为空表示永久禁言',
+    `mute_reason`    varchar(255)     NOT NULL DEFAULT '' COMMENT '禁言原因',
+    `permissions`    varchar(255)     NOT NULL DEFAULT '' COMMENT '管理员权限项(JSON)，为空时拥有全部权限',
+    `join_time`      datetime                  DEFAULT NULL COMMENT '入群时间',
+    `created_at`     datetime         NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `updated_at`     datetime         NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
     PRIMARY KEY (`id`),
     UNIQUE KEY `uk_group_id_user_id` (`group_id`, `user_id`) USING BTREE,
     KEY `idx_user_id` (`user_id`) USING BTREE,
+    KEY `idx_mute_expire_at` (`mute_expire_at`) USING BTREE,
     KEY `idx_created_at` (`created_at`) USING BTREE,
     KEY `idx_updated_at` (`updated_at`) USING BTREE
 ) ENGINE = InnoDB
@@ -281,19 +353,36 @@ CREATE TABLE IF NOT EXISTS `group_notice`
     `group_id`      int unsigned     NOT NULL COMMENT '群组ID',
     `creator_id`    int unsigned     NOT NULL COMMENT '创建者用户ID',
     `modify_id`     int              NOT NULL COMMENT '修改者ID',
+    `title`         varchar(64)      NOT NULL DEFAULT '' COMMENT '公告标题',
     `content`       longtext         NOT NULL COMMENT '公告内容',
-    `confirm_users` json                      DEFAULT NULL COMMENT '已确认成员',
-    `is_confirm`    tinyint unsigned NOT NULL DEFAULT '1' COMMENT '是否需群成员确认公告[1:是;2:否;]',
+    `confirm_users` json                      DEFAULT NULL COMMENT '已确认成员(已废弃，见 group_notice_confirm)',
+    `is_confirm`    tinyint unsigned NOT NULL DEFAULT '2' COMMENT '是否需群成员确认公告[1:是;2:否;]',
+    `is_top`        tinyint unsigned NOT NULL DEFAULT '2' COMMENT '是否置顶[1:是;2:否;]',
+    `is_delete`     tinyint unsigned NOT NULL DEFAULT '0' COMMENT '是否删除[0:否;2:是;]',
     `created_at`    datetime         NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
     `updated_at`    datetime         NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '更新时间',
-    PRIMARY KEY (`id`, `modify_id`) USING BTREE,
-    UNIQUE KEY `un_group_id` (`group_id`) USING BTREE,
+    PRIMARY KEY (`id`) USING BTREE,
+    KEY `idx_group_id` (`group_id`, `is_delete`) USING BTREE,
     KEY `idx_created_at` (`created_at`) USING BTREE,
     KEY `idx_updated_at` (`updated_at`) USING BTREE
 ) ENGINE = InnoDB
   DEFAULT CHARSET = utf8mb4
   COLLATE = utf8mb4_general_ci COMMENT ='群组公告表';;
 
+CREATE TABLE IF NOT EXISTS `group_notice_confirm`
+(
+    `id`         int unsigned NOT NULL AUTO_INCREMENT COMMENT '自增ID',
+    `notice_id`  int unsigned NOT NULL COMMENT '公告ID',
+    `group_id`   int unsigned NOT NULL COMMENT '群组ID',
+    `user_id`    int unsigned NOT NULL COMMENT '确认用户ID',
+    `created_at` datetime     NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '确认时间',
+    PRIMARY KEY (`id`) USING BTREE,
+    UNIQUE KEY `uk_notice_user` (`notice_id`, `user_id`) USING BTREE,
+    KEY `idx_group_id` (`group_id`) USING BTREE
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci COMMENT ='群公告确认记录表';;
+
 
 CREATE TABLE IF NOT EXISTS `group_vote`
 (
@@ -307,12 +396,15 @@ CREATE TABLE IF NOT EXISTS `group_vote`
     `answered_num`  int unsigned NOT NULL DEFAULT '0' COMMENT '已答人数',
     `is_anonymous`  int unsigned NOT NULL DEFAULT '2' COMMENT '匿名投票[1:是;2:否;]',
     `status`        int unsigned NOT NULL DEFAULT '1' COMMENT '投票状态[1:投票中;2:已完成;]',
+    `result_visible` int unsigned NOT NULL DEFAULT '1' COMMENT '结果可见范围[1:始终可见;2:投票后或结束后可见;]',
+    `deadline`      datetime              DEFAULT NULL COMMENT '截止时间，为空表示不限时',
     `created_at`    datetime     NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
     `updated_at`    datetime     NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
     PRIMARY KEY (`id`),
     KEY `idx_created_at` (`created_at`) USING BTREE,
     KEY `idx_updated_at` (`updated_at`) USING BTREE,
-    KEY `idx_groupid` (`group_id`) USING BTREE
+    KEY `idx_groupid` (`group_id`) USING BTREE,
+    KEY `idx_status_deadline` (`status`, `deadline`) USING BTREE
 ) ENGINE = InnoDB
   DEFAULT CHARSET = utf8mb4
   COLLATE = utf8mb4_general_ci COMMENT ='群投票表';;
@@ -333,6 +425,32 @@ CREATE TABLE IF NOT EXISTS `group_vote_answer`
   COLLATE = utf8mb4_general_ci COMMENT ='投票详情统计表';;
 
 
+CREATE TABLE IF NOT EXISTS `moderation_record`
+(
+    `id`            int unsigned     NOT NULL AUTO_INCREMENT,
+    `scene`         varchar(32)      NOT NULL COMMENT '内容场景[message:消息;nickname:昵称;group_name:群名称;group_notice:群公告;article_title:笔记标题;]',
+    `user_id`       int unsigned     NOT NULL COMMENT '提交内容的用户ID',
+    `target_id`     int unsigned     NOT NULL DEFAULT '0' COMMENT '内容所属对象ID',
+    `talk_mode`     tinyint unsigned NOT NULL DEFAULT '0' COMMENT '对话类型[1:私信;2:群聊;]',
+    `to_from_id`    int unsigned     NOT NULL DEFAULT '0' COMMENT '接收者ID或群ID',
+    `msg_id`        varchar(64)      NOT NULL DEFAULT '' COMMENT '消息ID',
+    `content`       text             NOT NULL COMMENT '原始内容',
+    `mask_content`  text             NOT NULL COMMENT '屏蔽敏感词后的内容',
+    `words`         varchar(512)     NOT NULL DEFAULT '' COMMENT '命中的敏感词',
+    `label`         varchar(64)      NOT NULL DEFAULT '' COMMENT '外部审核标签',
+    `status`        tinyint unsigned NOT NULL DEFAULT '1' COMMENT '审核状态[1:待审核;2:通过;3:驳回;]',
+    `reviewer_id`   int unsigned     NOT NULL DEFAULT '0' COMMENT '审核管理员ID',
+    `review_remark` varchar(255)     NOT NULL DEFAULT '' COMMENT '审核备注',
+    `reviewed_at`   datetime                  DEFAULT NULL COMMENT '审核时间',
+    `created_at`    datetime         NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `updated_at`    datetime         NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    PRIMARY KEY (`id`),
+    KEY `idx_status_id` (`status`, `id`) USING BTREE,
+    KEY `idx_user_id` (`user_id`) USING BTREE
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci COMMENT ='内容审核记录表';;
+
 CREATE TABLE IF NOT EXISTS `organize`
 (
     `id`          int unsigned NOT NULL AUTO_INCREMENT COMMENT '自增ID',
@@ -390,6 +508,48 @@ CREATE TABLE IF NOT EXISTS `organize_position`
   COLLATE = utf8mb4_general_ci COMMENT ='岗位信息表';;
 
 
+CREATE TABLE IF NOT EXISTS `report`
+(
+    `id`          int unsigned     NOT NULL AUTO_INCREMENT,
+    `user_id`     int unsigned     NOT NULL COMMENT '举报人ID',
+    `target_type` tinyint unsigned NOT NULL COMMENT '举报对象[1:消息;2:用户;3:群聊;]',
+    `target_id`   int unsigned     NOT NULL COMMENT '被举报的用户ID或群ID',
+    `talk_mode`   tinyint unsigned NOT NULL DEFAULT '0' COMMENT '证据消息所在的对话类型[1:私信;2:群聊;]',
+    `to_from_id`  int unsigned     NOT NULL DEFAULT '0' COMMENT '证据消息所在的会话',
+    `msg_ids`     varchar(1024)    NOT NULL DEFAULT '[]' COMMENT '证据消息ID',
+    `reason`      tinyint unsigned NOT NULL COMMENT '举报原因[1:垃圾广告;2:诈骗;3:色情;4:暴力违法;5:骚扰辱骂;6:其它;]',
+    `description` varchar(500)     NOT NULL DEFAULT '' COMMENT '举报说明',
+    `images`      varchar(2048)    NOT NULL DEFAULT '[]' COMMENT '举报截图',
+    `evidence`    mediumtext       NOT NULL COMMENT '证据消息快照',
+    `status`      tinyint unsigned NOT NULL DEFAULT '1' COMMENT '处理状态[1:待处理;2:已处理;3:已忽略;]',
+    `handler_id`  int unsigned     NOT NULL DEFAULT '0' COMMENT '处理管理员ID',
+    `handled_at`  datetime                  DEFAULT NULL COMMENT '处理时间',
+    `created_at`  datetime         NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `updated_at`  datetime         NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    PRIMARY KEY (`id`),
+    KEY `idx_status_id` (`status`, `id`) USING BTREE,
+    KEY `idx_target_type_target_id` (`target_type`, `target_id`) USING BTREE,
+    KEY `idx_user_id` (`user_id`) USING BTREE
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci COMMENT ='举报记录表';;
+
+CREATE TABLE IF NOT EXISTS `report_audit`
+(
+    `id`         int unsigned NOT NULL AUTO_INCREMENT,
+    `report_id`  int unsigned NOT NULL COMMENT '举报ID',
+    `admin_id`   int unsigned NOT NULL COMMENT '处理管理员ID',
+    `action`     varchar(32)  NOT NULL COMMENT '处理动作',
+    `remark`     varchar(255) NOT NULL DEFAULT '' COMMENT '处理备注',
+    `detail`     varchar(512) NOT NULL DEFAULT '' COMMENT '处理结果',
+    `created_at` datetime     NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '处理时间',
+    PRIMARY KEY (`id`),
+    KEY `idx_report_id` (`report_id`) USING BTREE,
+    KEY `idx_admin_id` (`admin_id`) USING BTREE
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci COMMENT ='举报处理审计表';;
+
 CREATE TABLE IF NOT EXISTS `robot`
 (
     `id`         int unsigned     NOT NULL AUTO_INCREMENT COMMENT '机器人ID',
@@ -411,6 +571,83 @@ CREATE TABLE IF NOT EXISTS `robot`
   DEFAULT CHARSET = utf8mb4
   COLLATE = utf8mb4_general_ci COMMENT ='聊天机器人表';;
 
+CREATE TABLE IF NOT EXISTS `sequence`
+(
+    `id`         int unsigned     NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+    `seq_type`   tinyint unsigned NOT NULL COMMENT '业务类型[1:用户;2:群;]',
+    `source_id`  int unsigned     NOT NULL COMMENT '用户ID或群ID',
+    `cur_seq`    bigint unsigned  NOT NULL DEFAULT '0' COMMENT '当前号段起始ID',
+    `max_seq`    bigint unsigned  NOT NULL DEFAULT '0' COMMENT '已分配的最大ID',
+    `step`       int unsigned     NOT NULL DEFAULT '1000' COMMENT '号段步长',
+    `created_at` datetime         NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `updated_at` datetime         NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_seq_type_source_id` (`seq_type`, `source_id`) USING BTREE
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci COMMENT ='消息时序号段表';;
+
+CREATE TABLE IF NOT EXISTS `sensitive_word`
+(
+    `id`         int unsigned     NOT NULL AUTO_INCREMENT,
+    `word`       varchar(64)      NOT NULL COMMENT '敏感词',
+    `action`     tinyint unsigned NOT NULL DEFAULT '1' COMMENT '处理方式[1:替换;2:审核;3:拒绝;]',
+    `status`     tinyint unsigned NOT NULL DEFAULT '1' COMMENT '状态[1:启用;2:停用;]',
+    `created_at` datetime         NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `updated_at` datetime         NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_word` (`word`) USING BTREE
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci COMMENT ='敏感词表';;
+
+
+CREATE TABLE IF NOT EXISTS `talk_export`
+(
+    `id`             int unsigned     NOT NULL AUTO_INCREMENT,
+    `requester_type` tinyint unsigned NOT NULL COMMENT '申请人类型[1:用户;2:管理员;]',
+    `requester_id`   int unsigned     NOT NULL COMMENT '申请人ID',
+    `user_id`        int unsigned     NOT NULL COMMENT '导出的用户ID',
+    `talk_mode`      tinyint unsigned NOT NULL DEFAULT '0' COMMENT '对话类型[0:全部;1:私信;2:群聊;]',
+    `to_from_id`     int unsigned     NOT NULL DEFAULT '0' COMMENT '好友ID或群ID，为0时导出全部会话',
+    `start_time`     datetime         NOT NULL COMMENT '开始时间',
+    `end_time`       datetime         NOT NULL COMMENT '结束时间',
+    `status`         tinyint unsigned NOT NULL DEFAULT '1' COMMENT '导出状态[1:待导出;2:导出中;3:已完成;4:导出失败;]',
+    `record_count`   int unsigned     NOT NULL DEFAULT '0' COMMENT '导出的消息数',
+    `file_path`      varchar(255)     NOT NULL DEFAULT '' COMMENT '私有桶中的文件路径',
+    `file_size`      bigint unsigned  NOT NULL DEFAULT '0' COMMENT '文件大小',
+    `error`          varchar(255)     NOT NULL DEFAULT '' COMMENT '失败原因',
+    `expired_at`     datetime                  DEFAULT NULL COMMENT '文件过期时间',
+    `created_at`     datetime         NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `updated_at`     datetime         NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    PRIMARY KEY (`id`),
+    KEY `idx_requester_type_requester_id` (`requester_type`, `requester_id`) USING BTREE,
+    KEY `idx_status_expired_at` (`status`, `expired_at`) USING BTREE
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci COMMENT ='聊天记录导出任务表';;
+
+CREATE TABLE IF NOT EXISTS `talk_favorite`
+(
+    `id`         int unsigned     NOT NULL AUTO_INCREMENT,
+    `user_id`    int unsigned     NOT NULL COMMENT '收藏的用户ID',
+    `msg_type`   int unsigned     NOT NULL COMMENT '消息类型',
+    `extra`      json             NOT NULL COMMENT '消息内容快照',
+    `content`    text             NOT NULL COMMENT '可检索文本',
+    `tags`       varchar(255)     NOT NULL DEFAULT '' COMMENT '标签，多个以英文逗号分隔',
+    `talk_mode`  tinyint unsigned NOT NULL COMMENT '来源对话类型[1:私信;2:群聊;]',
+    `to_from_id` int unsigned     NOT NULL COMMENT '来源好友ID或群ID',
+    `msg_id`     varchar(64)      NOT NULL COMMENT '来源消息ID',
+    `from_id`    int unsigned     NOT NULL DEFAULT '0' COMMENT '来源消息发送者ID',
+    `send_time`  datetime         NOT NULL COMMENT '来源消息发送时间',
+    `created_at` datetime         NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '收藏时间',
+    `updated_at` datetime         NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_user_id_msg_id` (`user_id`, `msg_id`) USING BTREE,
+    KEY `idx_user_id_msg_type` (`user_id`, `msg_type`) USING BTREE
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci COMMENT ='消息收藏表';;
 
 CREATE TABLE IF NOT EXISTS `talk_group_message`
 (
@@ -423,14 +660,17 @@ CREATE TABLE IF NOT EXISTS `talk_group_message`
     `is_revoked` tinyint unsigned NOT NULL DEFAULT '2' COMMENT '是否撤回[1:是;2:否;]',
     `extra`      json             NOT NULL COMMENT '消息扩展字段',
     `quote`      json             NOT NULL COMMENT '引用消息',
+    `root_msg_id` varchar(64)     NOT NULL DEFAULT '' COMMENT '话题根消息ID',
     `send_time`  datetime         NOT NULL COMMENT '发送时间',
     `created_at` datetime         NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
     `updated_at` datetime         NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
     PRIMARY KEY (`id`),
     UNIQUE KEY `uk_group_id_sequence` (`group_id`, `sequence`) USING BTREE,
     UNIQUE KEY `uk_msgid` (`msg_id`),
+    KEY `idx_root_msg_id_sequence` (`root_msg_id`, `sequence`) USING BTREE,
     KEY `idx_updated_at` (`updated_at`) USING BTREE,
-    KEY `idx_created_at` (`created_at`) USING BTREE
+    KEY `idx_created_at` (`created_at`) USING BTREE,
+    KEY `idx_send_time` (`send_time`) USING BTREE
 ) ENGINE = InnoDB
   DEFAULT CHARSET = utf8mb4
   COLLATE = utf8mb4_general_ci COMMENT ='群聊消息记录表';;
@@ -449,6 +689,165 @@ CREATE TABLE IF NOT EXISTS `talk_group_message_del`
   DEFAULT CHARSET = utf8mb4
   COLLATE = utf8mb4_general_ci COMMENT ='群聊消息记录表-删除记录关系表';;
 
+CREATE TABLE IF NOT EXISTS `talk_group_thread`
+(
+    `id`                 int unsigned NOT NULL AUTO_INCREMENT COMMENT '话题ID',
+    `group_id`           int unsigned NOT NULL COMMENT '群组ID',
+    `root_msg_id`        varchar(64)  NOT NULL COMMENT '根消息ID',
+    `reply_count`        int unsigned NOT NULL DEFAULT '0' COMMENT '回复总数',
+    `last_reply_msg_id`  varchar(64)  NOT NULL DEFAULT '' COMMENT '最后一条回复消息ID',
+    `last_reply_user_id` int unsigned NOT NULL DEFAULT '0' COMMENT '最后回复的用户ID',
+    `last_reply_at`      datetime     NOT NULL COMMENT '最后回复时间',
+    `created_at`         datetime     NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `updated_at`         datetime     NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_root_msg_id` (`root_msg_id`) USING BTREE,
+    KEY `idx_group_id` (`group_id`) USING BTREE
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci COMMENT ='群消息话题表';;
+
+CREATE TABLE IF NOT EXISTS `talk_group_thread_member`
+(
+    `id`          int unsigned NOT NULL AUTO_INCREMENT,
+    `group_id`    int unsigned NOT NULL COMMENT '群组ID',
+    `root_msg_id` varchar(64)  NOT NULL COMMENT '根消息ID',
+    `user_id`     int unsigned NOT NULL COMMENT '用户ID',
+    `created_at`  datetime     NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_root_msg_id_user_id` (`root_msg_id`, `user_id`) USING BTREE
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci COMMENT ='群消息话题参与者表';;
+
+CREATE TABLE IF NOT EXISTS `talk_message_archive`
+(
+    `id`            int unsigned     NOT NULL AUTO_INCREMENT,
+    `talk_mode`     tinyint unsigned NOT NULL COMMENT '对话类型[1:私信;2:群聊;]',
+    `month`         int unsigned     NOT NULL COMMENT '归档月份，格式 200601',
+    `archive_table` varchar(64)      NOT NULL COMMENT '归档表名',
+    `record_count`  int unsigned     NOT NULL DEFAULT '0' COMMENT '归档的记录数',
+    `created_at`    datetime         NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `updated_at`    datetime         NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_talk_mode_month` (`talk_mode`, `month`) USING BTREE
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci COMMENT ='聊天记录归档表登记';;
+
+CREATE TABLE IF NOT EXISTS `talk_message_pin`
+(
+    `id`         int unsigned     NOT NULL AUTO_INCREMENT,
+    `talk_mode`  tinyint unsigned NOT NULL COMMENT '对话类型[1:私信;2:群聊;]',
+    `user_id`    int unsigned     NOT NULL DEFAULT '0' COMMENT '私聊用户ID(较小的一方)，群聊为0',
+    `to_from_id` int unsigned     NOT NULL COMMENT '群ID或私聊用户ID(较大的一方)',
+    `msg_id`     varchar(64)      NOT NULL COMMENT '消息ID(私聊为原消息ID)',
+    `pinned_by`  int unsigned     NOT NULL COMMENT '置顶操作人',
+    `created_at` datetime         NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '置顶时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_talk_msg_id` (`talk_mode`, `to_from_id`, `user_id`, `msg_id`) USING BTREE
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci COMMENT ='会话置顶消息表';;
+
+CREATE TABLE IF NOT EXISTS `talk_scheduled_message`
+(
+    `id`          int unsigned     NOT NULL AUTO_INCREMENT,
+    `user_id`     int unsigned     NOT NULL COMMENT '发送者ID',
+    `talk_mode`   tinyint unsigned NOT NULL COMMENT '对话类型[1:私信;2:群聊;]',
+    `to_from_id`  int unsigned     NOT NULL COMMENT '接收者ID(好友ID或群ID)',
+    `msg_type`    varchar(32)      NOT NULL COMMENT '消息类型(与发送接口的 type 一致)',
+    `payload`     json             NOT NULL COMMENT '发送参数',
+    `send_at`     datetime         NOT NULL COMMENT '计划发送时间',
+    `status`      tinyint unsigned NOT NULL DEFAULT '1' COMMENT '状态[1:待发送;2:发送中;3:已发送;4:已取消;5:发送失败;]',
+    `fail_reason` varchar(255)     NOT NULL DEFAULT '' COMMENT '失败原因',
+    `msg_id`      varchar(64)      NOT NULL DEFAULT '' COMMENT '发送时生成的消息ID',
+    `created_at`  datetime         NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `updated_at`  datetime         NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '更新时间',
+    PRIMARY KEY (`id`),
+    KEY `idx_status_send_at` (`status`, `send_at`) USING BTREE,
+    KEY `idx_status_updated_at` (`status`, `updated_at`) USING BTREE,
+    KEY `idx_user_id_status` (`user_id`, `status`) USING BTREE
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci COMMENT ='定时消息表';;
+
+CREATE TABLE IF NOT EXISTS `talk_expire_setting`
+(
+    `id`         int unsigned     NOT NULL AUTO_INCREMENT,
+    `talk_mode`  tinyint unsigned NOT NULL COMMENT '对话类型[1:私信;2:群聊;]',
+    `user_id`    int unsigned     NOT NULL DEFAULT '0' COMMENT '私聊用户ID(较小的一方)，群聊为0',
+    `to_from_id` int unsigned     NOT NULL COMMENT '群ID或私聊用户ID(较大的一方)',
+    `mode`       tinyint unsigned NOT NULL COMMENT '计时方式[1:发送后;2:首次阅读后;]',
+    `ttl`        int unsigned     NOT NULL COMMENT '有效时长(秒)',
+    `updated_by` int unsigned     NOT NULL DEFAULT '0' COMMENT '最后设置人',
+    `created_at` datetime         NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `updated_at` datetime         NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '更新时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_talk` (`talk_mode`, `to_from_id`, `user_id`) USING BTREE
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci COMMENT ='会话消息过期设置表';;
+
+CREATE TABLE IF NOT EXISTS `talk_message_expire`
+(
+    `id`         int unsigned     NOT NULL AUTO_INCREMENT,
+    `talk_mode`  tinyint unsigned NOT NULL COMMENT '对话类型[1:私信;2:群聊;]',
+    `msg_id`     varchar(64)      NOT NULL COMMENT '消息ID',
+    `org_msg_id` varchar(64)      NOT NULL COMMENT '原消息ID(私聊双方相同，群聊同msg_id)',
+    `user_id`    int unsigned     NOT NULL DEFAULT '0' COMMENT '私聊消息所属用户ID，群聊为0',
+    `to_from_id` int unsigned     NOT NULL COMMENT '好友ID或群ID',
+    `from_id`    int unsigned     NOT NULL COMMENT '消息发送者',
+    `mode`       tinyint unsigned NOT NULL COMMENT '计时方式[1:发送后;2:首次阅读后;]',
+    `ttl`        int unsigned     NOT NULL COMMENT '有效时长(秒)',
+    `expire_at`  bigint unsigned  NOT NULL DEFAULT '0' COMMENT '过期时间戳(秒)，0表示等待首次阅读',
+    `created_at` datetime         NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_msg_id` (`msg_id`) USING BTREE,
+    KEY `idx_org_msg_id` (`org_msg_id`) USING BTREE,
+    KEY `idx_talk` (`talk_mode`, `to_from_id`, `user_id`) USING BTREE,
+    KEY `idx_expire_at` (`expire_at`) USING BTREE
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci COMMENT ='消息过期记录表';;
+
+CREATE TABLE IF NOT EXISTS `talk_message_outbox`
+(
+    `id`            int unsigned     NOT NULL AUTO_INCREMENT,
+    `talk_mode`     tinyint unsigned NOT NULL COMMENT '对话类型[1:私信;2:群聊;]',
+    `msg_id`        varchar(64)      NOT NULL COMMENT '消息ID(私聊为各自信箱中的消息ID)',
+    `status`        tinyint unsigned NOT NULL DEFAULT '1' COMMENT '投递状态[1:待投递;2:已投递;3:投递失败;]',
+    `attempts`      int unsigned     NOT NULL DEFAULT '0' COMMENT '投递次数',
+    `next_retry_at` datetime         NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '下次投递时间',
+    `last_error`    varchar(255)     NOT NULL DEFAULT '' COMMENT '最后一次投递失败原因',
+    `created_at`    datetime         NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `updated_at`    datetime         NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '更新时间',
+    PRIMARY KEY (`id`),
+    KEY `idx_status_next_retry_at` (`status`, `next_retry_at`) USING BTREE,
+    KEY `idx_status_updated_at` (`status`, `updated_at`) USING BTREE
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci COMMENT ='消息投递发件箱';;
+
+CREATE TABLE IF NOT EXISTS `talk_sync_event`
+(
+    `id`         bigint unsigned  NOT NULL AUTO_INCREMENT,
+    `user_id`    int unsigned     NOT NULL DEFAULT '0' COMMENT '用户ID，群级变更为0',
+    `group_id`   int unsigned     NOT NULL DEFAULT '0' COMMENT '群ID，用户级变更为0',
+    `sequence`   bigint unsigned  NOT NULL DEFAULT '0' COMMENT '用户时序ID，群级变更为0',
+    `event`      varchar(32)      NOT NULL COMMENT '变更类型',
+    `talk_mode`  tinyint unsigned NOT NULL COMMENT '对话类型[1:私信;2:群聊;]',
+    `to_from_id` int unsigned     NOT NULL COMMENT '好友ID或群ID',
+    `payload`    varchar(2048)    NOT NULL DEFAULT '{}' COMMENT '变更内容',
+    `created_at` datetime         NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    PRIMARY KEY (`id`),
+    KEY `idx_user_id_sequence` (`user_id`, `sequence`) USING BTREE,
+    KEY `idx_group_id_id` (`group_id`, `id`) USING BTREE,
+    KEY `idx_created_at` (`created_at`) USING BTREE
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci COMMENT ='多端同步变更记录表';;
+
 CREATE TABLE IF NOT EXISTS `talk_session`
 (
     `id`         int unsigned     NOT NULL AUTO_INCREMENT COMMENT '聊天列表ID',
@@ -489,9 +888,11 @@ CREATE TABLE IF NOT EXISTS `talk_user_message`
     `updated_at` datetime         NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
     PRIMARY KEY (`id`),
     UNIQUE KEY `uk_user_id_friend_id_sequence` (`user_id`, `to_from_id`, `sequence`) USING BTREE,
+    KEY `idx_user_id_sequence` (`user_id`, `sequence`) USING BTREE,
     UNIQUE KEY `uk_msgid` (`msg_id`) USING BTREE,
     KEY `idx_created_at` (`created_at`) USING BTREE,
     KEY `idx_updated_at` (`updated_at`) USING BTREE,
+    KEY `idx_send_time` (`send_time`) USING BTREE,
     KEY `idx_org_msg_id` (`org_msg_id`)
 ) ENGINE = InnoDB
   DEFAULT CHARSET = utf8mb4
@@ -531,6 +932,19 @@ CREATE TABLE IF NOT EXISTS `users_emoticon`
   DEFAULT CHARSET = utf8mb4
   COLLATE = utf8mb4_general_ci COMMENT ='用户收藏表情包';;
 
+CREATE TABLE IF NOT EXISTS `user_block`
+(
+    `id`            int unsigned NOT NULL AUTO_INCREMENT,
+    `user_id`       int unsigned NOT NULL COMMENT '用户ID',
+    `block_user_id` int unsigned NOT NULL COMMENT '被拉黑的用户ID',
+    `created_at`    datetime     NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '拉黑时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_user_id_block_user_id` (`user_id`, `block_user_id`) USING BTREE,
+    KEY `idx_block_user_id` (`block_user_id`) USING BTREE
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci COMMENT ='用户黑名单';;
+
 
 
 CREATE TABLE IF NOT EXISTS `article_history`
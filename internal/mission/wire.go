@@ -25,3 +25,7 @@ var TempProviderSet = wire.NewSet(
 	wire.Struct(new(temp.TestCommand), "*"),
 	wire.Struct(new(TempProvider), "*"),
 )
+
+var ImportProviderSet = wire.NewSet(
+	wire.Struct(new(ImportProvider), "*"),
+)
@@ -104,14 +104,24 @@ func (c *Consumer) start(ctx context.Context, handle IConsumerHandle) {
 	consumer.AddConcurrentHandlers(nsq.HandlerFunc(func(message *nsq.Message) error {
 		message.DisableAutoResponse()
 
+		// 耗时较长的任务定时续期，避免处理期间消息超时被重新投递
 		if handle.Touch() {
-			timer := time.NewTimer(time.Second * 10)
+			done := make(chan struct{})
 			go func() {
-				<-timer.C
-				message.Touch()
+				ticker := time.NewTicker(time.Second * 10)
+				defer ticker.Stop()
+
+				for {
+					select {
+					case <-ticker.C:
+						message.Touch()
+					case <-done:
+						return
+					}
+				}
 			}()
 
-			defer timer.Stop()
+			defer close(done)
 		}
 
 		err = handle.Do(context.Background(), message.Body, message.Attempts)
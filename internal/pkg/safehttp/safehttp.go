@@ -0,0 +1,104 @@
+package safehttp
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+var ErrForbiddenAddr = errors.New("safehttp: forbidden address")
+
+// 非公网地址段，net/netip 未提供判断方法的部分
+var forbiddenPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),       // 本网络
+	netip.MustParsePrefix("100.64.0.0/10"),   // 运营商级 NAT
+	netip.MustParsePrefix("192.0.0.0/24"),    // IETF 协议分配
+	netip.MustParsePrefix("198.18.0.0/15"),   // 基准测试
+	netip.MustParsePrefix("240.0.0.0/4"),     // 保留地址及广播
+	netip.MustParsePrefix("64:ff9b::/96"),    // NAT64，可映射到内网 IPv4
+	netip.MustParsePrefix("64:ff9b:1::/48"),  // 本地 NAT64
+	netip.MustParsePrefix("2002::/16"),       // 6to4，可映射到内网 IPv4
+	netip.MustParsePrefix("fec0::/10"),       // 已废弃的站点本地地址
+	netip.MustParsePrefix("100::/64"),        // 丢弃前缀
+	netip.MustParsePrefix("2001:db8::/32"),   // 文档示例
+	netip.MustParsePrefix("192.0.2.0/24"),    // 文档示例
+	netip.MustParsePrefix("198.51.100.0/24"), // 文档示例
+	netip.MustParsePrefix("203.0.113.0/24"),  // 文档示例
+}
+
+type Options struct {
+	Timeout      time.Duration // 请求总超时，包含重定向
+	MaxRedirects int           // 最多跟随的重定向次数，小于 0 时不跟随
+	AllowPrivate bool          // 允许访问内网地址，仅用于测试
+}
+
+// NewClient 创建访问外部地址的 HTTP 客户端
+// 每次建立连接时校验实际连接的 IP，避免通过 DNS 解析或重定向访问内网地址
+func NewClient(opts Options) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: opts.Timeout,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			if opts.AllowPrivate {
+				return nil
+			}
+
+			addr, err := netip.ParseAddrPort(address)
+			if err != nil || !IsPublicAddr(addr.Addr()) {
+				return ErrForbiddenAddr
+			}
+
+			return nil
+		},
+	}
+
+	transport := &http.Transport{
+		Proxy:                 nil, // 不使用环境变量中的代理，否则无法校验目标地址
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   opts.Timeout,
+		ResponseHeaderTimeout: opts.Timeout,
+		MaxIdleConns:          32,
+		IdleConnTimeout:       30 * time.Second,
+	}
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   opts.Timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if opts.MaxRedirects < 0 {
+				return http.ErrUseLastResponse
+			}
+
+			if len(via) > opts.MaxRedirects {
+				return fmt.Errorf("safehttp: stopped after %d redirects", opts.MaxRedirects)
+			}
+
+			if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+				return fmt.Errorf("safehttp: unsupported scheme %q", req.URL.Scheme)
+			}
+
+			return nil
+		},
+	}
+}
+
+// IsPublicAddr 判断是否为公网地址
+func IsPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+
+	if !addr.IsValid() || addr.IsUnspecified() || addr.IsLoopback() || addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return false
+	}
+
+	for _, prefix := range forbiddenPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+
+	return true
+}
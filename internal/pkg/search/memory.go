@@ -0,0 +1,296 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var _ IEngine = (*MemoryEngine)(nil)
+
+// MemoryEngine 内嵌倒排索引，适用于单节点部署
+// 指定 path 时通过 Flush 将索引快照写入磁盘，启动时自动加载
+type MemoryEngine struct {
+	mu          sync.RWMutex
+	path        string
+	docs        map[string]*Document
+	index       map[string]map[string]struct{}
+	checkpoints map[string]int64
+}
+
+type memorySnapshot struct {
+	Docs        []*Document
+	Checkpoints map[string]int64
+}
+
+func NewMemoryEngine(path string) (*MemoryEngine, error) {
+	engine := &MemoryEngine{
+		path:        path,
+		docs:        make(map[string]*Document),
+		index:       make(map[string]map[string]struct{}),
+		checkpoints: make(map[string]int64),
+	}
+
+	if path == "" {
+		return engine, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return engine, nil
+		}
+
+		return nil, err
+	}
+
+	var snapshot memorySnapshot
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&snapshot); err != nil {
+		return nil, err
+	}
+
+	for _, doc := range snapshot.Docs {
+		engine.add(doc)
+	}
+
+	if snapshot.Checkpoints != nil {
+		engine.checkpoints = snapshot.Checkpoints
+	}
+
+	return engine, nil
+}
+
+func (m *MemoryEngine) Index(_ context.Context, docs ...*Document) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, doc := range docs {
+		if doc == nil || doc.Id == "" {
+			continue
+		}
+
+		m.remove(doc.Id)
+		m.add(doc)
+	}
+
+	return nil
+}
+
+func (m *MemoryEngine) Delete(_ context.Context, ids ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, id := range ids {
+		m.remove(id)
+	}
+
+	return nil
+}
+
+func (m *MemoryEngine) Search(_ context.Context, query *Query) ([]*Document, error) {
+	tokens := queryTokens(query.Keyword)
+	if len(tokens) == 0 {
+		return make([]*Document, 0), nil
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	// 从文档最少的词开始求交集
+	postings := make([]map[string]struct{}, 0, len(tokens))
+	for _, token := range tokens {
+		ids, ok := m.index[token]
+		if !ok {
+			return make([]*Document, 0), nil
+		}
+
+		postings = append(postings, ids)
+	}
+
+	sort.Slice(postings, func(i, j int) bool {
+		return len(postings[i]) < len(postings[j])
+	})
+
+	terms := strings.Fields(strings.ToLower(query.Keyword))
+
+	items := make([]*Document, 0)
+	for id := range postings[0] {
+		matched := true
+		for _, ids := range postings[1:] {
+			if _, ok := ids[id]; !ok {
+				matched = false
+				break
+			}
+		}
+
+		if !matched {
+			continue
+		}
+
+		doc := m.docs[id]
+		if !m.filter(doc, query, terms) {
+			continue
+		}
+
+		items = append(items, doc)
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].SendTime.Equal(items[j].SendTime) {
+			return items[i].Id > items[j].Id
+		}
+
+		return items[i].SendTime.After(items[j].SendTime)
+	})
+
+	if query.Limit > 0 && len(items) > query.Limit {
+		items = items[:query.Limit]
+	}
+
+	result := make([]*Document, 0, len(items))
+	for _, item := range items {
+		doc := *item
+		result = append(result, &doc)
+	}
+
+	return result, nil
+}
+
+func (m *MemoryEngine) Checkpoint(_ context.Context, key string) (int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.checkpoints[key], nil
+}
+
+func (m *MemoryEngine) SetCheckpoint(_ context.Context, key string, value int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.checkpoints[key] = value
+	return nil
+}
+
+func (m *MemoryEngine) Flush(_ context.Context) error {
+	if m.path == "" {
+		return nil
+	}
+
+	m.mu.RLock()
+	snapshot := memorySnapshot{
+		Docs:        make([]*Document, 0, len(m.docs)),
+		Checkpoints: make(map[string]int64, len(m.checkpoints)),
+	}
+
+	for _, doc := range m.docs {
+		snapshot.Docs = append(snapshot.Docs, doc)
+	}
+
+	for k, v := range m.checkpoints {
+		snapshot.Checkpoints[k] = v
+	}
+	m.mu.RUnlock()
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&snapshot); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(m.path), 0755); err != nil {
+		return err
+	}
+
+	// 先写临时文件再重命名，避免进程中断导致快照损坏
+	tmp := m.path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, m.path)
+}
+
+func (m *MemoryEngine) filter(doc *Document, query *Query, terms []string) bool {
+	if doc.OwnerId > 0 {
+		if doc.OwnerId != query.UserId {
+			return false
+		}
+	} else if !slices.Contains(query.GroupIds, doc.ToFromId) {
+		return false
+	}
+
+	if query.TalkMode > 0 && doc.TalkMode != query.TalkMode {
+		return false
+	}
+
+	if query.ToFromId > 0 && doc.ToFromId != query.ToFromId {
+		return false
+	}
+
+	if query.FromId > 0 && doc.FromId != query.FromId {
+		return false
+	}
+
+	if len(query.MsgTypes) > 0 && !slices.Contains(query.MsgTypes, doc.MsgType) {
+		return false
+	}
+
+	if !query.StartTime.IsZero() && doc.SendTime.Before(query.StartTime) {
+		return false
+	}
+
+	if !query.EndTime.IsZero() && doc.SendTime.After(query.EndTime) {
+		return false
+	}
+
+	if query.Cursor != "" && !doc.before(query.Cursor) {
+		return false
+	}
+
+	// 分词命中后再校验原文，排除中文二字词拼接造成的误命中
+	content := strings.ToLower(doc.Content)
+	for _, term := range terms {
+		if !strings.Contains(content, term) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (m *MemoryEngine) add(doc *Document) {
+	m.docs[doc.Id] = doc
+
+	for _, token := range Tokenize(doc.Content) {
+		ids, ok := m.index[token]
+		if !ok {
+			ids = make(map[string]struct{})
+			m.index[token] = ids
+		}
+
+		ids[doc.Id] = struct{}{}
+	}
+}
+
+func (m *MemoryEngine) remove(id string) {
+	doc, ok := m.docs[id]
+	if !ok {
+		return
+	}
+
+	for _, token := range Tokenize(doc.Content) {
+		if ids, ok := m.index[token]; ok {
+			delete(ids, id)
+			if len(ids) == 0 {
+				delete(m.index, token)
+			}
+		}
+	}
+
+	delete(m.docs, id)
+}
@@ -0,0 +1,165 @@
+package search
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestDocs() []*Document {
+	now := time.Now()
+
+	return []*Document{
+		{Id: "p1", TalkMode: 1, OwnerId: 1, ToFromId: 2, FromId: 1, MsgType: 1, Content: "明天下午开会讨论需求", Sequence: 1, SendTime: now.Add(-3 * time.Hour)},
+		{Id: "p2", TalkMode: 1, OwnerId: 2, ToFromId: 1, FromId: 1, MsgType: 1, Content: "明天下午开会讨论需求", Sequence: 1, SendTime: now.Add(-3 * time.Hour)},
+		{Id: "g1", TalkMode: 2, ToFromId: 100, FromId: 3, MsgType: 1, Content: "Deploy the release on Friday", Sequence: 1, SendTime: now.Add(-2 * time.Hour)},
+		{Id: "g2", TalkMode: 2, ToFromId: 100, FromId: 4, MsgType: 4, Content: "go func main() { deploy() }", Sequence: 2, SendTime: now.Add(-1 * time.Hour)},
+		{Id: "g3", TalkMode: 2, ToFromId: 200, FromId: 3, MsgType: 1, Content: "deploy 失败了", Sequence: 1, SendTime: now},
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	tokens := Tokenize("Hello, 世界abc")
+
+	want := []string{"hello", "世", "世界", "界", "abc"}
+	if len(tokens) != len(want) {
+		t.Fatalf("Tokenize() = %v, want %v", tokens, want)
+	}
+
+	for i := range want {
+		if tokens[i] != want[i] {
+			t.Fatalf("Tokenize() = %v, want %v", tokens, want)
+		}
+	}
+}
+
+func TestMemoryEngine_Search(t *testing.T) {
+	ctx := context.Background()
+
+	engine, err := NewMemoryEngine("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := engine.Index(ctx, newTestDocs()...); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		query *Query
+		want  []string
+	}{
+		{name: "private owner only", query: &Query{Keyword: "开会", UserId: 1}, want: []string{"p1"}},
+		{name: "chinese phrase", query: &Query{Keyword: "下午开会", UserId: 2}, want: []string{"p2"}},
+		{name: "chinese phrase not adjacent", query: &Query{Keyword: "下会", UserId: 1}, want: []string{}},
+		{name: "group permission", query: &Query{Keyword: "deploy", UserId: 9, GroupIds: []int{100}}, want: []string{"g2", "g1"}},
+		{name: "case insensitive", query: &Query{Keyword: "DEPLOY", UserId: 9, GroupIds: []int{100, 200}}, want: []string{"g3", "g2", "g1"}},
+		{name: "multiple keywords", query: &Query{Keyword: "deploy friday", UserId: 9, GroupIds: []int{100}}, want: []string{"g1"}},
+		{name: "filter sender", query: &Query{Keyword: "deploy", UserId: 9, GroupIds: []int{100, 200}, FromId: 3}, want: []string{"g3", "g1"}},
+		{name: "filter conversation", query: &Query{Keyword: "deploy", UserId: 9, GroupIds: []int{100, 200}, TalkMode: 2, ToFromId: 200}, want: []string{"g3"}},
+		{name: "filter msg type", query: &Query{Keyword: "deploy", UserId: 9, GroupIds: []int{100}, MsgTypes: []int{4}}, want: []string{"g2"}},
+		{name: "filter date range", query: &Query{Keyword: "deploy", UserId: 9, GroupIds: []int{100, 200}, StartTime: time.Now().Add(-90 * time.Minute), EndTime: time.Now().Add(-30 * time.Minute)}, want: []string{"g2"}},
+		{name: "limit", query: &Query{Keyword: "deploy", UserId: 9, GroupIds: []int{100}, Limit: 1}, want: []string{"g2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, err := engine.Search(ctx, tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(items) != len(tt.want) {
+				t.Fatalf("Search() len = %d, want %d", len(items), len(tt.want))
+			}
+
+			for i, item := range items {
+				if item.Id != tt.want[i] {
+					t.Errorf("Search()[%d] = %s, want %s", i, item.Id, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryEngine_SearchCursor(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	engine, _ := NewMemoryEngine("")
+
+	// 批量转发的消息发送时间相同
+	_ = engine.Index(ctx,
+		&Document{Id: "a", TalkMode: 2, ToFromId: 100, Content: "hello", Sequence: 1, SendTime: now},
+		&Document{Id: "b", TalkMode: 2, ToFromId: 100, Content: "hello", Sequence: 2, SendTime: now},
+		&Document{Id: "c", TalkMode: 2, ToFromId: 100, Content: "hello", Sequence: 3, SendTime: now},
+	)
+
+	ids := make([]string, 0)
+	query := &Query{Keyword: "hello", GroupIds: []int{100}, Limit: 2}
+	for i := 0; i < 3; i++ {
+		items, _ := engine.Search(ctx, query)
+		if len(items) == 0 {
+			break
+		}
+
+		for _, item := range items {
+			ids = append(ids, item.Id)
+		}
+
+		query.Cursor = items[len(items)-1].Cursor()
+	}
+
+	if len(ids) != 3 || ids[0] != "c" || ids[1] != "b" || ids[2] != "a" {
+		t.Errorf("Search() pages = %v, want [c b a]", ids)
+	}
+}
+
+func TestMemoryEngine_Delete(t *testing.T) {
+	ctx := context.Background()
+
+	engine, _ := NewMemoryEngine("")
+	_ = engine.Index(ctx, newTestDocs()...)
+	_ = engine.Delete(ctx, "g1")
+
+	items, _ := engine.Search(ctx, &Query{Keyword: "friday", GroupIds: []int{100}})
+	if len(items) != 0 {
+		t.Errorf("Search() after delete len = %d, want 0", len(items))
+	}
+
+	// 覆盖写入时需要清理旧的分词
+	_ = engine.Index(ctx, &Document{Id: "g2", TalkMode: 2, ToFromId: 100, Content: "rollback", Sequence: 2})
+	items, _ = engine.Search(ctx, &Query{Keyword: "deploy", GroupIds: []int{100}})
+	if len(items) != 0 {
+		t.Errorf("Search() after reindex len = %d, want 0", len(items))
+	}
+}
+
+func TestMemoryEngine_Flush(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "search", "index.gob")
+
+	engine, _ := NewMemoryEngine(path)
+	_ = engine.Index(ctx, newTestDocs()...)
+	_ = engine.SetCheckpoint(ctx, "group", 10)
+
+	if err := engine.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	reload, err := NewMemoryEngine(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if value, _ := reload.Checkpoint(ctx, "group"); value != 10 {
+		t.Errorf("Checkpoint() = %d, want 10", value)
+	}
+
+	items, _ := reload.Search(ctx, &Query{Keyword: "开会", UserId: 1})
+	if len(items) != 1 || items[0].Id != "p1" {
+		t.Errorf("Search() after reload = %v", items)
+	}
+}
@@ -0,0 +1,83 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	MemoryDriver = "memory"
+)
+
+// Document 检索文档(一条聊天消息)
+type Document struct {
+	Id       string    `json:"id"`        // 文档ID(消息ID)
+	TalkMode int       `json:"talk_mode"` // 对话类型[1:私信;2:群聊;]
+	OwnerId  int       `json:"owner_id"`  // 私信消息所属用户ID，群消息为0
+	ToFromId int       `json:"to_from_id"`
+	FromId   int       `json:"from_id"`  // 发送者ID
+	MsgType  int       `json:"msg_type"` // 消息类型
+	Content  string    `json:"content"`  // 可检索文本
+	Sequence int64     `json:"sequence"` // 消息时序ID
+	SendTime time.Time `json:"send_time"`
+}
+
+// Query 检索条件
+type Query struct {
+	Keyword   string    // 关键词，多个关键词以空格分隔(AND)
+	UserId    int       // 检索用户，私信文档仅返回该用户的
+	GroupIds  []int     // 允许检索的群ID
+	TalkMode  int       // 对话类型，0 表示不限
+	ToFromId  int       // 会话对象(好友ID或者群ID)，0 表示不限
+	FromId    int       // 发送者，0 表示不限
+	MsgTypes  []int     // 消息类型
+	StartTime time.Time // 发送时间起
+	EndTime   time.Time // 发送时间止
+	Cursor    string    // 上次查询返回的游标，为空时从最新的数据开始
+	Limit     int       // 数据行数
+}
+
+// IEngine 检索引擎
+// 引擎只保存可检索的副本，撤回、删除等状态以数据库为准，由调用方在查询后校验
+type IEngine interface {
+	// Index 写入(覆盖)文档
+	Index(ctx context.Context, docs ...*Document) error
+	// Delete 删除文档
+	Delete(ctx context.Context, ids ...string) error
+	// Search 按发送时间倒序返回命中的文档
+	Search(ctx context.Context, query *Query) ([]*Document, error)
+	// Checkpoint 获取增量同步位点
+	Checkpoint(ctx context.Context, key string) (int64, error)
+	// SetCheckpoint 更新增量同步位点
+	SetCheckpoint(ctx context.Context, key string, value int64) error
+	// Flush 持久化索引
+	Flush(ctx context.Context) error
+}
+
+// Cursor 生成分页游标
+// 时序ID仅在会话内有序，跨会话检索使用发送时间+文档ID排序
+func (d *Document) Cursor() string {
+	return fmt.Sprintf("%d_%s", d.SendTime.UnixNano(), d.Id)
+}
+
+// before 判断文档是否排在游标之后(更早)
+func (d *Document) before(cursor string) bool {
+	value, id, ok := strings.Cut(cursor, "_")
+	if !ok {
+		return true
+	}
+
+	nano, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return true
+	}
+
+	if d.SendTime.UnixNano() == nano {
+		return d.Id < id
+	}
+
+	return d.SendTime.UnixNano() < nano
+}
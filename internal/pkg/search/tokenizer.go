@@ -0,0 +1,75 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Tokenize 分词
+// 字母数字按单词切分并转小写；中日韩文字按单字及相邻二字切分，以支持任意位置的短语匹配
+func Tokenize(text string) []string {
+	var (
+		tokens = make([]string, 0)
+		word   = make([]rune, 0)
+		cjk    = make([]rune, 0)
+	)
+
+	flushWord := func() {
+		if len(word) > 0 {
+			tokens = append(tokens, string(word))
+			word = word[:0]
+		}
+	}
+
+	flushCJK := func() {
+		for i := range cjk {
+			tokens = append(tokens, string(cjk[i]))
+			if i+1 < len(cjk) {
+				tokens = append(tokens, string(cjk[i:i+2]))
+			}
+		}
+		cjk = cjk[:0]
+	}
+
+	for _, r := range text {
+		switch {
+		case isCJK(r):
+			flushWord()
+			cjk = append(cjk, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushCJK()
+			word = append(word, unicode.ToLower(r))
+		default:
+			flushWord()
+			flushCJK()
+		}
+	}
+
+	flushWord()
+	flushCJK()
+
+	return tokens
+}
+
+// queryTokens 查询分词，中日韩文字只取二字词(单字查询除外)，减少倒排合并的开销
+func queryTokens(keyword string) []string {
+	tokens := make([]string, 0)
+	for _, token := range Tokenize(keyword) {
+		runes := []rune(token)
+		if len(runes) == 1 && isCJK(runes[0]) && len([]rune(strings.TrimSpace(keyword))) > 1 {
+			continue
+		}
+
+		tokens = append(tokens, token)
+	}
+
+	if len(tokens) == 0 {
+		return Tokenize(keyword)
+	}
+
+	return tokens
+}
+
+func isCJK(r rune) bool {
+	return unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r) || unicode.Is(unicode.Hangul, r)
+}
@@ -0,0 +1,187 @@
+package sensitive
+
+import (
+	"unicode"
+)
+
+// Hit 命中的敏感词，Start/End 为原文中的字符(rune)下标，区间为 [Start, End)
+type Hit struct {
+	Word  string
+	Index int // 敏感词在词库中的下标
+	Start int
+	End   int
+}
+
+type node struct {
+	next   map[rune]int32
+	fail   int32
+	output []int
+}
+
+// Matcher 基于 Aho-Corasick 自动机的多模式匹配器，构建后只读，可并发使用
+// 匹配时忽略大小写、全角半角差异，并跳过空白及标点符号，避免通过插入分隔符绕过检测
+type Matcher struct {
+	words   []string
+	lengths []int // 敏感词参与匹配的字符数
+	nodes   []*node
+}
+
+// New 根据词库构建匹配器
+func New(words []string) *Matcher {
+	m := &Matcher{
+		words:   words,
+		lengths: make([]int, len(words)),
+		nodes:   []*node{{next: make(map[rune]int32)}},
+	}
+
+	for i, word := range words {
+		m.insert(i, word)
+	}
+
+	m.build()
+	return m
+}
+
+func (m *Matcher) insert(index int, word string) {
+	cur := int32(0)
+	depth := 0
+
+	for _, r := range word {
+		if ignorable(r) {
+			continue
+		}
+
+		r = normalize(r)
+		depth++
+
+		next, ok := m.nodes[cur].next[r]
+		if !ok {
+			next = int32(len(m.nodes))
+			m.nodes = append(m.nodes, &node{next: make(map[rune]int32)})
+			m.nodes[cur].next[r] = next
+		}
+
+		cur = next
+	}
+
+	if cur != 0 {
+		m.lengths[index] = depth
+		m.nodes[cur].output = append(m.nodes[cur].output, index)
+	}
+}
+
+// build 按层次遍历构建失败指针，并合并失败链上的输出
+func (m *Matcher) build() {
+	queue := make([]int32, 0, len(m.nodes))
+	for _, child := range m.nodes[0].next {
+		queue = append(queue, child)
+	}
+
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+
+		for r, child := range m.nodes[cur].next {
+			fail := m.nodes[cur].fail
+			for {
+				if next, ok := m.nodes[fail].next[r]; ok && next != child {
+					m.nodes[child].fail = next
+					break
+				}
+
+				if fail == 0 {
+					m.nodes[child].fail = 0
+					break
+				}
+
+				fail = m.nodes[fail].fail
+			}
+
+			m.nodes[child].output = append(m.nodes[child].output, m.nodes[m.nodes[child].fail].output...)
+			queue = append(queue, child)
+		}
+	}
+}
+
+// Match 返回文本中全部命中的敏感词
+func (m *Matcher) Match(text string) []*Hit {
+	if m == nil || len(m.nodes) <= 1 {
+		return nil
+	}
+
+	runes := []rune(text)
+
+	// positions 记录参与匹配的字符在原文中的下标
+	positions := make([]int, 0, len(runes))
+
+	hits := make([]*Hit, 0)
+	cur := int32(0)
+	for i, r := range runes {
+		if ignorable(r) {
+			continue
+		}
+
+		positions = append(positions, i)
+		r = normalize(r)
+
+		for {
+			if next, ok := m.nodes[cur].next[r]; ok {
+				cur = next
+				break
+			}
+
+			if cur == 0 {
+				break
+			}
+
+			cur = m.nodes[cur].fail
+		}
+
+		for _, index := range m.nodes[cur].output {
+			hits = append(hits, &Hit{
+				Word:  m.words[index],
+				Index: index,
+				Start: positions[len(positions)-m.lengths[index]],
+				End:   i + 1,
+			})
+		}
+	}
+
+	return hits
+}
+
+// Contains 判断文本是否包含敏感词
+func (m *Matcher) Contains(text string) bool {
+	return len(m.Match(text)) > 0
+}
+
+// Mask 将命中的敏感词替换为 mask 字符，保留其中的空白及标点
+func Mask(text string, hits []*Hit, mask rune) string {
+	if len(hits) == 0 {
+		return text
+	}
+
+	runes := []rune(text)
+	for _, hit := range hits {
+		for i := hit.Start; i < hit.End && i < len(runes); i++ {
+			if !ignorable(runes[i]) {
+				runes[i] = mask
+			}
+		}
+	}
+
+	return string(runes)
+}
+
+func ignorable(r rune) bool {
+	return unicode.IsSpace(r) || unicode.IsPunct(r) || unicode.IsSymbol(r)
+}
+
+// normalize 转换为小写半角字符
+func normalize(r rune) rune {
+	if r >= 0xFF01 && r <= 0xFF5E {
+		r -= 0xFEE0
+	}
+
+	return unicode.ToLower(r)
+}
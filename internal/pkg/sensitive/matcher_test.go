@@ -0,0 +1,64 @@
+package sensitive
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatcher_Match(t *testing.T) {
+	m := New([]string{"he", "she", "his", "hers", "赌博", "Ｆoo"})
+
+	cases := []struct {
+		name   string
+		text   string
+		expect []string
+	}{
+		{name: "overlap", text: "ushers", expect: []string{"she", "he", "hers"}},
+		{name: "none", text: "hello world", expect: []string{"he"}},
+		{name: "chinese", text: "网络赌博违法", expect: []string{"赌博"}},
+		{name: "separator", text: "网络赌 * 博违法", expect: []string{"赌博"}},
+		{name: "case insensitive", text: "FOO bar", expect: []string{"Ｆoo"}},
+		{name: "empty", text: "", expect: []string{}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			words := make([]string, 0)
+			for _, hit := range m.Match(c.text) {
+				words = append(words, hit.Word)
+			}
+
+			if !reflect.DeepEqual(words, c.expect) {
+				t.Errorf("Match(%q) = %v, want %v", c.text, words, c.expect)
+			}
+		})
+	}
+}
+
+func TestMask(t *testing.T) {
+	m := New([]string{"赌博", "bad"})
+
+	cases := map[string]string{
+		"网络赌博违法":    "网络**违法",
+		"网络赌 * 博违法": "网络* * *违法",
+		"so BAD!":   "so ***!",
+		"正常内容":      "正常内容",
+	}
+
+	for text, expect := range cases {
+		if got := Mask(text, m.Match(text), '*'); got != expect {
+			t.Errorf("Mask(%q) = %q, want %q", text, got, expect)
+		}
+	}
+}
+
+func TestMatcher_Empty(t *testing.T) {
+	var m *Matcher
+	if m.Contains("anything") {
+		t.Error("nil matcher should not match")
+	}
+
+	if New(nil).Contains("anything") {
+		t.Error("empty matcher should not match")
+	}
+}
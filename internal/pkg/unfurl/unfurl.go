@@ -0,0 +1,268 @@
+package unfurl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gzydong/go-chat/internal/pkg/safehttp"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+)
+
+const (
+	defaultTimeout      = 5 * time.Second
+	defaultMaxBodySize  = 512 << 10
+	defaultMaxRedirects = 3
+	defaultUserAgent    = "Mozilla/5.0 (compatible; LumenIMBot/1.0; +link-preview)"
+
+	maxTitleLength       = 200
+	maxDescriptionLength = 300
+	maxUrlLength         = 2048
+)
+
+var (
+	ErrInvalidUrl    = errors.New("unfurl: invalid url")
+	ErrForbiddenAddr = safehttp.ErrForbiddenAddr
+	ErrNotHTML       = errors.New("unfurl: not html")
+	ErrNoMetadata    = errors.New("unfurl: no metadata")
+)
+
+var urlRegexp = regexp.MustCompile(`(?i)https?://[^\s<>"'` + "`" + `，。！？；：、（）【】《》「」“”‘’]+`)
+
+// Preview 链接预览信息
+type Preview struct {
+	Url         string `json:"url"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Image       string `json:"image"`
+	SiteName    string `json:"site_name"`
+}
+
+type Options struct {
+	Timeout      time.Duration // 单次抓取总超时，包含重定向
+	MaxBodySize  int64         // 最多读取的响应字节数
+	MaxRedirects int           // 最多跟随的重定向次数
+	UserAgent    string
+	AllowPrivate bool // 允许访问内网地址，仅用于测试
+}
+
+// Fetcher 抓取网页的 Open Graph 及标题信息，不允许访问内网地址
+type Fetcher struct {
+	opts   Options
+	client *http.Client
+}
+
+func New(opts Options) *Fetcher {
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultTimeout
+	}
+
+	if opts.MaxBodySize <= 0 {
+		opts.MaxBodySize = defaultMaxBodySize
+	}
+
+	if opts.MaxRedirects <= 0 {
+		opts.MaxRedirects = defaultMaxRedirects
+	}
+
+	if opts.UserAgent == "" {
+		opts.UserAgent = defaultUserAgent
+	}
+
+	client := safehttp.NewClient(safehttp.Options{
+		Timeout:      opts.Timeout,
+		MaxRedirects: opts.MaxRedirects,
+		AllowPrivate: opts.AllowPrivate,
+	})
+
+	return &Fetcher{opts: opts, client: client}
+}
+
+// ExtractUrls 提取文本中的链接，去重后最多返回 limit 个
+func ExtractUrls(text string, limit int) []string {
+	items := make([]string, 0)
+	if limit <= 0 {
+		return items
+	}
+
+	for _, value := range urlRegexp.FindAllString(text, -1) {
+		value = strings.TrimRight(value, ".,;:!?)]}")
+		if len(value) > maxUrlLength {
+			continue
+		}
+
+		u, err := url.Parse(value)
+		if err != nil || u.Hostname() == "" {
+			continue
+		}
+
+		exist := false
+		for _, item := range items {
+			if item == value {
+				exist = true
+				break
+			}
+		}
+
+		if exist {
+			continue
+		}
+
+		items = append(items, value)
+		if len(items) >= limit {
+			break
+		}
+	}
+
+	return items
+}
+
+// Fetch 抓取链接的预览信息
+func (f *Fetcher) Fetch(ctx context.Context, rawUrl string) (*Preview, error) {
+	u, err := url.Parse(rawUrl)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return nil, ErrInvalidUrl
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, f.opts.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("User-Agent", f.opts.UserAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		if errors.Is(err, ErrForbiddenAddr) {
+			return nil, ErrForbiddenAddr
+		}
+
+		return nil, err
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("unfurl: unexpected status %d", resp.StatusCode)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if !strings.Contains(contentType, "text/html") && !strings.Contains(contentType, "application/xhtml+xml") {
+		return nil, ErrNotHTML
+	}
+
+	body, err := charset.NewReader(io.LimitReader(resp.Body, f.opts.MaxBodySize), contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := parse(body, resp.Request.URL)
+	if preview.Title == "" && preview.Description == "" {
+		return nil, ErrNoMetadata
+	}
+
+	preview.Url = rawUrl
+	return preview, nil
+}
+
+// parse 解析 head 中的元信息，Open Graph 优先，遇到 body 时停止
+func parse(r io.Reader, base *url.URL) *Preview {
+	var (
+		meta    = make(map[string]string)
+		title   string
+		inTitle bool
+	)
+
+	tokenizer := html.NewTokenizer(r)
+
+loop:
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			break loop
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+
+			switch token.Data {
+			case "body":
+				break loop
+			case "title":
+				inTitle = title == ""
+			case "meta":
+				var key, content string
+				for _, attr := range token.Attr {
+					switch strings.ToLower(attr.Key) {
+					case "property", "name":
+						key = strings.ToLower(strings.TrimSpace(attr.Val))
+					case "content":
+						content = strings.TrimSpace(attr.Val)
+					}
+				}
+
+				if key != "" && content != "" {
+					if _, ok := meta[key]; !ok {
+						meta[key] = content
+					}
+				}
+			}
+		case html.TextToken:
+			if inTitle {
+				title += string(tokenizer.Text())
+			}
+		case html.EndTagToken:
+			if tokenizer.Token().Data == "title" {
+				inTitle = false
+			}
+		}
+	}
+
+	preview := &Preview{
+		Title:       first(meta["og:title"], meta["twitter:title"], title),
+		Description: first(meta["og:description"], meta["twitter:description"], meta["description"]),
+		SiteName:    first(meta["og:site_name"]),
+	}
+
+	preview.Title = truncate(strings.Join(strings.Fields(preview.Title), " "), maxTitleLength)
+	preview.Description = truncate(strings.Join(strings.Fields(preview.Description), " "), maxDescriptionLength)
+	preview.SiteName = truncate(preview.SiteName, maxTitleLength)
+
+	if image := first(meta["og:image:secure_url"], meta["og:image"], meta["twitter:image"]); image != "" {
+		if u, err := base.Parse(image); err == nil && (u.Scheme == "http" || u.Scheme == "https") && len(u.String()) <= maxUrlLength {
+			preview.Image = u.String()
+		}
+	}
+
+	return preview
+}
+
+func first(values ...string) string {
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
+
+func truncate(value string, length int) string {
+	if utf8.RuneCountInString(value) <= length {
+		return value
+	}
+
+	return string([]rune(value)[:length])
+}
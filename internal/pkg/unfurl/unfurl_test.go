@@ -0,0 +1,125 @@
+package unfurl
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExtractUrls(t *testing.T) {
+	tests := []struct {
+		text  string
+		limit int
+		want  []string
+	}{
+		{"no link here", 3, []string{}},
+		{"看看这个 https://example.com/a?b=1，不错吧", 3, []string{"https://example.com/a?b=1"}},
+		{"(see http://example.com/x).", 3, []string{"http://example.com/x"}},
+		{"https://a.com https://b.com https://a.com https://c.com", 2, []string{"https://a.com", "https://b.com"}},
+		{"ftp://example.com https://", 3, []string{}},
+	}
+
+	for _, tt := range tests {
+		if got := ExtractUrls(tt.text, tt.limit); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ExtractUrls(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestFetcher_Fetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/og":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, _ = w.Write([]byte(`<html><head><title>Fallback</title>
+<meta property="og:title" content="  Open   Graph Title ">
+<meta property="og:description" content="Description">
+<meta property="og:image" content="/cover.png">
+<meta property="og:site_name" content="Example">
+</head><body><meta property="og:title" content="ignored"></body></html>`))
+		case "/title":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte(`<html><head><title>Plain Title</title><meta name="description" content="Meta description"></head></html>`))
+		case "/gbk":
+			w.Header().Set("Content-Type", "text/html; charset=gbk")
+			_, _ = w.Write([]byte("<html><head><title>\xc4\xe3\xba\xc3</title></head></html>"))
+		case "/redirect":
+			http.Redirect(w, r, "/og", http.StatusFound)
+		case "/image":
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write([]byte("png"))
+		case "/large":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte("<html><head>" + strings.Repeat(" ", 4096) + "<title>Too Far</title></head></html>"))
+		case "/slow":
+			time.Sleep(500 * time.Millisecond)
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte("<title>Slow</title>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	fetcher := New(Options{AllowPrivate: true, MaxBodySize: 1024, Timeout: 200 * time.Millisecond})
+
+	tests := []struct {
+		path    string
+		want    *Preview
+		wantErr bool
+	}{
+		{path: "/og", want: &Preview{Title: "Open Graph Title", Description: "Description", Image: server.URL + "/cover.png", SiteName: "Example"}},
+		{path: "/title", want: &Preview{Title: "Plain Title", Description: "Meta description"}},
+		{path: "/gbk", want: &Preview{Title: "你好"}},
+		{path: "/redirect", want: &Preview{Title: "Open Graph Title", Description: "Description", Image: server.URL + "/cover.png", SiteName: "Example"}},
+		{path: "/image", wantErr: true},
+		{path: "/large", wantErr: true},
+		{path: "/slow", wantErr: true},
+		{path: "/missing", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := fetcher.Fetch(context.Background(), server.URL+tt.path)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("Fetch(%s) err = nil, want error", tt.path)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("Fetch(%s) err = %v", tt.path, err)
+			continue
+		}
+
+		tt.want.Url = server.URL + tt.path
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Fetch(%s) = %+v, want %+v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestFetcher_FetchForbidden(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte("<title>Internal</title>"))
+	}))
+	defer server.Close()
+
+	fetcher := New(Options{})
+
+	for _, rawUrl := range []string{server.URL, "http://localhost:" + server.URL[strings.LastIndex(server.URL, ":")+1:]} {
+		if _, err := fetcher.Fetch(context.Background(), rawUrl); !errors.Is(err, ErrForbiddenAddr) {
+			t.Errorf("Fetch(%s) err = %v, want %v", rawUrl, err, ErrForbiddenAddr)
+		}
+	}
+
+	if _, err := fetcher.Fetch(context.Background(), "file:///etc/passwd"); !errors.Is(err, ErrInvalidUrl) {
+		t.Errorf("Fetch(file) err = %v, want %v", err, ErrInvalidUrl)
+	}
+}
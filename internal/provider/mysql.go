@@ -60,6 +60,11 @@ func NewMySQLClient(conf *config.Config) *gorm.DB {
 		panic(fmt.Errorf("database error :%v", err))
 	}
 
+	// 补齐已有表的结构变更
+	if err := upgradeMySQL(db); err != nil {
+		panic(fmt.Errorf("database upgrade error :%v", err))
+	}
+
 	sqlDB, _ := db.DB()
 
 	sqlDB.SetMaxIdleConns(conf.MySQL.MaxIdleConnNum)
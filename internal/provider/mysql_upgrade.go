@@ -0,0 +1,103 @@
+package provider
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// mysqlColumnUpgrade 已有表新增的字段
+type mysqlColumnUpgrade struct {
+	Table      string
+	Column     string
+	Definition string
+}
+
+// mysqlIndexUpgrade 已有表新增的索引
+type mysqlIndexUpgrade struct {
+	Table      string
+	Index      string
+	Definition string
+}
+
+// 已部署的数据库中 CREATE TABLE IF NOT EXISTS 不会生效，已有表的结构变更需在此登记
+var mysqlColumnUpgrades = []mysqlColumnUpgrade{
+	{Table: "talk_group_message", Column: "root_msg_id", Definition: "varchar(64) NOT NULL DEFAULT '' COMMENT '话题根消息ID' AFTER `quote`"},
+	{Table: "group", Column: "mute_expire_at", Definition: "datetime DEFAULT NULL COMMENT '全员禁言截止时间，为空表示永久禁言' AFTER `is_mute`"},
+	{Table: "group", Column: "mute_reason", Definition: "varchar(255) NOT NULL DEFAULT '' COMMENT '全员禁言原因' AFTER `mute_expire_at`"},
+	{Table: "group", Column: "permission_setting", Definition: "varchar(512) NOT NULL DEFAULT '' COMMENT '群权限开放范围设置(JSON)，为空时使用默认范围' AFTER `mute_reason`"},
+	{Table: "group", Column: "join_mode", Definition: "tinyint unsigned NOT NULL DEFAULT '2' COMMENT '入群方式[1:自由加入;2:需审核;3:仅限邀请;4:回答问题;]' AFTER `permission_setting`"},
+	{Table: "group", Column: "join_question", Definition: "varchar(255) NOT NULL DEFAULT '' COMMENT '入群问题' AFTER `join_mode`"},
+	{Table: "group", Column: "join_answer", Definition: "varchar(255) NOT NULL DEFAULT '' COMMENT '入群问题答案' AFTER `join_question`"},
+	{Table: "group", Column: "anti_spam_setting", Definition: "varchar(255) NOT NULL DEFAULT '' COMMENT '防刷屏设置(JSON)，为空时不限制' AFTER `join_answer`"},
+	{Table: "group_member", Column: "mute_expire_at", Definition: "datetime DEFAULT NULL COMMENT '禁言截止时间，为空表示永久禁言' AFTER `is_mute`"},
+	{Table: "group_member", Column: "mute_reason", Definition: "varchar(255) NOT NULL DEFAULT '' COMMENT '禁言原因' AFTER `mute_expire_at`"},
+	{Table: "group_member", Column: "permissions", Definition: "varchar(255) NOT NULL DEFAULT '' COMMENT '管理员权限项(JSON)，为空时拥有全部权限' AFTER `mute_reason`"},
+	{Table: "group_notice", Column: "title", Definition: "varchar(64) NOT NULL DEFAULT '' COMMENT '公告标题' AFTER `modify_id`"},
+	{Table: "group_notice", Column: "is_top", Definition: "tinyint unsigned NOT NULL DEFAULT '2' COMMENT '是否置顶[1:是;2:否;]' AFTER `is_confirm`"},
+	{Table: "group_notice", Column: "is_delete", Definition: "tinyint unsigned NOT NULL DEFAULT '0' COMMENT '是否删除[0:否;2:是;]' AFTER `is_top`"},
+	{Table: "group_vote", Column: "result_visible", Definition: "int unsigned NOT NULL DEFAULT '1' COMMENT '结果可见范围[1:始终可见;2:投票后或结束后可见;]' AFTER `status`"},
+	{Table: "group_vote", Column: "deadline", Definition: "datetime DEFAULT NULL COMMENT '截止时间，为空表示不限时' AFTER `result_visible`"},
+	{Table: "talk_scheduled_message", Column: "msg_id", Definition: "varchar(64) NOT NULL DEFAULT '' COMMENT '发送时生成的消息ID' AFTER `fail_reason`"},
+}
+
+var mysqlIndexUpgrades = []mysqlIndexUpgrade{
+	{Table: "talk_group_message", Index: "idx_root_msg_id_sequence", Definition: "KEY `idx_root_msg_id_sequence` (`root_msg_id`, `sequence`) USING BTREE"},
+	{Table: "talk_user_message", Index: "idx_user_id_sequence", Definition: "KEY `idx_user_id_sequence` (`user_id`, `sequence`) USING BTREE"},
+	{Table: "talk_user_message", Index: "idx_send_time", Definition: "KEY `idx_send_time` (`send_time`) USING BTREE"},
+	{Table: "talk_group_message", Index: "idx_send_time", Definition: "KEY `idx_send_time` (`send_time`) USING BTREE"},
+	{Table: "group", Index: "idx_mute_expire_at", Definition: "KEY `idx_mute_expire_at` (`mute_expire_at`) USING BTREE"},
+	{Table: "group_member", Index: "idx_mute_expire_at", Definition: "KEY `idx_mute_expire_at` (`mute_expire_at`) USING BTREE"},
+	{Table: "group_notice", Index: "idx_group_id", Definition: "KEY `idx_group_id` (`group_id`, `is_delete`) USING BTREE"},
+	{Table: "group_vote", Index: "idx_status_deadline", Definition: "KEY `idx_status_deadline` (`status`, `deadline`) USING BTREE"},
+	{Table: "talk_scheduled_message", Index: "idx_status_updated_at", Definition: "KEY `idx_status_updated_at` (`status`, `updated_at`) USING BTREE"},
+}
+
+// upgradeMySQL 补齐已有表的结构变更，可重复执行
+// 表不存在时跳过，由初始化脚本创建
+func upgradeMySQL(db *gorm.DB) error {
+	migrator := db.Migrator()
+
+	for _, item := range mysqlColumnUpgrades {
+		if !migrator.HasTable(item.Table) || migrator.HasColumn(item.Table, item.Column) {
+			continue
+		}
+
+		if err := db.Exec(fmt.Sprintf("ALTER TABLE `%s` ADD COLUMN `%s` %s", item.Table, item.Column, item.Definition)).Error; err != nil {
+			return err
+		}
+	}
+
+	// 群公告支持多条历史公告，移除每个群只能有一条公告的唯一索引
+	if migrator.HasTable("group_notice") {
+		if migrator.HasIndex("group_notice", "un_group_id") {
+			if err := db.Exec("ALTER TABLE `group_notice` DROP INDEX `un_group_id`").Error; err != nil {
+				return err
+			}
+		}
+
+		var count int64
+		err := db.Raw("SELECT count(*) FROM information_schema.statistics WHERE table_schema = database() AND table_name = 'group_notice' AND index_name = 'PRIMARY'").Scan(&count).Error
+		if err != nil {
+			return err
+		}
+
+		if count > 1 {
+			if err := db.Exec("ALTER TABLE `group_notice` DROP PRIMARY KEY, ADD PRIMARY KEY (`id`) USING BTREE").Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, item := range mysqlIndexUpgrades {
+		if !migrator.HasTable(item.Table) || migrator.HasIndex(item.Table, item.Index) {
+			continue
+		}
+
+		if err := db.Exec(fmt.Sprintf("ALTER TABLE `%s` ADD %s", item.Table, item.Definition)).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -7,11 +7,15 @@ import (
 	"github.com/nsqio/go-nsq"
 )
 
-// NewNsqProducer 初始化生产者
+// NewNsqProducer 初始化生产者，连接在首次投递时建立
 func NewNsqProducer(conf *config.Config) *nsq.Producer {
+	var addr string
+	if conf.Nsq != nil {
+		addr = conf.Nsq.Addr
+	}
 
 	nsqConfig := nsq.NewConfig()
-	producer, err := nsq.NewProducer(conf.Nsq.Addr, nsqConfig)
+	producer, err := nsq.NewProducer(addr, nsqConfig)
 	if err != nil {
 		panic(fmt.Sprintf("create producer failed, err:%s\n", err.Error()))
 	}
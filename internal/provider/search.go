@@ -0,0 +1,26 @@
+package provider
+
+import (
+	"fmt"
+
+	"github.com/gzydong/go-chat/config"
+	"github.com/gzydong/go-chat/internal/pkg/search"
+)
+
+func NewSearchEngine(conf *config.Config) search.IEngine {
+	if conf.Search == nil {
+		conf.Search = &config.Search{Driver: search.MemoryDriver}
+	}
+
+	switch conf.Search.Driver {
+	case "", search.MemoryDriver:
+		engine, err := search.NewMemoryEngine(conf.Search.Path)
+		if err != nil {
+			panic(fmt.Errorf("search engine error: %s", err))
+		}
+
+		return engine
+	default:
+		panic(fmt.Errorf("search driver %s not supported", conf.Search.Driver))
+	}
+}
@@ -11,11 +11,13 @@ var ProviderSet = wire.NewSet(
 	NewHttpClient,
 	NewEmailClient,
 	NewFilesystem,
+	NewSearchEngine,
 	NewBase64Captcha,
 	NewIpAddressClient,
 	NewRsa,
 	NewAesUtil,
 	NewGiteeClient,
+	NewNsqProducer,
 	NewGithubClient,
 	NewWebUserJwtAuthorize,
 	wire.Struct(new(Providers), "*"),
@@ -0,0 +1,70 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// GroupAntiSpam 群防刷屏计数缓存
+type GroupAntiSpam struct {
+	redis *redis.Client
+}
+
+func NewGroupAntiSpam(rds *redis.Client) *GroupAntiSpam {
+	return &GroupAntiSpam{redis: rds}
+}
+
+// SlowModeTTL 获取慢速模式剩余等待时长，为 0 时可以发言
+func (g *GroupAntiSpam) SlowModeTTL(ctx context.Context, groupId int, uid int) time.Duration {
+	ttl, err := g.redis.PTTL(ctx, g.slowName(groupId, uid)).Result()
+	if err != nil || ttl <= 0 {
+		return 0
+	}
+
+	return ttl
+}
+
+// SetSlowMode 消息发送成功后开始计算慢速模式发言间隔
+func (g *GroupAntiSpam) SetSlowMode(ctx context.Context, groupId int, uid int, interval int) {
+	g.redis.Set(ctx, g.slowName(groupId, uid), 1, time.Duration(interval)*time.Second)
+}
+
+// FloodCount 获取统计窗口内已发送的消息数
+func (g *GroupAntiSpam) FloodCount(ctx context.Context, groupId int, uid int) int64 {
+	num, err := g.redis.Get(ctx, g.floodName(groupId, uid)).Int64()
+	if err != nil {
+		return 0
+	}
+
+	return num
+}
+
+// IncrFlood 消息发送成功后累加统计窗口内的发言次数，窗口从第一条消息开始计算
+func (g *GroupAntiSpam) IncrFlood(ctx context.Context, groupId int, uid int, window int) int64 {
+	num, err := g.redis.Incr(ctx, g.floodName(groupId, uid)).Result()
+	if err != nil {
+		return 0
+	}
+
+	if num == 1 {
+		g.redis.Expire(ctx, g.floodName(groupId, uid), time.Duration(window)*time.Second)
+	}
+
+	return num
+}
+
+// DelFlood 清除发言次数统计
+func (g *GroupAntiSpam) DelFlood(ctx context.Context, groupId int, uid int) {
+	g.redis.Del(ctx, g.floodName(groupId, uid))
+}
+
+func (g *GroupAntiSpam) slowName(groupId int, uid int) string {
+	return fmt.Sprintf("im:group:slow:%d:%d", groupId, uid)
+}
+
+func (g *GroupAntiSpam) floodName(groupId int, uid int) string {
+	return fmt.Sprintf("im:group:flood:%d:%d", groupId, uid)
+}
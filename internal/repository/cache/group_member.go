@@ -0,0 +1,117 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	groupMemberExpire        = time.Hour      // 成员集合缓存时长
+	groupMemberVersionExpire = 24 * time.Hour // 版本号缓存时长，需大于成员集合缓存时长
+)
+
+// GroupMember 群成员用户ID缓存，集合中固定包含成员 0 用于标识已加载
+// 成员变动时删除集合并递增版本号，读取时从数据库重新加载，避免大群每条消息推送都全量查询数据库
+type GroupMember struct {
+	redis *redis.Client
+}
+
+func NewGroupMember(redis *redis.Client) *GroupMember {
+	return &GroupMember{redis: redis}
+}
+
+// GetMemberIds 获取群成员用户ID，缓存未加载时返回 false
+func (g *GroupMember) GetMemberIds(ctx context.Context, groupId int) ([]int, bool) {
+	values, err := g.redis.SMembers(ctx, g.name(groupId)).Result()
+	if err != nil || len(values) == 0 {
+		return nil, false
+	}
+
+	ids := make([]int, 0, len(values))
+	for _, value := range values {
+		id, err := strconv.Atoi(value)
+		if err != nil || id == 0 {
+			continue
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, true
+}
+
+// Version 获取成员缓存的版本号，需在查询数据库之前获取，加载时用于校验
+func (g *GroupMember) Version(ctx context.Context, groupId int) (int64, error) {
+	version, err := g.redis.Get(ctx, g.versionName(groupId)).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+
+	return version, err
+}
+
+// Load 写入从数据库加载的成员，加载期间成员发生变动(版本号变化)时放弃写入，返回是否已写入
+func (g *GroupMember) Load(ctx context.Context, groupId int, version int64, uids []int) (bool, error) {
+	members := make([]any, 0, len(uids)+1)
+	members = append(members, 0)
+	for _, uid := range uids {
+		members = append(members, uid)
+	}
+
+	loaded := false
+	err := g.redis.Watch(ctx, func(tx *redis.Tx) error {
+		current, err := tx.Get(ctx, g.versionName(groupId)).Int64()
+		if err != nil && !errors.Is(err, redis.Nil) {
+			return err
+		}
+
+		if current != version {
+			return nil
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Del(ctx, g.name(groupId))
+			pipe.SAdd(ctx, g.name(groupId), members...)
+			pipe.Expire(ctx, g.name(groupId), groupMemberExpire)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		loaded = true
+		return nil
+	}, g.versionName(groupId))
+
+	// 提交前版本号发生变化
+	if errors.Is(err, redis.TxFailedErr) {
+		return false, nil
+	}
+
+	return loaded, err
+}
+
+// Del 成员变动后清除缓存并递增版本号，使加载中的旧数据不再写入
+func (g *GroupMember) Del(ctx context.Context, groupId int) error {
+	_, err := g.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Incr(ctx, g.versionName(groupId))
+		pipe.Expire(ctx, g.versionName(groupId), groupMemberVersionExpire)
+		pipe.Del(ctx, g.name(groupId))
+		return nil
+	})
+
+	return err
+}
+
+func (g *GroupMember) name(groupId int) string {
+	return fmt.Sprintf("im:group:member:ids:%d", groupId)
+}
+
+func (g *GroupMember) versionName(groupId int) string {
+	return fmt.Sprintf("im:group:member:ids-version:%d", groupId)
+}
@@ -0,0 +1,294 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// fakeRedis 仅实现成员缓存用到的命令，支持 WATCH/MULTI/EXEC 事务
+type fakeRedis struct {
+	mu         sync.Mutex
+	strings    map[string]string
+	sets       map[string]map[string]struct{}
+	revision   map[string]int // 键的修改次数，用于 WATCH 校验
+	beforeExec func()         // EXEC 执行前调用，模拟其它客户端的并发修改
+}
+
+func newFakeRedis(t *testing.T) (*fakeRedis, *redis.Client) {
+	server := &fakeRedis{
+		strings:  make(map[string]string),
+		sets:     make(map[string]map[string]struct{}),
+		revision: make(map[string]int),
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go server.serve(conn)
+		}
+	}()
+
+	client := redis.NewClient(&redis.Options{Addr: listener.Addr().String(), Protocol: 2, DisableIdentity: true})
+	t.Cleanup(func() {
+		_ = client.Close()
+		_ = listener.Close()
+	})
+
+	return server, client
+}
+
+func (f *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+
+	var (
+		reader  = bufio.NewReader(conn)
+		watched map[string]int
+		queued  [][]string
+		inMulti bool
+	)
+
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+
+		name := strings.ToUpper(args[0])
+
+		f.mu.Lock()
+		switch {
+		case name == "WATCH":
+			watched = make(map[string]int)
+			for _, key := range args[1:] {
+				watched[key] = f.revision[key]
+			}
+			_, err = io.WriteString(conn, "+OK\r\n")
+		case name == "UNWATCH":
+			watched = nil
+			_, err = io.WriteString(conn, "+OK\r\n")
+		case name == "MULTI":
+			inMulti, queued = true, nil
+			_, err = io.WriteString(conn, "+OK\r\n")
+		case name == "EXEC":
+			if f.beforeExec != nil {
+				hook := f.beforeExec
+				f.beforeExec = nil
+				f.mu.Unlock()
+				hook()
+				f.mu.Lock()
+			}
+
+			aborted := false
+			for key, revision := range watched {
+				if f.revision[key] != revision {
+					aborted = true
+				}
+			}
+
+			if aborted {
+				_, err = io.WriteString(conn, "*-1\r\n")
+			} else {
+				replies := make([]string, 0, len(queued))
+				for _, cmd := range queued {
+					replies = append(replies, f.exec(cmd))
+				}
+				_, err = io.WriteString(conn, fmt.Sprintf("*%d\r\n%s", len(replies), strings.Join(replies, "")))
+			}
+
+			inMulti, queued, watched = false, nil, nil
+		case inMulti:
+			queued = append(queued, args)
+			_, err = io.WriteString(conn, "+QUEUED\r\n")
+		default:
+			_, err = io.WriteString(conn, f.exec(args))
+		}
+		f.mu.Unlock()
+
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (f *fakeRedis) exec(args []string) string {
+	switch strings.ToUpper(args[0]) {
+	case "PING":
+		return "+PONG\r\n"
+	case "GET":
+		value, ok := f.strings[args[1]]
+		if !ok {
+			return "$-1\r\n"
+		}
+		return fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+	case "INCR":
+		value, _ := strconv.Atoi(f.strings[args[1]])
+		f.strings[args[1]] = strconv.Itoa(value + 1)
+		f.revision[args[1]]++
+		return fmt.Sprintf(":%d\r\n", value+1)
+	case "EXPIRE":
+		return ":1\r\n"
+	case "DEL":
+		count := 0
+		for _, key := range args[1:] {
+			if _, ok := f.sets[key]; ok {
+				delete(f.sets, key)
+				f.revision[key]++
+				count++
+			}
+		}
+		return fmt.Sprintf(":%d\r\n", count)
+	case "SADD":
+		set, ok := f.sets[args[1]]
+		if !ok {
+			set = make(map[string]struct{})
+			f.sets[args[1]] = set
+		}
+		for _, member := range args[2:] {
+			set[member] = struct{}{}
+		}
+		f.revision[args[1]]++
+		return fmt.Sprintf(":%d\r\n", len(args)-2)
+	case "SMEMBERS":
+		members := make([]string, 0)
+		for member := range f.sets[args[1]] {
+			members = append(members, fmt.Sprintf("$%d\r\n%s\r\n", len(member), member))
+		}
+		return fmt.Sprintf("*%d\r\n%s", len(members), strings.Join(members, ""))
+	}
+
+	return fmt.Sprintf("-ERR unknown command '%s'\r\n", args[0])
+}
+
+func readCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		if _, err := reader.ReadString('\n'); err != nil {
+			return nil, err
+		}
+
+		value, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+
+		args = append(args, strings.TrimSuffix(value, "\r\n"))
+	}
+
+	return args, nil
+}
+
+func TestGroupMemberLoad(t *testing.T) {
+	ctx := context.Background()
+
+	cases := []struct {
+		name       string
+		change     func(cache *GroupMember) // 查询数据库期间发生的成员变动
+		beforeExec bool                     // 变动发生在 WATCH 之后、EXEC 之前
+		wantLoaded bool
+	}{
+		{name: "no change", wantLoaded: true},
+		{name: "changed before load", change: func(cache *GroupMember) { _ = cache.Del(ctx, 1) }},
+		{name: "changed before exec", change: func(cache *GroupMember) { _ = cache.Del(ctx, 1) }, beforeExec: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			server, client := newFakeRedis(t)
+			cache := NewGroupMember(client)
+
+			version, err := cache.Version(ctx, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if c.change != nil {
+				if c.beforeExec {
+					server.beforeExec = func() { c.change(NewGroupMember(client)) }
+				} else {
+					c.change(cache)
+				}
+			}
+
+			loaded, err := cache.Load(ctx, 1, version, []int{10, 11})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if loaded != c.wantLoaded {
+				t.Fatalf("Load() = %v, want %v", loaded, c.wantLoaded)
+			}
+
+			ids, ok := cache.GetMemberIds(ctx, 1)
+			if ok != c.wantLoaded {
+				t.Fatalf("GetMemberIds() ok = %v, want %v", ok, c.wantLoaded)
+			}
+
+			slices.Sort(ids)
+			if c.wantLoaded && !slices.Equal(ids, []int{10, 11}) {
+				t.Errorf("GetMemberIds() = %v, want [10 11]", ids)
+			}
+		})
+	}
+}
+
+func TestGroupMemberDel(t *testing.T) {
+	ctx := context.Background()
+	_, client := newFakeRedis(t)
+	cache := NewGroupMember(client)
+
+	// 空群也标记为已加载
+	if loaded, err := cache.Load(ctx, 1, 0, nil); err != nil || !loaded {
+		t.Fatalf("Load() = %v, %v", loaded, err)
+	}
+
+	if ids, ok := cache.GetMemberIds(ctx, 1); !ok || len(ids) != 0 {
+		t.Fatalf("GetMemberIds() = %v, %v, want loaded empty set", ids, ok)
+	}
+
+	if err := cache.Del(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := cache.GetMemberIds(ctx, 1); ok {
+		t.Errorf("GetMemberIds() should miss after Del")
+	}
+
+	// 使用变动前的版本号加载的旧数据不再写入
+	if loaded, _ := cache.Load(ctx, 1, 0, []int{10}); loaded {
+		t.Errorf("Load() with stale version should be skipped")
+	}
+
+	version, _ := cache.Version(ctx, 1)
+	if version != 1 {
+		t.Errorf("Version() = %d, want 1", version)
+	}
+}
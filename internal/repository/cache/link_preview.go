@@ -0,0 +1,32 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gzydong/go-chat/internal/pkg/encrypt"
+	"github.com/redis/go-redis/v9"
+)
+
+// LinkPreviewStorage 链接预览缓存，抓取失败时缓存空值避免重复抓取
+type LinkPreviewStorage struct {
+	redis *redis.Client
+}
+
+func NewLinkPreviewStorage(rds *redis.Client) *LinkPreviewStorage {
+	return &LinkPreviewStorage{redis: rds}
+}
+
+// Get 获取链接预览，未缓存时返回 redis.Nil，空字符串表示抓取失败
+func (l *LinkPreviewStorage) Get(ctx context.Context, url string) (string, error) {
+	return l.redis.Get(ctx, l.name(url)).Result()
+}
+
+func (l *LinkPreviewStorage) Set(ctx context.Context, url string, value string, exp time.Duration) error {
+	return l.redis.Set(ctx, l.name(url), value, exp).Err()
+}
+
+func (l *LinkPreviewStorage) name(url string) string {
+	return fmt.Sprintf("im:message:link-preview:%s", encrypt.Md5(url))
+}
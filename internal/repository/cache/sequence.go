@@ -2,12 +2,32 @@ package cache
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
+// sequenceTakeScript 从号段中取号，号段不存在或剩余数量不足时返回空
+// 号段起始值可能超出 Lua 数值精度，脚本内仅对偏移量计算，起始值原样返回
+var sequenceTakeScript = redis.NewScript(`
+local segment = redis.call('HMGET', KEYS[1], 'base', 'off', 'size')
+if not segment[1] or not segment[2] or not segment[3] then
+	return false
+end
+
+local num = tonumber(ARGV[1])
+if tonumber(segment[2]) + num > tonumber(segment[3]) then
+	return false
+end
+
+return {segment[1], redis.call('HINCRBY', KEYS[1], 'off', num)}
+`)
+
+// Sequence 消息时序号段缓存
+// 号段由数据库分配，缓存仅负责在号段内发号，缓存丢失时重新向数据库申请号段
 type Sequence struct {
 	redis *redis.Client
 }
@@ -21,28 +41,50 @@ func (s *Sequence) Redis() *redis.Client {
 }
 
 func (s *Sequence) Name(seqType int32, sourceId int32) string {
-	return fmt.Sprintf("im:sequence:type_%d:%d", seqType, sourceId)
+	return fmt.Sprintf("im:sequence:segment:type_%d:%d", seqType, sourceId)
 }
 
-// Set 初始化发号器
-func (s *Sequence) Set(ctx context.Context, seqType int32, sourceId int32, value int64) error {
-	return s.redis.SetEx(ctx, s.Name(seqType, sourceId), value, 100*time.Hour).Err()
-}
+// Install 写入号段 (start, end]，其中前 used 个已被使用
+func (s *Sequence) Install(ctx context.Context, seqType int32, sourceId int32, start, used, end int64) error {
+	name := s.Name(seqType, sourceId)
 
-// Get 获取消息时序ID
-func (s *Sequence) Get(ctx context.Context, seqType int32, sourceId int32) int64 {
-	return s.redis.Incr(ctx, s.Name(seqType, sourceId)).Val()
+	pipe := s.redis.TxPipeline()
+	pipe.HSet(ctx, name, "base", start, "off", used, "size", end-start)
+	pipe.Expire(ctx, name, 100*time.Hour)
+	_, err := pipe.Exec(ctx)
+	return err
 }
 
-// BatchGet 批量获取消息时序ID
-func (s *Sequence) BatchGet(ctx context.Context, seqType int32, sourceId int32, num int64) []int64 {
+// Take 从号段中获取 num 个时序ID，返回最后一个ID
+// 号段不存在、剩余不足或号段低于 floor 时返回 false
+func (s *Sequence) Take(ctx context.Context, seqType int32, sourceId int32, num int64, floor int64) (int64, bool, error) {
+	res, err := sequenceTakeScript.Run(ctx, s.redis, []string{s.Name(seqType, sourceId)}, num).Slice()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return 0, false, nil
+		}
+
+		return 0, false, err
+	}
 
-	value := s.redis.IncrBy(ctx, s.Name(seqType, sourceId), num).Val()
+	if len(res) != 2 {
+		return 0, false, errors.New("invalid sequence segment")
+	}
+
+	base, err := strconv.ParseInt(fmt.Sprint(res[0]), 10, 64)
+	if err != nil {
+		return 0, false, err
+	}
+
+	off, ok := res[1].(int64)
+	if !ok {
+		return 0, false, errors.New("invalid sequence segment")
+	}
 
-	items := make([]int64, 0, num)
-	for i := num; i > 0; i-- {
-		items = append(items, value-i+1)
+	last := base + off
+	if last-num < floor {
+		return 0, false, nil
 	}
 
-	return items
+	return last, true, nil
 }
@@ -8,8 +8,24 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
-// 未读消息过期时间 - 14天
-const unreadExpireAt = 14 * 24 * time.Hour
+const (
+	unreadExpireAt = 14 * 24 * time.Hour // 未读消息过期时间 - 14天
+	unreadOnceTtl  = 24 * time.Hour      // 消息未读数累加标记的保留时间
+)
+
+// unreadIncrOnceScript 同一条消息的未读数只累加一次，KEYS[1] 为累加标记，其余为未读数缓存
+var unreadIncrOnceScript = redis.NewScript(`
+if not redis.call('SET', KEYS[1], 1, 'NX', 'EX', ARGV[1]) then
+	return 0
+end
+
+for i = 2, #KEYS do
+	redis.call('INCR', KEYS[i])
+	redis.call('EXPIRE', KEYS[i], ARGV[2])
+end
+
+return 1
+`)
 
 type UnreadStorage struct {
 	redis *redis.Client
@@ -39,6 +55,25 @@ func (u *UnreadStorage) PipeIncr(ctx context.Context, pipe redis.Pipeliner, uid,
 	pipe.Expire(ctx, name, unreadExpireAt)
 }
 
+// IncrOnce 按消息累加未读数，同一条消息重复投递时不会重复累加
+// @params token   消息唯一标识
+// @params mode    对话模式 1私信 2群聊
+// @params sender  发送者ID(群ID)
+// @params uids    接收消息的用户ID
+func (u *UnreadStorage) IncrOnce(ctx context.Context, token string, mode, sender int, uids ...int) error {
+	if len(uids) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(uids)+1)
+	keys = append(keys, fmt.Sprintf("im:unread:once:%s", token))
+	for _, uid := range uids {
+		keys = append(keys, u.name(uid, mode, sender))
+	}
+
+	return unreadIncrOnceScript.Run(ctx, u.redis, keys, int(unreadOnceTtl.Seconds()), int(unreadExpireAt.Seconds())).Err()
+}
+
 // Get 获取消息未读数
 // @params uid     用户ID
 // @params mode    对话模式 1私信 2群聊
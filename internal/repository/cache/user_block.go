@@ -0,0 +1,51 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// UserBlock 用户黑名单缓存，集合中固定包含成员 0 用于标识已加载
+type UserBlock struct {
+	redis *redis.Client
+}
+
+func NewUserBlock(redis *redis.Client) *UserBlock {
+	return &UserBlock{redis: redis}
+}
+
+func (u *UserBlock) IsBlocked(ctx context.Context, uid int, blockUserId int) bool {
+	return u.redis.SIsMember(ctx, u.name(uid), blockUserId).Val()
+}
+
+func (u *UserBlock) Set(ctx context.Context, uid int, blockUserIds []int) error {
+	members := make([]any, 0, len(blockUserIds)+1)
+	members = append(members, 0)
+	for _, id := range blockUserIds {
+		members = append(members, id)
+	}
+
+	_, err := u.redis.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, u.name(uid))
+		pipe.SAdd(ctx, u.name(uid), members...)
+		pipe.Expire(ctx, u.name(uid), 12*time.Hour)
+		return nil
+	})
+
+	return err
+}
+
+func (u *UserBlock) Del(ctx context.Context, uid int) {
+	u.redis.Del(ctx, u.name(uid))
+}
+
+func (u *UserBlock) Exist(ctx context.Context, uid int) bool {
+	return u.redis.Exists(ctx, u.name(uid)).Val() == 1
+}
+
+func (u *UserBlock) name(uid int) string {
+	return fmt.Sprintf("im:user:block:uid_%d", uid)
+}
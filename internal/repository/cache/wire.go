@@ -19,4 +19,8 @@ var ProviderSet = wire.NewSet(
 	NewUnreadStorage,
 	NewGroupApplyStorage,
 	NewUserClient,
+	NewLinkPreviewStorage,
+	NewUserBlock,
+	NewGroupMember,
+	NewGroupAntiSpam,
 )
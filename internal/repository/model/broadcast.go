@@ -0,0 +1,61 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+)
+
+const (
+	BroadcastStatusPending   = 1 // 待执行
+	BroadcastStatusRunning   = 2 // 执行中
+	BroadcastStatusCompleted = 3 // 已完成
+	BroadcastStatusCancelled = 4 // 已取消
+)
+
+const (
+	BroadcastRecipientPending   = 1 // 待发送
+	BroadcastRecipientSending   = 2 // 发送中
+	BroadcastRecipientSent      = 3 // 已发送
+	BroadcastRecipientFailed    = 4 // 发送失败
+	BroadcastRecipientCancelled = 5 // 已取消
+)
+
+// BroadcastJob 群发任务，由队列服务按限速逐个发送
+type BroadcastJob struct {
+	Id             int          `gorm:"column:id;primary_key;AUTO_INCREMENT" json:"id"`
+	AdminId        int          `gorm:"column:admin_id;" json:"admin_id"`               // 创建任务的管理员ID
+	SenderId       int          `gorm:"column:sender_id;" json:"sender_id"`             // 发送者用户ID
+	MsgType        int          `gorm:"column:msg_type;" json:"msg_type"`               // 消息类型
+	Extra          string       `gorm:"column:extra;" json:"extra"`                     // 消息内容
+	Rate           int          `gorm:"column:rate;" json:"rate"`                       // 每秒发送条数
+	Status         int          `gorm:"column:status;" json:"status"`                   // 任务状态[1:待执行;2:执行中;3:已完成;4:已取消;]
+	TotalCount     int          `gorm:"column:total_count;" json:"total_count"`         // 接收者总数
+	SentCount      int          `gorm:"column:sent_count;" json:"sent_count"`           // 发送成功数
+	FailedCount    int          `gorm:"column:failed_count;" json:"failed_count"`       // 发送失败数
+	CancelledCount int          `gorm:"column:cancelled_count;" json:"cancelled_count"` // 取消发送数
+	StartedAt      sql.NullTime `gorm:"column:started_at;" json:"started_at"`           // 开始时间
+	FinishedAt     sql.NullTime `gorm:"column:finished_at;" json:"finished_at"`         // 结束时间
+	CreatedAt      time.Time    `gorm:"column:created_at;" json:"created_at"`           // 创建时间
+	UpdatedAt      time.Time    `gorm:"column:updated_at;" json:"updated_at"`           // 更新时间
+}
+
+func (BroadcastJob) TableName() string {
+	return "broadcast_job"
+}
+
+// BroadcastRecipient 群发任务的接收者，MsgId 在创建任务时生成，重复投递时据此判断是否已发送
+type BroadcastRecipient struct {
+	Id        int       `gorm:"column:id;primary_key;AUTO_INCREMENT" json:"id"`
+	JobId     int       `gorm:"column:job_id;" json:"job_id"`         // 任务ID
+	TalkMode  int       `gorm:"column:talk_mode;" json:"talk_mode"`   // 对话类型[1:私信;2:群聊;]
+	ToFromId  int       `gorm:"column:to_from_id;" json:"to_from_id"` // 用户ID或群ID
+	MsgId     string    `gorm:"column:msg_id;" json:"msg_id"`         // 消息ID
+	Status    int       `gorm:"column:status;" json:"status"`         // 发送状态[1:待发送;2:发送中;3:已发送;4:发送失败;5:已取消;]
+	Error     string    `gorm:"column:error;" json:"error"`           // 失败原因
+	CreatedAt time.Time `gorm:"column:created_at;" json:"created_at"` // 创建时间
+	UpdatedAt time.Time `gorm:"column:updated_at;" json:"updated_at"` // 更新时间
+}
+
+func (BroadcastRecipient) TableName() string {
+	return "broadcast_recipient"
+}
@@ -5,31 +5,69 @@ import (
 )
 
 const (
-	GroupMemberMaxNum = 500 // 最大成员数量
+	GroupMemberMaxNum = 500 // 默认最大成员数量
 
 	GroupTypeNormal     = 1
 	GroupTypeEnterprise = 2
+
+	GroupJoinModeFree     = 1 // 自由加入
+	GroupJoinModeApproval = 2 // 需管理员审核
+	GroupJoinModeInvite   = 3 // 仅限邀请加入
+	GroupJoinModeQuestion = 4 // 回答问题后加入
 )
 
+// GroupMaxNumTiers 群成员上限可选档位，由后台管理员按需调整
+var GroupMaxNumTiers = []int{GroupMemberMaxNum, 2000, 5000, 10000}
+
 type Group struct {
-	Id        int       `gorm:"column:id;primary_key;AUTO_INCREMENT" json:"id"` // 群ID
-	Type      int       `gorm:"column:type;" json:"type"`                       // 群类型[1:普通群;2:企业群;]
-	CreatorId int       `gorm:"column:creator_id;" json:"creator_id"`           // 创建者ID(群主ID)
-	Name      string    `gorm:"column:name;" json:"name"`                       // 群名称
-	Profile   string    `gorm:"column:profile;" json:"profile"`                 // 群介绍
-	IsDismiss int       `gorm:"column:is_dismiss;" json:"is_dismiss"`           // 是否已解散[1:否;2:是;]
-	Avatar    string    `gorm:"column:avatar;" json:"avatar"`                   // 群头像
-	MaxNum    int       `gorm:"column:max_num;" json:"max_num"`                 // 最大群成员数量
-	IsOvert   int       `gorm:"column:is_overt;" json:"is_overt"`               // 是否公开可见[1:否;2:是;]
-	IsMute    int       `gorm:"column:is_mute;" json:"is_mute"`                 // 是否全员禁言 [1:否;2:是;] 提示:不包含群主或管理员
-	CreatedAt time.Time `gorm:"column:created_at;" json:"created_at"`           // 创建时间
-	UpdatedAt time.Time `gorm:"column:updated_at;" json:"updated_at"`           // 更新时间
+	Id                int        `gorm:"column:id;primary_key;AUTO_INCREMENT" json:"id"`       // 群ID
+	Type              int        `gorm:"column:type;" json:"type"`                             // 群类型[1:普通群;2:企业群;]
+	CreatorId         int        `gorm:"column:creator_id;" json:"creator_id"`                 // 创建者ID(群主ID)
+	Name              string     `gorm:"column:name;" json:"name"`                             // 群名称
+	Profile           string     `gorm:"column:profile;" json:"profile"`                       // 群介绍
+	IsDismiss         int        `gorm:"column:is_dismiss;" json:"is_dismiss"`                 // 是否已解散[1:否;2:是;]
+	Avatar            string     `gorm:"column:avatar;" json:"avatar"`                         // 群头像
+	MaxNum            int        `gorm:"column:max_num;" json:"max_num"`                       // 最大群成员数量
+	IsOvert           int        `gorm:"column:is_overt;" json:"is_overt"`                     // 是否公开可见[1:否;2:是;]
+	IsMute            int        `gorm:"column:is_mute;" json:"is_mute"`                       // 是否全员禁言 [1:否;2:是;] 提示:不包含群主或管理员
+	MuteExpireAt      *time.Time `gorm:"column:mute_expire_at;" json:"mute_expire_at"`         // 全员禁言截止时间，为空表示永久禁言
+	MuteReason        string     `gorm:"column:mute_reason;" json:"mute_reason"`               // 全员禁言原因
+	PermissionSetting string     `gorm:"column:permission_setting;" json:"permission_setting"` // 群权限开放范围设置(JSON)，为空时使用默认范围
+	JoinMode          int        `gorm:"column:join_mode;" json:"join_mode"`                   // 入群方式[1:自由加入;2:需审核;3:仅限邀请;4:回答问题;]
+	JoinQuestion      string     `gorm:"column:join_question;" json:"join_question"`           // 入群问题
+	JoinAnswer        string     `gorm:"column:join_answer;" json:"-"`                         // 入群问题答案
+	AntiSpamSetting   string     `gorm:"column:anti_spam_setting;" json:"anti_spam_setting"`   // 防刷屏设置(JSON)，为空时不限制
+	CreatedAt         time.Time  `gorm:"column:created_at;" json:"created_at"`                 // 创建时间
+	UpdatedAt         time.Time  `gorm:"column:updated_at;" json:"updated_at"`                 // 更新时间
 }
 
 func (Group) TableName() string {
 	return "group"
 }
 
+// IsMuted 判断当前是否处于全员禁言状态，已到期但尚未解除的禁言按未禁言处理
+func (g *Group) IsMuted(now time.Time) bool {
+	return IsMuteActive(g.IsMute, g.MuteExpireAt, now)
+}
+
+// MemberLimit 获取群成员数量上限，未设置时使用默认上限
+func (g *Group) MemberLimit() int {
+	if g.MaxNum <= 0 {
+		return GroupMemberMaxNum
+	}
+
+	return g.MaxNum
+}
+
+// GetJoinMode 获取入群方式，未设置时需管理员审核
+func (g *Group) GetJoinMode() int {
+	if g.JoinMode < GroupJoinModeFree || g.JoinMode > GroupJoinModeQuestion {
+		return GroupJoinModeApproval
+	}
+
+	return g.JoinMode
+}
+
 type GroupItem struct {
 	Id        int    `json:"id"`
 	GroupName string `json:"group_name"`
@@ -0,0 +1,42 @@
+package model
+
+import (
+	"github.com/gzydong/go-chat/internal/pkg/jsonutil"
+)
+
+const (
+	GroupSlowModeMax      = 3600  // 慢速模式最大间隔(秒)
+	GroupMsgMaxLength     = 10000 // 消息长度上限可设置的最大值
+	GroupFloodWindowMax   = 600   // 刷屏统计时间窗口最大值(秒)
+	GroupFloodMuteDefault = 600   // 触发刷屏后默认禁言时长(秒)
+)
+
+// GroupAntiSpam 群防刷屏设置，仅对普通成员生效
+type GroupAntiSpam struct {
+	SlowMode    int  `json:"slow_mode"`    // 慢速模式，每个成员两条消息的最小间隔(秒)，为 0 时关闭
+	MaxLength   int  `json:"max_length"`   // 文本消息最大长度，为 0 时不限制
+	BlockLink   bool `json:"block_link"`   // 禁止发送链接
+	BlockFile   bool `json:"block_file"`   // 禁止发送文件
+	FloodLimit  int  `json:"flood_limit"`  // 刷屏阈值，统计窗口内最多可发送的消息数，为 0 时关闭
+	FloodWindow int  `json:"flood_window"` // 刷屏统计时间窗口(秒)
+	FloodMute   int  `json:"flood_mute"`   // 触发刷屏后自动禁言时长(秒)
+}
+
+// IsEnabled 判断是否开启了任一防刷屏限制
+func (g *GroupAntiSpam) IsEnabled() bool {
+	return g.SlowMode > 0 || g.MaxLength > 0 || g.BlockLink || g.BlockFile || g.FloodLimit > 0
+}
+
+// AntiSpam 获取群防刷屏设置，未设置时不做任何限制
+func (g *Group) AntiSpam() *GroupAntiSpam {
+	setting := &GroupAntiSpam{}
+	if g.AntiSpamSetting == "" {
+		return setting
+	}
+
+	if err := jsonutil.Unmarshal(g.AntiSpamSetting, setting); err != nil {
+		return &GroupAntiSpam{}
+	}
+
+	return setting
+}
@@ -0,0 +1,39 @@
+package model
+
+import "time"
+
+const (
+	GroupInviteLinkStatusNormal  = 1 // 有效
+	GroupInviteLinkStatusRevoked = 2 // 已撤销
+)
+
+// GroupInviteLink 群邀请链接，二维码与分享链接均基于链接标识
+type GroupInviteLink struct {
+	Id         int        `gorm:"column:id;primary_key;AUTO_INCREMENT" json:"id"` // 自增ID
+	GroupId    int        `gorm:"column:group_id;" json:"group_id"`               // 群组ID
+	Token      string     `gorm:"column:token;" json:"token"`                     // 邀请链接标识
+	CreatorId  int        `gorm:"column:creator_id;" json:"creator_id"`           // 创建者ID
+	MaxUsage   int        `gorm:"column:max_usage;" json:"max_usage"`             // 最大使用次数，为 0 时不限制
+	UsageCount int        `gorm:"column:usage_count;" json:"usage_count"`         // 已使用次数
+	Status     int        `gorm:"column:status;" json:"status"`                   // 状态[1:有效;2:已撤销;]
+	ExpireAt   *time.Time `gorm:"column:expire_at;" json:"expire_at"`             // 过期时间，为空表示永久有效
+	CreatedAt  time.Time  `gorm:"column:created_at;" json:"created_at"`           // 创建时间
+	UpdatedAt  time.Time  `gorm:"column:updated_at;" json:"updated_at"`           // 更新时间
+}
+
+func (GroupInviteLink) TableName() string {
+	return "group_invite_link"
+}
+
+// IsAvailable 判断邀请链接当前是否可用
+func (g *GroupInviteLink) IsAvailable(now time.Time) bool {
+	if g.Status != GroupInviteLinkStatusNormal {
+		return false
+	}
+
+	if g.ExpireAt != nil && !now.Before(*g.ExpireAt) {
+		return false
+	}
+
+	return g.MaxUsage == 0 || g.UsageCount < g.MaxUsage
+}
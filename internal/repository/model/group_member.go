@@ -11,30 +11,48 @@ const (
 )
 
 type GroupMember struct {
-	Id        int       `gorm:"column:id;primary_key;AUTO_INCREMENT" json:"id"` // 自增ID
-	GroupId   int       `gorm:"column:group_id;" json:"group_id"`               // 群组ID
-	UserId    int       `gorm:"column:user_id;" json:"user_id"`                 // 用户ID
-	Leader    int       `gorm:"column:leader;" json:"leader"`                   // 成员属性[1:群主;2:管理员;3:普通成员;]
-	UserCard  string    `gorm:"column:user_card;" json:"user_card"`             // 群名片
-	IsQuit    int       `gorm:"column:is_quit;" json:"is_quit"`                 // 是否退群[1:否;2:是;]
-	IsMute    int       `gorm:"column:is_mute;" json:"is_mute"`                 // 是否禁言[1:否;2:是;]
-	JoinTime  time.Time `gorm:"column:join_time;" json:"join_time"`             // 入群时间
-	CreatedAt time.Time `gorm:"column:created_at;" json:"created_at"`           // 创建时间
-	UpdatedAt time.Time `gorm:"column:updated_at;" json:"updated_at"`           // 更新时间
+	Id           int        `gorm:"column:id;primary_key;AUTO_INCREMENT" json:"id"` // 自增ID
+	GroupId      int        `gorm:"column:group_id;" json:"group_id"`               // 群组ID
+	UserId       int        `gorm:"column:user_id;" json:"user_id"`                 // 用户ID
+	Leader       int        `gorm:"column:leader;" json:"leader"`                   // 成员属性[1:群主;2:管理员;3:普通成员;]
+	UserCard     string     `gorm:"column:user_card;" json:"user_card"`             // 群名片
+	IsQuit       int        `gorm:"column:is_quit;" json:"is_quit"`                 // 是否退群[1:否;2:是;]
+	IsMute       int        `gorm:"column:is_mute;" json:"is_mute"`                 // 是否禁言[1:否;2:是;]
+	MuteExpireAt *time.Time `gorm:"column:mute_expire_at;" json:"mute_expire_at"`   // 禁言截止时间，为空表示永久禁言
+	MuteReason   string     `gorm:"column:mute_reason;" json:"mute_reason"`         // 禁言原因
+	Permissions  string     `gorm:"column:permissions;" json:"permissions"`         // 管理员权限项(JSON)，为空时拥有全部权限
+	JoinTime     time.Time  `gorm:"column:join_time;" json:"join_time"`             // 入群时间
+	CreatedAt    time.Time  `gorm:"column:created_at;" json:"created_at"`           // 创建时间
+	UpdatedAt    time.Time  `gorm:"column:updated_at;" json:"updated_at"`           // 更新时间
 }
 
 func (GroupMember) TableName() string {
 	return "group_member"
 }
 
+// IsMuted 判断成员当前是否处于禁言状态，已到期但尚未解除的禁言按未禁言处理
+func (g *GroupMember) IsMuted(now time.Time) bool {
+	return IsMuteActive(g.IsMute, g.MuteExpireAt, now)
+}
+
+// IsMuteActive 判断禁言是否生效，禁言到期后由定时任务统一解除，期间按截止时间判断避免每次发言都写库
+func IsMuteActive(isMute int, expireAt *time.Time, now time.Time) bool {
+	if isMute != Yes {
+		return false
+	}
+
+	return expireAt == nil || now.Before(*expireAt)
+}
+
 type MemberItem struct {
-	Id       string `json:"id"`
-	UserId   int    `json:"user_id"`
-	Avatar   string `json:"avatar"`
-	Nickname string `json:"nickname"`
-	Gender   int    `json:"gender"`
-	Motto    string `json:"motto"`
-	Leader   int    `json:"leader"`
-	IsMute   int    `json:"is_mute"`
-	UserCard string `json:"user_card"`
+	Id           string     `json:"id"`
+	UserId       int        `json:"user_id"`
+	Avatar       string     `json:"avatar"`
+	Nickname     string     `json:"nickname"`
+	Gender       int        `json:"gender"`
+	Motto        string     `json:"motto"`
+	Leader       int        `json:"leader"`
+	IsMute       int        `json:"is_mute"`
+	UserCard     string     `json:"user_card"`
+	MuteExpireAt *time.Time `json:"mute_expire_at"`
 }
@@ -14,9 +14,12 @@ type GroupNotice struct {
 	GroupId      int       `gorm:"column:group_id;" json:"group_id"`               // 群组ID
 	CreatorId    int       `gorm:"column:creator_id;" json:"creator_id"`           // 创建者用户ID
 	ModifyId     int       `gorm:"column:modify_id;" json:"modify_id"`             // 创建者用户ID
+	Title        string    `gorm:"column:title;" json:"title"`                     // 公告标题
 	Content      string    `gorm:"column:content;" json:"content"`                 // 公告内容
-	ConfirmUsers string    `gorm:"column:confirm_users" json:"confirm_users"`      // 已确认成员
-	IsConfirm    int       `gorm:"column:is_confirm;" json:"is_confirm"`           // 是否需群成员确认公告[1:否;2:是;]
+	ConfirmUsers string    `gorm:"column:confirm_users" json:"confirm_users"`      // 已确认成员(已废弃，确认记录见 GroupNoticeConfirm)
+	IsConfirm    int       `gorm:"column:is_confirm;" json:"is_confirm"`           // 是否需群成员确认公告[1:是;2:否;]
+	IsTop        int       `gorm:"column:is_top;" json:"is_top"`                   // 是否置顶[1:是;2:否;]
+	IsDelete     int       `gorm:"column:is_delete;" json:"is_delete"`             // 是否删除[0:否;2:是;]
 	CreatedAt    time.Time `gorm:"column:created_at;" json:"created_at"`           // 创建时间
 	UpdatedAt    time.Time `gorm:"column:updated_at;" json:"updated_at"`           // 更新时间
 }
@@ -38,3 +41,16 @@ type SearchNoticeItem struct {
 	Avatar       string    `json:"avatar"`
 	Nickname     string    `json:"nickname"`
 }
+
+// GroupNoticeConfirm 群公告确认记录
+type GroupNoticeConfirm struct {
+	Id        int       `gorm:"column:id;primary_key;AUTO_INCREMENT" json:"id"` // 自增ID
+	NoticeId  int       `gorm:"column:notice_id;" json:"notice_id"`             // 公告ID
+	GroupId   int       `gorm:"column:group_id;" json:"group_id"`               // 群组ID
+	UserId    int       `gorm:"column:user_id;" json:"user_id"`                 // 确认用户ID
+	CreatedAt time.Time `gorm:"column:created_at;" json:"created_at"`           // 确认时间
+}
+
+func (GroupNoticeConfirm) TableName() string {
+	return "group_notice_confirm"
+}
@@ -0,0 +1,112 @@
+package model
+
+import (
+	"slices"
+
+	"github.com/gzydong/go-chat/internal/pkg/jsonutil"
+)
+
+// 群权限项
+const (
+	GroupPermInvite      = "invite"       // 邀请成员
+	GroupPermEditProfile = "edit_profile" // 修改群资料
+	GroupPermNotice      = "notice"       // 发布群公告
+	GroupPermPin         = "pin"          // 置顶消息
+	GroupPermMentionAll  = "mention_all"  // @所有人
+	GroupPermVote        = "vote"         // 发起投票
+	GroupPermRobot       = "robot"        // 管理群机器人
+)
+
+// 群权限开放范围
+const (
+	GroupPermScopeMember = 1 // 全部成员
+	GroupPermScopeAdmin  = 2 // 群主及管理员
+	GroupPermScopeOwner  = 3 // 仅群主
+)
+
+// GroupPermissions 全部群权限项
+var GroupPermissions = []string{
+	GroupPermInvite,
+	GroupPermEditProfile,
+	GroupPermNotice,
+	GroupPermPin,
+	GroupPermMentionAll,
+	GroupPermVote,
+	GroupPermRobot,
+}
+
+// GroupPermDefaultScopes 群权限默认开放范围，群未单独设置时使用
+var GroupPermDefaultScopes = map[string]int{
+	GroupPermInvite:      GroupPermScopeMember,
+	GroupPermEditProfile: GroupPermScopeOwner,
+	GroupPermNotice:      GroupPermScopeMember,
+	GroupPermPin:         GroupPermScopeAdmin,
+	GroupPermMentionAll:  GroupPermScopeAdmin,
+	GroupPermVote:        GroupPermScopeMember,
+	GroupPermRobot:       GroupPermScopeAdmin,
+}
+
+// PermissionScopes 获取群权限开放范围，未设置的权限项使用默认范围
+func (g *Group) PermissionScopes() map[string]int {
+	scopes := make(map[string]int, len(GroupPermDefaultScopes))
+	for perm, scope := range GroupPermDefaultScopes {
+		scopes[perm] = scope
+	}
+
+	if g.PermissionSetting == "" {
+		return scopes
+	}
+
+	var setting map[string]int
+	if err := jsonutil.Unmarshal(g.PermissionSetting, &setting); err != nil {
+		return scopes
+	}
+
+	for perm, scope := range setting {
+		if _, ok := scopes[perm]; ok && scope >= GroupPermScopeMember && scope <= GroupPermScopeOwner {
+			scopes[perm] = scope
+		}
+	}
+
+	return scopes
+}
+
+// AdminPermissions 获取管理员拥有的权限项，未单独设置时拥有全部权限
+func (g *GroupMember) AdminPermissions() []string {
+	if g.Permissions == "" {
+		return GroupPermissions
+	}
+
+	var perms []string
+	if err := jsonutil.Unmarshal(g.Permissions, &perms); err != nil {
+		return GroupPermissions
+	}
+
+	return perms
+}
+
+// IsGroupPermitted 判断群成员是否拥有指定权限
+// 群主拥有全部权限，对全部成员开放的权限所有成员均可使用，仅对管理员开放的权限需管理员未被收回该权限
+func IsGroupPermitted(group *Group, member *GroupMember, perm string) bool {
+	if member == nil || member.IsQuit == Yes {
+		return false
+	}
+
+	if member.Leader == GroupMemberLeaderOwner {
+		return true
+	}
+
+	scope, ok := group.PermissionScopes()[perm]
+	if !ok {
+		return false
+	}
+
+	switch scope {
+	case GroupPermScopeMember:
+		return true
+	case GroupPermScopeAdmin:
+		return member.Leader == GroupMemberLeaderAdmin && slices.Contains(member.AdminPermissions(), perm)
+	}
+
+	return false
+}
@@ -14,6 +14,7 @@ type GroupRobot struct {
 	RobotName   string    `gorm:"column:robot_name;not null" json:"robot_name"`                        // 机器人名称
 	WebhookUrl  string    `gorm:"column:webhook_url;type:varchar(255);uniqueIndex" json:"webhook_url"` // Webhook URL
 	Secret      string    `gorm:"column:secret" json:"secret"`                                         // 签名密钥
+	CallbackUrl string    `gorm:"column:callback_url;type:varchar(255)" json:"callback_url"`           // 卡片消息按钮点击回调地址
 	Description string    `gorm:"column:description" json:"description"`                               // 描述
 	Status      int       `gorm:"column:status;default:1" json:"status"`                               // 状态 0:禁用 1:活跃
 	CreatorId   int       `gorm:"column:creator_id;index" json:"creator_id"`                           // 创建者ID
@@ -8,27 +8,50 @@ const (
 
 	VoteStatusWait   = 1 // 等待中
 	VoteStatusFinish = 2 // 已结束
+
+	VoteResultVisibleAll       = 1 // 投票结果始终可见
+	VoteResultVisibleAfterVote = 2 // 投票后或投票结束后可见
 )
 
 type GroupVote struct {
-	Id           int       `gorm:"column:id;primary_key;AUTO_INCREMENT" json:"id"` // 投票ID
-	GroupId      int       `gorm:"column:group_id;" json:"group_id"`               // 群组ID
-	UserId       int       `gorm:"column:user_id;" json:"user_id"`                 // 用户ID
-	Title        string    `gorm:"column:title;" json:"title"`                     // 投票标题
-	AnswerMode   int       `gorm:"column:answer_mode;" json:"answer_mode"`         // 答题模式[1:单选;1:多选;]
-	AnswerOption string    `gorm:"column:answer_option;" json:"answer_option"`     // 答题选项
-	AnswerNum    int       `gorm:"column:answer_num;" json:"answer_num"`           // 应答人数
-	AnsweredNum  int       `gorm:"column:answered_num;" json:"answered_num"`       // 已答人数
-	IsAnonymous  int       `gorm:"column:is_anonymous;" json:"is_anonymous"`       // 匿名投票[1:否;2:是;]
-	Status       int       `gorm:"column:status;" json:"status"`                   // 投票状态[1:投票中;2:已完成;]
-	CreatedAt    time.Time `gorm:"column:created_at;" json:"created_at"`           // 创建时间
-	UpdatedAt    time.Time `gorm:"column:updated_at;" json:"updated_at"`           // 更新时间
+	Id            int        `gorm:"column:id;primary_key;AUTO_INCREMENT" json:"id"` // 投票ID
+	GroupId       int        `gorm:"column:group_id;" json:"group_id"`               // 群组ID
+	UserId        int        `gorm:"column:user_id;" json:"user_id"`                 // 用户ID
+	Title         string     `gorm:"column:title;" json:"title"`                     // 投票标题
+	AnswerMode    int        `gorm:"column:answer_mode;" json:"answer_mode"`         // 答题模式[1:单选;1:多选;]
+	AnswerOption  string     `gorm:"column:answer_option;" json:"answer_option"`     // 答题选项
+	AnswerNum     int        `gorm:"column:answer_num;" json:"answer_num"`           // 应答人数
+	AnsweredNum   int        `gorm:"column:answered_num;" json:"answered_num"`       // 已答人数
+	IsAnonymous   int        `gorm:"column:is_anonymous;" json:"is_anonymous"`       // 匿名投票[1:否;2:是;]
+	Status        int        `gorm:"column:status;" json:"status"`                   // 投票状态[1:投票中;2:已完成;]
+	ResultVisible int        `gorm:"column:result_visible;" json:"result_visible"`   // 结果可见范围[1:始终可见;2:投票后或结束后可见;]
+	Deadline      *time.Time `gorm:"column:deadline;" json:"deadline"`               // 截止时间，为空表示不限时
+	CreatedAt     time.Time  `gorm:"column:created_at;" json:"created_at"`           // 创建时间
+	UpdatedAt     time.Time  `gorm:"column:updated_at;" json:"updated_at"`           // 更新时间
 }
 
 func (GroupVote) TableName() string {
 	return "group_vote"
 }
 
+// IsClosed 判断投票是否已结束，到达截止时间但尚未被定时任务关闭的投票同样视为已结束
+func (g *GroupVote) IsClosed(now time.Time) bool {
+	if g.Status == VoteStatusFinish {
+		return true
+	}
+
+	return g.Deadline != nil && !now.Before(*g.Deadline)
+}
+
+// IsResultVisible 判断投票结果对用户是否可见
+func (g *GroupVote) IsResultVisible(isSubmit bool, now time.Time) bool {
+	if g.ResultVisible != VoteResultVisibleAfterVote {
+		return true
+	}
+
+	return isSubmit || g.IsClosed(now)
+}
+
 type GroupVoteOption struct {
 	Key   string `json:"key"`
 	Value string `json:"value"`
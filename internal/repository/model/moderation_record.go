@@ -0,0 +1,46 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+)
+
+const (
+	ModerationSceneMessage      = "message"       // 文本消息
+	ModerationSceneNickname     = "nickname"      // 用户昵称
+	ModerationSceneGroupName    = "group_name"    // 群名称
+	ModerationSceneGroupNotice  = "group_notice"  // 群公告
+	ModerationSceneArticleTitle = "article_title" // 笔记标题
+)
+
+const (
+	ModerationStatusPending  = 1 // 待审核
+	ModerationStatusApproved = 2 // 审核通过
+	ModerationStatusRejected = 3 // 审核驳回
+)
+
+// ModerationRecord 内容审核记录
+// 消息场景 TargetId 为空，通过 TalkMode、ToFromId、MsgId 定位消息；其它场景 TargetId 为用户ID、群ID、公告ID或笔记ID
+type ModerationRecord struct {
+	Id           int          `gorm:"column:id;primary_key;AUTO_INCREMENT" json:"id"`
+	Scene        string       `gorm:"column:scene;" json:"scene"`                 // 内容场景
+	UserId       int          `gorm:"column:user_id;" json:"user_id"`             // 提交内容的用户ID
+	TargetId     int          `gorm:"column:target_id;" json:"target_id"`         // 内容所属对象ID
+	TalkMode     int          `gorm:"column:talk_mode;" json:"talk_mode"`         // 对话类型[1:私信;2:群聊;]
+	ToFromId     int          `gorm:"column:to_from_id;" json:"to_from_id"`       // 接收者ID或群ID
+	MsgId        string       `gorm:"column:msg_id;" json:"msg_id"`               // 消息ID
+	Content      string       `gorm:"column:content;" json:"content"`             // 原始内容
+	MaskContent  string       `gorm:"column:mask_content;" json:"mask_content"`   // 屏蔽敏感词后的内容，驳回时替换原始内容
+	Words        string       `gorm:"column:words;" json:"words"`                 // 命中的敏感词
+	Label        string       `gorm:"column:label;" json:"label"`                 // 外部审核标签
+	Status       int          `gorm:"column:status;" json:"status"`               // 审核状态[1:待审核;2:通过;3:驳回;]
+	ReviewerId   int          `gorm:"column:reviewer_id;" json:"reviewer_id"`     // 审核管理员ID
+	ReviewRemark string       `gorm:"column:review_remark;" json:"review_remark"` // 审核备注
+	ReviewedAt   sql.NullTime `gorm:"column:reviewed_at;" json:"reviewed_at"`     // 审核时间
+	CreatedAt    time.Time    `gorm:"column:created_at;" json:"created_at"`       // 创建时间
+	UpdatedAt    time.Time    `gorm:"column:updated_at;" json:"updated_at"`       // 更新时间
+}
+
+func (ModerationRecord) TableName() string {
+	return "moderation_record"
+}
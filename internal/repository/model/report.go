@@ -0,0 +1,66 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+)
+
+const (
+	ReportTargetMessage = 1 // 举报消息
+	ReportTargetUser    = 2 // 举报用户
+	ReportTargetGroup   = 3 // 举报群聊
+)
+
+const (
+	ReportStatusPending = 1 // 待处理
+	ReportStatusHandled = 2 // 已处理
+	ReportStatusIgnored = 3 // 已忽略
+)
+
+const (
+	ReportActionIgnore        = "ignore"         // 忽略举报
+	ReportActionRevokeMessage = "revoke_message" // 撤回被举报的消息
+	ReportActionMuteMember    = "mute_member"    // 群内禁言被举报用户
+	ReportActionDisableUser   = "disable_user"   // 停用被举报用户
+	ReportActionDismissGroup  = "dismiss_group"  // 解散被举报群聊
+)
+
+// Report 举报记录
+// 举报消息时 TargetId 为消息发送者，举报用户或群聊时 TargetId 为用户ID或群ID
+type Report struct {
+	Id          int          `gorm:"column:id;primary_key;AUTO_INCREMENT" json:"id"`
+	UserId      int          `gorm:"column:user_id;" json:"user_id"`         // 举报人ID
+	TargetType  int          `gorm:"column:target_type;" json:"target_type"` // 举报对象[1:消息;2:用户;3:群聊;]
+	TargetId    int          `gorm:"column:target_id;" json:"target_id"`     // 被举报的用户ID或群ID
+	TalkMode    int          `gorm:"column:talk_mode;" json:"talk_mode"`     // 证据消息所在的对话类型[1:私信;2:群聊;]
+	ToFromId    int          `gorm:"column:to_from_id;" json:"to_from_id"`   // 证据消息所在的会话
+	MsgIds      string       `gorm:"column:msg_ids;" json:"msg_ids"`         // 证据消息ID
+	Reason      int          `gorm:"column:reason;" json:"reason"`           // 举报原因
+	Description string       `gorm:"column:description;" json:"description"` // 举报说明
+	Images      string       `gorm:"column:images;" json:"images"`           // 举报截图
+	Evidence    string       `gorm:"column:evidence;" json:"evidence"`       // 证据消息快照
+	Status      int          `gorm:"column:status;" json:"status"`           // 处理状态[1:待处理;2:已处理;3:已忽略;]
+	HandlerId   int          `gorm:"column:handler_id;" json:"handler_id"`   // 处理管理员ID
+	HandledAt   sql.NullTime `gorm:"column:handled_at;" json:"handled_at"`   // 处理时间
+	CreatedAt   time.Time    `gorm:"column:created_at;" json:"created_at"`   // 创建时间
+	UpdatedAt   time.Time    `gorm:"column:updated_at;" json:"updated_at"`   // 更新时间
+}
+
+func (Report) TableName() string {
+	return "report"
+}
+
+// ReportAudit 举报处理审计记录，每个处理动作一条
+type ReportAudit struct {
+	Id        int       `gorm:"column:id;primary_key;AUTO_INCREMENT" json:"id"`
+	ReportId  int       `gorm:"column:report_id;" json:"report_id"`   // 举报ID
+	AdminId   int       `gorm:"column:admin_id;" json:"admin_id"`     // 处理管理员ID
+	Action    string    `gorm:"column:action;" json:"action"`         // 处理动作
+	Remark    string    `gorm:"column:remark;" json:"remark"`         // 处理备注
+	Detail    string    `gorm:"column:detail;" json:"detail"`         // 处理结果
+	CreatedAt time.Time `gorm:"column:created_at;" json:"created_at"` // 处理时间
+}
+
+func (ReportAudit) TableName() string {
+	return "report_audit"
+}
@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+const (
+	SensitiveActionMask   = 1 // 替换为 *
+	SensitiveActionReview = 2 // 放行并提交人工审核
+	SensitiveActionReject = 3 // 拒绝提交
+)
+
+// SensitiveWord 敏感词
+type SensitiveWord struct {
+	Id        int       `gorm:"column:id;primary_key;AUTO_INCREMENT" json:"id"`
+	Word      string    `gorm:"column:word;" json:"word"`             // 敏感词
+	Action    int       `gorm:"column:action;" json:"action"`         // 命中后的处理方式[1:替换;2:审核;3:拒绝;]
+	Status    int       `gorm:"column:status;" json:"status"`         // 状态[1:启用;2:停用;]
+	CreatedAt time.Time `gorm:"column:created_at;" json:"created_at"` // 创建时间
+	UpdatedAt time.Time `gorm:"column:updated_at;" json:"updated_at"` // 更新时间
+}
+
+func (SensitiveWord) TableName() string {
+	return "sensitive_word"
+}
@@ -10,7 +10,7 @@ type Sequence struct {
 	SourceId  int32     `gorm:"column:source_id;" json:"source_id"`             // 来源ID  type=1:用户ID，type=2:群ID
 	CurSeq    int64     `gorm:"column:cur_seq;" json:"cur_seq"`                 // 当前分配ID
 	MaxSeq    int64     `gorm:"column:max_seq;" json:"max_seq"`                 // 可发放最大ID
-	Step      int64     `gorm:"column:step;" json:"step"`                       // 可发放最大ID
+	Step      int64     `gorm:"column:step;" json:"step"`                       // 号段步长
 	CreatedAt time.Time `gorm:"column:created_at;" json:"created_at"`           // 创建时间
 	UpdatedAt time.Time `gorm:"column:updated_at;" json:"updated_at"`           // 更新时间
 }
@@ -0,0 +1,42 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+)
+
+const (
+	TalkExportStatusPending   = 1 // 待导出
+	TalkExportStatusRunning   = 2 // 导出中
+	TalkExportStatusCompleted = 3 // 已完成
+	TalkExportStatusFailed    = 4 // 导出失败
+)
+
+const (
+	TalkExportRequesterUser  = 1 // 用户导出自己的聊天记录
+	TalkExportRequesterAdmin = 2 // 管理员导出指定用户的聊天记录
+)
+
+// TalkExport 聊天记录导出任务，ToFromId 为 0 时导出用户在时间范围内的全部会话
+type TalkExport struct {
+	Id            int          `gorm:"column:id;primary_key;AUTO_INCREMENT" json:"id"`
+	RequesterType int          `gorm:"column:requester_type;" json:"requester_type"` // 申请人类型[1:用户;2:管理员;]
+	RequesterId   int          `gorm:"column:requester_id;" json:"requester_id"`     // 申请人ID
+	UserId        int          `gorm:"column:user_id;" json:"user_id"`               // 导出的用户ID
+	TalkMode      int          `gorm:"column:talk_mode;" json:"talk_mode"`           // 对话类型[0:全部;1:私信;2:群聊;]
+	ToFromId      int          `gorm:"column:to_from_id;" json:"to_from_id"`         // 好友ID或群ID，为0时导出全部会话
+	StartTime     time.Time    `gorm:"column:start_time;" json:"start_time"`         // 开始时间
+	EndTime       time.Time    `gorm:"column:end_time;" json:"end_time"`             // 结束时间
+	Status        int          `gorm:"column:status;" json:"status"`                 // 导出状态[1:待导出;2:导出中;3:已完成;4:导出失败;]
+	RecordCount   int          `gorm:"column:record_count;" json:"record_count"`     // 导出的消息数
+	FilePath      string       `gorm:"column:file_path;" json:"file_path"`           // 私有桶中的文件路径
+	FileSize      int64        `gorm:"column:file_size;" json:"file_size"`           // 文件大小
+	Error         string       `gorm:"column:error;" json:"error"`                   // 失败原因
+	ExpiredAt     sql.NullTime `gorm:"column:expired_at;" json:"expired_at"`         // 文件过期时间
+	CreatedAt     time.Time    `gorm:"column:created_at;" json:"created_at"`         // 创建时间
+	UpdatedAt     time.Time    `gorm:"column:updated_at;" json:"updated_at"`         // 更新时间
+}
+
+func (TalkExport) TableName() string {
+	return "talk_export"
+}
@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// TalkFavorite 用户收藏的消息，保存消息内容快照，原消息撤回或删除后仍可查看
+type TalkFavorite struct {
+	Id        int       `gorm:"column:id;primary_key;AUTO_INCREMENT" json:"id"`
+	UserId    int       `gorm:"column:user_id;" json:"user_id"`       // 收藏的用户ID
+	MsgType   int       `gorm:"column:msg_type;" json:"msg_type"`     // 消息类型
+	Extra     string    `gorm:"column:extra;" json:"extra"`           // 消息内容快照
+	Content   string    `gorm:"column:content;" json:"content"`       // 可检索文本
+	Tags      string    `gorm:"column:tags;" json:"tags"`             // 标签，多个以英文逗号分隔
+	TalkMode  int       `gorm:"column:talk_mode;" json:"talk_mode"`   // 来源对话类型[1:私信;2:群聊;]
+	ToFromId  int       `gorm:"column:to_from_id;" json:"to_from_id"` // 来源好友ID或群ID
+	MsgId     string    `gorm:"column:msg_id;" json:"msg_id"`         // 来源消息ID
+	FromId    int       `gorm:"column:from_id;" json:"from_id"`       // 来源消息发送者ID
+	SendTime  time.Time `gorm:"column:send_time;" json:"send_time"`   // 来源消息发送时间
+	CreatedAt time.Time `gorm:"column:created_at;" json:"created_at"` // 收藏时间
+	UpdatedAt time.Time `gorm:"column:updated_at;" json:"updated_at"` // 更新时间
+}
+
+func (TalkFavorite) TableName() string {
+	return "talk_favorite"
+}
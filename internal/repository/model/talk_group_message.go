@@ -12,6 +12,7 @@ type TalkGroupMessage struct {
 	IsRevoked int       `gorm:"column:is_revoked;" json:"is_revoked"`           // 是否撤回[1:否;2:是;]
 	Extra     string    `gorm:"column:extra;" json:"extra"`                     // 消息扩展字段
 	Quote     string    `gorm:"column:quote;" json:"quote"`                     // 引用消息
+	RootMsgId string    `gorm:"column:root_msg_id;" json:"root_msg_id"`         // 话题根消息ID(为空表示群主时间线消息)
 	SendTime  time.Time `gorm:"column:send_time;" json:"send_time"`             // 发送时间
 	CreatedAt time.Time `gorm:"column:created_at;" json:"created_at"`           // 创建时间
 	UpdatedAt time.Time `gorm:"column:updated_at;" json:"updated_at"`           // 更新时间
@@ -0,0 +1,33 @@
+package model
+
+import "time"
+
+// TalkGroupThread 群消息话题(以某条群消息为根消息的回复串)
+type TalkGroupThread struct {
+	Id              int       `gorm:"column:id;primary_key;AUTO_INCREMENT" json:"id"`       // 话题ID
+	GroupId         int       `gorm:"column:group_id;" json:"group_id"`                     // 群组ID
+	RootMsgId       string    `gorm:"column:root_msg_id;" json:"root_msg_id"`               // 根消息ID
+	ReplyCount      int       `gorm:"column:reply_count;" json:"reply_count"`               // 回复总数
+	LastReplyMsgId  string    `gorm:"column:last_reply_msg_id;" json:"last_reply_msg_id"`   // 最后一条回复消息ID
+	LastReplyUserId int       `gorm:"column:last_reply_user_id;" json:"last_reply_user_id"` // 最后一条回复的用户ID
+	LastReplyAt     time.Time `gorm:"column:last_reply_at;" json:"last_reply_at"`           // 最后回复时间
+	CreatedAt       time.Time `gorm:"column:created_at;" json:"created_at"`                 // 创建时间
+	UpdatedAt       time.Time `gorm:"column:updated_at;" json:"updated_at"`                 // 更新时间
+}
+
+func (TalkGroupThread) TableName() string {
+	return "talk_group_thread"
+}
+
+// TalkGroupThreadMember 话题参与者(根消息发送者及所有回复者)
+type TalkGroupThreadMember struct {
+	Id        int       `gorm:"column:id;primary_key;AUTO_INCREMENT" json:"id"`
+	GroupId   int       `gorm:"column:group_id;" json:"group_id"`       // 群组ID
+	RootMsgId string    `gorm:"column:root_msg_id;" json:"root_msg_id"` // 根消息ID
+	UserId    int       `gorm:"column:user_id;" json:"user_id"`         // 用户ID
+	CreatedAt time.Time `gorm:"column:created_at;" json:"created_at"`   // 创建时间
+}
+
+func (TalkGroupThreadMember) TableName() string {
+	return "talk_group_thread_member"
+}
@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// TalkMessageArchive 聊天记录归档表登记，每种对话类型每月一张归档表
+type TalkMessageArchive struct {
+	Id           int       `gorm:"column:id;primary_key;AUTO_INCREMENT" json:"id"`
+	TalkMode     int       `gorm:"column:talk_mode;" json:"talk_mode"`         // 对话类型[1:私信;2:群聊;]
+	Month        int       `gorm:"column:month;" json:"month"`                 // 归档月份，格式 200601
+	ArchiveTable string    `gorm:"column:archive_table;" json:"archive_table"` // 归档表名
+	RecordCount  int       `gorm:"column:record_count;" json:"record_count"`   // 归档的记录数
+	CreatedAt    time.Time `gorm:"column:created_at;" json:"created_at"`       // 创建时间
+	UpdatedAt    time.Time `gorm:"column:updated_at;" json:"updated_at"`       // 更新时间
+}
+
+func (TalkMessageArchive) TableName() string {
+	return "talk_message_archive"
+}
@@ -0,0 +1,47 @@
+package model
+
+import "time"
+
+const (
+	TalkExpireModeAfterSend = 1 // 发送后开始计时
+	TalkExpireModeAfterRead = 2 // 首次阅读后开始计时(阅后即焚)
+)
+
+// TalkExpireSetting 会话消息过期设置
+// 群聊: UserId 为 0，ToFromId 为群ID
+// 私聊: UserId、ToFromId 为双方用户ID(较小的在前)，双方共享
+type TalkExpireSetting struct {
+	Id        int       `gorm:"column:id;primary_key;AUTO_INCREMENT" json:"id"`
+	TalkMode  int       `gorm:"column:talk_mode;" json:"talk_mode"`   // 对话类型[1:私信;2:群聊;]
+	UserId    int       `gorm:"column:user_id;" json:"user_id"`       // 私聊用户ID(较小的一方)
+	ToFromId  int       `gorm:"column:to_from_id;" json:"to_from_id"` // 群ID或私聊用户ID(较大的一方)
+	Mode      int       `gorm:"column:mode;" json:"mode"`             // 计时方式[1:发送后;2:首次阅读后;]
+	Ttl       int       `gorm:"column:ttl;" json:"ttl"`               // 有效时长(秒)
+	UpdatedBy int       `gorm:"column:updated_by;" json:"updated_by"` // 最后设置人
+	CreatedAt time.Time `gorm:"column:created_at;" json:"created_at"` // 创建时间
+	UpdatedAt time.Time `gorm:"column:updated_at;" json:"updated_at"` // 更新时间
+}
+
+func (TalkExpireSetting) TableName() string {
+	return "talk_expire_setting"
+}
+
+// TalkMessageExpire 消息过期记录
+// 私聊每一方的消息各一条记录，群聊每条消息一条记录(UserId 为 0)
+type TalkMessageExpire struct {
+	Id        int       `gorm:"column:id;primary_key;AUTO_INCREMENT" json:"id"`
+	TalkMode  int       `gorm:"column:talk_mode;" json:"talk_mode"`   // 对话类型[1:私信;2:群聊;]
+	MsgId     string    `gorm:"column:msg_id;" json:"msg_id"`         // 消息ID
+	OrgMsgId  string    `gorm:"column:org_msg_id;" json:"org_msg_id"` // 原消息ID(私聊双方相同，群聊同 msg_id)
+	UserId    int       `gorm:"column:user_id;" json:"user_id"`       // 私聊消息所属用户ID，群聊为0
+	ToFromId  int       `gorm:"column:to_from_id;" json:"to_from_id"` // 好友ID或群ID
+	FromId    int       `gorm:"column:from_id;" json:"from_id"`       // 消息发送者
+	Mode      int       `gorm:"column:mode;" json:"mode"`             // 计时方式[1:发送后;2:首次阅读后;]
+	Ttl       int       `gorm:"column:ttl;" json:"ttl"`               // 有效时长(秒)
+	ExpireAt  int64     `gorm:"column:expire_at;" json:"expire_at"`   // 过期时间戳(秒)，0表示等待首次阅读
+	CreatedAt time.Time `gorm:"column:created_at;" json:"created_at"` // 创建时间
+}
+
+func (TalkMessageExpire) TableName() string {
+	return "talk_message_expire"
+}
@@ -1,5 +1,8 @@
 package model
 
+// MentionAll @用户ID列表中表示@所有人的用户ID
+const MentionAll = 0
+
 type Quote struct {
 	QuoteId  string `json:"quote_id"`
 	Nickname string `json:"nickname"`
@@ -14,8 +17,18 @@ type TalkRecordExtraGroupMember struct {
 
 // TalkRecordExtraText 文本消息
 type TalkRecordExtraText struct {
-	Content  string `json:"content"`            // 文本消息
-	Mentions []int  `json:"mentions,omitempty"` // @用户ID列表
+	Content  string                        `json:"content"`            // 文本消息
+	Mentions []int                         `json:"mentions,omitempty"` // @用户ID列表，包含 MentionAll 时表示@所有人
+	Previews []*TalkRecordExtraLinkPreview `json:"previews,omitempty"` // 链接预览，异步抓取后补充
+}
+
+// TalkRecordExtraLinkPreview 链接预览
+type TalkRecordExtraLinkPreview struct {
+	Url         string `json:"url"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Image       string `json:"image"`
+	SiteName    string `json:"site_name"`
 }
 
 // TalkRecordExtraCode 代码消息
@@ -108,8 +121,10 @@ type TalkRecordExtraTransferGroup struct {
 
 // TalkRecordExtraGroupMuted 管理员设置群禁言消息
 type TalkRecordExtraGroupMuted struct {
-	OwnerId   int    `json:"owner_id"`   // 操作人ID
-	OwnerName string `json:"owner_name"` // 操作人昵称
+	OwnerId   int    `json:"owner_id"`            // 操作人ID
+	OwnerName string `json:"owner_name"`          // 操作人昵称
+	ExpireAt  string `json:"expire_at,omitempty"` // 禁言截止时间，为空表示永久禁言
+	Reason    string `json:"reason,omitempty"`    // 禁言原因
 }
 
 // TalkRecordExtraGroupCancelMuted 管理员解除群禁言消息
@@ -120,9 +135,11 @@ type TalkRecordExtraGroupCancelMuted struct {
 
 // TalkRecordExtraGroupMemberMuted 管理员设置群成员禁言消息
 type TalkRecordExtraGroupMemberMuted struct {
-	OwnerId   int                          `json:"owner_id"`   // 操作人ID
-	OwnerName string                       `json:"owner_name"` // 操作人昵称
-	Members   []TalkRecordExtraGroupMember `json:"members"`    // 成员列表
+	OwnerId   int                          `json:"owner_id"`            // 操作人ID
+	OwnerName string                       `json:"owner_name"`          // 操作人昵称
+	Members   []TalkRecordExtraGroupMember `json:"members"`             // 成员列表
+	ExpireAt  string                       `json:"expire_at,omitempty"` // 禁言截止时间，为空表示永久禁言
+	Reason    string                       `json:"reason,omitempty"`    // 禁言原因
 }
 
 // TalkRecordExtraGroupMemberCancelMuted 管理员解除群成员禁言消息
@@ -178,6 +195,32 @@ type TalkRecordExtraMixed struct {
 	Items []*TalkRecordExtraMixedItem `json:"items"` // 消息内容。可包含图片、文字、表情等多种消息。
 }
 
+// TalkRecordExtraInteractive 交互卡片消息，点击按钮时回调给所属的群机器人
+type TalkRecordExtraInteractive struct {
+	RobotId   int                                 `json:"robot_id"`   // 所属群机器人ID
+	RobotName string                              `json:"robot_name"` // 所属群机器人名称
+	Version   int                                 `json:"version"`    // 卡片版本，每次更新加一
+	Title     string                              `json:"title"`      // 卡片标题
+	Content   string                              `json:"content"`    // 卡片正文
+	Fields    []*TalkRecordExtraInteractiveField  `json:"fields"`     // 字段列表
+	Images    []string                            `json:"images"`     // 图片地址
+	Buttons   []*TalkRecordExtraInteractiveButton `json:"buttons"`    // 按钮列表
+}
+
+type TalkRecordExtraInteractiveField struct {
+	Label string `json:"label"` // 字段名
+	Value string `json:"value"` // 字段值
+	Short bool   `json:"short"` // 是否并排显示
+}
+
+type TalkRecordExtraInteractiveButton struct {
+	Action   string `json:"action"`   // 按钮标识，点击时回调给机器人
+	Text     string `json:"text"`     // 按钮文字
+	Style    string `json:"style"`    // 按钮样式[default:默认;primary:主要;danger:危险;]
+	Value    string `json:"value"`    // 附加数据，点击时原样回调
+	Disabled bool   `json:"disabled"` // 是否禁用
+}
+
 type TalkRecordExtraVote struct {
 	VoteId int `json:"vote_id"` // 群投票ID
 }
@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+const (
+	TalkOutboxStatusPending   = 1 // 待投递
+	TalkOutboxStatusDelivered = 2 // 已投递
+	TalkOutboxStatusFailed    = 3 // 投递失败(超过最大重试次数)
+)
+
+// TalkMessageOutbox 消息投递发件箱
+// 与消息在同一事务中写入，由投递任务完成推送、未读数及会话最后一条消息的更新
+type TalkMessageOutbox struct {
+	Id          int       `gorm:"column:id;primary_key;AUTO_INCREMENT" json:"id"`
+	TalkMode    int       `gorm:"column:talk_mode;" json:"talk_mode"`         // 对话类型[1:私信;2:群聊;]
+	MsgId       string    `gorm:"column:msg_id;" json:"msg_id"`               // 消息ID(私聊为各自信箱中的消息ID)
+	Status      int       `gorm:"column:status;" json:"status"`               // 投递状态[1:待投递;2:已投递;3:投递失败;]
+	Attempts    int       `gorm:"column:attempts;" json:"attempts"`           // 投递次数
+	NextRetryAt time.Time `gorm:"column:next_retry_at;" json:"next_retry_at"` // 下次投递时间
+	LastError   string    `gorm:"column:last_error;" json:"last_error"`       // 最后一次投递失败原因
+	CreatedAt   time.Time `gorm:"column:created_at;" json:"created_at"`       // 创建时间
+	UpdatedAt   time.Time `gorm:"column:updated_at;" json:"updated_at"`       // 更新时间
+}
+
+func (TalkMessageOutbox) TableName() string {
+	return "talk_message_outbox"
+}
@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// TalkMessagePin 会话置顶消息
+// 群聊: UserId 为 0，ToFromId 为群ID，MsgId 为群消息ID
+// 私聊: UserId、ToFromId 为双方用户ID(较小的在前)，MsgId 为原消息ID(org_msg_id)，双方共享
+type TalkMessagePin struct {
+	Id        int       `gorm:"column:id;primary_key;AUTO_INCREMENT" json:"id"`
+	TalkMode  int       `gorm:"column:talk_mode;" json:"talk_mode"`   // 对话类型[1:私信;2:群聊;]
+	UserId    int       `gorm:"column:user_id;" json:"user_id"`       // 私聊用户ID(较小的一方)
+	ToFromId  int       `gorm:"column:to_from_id;" json:"to_from_id"` // 群ID或私聊用户ID(较大的一方)
+	MsgId     string    `gorm:"column:msg_id;" json:"msg_id"`         // 消息ID
+	PinnedBy  int       `gorm:"column:pinned_by;" json:"pinned_by"`   // 置顶操作人
+	CreatedAt time.Time `gorm:"column:created_at;" json:"created_at"` // 置顶时间
+}
+
+func (TalkMessagePin) TableName() string {
+	return "talk_message_pin"
+}
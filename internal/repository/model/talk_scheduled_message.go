@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+const (
+	TalkScheduledStatusPending  = 1 // 待发送
+	TalkScheduledStatusSending  = 2 // 发送中
+	TalkScheduledStatusSent     = 3 // 已发送
+	TalkScheduledStatusCanceled = 4 // 已取消
+	TalkScheduledStatusFailed   = 5 // 发送失败
+)
+
+// TalkScheduledMessage 定时发送的消息
+type TalkScheduledMessage struct {
+	Id         int       `gorm:"column:id;primary_key;AUTO_INCREMENT" json:"id"`
+	UserId     int       `gorm:"column:user_id;" json:"user_id"`         // 发送者ID
+	TalkMode   int       `gorm:"column:talk_mode;" json:"talk_mode"`     // 对话类型[1:私信;2:群聊;]
+	ToFromId   int       `gorm:"column:to_from_id;" json:"to_from_id"`   // 接收者ID(好友ID或群ID)
+	MsgType    string    `gorm:"column:msg_type;" json:"msg_type"`       // 消息类型(与发送接口的 type 一致)
+	Payload    string    `gorm:"column:payload;" json:"payload"`         // 发送参数 json 字符串
+	SendAt     time.Time `gorm:"column:send_at;" json:"send_at"`         // 计划发送时间
+	Status     int       `gorm:"column:status;" json:"status"`           // 状态[1:待发送;2:发送中;3:已发送;4:已取消;5:发送失败;]
+	FailReason string    `gorm:"column:fail_reason;" json:"fail_reason"` // 失败原因
+	MsgId      string    `gorm:"column:msg_id;" json:"msg_id"`           // 发送时生成的消息ID，重新发送时复用避免重复发送
+	CreatedAt  time.Time `gorm:"column:created_at;" json:"created_at"`   // 创建时间
+	UpdatedAt  time.Time `gorm:"column:updated_at;" json:"updated_at"`   // 更新时间
+}
+
+func (TalkScheduledMessage) TableName() string {
+	return "talk_scheduled_message"
+}
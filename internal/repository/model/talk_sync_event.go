@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+const (
+	TalkSyncEventRevoke  = "message.revoke"  // 消息撤回
+	TalkSyncEventDelete  = "message.delete"  // 消息删除
+	TalkSyncEventTop     = "session.top"     // 会话置顶
+	TalkSyncEventDisturb = "session.disturb" // 会话免打扰
+	TalkSyncEventRead    = "session.read"    // 会话已读
+)
+
+// TalkSyncEvent 多端同步变更记录
+// 用户级变更: UserId 为用户ID，Sequence 与私聊消息共用用户时序ID
+// 群级变更: UserId 为 0，GroupId 为群ID，按 Id 顺序同步
+type TalkSyncEvent struct {
+	Id        int64     `gorm:"column:id;primary_key;AUTO_INCREMENT" json:"id"`
+	UserId    int       `gorm:"column:user_id;" json:"user_id"`       // 用户ID，群级变更为0
+	GroupId   int       `gorm:"column:group_id;" json:"group_id"`     // 群ID，用户级变更为0
+	Sequence  int64     `gorm:"column:sequence;" json:"sequence"`     // 用户时序ID，群级变更为0
+	Event     string    `gorm:"column:event;" json:"event"`           // 变更类型
+	TalkMode  int       `gorm:"column:talk_mode;" json:"talk_mode"`   // 对话类型[1:私信;2:群聊;]
+	ToFromId  int       `gorm:"column:to_from_id;" json:"to_from_id"` // 好友ID或群ID
+	Payload   string    `gorm:"column:payload;" json:"payload"`       // 变更内容
+	CreatedAt time.Time `gorm:"column:created_at;" json:"created_at"` // 创建时间
+}
+
+func (TalkSyncEvent) TableName() string {
+	return "talk_sync_event"
+}
@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+// UserBlock 用户黑名单
+type UserBlock struct {
+	Id          int       `gorm:"column:id;primary_key;AUTO_INCREMENT" json:"id"`
+	UserId      int       `gorm:"column:user_id;" json:"user_id"`             // 用户ID
+	BlockUserId int       `gorm:"column:block_user_id;" json:"block_user_id"` // 被拉黑的用户ID
+	CreatedAt   time.Time `gorm:"column:created_at;" json:"created_at"`       // 拉黑时间
+}
+
+func (UserBlock) TableName() string {
+	return "user_block"
+}
+
+// UserBlockItem 黑名单列表项
+type UserBlockItem struct {
+	UserId    int       `gorm:"column:user_id" json:"user_id"`       // 被拉黑的用户ID
+	Nickname  string    `gorm:"column:nickname" json:"nickname"`     // 用户昵称
+	Avatar    string    `gorm:"column:avatar" json:"avatar"`         // 用户头像
+	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"` // 拉黑时间
+}
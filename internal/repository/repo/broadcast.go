@@ -0,0 +1,32 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/gzydong/go-chat/internal/pkg/core"
+	"github.com/gzydong/go-chat/internal/repository/model"
+	"gorm.io/gorm"
+)
+
+type BroadcastJob struct {
+	core.Repo[model.BroadcastJob]
+}
+
+func NewBroadcastJob(db *gorm.DB) *BroadcastJob {
+	return &BroadcastJob{Repo: core.NewRepo[model.BroadcastJob](db)}
+}
+
+type BroadcastRecipient struct {
+	core.Repo[model.BroadcastRecipient]
+}
+
+func NewBroadcastRecipient(db *gorm.DB) *BroadcastRecipient {
+	return &BroadcastRecipient{Repo: core.NewRepo[model.BroadcastRecipient](db)}
+}
+
+// FindUnsent 获取任务中尚未发送完成的接收者，发送中的记录为上次中断时遗留
+func (b *BroadcastRecipient) FindUnsent(ctx context.Context, jobId int, limit int) ([]*model.BroadcastRecipient, error) {
+	return b.FindAll(ctx, func(db *gorm.DB) {
+		db.Where("job_id = ? and status in ?", jobId, []int{model.BroadcastRecipientPending, model.BroadcastRecipientSending}).Order("id asc").Limit(limit)
+	})
+}
@@ -0,0 +1,39 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/gzydong/go-chat/internal/pkg/core"
+	"github.com/gzydong/go-chat/internal/repository/model"
+	"gorm.io/gorm"
+)
+
+type GroupInviteLink struct {
+	core.Repo[model.GroupInviteLink]
+}
+
+func NewGroupInviteLink(db *gorm.DB) *GroupInviteLink {
+	return &GroupInviteLink{Repo: core.NewRepo[model.GroupInviteLink](db)}
+}
+
+// FindByToken 根据链接标识获取邀请链接
+func (g *GroupInviteLink) FindByToken(ctx context.Context, token string) (*model.GroupInviteLink, error) {
+	return g.FindByWhere(ctx, "token = ?", token)
+}
+
+// IncrUsage 占用一次邀请链接使用次数，达到使用上限或链接已撤销时返回 false
+func (g *GroupInviteLink) IncrUsage(ctx context.Context, id int) (bool, error) {
+	res := g.Model(ctx).
+		Where("id = ? and status = ? and (max_usage = 0 or usage_count < max_usage)", id, model.GroupInviteLinkStatusNormal).
+		UpdateColumn("usage_count", gorm.Expr("usage_count + 1"))
+	if res.Error != nil {
+		return false, res.Error
+	}
+
+	return res.RowsAffected > 0, nil
+}
+
+// DecrUsage 归还一次邀请链接使用次数
+func (g *GroupInviteLink) DecrUsage(ctx context.Context, id int) {
+	g.Model(ctx).Where("id = ? and usage_count > 0", id).UpdateColumn("usage_count", gorm.Expr("usage_count - 1"))
+}
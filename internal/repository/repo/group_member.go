@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/gzydong/go-chat/internal/pkg/core"
+	"github.com/gzydong/go-chat/internal/pkg/logger"
 	"github.com/gzydong/go-chat/internal/repository/cache"
 	"github.com/gzydong/go-chat/internal/repository/model"
 	"gorm.io/gorm"
@@ -12,10 +13,11 @@ import (
 type GroupMember struct {
 	core.Repo[model.GroupMember]
 	relation *cache.Relation
+	members  *cache.GroupMember
 }
 
-func NewGroupMember(db *gorm.DB, relation *cache.Relation) *GroupMember {
-	return &GroupMember{Repo: core.NewRepo[model.GroupMember](db), relation: relation}
+func NewGroupMember(db *gorm.DB, relation *cache.Relation, members *cache.GroupMember) *GroupMember {
+	return &GroupMember{Repo: core.NewRepo[model.GroupMember](db), relation: relation, members: members}
 }
 
 // IsMaster 判断是否是群主
@@ -55,15 +57,35 @@ func (g *GroupMember) FindByUserId(ctx context.Context, gid, uid int) (*model.Gr
 	return member, err
 }
 
-// GetMemberIds 获取所有群成员用户ID
+// GetMemberIds 获取所有群成员用户ID，优先读取缓存
+// 缓存未加载时查询数据库，查询期间成员发生变动则不写入缓存
 func (g *GroupMember) GetMemberIds(ctx context.Context, groupId int) []int {
+	if ids, ok := g.members.GetMemberIds(ctx, groupId); ok {
+		return ids
+	}
+
+	// 版本号需在查询数据库之前获取
+	version, versionErr := g.members.Version(ctx, groupId)
 
 	var ids []int
-	_ = g.Repo.Model(ctx).Select("user_id").Where("group_id = ? and is_quit = ?", groupId, model.No).Scan(&ids)
+	if err := g.Repo.Model(ctx).Select("user_id").Where("group_id = ? and is_quit = ?", groupId, model.No).Scan(&ids).Error; err != nil {
+		return ids
+	}
+
+	if versionErr == nil {
+		_, _ = g.members.Load(ctx, groupId, version, ids)
+	}
 
 	return ids
 }
 
+// ClearMemberIdsCache 群成员变动后清除成员ID缓存
+func (g *GroupMember) ClearMemberIdsCache(ctx context.Context, groupId int) {
+	if err := g.members.Del(ctx, groupId); err != nil {
+		logger.Errorf("clear group %d member ids cache err: %s", groupId, err.Error())
+	}
+}
+
 // GetUserGroupIds 获取所有群成员ID
 func (g *GroupMember) GetUserGroupIds(ctx context.Context, uid int) []int {
 
@@ -90,12 +112,36 @@ func (g *GroupMember) GetMemberRemark(ctx context.Context, groupId int, userId i
 
 // GetMembers 获取群组成员列表
 func (g *GroupMember) GetMembers(ctx context.Context, groupId int) []*model.MemberItem {
+	var items []*model.MemberItem
+	g.membersQuery(ctx, groupId).Order("group_member.leader desc").Scan(&items)
+
+	return items
+}
+
+// GetMembersPage 分页获取群组成员列表，按成员身份及入群先后排序
+func (g *GroupMember) GetMembersPage(ctx context.Context, groupId int, page, pageSize int) (int64, []*model.MemberItem, error) {
+	total := g.CountMemberTotal(ctx, groupId)
+	if total == 0 {
+		return 0, []*model.MemberItem{}, nil
+	}
+
+	var items []*model.MemberItem
+	err := g.membersQuery(ctx, groupId).Order("group_member.leader asc,group_member.id asc").Limit(pageSize).Offset((page - 1) * pageSize).Scan(&items).Error
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return total, items, nil
+}
+
+func (g *GroupMember) membersQuery(ctx context.Context, groupId int) *gorm.DB {
 	fields := []string{
 		"group_member.id",
 		"group_member.leader",
 		"group_member.user_card",
 		"group_member.user_id",
 		"group_member.is_mute",
+		"group_member.mute_expire_at",
 		"users.avatar",
 		"users.nickname",
 		"users.gender",
@@ -105,12 +151,8 @@ func (g *GroupMember) GetMembers(ctx context.Context, groupId int) []*model.Memb
 	tx := g.Repo.Db.WithContext(ctx).Table("group_member")
 	tx.Joins("left join users on users.id = group_member.user_id")
 	tx.Where("group_member.group_id = ? and group_member.is_quit = ?", groupId, model.No)
-	tx.Order("group_member.leader desc")
 
-	var items []*model.MemberItem
-	tx.Unscoped().Select(fields).Scan(&items)
-
-	return items
+	return tx.Unscoped().Select(fields)
 }
 
 type CountGroupMember struct {
@@ -16,13 +16,28 @@ func NewGroupNotice(db *gorm.DB) *GroupNotice {
 	return &GroupNotice{Repo: core.NewRepo[model.GroupNotice](db)}
 }
 
-// GetLatestNotice 获取最新公告
+// GetLatestNotice 获取最新公告，存在置顶公告时优先返回置顶公告
 func (g *GroupNotice) GetLatestNotice(ctx context.Context, groupId int) (*model.GroupNotice, error) {
 	var info model.GroupNotice
-	err := g.Repo.Db.WithContext(ctx).Last(&info, "group_id = ?", groupId).Error
+	err := g.Repo.Db.WithContext(ctx).
+		Where("group_id = ? and is_delete = ?", groupId, model.GroupNoticeIsDeleteNo).
+		Order("is_top asc, id desc").
+		First(&info).Error
 	if err != nil {
 		return nil, err
 	}
 
 	return &info, nil
 }
+
+// FindNotice 获取群内未删除的公告
+func (g *GroupNotice) FindNotice(ctx context.Context, groupId int, noticeId int) (*model.GroupNotice, error) {
+	return g.FindByWhere(ctx, "id = ? and group_id = ? and is_delete = ?", noticeId, groupId, model.GroupNoticeIsDeleteNo)
+}
+
+// GetNotices 获取群公告历史，置顶公告在前
+func (g *GroupNotice) GetNotices(ctx context.Context, groupId int) ([]*model.GroupNotice, error) {
+	return g.FindAll(ctx, func(db *gorm.DB) {
+		db.Where("group_id = ? and is_delete = ?", groupId, model.GroupNoticeIsDeleteNo).Order("is_top asc, id desc")
+	})
+}
@@ -0,0 +1,71 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/gzydong/go-chat/internal/pkg/core"
+	"github.com/gzydong/go-chat/internal/repository/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GroupNoticeConfirm struct {
+	core.Repo[model.GroupNoticeConfirm]
+}
+
+func NewGroupNoticeConfirm(db *gorm.DB) *GroupNoticeConfirm {
+	return &GroupNoticeConfirm{Repo: core.NewRepo[model.GroupNoticeConfirm](db)}
+}
+
+// Confirm 记录成员确认公告，重复确认时忽略
+func (g *GroupNoticeConfirm) Confirm(ctx context.Context, groupId int, noticeId int, uid int) error {
+	return g.Repo.Db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&model.GroupNoticeConfirm{
+		NoticeId:  noticeId,
+		GroupId:   groupId,
+		UserId:    uid,
+		CreatedAt: time.Now(),
+	}).Error
+}
+
+// GetConfirmedUserIds 获取已确认公告的用户ID
+func (g *GroupNoticeConfirm) GetConfirmedUserIds(ctx context.Context, noticeId int) ([]int, error) {
+	var ids []int
+	err := g.Model(ctx).Where("notice_id = ?", noticeId).Pluck("user_id", &ids).Error
+	return ids, err
+}
+
+// GetConfirmedNoticeIds 获取用户在指定公告中已确认的公告ID
+func (g *GroupNoticeConfirm) GetConfirmedNoticeIds(ctx context.Context, uid int, noticeIds []int) ([]int, error) {
+	if len(noticeIds) == 0 {
+		return []int{}, nil
+	}
+
+	var ids []int
+	err := g.Model(ctx).Where("user_id = ? and notice_id in ?", uid, noticeIds).Pluck("notice_id", &ids).Error
+	return ids, err
+}
+
+// CountByNoticeIds 统计各公告的确认人数
+func (g *GroupNoticeConfirm) CountByNoticeIds(ctx context.Context, noticeIds []int) (map[int]int, error) {
+	counts := make(map[int]int, len(noticeIds))
+	if len(noticeIds) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		NoticeId int
+		Total    int
+	}
+
+	err := g.Model(ctx).Select("notice_id, count(*) as total").Where("notice_id in ?", noticeIds).Group("notice_id").Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		counts[row.NoticeId] = row.Total
+	}
+
+	return counts, nil
+}
@@ -2,6 +2,7 @@ package repo
 
 import (
 	"context"
+	"time"
 
 	"github.com/gzydong/go-chat/internal/pkg/core"
 	"github.com/gzydong/go-chat/internal/pkg/jsonutil"
@@ -68,7 +69,7 @@ func (t *GroupVote) GetVoteStatistics(ctx context.Context, vid int) (*VoteStatis
 func (t *GroupVote) SetVoteStatistics(ctx context.Context, vid int) (*VoteStatistics, error) {
 	var (
 		vote         model.GroupVote
-		answerOption map[string]any
+		answerOption []model.GroupVoteOption
 		options      = make([]string, 0)
 	)
 
@@ -87,8 +88,8 @@ func (t *GroupVote) SetVoteStatistics(ctx context.Context, vid int) (*VoteStatis
 	}
 
 	opts := make(map[string]int)
-	for option := range answerOption {
-		opts[option] = 0
+	for _, option := range answerOption {
+		opts[option.Key] = 0
 	}
 
 	for _, option := range options {
@@ -115,3 +116,23 @@ func (t *GroupVote) FindAllAnsweredUserList(ctx context.Context, viteId int) ([]
 
 	return nil, err
 }
+
+// FindExpiredVotes 获取已到截止时间但仍在进行中的投票
+func (t *GroupVote) FindExpiredVotes(ctx context.Context, now time.Time, limit int) ([]*model.GroupVote, error) {
+	return t.FindAll(ctx, func(db *gorm.DB) {
+		db.Where("status = ? and deadline is not null and deadline <= ?", model.VoteStatusWait, now).Order("id asc").Limit(limit)
+	})
+}
+
+// Finish 结束投票，投票已结束时返回 false
+func (t *GroupVote) Finish(ctx context.Context, voteId int) (bool, error) {
+	res := t.Model(ctx).Where("id = ? and status = ?", voteId, model.VoteStatusWait).Updates(map[string]any{
+		"status":     model.VoteStatusFinish,
+		"updated_at": time.Now(),
+	})
+	if res.Error != nil {
+		return false, res.Error
+	}
+
+	return res.RowsAffected > 0, nil
+}
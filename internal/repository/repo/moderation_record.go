@@ -0,0 +1,15 @@
+package repo
+
+import (
+	"github.com/gzydong/go-chat/internal/pkg/core"
+	"github.com/gzydong/go-chat/internal/repository/model"
+	"gorm.io/gorm"
+)
+
+type ModerationRecord struct {
+	core.Repo[model.ModerationRecord]
+}
+
+func NewModerationRecord(db *gorm.DB) *ModerationRecord {
+	return &ModerationRecord{Repo: core.NewRepo[model.ModerationRecord](db)}
+}
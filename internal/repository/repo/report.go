@@ -0,0 +1,32 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/gzydong/go-chat/internal/pkg/core"
+	"github.com/gzydong/go-chat/internal/repository/model"
+	"gorm.io/gorm"
+)
+
+type Report struct {
+	core.Repo[model.Report]
+}
+
+func NewReport(db *gorm.DB) *Report {
+	return &Report{Repo: core.NewRepo[model.Report](db)}
+}
+
+type ReportAudit struct {
+	core.Repo[model.ReportAudit]
+}
+
+func NewReportAudit(db *gorm.DB) *ReportAudit {
+	return &ReportAudit{Repo: core.NewRepo[model.ReportAudit](db)}
+}
+
+// FindAllByReportId 获取举报的处理记录
+func (r *ReportAudit) FindAllByReportId(ctx context.Context, reportId int) ([]*model.ReportAudit, error) {
+	return r.FindAll(ctx, func(db *gorm.DB) {
+		db.Where("report_id = ?", reportId).Order("id asc")
+	})
+}
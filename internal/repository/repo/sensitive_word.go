@@ -0,0 +1,31 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/gzydong/go-chat/internal/pkg/core"
+	"github.com/gzydong/go-chat/internal/repository/model"
+	"gorm.io/gorm"
+)
+
+type SensitiveWord struct {
+	core.Repo[model.SensitiveWord]
+}
+
+func NewSensitiveWord(db *gorm.DB) *SensitiveWord {
+	return &SensitiveWord{Repo: core.NewRepo[model.SensitiveWord](db)}
+}
+
+// FindAllEnabled 获取全部启用的敏感词
+func (s *SensitiveWord) FindAllEnabled(ctx context.Context) ([]*model.SensitiveWord, error) {
+	return s.FindAll(ctx, func(db *gorm.DB) {
+		db.Where("status = ?", model.Yes)
+	})
+}
+
+// Version 词库版本，任意敏感词新增、修改或删除后发生变化
+func (s *SensitiveWord) Version(ctx context.Context) (string, error) {
+	var version string
+	err := s.Model(ctx).Select("concat(count(*), '-', ifnull(max(updated_at), ''), '-', ifnull(max(id), 0))").Scan(&version).Error
+	return version, err
+}
@@ -2,12 +2,15 @@ package repo
 
 import (
 	"context"
+	"time"
 
 	"github.com/bwmarrin/snowflake"
 	"github.com/gzydong/go-chat/internal/pkg/core"
+	"github.com/gzydong/go-chat/internal/pkg/logger"
 	"github.com/gzydong/go-chat/internal/repository/cache"
 	"github.com/gzydong/go-chat/internal/repository/model"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type SequenceType int32
@@ -17,10 +20,13 @@ const (
 	SequenceTypeGroup = 2
 )
 
+const SequenceDefaultStep = 1000 // 默认号段步长
+
 type Sequence struct {
 	db    *gorm.DB
 	cache *cache.Sequence
 	core.Repo[model.Sequence]
+	allocator *sequenceAllocator
 	snowflake *snowflake.Node
 }
 
@@ -33,21 +39,114 @@ func NewSequence(db *gorm.DB, cache *cache.Sequence) *Sequence {
 		panic(err)
 	}
 
-	return &Sequence{db: db, cache: cache, Repo: core.NewRepo[model.Sequence](db), snowflake: node}
+	s := &Sequence{db: db, cache: cache, Repo: core.NewRepo[model.Sequence](db), snowflake: node}
+	s.allocator = newSequenceAllocator(s, cache)
+
+	return s
 }
 
 // Get 获取会话间的时序ID
 func (s *Sequence) Get(ctx context.Context, seqType SequenceType, sourceId int32) int64 {
-	return s.snowflake.Generate().Int64()
+	return s.BatchGet(ctx, seqType, sourceId, 1)[0]
 }
 
 // BatchGet 批量获取会话间的时序ID
+// 数据库不可用时退化为雪花ID，保证唯一且递增，恢复后按 MAX(sequence) 重新对齐
 func (s *Sequence) BatchGet(ctx context.Context, seqType SequenceType, sourceId int32, num int) []int64 {
-	ids := make([]int64, 0)
+	ids := make([]int64, 0, num)
+	if num <= 0 {
+		return ids
+	}
 
-	for i := 0; i < num; i++ {
-		ids = append(ids, s.snowflake.Generate().Int64())
+	last, err := s.allocator.allocate(ctx, seqType, sourceId, int64(num))
+	if err != nil {
+		logger.Errorf("sequence allocate type_%d:%d err: %s", seqType, sourceId, err.Error())
+
+		for i := 0; i < num; i++ {
+			ids = append(ids, s.snowflake.Generate().Int64())
+		}
+
+		return ids
+	}
+
+	for i := int64(num) - 1; i >= 0; i-- {
+		ids = append(ids, last-i)
 	}
 
 	return ids
 }
+
+// reserve 申请号段，起始值取已分配的最大ID与消息表中最大时序ID的较大值
+// 缓存丢失或号段耗尽时都会重新对齐，避免时序ID重复或回退
+func (s *Sequence) reserve(ctx context.Context, seqType SequenceType, sourceId int32, size int64, install func(start, end int64)) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&model.Sequence{
+			SeqType:   int32(seqType),
+			SourceId:  sourceId,
+			Step:      SequenceDefaultStep,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		var row model.Sequence
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&row, "seq_type = ? and source_id = ?", seqType, sourceId).Error
+		if err != nil {
+			return err
+		}
+
+		seed, err := s.maxSequence(tx, seqType, sourceId)
+		if err != nil {
+			return err
+		}
+
+		step := row.Step
+		if step <= 0 {
+			step = SequenceDefaultStep
+		}
+
+		start := max(row.MaxSeq, seed)
+		end := start + max(step, size)
+
+		err = tx.Model(&model.Sequence{}).Where("id = ?", row.Id).Updates(map[string]any{
+			"cur_seq":    start,
+			"max_seq":    end,
+			"updated_at": now,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		install(start, end)
+		return nil
+	})
+}
+
+// maxSequence 获取已落库的最大时序ID
+func (s *Sequence) maxSequence(tx *gorm.DB, seqType SequenceType, sourceId int32) (int64, error) {
+	var value int64
+
+	if seqType == SequenceTypeGroup {
+		err := tx.Model(&model.TalkGroupMessage{}).Where("group_id = ?", sourceId).
+			Select("ifnull(max(sequence), 0)").Scan(&value).Error
+		return value, err
+	}
+
+	err := tx.Model(&model.TalkUserMessage{}).Where("user_id = ?", sourceId).
+		Select("ifnull(max(sequence), 0)").Scan(&value).Error
+	if err != nil {
+		return 0, err
+	}
+
+	// 多端同步的用户级变更与私聊消息共用时序ID
+	var eventSeq int64
+	err = tx.Model(&model.TalkSyncEvent{}).Where("user_id = ?", sourceId).
+		Select("ifnull(max(sequence), 0)").Scan(&eventSeq).Error
+
+	return max(value, eventSeq), err
+}
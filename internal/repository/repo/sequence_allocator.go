@@ -0,0 +1,110 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// sequenceReserver 号段持久化存储
+type sequenceReserver interface {
+	// reserve 申请至少 size 个ID的号段 (start, end]
+	// install 在持有号段锁期间执行，保证号段按申请顺序写入缓存
+	reserve(ctx context.Context, seqType SequenceType, sourceId int32, size int64, install func(start, end int64)) error
+}
+
+// sequenceCache 号段缓存
+type sequenceCache interface {
+	Take(ctx context.Context, seqType int32, sourceId int32, num int64, floor int64) (int64, bool, error)
+	Install(ctx context.Context, seqType int32, sourceId int32, start, used, end int64) error
+}
+
+type sequenceSegment struct {
+	cur int64 // 已发放的最后一个ID
+	max int64 // 号段最大ID
+}
+
+// sequenceAllocator 号段发号器
+// 号段由数据库分配，优先通过 Redis 发号以保证多节点间时序递增；
+// Redis 不可用时退化为进程内号段，此时仅保证唯一，Redis 恢复后丢弃进程内号段并重新申请
+type sequenceAllocator struct {
+	reserver sequenceReserver
+	cache    sequenceCache
+
+	mu    sync.Mutex
+	local map[string]*sequenceSegment
+}
+
+func newSequenceAllocator(reserver sequenceReserver, cache sequenceCache) *sequenceAllocator {
+	return &sequenceAllocator{reserver: reserver, cache: cache, local: make(map[string]*sequenceSegment)}
+}
+
+// allocate 获取 num 个连续的时序ID，返回最后一个ID
+func (a *sequenceAllocator) allocate(ctx context.Context, seqType SequenceType, sourceId int32, num int64) (int64, error) {
+	key := fmt.Sprintf("%d:%d", seqType, sourceId)
+
+	// 进程内号段已发放的ID不允许被 Redis 中的旧号段覆盖
+	floor := a.localMax(key)
+
+	last, ok, err := a.cache.Take(ctx, int32(seqType), sourceId, num, floor)
+	if err == nil && ok {
+		a.dropLocal(key)
+		return last, nil
+	}
+
+	useLocal := err != nil
+	if useLocal {
+		if last, ok := a.takeLocal(key, num); ok {
+			return last, nil
+		}
+	}
+
+	err = a.reserver.reserve(ctx, seqType, sourceId, num, func(start, end int64) {
+		last = start + num
+
+		if !useLocal && a.cache.Install(ctx, int32(seqType), sourceId, start, num, end) == nil {
+			a.dropLocal(key)
+			return
+		}
+
+		a.mu.Lock()
+		a.local[key] = &sequenceSegment{cur: last, max: end}
+		a.mu.Unlock()
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return last, nil
+}
+
+func (a *sequenceAllocator) localMax(key string) int64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if segment, ok := a.local[key]; ok {
+		return segment.max
+	}
+
+	return 0
+}
+
+func (a *sequenceAllocator) takeLocal(key string, num int64) (int64, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	segment, ok := a.local[key]
+	if !ok || segment.cur+num > segment.max {
+		return 0, false
+	}
+
+	segment.cur += num
+	return segment.cur, true
+}
+
+func (a *sequenceAllocator) dropLocal(key string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	delete(a.local, key)
+}
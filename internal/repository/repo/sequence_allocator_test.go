@@ -0,0 +1,227 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// memoryReserver 模拟数据库号段表，seed 模拟消息表中已落库的最大时序ID
+type memoryReserver struct {
+	mu    sync.Mutex
+	max   int64
+	seed  int64
+	step  int64
+	calls int
+}
+
+func (m *memoryReserver) reserve(_ context.Context, _ SequenceType, _ int32, size int64, install func(start, end int64)) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls++
+	start := max(m.max, m.seed)
+	m.max = start + max(m.step, size)
+	install(start, m.max)
+	return nil
+}
+
+// memoryCache 模拟 Redis 号段缓存，可模拟数据丢失及服务不可用
+type memoryCache struct {
+	mu       sync.Mutex
+	segments map[string][3]int64 // base, off, size
+	down     bool
+}
+
+func newMemoryCache() *memoryCache {
+	return &memoryCache{segments: make(map[string][3]int64)}
+}
+
+func (m *memoryCache) Take(_ context.Context, seqType int32, sourceId int32, num int64, floor int64) (int64, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.down {
+		return 0, false, errors.New("connection refused")
+	}
+
+	key := fmt.Sprintf("%d:%d", seqType, sourceId)
+	segment, ok := m.segments[key]
+	if !ok || segment[1]+num > segment[2] {
+		return 0, false, nil
+	}
+
+	segment[1] += num
+	m.segments[key] = segment
+
+	last := segment[0] + segment[1]
+	if last-num < floor {
+		return 0, false, nil
+	}
+
+	return last, true, nil
+}
+
+func (m *memoryCache) Install(_ context.Context, seqType int32, sourceId int32, start, used, end int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.down {
+		return errors.New("connection refused")
+	}
+
+	m.segments[fmt.Sprintf("%d:%d", seqType, sourceId)] = [3]int64{start, used, end - start}
+	return nil
+}
+
+func (m *memoryCache) setDown(down bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.down = down
+}
+
+func (m *memoryCache) flush() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.segments = make(map[string][3]int64)
+}
+
+func mustAllocate(t *testing.T, a *sequenceAllocator, num int64) int64 {
+	t.Helper()
+
+	last, err := a.allocate(context.Background(), SequenceTypeUser, 1, num)
+	if err != nil {
+		t.Fatalf("allocate err: %s", err.Error())
+	}
+
+	return last
+}
+
+func TestSequenceAllocator_Sequential(t *testing.T) {
+	reserver := &memoryReserver{step: 10}
+	allocator := newSequenceAllocator(reserver, newMemoryCache())
+
+	for i := int64(1); i <= 35; i++ {
+		if got := mustAllocate(t, allocator, 1); got != i {
+			t.Fatalf("allocate #%d = %d, want %d", i, got, i)
+		}
+	}
+
+	if reserver.calls != 4 {
+		t.Errorf("reserve calls = %d, want 4", reserver.calls)
+	}
+
+	// 批量获取超出步长时按需申请号段
+	if got := mustAllocate(t, allocator, 25); got != 65 {
+		t.Errorf("batch allocate = %d, want 65", got)
+	}
+}
+
+func TestSequenceAllocator_Seed(t *testing.T) {
+	reserver := &memoryReserver{step: 10, seed: 1000}
+	allocator := newSequenceAllocator(reserver, newMemoryCache())
+
+	if got := mustAllocate(t, allocator, 1); got != 1001 {
+		t.Errorf("allocate = %d, want 1001", got)
+	}
+}
+
+func TestSequenceAllocator_Concurrent(t *testing.T) {
+	reserver := &memoryReserver{step: 50}
+	cache := newMemoryCache()
+
+	// 多个发号器共享号段表及缓存，模拟多节点部署
+	allocators := []*sequenceAllocator{
+		newSequenceAllocator(reserver, cache),
+		newSequenceAllocator(reserver, cache),
+		newSequenceAllocator(reserver, cache),
+	}
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		seen = make(map[int64]bool)
+	)
+
+	for i := 0; i < 30; i++ {
+		wg.Add(1)
+		go func(allocator *sequenceAllocator) {
+			defer wg.Done()
+
+			prev := int64(0)
+			for j := 0; j < 200; j++ {
+				num := int64(j%3 + 1)
+				last, err := allocator.allocate(context.Background(), SequenceTypeGroup, 1, num)
+				if err != nil {
+					t.Errorf("allocate err: %s", err.Error())
+					return
+				}
+
+				if last-num < prev {
+					t.Errorf("sequence regressed: %d after %d", last, prev)
+				}
+
+				prev = last
+
+				mu.Lock()
+				for v := last - num + 1; v <= last; v++ {
+					if seen[v] {
+						t.Errorf("duplicate sequence %d", v)
+					}
+					seen[v] = true
+				}
+				mu.Unlock()
+			}
+		}(allocators[i%len(allocators)])
+	}
+
+	wg.Wait()
+}
+
+func TestSequenceAllocator_CacheLost(t *testing.T) {
+	reserver := &memoryReserver{step: 100}
+	cache := newMemoryCache()
+	allocator := newSequenceAllocator(reserver, cache)
+
+	before := mustAllocate(t, allocator, 1)
+
+	// 缓存丢失后重新申请号段，不会从头开始计数
+	cache.flush()
+
+	if after := mustAllocate(t, allocator, 1); after <= before {
+		t.Errorf("allocate after cache lost = %d, want > %d", after, before)
+	}
+}
+
+func TestSequenceAllocator_CacheDown(t *testing.T) {
+	reserver := &memoryReserver{step: 10}
+	cache := newMemoryCache()
+	allocator := newSequenceAllocator(reserver, cache)
+
+	prev := mustAllocate(t, allocator, 1)
+
+	// 缓存不可用时使用进程内号段
+	cache.setDown(true)
+	for i := 0; i < 25; i++ {
+		last := mustAllocate(t, allocator, 1)
+		if last <= prev {
+			t.Fatalf("allocate while cache down = %d, want > %d", last, prev)
+		}
+
+		prev = last
+	}
+
+	// 缓存恢复后，缓存中的旧号段低于进程内已发放的ID，需重新申请
+	cache.setDown(false)
+	if last := mustAllocate(t, allocator, 1); last <= prev {
+		t.Errorf("allocate after cache recovered = %d, want > %d", last, prev)
+	}
+
+	if len(allocator.local) != 0 {
+		t.Errorf("local segments = %d, want 0", len(allocator.local))
+	}
+}
@@ -0,0 +1,24 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/gzydong/go-chat/internal/pkg/core"
+	"github.com/gzydong/go-chat/internal/repository/model"
+	"gorm.io/gorm"
+)
+
+type TalkFavorite struct {
+	core.Repo[model.TalkFavorite]
+}
+
+func NewTalkFavorite(db *gorm.DB) *TalkFavorite {
+	return &TalkFavorite{Repo: core.NewRepo[model.TalkFavorite](db)}
+}
+
+// FindAllByIds 批量获取用户的收藏，按收藏顺序返回
+func (t *TalkFavorite) FindAllByIds(ctx context.Context, uid int, ids []int) ([]*model.TalkFavorite, error) {
+	return t.FindAll(ctx, func(db *gorm.DB) {
+		db.Where("user_id = ? and id in ?", uid, ids).Order("id asc")
+	})
+}
@@ -0,0 +1,77 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/gzydong/go-chat/internal/pkg/core"
+	"github.com/gzydong/go-chat/internal/repository/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TalkGroupThread struct {
+	core.Repo[model.TalkGroupThread]
+}
+
+func NewTalkGroupThread(db *gorm.DB) *TalkGroupThread {
+	return &TalkGroupThread{Repo: core.NewRepo[model.TalkGroupThread](db)}
+}
+
+// FindByRootMsgIds 批量获取话题汇总信息
+func (t *TalkGroupThread) FindByRootMsgIds(ctx context.Context, groupId int, rootMsgIds []string) ([]*model.TalkGroupThread, error) {
+	if len(rootMsgIds) == 0 {
+		return make([]*model.TalkGroupThread, 0), nil
+	}
+
+	return t.FindAllByWhere(ctx, "group_id = ? and root_msg_id in ?", groupId, rootMsgIds)
+}
+
+// AddReply 记录一条话题回复，更新回复数及最后回复信息，并登记参与者
+// 需在创建回复消息的同一事务中调用
+func (t *TalkGroupThread) AddReply(tx *gorm.DB, root *model.TalkGroupMessage, reply *model.TalkGroupMessage) error {
+	now := time.Now()
+
+	err := tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "root_msg_id"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"reply_count":        gorm.Expr("reply_count + 1"),
+			"last_reply_msg_id":  reply.MsgId,
+			"last_reply_user_id": reply.FromId,
+			"last_reply_at":      reply.SendTime,
+			"updated_at":         now,
+		}),
+	}).Create(&model.TalkGroupThread{
+		GroupId:         root.GroupId,
+		RootMsgId:       root.MsgId,
+		ReplyCount:      1,
+		LastReplyMsgId:  reply.MsgId,
+		LastReplyUserId: reply.FromId,
+		LastReplyAt:     reply.SendTime,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}).Error
+	if err != nil {
+		return err
+	}
+
+	members := []*model.TalkGroupThreadMember{
+		{GroupId: root.GroupId, RootMsgId: root.MsgId, UserId: reply.FromId, CreatedAt: now},
+	}
+
+	// 系统消息没有发送者
+	if root.FromId > 0 && root.FromId != reply.FromId {
+		members = append(members, &model.TalkGroupThreadMember{
+			GroupId: root.GroupId, RootMsgId: root.MsgId, UserId: root.FromId, CreatedAt: now,
+		})
+	}
+
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(members).Error
+}
+
+// GetMemberIds 获取话题参与者用户ID
+func (t *TalkGroupThread) GetMemberIds(ctx context.Context, rootMsgId string) []int {
+	var ids []int
+	_ = t.Db.WithContext(ctx).Model(&model.TalkGroupThreadMember{}).Where("root_msg_id = ?", rootMsgId).Pluck("user_id", &ids)
+	return ids
+}
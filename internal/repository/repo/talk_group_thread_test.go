@@ -0,0 +1,150 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gzydong/go-chat/internal/repository/model"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// execRecorder 记录执行的 SQL 语句，不连接数据库
+type execRecorder struct {
+	statements []string
+	args       [][]any
+}
+
+type execResult struct{}
+
+func (execResult) LastInsertId() (int64, error) { return 1, nil }
+func (execResult) RowsAffected() (int64, error) { return 1, nil }
+
+func (e *execRecorder) PrepareContext(context.Context, string) (*sql.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+
+func (e *execRecorder) ExecContext(_ context.Context, query string, args ...any) (sql.Result, error) {
+	e.statements = append(e.statements, query)
+	e.args = append(e.args, args)
+	return execResult{}, nil
+}
+
+func (e *execRecorder) QueryContext(context.Context, string, ...any) (*sql.Rows, error) {
+	return nil, errors.New("not supported")
+}
+
+func (e *execRecorder) QueryRowContext(context.Context, string, ...any) *sql.Row {
+	return nil
+}
+
+func newExecRecorderDb(t *testing.T) (*gorm.DB, *execRecorder) {
+	recorder := &execRecorder{}
+
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: recorder, SkipInitializeWithVersion: true}), &gorm.Config{
+		SkipDefaultTransaction: true,
+		Logger:                 logger.Discard,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return db, recorder
+}
+
+func TestTalkGroupThreadAddReply(t *testing.T) {
+	sendTime := time.Date(2024, 5, 1, 12, 0, 0, 0, time.Local)
+
+	cases := []struct {
+		name        string
+		root        *model.TalkGroupMessage
+		reply       *model.TalkGroupMessage
+		wantMembers []int
+	}{
+		{
+			name:        "other user reply",
+			root:        &model.TalkGroupMessage{GroupId: 10, MsgId: "root", FromId: 1},
+			reply:       &model.TalkGroupMessage{GroupId: 10, MsgId: "reply", FromId: 2, RootMsgId: "root", SendTime: sendTime},
+			wantMembers: []int{2, 1},
+		},
+		{
+			name:        "root sender reply",
+			root:        &model.TalkGroupMessage{GroupId: 10, MsgId: "root", FromId: 1},
+			reply:       &model.TalkGroupMessage{GroupId: 10, MsgId: "reply", FromId: 1, RootMsgId: "root", SendTime: sendTime},
+			wantMembers: []int{1},
+		},
+		{
+			name:        "system root message",
+			root:        &model.TalkGroupMessage{GroupId: 10, MsgId: "root", FromId: 0},
+			reply:       &model.TalkGroupMessage{GroupId: 10, MsgId: "reply", FromId: 2, RootMsgId: "root", SendTime: sendTime},
+			wantMembers: []int{2},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			db, recorder := newExecRecorderDb(t)
+
+			if err := NewTalkGroupThread(db).AddReply(db, c.root, c.reply); err != nil {
+				t.Fatalf("AddReply() error = %v", err)
+			}
+
+			if len(recorder.statements) != 2 {
+				t.Fatalf("AddReply() statements = %d, want 2", len(recorder.statements))
+			}
+
+			// 话题汇总：首次回复写入回复数1，已存在时累加回复数并更新最后回复信息
+			summary, args := recorder.statements[0], recorder.args[0]
+			for _, want := range []string{
+				"INSERT INTO `talk_group_thread`",
+				"ON DUPLICATE KEY UPDATE",
+				"`reply_count`=reply_count + 1",
+				"`last_reply_msg_id`=?",
+				"`last_reply_user_id`=?",
+				"`last_reply_at`=?",
+			} {
+				if !strings.Contains(summary, want) {
+					t.Errorf("summary sql missing %q: %s", want, summary)
+				}
+			}
+
+			// 插入值：group_id, root_msg_id, reply_count, last_reply_msg_id, last_reply_user_id, last_reply_at, ...
+			if args[0] != c.root.GroupId || args[1] != c.root.MsgId || args[2] != 1 ||
+				args[3] != c.reply.MsgId || args[4] != c.reply.FromId || args[5] != c.reply.SendTime {
+				t.Errorf("summary insert args = %v", args[:6])
+			}
+
+			// 更新值按列名排序：last_reply_at, last_reply_msg_id, last_reply_user_id, updated_at
+			update := args[len(args)-4:]
+			if update[0] != c.reply.SendTime || update[1] != c.reply.MsgId || update[2] != c.reply.FromId {
+				t.Errorf("summary update args = %v", update)
+			}
+
+			// 参与者：回复者及根消息发送者，重复登记时忽略
+			members, args := recorder.statements[1], recorder.args[1]
+			if !strings.HasPrefix(members, "INSERT INTO `talk_group_thread_member`") || !strings.HasSuffix(members, "ON DUPLICATE KEY UPDATE `id`=`id`") {
+				t.Errorf("member sql = %s", members)
+			}
+
+			var uids []int
+			for i := 2; i < len(args); i += 4 {
+				uids = append(uids, args[i].(int))
+			}
+
+			if len(uids) != len(c.wantMembers) {
+				t.Fatalf("members = %v, want %v", uids, c.wantMembers)
+			}
+
+			for i := range uids {
+				if uids[i] != c.wantMembers[i] {
+					t.Errorf("members = %v, want %v", uids, c.wantMembers)
+				}
+			}
+		})
+	}
+}
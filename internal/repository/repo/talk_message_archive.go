@@ -0,0 +1,79 @@
+package repo
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gzydong/go-chat/internal/entity"
+	"github.com/gzydong/go-chat/internal/pkg/core"
+	"github.com/gzydong/go-chat/internal/repository/model"
+	"gorm.io/gorm"
+)
+
+type TalkMessageArchive struct {
+	core.Repo[model.TalkMessageArchive]
+}
+
+func NewTalkMessageArchive(db *gorm.DB) *TalkMessageArchive {
+	return &TalkMessageArchive{Repo: core.NewRepo[model.TalkMessageArchive](db)}
+}
+
+// FindTables 获取对话类型的归档表，按月份倒序
+func (t *TalkMessageArchive) FindTables(ctx context.Context, talkMode int) ([]string, error) {
+	var tables []string
+	err := t.Model(ctx).Where("talk_mode = ?", talkMode).
+		Order("month desc").
+		Pluck("archive_table", &tables).Error
+
+	return tables, err
+}
+
+// Scan 依次在当前消息表及归档表(按月份倒序)中执行查询，直到 scan 返回 true
+// 归档表仅在当前消息表未查到全部数据时加载，适用于按消息ID读取可能已归档的记录
+func (t *TalkMessageArchive) Scan(ctx context.Context, talkMode int, scan func(tx *gorm.DB) (bool, error)) error {
+	tables := []string{model.TalkUserMessage{}.TableName()}
+	if talkMode == entity.ChatGroupMode {
+		tables[0] = model.TalkGroupMessage{}.TableName()
+	}
+
+	for i := 0; i < len(tables); i++ {
+		done, err := scan(t.Db.WithContext(ctx).Table(tables[i]))
+		if err != nil || done {
+			return err
+		}
+
+		if i == 0 {
+			archives, err := t.FindTables(ctx, talkMode)
+			if err != nil {
+				return err
+			}
+
+			tables = append(tables, archives...)
+		}
+	}
+
+	return nil
+}
+
+// Take 按条件获取一条消息，当前消息表不存在时查询归档表，均不存在时返回 gorm.ErrRecordNotFound
+func (t *TalkMessageArchive) Take(ctx context.Context, talkMode int, dest any, where string, args ...any) error {
+	found := false
+	err := t.Scan(ctx, talkMode, func(tx *gorm.DB) (bool, error) {
+		err := tx.Where(where, args...).Take(dest).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+
+		found = err == nil
+		return found, err
+	})
+	if err != nil {
+		return err
+	}
+
+	if !found {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
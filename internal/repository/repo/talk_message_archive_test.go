@@ -0,0 +1,134 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/gzydong/go-chat/internal/entity"
+	"github.com/gzydong/go-chat/internal/repository/model"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// tableConnector 模拟数据库，按查询的表名返回预设结果并记录查询过的表
+type tableConnector struct {
+	rows    map[string][][]driver.Value
+	columns map[string][]string
+	queried []string
+}
+
+func (c *tableConnector) Connect(context.Context) (driver.Conn, error) { return &tableConn{c}, nil }
+func (c *tableConnector) Driver() driver.Driver                        { return nil }
+
+type tableConn struct {
+	*tableConnector
+}
+
+func (c *tableConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c *tableConn) Close() error                        { return nil }
+func (c *tableConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (c *tableConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	_, table, _ := strings.Cut(query, "FROM `")
+	table, _, _ = strings.Cut(table, "`")
+	c.queried = append(c.queried, table)
+
+	return &tableRows{columns: c.columns[table], values: c.rows[table]}, nil
+}
+
+type tableRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *tableRows) Columns() []string { return r.columns }
+func (r *tableRows) Close() error      { return nil }
+
+func (r *tableRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+func newTableConnectorDb(t *testing.T, connector *tableConnector) *gorm.DB {
+	conn := sql.OpenDB(connector)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: conn, SkipInitializeWithVersion: true}), &gorm.Config{
+		SkipDefaultTransaction: true,
+		Logger:                 logger.Discard,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return db
+}
+
+func TestTalkMessageArchiveTake(t *testing.T) {
+	archive := "talk_group_message_archive_202401"
+
+	cases := []struct {
+		name        string
+		rows        map[string][][]driver.Value
+		wantErr     error
+		wantQueried []string
+	}{
+		{
+			name:        "current table",
+			rows:        map[string][][]driver.Value{"talk_group_message": {{int64(1), "m1"}}},
+			wantQueried: []string{"talk_group_message"},
+		},
+		{
+			name: "archived",
+			rows: map[string][][]driver.Value{
+				"talk_message_archive": {{archive}},
+				archive:                {{int64(1), "m1"}},
+			},
+			wantQueried: []string{"talk_group_message", "talk_message_archive", archive},
+		},
+		{
+			name:        "not found",
+			rows:        map[string][][]driver.Value{"talk_message_archive": {{archive}}},
+			wantErr:     gorm.ErrRecordNotFound,
+			wantQueried: []string{"talk_group_message", "talk_message_archive", archive},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			connector := &tableConnector{
+				rows: c.rows,
+				columns: map[string][]string{
+					"talk_group_message":   {"id", "msg_id"},
+					"talk_message_archive": {"archive_table"},
+					archive:                {"id", "msg_id"},
+				},
+			}
+
+			var record model.TalkGroupMessage
+			err := NewTalkMessageArchive(newTableConnectorDb(t, connector)).Take(context.Background(), entity.ChatGroupMode, &record, "msg_id = ?", "m1")
+			if !errors.Is(err, c.wantErr) {
+				t.Fatalf("Take() error = %v, want %v", err, c.wantErr)
+			}
+
+			if c.wantErr == nil && record.MsgId != "m1" {
+				t.Errorf("Take() record = %+v", record)
+			}
+
+			if strings.Join(connector.queried, ",") != strings.Join(c.wantQueried, ",") {
+				t.Errorf("queried = %v, want %v", connector.queried, c.wantQueried)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package repo
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gzydong/go-chat/internal/pkg/core"
+	"github.com/gzydong/go-chat/internal/repository/model"
+	"gorm.io/gorm"
+)
+
+type TalkExpireSetting struct {
+	core.Repo[model.TalkExpireSetting]
+}
+
+func NewTalkExpireSetting(db *gorm.DB) *TalkExpireSetting {
+	return &TalkExpireSetting{Repo: core.NewRepo[model.TalkExpireSetting](db)}
+}
+
+// FindByTalk 获取会话的消息过期设置，未设置时返回 nil
+func (t *TalkExpireSetting) FindByTalk(ctx context.Context, talkMode int, uid int, toFromId int) (*model.TalkExpireSetting, error) {
+	userId, receiverId := TalkPairKey(talkMode, uid, toFromId)
+
+	info, err := t.FindByWhere(ctx, "talk_mode = ? and user_id = ? and to_from_id = ?", talkMode, userId, receiverId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return info, nil
+}
+
+type TalkMessageExpire struct {
+	core.Repo[model.TalkMessageExpire]
+}
+
+func NewTalkMessageExpire(db *gorm.DB) *TalkMessageExpire {
+	return &TalkMessageExpire{Repo: core.NewRepo[model.TalkMessageExpire](db)}
+}
+
+// FindAllExpired 获取已到期的消息过期记录
+func (t *TalkMessageExpire) FindAllExpired(ctx context.Context, now int64, limit int) ([]*model.TalkMessageExpire, error) {
+	return t.FindAll(ctx, func(db *gorm.DB) {
+		db.Where("expire_at > 0 and expire_at <= ?", now).Order("expire_at asc, id asc").Limit(limit)
+	})
+}
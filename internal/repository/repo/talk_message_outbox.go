@@ -0,0 +1,55 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/gzydong/go-chat/internal/pkg/core"
+	"github.com/gzydong/go-chat/internal/repository/model"
+	"gorm.io/gorm"
+)
+
+type TalkMessageOutbox struct {
+	core.Repo[model.TalkMessageOutbox]
+}
+
+func NewTalkMessageOutbox(db *gorm.DB) *TalkMessageOutbox {
+	return &TalkMessageOutbox{Repo: core.NewRepo[model.TalkMessageOutbox](db)}
+}
+
+// FindAllPending 获取已到投递时间的待投递记录
+func (t *TalkMessageOutbox) FindAllPending(ctx context.Context, now time.Time, limit int) ([]*model.TalkMessageOutbox, error) {
+	return t.FindAll(ctx, func(db *gorm.DB) {
+		db.Where("status = ? and next_retry_at <= ?", model.TalkOutboxStatusPending, now).Order("id asc").Limit(limit)
+	})
+}
+
+// Claim 抢占投递记录，抢占成功后在 lease 时间内其它进程不会重复投递
+// 投递进程异常退出时，租约到期后记录会被重新投递
+func (t *TalkMessageOutbox) Claim(ctx context.Context, id int, lease time.Duration) (bool, error) {
+	now := time.Now()
+
+	rows, err := t.UpdateByWhere(ctx, map[string]any{
+		"attempts":      gorm.Expr("attempts + 1"),
+		"next_retry_at": now.Add(lease),
+		"updated_at":    now,
+	}, "id = ? and status = ? and next_retry_at <= ?", id, model.TalkOutboxStatusPending, now)
+	if err != nil {
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+// DeleteDelivered 清理指定时间之前已投递的记录
+func (t *TalkMessageOutbox) DeleteDelivered(ctx context.Context, before time.Time, limit int) (int64, error) {
+	var ids []int
+	err := t.Model(ctx).Where("status = ? and updated_at < ?", model.TalkOutboxStatusDelivered, before).
+		Limit(limit).Pluck("id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+
+	res := t.Db.WithContext(ctx).Delete(&model.TalkMessageOutbox{}, ids)
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,26 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/gzydong/go-chat/internal/pkg/core"
+	"github.com/gzydong/go-chat/internal/repository/model"
+	"gorm.io/gorm"
+)
+
+type TalkMessagePin struct {
+	core.Repo[model.TalkMessagePin]
+}
+
+func NewTalkMessagePin(db *gorm.DB) *TalkMessagePin {
+	return &TalkMessagePin{Repo: core.NewRepo[model.TalkMessagePin](db)}
+}
+
+// FindAllByTalk 获取会话的置顶消息，最近置顶的在前
+func (t *TalkMessagePin) FindAllByTalk(ctx context.Context, talkMode int, uid int, toFromId int) ([]*model.TalkMessagePin, error) {
+	userId, receiverId := TalkPairKey(talkMode, uid, toFromId)
+
+	return t.FindAll(ctx, func(db *gorm.DB) {
+		db.Where("talk_mode = ? and user_id = ? and to_from_id = ?", talkMode, userId, receiverId).Order("id desc")
+	})
+}
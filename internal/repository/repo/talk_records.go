@@ -1,4 +1,16 @@
 package repo
 
+import "github.com/gzydong/go-chat/internal/entity"
+
 type TalkRecords struct {
 }
+
+// TalkPairKey 获取会话级数据(置顶消息、阅后即焚设置等)的会话标识
+// 群聊为 (0, 群ID)，私聊双方共用同一组标识 (较小用户ID, 较大用户ID)
+func TalkPairKey(talkMode int, uid int, toFromId int) (int, int) {
+	if talkMode == entity.ChatGroupMode {
+		return 0, toFromId
+	}
+
+	return min(uid, toFromId), max(uid, toFromId)
+}
@@ -0,0 +1,69 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/gzydong/go-chat/internal/pkg/core"
+	"github.com/gzydong/go-chat/internal/repository/model"
+	"gorm.io/gorm"
+)
+
+type TalkScheduledMessage struct {
+	core.Repo[model.TalkScheduledMessage]
+}
+
+func NewTalkScheduledMessage(db *gorm.DB) *TalkScheduledMessage {
+	return &TalkScheduledMessage{Repo: core.NewRepo[model.TalkScheduledMessage](db)}
+}
+
+// FindAllDue 获取已到发送时间的待发送消息，以及发送租约已过期的发送中消息
+func (t *TalkScheduledMessage) FindAllDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*model.TalkScheduledMessage, error) {
+	return t.FindAll(ctx, func(db *gorm.DB) {
+		db.Where("(status = ? and send_at <= ?) or (status = ? and updated_at <= ?)",
+			model.TalkScheduledStatusPending, now, model.TalkScheduledStatusSending, now.Add(-lease),
+		).Order("send_at asc, id asc").Limit(limit)
+	})
+}
+
+// Claim 抢占发送任务，抢占成功后在 lease 时间内其它进程不会重复发送
+// 发送进程异常退出时，租约到期后由其它进程重新抢占，并沿用已生成的消息ID
+func (t *TalkScheduledMessage) Claim(ctx context.Context, item *model.TalkScheduledMessage, msgId string, lease time.Duration) (bool, error) {
+	now := time.Now()
+
+	data := map[string]any{
+		"status":     model.TalkScheduledStatusSending,
+		"msg_id":     msgId,
+		"updated_at": now,
+	}
+
+	var (
+		rows int64
+		err  error
+	)
+
+	if item.Status == model.TalkScheduledStatusSending {
+		rows, err = t.UpdateByWhere(ctx, data, "id = ? and status = ? and updated_at <= ?", item.Id, model.TalkScheduledStatusSending, now.Add(-lease))
+	} else {
+		rows, err = t.UpdateByWhere(ctx, data, "id = ? and status = ?", item.Id, model.TalkScheduledStatusPending)
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+// UpdateStatus 按原状态更新消息状态，返回是否更新成功
+// 用于多个进程并发处理时抢占任务
+func (t *TalkScheduledMessage) UpdateStatus(ctx context.Context, id int, from int, data map[string]any) (bool, error) {
+	data["updated_at"] = time.Now()
+
+	rows, err := t.UpdateByWhere(ctx, data, "id = ? and status = ?", id, from)
+	if err != nil {
+		return false, err
+	}
+
+	return rows > 0, nil
+}
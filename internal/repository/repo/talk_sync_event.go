@@ -0,0 +1,15 @@
+package repo
+
+import (
+	"github.com/gzydong/go-chat/internal/pkg/core"
+	"github.com/gzydong/go-chat/internal/repository/model"
+	"gorm.io/gorm"
+)
+
+type TalkSyncEvent struct {
+	core.Repo[model.TalkSyncEvent]
+}
+
+func NewTalkSyncEvent(db *gorm.DB) *TalkSyncEvent {
+	return &TalkSyncEvent{Repo: core.NewRepo[model.TalkSyncEvent](db)}
+}
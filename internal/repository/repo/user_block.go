@@ -0,0 +1,86 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/gzydong/go-chat/internal/pkg/core"
+	"github.com/gzydong/go-chat/internal/repository/cache"
+	"github.com/gzydong/go-chat/internal/repository/model"
+	"gorm.io/gorm"
+)
+
+type UserBlock struct {
+	core.Repo[model.UserBlock]
+	cache *cache.UserBlock
+}
+
+func NewUserBlock(db *gorm.DB, cache *cache.UserBlock) *UserBlock {
+	return &UserBlock{Repo: core.NewRepo[model.UserBlock](db), cache: cache}
+}
+
+// IsBlocked 判断 uid 是否已将 blockUserId 拉黑
+func (u *UserBlock) IsBlocked(ctx context.Context, uid int, blockUserId int) bool {
+	if !u.cache.Exist(ctx, uid) {
+		if err := u.LoadCache(ctx, uid); err != nil {
+			ok, _ := u.IsExist(ctx, "user_id = ? and block_user_id = ?", uid, blockUserId)
+			return ok
+		}
+	}
+
+	return u.cache.IsBlocked(ctx, uid, blockUserId)
+}
+
+// FindBlocker 获取双方之间发起拉黑的用户ID，双方互相拉黑时返回 uid，未拉黑时返回 0
+func (u *UserBlock) FindBlocker(ctx context.Context, uid int, uid2 int) int {
+	if u.IsBlocked(ctx, uid, uid2) {
+		return uid
+	}
+
+	if u.IsBlocked(ctx, uid2, uid) {
+		return uid2
+	}
+
+	return 0
+}
+
+// IsEitherBlocked 判断双方之间是否存在任意方向的拉黑关系
+func (u *UserBlock) IsEitherBlocked(ctx context.Context, uid int, uid2 int) bool {
+	return u.FindBlocker(ctx, uid, uid2) != 0
+}
+
+// FindRelatedIds 获取与用户存在拉黑关系（拉黑或被拉黑）的用户ID
+func (u *UserBlock) FindRelatedIds(ctx context.Context, uid int) ([]int, error) {
+	items, err := u.FindAll(ctx, func(db *gorm.DB) {
+		db.Select("user_id,block_user_id").Where("user_id = ? or block_user_id = ?", uid, uid)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, 0, len(items))
+	for _, item := range items {
+		if item.UserId == uid {
+			ids = append(ids, item.BlockUserId)
+		} else {
+			ids = append(ids, item.UserId)
+		}
+	}
+
+	return ids, nil
+}
+
+// LoadCache 加载用户黑名单缓存
+func (u *UserBlock) LoadCache(ctx context.Context, uid int) error {
+	var ids []int
+	err := u.Model(ctx).Where("user_id = ?", uid).Pluck("block_user_id", &ids).Error
+	if err != nil {
+		return err
+	}
+
+	return u.cache.Set(ctx, uid, ids)
+}
+
+// ClearCache 清除用户黑名单缓存
+func (u *UserBlock) ClearCache(ctx context.Context, uid int) {
+	u.cache.Del(ctx, uid)
+}
@@ -15,6 +15,7 @@ var ProviderSet = wire.NewSet(
 	NewTalkRecordGroup,
 	NewTalkRecordFriend,
 	NewGroupNotice,
+	NewGroupNoticeConfirm,
 	NewTalkSession,
 	NewTalkRecordGroupDel,
 	NewEmoticon,
@@ -37,4 +38,22 @@ var ProviderSet = wire.NewSet(
 	NewSysAdminTotp,
 	NewInviteCode,
 	NewGroupRobot,
+	NewTalkGroupThread,
+	NewTalkMessagePin,
+	NewTalkScheduledMessage,
+	NewTalkExpireSetting,
+	NewTalkMessageExpire,
+	NewTalkMessageOutbox,
+	NewTalkSyncEvent,
+	NewSensitiveWord,
+	NewModerationRecord,
+	NewReport,
+	NewReportAudit,
+	NewBroadcastJob,
+	NewBroadcastRecipient,
+	NewTalkExport,
+	NewTalkMessageArchive,
+	NewTalkFavorite,
+	NewUserBlock,
+	NewGroupInviteLink,
 )
@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/gzydong/go-chat/internal/entity"
 	"github.com/gzydong/go-chat/internal/repository/model"
@@ -14,6 +15,8 @@ var _ IAuthService = (*AuthService)(nil)
 
 type IAuthService interface {
 	IsAuth(ctx context.Context, opt *AuthOption) error
+	// RecordSend 消息发送成功后累计群防刷屏计数，opt 与发送前校验时一致
+	RecordSend(ctx context.Context, opt *AuthOption)
 }
 
 type AuthService struct {
@@ -21,6 +24,8 @@ type AuthService struct {
 	ContactRepo     *repo.Contact
 	GroupRepo       *repo.Group
 	GroupMemberRepo *repo.GroupMember
+	UserBlockRepo   *repo.UserBlock
+	AntiSpamService IGroupAntiSpamService
 }
 
 type AuthOption struct {
@@ -28,11 +33,21 @@ type AuthOption struct {
 	UserId            int
 	ToFromId          int
 	IsVerifyGroupMute bool
+	IsVerifyAntiSpam  bool   // 是否校验群防刷屏限制，仅发送消息时校验
+	MsgType           string // 消息类型
+	Content           string // 消息文本内容
 }
 
 func (a *AuthService) IsAuth(ctx context.Context, opt *AuthOption) error {
 
 	if opt.TalkType == entity.ChatPrivateMode {
+		switch a.UserBlockRepo.FindBlocker(ctx, opt.UserId, opt.ToFromId) {
+		case opt.UserId:
+			return errors.New("你已将对方加入黑名单，请先移出后再发送消息！")
+		case opt.ToFromId:
+			return errors.New("消息已发出，但被对方拒收了！")
+		}
+
 		if isOk, err := a.OrganizeRepo.IsQiyeMember(ctx, opt.UserId, opt.ToFromId); err != nil {
 			return errors.New("系统繁忙，请稍后再试！！！")
 		} else if isOk {
@@ -68,13 +83,27 @@ func (a *AuthService) IsAuth(ctx context.Context, opt *AuthOption) error {
 		return errors.New("暂无权限发送消息！")
 	}
 
-	if memberInfo.IsMute == model.Yes {
-		return errors.New("已被群主或管理员禁言！")
+	// 禁言到期后由定时任务解除，这里只按截止时间判断
+	now := time.Now()
+	if memberInfo.IsMuted(now) {
+		return errors.New(muteErrorMessage("已被群主或管理员禁言", memberInfo.MuteExpireAt, now))
+	}
+
+	if opt.IsVerifyGroupMute && groupInfo.IsMuted(now) && memberInfo.Leader == model.GroupMemberLeaderOrdinary {
+		return errors.New(muteErrorMessage("此群聊已开启全员禁言", groupInfo.MuteExpireAt, now))
 	}
 
-	if opt.IsVerifyGroupMute && groupInfo.IsMute == model.Yes && memberInfo.Leader == model.GroupMemberLeaderOrdinary {
-		return errors.New("此群聊已开启全员禁言！")
+	if opt.IsVerifyAntiSpam && memberInfo.Leader == model.GroupMemberLeaderOrdinary {
+		return a.AntiSpamService.Verify(ctx, groupInfo, opt)
 	}
 
 	return nil
 }
+
+func (a *AuthService) RecordSend(ctx context.Context, opt *AuthOption) {
+	if opt.TalkType != entity.ChatGroupMode || !opt.IsVerifyAntiSpam {
+		return
+	}
+
+	a.AntiSpamService.Record(ctx, opt.ToFromId, opt.UserId)
+}
@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/gzydong/go-chat/internal/entity"
+	"github.com/gzydong/go-chat/internal/pkg/jsonutil"
+	"github.com/gzydong/go-chat/internal/pkg/logger"
+	"github.com/gzydong/go-chat/internal/pkg/strutil"
+	"github.com/gzydong/go-chat/internal/repository/model"
+	"github.com/gzydong/go-chat/internal/repository/repo"
+	"github.com/nsqio/go-nsq"
+	"gorm.io/gorm"
+)
+
+const (
+	broadcastMaxRecipients = 10000 // 单个任务最多接收者数
+	broadcastDefaultRate   = 10    // 默认每秒发送条数
+	broadcastMaxRate       = 50    // 最大每秒发送条数
+)
+
+var _ IBroadcastService = (*BroadcastService)(nil)
+
+type BroadcastCreateOpt struct {
+	AdminId     int
+	SenderId    int
+	UserIds     []int
+	GroupIds    []int
+	AllContacts bool // 同时发送给发送者的全部好友
+	MsgType     int  // 仅支持文本及图片消息
+	Content     string
+	Image       *model.TalkRecordExtraImage
+	Rate        int // 每秒发送条数
+}
+
+type IBroadcastService interface {
+	// Create 创建群发任务并投递到队列
+	Create(ctx context.Context, opt *BroadcastCreateOpt) (*model.BroadcastJob, error)
+	// Cancel 取消群发任务，未发送的接收者标记为已取消
+	Cancel(ctx context.Context, jobId int) error
+}
+
+type BroadcastService struct {
+	*repo.Source
+	BroadcastJobRepo       *repo.BroadcastJob
+	BroadcastRecipientRepo *repo.BroadcastRecipient
+	UsersRepo              *repo.Users
+	Producer               *nsq.Producer
+}
+
+func (b *BroadcastService) Create(ctx context.Context, opt *BroadcastCreateOpt) (*model.BroadcastJob, error) {
+	sender, err := b.UsersRepo.FindById(ctx, opt.SenderId)
+	if err != nil || sender.Status != model.UsersStatusNormal {
+		return nil, errors.New("发送者不存在或已停用")
+	}
+
+	var extra string
+	switch opt.MsgType {
+	case entity.ChatMsgTypeText:
+		if strings.TrimSpace(opt.Content) == "" {
+			return nil, errors.New("消息内容不能为空")
+		}
+
+		extra = jsonutil.Encode(model.TalkRecordExtraText{Content: html.EscapeString(opt.Content)})
+	case entity.ChatMsgTypeImage:
+		if opt.Image == nil || opt.Image.Url == "" {
+			return nil, errors.New("图片地址不能为空")
+		}
+
+		extra = jsonutil.Encode(opt.Image)
+	default:
+		return nil, errors.New("不支持的消息类型")
+	}
+
+	userIds := opt.UserIds
+	if opt.AllContacts {
+		var friendIds []int
+		err := b.Source.Db().WithContext(ctx).Model(&model.Contact{}).
+			Where("user_id = ? and status = ?", opt.SenderId, model.ContactStatusNormal).
+			Pluck("friend_id", &friendIds).Error
+		if err != nil {
+			return nil, err
+		}
+
+		userIds = append(userIds, friendIds...)
+	}
+
+	recipients := newBroadcastRecipients(opt.SenderId, userIds, opt.GroupIds)
+	if len(recipients) == 0 {
+		return nil, errors.New("请选择接收者")
+	}
+
+	if len(recipients) > broadcastMaxRecipients {
+		return nil, fmt.Errorf("单个任务最多发送给%d个接收者", broadcastMaxRecipients)
+	}
+
+	rate := opt.Rate
+	if rate <= 0 {
+		rate = broadcastDefaultRate
+	} else if rate > broadcastMaxRate {
+		rate = broadcastMaxRate
+	}
+
+	job := &model.BroadcastJob{
+		AdminId:    opt.AdminId,
+		SenderId:   opt.SenderId,
+		MsgType:    opt.MsgType,
+		Extra:      extra,
+		Rate:       rate,
+		Status:     model.BroadcastStatusPending,
+		TotalCount: len(recipients),
+	}
+
+	err = b.Source.Db().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(job).Error; err != nil {
+			return err
+		}
+
+		for _, item := range recipients {
+			item.JobId = job.Id
+		}
+
+		return tx.CreateInBatches(recipients, 500).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := b.Producer.Publish(entity.BroadcastTopic, []byte(jsonutil.Encode(entity.BroadcastJobMessage{JobId: job.Id}))); err != nil {
+		logger.Errorf("broadcast job %d publish err: %s", job.Id, err.Error())
+
+		if err := b.Cancel(ctx, job.Id); err != nil {
+			logger.Errorf("broadcast job %d cancel err: %s", job.Id, err.Error())
+		}
+
+		return nil, errors.New("群发任务投递失败，请稍后再试")
+	}
+
+	return job, nil
+}
+
+func (b *BroadcastService) Cancel(ctx context.Context, jobId int) error {
+	return b.Source.Db().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&model.BroadcastJob{}).
+			Where("id = ? and status in ?", jobId, []int{model.BroadcastStatusPending, model.BroadcastStatusRunning}).
+			Updates(map[string]any{
+				"status":      model.BroadcastStatusCancelled,
+				"finished_at": sql.NullTime{Time: time.Now(), Valid: true},
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+
+		if res.RowsAffected == 0 {
+			return errors.New("任务不存在或已结束")
+		}
+
+		// 发送中的接收者由队列服务确认结果
+		res = tx.Model(&model.BroadcastRecipient{}).
+			Where("job_id = ? and status = ?", jobId, model.BroadcastRecipientPending).
+			Update("status", model.BroadcastRecipientCancelled)
+		if res.Error != nil {
+			return res.Error
+		}
+
+		return tx.Model(&model.BroadcastJob{}).Where("id = ?", jobId).
+			Update("cancelled_count", gorm.Expr("cancelled_count + ?", res.RowsAffected)).Error
+	})
+}
+
+// newBroadcastRecipients 生成接收者列表，去重并排除发送者本人
+func newBroadcastRecipients(senderId int, userIds []int, groupIds []int) []*model.BroadcastRecipient {
+	items := make([]*model.BroadcastRecipient, 0, len(userIds)+len(groupIds))
+
+	add := func(talkMode int, ids []int) {
+		exists := make(map[int]struct{}, len(ids))
+		for _, id := range ids {
+			if id <= 0 || (talkMode == entity.ChatPrivateMode && id == senderId) {
+				continue
+			}
+
+			if _, ok := exists[id]; ok {
+				continue
+			}
+
+			exists[id] = struct{}{}
+			items = append(items, &model.BroadcastRecipient{
+				TalkMode: talkMode,
+				ToFromId: id,
+				MsgId:    strutil.NewMsgId(),
+				Status:   model.BroadcastRecipientPending,
+			})
+		}
+	}
+
+	add(entity.ChatPrivateMode, userIds)
+	add(entity.ChatGroupMode, groupIds)
+
+	return items
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/gzydong/go-chat/internal/entity"
+)
+
+func TestNewBroadcastRecipients(t *testing.T) {
+	cases := []struct {
+		name     string
+		senderId int
+		userIds  []int
+		groupIds []int
+		expect   [][2]int // talk_mode, to_from_id
+	}{
+		{name: "empty", senderId: 1, expect: [][2]int{}},
+		{name: "exclude sender", senderId: 1, userIds: []int{1, 2}, expect: [][2]int{{entity.ChatPrivateMode, 2}}},
+		{name: "dedupe", senderId: 1, userIds: []int{2, 3, 2, 0, -1}, expect: [][2]int{{entity.ChatPrivateMode, 2}, {entity.ChatPrivateMode, 3}}},
+		{name: "group id equals sender", senderId: 1, userIds: []int{2}, groupIds: []int{1, 1, 2}, expect: [][2]int{
+			{entity.ChatPrivateMode, 2}, {entity.ChatGroupMode, 1}, {entity.ChatGroupMode, 2},
+		}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			items := newBroadcastRecipients(c.senderId, c.userIds, c.groupIds)
+			if len(items) != len(c.expect) {
+				t.Fatalf("newBroadcastRecipients len = %d, expect %d", len(items), len(c.expect))
+			}
+
+			msgIds := make(map[string]struct{}, len(items))
+			for i, item := range items {
+				if item.TalkMode != c.expect[i][0] || item.ToFromId != c.expect[i][1] {
+					t.Errorf("newBroadcastRecipients[%d] = (%d, %d), expect %v", i, item.TalkMode, item.ToFromId, c.expect[i])
+				}
+
+				if _, ok := msgIds[item.MsgId]; ok || item.MsgId == "" {
+					t.Errorf("newBroadcastRecipients[%d] msg_id %q should be unique", i, item.MsgId)
+				}
+
+				msgIds[item.MsgId] = struct{}{}
+			}
+		})
+	}
+}
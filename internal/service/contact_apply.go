@@ -29,7 +29,8 @@ type IContactApplyService interface {
 
 type ContactApplyService struct {
 	*repo.Source
-	PushMessage *logic.PushMessage
+	PushMessage   *logic.PushMessage
+	UserBlockRepo *repo.UserBlock
 }
 
 type ContactApplyCreateOpt struct {
@@ -41,6 +42,13 @@ type ContactApplyCreateOpt struct {
 
 func (s *ContactApplyService) Create(ctx context.Context, opt *ContactApplyCreateOpt) error {
 
+	switch s.UserBlockRepo.FindBlocker(ctx, opt.UserId, opt.FriendId) {
+	case opt.UserId:
+		return errors.New("你已将对方加入黑名单，请先移出后再添加好友！")
+	case opt.FriendId:
+		return errors.New("对方拒绝了你的好友申请！")
+	}
+
 	apply := &model.ContactApply{
 		UserId:      opt.UserId,
 		FriendId:    opt.FriendId,
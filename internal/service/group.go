@@ -3,6 +3,8 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"slices"
 	"time"
 
 	"github.com/gzydong/go-chat/internal/logic"
@@ -30,6 +32,7 @@ type IGroupService interface {
 	Invite(ctx context.Context, opt *GroupInviteOpt) error
 	RemoveMember(ctx context.Context, opt *GroupRemoveMembersOpt) error
 	List(userId int) ([]*model.GroupItem, error)
+	UpdateMaxNum(ctx context.Context, groupId int, maxNum int) error
 }
 
 type GroupService struct {
@@ -39,6 +42,7 @@ type GroupService struct {
 	Relation        *cache.Relation
 	Sequence        *repo.Sequence
 	PushMessage     *logic.PushMessage
+	UserBlockRepo   *repo.UserBlock
 }
 
 type GroupCreateOpt struct {
@@ -57,6 +61,10 @@ func (g *GroupService) Create(ctx context.Context, opt *GroupCreateOpt) (int, er
 		talkList []*model.TalkSession
 	)
 
+	if err = g.checkInviteBlocked(ctx, opt.UserId, opt.MemberIds); err != nil {
+		return 0, err
+	}
+
 	// 群成员用户ID
 	uids := sliceutil.Unique(append(opt.MemberIds, opt.UserId))
 
@@ -70,6 +78,7 @@ func (g *GroupService) Create(ctx context.Context, opt *GroupCreateOpt) (int, er
 		MaxNum:    model.GroupMemberMaxNum,
 		IsOvert:   model.No,
 		IsMute:    model.No,
+		JoinMode:  model.GroupJoinModeApproval,
 	}
 
 	err = g.Source.Db().Transaction(func(tx *gorm.DB) error {
@@ -187,7 +196,13 @@ func (g *GroupService) Dismiss(ctx context.Context, groupId int, uid int) error
 		return nil
 	})
 
-	return err
+	if err != nil {
+		return err
+	}
+
+	g.GroupMemberRepo.ClearMemberIdsCache(ctx, groupId)
+
+	return nil
 }
 
 // Secede 退出群组[仅管理员及群成员]
@@ -247,6 +262,7 @@ func (g *GroupService) Secede(ctx context.Context, groupId int, uid int) error {
 	}
 
 	g.Relation.DelGroupRelation(ctx, uid, groupId)
+	g.GroupMemberRepo.ClearMemberIdsCache(ctx, groupId)
 
 	_ = g.PushMessage.MultiPush(ctx, entity.ImTopicChat, []*entity.SubscribeMessage{
 		{
@@ -273,6 +289,7 @@ type GroupInviteOpt struct {
 	UserId    int   // 操作人ID
 	GroupId   int   // 群ID
 	MemberIds []int // 群成员ID
+	IsApply   bool  // 是否为审核通过的入群申请，申请人主动加入时不校验黑名单
 }
 
 // Invite 邀请加入群聊
@@ -286,13 +303,26 @@ func (g *GroupService) Invite(ctx context.Context, opt *GroupInviteOpt) error {
 		db             = g.Source.Db().WithContext(ctx)
 	)
 
+	if len(opt.MemberIds) == 0 {
+		return errors.New("请选择要邀请的成员！")
+	}
+
+	// 已是群成员的用户以数据库为准，不读取成员缓存
+	var joined []int
+	err = db.Model(&model.GroupMember{}).Where("group_id = ? and user_id in ? and is_quit = ?", opt.GroupId, opt.MemberIds, model.No).Pluck("user_id", &joined).Error
+	if err != nil {
+		return err
+	}
+
 	m := make(map[int]struct{})
-	for _, value := range g.GroupMemberRepo.GetMemberIds(ctx, opt.GroupId) {
+	for _, value := range joined {
 		m[value] = struct{}{}
 	}
 
-	if len(opt.MemberIds) == 0 {
-		return errors.New("请选择要邀请的成员！")
+	if !opt.IsApply {
+		if err = g.checkInviteBlocked(ctx, opt.UserId, opt.MemberIds); err != nil {
+			return err
+		}
 	}
 
 	listHash := make(map[int]*model.TalkSession)
@@ -350,6 +380,15 @@ func (g *GroupService) Invite(ctx context.Context, opt *GroupInviteOpt) error {
 		return errors.New("邀请的好友，都已成为群成员")
 	}
 
+	group, err := g.GroupRepo.FindById(ctx, opt.GroupId)
+	if err != nil {
+		return err
+	}
+
+	if len(m)+len(addMembers) > group.MemberLimit() {
+		return entity.ErrGroupMemberLimit
+	}
+
 	record := &model.TalkGroupMessage{
 		MsgId:     strutil.NewMsgId(),
 		Sequence:  g.Sequence.Get(ctx, repo.SequenceTypeGroup, int32(opt.GroupId)),
@@ -402,6 +441,8 @@ func (g *GroupService) Invite(ctx context.Context, opt *GroupInviteOpt) error {
 		return err
 	}
 
+	g.GroupMemberRepo.ClearMemberIdsCache(ctx, opt.GroupId)
+
 	_ = g.PushMessage.MultiPush(ctx, entity.ImTopicChat, []*entity.SubscribeMessage{
 		{
 			Event: entity.SubEventImMessage,
@@ -497,6 +538,7 @@ func (g *GroupService) RemoveMember(ctx context.Context, opt *GroupRemoveMembers
 	}
 
 	g.Relation.BatchDelGroupRelation(ctx, opt.MemberIds, opt.GroupId)
+	g.GroupMemberRepo.ClearMemberIdsCache(ctx, opt.GroupId)
 
 	_ = g.PushMessage.MultiPush(ctx, entity.ImTopicChat, []*entity.SubscribeMessage{
 		{
@@ -519,6 +561,37 @@ func (g *GroupService) RemoveMember(ctx context.Context, opt *GroupRemoveMembers
 	return nil
 }
 
+// UpdateMaxNum 调整群成员数量上限，上限需为可选档位且不能低于当前成员数
+func (g *GroupService) UpdateMaxNum(ctx context.Context, groupId int, maxNum int) error {
+	if !slices.Contains(model.GroupMaxNumTiers, maxNum) {
+		return errors.New("群成员上限档位不正确")
+	}
+
+	group, err := g.GroupRepo.FindById(ctx, groupId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entity.ErrGroupNotExist
+		}
+
+		return err
+	}
+
+	if group.IsDismiss == model.Yes {
+		return entity.ErrGroupDismissed
+	}
+
+	if int(g.GroupMemberRepo.CountMemberTotal(ctx, groupId)) > maxNum {
+		return fmt.Errorf("当前群成员数量已超过%d人，无法调整", maxNum)
+	}
+
+	_, err = g.GroupRepo.UpdateById(ctx, groupId, map[string]any{
+		"max_num":    maxNum,
+		"updated_at": time.Now(),
+	})
+
+	return err
+}
+
 type session struct {
 	ToFromId  int `json:"to_from_id"`
 	IsDisturb int `json:"is_disturb"`
@@ -568,3 +641,14 @@ func (g *GroupService) List(userId int) ([]*model.GroupItem, error) {
 
 	return items, nil
 }
+
+// checkInviteBlocked 被邀请人已将邀请人加入黑名单时不允许邀请入群
+func (g *GroupService) checkInviteBlocked(ctx context.Context, uid int, memberIds []int) error {
+	for _, memberId := range memberIds {
+		if g.UserBlockRepo.IsBlocked(ctx, memberId, uid) {
+			return errors.New("部分成员已将你加入黑名单，无法邀请入群！")
+		}
+	}
+
+	return nil
+}
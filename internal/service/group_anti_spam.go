@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/gzydong/go-chat/internal/entity"
+	"github.com/gzydong/go-chat/internal/pkg/jsonutil"
+	"github.com/gzydong/go-chat/internal/pkg/logger"
+	"github.com/gzydong/go-chat/internal/repository/cache"
+	"github.com/gzydong/go-chat/internal/repository/model"
+	"github.com/gzydong/go-chat/internal/repository/repo"
+)
+
+const groupFloodMuteReason = "频繁发送消息，系统自动禁言"
+
+// 匹配 http(s) 链接、www 开头的地址及常见后缀的域名
+var linkRegexp = regexp.MustCompile(`(?i)(https?://|www\.)\S+|\b[a-z0-9][a-z0-9-]*(\.[a-z0-9-]+)*\.(com|cn|net|org|io|cc|me|top|xyz|vip|info|co)\b`)
+
+var _ IGroupAntiSpamService = (*GroupAntiSpamService)(nil)
+
+type IGroupAntiSpamService interface {
+	// GetSetting 获取群防刷屏设置
+	GetSetting(ctx context.Context, groupId int, uid int) (*model.GroupAntiSpam, error)
+	// UpdateSetting 修改群防刷屏设置
+	UpdateSetting(ctx context.Context, groupId int, uid int, setting *model.GroupAntiSpam) error
+	// Verify 校验普通成员发送的消息是否触发防刷屏限制，不累计发言次数
+	Verify(ctx context.Context, group *model.Group, opt *AuthOption) error
+	// Record 普通成员的消息发送成功后累计慢速模式及刷屏计数
+	Record(ctx context.Context, groupId int, uid int)
+}
+
+type GroupAntiSpamService struct {
+	GroupRepo        *repo.Group
+	GroupMemberRepo  *repo.GroupMember
+	AntiSpamStorage  *cache.GroupAntiSpam
+	GroupMuteService IGroupMuteService
+	GroupPermission  IGroupPermissionService
+}
+
+func (g *GroupAntiSpamService) GetSetting(ctx context.Context, groupId int, uid int) (*model.GroupAntiSpam, error) {
+	if !g.GroupMemberRepo.IsMember(ctx, groupId, uid, true) {
+		return nil, entity.ErrPermissionDenied
+	}
+
+	group, err := g.GroupRepo.FindById(ctx, groupId)
+	if err != nil {
+		return nil, err
+	}
+
+	return group.AntiSpam(), nil
+}
+
+func (g *GroupAntiSpamService) UpdateSetting(ctx context.Context, groupId int, uid int, setting *model.GroupAntiSpam) error {
+	if err := checkAntiSpamSetting(setting); err != nil {
+		return err
+	}
+
+	if err := g.GroupPermission.Check(ctx, groupId, uid, model.GroupPermEditProfile); err != nil {
+		return err
+	}
+
+	_, err := g.GroupRepo.UpdateByWhere(ctx, map[string]any{
+		"anti_spam_setting": jsonutil.Encode(setting),
+		"updated_at":        time.Now(),
+	}, "id = ?", groupId)
+
+	return err
+}
+
+// Verify 依次校验消息长度、链接、文件、慢速模式及刷屏阈值，刷屏的成员会被自动禁言
+// 发言次数由 Record 在消息发送成功后累计，被拒绝的消息不占用慢速模式间隔及刷屏次数
+func (g *GroupAntiSpamService) Verify(ctx context.Context, group *model.Group, opt *AuthOption) error {
+	setting := group.AntiSpam()
+	if !setting.IsEnabled() {
+		return nil
+	}
+
+	if setting.MaxLength > 0 && len([]rune(opt.Content)) > setting.MaxLength {
+		return fmt.Errorf("消息长度不能超过%d个字符！", setting.MaxLength)
+	}
+
+	if setting.BlockLink && isContainsLink(opt.Content) {
+		return errors.New("此群聊已禁止发送链接！")
+	}
+
+	if setting.BlockFile && opt.MsgType == "file" {
+		return errors.New("此群聊已禁止发送文件！")
+	}
+
+	if setting.SlowMode > 0 {
+		if ttl := g.AntiSpamStorage.SlowModeTTL(ctx, group.Id, opt.UserId); ttl > 0 {
+			return fmt.Errorf("此群聊已开启慢速模式，请%d秒后再发送！", int((ttl+time.Second-1)/time.Second))
+		}
+	}
+
+	if setting.FloodLimit <= 0 || setting.FloodWindow <= 0 {
+		return nil
+	}
+
+	if g.AntiSpamStorage.FloodCount(ctx, group.Id, opt.UserId) < int64(setting.FloodLimit) {
+		return nil
+	}
+
+	g.AntiSpamStorage.DelFlood(ctx, group.Id, opt.UserId)
+
+	duration := floodMuteDuration(setting)
+	err := g.GroupMuteService.Mute(ctx, &GroupMuteOpt{
+		GroupId:  group.Id,
+		UserId:   opt.UserId,
+		Duration: duration,
+		Reason:   groupFloodMuteReason,
+	})
+	if err != nil {
+		logger.Errorf("group flood mute error: %s", err.Error())
+	}
+
+	return fmt.Errorf("发送消息过于频繁，已被禁言%s！", formatMuteRemaining(duration))
+}
+
+func (g *GroupAntiSpamService) Record(ctx context.Context, groupId int, uid int) {
+	group, err := g.GroupRepo.FindById(ctx, groupId)
+	if err != nil {
+		return
+	}
+
+	setting := group.AntiSpam()
+	if setting.SlowMode <= 0 && (setting.FloodLimit <= 0 || setting.FloodWindow <= 0) {
+		return
+	}
+
+	member, err := g.GroupMemberRepo.FindByUserId(ctx, groupId, uid)
+	if err != nil || member.Leader != model.GroupMemberLeaderOrdinary {
+		return
+	}
+
+	if setting.SlowMode > 0 {
+		g.AntiSpamStorage.SetSlowMode(ctx, groupId, uid, setting.SlowMode)
+	}
+
+	if setting.FloodLimit > 0 && setting.FloodWindow > 0 {
+		g.AntiSpamStorage.IncrFlood(ctx, groupId, uid, setting.FloodWindow)
+	}
+}
+
+func checkAntiSpamSetting(setting *model.GroupAntiSpam) error {
+	if setting.SlowMode < 0 || setting.SlowMode > model.GroupSlowModeMax {
+		return fmt.Errorf("慢速模式间隔需在0至%d秒之间", model.GroupSlowModeMax)
+	}
+
+	if setting.MaxLength < 0 || setting.MaxLength > model.GroupMsgMaxLength {
+		return fmt.Errorf("消息长度限制需在0至%d个字符之间", model.GroupMsgMaxLength)
+	}
+
+	if setting.FloodLimit < 0 {
+		return errors.New("刷屏阈值不能小于0")
+	}
+
+	if setting.FloodLimit == 0 {
+		setting.FloodWindow, setting.FloodMute = 0, 0
+		return nil
+	}
+
+	if setting.FloodWindow <= 0 || setting.FloodWindow > model.GroupFloodWindowMax {
+		return fmt.Errorf("刷屏统计时间需在1至%d秒之间", model.GroupFloodWindowMax)
+	}
+
+	mute := time.Duration(setting.FloodMute) * time.Second
+	if setting.FloodMute != 0 && (mute < GroupMuteMinDuration || mute > GroupMuteMaxDuration) {
+		return fmt.Errorf("自动禁言时长需在%s至%s之间", formatMuteRemaining(GroupMuteMinDuration), formatMuteRemaining(GroupMuteMaxDuration))
+	}
+
+	return nil
+}
+
+// floodMuteDuration 刷屏自动禁言时长，未设置时使用默认时长
+func floodMuteDuration(setting *model.GroupAntiSpam) time.Duration {
+	if setting.FloodMute <= 0 {
+		return model.GroupFloodMuteDefault * time.Second
+	}
+
+	return time.Duration(setting.FloodMute) * time.Second
+}
+
+func isContainsLink(content string) bool {
+	return content != "" && linkRegexp.MatchString(content)
+}
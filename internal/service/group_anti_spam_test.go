@@ -0,0 +1,61 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gzydong/go-chat/internal/repository/model"
+)
+
+func TestIsContainsLink(t *testing.T) {
+	cases := []struct {
+		content string
+		want    bool
+	}{
+		{content: "访问 https://example.com/a?b=1 查看", want: true},
+		{content: "www.example.org", want: true},
+		{content: "加我 abc.cn 领福利", want: true},
+		{content: "明天10.30开会", want: false},
+		{content: "普通消息", want: false},
+		{content: "", want: false},
+	}
+
+	for _, c := range cases {
+		if got := isContainsLink(c.content); got != c.want {
+			t.Errorf("isContainsLink(%q) = %v, want %v", c.content, got, c.want)
+		}
+	}
+}
+
+func TestCheckAntiSpamSetting(t *testing.T) {
+	cases := []struct {
+		name    string
+		setting *model.GroupAntiSpam
+		wantErr bool
+	}{
+		{name: "disabled", setting: &model.GroupAntiSpam{}},
+		{name: "slow mode", setting: &model.GroupAntiSpam{SlowMode: 30, MaxLength: 500}},
+		{name: "slow mode too long", setting: &model.GroupAntiSpam{SlowMode: model.GroupSlowModeMax + 1}, wantErr: true},
+		{name: "flood without window", setting: &model.GroupAntiSpam{FloodLimit: 10}, wantErr: true},
+		{name: "flood mute too short", setting: &model.GroupAntiSpam{FloodLimit: 10, FloodWindow: 10, FloodMute: 30}, wantErr: true},
+		{name: "flood", setting: &model.GroupAntiSpam{FloodLimit: 10, FloodWindow: 10, FloodMute: 300}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if err := checkAntiSpamSetting(c.setting); (err != nil) != c.wantErr {
+				t.Errorf("checkAntiSpamSetting() error = %v, wantErr %v", err, c.wantErr)
+			}
+		})
+	}
+}
+
+func TestFloodMuteDuration(t *testing.T) {
+	if got := floodMuteDuration(&model.GroupAntiSpam{}); got != model.GroupFloodMuteDefault*time.Second {
+		t.Errorf("floodMuteDuration() = %v, want default", got)
+	}
+
+	if got := floodMuteDuration(&model.GroupAntiSpam{FloodMute: 120}); got != 2*time.Minute {
+		t.Errorf("floodMuteDuration() = %v, want 2m", got)
+	}
+}
@@ -4,8 +4,14 @@ import (
 	"context"
 	"errors"
 
+	"github.com/gzydong/go-chat/internal/entity"
+	"github.com/gzydong/go-chat/internal/logic"
+	"github.com/gzydong/go-chat/internal/pkg/jsonutil"
+	"github.com/gzydong/go-chat/internal/pkg/timeutil"
+	"github.com/gzydong/go-chat/internal/repository/cache"
 	"github.com/gzydong/go-chat/internal/repository/model"
 	"github.com/gzydong/go-chat/internal/repository/repo"
+	"gorm.io/gorm"
 )
 
 var _ IGroupApplyService = (*GroupApplyService)(nil)
@@ -14,11 +20,16 @@ type IGroupApplyService interface {
 	Auth(ctx context.Context, applyId, userId int) bool
 	Insert(ctx context.Context, groupId, userId int, remark string) error
 	Delete(ctx context.Context, applyId, userId int) error
+	// Create 提交入群申请，已有待审核的申请时更新申请备注，返回申请ID
+	Create(ctx context.Context, groupId, userId int, remark string) (int, error)
 }
 
 type GroupApplyService struct {
 	*repo.Source
-	GroupApplyRepo *repo.GroupApply
+	GroupApplyRepo    *repo.GroupApply
+	GroupMemberRepo   *repo.GroupMember
+	GroupApplyStorage *cache.GroupApplyStorage
+	PushMessage       *logic.PushMessage
 }
 
 func (s *GroupApplyService) Auth(ctx context.Context, applyId, userId int) bool {
@@ -49,3 +60,53 @@ func (s *GroupApplyService) Delete(ctx context.Context, applyId, userId int) err
 
 	return s.Source.Db().WithContext(ctx).Delete(&model.GroupApply{}, "id = ?", applyId).Error
 }
+
+func (s *GroupApplyService) Create(ctx context.Context, groupId, userId int, remark string) (int, error) {
+	apply, err := s.GroupApplyRepo.FindByWhere(ctx, "group_id = ? and user_id = ? and status = ?", groupId, userId, model.GroupApplyStatusWait)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, err
+	}
+
+	applyId := 0
+	if apply == nil {
+		data := &model.GroupApply{
+			GroupId: groupId,
+			UserId:  userId,
+			Status:  model.GroupApplyStatusWait,
+			Remark:  remark,
+		}
+
+		err = s.GroupApplyRepo.Create(ctx, data)
+		if err == nil {
+			applyId = data.Id
+		}
+	} else {
+		applyId = apply.Id
+		data := map[string]any{
+			"remark":     remark,
+			"updated_at": timeutil.DateTime(),
+		}
+
+		_, err = s.GroupApplyRepo.UpdateByWhere(ctx, data, "id = ?", apply.Id)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	find, err := s.GroupMemberRepo.FindByWhere(ctx, "group_id = ? and leader = ?", groupId, model.GroupMemberLeaderOwner)
+	if err == nil && find != nil {
+		s.GroupApplyStorage.Incr(ctx, find.UserId)
+	}
+
+	_ = s.PushMessage.Push(ctx, entity.ImChannelChat, &entity.SubscribeMessage{
+		Event: entity.SubEventGroupApply,
+		Payload: jsonutil.Encode(entity.SubEventGroupApplyPayload{
+			GroupId: groupId,
+			UserId:  userId,
+			ApplyId: applyId,
+		}),
+	})
+
+	return applyId, nil
+}
@@ -0,0 +1,297 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gzydong/go-chat/internal/entity"
+	"github.com/gzydong/go-chat/internal/pkg/strutil"
+	"github.com/gzydong/go-chat/internal/repository/model"
+	"github.com/gzydong/go-chat/internal/repository/repo"
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+)
+
+const (
+	GroupJoinStatusJoined  = 1 // 已加入群聊
+	GroupJoinStatusApplied = 2 // 已提交入群申请，等待审核
+
+	GroupInviteLinkMaxExpire = 30 * 24 * time.Hour // 邀请链接最长有效期
+	GroupInviteLinkMaxUsage  = 10000               // 邀请链接最大使用次数上限
+
+	groupJoinQuestionMaxLen = 100 // 入群问题及答案最大长度
+)
+
+var _ IGroupJoinService = (*GroupJoinService)(nil)
+
+type GroupInviteLinkCreateOpt struct {
+	UserId   int           // 创建者ID
+	GroupId  int           // 群ID
+	Expire   time.Duration // 有效期，为 0 时永久有效
+	MaxUsage int           // 最大使用次数，为 0 时不限制
+}
+
+type GroupJoinOpt struct {
+	UserId  int    // 加入者ID
+	GroupId int    // 群ID，通过邀请链接加入时可为空
+	Token   string // 邀请链接标识
+	Answer  string // 入群问题答案
+	Remark  string // 入群申请备注
+}
+
+type GroupJoinModeOpt struct {
+	UserId   int    // 操作人ID
+	GroupId  int    // 群ID
+	Mode     int    // 入群方式
+	Question string // 入群问题，仅回答问题入群时有效
+	Answer   string // 入群问题答案，仅回答问题入群时有效
+}
+
+type IGroupJoinService interface {
+	// CreateLink 创建群邀请链接
+	CreateLink(ctx context.Context, opt *GroupInviteLinkCreateOpt) (*model.GroupInviteLink, error)
+	// ListLinks 获取群邀请链接，群主及管理员可查看全部链接，其他成员仅可查看自己创建的链接
+	ListLinks(ctx context.Context, groupId int, uid int) ([]*model.GroupInviteLink, error)
+	// RevokeLink 撤销邀请链接，仅创建者、群主及管理员可操作
+	RevokeLink(ctx context.Context, linkId int, uid int) error
+	// FindLink 根据链接标识获取可用的邀请链接及所属群
+	FindLink(ctx context.Context, token string) (*model.GroupInviteLink, *model.Group, error)
+	// Join 通过邀请链接或群ID加入群聊，需审核的群提交入群申请，返回加入状态
+	Join(ctx context.Context, opt *GroupJoinOpt) (int, error)
+	// UpdateJoinMode 修改入群方式
+	UpdateJoinMode(ctx context.Context, opt *GroupJoinModeOpt) error
+}
+
+type GroupJoinService struct {
+	GroupRepo           *repo.Group
+	GroupMemberRepo     *repo.GroupMember
+	GroupInviteLinkRepo *repo.GroupInviteLink
+	GroupService        IGroupService
+	GroupApplyService   IGroupApplyService
+	GroupPermission     IGroupPermissionService
+}
+
+func (g *GroupJoinService) CreateLink(ctx context.Context, opt *GroupInviteLinkCreateOpt) (*model.GroupInviteLink, error) {
+	if opt.Expire < 0 || opt.Expire > GroupInviteLinkMaxExpire {
+		return nil, fmt.Errorf("邀请链接有效期不能超过%d天", int(GroupInviteLinkMaxExpire.Hours()/24))
+	}
+
+	if opt.MaxUsage < 0 || opt.MaxUsage > GroupInviteLinkMaxUsage {
+		return nil, fmt.Errorf("邀请链接使用次数不能超过%d次", GroupInviteLinkMaxUsage)
+	}
+
+	if err := g.GroupPermission.Check(ctx, opt.GroupId, opt.UserId, model.GroupPermInvite); err != nil {
+		return nil, err
+	}
+
+	link := &model.GroupInviteLink{
+		GroupId:   opt.GroupId,
+		Token:     strutil.NewMsgId(),
+		CreatorId: opt.UserId,
+		MaxUsage:  opt.MaxUsage,
+		Status:    model.GroupInviteLinkStatusNormal,
+	}
+
+	if opt.Expire > 0 {
+		link.ExpireAt = lo.ToPtr(time.Now().Add(opt.Expire).Truncate(time.Second))
+	}
+
+	if err := g.GroupInviteLinkRepo.Create(ctx, link); err != nil {
+		return nil, err
+	}
+
+	return link, nil
+}
+
+func (g *GroupJoinService) ListLinks(ctx context.Context, groupId int, uid int) ([]*model.GroupInviteLink, error) {
+	if !g.GroupMemberRepo.IsMember(ctx, groupId, uid, true) {
+		return nil, entity.ErrPermissionDenied
+	}
+
+	isLeader := g.GroupMemberRepo.IsLeader(ctx, groupId, uid)
+
+	return g.GroupInviteLinkRepo.FindAll(ctx, func(db *gorm.DB) {
+		db.Where("group_id = ? and status = ?", groupId, model.GroupInviteLinkStatusNormal)
+
+		if !isLeader {
+			db.Where("creator_id = ?", uid)
+		}
+
+		db.Order("id desc")
+	})
+}
+
+func (g *GroupJoinService) RevokeLink(ctx context.Context, linkId int, uid int) error {
+	link, err := g.GroupInviteLinkRepo.FindById(ctx, linkId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entity.ErrDataNotFound
+		}
+
+		return err
+	}
+
+	if link.CreatorId != uid && !g.GroupMemberRepo.IsLeader(ctx, link.GroupId, uid) {
+		return entity.ErrPermissionDenied
+	}
+
+	if link.Status == model.GroupInviteLinkStatusRevoked {
+		return nil
+	}
+
+	_, err = g.GroupInviteLinkRepo.UpdateById(ctx, link.Id, map[string]any{
+		"status":     model.GroupInviteLinkStatusRevoked,
+		"updated_at": time.Now(),
+	})
+
+	return err
+}
+
+func (g *GroupJoinService) FindLink(ctx context.Context, token string) (*model.GroupInviteLink, *model.Group, error) {
+	link, err := g.GroupInviteLinkRepo.FindByToken(ctx, token)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, errors.New("邀请链接不存在")
+		}
+
+		return nil, nil, err
+	}
+
+	if !link.IsAvailable(time.Now()) {
+		return nil, nil, errors.New("邀请链接已失效")
+	}
+
+	group, err := g.findGroup(ctx, link.GroupId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return link, group, nil
+}
+
+func (g *GroupJoinService) Join(ctx context.Context, opt *GroupJoinOpt) (int, error) {
+	var (
+		link  *model.GroupInviteLink
+		group *model.Group
+		err   error
+	)
+
+	if opt.Token != "" {
+		link, group, err = g.FindLink(ctx, opt.Token)
+	} else {
+		group, err = g.findGroup(ctx, opt.GroupId)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	if g.GroupMemberRepo.IsMember(ctx, group.Id, opt.UserId, false) {
+		return 0, errors.New("你已是该群成员")
+	}
+
+	mode := group.GetJoinMode()
+	switch mode {
+	case model.GroupJoinModeInvite:
+		if link == nil {
+			return 0, errors.New("该群仅支持通过邀请加入")
+		}
+	case model.GroupJoinModeQuestion:
+		if !isJoinAnswerMatched(group.JoinAnswer, opt.Answer) {
+			return 0, errors.New("入群问题回答错误")
+		}
+	}
+
+	// 先占用链接使用次数，加入失败后归还，避免并发加入时超出使用上限
+	if link != nil {
+		ok, err := g.GroupInviteLinkRepo.IncrUsage(ctx, link.Id)
+		if err != nil {
+			return 0, err
+		}
+
+		if !ok {
+			return 0, errors.New("邀请链接已失效")
+		}
+	}
+
+	status := GroupJoinStatusJoined
+	if mode == model.GroupJoinModeApproval {
+		status = GroupJoinStatusApplied
+		_, err = g.GroupApplyService.Create(ctx, group.Id, opt.UserId, opt.Remark)
+	} else {
+		err = g.GroupService.Invite(ctx, &GroupInviteOpt{
+			UserId:    lo.Ternary(link != nil, lo.FromPtr(link).CreatorId, opt.UserId),
+			GroupId:   group.Id,
+			MemberIds: []int{opt.UserId},
+			IsApply:   true,
+		})
+	}
+
+	if err != nil {
+		if link != nil {
+			g.GroupInviteLinkRepo.DecrUsage(ctx, link.Id)
+		}
+
+		return 0, err
+	}
+
+	return status, nil
+}
+
+func (g *GroupJoinService) UpdateJoinMode(ctx context.Context, opt *GroupJoinModeOpt) error {
+	if opt.Mode < model.GroupJoinModeFree || opt.Mode > model.GroupJoinModeQuestion {
+		return errors.New("入群方式不正确")
+	}
+
+	if err := g.GroupPermission.Check(ctx, opt.GroupId, opt.UserId, model.GroupPermEditProfile); err != nil {
+		return err
+	}
+
+	data := map[string]any{
+		"join_mode":  opt.Mode,
+		"updated_at": time.Now(),
+	}
+
+	if opt.Mode == model.GroupJoinModeQuestion {
+		question, answer := strings.TrimSpace(opt.Question), strings.TrimSpace(opt.Answer)
+		if question == "" || answer == "" {
+			return errors.New("请设置入群问题及答案")
+		}
+
+		if len([]rune(question)) > groupJoinQuestionMaxLen || len([]rune(answer)) > groupJoinQuestionMaxLen {
+			return fmt.Errorf("入群问题及答案不能超过%d个字符", groupJoinQuestionMaxLen)
+		}
+
+		data["join_question"] = question
+		data["join_answer"] = answer
+	}
+
+	_, err := g.GroupRepo.UpdateById(ctx, opt.GroupId, data)
+	return err
+}
+
+func (g *GroupJoinService) findGroup(ctx context.Context, groupId int) (*model.Group, error) {
+	group, err := g.GroupRepo.FindById(ctx, groupId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, entity.ErrGroupNotExist
+		}
+
+		return nil, err
+	}
+
+	if group.IsDismiss == model.Yes {
+		return nil, entity.ErrGroupDismissed
+	}
+
+	return group, nil
+}
+
+// isJoinAnswerMatched 校验入群问题答案，忽略首尾空白及大小写
+func isJoinAnswerMatched(expect string, answer string) bool {
+	expect, answer = strings.TrimSpace(expect), strings.TrimSpace(answer)
+	return expect != "" && strings.EqualFold(expect, answer)
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gzydong/go-chat/internal/repository/model"
+	"github.com/samber/lo"
+)
+
+func TestIsJoinAnswerMatched(t *testing.T) {
+	cases := []struct {
+		expect string
+		answer string
+		want   bool
+	}{
+		{expect: "Go", answer: "go", want: true},
+		{expect: "Go", answer: "  GO ", want: true},
+		{expect: "Go", answer: "golang", want: false},
+		{expect: "", answer: "", want: false},
+	}
+
+	for _, c := range cases {
+		if got := isJoinAnswerMatched(c.expect, c.answer); got != c.want {
+			t.Errorf("isJoinAnswerMatched(%q, %q) = %v, want %v", c.expect, c.answer, got, c.want)
+		}
+	}
+}
+
+func TestGroupInviteLinkIsAvailable(t *testing.T) {
+	now := time.Now()
+
+	cases := []struct {
+		name string
+		link *model.GroupInviteLink
+		want bool
+	}{
+		{name: "permanent", link: &model.GroupInviteLink{Status: model.GroupInviteLinkStatusNormal}, want: true},
+		{name: "revoked", link: &model.GroupInviteLink{Status: model.GroupInviteLinkStatusRevoked}, want: false},
+		{name: "expired", link: &model.GroupInviteLink{Status: model.GroupInviteLinkStatusNormal, ExpireAt: lo.ToPtr(now)}, want: false},
+		{name: "not expired", link: &model.GroupInviteLink{Status: model.GroupInviteLinkStatusNormal, ExpireAt: lo.ToPtr(now.Add(time.Hour))}, want: true},
+		{name: "usage exhausted", link: &model.GroupInviteLink{Status: model.GroupInviteLinkStatusNormal, MaxUsage: 2, UsageCount: 2}, want: false},
+		{name: "usage remaining", link: &model.GroupInviteLink{Status: model.GroupInviteLinkStatusNormal, MaxUsage: 2, UsageCount: 1}, want: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := c.link.IsAvailable(now); got != c.want {
+				t.Errorf("IsAvailable() = %v, want %v", got, c.want)
+			}
+		})
+	}
+}
@@ -39,9 +39,18 @@ func (g *GroupMemberService) Handover(ctx context.Context, groupId int, userId i
 }
 
 func (g *GroupMemberService) SetLeaderStatus(ctx context.Context, groupId int, userId int, leader int) error {
-	return g.GroupMemberRepo.Model(ctx).Where("group_id = ? and user_id = ?", groupId, userId).UpdateColumn("leader", leader).Error
+	// 身份变更后重置管理员权限项，重新任命的管理员默认拥有全部权限
+	return g.GroupMemberRepo.Model(ctx).Where("group_id = ? and user_id = ?", groupId, userId).UpdateColumns(map[string]any{
+		"leader":      leader,
+		"permissions": "",
+	}).Error
 }
 
+// SetMuteStatus 设置成员禁言状态，禁言为永久禁言，限时禁言见 GroupMuteService
 func (g *GroupMemberService) SetMuteStatus(ctx context.Context, groupId int, userId int, status int) error {
-	return g.GroupMemberRepo.Model(ctx).Where("group_id = ? and user_id = ?", groupId, userId).UpdateColumn("is_mute", status).Error
+	return g.GroupMemberRepo.Model(ctx).Where("group_id = ? and user_id = ?", groupId, userId).UpdateColumns(map[string]any{
+		"is_mute":        status,
+		"mute_expire_at": nil,
+		"mute_reason":    "",
+	}).Error
 }
@@ -0,0 +1,378 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gzydong/go-chat/internal/entity"
+	"github.com/gzydong/go-chat/internal/logic"
+	"github.com/gzydong/go-chat/internal/pkg/jsonutil"
+	"github.com/gzydong/go-chat/internal/pkg/logger"
+	"github.com/gzydong/go-chat/internal/repository/model"
+	"github.com/gzydong/go-chat/internal/repository/repo"
+	"github.com/gzydong/go-chat/internal/service/message"
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+)
+
+const (
+	GroupMuteTypeMute   = 1 // 禁言
+	GroupMuteTypeUnmute = 2 // 解除禁言
+
+	GroupMuteMinDuration = time.Minute         // 最短禁言时长
+	GroupMuteMaxDuration = 30 * 24 * time.Hour // 最长禁言时长
+
+	groupMuteMaxReasonLen = 100 // 禁言原因最大长度
+	groupMuteReleaseLimit = 500 // 单次解除到期禁言的最大数量
+	groupMuteSystemName   = "系统"
+)
+
+var _ IGroupMuteService = (*GroupMuteService)(nil)
+
+type GroupMuteOpt struct {
+	OperatorId int           // 操作人ID，为 0 时表示系统自动禁言
+	GroupId    int           // 群ID
+	UserId     int           // 禁言成员ID，为 0 时表示全员禁言
+	Duration   time.Duration // 禁言时长，为 0 时表示永久禁言
+	Reason     string        // 禁言原因
+}
+
+type IGroupMuteService interface {
+	// Mute 禁言群成员或开启全员禁言
+	Mute(ctx context.Context, opt *GroupMuteOpt) error
+	// Unmute 解除群成员禁言或全员禁言，userId 为 0 时表示解除全员禁言
+	Unmute(ctx context.Context, operatorId int, groupId int, userId int) error
+	// ReleaseExpired 解除已到期的禁言，返回解除的数量
+	ReleaseExpired(ctx context.Context) (int, error)
+}
+
+type GroupMuteService struct {
+	*repo.Source
+	GroupRepo       *repo.Group
+	GroupMemberRepo *repo.GroupMember
+	UsersRepo       *repo.Users
+	PushMessage     *logic.PushMessage
+	MessageService  message.IService
+}
+
+func (g *GroupMuteService) Mute(ctx context.Context, opt *GroupMuteOpt) error {
+	if opt.Duration < 0 || opt.Duration > GroupMuteMaxDuration || (opt.Duration > 0 && opt.Duration < GroupMuteMinDuration) {
+		return fmt.Errorf("禁言时长需在%s至%s之间", formatMuteRemaining(GroupMuteMinDuration), formatMuteRemaining(GroupMuteMaxDuration))
+	}
+
+	opt.Reason = strings.TrimSpace(opt.Reason)
+	if len([]rune(opt.Reason)) > groupMuteMaxReasonLen {
+		return fmt.Errorf("禁言原因不能超过%d个字符", groupMuteMaxReasonLen)
+	}
+
+	var (
+		expireAt   *time.Time
+		expireUnix int64
+	)
+
+	if opt.Duration > 0 {
+		expireAt = lo.ToPtr(time.Now().Add(opt.Duration).Truncate(time.Second))
+		expireUnix = expireAt.Unix()
+	}
+
+	data := map[string]any{
+		"is_mute":        model.Yes,
+		"mute_expire_at": expireAt,
+		"mute_reason":    opt.Reason,
+		"updated_at":     time.Now(),
+	}
+
+	operator, err := g.findOperator(ctx, opt.OperatorId)
+	if err != nil {
+		return err
+	}
+
+	if opt.UserId == 0 {
+		if _, err := g.GroupRepo.UpdateByWhere(ctx, data, "id = ?", opt.GroupId); err != nil {
+			return err
+		}
+
+		_ = g.MessageService.CreateGroupMessage(ctx, message.CreateGroupMessageOption{
+			MsgType:  entity.ChatMsgSysGroupMuted,
+			FromId:   opt.OperatorId,
+			ToFromId: opt.GroupId,
+			Extra: jsonutil.Encode(model.TalkRecordExtraGroupMuted{
+				OwnerId:   operator.Id,
+				OwnerName: operator.Nickname,
+				ExpireAt:  formatMuteExpireAt(expireAt),
+				Reason:    opt.Reason,
+			}),
+		})
+	} else {
+		member, err := g.GroupMemberRepo.FindByUserId(ctx, opt.GroupId, opt.UserId)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("禁言的用户不是群成员")
+			}
+
+			return err
+		}
+
+		if member.IsQuit == model.Yes {
+			return errors.New("禁言的用户不是群成员")
+		}
+
+		if opt.UserId == opt.OperatorId || member.Leader == model.GroupMemberLeaderOwner {
+			return errors.New("不能禁言自己或群主")
+		}
+
+		if _, err := g.GroupMemberRepo.UpdateByWhere(ctx, data, "id = ?", member.Id); err != nil {
+			return err
+		}
+
+		_ = g.MessageService.CreateGroupMessage(ctx, message.CreateGroupMessageOption{
+			MsgType:  entity.ChatMsgSysGroupMemberMuted,
+			FromId:   opt.OperatorId,
+			ToFromId: opt.GroupId,
+			Extra: jsonutil.Encode(model.TalkRecordExtraGroupMemberMuted{
+				OwnerId:   operator.Id,
+				OwnerName: operator.Nickname,
+				Members:   g.findMembers(ctx, []int{opt.UserId}),
+				ExpireAt:  formatMuteExpireAt(expireAt),
+				Reason:    opt.Reason,
+			}),
+		})
+	}
+
+	g.notify(ctx, &entity.SubEventGroupMutePayload{
+		GroupId:    opt.GroupId,
+		UserId:     opt.UserId,
+		OperatorId: opt.OperatorId,
+		Type:       GroupMuteTypeMute,
+		Reason:     opt.Reason,
+		ExpireAt:   expireUnix,
+	})
+
+	return nil
+}
+
+func (g *GroupMuteService) Unmute(ctx context.Context, operatorId int, groupId int, userId int) error {
+	data := map[string]any{
+		"is_mute":        model.No,
+		"mute_expire_at": nil,
+		"mute_reason":    "",
+		"updated_at":     time.Now(),
+	}
+
+	var (
+		affected int64
+		err      error
+	)
+
+	if userId == 0 {
+		affected, err = g.GroupRepo.UpdateByWhere(ctx, data, "id = ? and is_mute = ?", groupId, model.Yes)
+	} else {
+		affected, err = g.GroupMemberRepo.UpdateByWhere(ctx, data, "group_id = ? and user_id = ? and is_mute = ?", groupId, userId, model.Yes)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	// 未处于禁言状态时不重复通知
+	if affected == 0 {
+		return nil
+	}
+
+	operator, err := g.UsersRepo.FindByIdWithCache(ctx, operatorId)
+	if err != nil {
+		return err
+	}
+
+	if userId == 0 {
+		_ = g.MessageService.CreateGroupMessage(ctx, message.CreateGroupMessageOption{
+			MsgType:  entity.ChatMsgSysGroupCancelMuted,
+			FromId:   operatorId,
+			ToFromId: groupId,
+			Extra: jsonutil.Encode(model.TalkRecordExtraGroupCancelMuted{
+				OwnerId:   operator.Id,
+				OwnerName: operator.Nickname,
+			}),
+		})
+	} else {
+		_ = g.MessageService.CreateGroupMessage(ctx, message.CreateGroupMessageOption{
+			MsgType:  entity.ChatMsgSysGroupMemberCancelMuted,
+			FromId:   operatorId,
+			ToFromId: groupId,
+			Extra: jsonutil.Encode(model.TalkRecordExtraGroupMemberCancelMuted{
+				OwnerId:   operator.Id,
+				OwnerName: operator.Nickname,
+				Members:   g.findMembers(ctx, []int{userId}),
+			}),
+		})
+	}
+
+	g.notify(ctx, &entity.SubEventGroupMutePayload{
+		GroupId:    groupId,
+		UserId:     userId,
+		OperatorId: operatorId,
+		Type:       GroupMuteTypeUnmute,
+	})
+
+	return nil
+}
+
+func (g *GroupMuteService) ReleaseExpired(ctx context.Context) (int, error) {
+	now := time.Now()
+
+	groups, err := g.GroupRepo.FindAll(ctx, func(db *gorm.DB) {
+		db.Select("id").Where("is_mute = ? and mute_expire_at <= ?", model.Yes, now).Limit(groupMuteReleaseLimit)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	members, err := g.GroupMemberRepo.FindAll(ctx, func(db *gorm.DB) {
+		db.Select("id,group_id,user_id").Where("is_mute = ? and mute_expire_at <= ?", model.Yes, now).Limit(groupMuteReleaseLimit)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	data := map[string]any{
+		"is_mute":        model.No,
+		"mute_expire_at": nil,
+		"mute_reason":    "",
+		"updated_at":     now,
+	}
+
+	count := 0
+	for _, group := range groups {
+		// 按截止时间条件更新，避免覆盖期间重新设置的禁言
+		affected, err := g.GroupRepo.UpdateByWhere(ctx, data, "id = ? and is_mute = ? and mute_expire_at <= ?", group.Id, model.Yes, now)
+		if err != nil {
+			return count, err
+		}
+
+		if affected == 0 {
+			continue
+		}
+
+		count++
+
+		_ = g.MessageService.CreateGroupMessage(ctx, message.CreateGroupMessageOption{
+			MsgType:  entity.ChatMsgSysGroupCancelMuted,
+			FromId:   0,
+			ToFromId: group.Id,
+			Extra: jsonutil.Encode(model.TalkRecordExtraGroupCancelMuted{
+				OwnerName: groupMuteSystemName,
+			}),
+		})
+
+		g.notify(ctx, &entity.SubEventGroupMutePayload{
+			GroupId: group.Id,
+			Type:    GroupMuteTypeUnmute,
+		})
+	}
+
+	released := make(map[int][]int)
+	for _, member := range members {
+		affected, err := g.GroupMemberRepo.UpdateByWhere(ctx, data, "id = ? and is_mute = ? and mute_expire_at <= ?", member.Id, model.Yes, now)
+		if err != nil {
+			return count, err
+		}
+
+		if affected == 0 {
+			continue
+		}
+
+		count++
+		released[member.GroupId] = append(released[member.GroupId], member.UserId)
+
+		g.notify(ctx, &entity.SubEventGroupMutePayload{
+			GroupId: member.GroupId,
+			UserId:  member.UserId,
+			Type:    GroupMuteTypeUnmute,
+		})
+	}
+
+	// 同一个群到期的成员合并为一条系统消息
+	for groupId, uids := range released {
+		_ = g.MessageService.CreateGroupMessage(ctx, message.CreateGroupMessageOption{
+			MsgType:  entity.ChatMsgSysGroupMemberCancelMuted,
+			FromId:   0,
+			ToFromId: groupId,
+			Extra: jsonutil.Encode(model.TalkRecordExtraGroupMemberCancelMuted{
+				OwnerName: groupMuteSystemName,
+				Members:   g.findMembers(ctx, uids),
+			}),
+		})
+	}
+
+	return count, nil
+}
+
+// findOperator 获取操作人信息，操作人ID为 0 时表示系统操作
+func (g *GroupMuteService) findOperator(ctx context.Context, operatorId int) (*model.Users, error) {
+	if operatorId == 0 {
+		return &model.Users{Nickname: groupMuteSystemName}, nil
+	}
+
+	return g.UsersRepo.FindByIdWithCache(ctx, operatorId)
+}
+
+func (g *GroupMuteService) findMembers(ctx context.Context, uids []int) []model.TalkRecordExtraGroupMember {
+	members := make([]model.TalkRecordExtraGroupMember, 0, len(uids))
+	g.Source.Db().WithContext(ctx).Model(&model.Users{}).Select("id as user_id", "nickname").Where("id in ?", uids).Scan(&members)
+	return members
+}
+
+func (g *GroupMuteService) notify(ctx context.Context, payload *entity.SubEventGroupMutePayload) {
+	err := g.PushMessage.Push(ctx, entity.ImTopicChat, &entity.SubscribeMessage{
+		Event:   entity.SubEventGroupMute,
+		Payload: jsonutil.Encode(payload),
+	})
+	if err != nil {
+		logger.Errorf("group mute push error: %s", err.Error())
+	}
+}
+
+func formatMuteExpireAt(expireAt *time.Time) string {
+	if expireAt == nil {
+		return ""
+	}
+
+	return expireAt.Format(time.DateTime)
+}
+
+// formatMuteRemaining 格式化禁言剩余时长，不足一分钟按一分钟展示
+func formatMuteRemaining(d time.Duration) string {
+	minutes := int64((d + time.Minute - 1) / time.Minute)
+	if minutes < 1 {
+		minutes = 1
+	}
+
+	days, hours, minutes := minutes/(24*60), minutes%(24*60)/60, minutes%60
+
+	var sb strings.Builder
+	if days > 0 {
+		sb.WriteString(fmt.Sprintf("%d天", days))
+	}
+
+	if hours > 0 {
+		sb.WriteString(fmt.Sprintf("%d小时", hours))
+	}
+
+	if minutes > 0 {
+		sb.WriteString(fmt.Sprintf("%d分钟", minutes))
+	}
+
+	return sb.String()
+}
+
+// muteErrorMessage 生成禁言提示，限时禁言附带剩余时长
+func muteErrorMessage(prefix string, expireAt *time.Time, now time.Time) string {
+	if expireAt == nil {
+		return prefix + "！"
+	}
+
+	return fmt.Sprintf("%s，剩余%s！", prefix, formatMuteRemaining(expireAt.Sub(now)))
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gzydong/go-chat/internal/repository/model"
+)
+
+func TestFormatMuteRemaining(t *testing.T) {
+	cases := map[time.Duration]string{
+		10 * time.Second:              "1分钟",
+		10 * time.Minute:              "10分钟",
+		10*time.Minute + time.Second:  "11分钟",
+		time.Hour:                     "1小时",
+		24 * time.Hour:                "1天",
+		26*time.Hour + 30*time.Minute: "1天2小时30分钟",
+		GroupMuteMaxDuration:          "30天",
+		-5 * time.Second:              "1分钟",
+	}
+
+	for d, expect := range cases {
+		if got := formatMuteRemaining(d); got != expect {
+			t.Errorf("formatMuteRemaining(%s) = %q, want %q", d, got, expect)
+		}
+	}
+}
+
+func TestMuteErrorMessage(t *testing.T) {
+	now := time.Date(2024, 1, 2, 15, 4, 5, 0, time.Local)
+	expireAt := now.Add(90 * time.Minute)
+
+	if got := muteErrorMessage("已被群主或管理员禁言", nil, now); got != "已被群主或管理员禁言！" {
+		t.Errorf("permanent mute message = %q", got)
+	}
+
+	if got := muteErrorMessage("已被群主或管理员禁言", &expireAt, now); got != "已被群主或管理员禁言，剩余1小时30分钟！" {
+		t.Errorf("timed mute message = %q", got)
+	}
+}
+
+func TestIsMuteActive(t *testing.T) {
+	now := time.Date(2024, 1, 2, 15, 4, 5, 0, time.Local)
+	past, future := now.Add(-time.Second), now.Add(time.Minute)
+
+	cases := []struct {
+		name     string
+		isMute   int
+		expireAt *time.Time
+		expect   bool
+	}{
+		{name: "not muted", isMute: model.No, expireAt: nil, expect: false},
+		{name: "permanent", isMute: model.Yes, expireAt: nil, expect: true},
+		{name: "timed", isMute: model.Yes, expireAt: &future, expect: true},
+		{name: "expired", isMute: model.Yes, expireAt: &past, expect: false},
+		{name: "expire at now", isMute: model.Yes, expireAt: &now, expect: false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := model.IsMuteActive(c.isMute, c.expireAt, now); got != c.expect {
+				t.Errorf("IsMuteActive() = %v, want %v", got, c.expect)
+			}
+		})
+	}
+}
@@ -0,0 +1,382 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gzydong/go-chat/internal/entity"
+	"github.com/gzydong/go-chat/internal/logic"
+	"github.com/gzydong/go-chat/internal/pkg/jsonutil"
+	"github.com/gzydong/go-chat/internal/pkg/logger"
+	"github.com/gzydong/go-chat/internal/repository/cache"
+	"github.com/gzydong/go-chat/internal/repository/model"
+	"github.com/gzydong/go-chat/internal/repository/repo"
+	"github.com/gzydong/go-chat/internal/service/message"
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+)
+
+const (
+	GroupNoticeEventPublish = 1 // 发布公告
+	GroupNoticeEventRemind  = 2 // 提醒确认
+
+	groupNoticeMaxTitleLen    = 64  // 公告标题最大长度
+	groupNoticeRemindInterval = 300 // 同一公告提醒确认的最小间隔(秒)
+)
+
+var _ IGroupNoticeService = (*GroupNoticeService)(nil)
+
+type GroupNoticePublishOpt struct {
+	UserId    int    // 操作人ID
+	GroupId   int    // 群ID
+	NoticeId  int    // 公告ID，为 0 时发布新公告
+	Title     string // 公告标题
+	Content   string // 公告内容
+	IsTop     bool   // 是否置顶
+	IsConfirm bool   // 是否需群成员确认
+}
+
+type GroupNoticeItem struct {
+	Notice       *model.GroupNotice
+	IsConfirmed  bool // 当前用户是否已确认
+	ConfirmCount int  // 已确认人数，仅需确认的公告统计
+}
+
+type IGroupNoticeService interface {
+	// Publish 发布或修改群公告，返回公告ID
+	Publish(ctx context.Context, opt *GroupNoticePublishOpt) (int, error)
+	// Delete 删除群公告
+	Delete(ctx context.Context, groupId int, noticeId int, uid int) error
+	// SetTop 置顶或取消置顶群公告
+	SetTop(ctx context.Context, groupId int, noticeId int, uid int, isTop bool) error
+	// List 获取群公告历史
+	List(ctx context.Context, groupId int, uid int) ([]*GroupNoticeItem, error)
+	// Confirm 确认群公告
+	Confirm(ctx context.Context, groupId int, noticeId int, uid int) error
+	// Unconfirmed 获取未确认公告的群成员ID[群主及管理员权限]
+	Unconfirmed(ctx context.Context, groupId int, noticeId int, uid int) ([]int, error)
+	// Remind 提醒未确认公告的群成员，返回提醒人数[群主及管理员权限]
+	Remind(ctx context.Context, groupId int, noticeId int, uid int) (int, error)
+}
+
+type GroupNoticeService struct {
+	*repo.Source
+	GroupNoticeRepo        *repo.GroupNotice
+	GroupNoticeConfirmRepo *repo.GroupNoticeConfirm
+	GroupMemberRepo        *repo.GroupMember
+	UsersRepo              *repo.Users
+	RedisLock              *cache.RedisLock
+	PushMessage            *logic.PushMessage
+	MessageService         message.IService
+	ModerationService      IModerationService
+	GroupPermission        IGroupPermissionService
+}
+
+func (g *GroupNoticeService) Publish(ctx context.Context, opt *GroupNoticePublishOpt) (int, error) {
+	opt.Title = strings.TrimSpace(opt.Title)
+	if len([]rune(opt.Title)) > groupNoticeMaxTitleLen {
+		return 0, fmt.Errorf("公告标题不能超过%d个字符", groupNoticeMaxTitleLen)
+	}
+
+	if strings.TrimSpace(opt.Content) == "" {
+		return 0, errors.New("公告内容不能为空")
+	}
+
+	if err := g.GroupPermission.Check(ctx, opt.GroupId, opt.UserId, model.GroupPermNotice); err != nil {
+		return 0, err
+	}
+
+	var old *model.GroupNotice
+	if opt.NoticeId > 0 {
+		notice, err := g.findManageable(ctx, opt.GroupId, opt.NoticeId, opt.UserId)
+		if err != nil {
+			return 0, err
+		}
+
+		old = notice
+	}
+
+	moderation, err := g.ModerationService.Check(ctx, &ModerationCheckOpt{
+		Scene:   model.ModerationSceneGroupNotice,
+		UserId:  opt.UserId,
+		Content: opt.Content,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	notice := &model.GroupNotice{
+		Id:           opt.NoticeId,
+		GroupId:      opt.GroupId,
+		CreatorId:    opt.UserId,
+		ModifyId:     opt.UserId,
+		Title:        opt.Title,
+		Content:      moderation.Content,
+		ConfirmUsers: "[]",
+		IsConfirm:    lo.Ternary(opt.IsConfirm, model.Yes, model.No),
+		IsTop:        lo.Ternary(opt.IsTop, model.Yes, model.No),
+		IsDelete:     model.GroupNoticeIsDeleteNo,
+	}
+
+	if opt.NoticeId == 0 {
+		err = g.GroupNoticeRepo.Create(ctx, notice)
+	} else {
+		err = g.update(ctx, old, notice)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	g.ModerationService.Flag(ctx, &ModerationFlagOpt{
+		Scene:    model.ModerationSceneGroupNotice,
+		UserId:   opt.UserId,
+		TargetId: notice.Id,
+		Result:   moderation,
+	})
+
+	user, err := g.UsersRepo.FindByIdWithCache(ctx, opt.UserId)
+	if err == nil {
+		_ = g.MessageService.CreateGroupMessage(ctx, message.CreateGroupMessageOption{
+			MsgType:  entity.ChatMsgTypeGroupNotice,
+			FromId:   opt.UserId,
+			ToFromId: opt.GroupId,
+			Extra: jsonutil.Encode(model.TalkRecordExtraGroupNotice{
+				OwnerId:   opt.UserId,
+				OwnerName: user.Nickname,
+				Title:     lo.Ternary(opt.NoticeId == 0, fmt.Sprintf("【%s】 发布了群公告", user.Nickname), fmt.Sprintf("【%s】 更新了群公告", user.Nickname)),
+				Content:   notice.Content,
+			}),
+		})
+	}
+
+	g.notify(ctx, &entity.SubEventGroupNoticePayload{
+		GroupId:  opt.GroupId,
+		NoticeId: notice.Id,
+		Type:     GroupNoticeEventPublish,
+	})
+
+	return notice.Id, nil
+}
+
+// update 修改公告内容，内容变更后需重新确认
+func (g *GroupNoticeService) update(ctx context.Context, old *model.GroupNotice, notice *model.GroupNotice) error {
+	return g.Source.Db().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&model.GroupNotice{}).Where("id = ?", notice.Id).Updates(map[string]any{
+			"title":      notice.Title,
+			"content":    notice.Content,
+			"is_confirm": notice.IsConfirm,
+			"is_top":     notice.IsTop,
+			"modify_id":  notice.ModifyId,
+			"updated_at": time.Now(),
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		if old.Content == notice.Content && old.Title == notice.Title {
+			return nil
+		}
+
+		return tx.Where("notice_id = ?", notice.Id).Delete(&model.GroupNoticeConfirm{}).Error
+	})
+}
+
+func (g *GroupNoticeService) Delete(ctx context.Context, groupId int, noticeId int, uid int) error {
+	if err := g.GroupPermission.Check(ctx, groupId, uid, model.GroupPermNotice); err != nil {
+		return err
+	}
+
+	if _, err := g.findManageable(ctx, groupId, noticeId, uid); err != nil {
+		return err
+	}
+
+	_, err := g.GroupNoticeRepo.UpdateById(ctx, noticeId, map[string]any{
+		"is_delete":  model.GroupNoticeIsDeleteYes,
+		"modify_id":  uid,
+		"updated_at": time.Now(),
+	})
+
+	return err
+}
+
+func (g *GroupNoticeService) SetTop(ctx context.Context, groupId int, noticeId int, uid int, isTop bool) error {
+	if err := g.GroupPermission.Check(ctx, groupId, uid, model.GroupPermNotice); err != nil {
+		return err
+	}
+
+	if _, err := g.findManageable(ctx, groupId, noticeId, uid); err != nil {
+		return err
+	}
+
+	_, err := g.GroupNoticeRepo.UpdateById(ctx, noticeId, map[string]any{
+		"is_top":     lo.Ternary(isTop, model.Yes, model.No),
+		"updated_at": time.Now(),
+	})
+
+	return err
+}
+
+func (g *GroupNoticeService) List(ctx context.Context, groupId int, uid int) ([]*GroupNoticeItem, error) {
+	if !g.GroupMemberRepo.IsMember(ctx, groupId, uid, true) {
+		return nil, entity.ErrPermissionDenied
+	}
+
+	notices, err := g.GroupNoticeRepo.GetNotices(ctx, groupId)
+	if err != nil {
+		return nil, err
+	}
+
+	confirmIds := lo.FilterMap(notices, func(item *model.GroupNotice, _ int) (int, bool) {
+		return item.Id, item.IsConfirm == model.Yes
+	})
+
+	confirmed, err := g.GroupNoticeConfirmRepo.GetConfirmedNoticeIds(ctx, uid, confirmIds)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := g.GroupNoticeConfirmRepo.CountByNoticeIds(ctx, confirmIds)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*GroupNoticeItem, 0, len(notices))
+	for _, notice := range notices {
+		items = append(items, &GroupNoticeItem{
+			Notice:       notice,
+			IsConfirmed:  lo.Contains(confirmed, notice.Id),
+			ConfirmCount: counts[notice.Id],
+		})
+	}
+
+	return items, nil
+}
+
+func (g *GroupNoticeService) Confirm(ctx context.Context, groupId int, noticeId int, uid int) error {
+	if !g.GroupMemberRepo.IsMember(ctx, groupId, uid, true) {
+		return entity.ErrPermissionDenied
+	}
+
+	notice, err := g.find(ctx, groupId, noticeId)
+	if err != nil {
+		return err
+	}
+
+	if notice.IsConfirm != model.Yes {
+		return errors.New("该公告无需确认")
+	}
+
+	return g.GroupNoticeConfirmRepo.Confirm(ctx, groupId, noticeId, uid)
+}
+
+func (g *GroupNoticeService) Unconfirmed(ctx context.Context, groupId int, noticeId int, uid int) ([]int, error) {
+	if !g.GroupMemberRepo.IsLeader(ctx, groupId, uid) {
+		return nil, entity.ErrPermissionDenied
+	}
+
+	notice, err := g.find(ctx, groupId, noticeId)
+	if err != nil {
+		return nil, err
+	}
+
+	if notice.IsConfirm != model.Yes {
+		return []int{}, nil
+	}
+
+	confirmed, err := g.GroupNoticeConfirmRepo.GetConfirmedUserIds(ctx, noticeId)
+	if err != nil {
+		return nil, err
+	}
+
+	members := g.GroupMemberRepo.GetMemberIds(ctx, groupId)
+
+	return lo.Without(members, confirmed...), nil
+}
+
+func (g *GroupNoticeService) Remind(ctx context.Context, groupId int, noticeId int, uid int) (int, error) {
+	uids, err := g.Unconfirmed(ctx, groupId, noticeId, uid)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(uids) == 0 {
+		return 0, nil
+	}
+
+	// 锁到期前不释放，用于限制提醒频率
+	if !g.RedisLock.Lock(ctx, fmt.Sprintf("group_notice_remind:%d", noticeId), groupNoticeRemindInterval) {
+		return 0, entity.ErrTooFrequentOperation
+	}
+
+	g.notify(ctx, &entity.SubEventGroupNoticePayload{
+		GroupId:  groupId,
+		NoticeId: noticeId,
+		Type:     GroupNoticeEventRemind,
+		UserIds:  uids,
+	})
+
+	return len(uids), nil
+}
+
+func (g *GroupNoticeService) find(ctx context.Context, groupId int, noticeId int) (*model.GroupNotice, error) {
+	notice, err := g.GroupNoticeRepo.FindNotice(ctx, groupId, noticeId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("群公告不存在")
+		}
+
+		return nil, err
+	}
+
+	return notice, nil
+}
+
+// findManageable 查询可修改的公告，已发布的公告仅发布者及群主、管理员可修改、删除或置顶
+func (g *GroupNoticeService) findManageable(ctx context.Context, groupId int, noticeId int, uid int) (*model.GroupNotice, error) {
+	notice, err := g.find(ctx, groupId, noticeId)
+	if err != nil {
+		return nil, err
+	}
+
+	member, err := g.GroupMemberRepo.FindByUserId(ctx, groupId, uid)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+
+		member = nil
+	}
+
+	if !canManageGroupNotice(notice, member) {
+		return nil, entity.ErrPermissionDenied
+	}
+
+	return notice, nil
+}
+
+func (g *GroupNoticeService) notify(ctx context.Context, payload *entity.SubEventGroupNoticePayload) {
+	err := g.PushMessage.Push(ctx, entity.ImTopicChat, &entity.SubscribeMessage{
+		Event:   entity.SubEventGroupNotice,
+		Payload: jsonutil.Encode(payload),
+	})
+	if err != nil {
+		logger.Errorf("group notice push error: %s", err.Error())
+	}
+}
+
+// canManageGroupNotice 群成员是否可修改公告，发布者本人或群主、管理员可修改
+func canManageGroupNotice(notice *model.GroupNotice, member *model.GroupMember) bool {
+	if member == nil || member.IsQuit == model.Yes {
+		return false
+	}
+
+	if notice.CreatorId == member.UserId {
+		return true
+	}
+
+	return member.Leader == model.GroupMemberLeaderOwner || member.Leader == model.GroupMemberLeaderAdmin
+}
@@ -0,0 +1,33 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/gzydong/go-chat/internal/repository/model"
+)
+
+func TestCanManageGroupNotice(t *testing.T) {
+	notice := &model.GroupNotice{Id: 1, GroupId: 10, CreatorId: 1}
+
+	cases := []struct {
+		name   string
+		member *model.GroupMember
+		expect bool
+	}{
+		{name: "creator", member: &model.GroupMember{UserId: 1, Leader: model.GroupMemberLeaderOrdinary, IsQuit: model.No}, expect: true},
+		{name: "owner", member: &model.GroupMember{UserId: 2, Leader: model.GroupMemberLeaderOwner, IsQuit: model.No}, expect: true},
+		{name: "admin", member: &model.GroupMember{UserId: 3, Leader: model.GroupMemberLeaderAdmin, IsQuit: model.No}, expect: true},
+		{name: "other member", member: &model.GroupMember{UserId: 4, Leader: model.GroupMemberLeaderOrdinary, IsQuit: model.No}, expect: false},
+		{name: "quit creator", member: &model.GroupMember{UserId: 1, Leader: model.GroupMemberLeaderOrdinary, IsQuit: model.Yes}, expect: false},
+		{name: "quit admin", member: &model.GroupMember{UserId: 3, Leader: model.GroupMemberLeaderAdmin, IsQuit: model.Yes}, expect: false},
+		{name: "not member", member: nil, expect: false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := canManageGroupNotice(notice, c.member); got != c.expect {
+				t.Errorf("canManageGroupNotice() = %v, want %v", got, c.expect)
+			}
+		})
+	}
+}
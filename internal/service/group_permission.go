@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/gzydong/go-chat/internal/entity"
+	"github.com/gzydong/go-chat/internal/pkg/jsonutil"
+	"github.com/gzydong/go-chat/internal/repository/model"
+	"github.com/gzydong/go-chat/internal/repository/repo"
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+)
+
+var _ IGroupPermissionService = (*GroupPermissionService)(nil)
+
+type IGroupPermissionService interface {
+	// Check 校验群成员是否拥有指定权限，群组、成员及权限的判断统一由此处完成
+	Check(ctx context.Context, groupId int, uid int, perm string) error
+	// GetSetting 获取群权限设置及当前用户拥有的权限
+	GetSetting(ctx context.Context, groupId int, uid int) (*GroupPermissionSetting, error)
+	// UpdateScopes 修改群权限开放范围[群主权限]
+	UpdateScopes(ctx context.Context, groupId int, uid int, scopes map[string]int) error
+	// UpdateAdminPermissions 设置管理员拥有的权限项[群主权限]
+	UpdateAdminPermissions(ctx context.Context, opt *GroupAdminPermissionOpt) error
+}
+
+type GroupPermissionSetting struct {
+	Scopes      map[string]int          // 各权限项开放范围
+	Permissions []string                // 当前用户拥有的权限项
+	Admins      []*GroupAdminPermission // 管理员权限项，仅群主可见
+}
+
+type GroupAdminPermission struct {
+	UserId      int
+	Permissions []string
+}
+
+type GroupAdminPermissionOpt struct {
+	OperatorId  int      // 操作人ID
+	GroupId     int      // 群ID
+	UserId      int      // 管理员ID
+	Permissions []string // 权限项
+}
+
+type GroupPermissionService struct {
+	GroupRepo       *repo.Group
+	GroupMemberRepo *repo.GroupMember
+}
+
+func (g *GroupPermissionService) Check(ctx context.Context, groupId int, uid int, perm string) error {
+	group, member, err := g.find(ctx, groupId, uid)
+	if err != nil {
+		return err
+	}
+
+	if !model.IsGroupPermitted(group, member, perm) {
+		return entity.ErrPermissionDenied
+	}
+
+	return nil
+}
+
+func (g *GroupPermissionService) GetSetting(ctx context.Context, groupId int, uid int) (*GroupPermissionSetting, error) {
+	group, member, err := g.find(ctx, groupId, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	setting := &GroupPermissionSetting{
+		Scopes: group.PermissionScopes(),
+		Permissions: lo.Filter(model.GroupPermissions, func(perm string, _ int) bool {
+			return model.IsGroupPermitted(group, member, perm)
+		}),
+		Admins: make([]*GroupAdminPermission, 0),
+	}
+
+	if member.Leader != model.GroupMemberLeaderOwner {
+		return setting, nil
+	}
+
+	admins, err := g.GroupMemberRepo.FindAll(ctx, func(db *gorm.DB) {
+		db.Where("group_id = ? and leader = ? and is_quit = ?", groupId, model.GroupMemberLeaderAdmin, model.No)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, admin := range admins {
+		setting.Admins = append(setting.Admins, &GroupAdminPermission{
+			UserId:      admin.UserId,
+			Permissions: admin.AdminPermissions(),
+		})
+	}
+
+	return setting, nil
+}
+
+func (g *GroupPermissionService) UpdateScopes(ctx context.Context, groupId int, uid int, scopes map[string]int) error {
+	group, member, err := g.find(ctx, groupId, uid)
+	if err != nil {
+		return err
+	}
+
+	if member.Leader != model.GroupMemberLeaderOwner {
+		return entity.ErrPermissionDenied
+	}
+
+	setting := group.PermissionScopes()
+	for perm, scope := range scopes {
+		if _, ok := setting[perm]; !ok {
+			return fmt.Errorf("权限项 %s 不存在", perm)
+		}
+
+		if scope < model.GroupPermScopeMember || scope > model.GroupPermScopeOwner {
+			return fmt.Errorf("权限项 %s 的开放范围不正确", perm)
+		}
+
+		setting[perm] = scope
+	}
+
+	_, err = g.GroupRepo.UpdateById(ctx, groupId, map[string]any{
+		"permission_setting": jsonutil.Encode(setting),
+		"updated_at":         time.Now(),
+	})
+
+	return err
+}
+
+func (g *GroupPermissionService) UpdateAdminPermissions(ctx context.Context, opt *GroupAdminPermissionOpt) error {
+	_, member, err := g.find(ctx, opt.GroupId, opt.OperatorId)
+	if err != nil {
+		return err
+	}
+
+	if member.Leader != model.GroupMemberLeaderOwner {
+		return entity.ErrPermissionDenied
+	}
+
+	if len(opt.Permissions) == 0 {
+		return errors.New("请至少保留一项权限，如需收回全部权限请取消管理员")
+	}
+
+	for _, perm := range opt.Permissions {
+		if !slices.Contains(model.GroupPermissions, perm) {
+			return fmt.Errorf("权限项 %s 不存在", perm)
+		}
+	}
+
+	admin, err := g.GroupMemberRepo.FindByUserId(ctx, opt.GroupId, opt.UserId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("该用户不是群成员")
+		}
+
+		return err
+	}
+
+	if admin.IsQuit == model.Yes || admin.Leader != model.GroupMemberLeaderAdmin {
+		return errors.New("该用户不是群管理员")
+	}
+
+	perms := lo.Uniq(opt.Permissions)
+
+	// 拥有全部权限时不单独保存，之后新增的权限项默认对其开放
+	value := jsonutil.Encode(perms)
+	if len(perms) == len(model.GroupPermissions) {
+		value = ""
+	}
+
+	_, err = g.GroupMemberRepo.UpdateById(ctx, admin.Id, map[string]any{
+		"permissions": value,
+		"updated_at":  time.Now(),
+	})
+
+	return err
+}
+
+func (g *GroupPermissionService) find(ctx context.Context, groupId int, uid int) (*model.Group, *model.GroupMember, error) {
+	group, err := g.GroupRepo.FindById(ctx, groupId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, entity.ErrGroupNotExist
+		}
+
+		return nil, nil, err
+	}
+
+	if group.IsDismiss == model.Yes {
+		return nil, nil, entity.ErrGroupDismissed
+	}
+
+	member, err := g.GroupMemberRepo.FindByUserId(ctx, groupId, uid)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, entity.ErrPermissionDenied
+		}
+
+		return nil, nil, err
+	}
+
+	if member.IsQuit == model.Yes {
+		return nil, nil, entity.ErrPermissionDenied
+	}
+
+	return group, member, nil
+}
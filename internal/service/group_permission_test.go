@@ -0,0 +1,58 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/gzydong/go-chat/internal/pkg/jsonutil"
+	"github.com/gzydong/go-chat/internal/repository/model"
+)
+
+func TestIsGroupPermitted(t *testing.T) {
+	group := &model.Group{
+		PermissionSetting: jsonutil.Encode(map[string]int{
+			model.GroupPermInvite:     model.GroupPermScopeAdmin,
+			model.GroupPermNotice:     model.GroupPermScopeOwner,
+			model.GroupPermVote:       model.GroupPermScopeMember,
+			"unknown":                 model.GroupPermScopeMember,
+			model.GroupPermMentionAll: 9,
+		}),
+	}
+
+	owner := &model.GroupMember{Leader: model.GroupMemberLeaderOwner, IsQuit: model.No}
+	admin := &model.GroupMember{Leader: model.GroupMemberLeaderAdmin, IsQuit: model.No}
+	limitedAdmin := &model.GroupMember{
+		Leader:      model.GroupMemberLeaderAdmin,
+		IsQuit:      model.No,
+		Permissions: jsonutil.Encode([]string{model.GroupPermPin}),
+	}
+	member := &model.GroupMember{Leader: model.GroupMemberLeaderOrdinary, IsQuit: model.No}
+	quit := &model.GroupMember{Leader: model.GroupMemberLeaderAdmin, IsQuit: model.Yes}
+
+	cases := []struct {
+		name   string
+		member *model.GroupMember
+		perm   string
+		expect bool
+	}{
+		{name: "owner has owner-only permission", member: owner, perm: model.GroupPermNotice, expect: true},
+		{name: "admin denied owner-only permission", member: admin, perm: model.GroupPermNotice, expect: false},
+		{name: "admin has admin permission", member: admin, perm: model.GroupPermInvite, expect: true},
+		{name: "member denied admin permission", member: member, perm: model.GroupPermInvite, expect: false},
+		{name: "member has member permission", member: member, perm: model.GroupPermVote, expect: true},
+		{name: "default scope applies", member: member, perm: model.GroupPermEditProfile, expect: false},
+		{name: "invalid scope falls back to default", member: admin, perm: model.GroupPermMentionAll, expect: true},
+		{name: "limited admin keeps granted permission", member: limitedAdmin, perm: model.GroupPermPin, expect: true},
+		{name: "limited admin loses revoked permission", member: limitedAdmin, perm: model.GroupPermInvite, expect: false},
+		{name: "limited admin keeps member permission", member: limitedAdmin, perm: model.GroupPermVote, expect: true},
+		{name: "quit member denied", member: quit, perm: model.GroupPermVote, expect: false},
+		{name: "unknown permission denied", member: admin, perm: "unknown", expect: false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := model.IsGroupPermitted(group, c.member, c.perm); got != c.expect {
+				t.Errorf("IsGroupPermitted(%s) = %v, want %v", c.perm, got, c.expect)
+			}
+		})
+	}
+}
@@ -3,7 +3,13 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/gzydong/go-chat/internal/pkg/jsonutil"
 	"github.com/gzydong/go-chat/internal/repository/model"
 	"github.com/gzydong/go-chat/internal/repository/repo"
 	"github.com/gzydong/go-chat/internal/service/message"
@@ -19,9 +25,11 @@ type IGroupRobotService interface {
 	// DeleteRobot 删除机器人
 	DeleteRobot(ctx context.Context, robotId int, userId int) error
 	// UpdateRobot 更新机器人信息
-	UpdateRobot(ctx context.Context, robotId int, robotName string, description string) error
-	// SendWebhookMessage 发送Webhook消息到群
-	SendWebhookMessage(ctx context.Context, webhookUrl string, timestamp string, signature string, req *WebhookMessageRequest) error
+	UpdateRobot(ctx context.Context, robotId int, userId int, robotName string, description string, callbackUrl string) error
+	// FindWebhookRobot 根据Webhook地址查找机器人并验证签名
+	FindWebhookRobot(ctx context.Context, webhookUrl string, timestamp string, signature string) (*model.GroupRobot, error)
+	// SendWebhookMessage 发送Webhook消息到群，卡片消息返回消息ID
+	SendWebhookMessage(ctx context.Context, webhookUrl string, timestamp string, signature string, req *WebhookMessageRequest) (string, error)
 	// GetRobotMessages 获取机器人消息列表
 	GetRobotMessages(ctx context.Context, robotId int, limit int) ([]*model.GroupRobotMessage, error)
 }
@@ -31,13 +39,16 @@ type GroupRobotService struct {
 	GroupRepo       *repo.Group
 	GroupMemberRepo *repo.GroupMember
 	MessageService  message.IService
+	TalkCardService ITalkCardService
+	GroupPermission IGroupPermissionService
 }
 
 type WebhookMessageRequest struct {
-	MsgType  string                  `json:"msgtype"` // text, markdown, image
-	Text     *WebhookTextMessage     `json:"text,omitempty"`
-	Markdown *WebhookMarkdownMessage `json:"markdown,omitempty"`
-	Image    *WebhookImageMessage    `json:"image,omitempty"`
+	MsgType  string                            `json:"msgtype"` // text, markdown, image, card
+	Text     *WebhookTextMessage               `json:"text,omitempty"`
+	Markdown *WebhookMarkdownMessage           `json:"markdown,omitempty"`
+	Image    *WebhookImageMessage              `json:"image,omitempty"`
+	Card     *model.TalkRecordExtraInteractive `json:"card,omitempty"`
 }
 
 type WebhookTextMessage struct {
@@ -54,14 +65,8 @@ type WebhookImageMessage struct {
 }
 
 func (g *GroupRobotService) CreateRobot(ctx context.Context, groupId int, robotName string, description string, creatorId int) (*model.GroupRobot, error) {
-	// 验证用户是否是群主或管理员
-	member, err := g.GroupMemberRepo.FindByUserId(ctx, groupId, creatorId)
-	if err != nil {
-		return nil, errors.New("用户不是群成员")
-	}
-
-	if member.Leader == model.GroupMemberLeaderOrdinary {
-		return nil, errors.New("只有群主或管理员可以创建机器人")
+	if err := g.GroupPermission.Check(ctx, groupId, creatorId, model.GroupPermRobot); err != nil {
+		return nil, err
 	}
 
 	robot := &model.GroupRobot{
@@ -90,20 +95,23 @@ func (g *GroupRobotService) DeleteRobot(ctx context.Context, robotId int, userId
 		return err
 	}
 
-	// 验证用户权限
-	member, err := g.GroupMemberRepo.FindByUserId(ctx, robot.GroupId, userId)
-	if err != nil {
-		return errors.New("用户不是群成员")
-	}
-
-	if member.Leader == model.GroupMemberLeaderOrdinary {
-		return errors.New("只有群主或管理员可以删除机器人")
+	if err := g.GroupPermission.Check(ctx, robot.GroupId, userId, model.GroupPermRobot); err != nil {
+		return err
 	}
 
 	return g.GroupRobotRepo.Delete(ctx, robotId)
 }
 
-func (g *GroupRobotService) UpdateRobot(ctx context.Context, robotId int, robotName string, description string) error {
+func (g *GroupRobotService) UpdateRobot(ctx context.Context, robotId int, userId int, robotName string, description string, callbackUrl string) error {
+	robot, err := g.GroupRobotRepo.FindById(ctx, robotId)
+	if err != nil {
+		return err
+	}
+
+	if err := g.GroupPermission.Check(ctx, robot.GroupId, userId, model.GroupPermRobot); err != nil {
+		return err
+	}
+
 	updates := make(map[string]interface{})
 
 	if robotName != "" {
@@ -112,6 +120,13 @@ func (g *GroupRobotService) UpdateRobot(ctx context.Context, robotId int, robotN
 	if description != "" {
 		updates["description"] = description
 	}
+	if callbackUrl != "" {
+		u, err := url.Parse(callbackUrl)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errors.New("回调地址格式错误")
+		}
+		updates["callback_url"] = callbackUrl
+	}
 
 	if len(updates) == 0 {
 		return nil
@@ -120,20 +135,48 @@ func (g *GroupRobotService) UpdateRobot(ctx context.Context, robotId int, robotN
 	return g.GroupRobotRepo.Update(ctx, robotId, updates)
 }
 
-func (g *GroupRobotService) SendWebhookMessage(ctx context.Context, webhookUrl string, timestamp string, signature string, req *WebhookMessageRequest) error {
+func (g *GroupRobotService) FindWebhookRobot(ctx context.Context, webhookUrl string, timestamp string, signature string) (*model.GroupRobot, error) {
+	// 路由参数仅包含 webhook 标识
+	if !strings.HasPrefix(webhookUrl, "/") {
+		webhookUrl = fmt.Sprintf("/api/v1/webhook/robot/%s", webhookUrl)
+	}
+
 	// 验证webhook URL
 	robot, err := g.GroupRobotRepo.FindByWebhookUrl(ctx, webhookUrl)
 	if err != nil {
-		return errors.New("无效的webhook URL")
+		return nil, errors.New("无效的webhook URL")
 	}
 
 	if robot.Status != model.GroupRobotStatusActive {
-		return errors.New("机器人已被禁用")
+		return nil, errors.New("机器人已被禁用")
+	}
+
+	// 时间戳与服务器时间相差超过5分钟视为重放请求，支持秒及毫秒
+	value, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return nil, errors.New("时间戳格式错误")
+	}
+
+	if value > 1e12 {
+		value /= 1000
+	}
+
+	if diff := time.Now().Unix() - value; diff > 300 || diff < -300 {
+		return nil, errors.New("时间戳已过期")
 	}
 
 	// 验证签名
 	if !g.GroupRobotRepo.VerifySignature(robot.Secret, timestamp, signature) {
-		return errors.New("签名验证失败")
+		return nil, errors.New("签名验证失败")
+	}
+
+	return robot, nil
+}
+
+func (g *GroupRobotService) SendWebhookMessage(ctx context.Context, webhookUrl string, timestamp string, signature string, req *WebhookMessageRequest) (string, error) {
+	robot, err := g.FindWebhookRobot(ctx, webhookUrl, timestamp, signature)
+	if err != nil {
+		return "", err
 	}
 
 	// 解析消息内容
@@ -141,21 +184,41 @@ func (g *GroupRobotService) SendWebhookMessage(ctx context.Context, webhookUrl s
 	switch req.MsgType {
 	case "text":
 		if req.Text == nil {
-			return errors.New("文本消息内容不能为空")
+			return "", errors.New("文本消息内容不能为空")
 		}
 		content = req.Text.Content
 	case "markdown":
 		if req.Markdown == nil {
-			return errors.New("Markdown消息内容不能为空")
+			return "", errors.New("Markdown消息内容不能为空")
 		}
 		content = req.Markdown.Content
 	case "image":
 		if req.Image == nil {
-			return errors.New("图片消息内容不能为空")
+			return "", errors.New("图片消息内容不能为空")
 		}
 		content = req.Image.Base64
+	case "card":
+		msgId, err := g.TalkCardService.Send(ctx, robot, req.Card)
+		if err != nil {
+			return "", err
+		}
+
+		err = g.GroupRobotRepo.SaveMessage(ctx, &model.GroupRobotMessage{
+			RobotId: robot.Id,
+			GroupId: robot.GroupId,
+			MsgType: req.MsgType,
+			Content: req.Card.Title,
+			Extra:   jsonutil.Encode(map[string]any{"msg_id": msgId}),
+			Status:  1,
+			SendAt:  time.Now(),
+		})
+		if err != nil {
+			return "", err
+		}
+
+		return msgId, nil
 	default:
-		return errors.New("不支持的消息类型")
+		return "", errors.New("不支持的消息类型")
 	}
 
 	// 保存消息记录
@@ -168,14 +231,14 @@ func (g *GroupRobotService) SendWebhookMessage(ctx context.Context, webhookUrl s
 	}
 
 	if err := g.GroupRobotRepo.SaveMessage(ctx, robotMessage); err != nil {
-		return err
+		return "", err
 	}
 
 	// 发送消息到群聊（通过消息服务）
 	// 这里需要调用MessageService来实际发送消息
 	// 简化版本：记录即可，实际发送由消息服务处理
 
-	return nil
+	return "", nil
 }
 
 func (g *GroupRobotService) GetRobotMessages(ctx context.Context, robotId int, limit int) ([]*model.GroupRobotMessage, error) {
@@ -4,19 +4,39 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
+	"time"
 
+	"github.com/gzydong/go-chat/internal/entity"
+	"github.com/gzydong/go-chat/internal/logic"
 	"github.com/gzydong/go-chat/internal/pkg/jsonutil"
+	"github.com/gzydong/go-chat/internal/pkg/logger"
 	"github.com/gzydong/go-chat/internal/repository/model"
 	"github.com/gzydong/go-chat/internal/repository/repo"
+	"github.com/samber/lo"
 	"gorm.io/gorm"
 )
 
+const (
+	GroupVoteMinDuration = time.Minute         // 投票最短时长
+	GroupVoteMaxDuration = 30 * 24 * time.Hour // 投票最长时长
+
+	groupVoteCloseLimit = 200 // 单次关闭到期投票的最大数量
+)
+
 var _ IGroupVoteService = (*GroupVoteService)(nil)
 
 type IGroupVoteService interface {
 	Create(ctx context.Context, opt *GroupVoteCreateOpt) (int, error)
+	// Submit 提交投票，投票结束前可重新投票
 	Submit(ctx context.Context, opt *GroupVoteSubmitOpt) error
 	Detail(ctx context.Context, opt *GroupVoteDetailOpt) error
+	// Result 获取投票结果，结果不可见时不返回统计数据
+	Result(ctx context.Context, voteId int, uid int) (*GroupVoteResult, error)
+	// Close 提前结束投票，仅发起人、群主及管理员可操作
+	Close(ctx context.Context, voteId int, uid int) error
+	// CloseExpired 结束已到截止时间的投票，返回结束的数量
+	CloseExpired(ctx context.Context) (int, error)
 }
 
 type GroupVoteService struct {
@@ -24,18 +44,25 @@ type GroupVoteService struct {
 	GroupMemberRepo *repo.GroupMember
 	GroupVoteRepo   *repo.GroupVote
 	Sequence        *repo.Sequence
+	PushMessage     *logic.PushMessage
 }
 
 type GroupVoteCreateOpt struct {
-	GroupId       int      // 群组ID
-	UserId        int      // 用户ID(创建人)
-	Title         string   // 投票标题
-	AnswerMode    int      // 答题模式[1:单选;2:多选;]
-	AnswerOptions []string // 答题选项
-	IsAnonymous   bool     // 匿名投票
+	GroupId       int           // 群组ID
+	UserId        int           // 用户ID(创建人)
+	Title         string        // 投票标题
+	AnswerMode    int           // 答题模式[1:单选;2:多选;]
+	AnswerOptions []string      // 答题选项
+	IsAnonymous   bool          // 匿名投票
+	Duration      time.Duration // 投票时长，为 0 时不限时
+	ResultVisible int           // 结果可见范围[1:始终可见;2:投票后或结束后可见;]
 }
 
 func (g *GroupVoteService) Create(ctx context.Context, opt *GroupVoteCreateOpt) (int, error) {
+	if opt.Duration < 0 || opt.Duration > GroupVoteMaxDuration || (opt.Duration > 0 && opt.Duration < GroupVoteMinDuration) {
+		return 0, fmt.Errorf("投票时长需在1分钟至%d天之间", int(GroupVoteMaxDuration.Hours()/24))
+	}
+
 	options := make([]model.GroupVoteOption, 0)
 	for i, value := range opt.AnswerOptions {
 		options = append(options, model.GroupVoteOption{
@@ -45,19 +72,24 @@ func (g *GroupVoteService) Create(ctx context.Context, opt *GroupVoteCreateOpt)
 	}
 
 	vote := &model.GroupVote{
-		GroupId:      opt.GroupId,
-		UserId:       opt.UserId,
-		Title:        opt.Title,
-		AnswerMode:   opt.AnswerMode,
-		AnswerOption: jsonutil.Encode(options),
-		AnswerNum:    int(g.GroupMemberRepo.CountMemberTotal(ctx, opt.GroupId)),
-		Status:       model.VoteStatusWait,
+		GroupId:       opt.GroupId,
+		UserId:        opt.UserId,
+		Title:         opt.Title,
+		AnswerMode:    opt.AnswerMode,
+		AnswerOption:  jsonutil.Encode(options),
+		AnswerNum:     int(g.GroupMemberRepo.CountMemberTotal(ctx, opt.GroupId)),
+		Status:        model.VoteStatusWait,
+		ResultVisible: lo.Ternary(opt.ResultVisible == model.VoteResultVisibleAfterVote, model.VoteResultVisibleAfterVote, model.VoteResultVisibleAll),
 	}
 
 	if opt.IsAnonymous {
 		vote.IsAnonymous = 1
 	}
 
+	if opt.Duration > 0 {
+		vote.Deadline = lo.ToPtr(time.Now().Add(opt.Duration).Truncate(time.Second))
+	}
+
 	if err := g.Source.Db().Create(vote).Error; err != nil {
 		return 0, err
 	}
@@ -72,9 +104,7 @@ type GroupVoteSubmitOpt struct {
 }
 
 func (g *GroupVoteService) Submit(ctx context.Context, opt *GroupVoteSubmitOpt) error {
-	db := g.Source.Db().WithContext(ctx)
-
-	voteInfo, err := g.GroupVoteRepo.FindById(ctx, opt.VoteId)
+	voteInfo, err := g.find(ctx, opt.VoteId)
 	if err != nil {
 		return err
 	}
@@ -83,24 +113,18 @@ func (g *GroupVoteService) Submit(ctx context.Context, opt *GroupVoteSubmitOpt)
 		return errors.New("暂无投票权限！")
 	}
 
-	var count int64
-	db.Table("group_vote_answer").Where("vote_id = ? and user_id = ?", opt.VoteId, opt.UserId).Count(&count)
-	if count > 0 {
-		return fmt.Errorf("重复投票[%d]", opt.VoteId)
+	if voteInfo.IsClosed(time.Now()) {
+		return errors.New("投票已结束")
 	}
 
-	ops := opt.Options
-	if voteInfo.AnswerMode == model.VoteAnswerModeSingle {
-		ops = ops[:1]
+	ops, err := checkVoteOptions(voteInfo, opt.Options)
+	if err != nil {
+		return err
 	}
 
-	err = g.Source.Db().Transaction(func(tx *gorm.DB) error {
-		data := map[string]any{
-			"answered_num": gorm.Expr("answered_num + 1"),
-			"status":       gorm.Expr("if(answered_num >= answer_num, 1, 0)"),
-		}
-
-		if err := tx.Table("group_vote").Where("id = ?", voteInfo.Id).Updates(data).Error; err != nil {
+	// 重新投票时覆盖之前的选项，已答人数按实际投票用户数重新统计
+	err = g.Source.Db().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("vote_id = ? and user_id = ?", voteInfo.Id, opt.UserId).Delete(&model.GroupVoteAnswer{}).Error; err != nil {
 			return err
 		}
 
@@ -113,24 +137,21 @@ func (g *GroupVoteService) Submit(ctx context.Context, opt *GroupVoteSubmitOpt)
 			})
 		}
 
-		return tx.Create(answers).Error
+		if err := tx.Create(answers).Error; err != nil {
+			return err
+		}
+
+		return tx.Table("group_vote").Where("id = ?", voteInfo.Id).Updates(map[string]any{
+			"answered_num": gorm.Expr("(select count(distinct user_id) from group_vote_answer where vote_id = ?)", voteInfo.Id),
+			"updated_at":   time.Now(),
+		}).Error
 	})
 
 	if err != nil {
 		return err
 	}
 
-	// Notify group members about the vote submission
-	// Note: To fully implement this, add PushMessage dependency to GroupVoteService
-	// and send a system message similar to other group operations
-	// Example implementation when PushMessage is added:
-	// _ = g.PushMessage.Push(ctx, entity.ImTopicChat, &entity.SubscribeMessage{
-	//     Event:    entity.SubEventGroupVote,
-	//     UserId:   opt.UserId,
-	//     TalkType: entity.ChatGroupMode,
-	//     FromId:   opt.UserId,
-	//     ToFromId: voteInfo.GroupId,
-	// })
+	g.notify(ctx, voteInfo.Id)
 
 	return nil
 }
@@ -143,3 +164,172 @@ type GroupVoteDetailOpt struct {
 func (g *GroupVoteService) Detail(ctx context.Context, opt *GroupVoteDetailOpt) error {
 	return nil
 }
+
+type GroupVoteResult struct {
+	Vote       *model.GroupVote
+	IsSubmit   bool                 // 当前用户是否已投票
+	IsVisible  bool                 // 结果是否可见
+	Options    []string             // 当前用户的投票选项
+	Statistics *repo.VoteStatistics // 投票统计，结果不可见时为空
+}
+
+func (g *GroupVoteService) Result(ctx context.Context, voteId int, uid int) (*GroupVoteResult, error) {
+	voteInfo, err := g.find(ctx, voteId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !g.GroupMemberRepo.IsMember(ctx, voteInfo.GroupId, uid, false) {
+		return nil, entity.ErrPermissionDenied
+	}
+
+	var options []string
+	err = g.Source.Db().WithContext(ctx).Model(&model.GroupVoteAnswer{}).
+		Where("vote_id = ? and user_id = ?", voteId, uid).Pluck("option", &options).Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := &GroupVoteResult{
+		Vote:     voteInfo,
+		IsSubmit: len(options) > 0,
+		Options:  options,
+	}
+
+	result.IsVisible = voteInfo.IsResultVisible(result.IsSubmit, time.Now())
+	if !result.IsVisible {
+		return result, nil
+	}
+
+	result.Statistics, err = g.GroupVoteRepo.GetVoteStatistics(ctx, voteId)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (g *GroupVoteService) Close(ctx context.Context, voteId int, uid int) error {
+	voteInfo, err := g.find(ctx, voteId)
+	if err != nil {
+		return err
+	}
+
+	if voteInfo.UserId != uid && !g.GroupMemberRepo.IsLeader(ctx, voteInfo.GroupId, uid) {
+		return entity.ErrPermissionDenied
+	}
+
+	ok, err := g.GroupVoteRepo.Finish(ctx, voteId)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return errors.New("投票已结束")
+	}
+
+	g.notify(ctx, voteId)
+
+	return nil
+}
+
+func (g *GroupVoteService) CloseExpired(ctx context.Context) (int, error) {
+	votes, err := g.GroupVoteRepo.FindExpiredVotes(ctx, time.Now(), groupVoteCloseLimit)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, vote := range votes {
+		ok, err := g.GroupVoteRepo.Finish(ctx, vote.Id)
+		if err != nil {
+			return count, err
+		}
+
+		if ok {
+			count++
+			g.notify(ctx, vote.Id)
+		}
+	}
+
+	return count, nil
+}
+
+func (g *GroupVoteService) find(ctx context.Context, voteId int) (*model.GroupVote, error) {
+	voteInfo, err := g.GroupVoteRepo.FindById(ctx, voteId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("投票不存在")
+		}
+
+		return nil, err
+	}
+
+	return voteInfo, nil
+}
+
+// notify 刷新投票统计缓存并推送最新结果
+func (g *GroupVoteService) notify(ctx context.Context, voteId int) {
+	voteInfo, err := g.GroupVoteRepo.FindById(ctx, voteId)
+	if err != nil {
+		return
+	}
+
+	statistics, err := g.GroupVoteRepo.SetVoteStatistics(ctx, voteId)
+	if err != nil {
+		logger.Errorf("group vote statistics error: %s", err.Error())
+		return
+	}
+
+	uids, err := g.GroupVoteRepo.SetVoteAnswerUser(ctx, voteId)
+	if err != nil {
+		logger.Errorf("group vote answer users error: %s", err.Error())
+		return
+	}
+
+	err = g.PushMessage.Push(ctx, entity.ImTopicChat, &entity.SubscribeMessage{
+		Event: entity.SubEventGroupVoteUpdate,
+		Payload: jsonutil.Encode(entity.SubEventGroupVoteUpdatePayload{
+			GroupId:       voteInfo.GroupId,
+			VoteId:        voteInfo.Id,
+			Status:        lo.Ternary(voteInfo.IsClosed(time.Now()), model.VoteStatusFinish, model.VoteStatusWait),
+			AnswerNum:     voteInfo.AnswerNum,
+			AnsweredNum:   voteInfo.AnsweredNum,
+			Options:       statistics.Options,
+			ResultVisible: voteInfo.ResultVisible,
+			AnsweredUids:  lo.Uniq(uids),
+		}),
+	})
+	if err != nil {
+		logger.Errorf("group vote push error: %s", err.Error())
+	}
+}
+
+// checkVoteOptions 校验投票选项，单选投票仅保留第一个选项
+func checkVoteOptions(vote *model.GroupVote, options []string) ([]string, error) {
+	var answerOptions []model.GroupVoteOption
+	if err := jsonutil.Unmarshal(vote.AnswerOption, &answerOptions); err != nil {
+		return nil, err
+	}
+
+	keys := lo.Map(answerOptions, func(item model.GroupVoteOption, _ int) string {
+		return item.Key
+	})
+
+	ops := lo.Uniq(options)
+	if len(ops) == 0 {
+		return nil, errors.New("请选择投票选项")
+	}
+
+	for _, option := range ops {
+		if !slices.Contains(keys, option) {
+			return nil, fmt.Errorf("投票选项 %s 不存在", option)
+		}
+	}
+
+	if vote.AnswerMode == model.VoteAnswerModeSingle {
+		ops = ops[:1]
+	}
+
+	return ops, nil
+}
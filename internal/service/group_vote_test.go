@@ -0,0 +1,74 @@
+package service
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gzydong/go-chat/internal/pkg/jsonutil"
+	"github.com/gzydong/go-chat/internal/repository/model"
+	"github.com/samber/lo"
+)
+
+func TestCheckVoteOptions(t *testing.T) {
+	options := jsonutil.Encode([]model.GroupVoteOption{{Key: "A", Value: "a"}, {Key: "B", Value: "b"}, {Key: "C", Value: "c"}})
+
+	single := &model.GroupVote{AnswerMode: model.VoteAnswerModeSingle, AnswerOption: options}
+	multiple := &model.GroupVote{AnswerMode: model.VoteAnswerModeMultiple, AnswerOption: options}
+
+	cases := []struct {
+		name    string
+		vote    *model.GroupVote
+		options []string
+		want    []string
+		wantErr bool
+	}{
+		{name: "single keeps first", vote: single, options: []string{"B", "A"}, want: []string{"B"}},
+		{name: "multiple removes duplicates", vote: multiple, options: []string{"A", "C", "A"}, want: []string{"A", "C"}},
+		{name: "unknown option", vote: multiple, options: []string{"A", "D"}, wantErr: true},
+		{name: "empty options", vote: single, options: nil, wantErr: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := checkVoteOptions(c.vote, c.options)
+			if (err != nil) != c.wantErr {
+				t.Fatalf("checkVoteOptions() error = %v, wantErr %v", err, c.wantErr)
+			}
+
+			if !c.wantErr && !slices.Equal(got, c.want) {
+				t.Errorf("checkVoteOptions() = %v, want %v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestGroupVoteResultVisible(t *testing.T) {
+	now := time.Now()
+
+	open := &model.GroupVote{Status: model.VoteStatusWait, ResultVisible: model.VoteResultVisibleAfterVote}
+	expired := &model.GroupVote{Status: model.VoteStatusWait, ResultVisible: model.VoteResultVisibleAfterVote, Deadline: lo.ToPtr(now.Add(-time.Second))}
+	finished := &model.GroupVote{Status: model.VoteStatusFinish, ResultVisible: model.VoteResultVisibleAfterVote}
+	public := &model.GroupVote{Status: model.VoteStatusWait, ResultVisible: model.VoteResultVisibleAll}
+
+	cases := []struct {
+		name     string
+		vote     *model.GroupVote
+		isSubmit bool
+		want     bool
+	}{
+		{name: "hidden before voting", vote: open, want: false},
+		{name: "visible after voting", vote: open, isSubmit: true, want: true},
+		{name: "visible after deadline", vote: expired, want: true},
+		{name: "visible after close", vote: finished, want: true},
+		{name: "always visible", vote: public, want: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := c.vote.IsResultVisible(c.isSubmit, now); got != c.want {
+				t.Errorf("IsResultVisible() = %v, want %v", got, c.want)
+			}
+		})
+	}
+}
@@ -1,5 +1,13 @@
 package message
 
+import "github.com/gzydong/go-chat/internal/repository/model"
+
+// MessageExpire 消息过期设置
+type MessageExpire struct {
+	Mode int `json:"mode"` // 计时方式 1:发送后开始计时 2:首次阅读后开始计时
+	Ttl  int `json:"ttl"`  // 有效时长(秒)
+}
+
 type CreatePrivateSysMessageOption struct {
 	FromId   int    `json:"from_id"`    // 发送者
 	ToFromId int    `json:"to_from_id"` // 接受者(好友ID或者群组ID)
@@ -7,21 +15,24 @@ type CreatePrivateSysMessageOption struct {
 }
 
 type CreatePrivateMessageOption struct {
-	MsgId    string `json:"msg_id"`     // 消息id
-	MsgType  int    `json:"msg_type"`   // 消息类型，1-文本消息，2-图片消息，3-语音消息，4-视频消息，5-文件消息，6-链接消息，7-小程序消息
-	FromId   int    `json:"from_id"`    // 发送者
-	ToFromId int    `json:"to_from_id"` // 接受者(好友ID或者群组ID)
-	QuoteId  string `json:"quote_id"`   // 引用消息id
-	Extra    string `json:"extra"`      // 扩展字段
+	MsgId    string         `json:"msg_id"`           // 消息id
+	MsgType  int            `json:"msg_type"`         // 消息类型，1-文本消息，2-图片消息，3-语音消息，4-视频消息，5-文件消息，6-链接消息，7-小程序消息
+	FromId   int            `json:"from_id"`          // 发送者
+	ToFromId int            `json:"to_from_id"`       // 接受者(好友ID或者群组ID)
+	QuoteId  string         `json:"quote_id"`         // 引用消息id
+	Extra    string         `json:"extra"`            // 扩展字段
+	Expire   *MessageExpire `json:"expire,omitempty"` // 消息过期设置(为空时使用会话设置)
 }
 
 type CreateGroupMessageOption struct {
-	MsgId    string `json:"msg_id"`     // 消息id
-	MsgType  int    `json:"msg_type"`   // 消息类型，1-文本消息，2-图片消息，3-语音消息，4-视频消息，5-文件消息，6-链接消息，7-小程序消息
-	FromId   int    `json:"from_id"`    // 发送者
-	ToFromId int    `json:"to_from_id"` // 接受者(好友ID或者群组ID)
-	QuoteId  string `json:"quote_id"`   // 引用消息id
-	Extra    string `json:"extra"`      // 扩展字段
+	MsgId     string         `json:"msg_id"`           // 消息id
+	MsgType   int            `json:"msg_type"`         // 消息类型，1-文本消息，2-图片消息，3-语音消息，4-视频消息，5-文件消息，6-链接消息，7-小程序消息
+	FromId    int            `json:"from_id"`          // 发送者
+	ToFromId  int            `json:"to_from_id"`       // 接受者(好友ID或者群组ID)
+	QuoteId   string         `json:"quote_id"`         // 引用消息id
+	Extra     string         `json:"extra"`            // 扩展字段
+	RootMsgId string         `json:"root_msg_id"`      // 话题根消息ID(仅群聊)
+	Expire    *MessageExpire `json:"expire,omitempty"` // 消息过期设置(为空时使用会话设置)
 }
 
 type CreateGroupSysMessageOption struct {
@@ -30,13 +41,15 @@ type CreateGroupSysMessageOption struct {
 }
 
 type CreateMessageOption struct {
-	MsgId    string `json:"msg_id"`     // 消息id
-	TalkMode int    `json:"talk_mode"`  // 发送模式，1-单聊，2-群聊
-	FromId   int    `json:"from_id"`    // 发送者
-	ToFromId int    `json:"to_from_id"` // 接受者(好友ID或者群组ID)
-	MsgType  int    `json:"msg_type"`   // 消息类型
-	QuoteId  string `json:"quote_id"`   // 引用消息id
-	Extra    string `json:"extra"`      // 扩展字段
+	MsgId     string         `json:"msg_id"`           // 消息id
+	TalkMode  int            `json:"talk_mode"`        // 发送模式，1-单聊，2-群聊
+	FromId    int            `json:"from_id"`          // 发送者
+	ToFromId  int            `json:"to_from_id"`       // 接受者(好友ID或者群组ID)
+	MsgType   int            `json:"msg_type"`         // 消息类型
+	QuoteId   string         `json:"quote_id"`         // 引用消息id
+	Extra     string         `json:"extra"`            // 扩展字段
+	RootMsgId string         `json:"root_msg_id"`      // 话题根消息ID(仅群聊)
+	Expire    *MessageExpire `json:"expire,omitempty"` // 消息过期设置(为空时使用会话设置)
 }
 
 type CreateLoginMessageOption struct {
@@ -50,60 +63,72 @@ type CreateLoginMessageOption struct {
 }
 
 type CreateTextMessage struct {
-	MsgId    string `json:"msg_id"`             // 消息id
-	TalkMode int    `json:"talk_mode"`          // 发送模式，1-单聊，2-群聊
-	FromId   int    `json:"from_id"`            // 发送者
-	ToFromId int    `json:"to_from_id"`         // 接受者(好友ID或者群组ID)
-	Content  string `json:"content"`            // 消息内容
-	QuoteId  string `json:"quote_id"`           // 引用消息id
-	Mentions []int  `json:"mentions,omitempty"` // @用户ID列表
+	MsgId     string         `json:"msg_id"`             // 消息id
+	TalkMode  int            `json:"talk_mode"`          // 发送模式，1-单聊，2-群聊
+	FromId    int            `json:"from_id"`            // 发送者
+	ToFromId  int            `json:"to_from_id"`         // 接受者(好友ID或者群组ID)
+	Content   string         `json:"content"`            // 消息内容
+	QuoteId   string         `json:"quote_id"`           // 引用消息id
+	Mentions  []int          `json:"mentions,omitempty"` // @用户ID列表
+	RootMsgId string         `json:"root_msg_id"`        // 话题根消息ID(仅群聊)
+	Expire    *MessageExpire `json:"expire,omitempty"`   // 消息过期设置(为空时使用会话设置)
 }
 
 type CreateImageMessage struct {
-	MsgId    string `json:"msg_id"`     // 消息id
-	TalkMode int    `json:"talk_mode"`  // 发送模式，1-单聊，2-群聊
-	FromId   int    `json:"from_id"`    // 发送者
-	ToFromId int    `json:"to_from_id"` // 接受者(好友ID或者群组ID)
-	QuoteId  string `json:"quote_id"`   // 引用消息id
-	Url      string `json:"url"`        // 图片地址
-	Width    int    `json:"width"`      // 图片宽度
-	Height   int    `json:"height"`     // 图片高度
-	Size     int    `json:"size"`       // 图片大小
+	MsgId     string         `json:"msg_id"`           // 消息id
+	TalkMode  int            `json:"talk_mode"`        // 发送模式，1-单聊，2-群聊
+	FromId    int            `json:"from_id"`          // 发送者
+	ToFromId  int            `json:"to_from_id"`       // 接受者(好友ID或者群组ID)
+	QuoteId   string         `json:"quote_id"`         // 引用消息id
+	Url       string         `json:"url"`              // 图片地址
+	Width     int            `json:"width"`            // 图片宽度
+	Height    int            `json:"height"`           // 图片高度
+	Size      int            `json:"size"`             // 图片大小
+	RootMsgId string         `json:"root_msg_id"`      // 话题根消息ID(仅群聊)
+	Expire    *MessageExpire `json:"expire,omitempty"` // 消息过期设置(为空时使用会话设置)
 }
 
 type CreateVoiceMessage struct {
-	TalkMode int    `json:"talk_mode"`  // 发送模式，1-单聊，2-群聊
-	FromId   int    `json:"from_id"`    // 发送者
-	ToFromId int    `json:"to_from_id"` // 接受者(好友ID或者群组ID)
-	Url      string `json:"url"`        // 语音地址
-	Duration int    `json:"duration"`   // 语音时长
-	Size     int    `json:"size"`       // 语音大小
+	TalkMode  int            `json:"talk_mode"`        // 发送模式，1-单聊，2-群聊
+	FromId    int            `json:"from_id"`          // 发送者
+	ToFromId  int            `json:"to_from_id"`       // 接受者(好友ID或者群组ID)
+	Url       string         `json:"url"`              // 语音地址
+	Duration  int            `json:"duration"`         // 语音时长
+	Size      int            `json:"size"`             // 语音大小
+	RootMsgId string         `json:"root_msg_id"`      // 话题根消息ID(仅群聊)
+	Expire    *MessageExpire `json:"expire,omitempty"` // 消息过期设置(为空时使用会话设置)
 }
 
 type CreateVideoMessage struct {
-	TalkMode int    `json:"talk_mode"`  // 发送模式，1-单聊，2-群聊
-	FromId   int    `json:"from_id"`    // 发送者
-	ToFromId int    `json:"to_from_id"` // 接受者(好友ID或者群组ID)
-	Url      string `json:"url"`        // 视频地址
-	Duration int    `json:"duration"`   // 视频时长
-	Size     int    `json:"size"`       // 视频大小
-	Cover    string `json:"cover"`      // 视频封面
+	TalkMode  int            `json:"talk_mode"`        // 发送模式，1-单聊，2-群聊
+	FromId    int            `json:"from_id"`          // 发送者
+	ToFromId  int            `json:"to_from_id"`       // 接受者(好友ID或者群组ID)
+	Url       string         `json:"url"`              // 视频地址
+	Duration  int            `json:"duration"`         // 视频时长
+	Size      int            `json:"size"`             // 视频大小
+	Cover     string         `json:"cover"`            // 视频封面
+	RootMsgId string         `json:"root_msg_id"`      // 话题根消息ID(仅群聊)
+	Expire    *MessageExpire `json:"expire,omitempty"` // 消息过期设置(为空时使用会话设置)
 }
 
 type CreateFileMessage struct {
-	TalkMode int    `json:"talk_mode"`  // 发送模式，1-单聊，2-群聊
-	FromId   int    `json:"from_id"`    // 发送者
-	ToFromId int    `json:"to_from_id"` // 接受者(好友ID或者群组ID)
-	UploadId string `json:"upload_id"`  // 文件上传ID
+	TalkMode  int            `json:"talk_mode"`        // 发送模式，1-单聊，2-群聊
+	FromId    int            `json:"from_id"`          // 发送者
+	ToFromId  int            `json:"to_from_id"`       // 接受者(好友ID或者群组ID)
+	UploadId  string         `json:"upload_id"`        // 文件上传ID
+	RootMsgId string         `json:"root_msg_id"`      // 话题根消息ID(仅群聊)
+	Expire    *MessageExpire `json:"expire,omitempty"` // 消息过期设置(为空时使用会话设置)
 }
 
 type CreateCodeMessage struct {
-	MsgId    string `json:"msg_id"`     // 消息id
-	TalkMode int    `json:"talk_mode"`  // 发送模式，1-单聊，2-群聊
-	FromId   int    `json:"from_id"`    // 发送者
-	ToFromId int    `json:"to_from_id"` // 接受者(好友ID或者群组ID)
-	Code     string `json:"code"`       // 代码内容
-	Lang     string `json:"lang"`       // 代码语言
+	MsgId     string         `json:"msg_id"`           // 消息id
+	TalkMode  int            `json:"talk_mode"`        // 发送模式，1-单聊，2-群聊
+	FromId    int            `json:"from_id"`          // 发送者
+	ToFromId  int            `json:"to_from_id"`       // 接受者(好友ID或者群组ID)
+	Code      string         `json:"code"`             // 代码内容
+	Lang      string         `json:"lang"`             // 代码语言
+	RootMsgId string         `json:"root_msg_id"`      // 话题根消息ID(仅群聊)
+	Expire    *MessageExpire `json:"expire,omitempty"` // 消息过期设置(为空时使用会话设置)
 }
 
 type CreateVoteMessage struct {
@@ -114,10 +139,12 @@ type CreateVoteMessage struct {
 }
 
 type CreateEmoticonMessage struct {
-	TalkMode   int `json:"talk_mode"`   // 发送模式，1-单聊，2-群聊
-	FromId     int `json:"from_id"`     // 发送者
-	ToFromId   int `json:"to_from_id"`  // 接受者(好友ID或者群组ID)
-	EmoticonId int `json:"emoticon_id"` // 表情ID
+	TalkMode   int            `json:"talk_mode"`        // 发送模式，1-单聊，2-群聊
+	FromId     int            `json:"from_id"`          // 发送者
+	ToFromId   int            `json:"to_from_id"`       // 接受者(好友ID或者群组ID)
+	EmoticonId int            `json:"emoticon_id"`      // 表情ID
+	RootMsgId  string         `json:"root_msg_id"`      // 话题根消息ID(仅群聊)
+	Expire     *MessageExpire `json:"expire,omitempty"` // 消息过期设置(为空时使用会话设置)
 }
 
 type CreateForwardMessage struct {
@@ -132,13 +159,15 @@ type CreateForwardMessage struct {
 }
 
 type CreateLocationMessage struct {
-	MsgId       string `json:"msg_id"`      // 消息id
-	TalkMode    int    `json:"talk_mode"`   // 发送模式，1-单聊，2-群聊
-	FromId      int    `json:"from_id"`     // 发送者
-	ToFromId    int    `json:"to_from_id"`  // 接受者(好友ID或者群组ID)
-	Longitude   string `json:"longitude"`   // 地理位置 经度
-	Latitude    string `json:"latitude"`    // 地理位置 纬度
-	Description string `json:"description"` // 位置描述
+	MsgId       string         `json:"msg_id"`           // 消息id
+	TalkMode    int            `json:"talk_mode"`        // 发送模式，1-单聊，2-群聊
+	FromId      int            `json:"from_id"`          // 发送者
+	ToFromId    int            `json:"to_from_id"`       // 接受者(好友ID或者群组ID)
+	Longitude   string         `json:"longitude"`        // 地理位置 经度
+	Latitude    string         `json:"latitude"`         // 地理位置 纬度
+	Description string         `json:"description"`      // 位置描述
+	RootMsgId   string         `json:"root_msg_id"`      // 话题根消息ID(仅群聊)
+	Expire      *MessageExpire `json:"expire,omitempty"` // 消息过期设置(为空时使用会话设置)
 }
 
 type CreateBusinessCardMessage struct {
@@ -157,6 +186,8 @@ type CreateMixedMessage struct {
 	QuoteId     string                   `json:"quote_id"`           // 引用消息id
 	Mentions    []int                    `json:"mentions,omitempty"` // @用户ID列表
 	MessageList []CreateMixedMessageItem `json:"message_list"`       // 消息列表
+	RootMsgId   string                   `json:"root_msg_id"`        // 话题根消息ID(仅群聊)
+	Expire      *MessageExpire           `json:"expire,omitempty"`   // 消息过期设置(为空时使用会话设置)
 }
 
 type CreateMixedMessageItem struct {
@@ -195,3 +226,11 @@ type CreateTransferMessage struct {
 	Amount     float64 `json:"amount"`      // 转账金额（单位：分）
 	Remark     string  `json:"remark"`      // 转账备注
 }
+
+type CreateInteractiveMessage struct {
+	MsgId    string                            `json:"msg_id"`     // 消息id
+	TalkMode int                               `json:"talk_mode"`  // 发送模式，1-单聊，2-群聊
+	FromId   int                               `json:"from_id"`    // 发送者，群机器人发送时为0
+	ToFromId int                               `json:"to_from_id"` // 接受者(好友ID或者群组ID)
+	Card     *model.TalkRecordExtraInteractive `json:"card"`       // 卡片内容
+}
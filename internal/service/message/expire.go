@@ -0,0 +1,49 @@
+package message
+
+import (
+	"context"
+	"time"
+
+	"github.com/gzydong/go-chat/internal/repository/model"
+	"gorm.io/gorm"
+)
+
+// resolveExpire 获取消息的过期设置，未指定时使用会话的过期设置，返回 nil 表示消息不过期
+func (s *Service) resolveExpire(ctx context.Context, talkMode int, fromId int, toFromId int, expire *MessageExpire) (*MessageExpire, error) {
+	// 系统消息不过期
+	if fromId == 0 {
+		return nil, nil
+	}
+
+	if expire != nil && expire.Ttl > 0 {
+		return expire, nil
+	}
+
+	setting, err := s.TalkExpireSettingRepo.FindByTalk(ctx, talkMode, fromId, toFromId)
+	if err != nil || setting == nil || setting.Ttl <= 0 {
+		return nil, err
+	}
+
+	return &MessageExpire{Mode: setting.Mode, Ttl: setting.Ttl}, nil
+}
+
+// createExpire 记录消息的过期信息，发送后计时的消息直接计算过期时间，首次阅读后计时的消息等待阅读后再计算
+func createExpire(tx *gorm.DB, expire *MessageExpire, items []*model.TalkMessageExpire) error {
+	if expire == nil || len(items) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	for _, item := range items {
+		item.Mode = expire.Mode
+		item.Ttl = expire.Ttl
+		item.CreatedAt = now
+
+		if expire.Mode != model.TalkExpireModeAfterRead {
+			item.Mode = model.TalkExpireModeAfterSend
+			item.ExpireAt = now.Unix() + int64(expire.Ttl)
+		}
+	}
+
+	return tx.Create(items).Error
+}
@@ -1,7 +1,11 @@
 package message
 
 import (
+	"cmp"
 	"context"
+	"html"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/gzydong/go-chat/internal/entity"
@@ -11,6 +15,7 @@ import (
 	"github.com/gzydong/go-chat/internal/repository/model"
 	"github.com/gzydong/go-chat/internal/repository/repo"
 	"github.com/samber/lo"
+	"gorm.io/gorm"
 )
 
 type ForwardMessageOpt struct {
@@ -30,34 +35,16 @@ func (s *Service) toSplitForward(ctx context.Context, req ForwardMessageOpt) err
 		messageItems = make([]model.TalkRecord, 0)
 	)
 
-	if req.TalkMode == entity.ChatGroupMode {
-		records := make([]model.TalkGroupMessage, 0)
-
-		err := db.Table("talk_group_message").Where("group_id = ? and msg_id in ?", req.ToFromId, req.MsgIds).Scan(&records).Error
-		if err != nil {
-			return err
-		}
-
-		for _, v := range records {
-			messageItems = append(messageItems, model.TalkRecord{
-				MsgType: v.MsgType,
-				Extra:   v.Extra,
-			})
-		}
-	} else {
-		records := make([]model.TalkUserMessage, 0)
-
-		err := db.Table("talk_user_message").Where("user_id = ? and to_from_id = ? and msg_id in ?", req.UserId, req.ToFromId, req.MsgIds).Scan(&records).Error
-		if err != nil {
-			return err
-		}
+	records, err := s.findForwardRecords(ctx, req)
+	if err != nil {
+		return err
+	}
 
-		for _, v := range records {
-			messageItems = append(messageItems, model.TalkRecord{
-				MsgType: v.MsgType,
-				Extra:   v.Extra,
-			})
-		}
+	for _, v := range records {
+		messageItems = append(messageItems, model.TalkRecord{
+			MsgType: v.MsgType,
+			Extra:   v.Extra,
+		})
 	}
 
 	// 向群发送消息
@@ -186,54 +173,31 @@ func (s *Service) toCombineForward(ctx context.Context, req ForwardMessageOpt) e
 		pushMessageItems = make([]entity.SubEventImMessagePayload, 0)
 	)
 
-	if req.TalkMode == entity.ChatGroupMode {
-		records := make([]model.TalkGroupMessage, 0)
-
-		err := db.Table("talk_group_message").Where("group_id = ? and msg_id in ?", req.ToFromId, req.MsgIds).Order("sequence asc").Limit(3).Scan(&records).Error
-		if err != nil {
-			return err
-		}
-
-		uids := make([]int, 0)
-		for _, v := range records {
-			uids = append(uids, v.FromId)
-		}
-
-		userNameItems, err := s.findUserNameList(ctx, uids)
-		if err != nil {
-			return err
-		}
-
-		for _, v := range records {
-			extra.Records = append(extra.Records, model.TalkRecordExtraForwardRecord{
-				Nickname: userNameItems[v.FromId],
-				Content:  text(v.MsgType, v.Extra),
-			})
-		}
-	} else {
-		records := make([]model.TalkUserMessage, 0)
+	records, err := s.findForwardRecords(ctx, req)
+	if err != nil {
+		return err
+	}
 
-		err := s.Source.Db().Table("talk_user_message").Where("user_id = ? and to_from_id = ? and msg_id in ?", req.UserId, req.ToFromId, req.MsgIds).Order("sequence asc").Limit(3).Scan(&records).Error
-		if err != nil {
-			return err
-		}
+	// 合并转发的摘要只展示前三条消息
+	if len(records) > 3 {
+		records = records[:3]
+	}
 
-		uids := make([]int, 0)
-		for _, v := range records {
-			uids = append(uids, v.FromId)
-		}
+	uids := make([]int, 0)
+	for _, v := range records {
+		uids = append(uids, v.FromId)
+	}
 
-		userNameItems, err := s.findUserNameList(ctx, uids)
-		if err != nil {
-			return err
-		}
+	userNameItems, err := s.findUserNameList(ctx, uids)
+	if err != nil {
+		return err
+	}
 
-		for _, v := range records {
-			extra.Records = append(extra.Records, model.TalkRecordExtraForwardRecord{
-				Nickname: userNameItems[v.FromId],
-				Content:  text(v.MsgType, v.Extra),
-			})
-		}
+	for _, v := range records {
+		extra.Records = append(extra.Records, model.TalkRecordExtraForwardRecord{
+			Nickname: userNameItems[v.FromId],
+			Content:  text(v.MsgType, v.Extra),
+		})
 	}
 
 	switch req.ToUserIdType {
@@ -346,7 +310,96 @@ func (s *Service) findUserNameList(ctx context.Context, uids []int) (map[int]str
 	return items, nil
 }
 
+// forwardRecord 被转发的消息
+type forwardRecord struct {
+	MsgId    string
+	FromId   int
+	MsgType  int
+	Extra    string
+	Sequence int64
+}
+
+// findForwardRecords 获取被转发的消息，按时序ID正序返回
+// 被转发的消息可能已归档，当前消息表未查到全部消息时继续查询归档表
+func (s *Service) findForwardRecords(ctx context.Context, req ForwardMessageOpt) ([]*forwardRecord, error) {
+	records := make([]*forwardRecord, 0, len(req.MsgIds))
+
+	err := s.TalkMessageArchive.Scan(ctx, req.TalkMode, func(tx *gorm.DB) (bool, error) {
+		tx = tx.Select("msg_id, from_id, msg_type, extra, sequence")
+		if req.TalkMode == entity.ChatGroupMode {
+			tx = tx.Where("group_id = ? and msg_id in ?", req.ToFromId, req.MsgIds)
+		} else {
+			tx = tx.Where("user_id = ? and to_from_id = ? and msg_id in ?", req.UserId, req.ToFromId, req.MsgIds)
+		}
+
+		var list []*forwardRecord
+		if err := tx.Scan(&list).Error; err != nil {
+			return false, err
+		}
+
+		records = append(records, list...)
+		return len(records) >= len(req.MsgIds), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(records, func(a, b *forwardRecord) int {
+		return cmp.Compare(a.Sequence, b.Sequence)
+	})
+
+	return records, nil
+}
+
+// ForwardContent 获取被转发消息的文本内容，用于发送前的群防刷屏校验
+func (s *Service) ForwardContent(ctx context.Context, option CreateForwardMessage) (string, bool, error) {
+	records, err := s.findForwardRecords(ctx, ForwardMessageOpt{
+		MsgIds:   option.MsgIds,
+		TalkMode: option.TalkMode,
+		ToFromId: option.ToFromId,
+		UserId:   option.UserId,
+	})
+	if err != nil {
+		return "", false, err
+	}
+
+	hasFile := false
+	contents := make([]string, 0, len(records))
+	for _, record := range records {
+		if record.MsgType == entity.ChatMsgTypeFile {
+			hasFile = true
+		}
+
+		if content := SearchText(record.MsgType, record.Extra); content != "" {
+			contents = append(contents, content)
+		}
+	}
+
+	return strings.Join(contents, "\n"), hasFile, nil
+}
+
 func text(msgType int, extra string) string {
+	switch msgType {
+	case entity.ChatMsgTypeText:
+		return strutil.MtSubstr(extraText(msgType, extra), 0, 200)
+	default:
+		if value, ok := entity.ChatMsgTypeMapping[msgType]; ok {
+			return value
+		}
+	}
+
+	return "未知消息"
+}
+
+// SearchText 获取消息的可检索文本
+// 文本、代码、文件名、转发摘要及图文消息中的文字可被检索，其它类型返回空字符串
+// 文本内容入库时已转义，此处返回原文
+func SearchText(msgType int, extra string) string {
+	return html.UnescapeString(extraText(msgType, extra))
+}
+
+// extraText 解析消息 extra 中的文字内容，保持入库时的格式
+func extraText(msgType int, extra string) string {
 	switch msgType {
 	case entity.ChatMsgTypeText:
 		data := model.TalkRecordExtraText{}
@@ -354,12 +407,48 @@ func text(msgType int, extra string) string {
 			return ""
 		}
 
-		return strutil.MtSubstr(data.Content, 0, 200)
-	default:
-		if value, ok := entity.ChatMsgTypeMapping[msgType]; ok {
-			return value
+		return data.Content
+	case entity.ChatMsgTypeCode:
+		data := model.TalkRecordExtraCode{}
+		if err := jsonutil.Unmarshal(extra, &data); err != nil {
+			return ""
 		}
+
+		return data.Lang + "\n" + data.Code
+	case entity.ChatMsgTypeFile:
+		data := model.TalkRecordExtraFile{}
+		if err := jsonutil.Unmarshal(extra, &data); err != nil {
+			return ""
+		}
+
+		return data.Name
+	case entity.ChatMsgTypeForward:
+		data := model.TalkRecordExtraForward{}
+		if err := jsonutil.Unmarshal(extra, &data); err != nil {
+			return ""
+		}
+
+		items := make([]string, 0, len(data.Records))
+		for _, record := range data.Records {
+			items = append(items, record.Nickname+": "+record.Content)
+		}
+
+		return strings.Join(items, "\n")
+	case entity.ChatMsgTypeMixed:
+		data := model.TalkRecordExtraMixed{}
+		if err := jsonutil.Unmarshal(extra, &data); err != nil {
+			return ""
+		}
+
+		items := make([]string, 0, len(data.Items))
+		for _, item := range data.Items {
+			if item.Type == entity.ChatMsgTypeText {
+				items = append(items, item.Content)
+			}
+		}
+
+		return strings.Join(items, "\n")
 	}
 
-	return "未知消息"
+	return ""
 }
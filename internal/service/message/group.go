@@ -6,20 +6,29 @@ import (
 
 	"github.com/gzydong/go-chat/internal/entity"
 	"github.com/gzydong/go-chat/internal/pkg/jsonutil"
-	"github.com/gzydong/go-chat/internal/pkg/logger"
 	"github.com/gzydong/go-chat/internal/pkg/strutil"
-	"github.com/gzydong/go-chat/internal/repository/cache"
 	"github.com/gzydong/go-chat/internal/repository/model"
 	"github.com/gzydong/go-chat/internal/repository/repo"
 	"github.com/samber/lo"
+	"gorm.io/gorm"
 )
 
 func (s *Service) CreateGroupMessage(ctx context.Context, option CreateGroupMessageOption) error {
 	quoteJsonText := "{}"
 
+	var root *model.TalkGroupMessage
+	if option.RootMsgId != "" {
+		record, err := s.findThreadRoot(ctx, option.ToFromId, option.RootMsgId)
+		if err != nil {
+			return err
+		}
+
+		root = record
+	}
+
 	if option.QuoteId != "" {
 		quoteRecord := &model.TalkGroupMessage{}
-		if err := s.Source.Db().First(quoteRecord, "msg_id = ?", option.QuoteId).Error; err != nil {
+		if err := s.TalkMessageArchive.Take(ctx, entity.ChatGroupMode, quoteRecord, "msg_id = ?", option.QuoteId); err != nil {
 			return err
 		}
 
@@ -38,6 +47,16 @@ func (s *Service) CreateGroupMessage(ctx context.Context, option CreateGroupMess
 		quoteJsonText = jsonutil.Encode(quote)
 	}
 
+	var expire *MessageExpire
+	if root == nil {
+		record, err := s.resolveExpire(ctx, entity.ChatGroupMode, option.FromId, option.ToFromId, option.Expire)
+		if err != nil {
+			return err
+		}
+
+		expire = record
+	}
+
 	item := &model.TalkGroupMessage{
 		MsgId:     lo.Ternary(option.MsgId == "", strutil.NewMsgId(), option.MsgId),
 		Sequence:  s.Sequence.Get(ctx, repo.SequenceTypeGroup, int32(option.ToFromId)),
@@ -46,38 +65,41 @@ func (s *Service) CreateGroupMessage(ctx context.Context, option CreateGroupMess
 		FromId:    option.FromId,
 		Quote:     quoteJsonText,
 		Extra:     option.Extra,
+		RootMsgId: option.RootMsgId,
 		IsRevoked: model.No,
 		SendTime:  time.Now(),
 	}
 
-	if err := s.Source.Db().WithContext(ctx).Create(item).Error; err != nil {
-		return err
+	// 话题回复不进入群聊主时间线，也不计入会话未读数，不参与消息过期
+	if root != nil {
+		return s.createGroupThreadMessage(ctx, root, item)
 	}
 
-	err := s.PushMessage.Push(ctx, entity.ImTopicChat, &entity.SubscribeMessage{
-		Event: entity.SubEventImMessage,
-		Payload: jsonutil.Encode(entity.SubEventImMessagePayload{
+	outbox := newOutbox(entity.ChatGroupMode, item.MsgId)
+
+	err := s.Source.Db().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(item).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Create(outbox).Error; err != nil {
+			return err
+		}
+
+		return createExpire(tx, expire, []*model.TalkMessageExpire{{
 			TalkMode: entity.ChatGroupMode,
-			Message:  jsonutil.Encode(item),
-		}),
+			MsgId:    item.MsgId,
+			OrgMsgId: item.MsgId,
+			ToFromId: item.GroupId,
+			FromId:   item.FromId,
+		}})
 	})
 	if err != nil {
-		logger.Errorf("CreateGroupMessage publish message error:%s", err.Error())
-	}
-
-	pipe := s.Source.Redis().Pipeline()
-	for _, uid := range s.GroupMemberRepo.GetMemberIds(ctx, item.GroupId) {
-		if uid != item.FromId {
-			s.UnreadStorage.PipeIncr(ctx, pipe, uid, entity.ChatGroupMode, item.GroupId)
-		}
+		return err
 	}
-	_, _ = pipe.Exec(ctx)
 
-	// 更新最后一条消息
-	_ = s.MessageStorage.Set(ctx, entity.ChatGroupMode, item.FromId, item.GroupId, &cache.LastCacheMessage{
-		Content:  s.getTextMessage(item.MsgType, option.Extra),
-		Datetime: item.CreatedAt.Format(time.DateTime),
-	})
+	// 推送消息，更新未读数及最后一条消息
+	s.deliverGroupOutbox(ctx, outbox, item)
 
 	return nil
 }
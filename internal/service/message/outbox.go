@@ -0,0 +1,232 @@
+package message
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gzydong/go-chat/internal/entity"
+	"github.com/gzydong/go-chat/internal/pkg/jsonutil"
+	"github.com/gzydong/go-chat/internal/pkg/logger"
+	"github.com/gzydong/go-chat/internal/pkg/strutil"
+	"github.com/gzydong/go-chat/internal/repository/cache"
+	"github.com/gzydong/go-chat/internal/repository/model"
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+)
+
+const (
+	outboxLease       = 30 * time.Second // 投递租约时长
+	outboxMaxAttempts = 20               // 最大投递次数
+)
+
+// newOutbox 创建待投递记录，需与消息在同一事务中写入
+func newOutbox(talkMode int, msgId string) *model.TalkMessageOutbox {
+	now := time.Now()
+
+	return &model.TalkMessageOutbox{
+		TalkMode: talkMode,
+		MsgId:    msgId,
+		Status:   model.TalkOutboxStatusPending,
+		// 时间字段按秒存储且会四舍五入，截断后保证写入后立即投递时可被抢占
+		NextRetryAt: now.Truncate(time.Second),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+// deliverPrivateOutbox 私聊消息写入后立即投递，投递失败的记录由投递任务重试
+func (s *Service) deliverPrivateOutbox(ctx context.Context, outboxes []*model.TalkMessageOutbox, items []*model.TalkUserMessage) {
+	for i, outbox := range outboxes {
+		record := items[i]
+
+		_, err := s.relay(ctx, outbox, func() error {
+			return s.pushPrivate(ctx, record)
+		})
+		if err != nil {
+			logger.Errorf("message outbox %d deliver err: %s", outbox.Id, err.Error())
+		}
+	}
+}
+
+// deliverGroupOutbox 群消息写入后立即投递，投递失败的记录由投递任务重试
+func (s *Service) deliverGroupOutbox(ctx context.Context, outbox *model.TalkMessageOutbox, record *model.TalkGroupMessage) {
+	_, err := s.relay(ctx, outbox, func() error {
+		return s.pushGroup(ctx, record)
+	})
+	if err != nil {
+		logger.Errorf("message outbox %d deliver err: %s", outbox.Id, err.Error())
+	}
+}
+
+// RelayOutbox 投递已到投递时间的记录，返回本次抢占到的记录数
+func (s *Service) RelayOutbox(ctx context.Context, limit int) (int, error) {
+	items, err := s.TalkMessageOutboxRepo.FindAllPending(ctx, time.Now(), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for _, item := range items {
+		ok, err := s.relay(ctx, item, func() error {
+			return s.deliver(ctx, item)
+		})
+		if ok {
+			total++
+		}
+
+		if err != nil {
+			logger.Errorf("message outbox %d relay err: %s", item.Id, err.Error())
+		}
+	}
+
+	return total, nil
+}
+
+// ClearOutbox 清理指定时间之前已投递的记录
+func (s *Service) ClearOutbox(ctx context.Context, before time.Time, limit int) (int64, error) {
+	return s.TalkMessageOutboxRepo.DeleteDelivered(ctx, before, limit)
+}
+
+// relay 抢占并投递记录，投递失败时按重试次数延后下次投递时间
+// 投递为至少一次语义，重复投递时客户端按消息ID去重
+func (s *Service) relay(ctx context.Context, item *model.TalkMessageOutbox, deliver func() error) (bool, error) {
+	ok, err := s.TalkMessageOutboxRepo.Claim(ctx, item.Id, outboxLease)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	attempts, now := item.Attempts+1, time.Now()
+
+	if err := deliver(); err != nil {
+		data := map[string]any{
+			"last_error":    strutil.MtSubstr(err.Error(), 0, 255),
+			"next_retry_at": now.Add(outboxBackoff(attempts)),
+			"updated_at":    now,
+		}
+
+		if attempts >= outboxMaxAttempts {
+			data["status"] = model.TalkOutboxStatusFailed
+		}
+
+		if _, err := s.TalkMessageOutboxRepo.UpdateById(ctx, item.Id, data); err != nil {
+			logger.Errorf("message outbox %d update err: %s", item.Id, err.Error())
+		}
+
+		return true, err
+	}
+
+	_, err = s.TalkMessageOutboxRepo.UpdateById(ctx, item.Id, map[string]any{
+		"status":     model.TalkOutboxStatusDelivered,
+		"last_error": "",
+		"updated_at": now,
+	})
+
+	return true, err
+}
+
+// deliver 重新读取消息后投递
+func (s *Service) deliver(ctx context.Context, item *model.TalkMessageOutbox) error {
+	switch item.TalkMode {
+	case entity.ChatPrivateMode:
+		return s.deliverPrivate(ctx, item.MsgId)
+	case entity.ChatGroupMode:
+		return s.deliverGroup(ctx, item.MsgId)
+	}
+
+	return nil
+}
+
+func (s *Service) deliverPrivate(ctx context.Context, msgId string) error {
+	var record model.TalkUserMessage
+	if err := s.Source.Db().WithContext(ctx).First(&record, "msg_id = ?", msgId).Error; err != nil {
+		// 消息已被清理，无需投递
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+
+		return err
+	}
+
+	return s.pushPrivate(ctx, &record)
+}
+
+// pushPrivate 推送私聊消息，并更新未读数及会话最后一条消息
+// 未读数按消息只累加一次，推送失败重试时不会重复累加
+func (s *Service) pushPrivate(ctx context.Context, record *model.TalkUserMessage) error {
+	if record.UserId != record.FromId {
+		err := s.UnreadStorage.IncrOnce(ctx, fmt.Sprintf("%d_%d", entity.ChatPrivateMode, record.Id), entity.ChatPrivateMode, record.ToFromId, record.UserId)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := s.Source.Redis().Publish(ctx, entity.ImTopicChat, jsonutil.Encode(&entity.SubscribeMessage{
+		Event: entity.SubEventImMessage,
+		Payload: jsonutil.Encode(entity.SubEventImMessagePayload{
+			TalkMode: entity.ChatPrivateMode,
+			Message:  jsonutil.Encode(record),
+		}),
+	})).Err()
+	if err != nil {
+		return err
+	}
+
+	// 更新最后一条消息
+	return s.MessageStorage.Set(ctx, entity.ChatPrivateMode, record.UserId, record.ToFromId, &cache.LastCacheMessage{
+		Content:  s.getTextMessage(record.MsgType, record.Extra),
+		Datetime: record.CreatedAt.Format(time.DateTime),
+	})
+}
+
+func (s *Service) deliverGroup(ctx context.Context, msgId string) error {
+	var record model.TalkGroupMessage
+	if err := s.Source.Db().WithContext(ctx).First(&record, "msg_id = ?", msgId).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+
+		return err
+	}
+
+	return s.pushGroup(ctx, &record)
+}
+
+// pushGroup 推送群消息，并更新群成员未读数及会话最后一条消息，话题回复按话题事件推送
+// 未读数按消息只累加一次，推送失败重试时不会重复累加
+func (s *Service) pushGroup(ctx context.Context, record *model.TalkGroupMessage) error {
+	if record.RootMsgId != "" {
+		return s.pushThread(ctx, record)
+	}
+
+	uids := lo.Without(s.GroupMemberRepo.GetMemberIds(ctx, record.GroupId), record.FromId)
+
+	err := s.UnreadStorage.IncrOnce(ctx, fmt.Sprintf("%d_%d", entity.ChatGroupMode, record.Id), entity.ChatGroupMode, record.GroupId, uids...)
+	if err != nil {
+		return err
+	}
+
+	err = s.Source.Redis().Publish(ctx, entity.ImTopicChat, jsonutil.Encode(&entity.SubscribeMessage{
+		Event: entity.SubEventImMessage,
+		Payload: jsonutil.Encode(entity.SubEventImMessagePayload{
+			TalkMode: entity.ChatGroupMode,
+			Message:  jsonutil.Encode(record),
+		}),
+	})).Err()
+	if err != nil {
+		return err
+	}
+
+	// 更新最后一条消息
+	return s.MessageStorage.Set(ctx, entity.ChatGroupMode, record.FromId, record.GroupId, &cache.LastCacheMessage{
+		Content:  s.getTextMessage(record.MsgType, record.Extra),
+		Datetime: record.CreatedAt.Format(time.DateTime),
+	})
+}
+
+// outboxBackoff 投递失败后的重试间隔，按次数指数增长，最长5分钟
+func outboxBackoff(attempts int) time.Duration {
+	delay := time.Second << min(attempts, 9)
+	return min(delay, 5*time.Minute)
+}
@@ -6,12 +6,11 @@ import (
 
 	"github.com/gzydong/go-chat/internal/entity"
 	"github.com/gzydong/go-chat/internal/pkg/jsonutil"
-	"github.com/gzydong/go-chat/internal/pkg/logger"
 	"github.com/gzydong/go-chat/internal/pkg/strutil"
-	"github.com/gzydong/go-chat/internal/repository/cache"
 	"github.com/gzydong/go-chat/internal/repository/model"
 	"github.com/gzydong/go-chat/internal/repository/repo"
 	"github.com/samber/lo"
+	"gorm.io/gorm"
 )
 
 func (s *Service) CreatePrivateMessage(ctx context.Context, option CreatePrivateMessageOption) error {
@@ -24,7 +23,7 @@ func (s *Service) CreatePrivateMessage(ctx context.Context, option CreatePrivate
 
 	if option.QuoteId != "" {
 		quoteRecord := &model.TalkUserMessage{}
-		if err := s.Source.Db().First(quoteRecord, "msg_id = ?", option.QuoteId).Error; err != nil {
+		if err := s.TalkMessageArchive.Take(ctx, entity.ChatPrivateMode, quoteRecord, "msg_id = ?", option.QuoteId); err != nil {
 			return err
 		}
 
@@ -43,6 +42,11 @@ func (s *Service) CreatePrivateMessage(ctx context.Context, option CreatePrivate
 		quoteJsonText = jsonutil.Encode(queue)
 	}
 
+	expire, err := s.resolveExpire(ctx, entity.ChatPrivateMode, option.FromId, option.ToFromId, option.Expire)
+	if err != nil {
+		return err
+	}
+
 	items = append(items, &model.TalkUserMessage{
 		// 发送消息时携带了消息则直接使用
 		MsgId:     lo.Ternary(option.MsgId == "", strutil.NewMsgId(), option.MsgId),
@@ -74,35 +78,37 @@ func (s *Service) CreatePrivateMessage(ctx context.Context, option CreatePrivate
 		IsDeleted: model.No,
 	})
 
-	if err := s.Db().WithContext(ctx).Create(items).Error; err != nil {
-		return err
+	outboxes := make([]*model.TalkMessageOutbox, 0, len(items))
+	for _, item := range items {
+		outboxes = append(outboxes, newOutbox(entity.ChatPrivateMode, item.MsgId))
 	}
 
-	// 推送消息
-	pipe := s.Source.Redis().Pipeline()
-	for _, item := range items {
-		content := &entity.SubscribeMessage{
-			Event: entity.SubEventImMessage,
-			Payload: jsonutil.Encode(entity.SubEventImMessagePayload{
-				TalkMode: entity.ChatPrivateMode,
-				Message:  jsonutil.Encode(item),
-			}),
+	err = s.Db().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(items).Error; err != nil {
+			return err
 		}
 
-		pipe.Publish(ctx, entity.ImTopicChat, jsonutil.Encode(content))
-
-		if item.UserId != option.FromId {
-			s.UnreadStorage.PipeIncr(ctx, pipe, item.UserId, entity.ChatPrivateMode, item.ToFromId)
+		if err := tx.Create(outboxes).Error; err != nil {
+			return err
 		}
 
-		// 更新最后一条消息
-		_ = s.MessageStorage.Set(ctx, entity.ChatPrivateMode, item.UserId, item.ToFromId, &cache.LastCacheMessage{
-			Content:  s.getTextMessage(item.MsgType, option.Extra),
-			Datetime: item.CreatedAt.Format(time.DateTime),
-		})
+		return createExpire(tx, expire, lo.Map(items, func(item *model.TalkUserMessage, _ int) *model.TalkMessageExpire {
+			return &model.TalkMessageExpire{
+				TalkMode: entity.ChatPrivateMode,
+				MsgId:    item.MsgId,
+				OrgMsgId: item.OrgMsgId,
+				UserId:   item.UserId,
+				ToFromId: item.ToFromId,
+				FromId:   item.FromId,
+			}
+		}))
+	})
+	if err != nil {
+		return err
 	}
 
-	_, _ = pipe.Exec(ctx)
+	// 推送消息，更新未读数及最后一条消息
+	s.deliverPrivateOutbox(ctx, outboxes, items)
 
 	return nil
 }
@@ -131,28 +137,21 @@ func (s *Service) CreateToUserPrivateMessage(ctx context.Context, data *model.Ta
 	data.IsRevoked = model.No
 	data.IsDeleted = model.No
 
-	if err := s.Db().WithContext(ctx).Create(data).Error; err != nil {
-		return err
-	}
+	outbox := newOutbox(entity.ChatPrivateMode, data.MsgId)
 
-	err := s.PushMessage.Push(ctx, entity.ImTopicChat, &entity.SubscribeMessage{
-		Event: entity.SubEventImMessage,
-		Payload: jsonutil.Encode(entity.SubEventImMessagePayload{
-			TalkMode: entity.ChatPrivateMode,
-			Message:  jsonutil.Encode(data),
-		}),
+	err := s.Db().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(data).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(outbox).Error
 	})
 	if err != nil {
-		logger.Errorf("SendToUserPrivateLetter redis push err:%s", err.Error())
+		return err
 	}
 
-	s.UnreadStorage.Incr(ctx, data.UserId, entity.ChatPrivateMode, data.ToFromId)
-
-	// 更新最后一条消息
-	_ = s.MessageStorage.Set(ctx, entity.ChatPrivateMode, data.UserId, data.ToFromId, &cache.LastCacheMessage{
-		Content:  s.getTextMessage(data.MsgType, data.Extra),
-		Datetime: data.CreatedAt.Format(time.DateTime),
-	})
+	// 推送消息，更新未读数及最后一条消息
+	s.deliverPrivateOutbox(ctx, []*model.TalkMessageOutbox{outbox}, []*model.TalkUserMessage{data})
 
 	return nil
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"html"
 	"time"
 
 	"github.com/gzydong/go-chat/internal/logic"
@@ -14,6 +15,7 @@ import (
 	"github.com/gzydong/go-chat/internal/pkg/jsonutil"
 	"github.com/gzydong/go-chat/internal/pkg/logger"
 	"github.com/gzydong/go-chat/internal/pkg/strutil"
+	"github.com/gzydong/go-chat/internal/pkg/unfurl"
 	"github.com/gzydong/go-chat/internal/repository/cache"
 	"github.com/gzydong/go-chat/internal/repository/model"
 	"github.com/gzydong/go-chat/internal/repository/repo"
@@ -22,6 +24,9 @@ import (
 
 var _ IService = (*Service)(nil)
 
+// linkPreviewMaxUrls 单条文本消息最多生成预览的链接数
+const linkPreviewMaxUrls = 3
+
 // IPrivateMessage 私有消息
 type IPrivateMessage interface {
 	// CreatePrivateSysMessage 给指定用户创建私有的系统消息
@@ -63,6 +68,8 @@ type IMessage interface {
 	CreateEmoticonMessage(ctx context.Context, option CreateEmoticonMessage) error
 	// CreateForwardMessage 转发消息
 	CreateForwardMessage(ctx context.Context, option CreateForwardMessage) error
+	// ForwardContent 获取被转发消息的文本内容，及是否包含文件消息
+	ForwardContent(ctx context.Context, option CreateForwardMessage) (string, bool, error)
 	// CreateLocationMessage 位置消息
 	CreateLocationMessage(ctx context.Context, option CreateLocationMessage) error
 	// CreateBusinessCardMessage 推送用户名片消息
@@ -75,27 +82,43 @@ type IMessage interface {
 	CreateRedEnvelopeMessage(ctx context.Context, option CreateRedEnvelopeMessage) error
 	// CreateTransferMessage 转账消息
 	CreateTransferMessage(ctx context.Context, option CreateTransferMessage) error
+	// CreateInteractiveMessage 交互卡片消息
+	CreateInteractiveMessage(ctx context.Context, option CreateInteractiveMessage) error
+}
+
+// IOutbox 消息投递
+type IOutbox interface {
+	// RelayOutbox 投递发件箱中待投递的消息，返回处理数量
+	RelayOutbox(ctx context.Context, limit int) (int, error)
+	// ClearOutbox 清理指定时间之前已投递的发件箱记录
+	ClearOutbox(ctx context.Context, before time.Time, limit int) (int64, error)
 }
 
 type IService interface {
 	IPrivateMessage
 	IGroupMessage
 	IMessage
+	IOutbox
 }
 
 type Service struct {
 	*repo.Source
-	GroupMemberRepo     *repo.GroupMember
-	SplitUploadRepo     *repo.FileUpload
-	TalkRecordsVoteRepo *repo.GroupVote
-	UsersRepo           *repo.Users
-	Filesystem          filesystem.IFilesystem
-	UnreadStorage       *cache.UnreadStorage
-	MessageStorage      *cache.MessageStorage
-	ServerStorage       *cache.ServerStorage
-	Sequence            *repo.Sequence
-	RobotRepo           *repo.Robot
-	PushMessage         *logic.PushMessage
+	GroupMemberRepo       *repo.GroupMember
+	SplitUploadRepo       *repo.FileUpload
+	TalkRecordsVoteRepo   *repo.GroupVote
+	UsersRepo             *repo.Users
+	Filesystem            filesystem.IFilesystem
+	UnreadStorage         *cache.UnreadStorage
+	MessageStorage        *cache.MessageStorage
+	ServerStorage         *cache.ServerStorage
+	Sequence              *repo.Sequence
+	RobotRepo             *repo.Robot
+	PushMessage           *logic.PushMessage
+	TalkGroupThreadRepo   *repo.TalkGroupThread
+	TalkExpireSettingRepo *repo.TalkExpireSetting
+	TalkMessageExpireRepo *repo.TalkMessageExpire
+	TalkMessageOutboxRepo *repo.TalkMessageOutbox
+	TalkMessageArchive    *repo.TalkMessageArchive
 }
 
 func (s *Service) CreateMessage(ctx context.Context, option CreateMessageOption) error {
@@ -107,42 +130,76 @@ func (s *Service) CreateMessage(ctx context.Context, option CreateMessageOption)
 			ToFromId: option.ToFromId,
 			QuoteId:  option.QuoteId,
 			Extra:    option.Extra,
+			Expire:   option.Expire,
 		})
 	}
 
 	return s.CreateGroupMessage(ctx, CreateGroupMessageOption{
-		MsgId:    option.MsgId,
-		MsgType:  option.MsgType,
-		FromId:   option.FromId,
-		ToFromId: option.ToFromId,
-		QuoteId:  option.QuoteId,
-		Extra:    option.Extra,
+		MsgId:     option.MsgId,
+		MsgType:   option.MsgType,
+		FromId:    option.FromId,
+		ToFromId:  option.ToFromId,
+		QuoteId:   option.QuoteId,
+		Extra:     option.Extra,
+		RootMsgId: option.RootMsgId,
+		Expire:    option.Expire,
 	})
 }
 
+// linkPreviewUrls 提取文本消息中需要生成预览的链接
+// 消息内容已做 HTML 转义，需还原后再提取，否则链接中的 & 会变成 &amp;
+func linkPreviewUrls(content string) []string {
+	return unfurl.ExtractUrls(html.UnescapeString(content), linkPreviewMaxUrls)
+}
+
 func (s *Service) CreateTextMessage(ctx context.Context, option CreateTextMessage) error {
-	return s.CreateMessage(ctx, CreateMessageOption{
-		MsgId:    option.MsgId,
-		TalkMode: option.TalkMode,
-		FromId:   option.FromId,
-		ToFromId: option.ToFromId,
-		MsgType:  entity.ChatMsgTypeText,
-		QuoteId:  option.QuoteId,
+	urls := linkPreviewUrls(option.Content)
+	if len(urls) > 0 && option.MsgId == "" {
+		option.MsgId = strutil.NewMsgId()
+	}
+
+	err := s.CreateMessage(ctx, CreateMessageOption{
+		MsgId:     option.MsgId,
+		TalkMode:  option.TalkMode,
+		FromId:    option.FromId,
+		ToFromId:  option.ToFromId,
+		RootMsgId: option.RootMsgId,
+		Expire:    option.Expire,
+		MsgType:   entity.ChatMsgTypeText,
+		QuoteId:   option.QuoteId,
 		Extra: jsonutil.Encode(model.TalkRecordExtraText{
 			Content:  option.Content,
 			Mentions: option.Mentions,
 		}),
 	})
+	if err != nil || len(urls) == 0 {
+		return err
+	}
+
+	// 链接预览由队列服务异步抓取，抓取完成后推送 im.message.update
+	err = s.Source.Redis().Publish(ctx, entity.LinkPreviewTopic, jsonutil.Encode(entity.LinkPreviewMessage{
+		TalkMode: option.TalkMode,
+		ToFromId: option.ToFromId,
+		MsgId:    option.MsgId,
+		Urls:     urls,
+	})).Err()
+	if err != nil {
+		logger.Errorf("publish link preview err: %s", err.Error())
+	}
+
+	return nil
 }
 
 func (s *Service) CreateImageMessage(ctx context.Context, option CreateImageMessage) error {
 	return s.CreateMessage(ctx, CreateMessageOption{
-		MsgId:    option.MsgId,
-		TalkMode: option.TalkMode,
-		FromId:   option.FromId,
-		ToFromId: option.ToFromId,
-		MsgType:  entity.ChatMsgTypeImage,
-		QuoteId:  option.QuoteId,
+		MsgId:     option.MsgId,
+		TalkMode:  option.TalkMode,
+		FromId:    option.FromId,
+		ToFromId:  option.ToFromId,
+		RootMsgId: option.RootMsgId,
+		Expire:    option.Expire,
+		MsgType:   entity.ChatMsgTypeImage,
+		QuoteId:   option.QuoteId,
 		Extra: jsonutil.Encode(model.TalkRecordExtraImage{
 			Size:   option.Size,
 			Url:    option.Url,
@@ -154,10 +211,12 @@ func (s *Service) CreateImageMessage(ctx context.Context, option CreateImageMess
 
 func (s *Service) CreateVoiceMessage(ctx context.Context, option CreateVoiceMessage) error {
 	return s.CreateMessage(ctx, CreateMessageOption{
-		TalkMode: option.TalkMode,
-		FromId:   option.FromId,
-		ToFromId: option.ToFromId,
-		MsgType:  entity.ChatMsgTypeAudio,
+		TalkMode:  option.TalkMode,
+		FromId:    option.FromId,
+		ToFromId:  option.ToFromId,
+		RootMsgId: option.RootMsgId,
+		Expire:    option.Expire,
+		MsgType:   entity.ChatMsgTypeAudio,
 		Extra: jsonutil.Encode(model.TalkRecordExtraAudio{
 			Name:     "",
 			Size:     option.Size,
@@ -169,10 +228,12 @@ func (s *Service) CreateVoiceMessage(ctx context.Context, option CreateVoiceMess
 
 func (s *Service) CreateVideoMessage(ctx context.Context, option CreateVideoMessage) error {
 	return s.CreateMessage(ctx, CreateMessageOption{
-		TalkMode: option.TalkMode,
-		FromId:   option.FromId,
-		ToFromId: option.ToFromId,
-		MsgType:  entity.ChatMsgTypeVideo,
+		TalkMode:  option.TalkMode,
+		FromId:    option.FromId,
+		ToFromId:  option.ToFromId,
+		RootMsgId: option.RootMsgId,
+		Expire:    option.Expire,
+		MsgType:   entity.ChatMsgTypeVideo,
 		Extra: jsonutil.Encode(model.TalkRecordExtraVideo{
 			Name:     "",
 			Cover:    option.Cover,
@@ -213,9 +274,11 @@ func (s *Service) CreateFileMessage(ctx context.Context, option CreateFileMessag
 	}
 
 	message := CreateMessageOption{
-		TalkMode: option.TalkMode,
-		FromId:   option.FromId,
-		ToFromId: option.ToFromId,
+		TalkMode:  option.TalkMode,
+		FromId:    option.FromId,
+		ToFromId:  option.ToFromId,
+		RootMsgId: option.RootMsgId,
+		Expire:    option.Expire,
 	}
 
 	switch entity.GetMediaType(file.FileExt) {
@@ -248,11 +311,13 @@ func (s *Service) CreateFileMessage(ctx context.Context, option CreateFileMessag
 
 func (s *Service) CreateCodeMessage(ctx context.Context, option CreateCodeMessage) error {
 	return s.CreateMessage(ctx, CreateMessageOption{
-		MsgId:    option.MsgId,
-		TalkMode: option.TalkMode,
-		FromId:   option.FromId,
-		ToFromId: option.ToFromId,
-		MsgType:  entity.ChatMsgTypeCode,
+		MsgId:     option.MsgId,
+		TalkMode:  option.TalkMode,
+		FromId:    option.FromId,
+		ToFromId:  option.ToFromId,
+		RootMsgId: option.RootMsgId,
+		Expire:    option.Expire,
+		MsgType:   entity.ChatMsgTypeCode,
 		Extra: jsonutil.Encode(model.TalkRecordExtraCode{
 			Lang: option.Lang,
 			Code: option.Code,
@@ -283,10 +348,12 @@ func (s *Service) CreateEmoticonMessage(ctx context.Context, option CreateEmotic
 	}
 
 	return s.CreateMessage(ctx, CreateMessageOption{
-		TalkMode: option.TalkMode,
-		FromId:   option.FromId,
-		ToFromId: option.ToFromId,
-		MsgType:  entity.ChatMsgTypeImage,
+		TalkMode:  option.TalkMode,
+		FromId:    option.FromId,
+		ToFromId:  option.ToFromId,
+		RootMsgId: option.RootMsgId,
+		Expire:    option.Expire,
+		MsgType:   entity.ChatMsgTypeImage,
 		Extra: jsonutil.Encode(model.TalkRecordExtraImage{
 			Url: emoticon.Url,
 		}),
@@ -295,6 +362,16 @@ func (s *Service) CreateEmoticonMessage(ctx context.Context, option CreateEmotic
 
 // CreateForwardMessage todo 待完善
 func (s *Service) CreateForwardMessage(ctx context.Context, option CreateForwardMessage) error {
+	// 限时消息及阅后即焚消息不允许转发
+	exist, err := s.TalkMessageExpireRepo.IsExist(ctx, "msg_id in ?", option.MsgIds)
+	if err != nil {
+		return err
+	}
+
+	if exist {
+		return errors.New("限时消息不支持转发")
+	}
+
 	items := make([]ForwardMessageOpt, 0)
 
 	// 发送方式 1:逐条发送 2:合并发送
@@ -394,11 +471,13 @@ func (s *Service) CreateForwardMessage(ctx context.Context, option CreateForward
 
 func (s *Service) CreateLocationMessage(ctx context.Context, option CreateLocationMessage) error {
 	return s.CreateMessage(ctx, CreateMessageOption{
-		MsgId:    option.MsgId,
-		TalkMode: option.TalkMode,
-		FromId:   option.FromId,
-		ToFromId: option.ToFromId,
-		MsgType:  entity.ChatMsgTypeLocation,
+		MsgId:     option.MsgId,
+		TalkMode:  option.TalkMode,
+		FromId:    option.FromId,
+		ToFromId:  option.ToFromId,
+		RootMsgId: option.RootMsgId,
+		Expire:    option.Expire,
+		MsgType:   entity.ChatMsgTypeLocation,
 		Extra: jsonutil.Encode(model.TalkRecordExtraLocation{
 			Longitude:   option.Longitude,
 			Latitude:    option.Latitude,
@@ -442,11 +521,13 @@ func (s *Service) CreateMixedMessage(ctx context.Context, option CreateMixedMess
 	}
 
 	return s.CreateMessage(ctx, CreateMessageOption{
-		MsgId:    option.MsgId,
-		TalkMode: option.TalkMode,
-		FromId:   option.FromId,
-		ToFromId: option.ToFromId,
-		MsgType:  entity.ChatMsgTypeMixed,
+		MsgId:     option.MsgId,
+		TalkMode:  option.TalkMode,
+		FromId:    option.FromId,
+		ToFromId:  option.ToFromId,
+		RootMsgId: option.RootMsgId,
+		Expire:    option.Expire,
+		MsgType:   entity.ChatMsgTypeMixed,
 		Extra: jsonutil.Encode(model.TalkRecordExtraMixed{
 			Items: items,
 		}),
@@ -500,6 +581,17 @@ func (s *Service) CreateTransferMessage(ctx context.Context, option CreateTransf
 	})
 }
 
+func (s *Service) CreateInteractiveMessage(ctx context.Context, option CreateInteractiveMessage) error {
+	return s.CreateMessage(ctx, CreateMessageOption{
+		MsgId:    option.MsgId,
+		TalkMode: option.TalkMode,
+		FromId:   option.FromId,
+		ToFromId: option.ToFromId,
+		MsgType:  entity.ChatMsgTypeInteractive,
+		Extra:    jsonutil.Encode(option.Card),
+	})
+}
+
 func (s *Service) CreateLoginMessage(ctx context.Context, option CreateLoginMessageOption) error {
 	robot, err := s.RobotRepo.GetLoginRobot(ctx)
 	if err != nil {
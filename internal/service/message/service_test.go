@@ -0,0 +1,46 @@
+package message
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLinkPreviewUrls(t *testing.T) {
+	cases := []struct {
+		content string
+		want    []string
+	}{
+		{content: "看看 https://example.com/a?b=1&amp;c=2 这个", want: []string{"https://example.com/a?b=1&c=2"}},
+		{content: "https://example.com/?q=a&amp;amp;b", want: []string{"https://example.com/?q=a&amp;b"}},
+		{content: "&lt;https://example.com/x&gt;", want: []string{"https://example.com/x"}},
+		{content: "普通消息", want: []string{}},
+	}
+
+	for _, c := range cases {
+		if got := linkPreviewUrls(c.content); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("linkPreviewUrls(%q) = %v, want %v", c.content, got, c.want)
+		}
+	}
+}
+
+func TestSearchText(t *testing.T) {
+	cases := []struct {
+		msgType int
+		extra   string
+		want    string
+	}{
+		{msgType: 1, extra: `{"content":"a &lt; b &amp;&amp; c"}`, want: "a < b && c"},
+		{msgType: 2, extra: `{"lang":"go","code":"x < y"}`, want: "go\nx < y"},
+		{msgType: 3, extra: `{"url":"a.png"}`, want: ""},
+	}
+
+	for _, c := range cases {
+		if got := SearchText(c.msgType, c.extra); got != c.want {
+			t.Errorf("SearchText(%d, %s) = %q, want %q", c.msgType, c.extra, got, c.want)
+		}
+	}
+
+	if got := text(1, `{"content":"a &lt; b"}`); got != "a &lt; b" {
+		t.Errorf("text() = %q, want escaped content", got)
+	}
+}
@@ -0,0 +1,70 @@
+package message
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gzydong/go-chat/internal/entity"
+	"github.com/gzydong/go-chat/internal/pkg/jsonutil"
+	"github.com/gzydong/go-chat/internal/repository/model"
+	"gorm.io/gorm"
+)
+
+// findThreadRoot 查询话题根消息，话题回复本身不能再作为根消息
+func (s *Service) findThreadRoot(ctx context.Context, groupId int, rootMsgId string) (*model.TalkGroupMessage, error) {
+	root := &model.TalkGroupMessage{}
+	err := s.Source.Db().WithContext(ctx).First(root, "msg_id = ? and group_id = ?", rootMsgId, groupId).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("话题消息不存在")
+		}
+
+		return nil, err
+	}
+
+	if root.RootMsgId != "" {
+		return nil, errors.New("不支持在话题回复中创建话题")
+	}
+
+	if root.IsRevoked == model.Yes {
+		return nil, errors.New("消息已撤回，无法回复")
+	}
+
+	return root, nil
+}
+
+// createGroupThreadMessage 创建话题回复消息，与群消息一样通过待投递记录推送
+func (s *Service) createGroupThreadMessage(ctx context.Context, root *model.TalkGroupMessage, item *model.TalkGroupMessage) error {
+	outbox := newOutbox(entity.ChatGroupMode, item.MsgId)
+
+	err := s.Source.Db().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(item).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Create(outbox).Error; err != nil {
+			return err
+		}
+
+		return s.TalkGroupThreadRepo.AddReply(tx, root, item)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.deliverGroupOutbox(ctx, outbox, item)
+
+	return nil
+}
+
+// pushThread 推送话题回复，话题回复不计入会话未读数，也不更新会话最后一条消息
+func (s *Service) pushThread(ctx context.Context, record *model.TalkGroupMessage) error {
+	return s.PushMessage.Push(ctx, entity.ImTopicChat, &entity.SubscribeMessage{
+		Event: entity.SubEventImMessageThread,
+		Payload: jsonutil.Encode(entity.SubEventImMessageThreadPayload{
+			GroupId:   record.GroupId,
+			RootMsgId: record.RootMsgId,
+			Message:   jsonutil.Encode(record),
+		}),
+	})
+}
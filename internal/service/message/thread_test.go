@@ -0,0 +1,110 @@
+package message
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/gzydong/go-chat/internal/repository/model"
+	"github.com/gzydong/go-chat/internal/repository/repo"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// rootConnector 模拟数据库，查询时返回预设的根消息，root 为 nil 时返回空结果
+type rootConnector struct {
+	root *model.TalkGroupMessage
+}
+
+func (r *rootConnector) Connect(context.Context) (driver.Conn, error) { return &rootConn{r}, nil }
+func (r *rootConnector) Driver() driver.Driver                        { return nil }
+
+type rootConn struct {
+	*rootConnector
+}
+
+func (r *rootConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (r *rootConn) Close() error                        { return nil }
+func (r *rootConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (r *rootConn) QueryContext(context.Context, string, []driver.NamedValue) (driver.Rows, error) {
+	rows := &rootRows{}
+	if r.root != nil {
+		rows.values = [][]driver.Value{{int64(r.root.Id), r.root.MsgId, int64(r.root.GroupId), int64(r.root.FromId), r.root.RootMsgId, int64(r.root.IsRevoked)}}
+	}
+
+	return rows, nil
+}
+
+type rootRows struct {
+	values [][]driver.Value
+}
+
+func (r *rootRows) Columns() []string {
+	return []string{"id", "msg_id", "group_id", "from_id", "root_msg_id", "is_revoked"}
+}
+
+func (r *rootRows) Close() error { return nil }
+
+func (r *rootRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+func newRootService(t *testing.T, root *model.TalkGroupMessage) *Service {
+	conn := sql.OpenDB(&rootConnector{root: root})
+	t.Cleanup(func() { _ = conn.Close() })
+
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: conn, SkipInitializeWithVersion: true}), &gorm.Config{
+		SkipDefaultTransaction: true,
+		Logger:                 logger.Discard,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &Service{Source: repo.NewSource(db, nil)}
+}
+
+func TestFindThreadRoot(t *testing.T) {
+	cases := []struct {
+		name    string
+		root    *model.TalkGroupMessage
+		wantErr string
+	}{
+		{name: "missing root", root: nil, wantErr: "话题消息不存在"},
+		{name: "revoked root", root: &model.TalkGroupMessage{Id: 1, MsgId: "root", GroupId: 10, FromId: 1, IsRevoked: model.Yes}, wantErr: "消息已撤回，无法回复"},
+		{name: "reply as root", root: &model.TalkGroupMessage{Id: 1, MsgId: "root", GroupId: 10, FromId: 1, RootMsgId: "other", IsRevoked: model.No}, wantErr: "不支持在话题回复中创建话题"},
+		{name: "root", root: &model.TalkGroupMessage{Id: 1, MsgId: "root", GroupId: 10, FromId: 1, IsRevoked: model.No}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			root, err := newRootService(t, c.root).findThreadRoot(context.Background(), 10, "root")
+			if c.wantErr != "" {
+				if err == nil || err.Error() != c.wantErr {
+					t.Fatalf("findThreadRoot() error = %v, want %q", err, c.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("findThreadRoot() error = %v", err)
+			}
+
+			if root.MsgId != "root" || root.FromId != 1 {
+				t.Errorf("findThreadRoot() root = %+v", root)
+			}
+		})
+	}
+}
@@ -0,0 +1,308 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gzydong/go-chat/internal/entity"
+	"github.com/gzydong/go-chat/internal/pkg/logger"
+	"github.com/gzydong/go-chat/internal/pkg/sensitive"
+	"github.com/gzydong/go-chat/internal/pkg/strutil"
+	"github.com/gzydong/go-chat/internal/repository/model"
+	"github.com/gzydong/go-chat/internal/repository/repo"
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+)
+
+// moderationReloadInterval 词库版本检查间隔，管理后台修改词库后其它节点在此间隔内生效
+const moderationReloadInterval = 30 * time.Second
+
+var _ IModerationService = (*ModerationService)(nil)
+
+type moderationDictionary struct {
+	version string
+	matcher *sensitive.Matcher
+	actions []int
+}
+
+var (
+	moderationDict      atomic.Pointer[moderationDictionary]
+	moderationCheckedAt atomic.Int64
+	moderationMutex     sync.Mutex
+)
+
+type ModerationCheckOpt struct {
+	Scene   string // 内容场景
+	UserId  int    // 提交内容的用户ID
+	Content string // 待检测内容
+}
+
+type ModerationResult struct {
+	Content     string   // 替换需屏蔽的敏感词后的内容
+	MaskContent string   // 替换全部敏感词后的内容
+	Action      int      // 处理方式，0 表示未命中
+	Words       []string // 命中的敏感词
+	Label       string   // 外部审核标签
+}
+
+// IsReview 是否需要人工审核
+func (r *ModerationResult) IsReview() bool {
+	return r != nil && r.Action == model.SensitiveActionReview
+}
+
+type ModerationFlagOpt struct {
+	Scene    string
+	UserId   int
+	TargetId int // 用户ID、群ID、公告ID或笔记ID，消息场景为空
+	TalkMode int
+	ToFromId int
+	MsgId    string
+	Result   *ModerationResult
+}
+
+type ModerationReviewOpt struct {
+	Id       int
+	AdminId  int
+	Approved bool
+	Remark   string
+}
+
+type ModerationClassifyResult struct {
+	Action int    // 处理方式，仅支持审核及拒绝
+	Label  string // 审核标签
+}
+
+// IModerationClassifier 外部内容审核，可接入第三方内容安全服务
+type IModerationClassifier interface {
+	Classify(ctx context.Context, scene string, content string) (*ModerationClassifyResult, error)
+}
+
+// NoopModerationClassifier 默认不接入外部内容审核
+type NoopModerationClassifier struct{}
+
+func (NoopModerationClassifier) Classify(_ context.Context, _ string, _ string) (*ModerationClassifyResult, error) {
+	return nil, nil
+}
+
+type IModerationService interface {
+	// Check 检测内容，命中拒绝规则时返回 entity.ErrContentRejected
+	Check(ctx context.Context, opt *ModerationCheckOpt) (*ModerationResult, error)
+	// Flag 内容需人工审核时写入审核队列
+	Flag(ctx context.Context, opt *ModerationFlagOpt)
+	// Reload 词库有变化时重新加载
+	Reload(ctx context.Context) error
+	// Review 审核内容，驳回时撤回消息或替换为屏蔽后的内容
+	Review(ctx context.Context, opt *ModerationReviewOpt) error
+}
+
+type ModerationService struct {
+	*repo.Source
+	SensitiveWordRepo    *repo.SensitiveWord
+	ModerationRecordRepo *repo.ModerationRecord
+	UsersRepo            *repo.Users
+	Classifier           IModerationClassifier
+	TalkService          ITalkService
+}
+
+func (m *ModerationService) Check(ctx context.Context, opt *ModerationCheckOpt) (*ModerationResult, error) {
+	result := &ModerationResult{Content: opt.Content, MaskContent: opt.Content, Words: make([]string, 0)}
+	if strings.TrimSpace(opt.Content) == "" {
+		return result, nil
+	}
+
+	if dict := m.dictionary(ctx); dict != nil {
+		hits := dict.matcher.Match(opt.Content)
+
+		masks := make([]*sensitive.Hit, 0, len(hits))
+		for _, hit := range hits {
+			action := dict.actions[hit.Index]
+			result.Action = max(result.Action, action)
+
+			if !lo.Contains(result.Words, hit.Word) {
+				result.Words = append(result.Words, hit.Word)
+			}
+
+			if action == model.SensitiveActionMask {
+				masks = append(masks, hit)
+			}
+		}
+
+		result.Content = sensitive.Mask(opt.Content, masks, '*')
+		result.MaskContent = sensitive.Mask(opt.Content, hits, '*')
+	}
+
+	if result.Action != model.SensitiveActionReject {
+		classified, err := m.Classifier.Classify(ctx, opt.Scene, result.Content)
+		if err != nil {
+			// 外部审核不可用时仅依据词库处理
+			logger.Errorf("moderation classify err: %s", err.Error())
+		} else if classified != nil {
+			result.Label = classified.Label
+			if classified.Action >= model.SensitiveActionReview {
+				result.Action = max(result.Action, classified.Action)
+			}
+		}
+	}
+
+	if result.Action == model.SensitiveActionReject {
+		return result, entity.ErrContentRejected
+	}
+
+	return result, nil
+}
+
+func (m *ModerationService) Flag(ctx context.Context, opt *ModerationFlagOpt) {
+	if !opt.Result.IsReview() {
+		return
+	}
+
+	now := time.Now()
+	err := m.ModerationRecordRepo.Create(ctx, &model.ModerationRecord{
+		Scene:       opt.Scene,
+		UserId:      opt.UserId,
+		TargetId:    opt.TargetId,
+		TalkMode:    opt.TalkMode,
+		ToFromId:    opt.ToFromId,
+		MsgId:       opt.MsgId,
+		Content:     opt.Result.Content,
+		MaskContent: opt.Result.MaskContent,
+		Words:       strutil.MtSubstr(strings.Join(opt.Result.Words, ","), 0, 500),
+		Label:       strutil.MtSubstr(opt.Result.Label, 0, 64),
+		Status:      model.ModerationStatusPending,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	})
+	if err != nil {
+		logger.Errorf("moderation flag %s err: %s", opt.Scene, err.Error())
+	}
+}
+
+// dictionary 获取当前词库，超过检查间隔时检查词库版本
+func (m *ModerationService) dictionary(ctx context.Context) *moderationDictionary {
+	now := time.Now().Unix()
+
+	checkedAt := moderationCheckedAt.Load()
+	if now-checkedAt >= int64(moderationReloadInterval/time.Second) && moderationCheckedAt.CompareAndSwap(checkedAt, now) {
+		if err := m.Reload(ctx); err != nil {
+			logger.Errorf("moderation reload err: %s", err.Error())
+		}
+	}
+
+	return moderationDict.Load()
+}
+
+func (m *ModerationService) Reload(ctx context.Context) error {
+	moderationMutex.Lock()
+	defer moderationMutex.Unlock()
+
+	version, err := m.SensitiveWordRepo.Version(ctx)
+	if err != nil {
+		return err
+	}
+
+	if dict := moderationDict.Load(); dict != nil && dict.version == version {
+		return nil
+	}
+
+	items, err := m.SensitiveWordRepo.FindAllEnabled(ctx)
+	if err != nil {
+		return err
+	}
+
+	words := make([]string, 0, len(items))
+	actions := make([]int, 0, len(items))
+	for _, item := range items {
+		words = append(words, item.Word)
+		actions = append(actions, item.Action)
+	}
+
+	moderationDict.Store(&moderationDictionary{
+		version: version,
+		matcher: sensitive.New(words),
+		actions: actions,
+	})
+
+	return nil
+}
+
+func (m *ModerationService) Review(ctx context.Context, opt *ModerationReviewOpt) error {
+	record, err := m.ModerationRecordRepo.FindById(ctx, opt.Id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("审核记录不存在")
+		}
+
+		return err
+	}
+
+	if record.Status != model.ModerationStatusPending {
+		return errors.New("该内容已审核")
+	}
+
+	now := time.Now()
+	err = m.Source.Db().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&model.ModerationRecord{}).
+			Where("id = ? and status = ?", record.Id, model.ModerationStatusPending).
+			Updates(map[string]any{
+				"status":        lo.Ternary(opt.Approved, model.ModerationStatusApproved, model.ModerationStatusRejected),
+				"reviewer_id":   opt.AdminId,
+				"review_remark": opt.Remark,
+				"reviewed_at":   sql.NullTime{Time: now, Valid: true},
+				"updated_at":    now,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+
+		if res.RowsAffected == 0 {
+			return errors.New("该内容已审核")
+		}
+
+		if opt.Approved {
+			return nil
+		}
+
+		return m.takedown(tx, record)
+	})
+	if err != nil {
+		return err
+	}
+
+	if opt.Approved {
+		return nil
+	}
+
+	switch record.Scene {
+	case model.ModerationSceneMessage:
+		return m.TalkService.ForceRevoke(ctx, record.TalkMode, record.MsgId, "该消息因违规已被撤回")
+	case model.ModerationSceneNickname:
+		_ = m.UsersRepo.ClearTableCache(ctx, record.TargetId)
+	}
+
+	return nil
+}
+
+// takedown 驳回内容，未被修改时替换为屏蔽后的内容，消息在提交后撤回
+func (m *ModerationService) takedown(tx *gorm.DB, record *model.ModerationRecord) error {
+	switch record.Scene {
+	case model.ModerationSceneNickname:
+		return tx.Model(&model.Users{}).Where("id = ? and nickname = ?", record.TargetId, record.Content).
+			Update("nickname", record.MaskContent).Error
+	case model.ModerationSceneGroupName:
+		return tx.Model(&model.Group{}).Where("id = ? and name = ?", record.TargetId, record.Content).
+			Update("name", record.MaskContent).Error
+	case model.ModerationSceneGroupNotice:
+		return tx.Model(&model.GroupNotice{}).Where("id = ? and content = ?", record.TargetId, record.Content).
+			Update("content", record.MaskContent).Error
+	case model.ModerationSceneArticleTitle:
+		return tx.Model(&model.Article{}).Where("id = ? and title = ?", record.TargetId, record.Content).
+			Update("title", record.MaskContent).Error
+	}
+
+	return nil
+}
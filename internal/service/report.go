@@ -0,0 +1,338 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gzydong/go-chat/internal/entity"
+	"github.com/gzydong/go-chat/internal/pkg/jsonutil"
+	"github.com/gzydong/go-chat/internal/pkg/sliceutil"
+	"github.com/gzydong/go-chat/internal/pkg/strutil"
+	"github.com/gzydong/go-chat/internal/repository/model"
+	"github.com/gzydong/go-chat/internal/repository/repo"
+	"github.com/gzydong/go-chat/internal/service/message"
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+)
+
+// reportMaxMessages 单次举报最多附带的消息数
+const reportMaxMessages = 20
+
+var _ IReportService = (*ReportService)(nil)
+
+type ReportCreateOpt struct {
+	UserId      int
+	TargetType  int
+	TargetId    int // 举报用户或群聊时必填
+	TalkMode    int // 证据消息所在的对话类型，举报消息时必填
+	ToFromId    int // 证据消息所在的会话，举报消息时必填
+	MsgIds      []string
+	Reason      int
+	Description string
+	Images      []string
+}
+
+type ReportHandleOpt struct {
+	ReportId int
+	AdminId  int
+	Actions  []string
+	Remark   string
+}
+
+type IReportService interface {
+	// Create 提交举报，并保存证据消息快照
+	Create(ctx context.Context, opt *ReportCreateOpt) (int, error)
+	// Handle 处理举报，每个处理动作写入审计记录
+	Handle(ctx context.Context, opt *ReportHandleOpt) error
+}
+
+type ReportService struct {
+	*repo.Source
+	ReportRepo         *repo.Report
+	ReportAuditRepo    *repo.ReportAudit
+	UsersRepo          *repo.Users
+	GroupRepo          *repo.Group
+	GroupMemberRepo    *repo.GroupMember
+	TalkRecordService  ITalkRecordService
+	TalkService        ITalkService
+	GroupService       IGroupService
+	GroupMemberService IGroupMemberService
+	MessageService     message.IService
+}
+
+func (r *ReportService) Create(ctx context.Context, opt *ReportCreateOpt) (int, error) {
+	opt.MsgIds = sliceutil.Unique(opt.MsgIds)
+	if len(opt.MsgIds) > reportMaxMessages {
+		return 0, fmt.Errorf("单次最多举报%d条消息", reportMaxMessages)
+	}
+
+	// 举报用户或群聊时，证据消息只能来自与其的会话
+	switch opt.TargetType {
+	case model.ReportTargetMessage:
+		if len(opt.MsgIds) == 0 {
+			return 0, errors.New("请选择举报的消息")
+		}
+	case model.ReportTargetUser:
+		if opt.TargetId == opt.UserId {
+			return 0, errors.New("不能举报自己")
+		}
+
+		if _, err := r.UsersRepo.FindByIdWithCache(ctx, opt.TargetId); err != nil {
+			return 0, entity.ErrUserNotExist
+		}
+
+		opt.TalkMode, opt.ToFromId = entity.ChatPrivateMode, opt.TargetId
+	case model.ReportTargetGroup:
+		if _, err := r.GroupRepo.FindById(ctx, opt.TargetId); err != nil {
+			return 0, entity.ErrGroupNotExist
+		}
+
+		opt.TalkMode, opt.ToFromId = entity.ChatGroupMode, opt.TargetId
+	default:
+		return 0, errors.New("举报对象错误")
+	}
+
+	evidence, err := r.snapshot(ctx, opt)
+	if err != nil {
+		return 0, err
+	}
+
+	if opt.TargetType == model.ReportTargetMessage {
+		senders := sliceutil.Unique(lo.Map(evidence, func(item *model.TalkMessageRecord, _ int) int {
+			return item.FromId
+		}))
+
+		if len(senders) != 1 {
+			return 0, errors.New("举报的消息需来自同一用户")
+		}
+
+		if senders[0] == opt.UserId {
+			return 0, errors.New("不能举报自己的消息")
+		}
+
+		opt.TargetId = senders[0]
+	}
+
+	msgIds := jsonutil.Encode(opt.MsgIds)
+
+	exist, err := r.ReportRepo.IsExist(ctx, "user_id = ? and target_type = ? and target_id = ? and msg_ids = ? and status = ?",
+		opt.UserId, opt.TargetType, opt.TargetId, msgIds, model.ReportStatusPending)
+	if err != nil {
+		return 0, err
+	}
+
+	if exist {
+		return 0, errors.New("已提交举报，请耐心等待处理结果")
+	}
+
+	now := time.Now()
+	report := &model.Report{
+		UserId:      opt.UserId,
+		TargetType:  opt.TargetType,
+		TargetId:    opt.TargetId,
+		TalkMode:    lo.Ternary(len(evidence) > 0, opt.TalkMode, 0),
+		ToFromId:    lo.Ternary(len(evidence) > 0, opt.ToFromId, 0),
+		MsgIds:      msgIds,
+		Reason:      opt.Reason,
+		Description: opt.Description,
+		Images:      jsonutil.Encode(lo.Ternary(opt.Images == nil, []string{}, opt.Images)),
+		Evidence:    jsonutil.Encode(evidence),
+		Status:      model.ReportStatusPending,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if err := r.ReportRepo.Create(ctx, report); err != nil {
+		return 0, err
+	}
+
+	return report.Id, nil
+}
+
+// snapshot 获取证据消息快照，仅允许举报人可见的消息
+func (r *ReportService) snapshot(ctx context.Context, opt *ReportCreateOpt) ([]*model.TalkMessageRecord, error) {
+	items := make([]*model.TalkMessageRecord, 0, len(opt.MsgIds))
+	if len(opt.MsgIds) == 0 {
+		return items, nil
+	}
+
+	if opt.TalkMode == entity.ChatGroupMode && !r.GroupMemberRepo.IsMember(ctx, opt.ToFromId, opt.UserId, false) {
+		return nil, entity.ErrPermissionDenied
+	}
+
+	for _, msgId := range opt.MsgIds {
+		var (
+			record *model.TalkMessageRecord
+			err    error
+		)
+
+		if opt.TalkMode == entity.ChatGroupMode {
+			record, err = r.TalkRecordService.FindTalkGroupRecord(ctx, msgId)
+		} else {
+			record, err = r.TalkRecordService.FindTalkPrivateRecord(ctx, opt.UserId, msgId)
+		}
+
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, errors.New("举报的消息不存在")
+			}
+
+			return nil, err
+		}
+
+		if record.ToFromId != opt.ToFromId {
+			return nil, errors.New("举报的消息不存在")
+		}
+
+		items = append(items, record)
+	}
+
+	return items, nil
+}
+
+func (r *ReportService) Handle(ctx context.Context, opt *ReportHandleOpt) error {
+	report, err := r.ReportRepo.FindById(ctx, opt.ReportId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("举报记录不存在")
+		}
+
+		return err
+	}
+
+	if report.Status != model.ReportStatusPending {
+		return errors.New("该举报已处理")
+	}
+
+	actions := sliceutil.Unique(opt.Actions)
+	if len(actions) == 0 {
+		return errors.New("请选择处理方式")
+	}
+
+	ignored := lo.Contains(actions, model.ReportActionIgnore)
+	if ignored && len(actions) > 1 {
+		return errors.New("忽略举报时不能选择其它处理方式")
+	}
+
+	// 逐个执行处理动作，已执行的动作均记录审计，失败后可重新处理
+	for _, action := range actions {
+		detail, err := r.execute(ctx, report, action)
+		if err != nil {
+			return err
+		}
+
+		err = r.ReportAuditRepo.Create(ctx, &model.ReportAudit{
+			ReportId:  report.Id,
+			AdminId:   opt.AdminId,
+			Action:    action,
+			Remark:    opt.Remark,
+			Detail:    strutil.MtSubstr(detail, 0, 500),
+			CreatedAt: time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	affected, err := r.ReportRepo.UpdateByWhere(ctx, map[string]any{
+		"status":     lo.Ternary(ignored, model.ReportStatusIgnored, model.ReportStatusHandled),
+		"handler_id": opt.AdminId,
+		"handled_at": sql.NullTime{Time: now, Valid: true},
+		"updated_at": now,
+	}, "id = ? and status = ?", report.Id, model.ReportStatusPending)
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return errors.New("该举报已处理")
+	}
+
+	return nil
+}
+
+// execute 执行处理动作，返回处理结果描述
+func (r *ReportService) execute(ctx context.Context, report *model.Report, action string) (string, error) {
+	switch action {
+	case model.ReportActionIgnore:
+		return "忽略举报", nil
+	case model.ReportActionRevokeMessage:
+		var msgIds []string
+		if err := jsonutil.Unmarshal(report.MsgIds, &msgIds); err != nil || len(msgIds) == 0 {
+			return "", errors.New("该举报没有可撤回的消息")
+		}
+
+		for _, msgId := range msgIds {
+			if err := r.TalkService.ForceRevoke(ctx, report.TalkMode, msgId, "该消息因违规已被撤回"); err != nil {
+				return "", err
+			}
+		}
+
+		return fmt.Sprintf("撤回消息 %s", report.MsgIds), nil
+	case model.ReportActionMuteMember:
+		if report.TargetType == model.ReportTargetGroup || report.TalkMode != entity.ChatGroupMode {
+			return "", errors.New("仅支持禁言群聊中被举报的用户")
+		}
+
+		if err := r.GroupMemberService.SetMuteStatus(ctx, report.ToFromId, report.TargetId, model.Yes); err != nil {
+			return "", err
+		}
+
+		_ = r.MessageService.CreateGroupSysMessage(ctx, message.CreateGroupSysMessageOption{
+			GroupId: report.ToFromId,
+			Content: "有成员因违规已被管理员禁言",
+		})
+
+		return fmt.Sprintf("群 %d 禁言用户 %d", report.ToFromId, report.TargetId), nil
+	case model.ReportActionDisableUser:
+		if report.TargetType == model.ReportTargetGroup {
+			return "", errors.New("举报群聊时不支持停用用户")
+		}
+
+		_, err := r.UsersRepo.UpdateById(ctx, report.TargetId, map[string]any{
+			"status":     model.UsersStatusDisabled,
+			"updated_at": time.Now(),
+		})
+		if err != nil {
+			return "", err
+		}
+
+		_ = r.UsersRepo.ClearTableCache(ctx, report.TargetId)
+		return fmt.Sprintf("停用用户 %d", report.TargetId), nil
+	case model.ReportActionDismissGroup:
+		groupId := lo.Ternary(report.TargetType == model.ReportTargetGroup, report.TargetId, 0)
+		if groupId == 0 && report.TalkMode == entity.ChatGroupMode {
+			groupId = report.ToFromId
+		}
+
+		if groupId == 0 {
+			return "", errors.New("该举报没有可解散的群聊")
+		}
+
+		group, err := r.GroupRepo.FindById(ctx, groupId)
+		if err != nil {
+			return "", err
+		}
+
+		if group.IsDismiss == model.Yes {
+			return fmt.Sprintf("群 %d 已解散", groupId), nil
+		}
+
+		if err := r.GroupService.Dismiss(ctx, groupId, group.CreatorId); err != nil {
+			return "", err
+		}
+
+		_ = r.MessageService.CreateGroupSysMessage(ctx, message.CreateGroupSysMessageOption{
+			GroupId: groupId,
+			Content: "该群因违规已被管理员解散！",
+		})
+
+		return fmt.Sprintf("解散群 %d", groupId), nil
+	}
+
+	return "", fmt.Errorf("不支持的处理方式: %s", action)
+}
@@ -34,6 +34,8 @@ type TalkDeleteRecordOption struct {
 type ITalkService interface {
 	DeleteRecord(ctx context.Context, opt *TalkDeleteRecordOption) error
 	Revoke(ctx context.Context, opt *TalkRevokeOption) error
+	// ForceRevoke 管理员撤回消息，不校验发送者及撤回时间
+	ForceRevoke(ctx context.Context, talkMode int, msgId string, remark string) error
 }
 
 type TalkService struct {
@@ -42,6 +44,7 @@ type TalkService struct {
 	UserRepo        *repo.Users
 	PushMessage     *logic.PushMessage
 	MessageStorage  *cache.MessageStorage
+	TalkSyncService ITalkSyncService
 }
 
 // DeleteRecord 删除消息记录
@@ -50,9 +53,15 @@ func (t *TalkService) DeleteRecord(ctx context.Context, opt *TalkDeleteRecordOpt
 
 	// 私有消息直接更新删除状态
 	if opt.TalkMode == entity.ChatPrivateMode {
-		return db.Model(model.TalkUserMessage{}).
+		err := db.Model(model.TalkUserMessage{}).
 			Where("user_id = ? and msg_id in ?", opt.UserId, opt.MsgIds).
 			Update("is_deleted", model.Yes).Error
+		if err != nil {
+			return err
+		}
+
+		t.TalkSyncService.RecordUserEvent(ctx, opt.UserId, model.TalkSyncEventDelete, opt.TalkMode, opt.ToFromId, &TalkSyncPayload{MsgIds: opt.MsgIds})
+		return nil
 	}
 
 	if !t.GroupMemberRepo.IsMember(ctx, opt.ToFromId, opt.UserId, false) {
@@ -79,7 +88,12 @@ func (t *TalkService) DeleteRecord(ctx context.Context, opt *TalkDeleteRecordOpt
 	}
 
 	// 删除后清除最后一条记录
-	return db.Create(items).Error
+	if err := db.Create(items).Error; err != nil {
+		return err
+	}
+
+	t.TalkSyncService.RecordUserEvent(ctx, opt.UserId, model.TalkSyncEventDelete, opt.TalkMode, opt.ToFromId, &TalkSyncPayload{MsgIds: opt.MsgIds})
+	return nil
 }
 
 // Revoke 撤回消息
@@ -145,9 +159,7 @@ func (t *TalkService) Revoke(ctx context.Context, opt *TalkRevokeOption) (err er
 		fromId = record.FromId
 		toFromId = record.ToFromId
 
-		return db.Model(&model.TalkUserMessage{}).
-			Where("org_msg_id = ?", record.OrgMsgId).
-			Update("is_revoked", model.Yes).Error
+		return t.revokePrivate(ctx, &record)
 
 	case entity.ChatGroupMode:
 		var record model.TalkGroupMessage
@@ -172,10 +184,90 @@ func (t *TalkService) Revoke(ctx context.Context, opt *TalkRevokeOption) (err er
 		fromId = record.FromId
 		toFromId = record.GroupId
 
-		return db.Model(&model.TalkGroupMessage{}).
-			Where("msg_id = ?", record.MsgId).
-			Update("is_revoked", model.Yes).Error
+		return t.revokeGroup(ctx, &record)
 	}
 
 	return errors.New("暂不支持撤回消息")
 }
+
+// ForceRevoke 管理员撤回消息，不校验发送者及撤回时间
+// 私聊消息可传任意一方的消息ID，双方的消息副本一并撤回
+func (t *TalkService) ForceRevoke(ctx context.Context, talkMode int, msgId string, remark string) error {
+	db := t.Source.Db().WithContext(ctx)
+
+	switch talkMode {
+	case entity.ChatPrivateMode:
+		var record model.TalkUserMessage
+		if err := db.First(&record, "msg_id = ?", msgId).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("消息ID不存在")
+			}
+
+			return err
+		}
+
+		if err := t.revokePrivate(ctx, &record); err != nil {
+			return err
+		}
+	case entity.ChatGroupMode:
+		var record model.TalkGroupMessage
+		if err := db.First(&record, "msg_id = ?", msgId).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("消息ID不存在")
+			}
+
+			return err
+		}
+
+		if err := t.revokeGroup(ctx, &record); err != nil {
+			return err
+		}
+	default:
+		return errors.New("暂不支持撤回消息")
+	}
+
+	return t.PushMessage.Push(ctx, entity.ImTopicChat, &entity.SubscribeMessage{
+		Event: entity.SubEventImMessageRevoke,
+		Payload: jsonutil.Encode(entity.SubEventTalkRevokePayload{
+			TalkMode: talkMode,
+			MsgId:    msgId,
+			Remark:   remark,
+		}),
+	})
+}
+
+// revokePrivate 撤回私聊消息，双方的消息副本一并撤回并各自记录变更
+func (t *TalkService) revokePrivate(ctx context.Context, record *model.TalkUserMessage) error {
+	db := t.Source.Db().WithContext(ctx)
+
+	err := db.Model(&model.TalkUserMessage{}).
+		Where("org_msg_id = ?", record.OrgMsgId).
+		Update("is_revoked", model.Yes).Error
+	if err != nil {
+		return err
+	}
+
+	var copies []*model.TalkUserMessage
+	if err := db.Select("user_id,to_from_id,msg_id").Where("org_msg_id = ?", record.OrgMsgId).Find(&copies).Error; err != nil {
+		return err
+	}
+
+	for _, item := range copies {
+		t.TalkSyncService.RecordUserEvent(ctx, item.UserId, model.TalkSyncEventRevoke, entity.ChatPrivateMode, item.ToFromId, &TalkSyncPayload{MsgId: item.MsgId})
+	}
+
+	return nil
+}
+
+// revokeGroup 撤回群消息并记录群变更
+func (t *TalkService) revokeGroup(ctx context.Context, record *model.TalkGroupMessage) error {
+	err := t.Source.Db().WithContext(ctx).Model(&model.TalkGroupMessage{}).
+		Where("msg_id = ?", record.MsgId).
+		Update("is_revoked", model.Yes).Error
+	if err != nil {
+		return err
+	}
+
+	t.TalkSyncService.RecordGroupEvent(ctx, record.GroupId, model.TalkSyncEventRevoke, &TalkSyncPayload{MsgId: record.MsgId})
+	return nil
+}
@@ -0,0 +1,385 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gzydong/go-chat/internal/entity"
+	"github.com/gzydong/go-chat/internal/logic"
+	"github.com/gzydong/go-chat/internal/pkg/jsonutil"
+	"github.com/gzydong/go-chat/internal/pkg/logger"
+	"github.com/gzydong/go-chat/internal/pkg/safehttp"
+	"github.com/gzydong/go-chat/internal/pkg/strutil"
+	"github.com/gzydong/go-chat/internal/repository/model"
+	"github.com/gzydong/go-chat/internal/repository/repo"
+	"github.com/gzydong/go-chat/internal/service/message"
+	"gorm.io/gorm"
+)
+
+const (
+	talkCardCallbackEvent   = "card.action"
+	talkCardCallbackTimeout = 5 * time.Second
+	talkCardResponseMaxSize = 64 << 10
+)
+
+var _ ITalkCardService = (*TalkCardService)(nil)
+
+var (
+	talkCardActionRegexp = regexp.MustCompile(`^[a-zA-Z0-9_.:-]{1,64}$`)
+
+	// 机器人回调地址由群管理员配置，同样不允许访问内网地址
+	talkCardClient = safehttp.NewClient(safehttp.Options{Timeout: talkCardCallbackTimeout, MaxRedirects: -1})
+)
+
+type TalkCardActionOpt struct {
+	UserId int
+	MsgId  string
+	Action string
+}
+
+type TalkCardActionResult struct {
+	Toast string // 机器人返回的提示信息
+}
+
+// TalkCardCallback 按钮点击回调内容
+type TalkCardCallback struct {
+	Event     string `json:"event"`
+	RobotId   int    `json:"robot_id"`
+	GroupId   int    `json:"group_id"`
+	MsgId     string `json:"msg_id"`
+	Version   int    `json:"version"`
+	UserId    int    `json:"user_id"`
+	Nickname  string `json:"nickname"`
+	Action    string `json:"action"`
+	Value     string `json:"value"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// TalkCardCallbackResponse 机器人的回调响应，返回卡片内容时原地更新卡片
+type TalkCardCallbackResponse struct {
+	Toast string                            `json:"toast"`
+	Card  *model.TalkRecordExtraInteractive `json:"card"`
+}
+
+type ITalkCardService interface {
+	// Send 群机器人发送卡片消息，返回消息ID
+	Send(ctx context.Context, robot *model.GroupRobot, card *model.TalkRecordExtraInteractive) (string, error)
+	// Action 点击卡片按钮，签名后回调给所属机器人
+	Action(ctx context.Context, opt *TalkCardActionOpt) (*TalkCardActionResult, error)
+	// Update 群机器人更新卡片内容
+	Update(ctx context.Context, robot *model.GroupRobot, msgId string, card *model.TalkRecordExtraInteractive) error
+}
+
+type TalkCardService struct {
+	*repo.Source
+	GroupRobotRepo   *repo.GroupRobot
+	GroupMemberRepo  *repo.GroupMember
+	TalkGroupMessage *repo.TalkGroupMessage
+	UsersRepo        *repo.Users
+	MessageService   message.IService
+	PushMessage      *logic.PushMessage
+}
+
+func (t *TalkCardService) Send(ctx context.Context, robot *model.GroupRobot, card *model.TalkRecordExtraInteractive) (string, error) {
+	if err := validateTalkCard(card); err != nil {
+		return "", err
+	}
+
+	card.RobotId, card.RobotName, card.Version = robot.Id, robot.RobotName, 1
+
+	msgId := strutil.NewMsgId()
+	err := t.MessageService.CreateInteractiveMessage(ctx, message.CreateInteractiveMessage{
+		MsgId:    msgId,
+		TalkMode: entity.ChatGroupMode,
+		ToFromId: robot.GroupId,
+		Card:     card,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return msgId, nil
+}
+
+func (t *TalkCardService) Action(ctx context.Context, opt *TalkCardActionOpt) (*TalkCardActionResult, error) {
+	record, card, err := t.find(ctx, opt.MsgId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !t.GroupMemberRepo.IsMember(ctx, record.GroupId, opt.UserId, true) {
+		return nil, entity.ErrPermissionDenied
+	}
+
+	var button *model.TalkRecordExtraInteractiveButton
+	for _, item := range card.Buttons {
+		if item.Action == opt.Action {
+			button = item
+			break
+		}
+	}
+
+	if button == nil || button.Disabled {
+		return nil, errors.New("该按钮不可用")
+	}
+
+	robot, err := t.GroupRobotRepo.FindById(ctx, card.RobotId)
+	if err != nil || robot.Status != model.GroupRobotStatusActive {
+		return nil, errors.New("机器人已被禁用")
+	}
+
+	if robot.CallbackUrl == "" {
+		return nil, errors.New("机器人未配置回调地址")
+	}
+
+	// 同一用户对同一卡片的点击间隔至少 1 秒
+	key := fmt.Sprintf("im:message:card-action:%s:%d", record.MsgId, opt.UserId)
+	if ok, _ := t.Source.Redis().SetNX(ctx, key, 1, time.Second).Result(); !ok {
+		return nil, errors.New("操作太频繁，请稍后再试")
+	}
+
+	callback := &TalkCardCallback{
+		Event:     talkCardCallbackEvent,
+		RobotId:   robot.Id,
+		GroupId:   record.GroupId,
+		MsgId:     record.MsgId,
+		Version:   card.Version,
+		UserId:    opt.UserId,
+		Action:    button.Action,
+		Value:     button.Value,
+		Timestamp: time.Now().Unix(),
+	}
+
+	if user, err := t.UsersRepo.FindByIdWithCache(ctx, opt.UserId); err == nil {
+		callback.Nickname = user.Nickname
+	}
+
+	resp, err := t.callback(ctx, robot, callback)
+	if err != nil {
+		logger.Errorf("talk card callback robot_%d err: %s", robot.Id, err.Error())
+		return nil, errors.New("机器人响应异常，请稍后再试")
+	}
+
+	if resp.Card != nil {
+		if err := t.update(ctx, robot, record, card, resp.Card); err != nil {
+			return nil, err
+		}
+	}
+
+	return &TalkCardActionResult{Toast: strutil.MtSubstr(resp.Toast, 0, 100)}, nil
+}
+
+func (t *TalkCardService) Update(ctx context.Context, robot *model.GroupRobot, msgId string, card *model.TalkRecordExtraInteractive) error {
+	record, current, err := t.find(ctx, msgId)
+	if err != nil {
+		return err
+	}
+
+	if record.GroupId != robot.GroupId || current.RobotId != robot.Id {
+		return errors.New("卡片消息不存在")
+	}
+
+	return t.update(ctx, robot, record, current, card)
+}
+
+// find 获取未撤回的卡片消息
+func (t *TalkCardService) find(ctx context.Context, msgId string) (*model.TalkGroupMessage, *model.TalkRecordExtraInteractive, error) {
+	record, err := t.TalkGroupMessage.FindByMsgId(ctx, msgId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, errors.New("卡片消息不存在")
+		}
+
+		return nil, nil, err
+	}
+
+	if record.MsgType != entity.ChatMsgTypeInteractive || record.IsRevoked == model.Yes {
+		return nil, nil, errors.New("卡片消息不存在")
+	}
+
+	var card model.TalkRecordExtraInteractive
+	if err := jsonutil.Unmarshal(record.Extra, &card); err != nil {
+		return nil, nil, err
+	}
+
+	return record, &card, nil
+}
+
+// update 原地更新卡片内容，以卡片版本号作为条件避免并发更新相互覆盖
+// extra 为 JSON 字段，数据库会重新格式化内容，不能直接按原内容比较
+func (t *TalkCardService) update(ctx context.Context, robot *model.GroupRobot, record *model.TalkGroupMessage, current, card *model.TalkRecordExtraInteractive) error {
+	if err := validateTalkCard(card); err != nil {
+		return err
+	}
+
+	card.RobotId, card.RobotName, card.Version = robot.Id, robot.RobotName, current.Version+1
+
+	extra := jsonutil.Encode(card)
+	res := t.Source.Db().WithContext(ctx).Model(&model.TalkGroupMessage{}).
+		Where("msg_id = ? and is_revoked = ?", record.MsgId, model.No).
+		Where("coalesce(json_extract(extra, '$.version'), 0) = ?", current.Version).
+		Update("extra", extra)
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return errors.New("卡片已被更新，请刷新后重试")
+	}
+
+	return t.PushMessage.Push(ctx, entity.ImTopicChat, &entity.SubscribeMessage{
+		Event: entity.SubEventImMessageUpdate,
+		Payload: jsonutil.Encode(entity.SubEventImMessageUpdatePayload{
+			TalkMode: entity.ChatGroupMode,
+			ToFromId: record.GroupId,
+			MsgId:    record.MsgId,
+			Extra:    extra,
+		}),
+	})
+}
+
+func (t *TalkCardService) callback(ctx context.Context, robot *model.GroupRobot, callback *TalkCardCallback) (*TalkCardCallbackResponse, error) {
+	body := []byte(jsonutil.Encode(callback))
+	timestamp := strconv.FormatInt(callback.Timestamp, 10)
+
+	ctx, cancel := context.WithTimeout(ctx, talkCardCallbackTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, robot.CallbackUrl, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("timestamp", timestamp)
+	req.Header.Set("signature", signTalkCardCallback(robot.Secret, timestamp, body))
+
+	resp, err := talkCardClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, talkCardResponseMaxSize))
+	if err != nil {
+		return nil, err
+	}
+
+	result := &TalkCardCallbackResponse{}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return result, nil
+	}
+
+	if err := jsonutil.Unmarshal(data, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// signTalkCardCallback 回调签名，HMAC-SHA256(secret, timestamp + "\n" + body) 后 Base64 编码
+func signTalkCardCallback(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("\n"))
+	mac.Write(body)
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// validateTalkCard 校验卡片内容
+func validateTalkCard(card *model.TalkRecordExtraInteractive) error {
+	if card == nil {
+		return errors.New("卡片内容不能为空")
+	}
+
+	if card.Title == "" || utf8.RuneCountInString(card.Title) > 64 {
+		return errors.New("卡片标题不能为空且不能超过64个字符")
+	}
+
+	if utf8.RuneCountInString(card.Content) > 2000 {
+		return errors.New("卡片正文不能超过2000个字符")
+	}
+
+	if len(card.Fields) > 20 {
+		return errors.New("卡片字段不能超过20个")
+	}
+
+	for _, field := range card.Fields {
+		if field == nil || field.Label == "" || utf8.RuneCountInString(field.Label) > 32 || utf8.RuneCountInString(field.Value) > 512 {
+			return errors.New("卡片字段格式错误")
+		}
+	}
+
+	if len(card.Images) > 9 {
+		return errors.New("卡片图片不能超过9张")
+	}
+
+	for _, image := range card.Images {
+		u, err := url.Parse(image)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || len(image) > 1024 {
+			return errors.New("卡片图片地址错误")
+		}
+	}
+
+	if len(card.Buttons) > 6 {
+		return errors.New("卡片按钮不能超过6个")
+	}
+
+	actions := make(map[string]struct{}, len(card.Buttons))
+	for _, button := range card.Buttons {
+		if button == nil || !talkCardActionRegexp.MatchString(button.Action) {
+			return errors.New("卡片按钮标识格式错误")
+		}
+
+		if _, ok := actions[button.Action]; ok {
+			return errors.New("卡片按钮标识重复")
+		}
+
+		actions[button.Action] = struct{}{}
+
+		if button.Text == "" || utf8.RuneCountInString(button.Text) > 20 || len(button.Value) > 1024 {
+			return errors.New("卡片按钮格式错误")
+		}
+
+		switch button.Style {
+		case "":
+			button.Style = "default"
+		case "default", "primary", "danger":
+		default:
+			return errors.New("卡片按钮样式错误")
+		}
+	}
+
+	if card.Fields == nil {
+		card.Fields = make([]*model.TalkRecordExtraInteractiveField, 0)
+	}
+
+	if card.Images == nil {
+		card.Images = make([]string, 0)
+	}
+
+	if card.Buttons == nil {
+		card.Buttons = make([]*model.TalkRecordExtraInteractiveButton, 0)
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gzydong/go-chat/internal/repository/model"
+)
+
+func TestValidateTalkCard(t *testing.T) {
+	button := func(action, style string) *model.TalkRecordExtraInteractiveButton {
+		return &model.TalkRecordExtraInteractiveButton{Action: action, Text: "确定", Style: style}
+	}
+
+	cases := []struct {
+		name string
+		card *model.TalkRecordExtraInteractive
+		err  bool
+	}{
+		{name: "nil", card: nil, err: true},
+		{name: "minimal", card: &model.TalkRecordExtraInteractive{Title: "审批"}},
+		{name: "empty title", card: &model.TalkRecordExtraInteractive{}, err: true},
+		{name: "long title", card: &model.TalkRecordExtraInteractive{Title: strings.Repeat("标", 65)}, err: true},
+		{name: "long content", card: &model.TalkRecordExtraInteractive{Title: "审批", Content: strings.Repeat("a", 2001)}, err: true},
+		{name: "field without label", card: &model.TalkRecordExtraInteractive{Title: "审批", Fields: []*model.TalkRecordExtraInteractiveField{{Value: "1"}}}, err: true},
+		{name: "image scheme", card: &model.TalkRecordExtraInteractive{Title: "审批", Images: []string{"javascript:alert(1)"}}, err: true},
+		{name: "image ok", card: &model.TalkRecordExtraInteractive{Title: "审批", Images: []string{"https://example.com/a.png"}}},
+		{name: "buttons", card: &model.TalkRecordExtraInteractive{Title: "审批", Buttons: []*model.TalkRecordExtraInteractiveButton{button("approve", "primary"), button("reject", "danger"), button("later", "")}}},
+		{name: "duplicate action", card: &model.TalkRecordExtraInteractive{Title: "审批", Buttons: []*model.TalkRecordExtraInteractiveButton{button("ok", ""), button("ok", "")}}, err: true},
+		{name: "bad action", card: &model.TalkRecordExtraInteractive{Title: "审批", Buttons: []*model.TalkRecordExtraInteractiveButton{button("a b", "")}}, err: true},
+		{name: "bad style", card: &model.TalkRecordExtraInteractive{Title: "审批", Buttons: []*model.TalkRecordExtraInteractiveButton{button("ok", "blink")}}, err: true},
+		{name: "too many buttons", card: &model.TalkRecordExtraInteractive{Title: "审批", Buttons: []*model.TalkRecordExtraInteractiveButton{
+			button("a", ""), button("b", ""), button("c", ""), button("d", ""), button("e", ""), button("f", ""), button("g", ""),
+		}}, err: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := validateTalkCard(c.card)
+			if c.err {
+				if err == nil {
+					t.Errorf("validateTalkCard expect error")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("validateTalkCard err = %v", err)
+			}
+
+			if c.card.Fields == nil || c.card.Images == nil || c.card.Buttons == nil {
+				t.Errorf("validateTalkCard should normalize nil slices")
+			}
+
+			for _, item := range c.card.Buttons {
+				if item.Style == "" {
+					t.Errorf("validateTalkCard should fill default button style")
+				}
+			}
+		})
+	}
+}
+
+func TestSignTalkCardCallback(t *testing.T) {
+	body := []byte(`{"event":"card.action"}`)
+
+	sign := signTalkCardCallback("secret", "1700000000", body)
+	if sign != signTalkCardCallback("secret", "1700000000", body) {
+		t.Errorf("signTalkCardCallback should be deterministic")
+	}
+
+	if sign == signTalkCardCallback("other", "1700000000", body) {
+		t.Errorf("signTalkCardCallback should depend on secret")
+	}
+
+	if sign == signTalkCardCallback("secret", "1700000001", body) {
+		t.Errorf("signTalkCardCallback should depend on timestamp")
+	}
+
+	if sign == signTalkCardCallback("secret", "1700000000", []byte(`{}`)) {
+		t.Errorf("signTalkCardCallback should depend on body")
+	}
+}
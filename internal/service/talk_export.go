@@ -0,0 +1,617 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/gzydong/go-chat/internal/entity"
+	"github.com/gzydong/go-chat/internal/logic"
+	"github.com/gzydong/go-chat/internal/pkg/filesystem"
+	"github.com/gzydong/go-chat/internal/pkg/jsonutil"
+	"github.com/gzydong/go-chat/internal/pkg/logger"
+	"github.com/gzydong/go-chat/internal/pkg/strutil"
+	"github.com/gzydong/go-chat/internal/repository/model"
+	"github.com/gzydong/go-chat/internal/repository/repo"
+	"github.com/gzydong/go-chat/internal/service/message"
+	"github.com/nsqio/go-nsq"
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+)
+
+const (
+	talkExportMaxDays      = 366                // 单次导出的最大时间范围
+	talkExportMaxRecords   = 200000             // 单次导出的最大消息数，超出部分不再导出
+	talkExportMaxFileSize  = 50 << 20           // 单个附件超过该大小时不打包
+	talkExportMaxFilesSize = 500 << 20          // 打包附件的总大小
+	talkExportBatchSize    = 500                // 每次读取的消息数
+	talkExportExpire       = 7 * 24 * time.Hour // 导出文件保留时长
+	talkExportUrlExpire    = 24 * time.Hour     // 下载链接有效期
+	talkExportPartSize     = 8 << 20            // 上传压缩包的分片大小
+)
+
+var _ ITalkExportService = (*TalkExportService)(nil)
+
+type TalkExportCreateOpt struct {
+	RequesterType int
+	RequesterId   int
+	UserId        int // 导出的用户
+	TalkMode      int // 为 0 时导出全部类型的会话
+	ToFromId      int // 为 0 时导出全部会话
+	StartTime     time.Time
+	EndTime       time.Time
+}
+
+type ITalkExportService interface {
+	// Create 创建导出任务并投递到队列
+	Create(ctx context.Context, opt *TalkExportCreateOpt) (*model.TalkExport, error)
+	// Run 执行导出任务，生成压缩包写入私有桶并通知申请人
+	Run(ctx context.Context, exportId int) error
+	// DownloadUrl 获取导出文件的下载地址
+	DownloadUrl(export *model.TalkExport) (string, error)
+}
+
+type TalkExportService struct {
+	*repo.Source
+	TalkExportRepo     *repo.TalkExport
+	UsersRepo          *repo.Users
+	GroupRepo          *repo.Group
+	GroupMemberRepo    *repo.GroupMember
+	TalkMessageArchive *repo.TalkMessageArchive
+	Filesystem         filesystem.IFilesystem
+	Producer           *nsq.Producer
+	PushMessage        *logic.PushMessage
+}
+
+func (t *TalkExportService) Create(ctx context.Context, opt *TalkExportCreateOpt) (*model.TalkExport, error) {
+	if !opt.EndTime.After(opt.StartTime) {
+		return nil, errors.New("结束时间必须晚于开始时间")
+	}
+
+	if opt.EndTime.Sub(opt.StartTime) > talkExportMaxDays*24*time.Hour {
+		return nil, fmt.Errorf("导出时间范围不能超过%d天", talkExportMaxDays)
+	}
+
+	if opt.ToFromId > 0 && opt.TalkMode != entity.ChatPrivateMode && opt.TalkMode != entity.ChatGroupMode {
+		return nil, errors.New("请选择会话类型")
+	}
+
+	if _, err := t.UsersRepo.FindById(ctx, opt.UserId); err != nil {
+		return nil, errors.New("用户不存在")
+	}
+
+	// 用户只能导出自己所在群的聊天记录，管理员不受限制
+	if opt.RequesterType == model.TalkExportRequesterUser && opt.TalkMode == entity.ChatGroupMode && opt.ToFromId > 0 {
+		if !t.GroupMemberRepo.IsMember(ctx, opt.ToFromId, opt.UserId, false) {
+			return nil, entity.ErrPermissionDenied
+		}
+	}
+
+	exist, err := t.TalkExportRepo.IsExist(ctx, "requester_type = ? and requester_id = ? and status in ?",
+		opt.RequesterType, opt.RequesterId, []int{model.TalkExportStatusPending, model.TalkExportStatusRunning})
+	if err != nil {
+		return nil, err
+	}
+
+	if exist {
+		return nil, errors.New("已有导出任务正在进行，请稍后再试")
+	}
+
+	export := &model.TalkExport{
+		RequesterType: opt.RequesterType,
+		RequesterId:   opt.RequesterId,
+		UserId:        opt.UserId,
+		TalkMode:      opt.TalkMode,
+		ToFromId:      opt.ToFromId,
+		StartTime:     opt.StartTime,
+		EndTime:       opt.EndTime,
+		Status:        model.TalkExportStatusPending,
+	}
+
+	if err := t.TalkExportRepo.Create(ctx, export); err != nil {
+		return nil, err
+	}
+
+	if err := t.Producer.Publish(entity.TalkExportTopic, []byte(jsonutil.Encode(entity.TalkExportJobMessage{ExportId: export.Id}))); err != nil {
+		logger.Errorf("talk export %d publish err: %s", export.Id, err.Error())
+		t.fail(ctx, export, "任务投递失败")
+		return nil, errors.New("导出任务投递失败，请稍后再试")
+	}
+
+	return export, nil
+}
+
+func (t *TalkExportService) Run(ctx context.Context, exportId int) error {
+	export, err := t.TalkExportRepo.FindById(ctx, exportId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+
+		return err
+	}
+
+	// 导出中的任务为上次中断时遗留，重新导出
+	if export.Status != model.TalkExportStatusPending && export.Status != model.TalkExportStatusRunning {
+		return nil
+	}
+
+	_, err = t.TalkExportRepo.UpdateById(ctx, export.Id, map[string]any{"status": model.TalkExportStatusRunning})
+	if err != nil {
+		return err
+	}
+
+	filePath, size, count, err := t.build(ctx, export)
+	if err != nil {
+		logger.Errorf("talk export %d build err: %s", export.Id, err.Error())
+		t.fail(ctx, export, "导出失败")
+		return nil
+	}
+
+	export.Status = model.TalkExportStatusCompleted
+	export.FilePath = filePath
+	export.FileSize = size
+	export.RecordCount = count
+	export.ExpiredAt = sql.NullTime{Time: time.Now().Add(talkExportExpire), Valid: true}
+
+	_, err = t.TalkExportRepo.UpdateById(ctx, export.Id, map[string]any{
+		"status":       export.Status,
+		"file_path":    export.FilePath,
+		"file_size":    export.FileSize,
+		"record_count": export.RecordCount,
+		"expired_at":   export.ExpiredAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	t.notify(ctx, export)
+	return nil
+}
+
+func (t *TalkExportService) DownloadUrl(export *model.TalkExport) (string, error) {
+	if export.Status != model.TalkExportStatusCompleted {
+		return "", errors.New("导出文件尚未生成")
+	}
+
+	if export.FilePath == "" || (export.ExpiredAt.Valid && export.ExpiredAt.Time.Before(time.Now())) {
+		return "", errors.New("导出文件已过期")
+	}
+
+	name := fmt.Sprintf("聊天记录_%s.zip", export.CreatedAt.Format("20060102150405"))
+	return t.Filesystem.PrivateUrl(t.Filesystem.BucketPrivateName(), export.FilePath, name, talkExportUrlExpire), nil
+}
+
+// build 生成压缩包并写入私有桶，返回文件路径、文件大小及导出的消息数
+func (t *TalkExportService) build(ctx context.Context, export *model.TalkExport) (string, int64, int, error) {
+	tmp, err := os.CreateTemp("", "talk-export-*.zip")
+	if err != nil {
+		return "", 0, 0, err
+	}
+
+	defer func() { _ = removeTempFile(tmp) }()
+
+	conversations, err := t.conversations(ctx, export)
+	if err != nil {
+		return "", 0, 0, err
+	}
+
+	exporter := &talkExporter{
+		service:   t,
+		export:    export,
+		archive:   newTalkExportArchive(tmp),
+		nicknames: make(map[int]string),
+	}
+
+	for _, item := range conversations {
+		if exporter.count >= talkExportMaxRecords {
+			exporter.truncated = true
+			break
+		}
+
+		if err := exporter.conversation(ctx, item[0], item[1]); err != nil {
+			return "", 0, 0, err
+		}
+	}
+
+	err = exporter.archive.Close(map[string]any{
+		"id":           export.Id,
+		"user_id":      export.UserId,
+		"start_time":   export.StartTime.Format(time.DateTime),
+		"end_time":     export.EndTime.Format(time.DateTime),
+		"exported_at":  time.Now().Format(time.DateTime),
+		"record_count": exporter.count,
+		"truncated":    exporter.truncated,
+	})
+	if err != nil {
+		return "", 0, 0, err
+	}
+
+	filePath := fmt.Sprintf("talk-export/%s/%s", time.Now().Format("200601"), strutil.GenFileName("zip"))
+
+	size, err := t.upload(filePath, tmp)
+	if err != nil {
+		return "", 0, 0, err
+	}
+
+	return filePath, size, exporter.count, nil
+}
+
+// upload 按分片读取临时文件并上传到私有桶，避免将整个压缩包读入内存
+func (t *TalkExportService) upload(filePath string, file *os.File) (int64, error) {
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+
+	bucket := t.Filesystem.BucketPrivateName()
+
+	uploadId, err := t.Filesystem.InitiateMultipartUpload(bucket, filePath)
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		size  int64
+		parts = make([]filesystem.ObjectPart, 0)
+		buf   = make([]byte, talkExportPartSize)
+	)
+
+	for index := 1; ; index++ {
+		n, err := io.ReadFull(file, buf)
+		if n > 0 {
+			part, err := t.Filesystem.PutObjectPart(bucket, filePath, uploadId, index, bytes.NewReader(buf[:n]), int64(n))
+			if err != nil {
+				_ = t.Filesystem.AbortMultipartUpload(bucket, filePath, uploadId)
+				return 0, err
+			}
+
+			parts = append(parts, part)
+			size += int64(n)
+		}
+
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+
+		if err != nil {
+			_ = t.Filesystem.AbortMultipartUpload(bucket, filePath, uploadId)
+			return 0, err
+		}
+	}
+
+	if err := t.Filesystem.CompleteMultipartUpload(bucket, filePath, uploadId, parts); err != nil {
+		return 0, err
+	}
+
+	return size, nil
+}
+
+// conversations 获取需要导出的会话，返回 [对话类型, 好友ID或群ID]
+func (t *TalkExportService) conversations(ctx context.Context, export *model.TalkExport) ([][2]int, error) {
+	if export.ToFromId > 0 {
+		return [][2]int{{export.TalkMode, export.ToFromId}}, nil
+	}
+
+	items := make([][2]int, 0)
+	db := t.Source.Db().WithContext(ctx)
+
+	if export.TalkMode == 0 || export.TalkMode == entity.ChatPrivateMode {
+		tables, err := t.tables(ctx, entity.ChatPrivateMode)
+		if err != nil {
+			return nil, err
+		}
+
+		ids := make([]int, 0)
+		for _, table := range tables {
+			var list []int
+			err := db.Table(table).Distinct("to_from_id").
+				Where("user_id = ? and is_deleted = ? and send_time between ? and ?", export.UserId, model.No, export.StartTime, export.EndTime).
+				Pluck("to_from_id", &list).Error
+			if err != nil {
+				return nil, err
+			}
+
+			ids = append(ids, list...)
+		}
+
+		for _, id := range lo.Uniq(ids) {
+			items = append(items, [2]int{entity.ChatPrivateMode, id})
+		}
+	}
+
+	if export.TalkMode == 0 || export.TalkMode == entity.ChatGroupMode {
+		groupIds := t.GroupMemberRepo.GetUserGroupIds(ctx, export.UserId)
+		if len(groupIds) > 0 {
+			tables, err := t.tables(ctx, entity.ChatGroupMode)
+			if err != nil {
+				return nil, err
+			}
+
+			ids := make([]int, 0)
+			for _, table := range tables {
+				var list []int
+				err := db.Table(table).Distinct("group_id").
+					Where("group_id in ? and send_time between ? and ?", groupIds, export.StartTime, export.EndTime).
+					Pluck("group_id", &list).Error
+				if err != nil {
+					return nil, err
+				}
+
+				ids = append(ids, list...)
+			}
+
+			for _, id := range lo.Uniq(ids) {
+				items = append(items, [2]int{entity.ChatGroupMode, id})
+			}
+		}
+	}
+
+	return items, nil
+}
+
+// tables 获取对话类型的消息表，归档表按月份正序排在当前消息表之前
+func (t *TalkExportService) tables(ctx context.Context, talkMode int) ([]string, error) {
+	archives, err := t.TalkMessageArchive.FindTables(ctx, talkMode)
+	if err != nil {
+		return nil, err
+	}
+
+	slices.Reverse(archives)
+
+	if talkMode == entity.ChatGroupMode {
+		return append(archives, model.TalkGroupMessage{}.TableName()), nil
+	}
+
+	return append(archives, model.TalkUserMessage{}.TableName()), nil
+}
+
+func (t *TalkExportService) fail(ctx context.Context, export *model.TalkExport, reason string) {
+	export.Status = model.TalkExportStatusFailed
+	export.Error = reason
+
+	_, err := t.TalkExportRepo.UpdateById(ctx, export.Id, map[string]any{
+		"status": export.Status,
+		"error":  export.Error,
+	})
+	if err != nil {
+		logger.Errorf("talk export %d update err: %s", export.Id, err.Error())
+	}
+
+	t.notify(ctx, export)
+}
+
+// notify 通知用户导出结果，管理员通过任务详情查看
+func (t *TalkExportService) notify(ctx context.Context, export *model.TalkExport) {
+	if export.RequesterType != model.TalkExportRequesterUser {
+		return
+	}
+
+	payload := entity.SubEventTalkExportPayload{
+		UserId:   export.RequesterId,
+		ExportId: export.Id,
+		Status:   export.Status,
+	}
+
+	if export.Status == model.TalkExportStatusCompleted {
+		payload.Url, _ = t.DownloadUrl(export)
+	}
+
+	err := t.PushMessage.Push(ctx, entity.ImTopicChat, &entity.SubscribeMessage{
+		Event:   entity.SubEventTalkExport,
+		Payload: jsonutil.Encode(payload),
+	})
+	if err != nil {
+		logger.Errorf("talk export %d notify err: %s", export.Id, err.Error())
+	}
+}
+
+// talkExporter 单个导出任务的执行状态
+type talkExporter struct {
+	service   *TalkExportService
+	export    *model.TalkExport
+	archive   *talkExportArchive
+	nicknames map[int]string
+	count     int   // 已导出的消息数
+	filesSize int64 // 已打包的附件大小
+	truncated bool  // 是否因超出上限而截断
+}
+
+func (e *talkExporter) conversation(ctx context.Context, talkMode int, toFromId int) error {
+	name, err := e.conversationName(ctx, talkMode, toFromId)
+	if err != nil {
+		return err
+	}
+
+	section, err := e.archive.Begin(talkMode, toFromId, name)
+	if err != nil {
+		return err
+	}
+
+	defer section.Discard()
+
+	tables, err := e.service.tables(ctx, talkMode)
+	if err != nil {
+		return err
+	}
+
+	// 依次导出归档表及当前消息表，每张表内按ID分页
+	for _, table := range tables {
+		var lastId int64
+		for e.count < talkExportMaxRecords {
+			records, err := e.records(ctx, table, talkMode, toFromId, lastId)
+			if err != nil {
+				return err
+			}
+
+			if len(records) == 0 {
+				break
+			}
+
+			if err := e.loadNicknames(ctx, records); err != nil {
+				return err
+			}
+
+			for _, record := range records {
+				if e.count >= talkExportMaxRecords {
+					e.truncated = true
+					break
+				}
+
+				if err := section.Write(e.record(section, record)); err != nil {
+					return err
+				}
+
+				e.count++
+				lastId = record.Id
+			}
+
+			if len(records) < talkExportBatchSize {
+				break
+			}
+		}
+	}
+
+	return section.Close(func(filePath string) ([]byte, error) {
+		return e.service.Filesystem.GetObject(e.service.Filesystem.BucketPrivateName(), filePath)
+	})
+}
+
+// talkExportMessage 私聊及群聊消息的公共字段
+type talkExportMessage struct {
+	Id        int64
+	MsgId     string
+	MsgType   int
+	FromId    int
+	IsRevoked int
+	Extra     string
+	RootMsgId string
+	SendTime  time.Time
+}
+
+func (e *talkExporter) records(ctx context.Context, table string, talkMode int, toFromId int, lastId int64) ([]*talkExportMessage, error) {
+	var (
+		items = make([]*talkExportMessage, 0)
+		db    = e.service.Source.Db().WithContext(ctx)
+	)
+
+	if talkMode == entity.ChatPrivateMode {
+		err := db.Table(table).
+			Select("id, msg_id, msg_type, from_id, is_revoked, extra, send_time").
+			Where("user_id = ? and to_from_id = ? and is_deleted = ?", e.export.UserId, toFromId, model.No).
+			Where("send_time between ? and ? and id > ?", e.export.StartTime, e.export.EndTime, lastId).
+			Order("id asc").Limit(talkExportBatchSize).Scan(&items).Error
+		return items, err
+	}
+
+	// 用户删除的群消息不导出
+	err := db.Table(table).
+		Select("id, msg_id, msg_type, from_id, is_revoked, extra, root_msg_id, send_time").
+		Where("group_id = ? and send_time between ? and ? and id > ?", toFromId, e.export.StartTime, e.export.EndTime, lastId).
+		Where("msg_id not in (?)", db.Model(&model.TalkGroupMessageDel{}).Select("msg_id").Where("group_id = ? and user_id = ?", toFromId, e.export.UserId)).
+		Order("id asc").Limit(talkExportBatchSize).Scan(&items).Error
+	return items, err
+}
+
+func (e *talkExporter) record(section *talkExportSection, item *talkExportMessage) *talkExportRecord {
+	record := &talkExportRecord{
+		MsgId:     item.MsgId,
+		MsgType:   item.MsgType,
+		FromId:    item.FromId,
+		Nickname:  e.nicknames[item.FromId],
+		IsRevoked: item.IsRevoked == model.Yes,
+		RootMsgId: item.RootMsgId,
+		SendTime:  item.SendTime.Format(time.DateTime),
+	}
+
+	if item.FromId == 0 {
+		record.Nickname = "系统"
+	}
+
+	// 用户导出时不包含已撤回消息的内容，管理员合规导出保留原始内容
+	if record.IsRevoked && e.export.RequesterType == model.TalkExportRequesterUser {
+		record.Content = "此消息已撤回"
+		return record
+	}
+
+	record.Extra = []byte(item.Extra)
+	record.Content = talkExportText(item.MsgType, item.Extra)
+
+	if item.MsgType == entity.ChatMsgTypeFile {
+		var file model.TalkRecordExtraFile
+		if err := jsonutil.Unmarshal(item.Extra, &file); err == nil && file.Path != "" {
+			size := int64(file.Size)
+			if size <= talkExportMaxFileSize && e.filesSize+size <= talkExportMaxFilesSize {
+				e.filesSize += size
+				record.File = section.AddFile(item.MsgId, file.Name, file.Path)
+			}
+		}
+	}
+
+	return record
+}
+
+func (e *talkExporter) loadNicknames(ctx context.Context, items []*talkExportMessage) error {
+	ids := make([]any, 0)
+	for _, item := range items {
+		if _, ok := e.nicknames[item.FromId]; !ok && item.FromId > 0 {
+			e.nicknames[item.FromId] = ""
+			ids = append(ids, item.FromId)
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	users, err := e.service.UsersRepo.FindByIds(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	for _, user := range users {
+		e.nicknames[user.Id] = user.Nickname
+	}
+
+	return nil
+}
+
+func (e *talkExporter) conversationName(ctx context.Context, talkMode int, toFromId int) (string, error) {
+	if talkMode == entity.ChatGroupMode {
+		group, err := e.service.GroupRepo.FindById(ctx, toFromId)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", err
+		}
+
+		if group != nil {
+			return group.Name, nil
+		}
+
+		return fmt.Sprintf("群聊%d", toFromId), nil
+	}
+
+	user, err := e.service.UsersRepo.FindById(ctx, toFromId)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", err
+	}
+
+	if user != nil {
+		return user.Nickname, nil
+	}
+
+	return fmt.Sprintf("用户%d", toFromId), nil
+}
+
+// talkExportText 获取消息的可读文本，无文字内容的消息使用类型描述
+func talkExportText(msgType int, extra string) string {
+	if value := message.SearchText(msgType, extra); value != "" {
+		return value
+	}
+
+	return lo.Ternary(entity.ChatMsgTypeMapping[msgType] != "", entity.ChatMsgTypeMapping[msgType], "未知消息")
+}
@@ -0,0 +1,306 @@
+package service
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+)
+
+const talkExportHtmlStyle = `<style>
+body{font-family:-apple-system,"PingFang SC","Microsoft YaHei",sans-serif;max-width:860px;margin:24px auto;color:#333}
+.msg{padding:8px 0;border-bottom:1px solid #eee}
+.meta{font-size:12px;color:#999}
+.content{white-space:pre-wrap;word-break:break-all;margin-top:4px}
+.revoked{color:#bbb;font-style:italic}
+</style>`
+
+// talkExportRecord 导出的单条消息
+type talkExportRecord struct {
+	MsgId     string          `json:"msg_id"`
+	MsgType   int             `json:"msg_type"`
+	FromId    int             `json:"from_id"`
+	Nickname  string          `json:"nickname"`
+	Content   string          `json:"content"` // 可读文本
+	Extra     json.RawMessage `json:"extra"`   // 原始消息内容
+	IsRevoked bool            `json:"is_revoked"`
+	RootMsgId string          `json:"root_msg_id,omitempty"`
+	File      string          `json:"file,omitempty"` // 打包的附件在压缩包中的路径
+	SendTime  string          `json:"send_time"`
+}
+
+// talkExportConversation 导出的会话
+type talkExportConversation struct {
+	Dir      string `json:"dir"`
+	TalkMode int    `json:"talk_mode"`
+	ToFromId int    `json:"to_from_id"`
+	Name     string `json:"name"`
+	Count    int    `json:"count"`
+}
+
+// talkExportFile 待打包的附件
+type talkExportFile struct {
+	Name string // 压缩包中的路径
+	Path string // 私有桶中的路径
+}
+
+// talkExportArchive 聊天记录压缩包，每个会话一个目录，包含 messages.json、messages.csv、transcript.html 及附件
+type talkExportArchive struct {
+	zw            *zip.Writer
+	conversations []*talkExportConversation
+	missingFiles  []string
+}
+
+func newTalkExportArchive(w io.Writer) *talkExportArchive {
+	return &talkExportArchive{
+		zw:            zip.NewWriter(w),
+		conversations: make([]*talkExportConversation, 0),
+		missingFiles:  make([]string, 0),
+	}
+}
+
+// Begin 开始导出一个会话，同一时间只能有一个会话在导出
+func (a *talkExportArchive) Begin(talkMode int, toFromId int, name string) (*talkExportSection, error) {
+	conv := &talkExportConversation{
+		Dir:      fmt.Sprintf("conversations/%d_%d", talkMode, toFromId),
+		TalkMode: talkMode,
+		ToFromId: toFromId,
+		Name:     name,
+	}
+
+	w, err := a.zw.Create(conv.Dir + "/messages.json")
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := io.WriteString(w, "["); err != nil {
+		return nil, err
+	}
+
+	htmlFile, err := os.CreateTemp("", "talk-export-*.html")
+	if err != nil {
+		return nil, err
+	}
+
+	csvFile, err := os.CreateTemp("", "talk-export-*.csv")
+	if err != nil {
+		_ = removeTempFile(htmlFile)
+		return nil, err
+	}
+
+	section := &talkExportSection{
+		archive:  a,
+		conv:     conv,
+		json:     w,
+		htmlFile: htmlFile,
+		csvFile:  csvFile,
+		csv:      csv.NewWriter(csvFile),
+		files:    make([]*talkExportFile, 0),
+	}
+
+	title := html.EscapeString(name)
+	_, _ = fmt.Fprintf(htmlFile, "<!DOCTYPE html><html><head><meta charset=\"utf-8\"><title>%s</title>%s</head><body><h2>%s</h2>\n", title, talkExportHtmlStyle, title)
+
+	// 写入 BOM 以便 Excel 正确识别编码
+	_, _ = csvFile.WriteString("\xEF\xBB\xBF")
+	_ = section.csv.Write([]string{"msg_id", "send_time", "from_id", "nickname", "msg_type", "content", "is_revoked", "file"})
+
+	a.conversations = append(a.conversations, conv)
+	return section, nil
+}
+
+// Close 写入会话列表及导出说明并结束压缩包
+func (a *talkExportArchive) Close(manifest map[string]any) error {
+	w, err := a.zw.Create("index.html")
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(w, "<!DOCTYPE html><html><head><meta charset=\"utf-8\"><title>聊天记录</title>%s</head><body><h2>聊天记录</h2><ul>\n", talkExportHtmlStyle)
+	for _, conv := range a.conversations {
+		_, _ = fmt.Fprintf(w, "<li><a href=\"%s/transcript.html\">%s</a>（%d条）</li>\n", conv.Dir, html.EscapeString(conv.Name), conv.Count)
+	}
+	_, _ = io.WriteString(w, "</ul></body></html>\n")
+
+	manifest["conversations"] = a.conversations
+	manifest["missing_files"] = a.missingFiles
+
+	w, err = a.zw.Create("manifest.json")
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(manifest); err != nil {
+		return err
+	}
+
+	return a.zw.Close()
+}
+
+// talkExportSection 单个会话的导出，messages.json 直接写入压缩包，HTML 及 CSV 先写入临时文件
+type talkExportSection struct {
+	archive  *talkExportArchive
+	conv     *talkExportConversation
+	json     io.Writer
+	htmlFile *os.File
+	csvFile  *os.File
+	csv      *csv.Writer
+	files    []*talkExportFile
+}
+
+// AddFile 登记需要打包的附件，返回附件在压缩包中的路径
+func (s *talkExportSection) AddFile(msgId string, name string, filePath string) string {
+	file := &talkExportFile{
+		Name: fmt.Sprintf("%s/files/%s_%s", s.conv.Dir, msgId, talkExportFileName(name)),
+		Path: filePath,
+	}
+
+	s.files = append(s.files, file)
+	return file.Name
+}
+
+func (s *talkExportSection) Write(record *talkExportRecord) error {
+	if len(record.Extra) == 0 || !json.Valid(record.Extra) {
+		record.Extra = json.RawMessage("{}")
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	if s.conv.Count > 0 {
+		if _, err := io.WriteString(s.json, ",\n"); err != nil {
+			return err
+		}
+	}
+
+	if _, err := s.json.Write(data); err != nil {
+		return err
+	}
+
+	s.conv.Count++
+
+	content := html.EscapeString(record.Content)
+	if record.IsRevoked {
+		content = "<span class=\"revoked\">" + content + "（已撤回）</span>"
+	}
+
+	if record.File != "" {
+		content += fmt.Sprintf("<br><a href=\"%s\">下载附件</a>", html.EscapeString(strings.TrimPrefix(record.File, s.conv.Dir+"/")))
+	}
+
+	_, err = fmt.Fprintf(s.htmlFile, "<div class=\"msg\"><div class=\"meta\">%s · %s</div><div class=\"content\">%s</div></div>\n",
+		html.EscapeString(record.Nickname), record.SendTime, content)
+	if err != nil {
+		return err
+	}
+
+	return s.csv.Write([]string{
+		record.MsgId,
+		record.SendTime,
+		strconv.Itoa(record.FromId),
+		record.Nickname,
+		strconv.Itoa(record.MsgType),
+		record.Content,
+		strconv.FormatBool(record.IsRevoked),
+		record.File,
+	})
+}
+
+// Close 结束会话导出，readFile 用于读取附件内容，读取失败的附件记录到导出说明中
+func (s *talkExportSection) Close(readFile func(filePath string) ([]byte, error)) error {
+	defer s.Discard()
+
+	if _, err := io.WriteString(s.json, "]\n"); err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(s.htmlFile, "</body></html>\n"); err != nil {
+		return err
+	}
+
+	s.csv.Flush()
+	if err := s.csv.Error(); err != nil {
+		return err
+	}
+
+	if err := s.copy(s.conv.Dir+"/transcript.html", s.htmlFile); err != nil {
+		return err
+	}
+
+	if err := s.copy(s.conv.Dir+"/messages.csv", s.csvFile); err != nil {
+		return err
+	}
+
+	for _, file := range s.files {
+		data, err := readFile(file.Path)
+		if err != nil {
+			s.archive.missingFiles = append(s.archive.missingFiles, file.Name)
+			continue
+		}
+
+		w, err := s.archive.zw.Create(file.Name)
+		if err != nil {
+			return err
+		}
+
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Discard 删除临时文件，导出中途失败时调用
+func (s *talkExportSection) Discard() {
+	_ = removeTempFile(s.htmlFile)
+	_ = removeTempFile(s.csvFile)
+}
+
+func (s *talkExportSection) copy(name string, file *os.File) error {
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	w, err := s.archive.zw.Create(name)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(w, file)
+	return err
+}
+
+// talkExportFileName 去除文件名中的路径分隔符等字符，避免解压时写到目录之外
+func talkExportFileName(name string) string {
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || strings.ContainsRune(`/\:*?"<>|`, r) {
+			return '_'
+		}
+
+		return r
+	}, name)
+
+	if name == "" || name == "." || name == ".." {
+		return "file"
+	}
+
+	return name
+}
+
+func removeTempFile(file *os.File) error {
+	_ = file.Close()
+	return os.Remove(file.Name())
+}
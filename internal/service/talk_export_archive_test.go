@@ -0,0 +1,147 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestTalkExportArchive(t *testing.T) {
+	buf := &bytes.Buffer{}
+	archive := newTalkExportArchive(buf)
+
+	section, err := archive.Begin(1, 2, "<Alice>")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file := section.AddFile("m2", "../../report.pdf", "private/report.pdf")
+	missing := section.AddFile("m3", "lost.txt", "private/lost.txt")
+
+	records := []*talkExportRecord{
+		{MsgId: "m1", MsgType: 1, FromId: 1, Nickname: "Bob", Content: "<b>hi</b>", Extra: json.RawMessage(`{"content":"<b>hi</b>"}`), SendTime: "2024-01-01 00:00:00"},
+		{MsgId: "m2", MsgType: 6, FromId: 2, Nickname: "Alice", Content: "report.pdf", File: file, SendTime: "2024-01-01 00:01:00"},
+		{MsgId: "m3", MsgType: 6, FromId: 2, Nickname: "Alice", Content: "lost.txt", File: missing, Extra: json.RawMessage("invalid"), SendTime: "2024-01-01 00:02:00"},
+	}
+
+	for _, record := range records {
+		if err := section.Write(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err = section.Close(func(filePath string) ([]byte, error) {
+		if filePath == "private/report.pdf" {
+			return []byte("pdf"), nil
+		}
+
+		return nil, errors.New("not found")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := archive.Close(map[string]any{"id": 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := make(map[string]string)
+	for _, f := range reader.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		data, _ := io.ReadAll(rc)
+		_ = rc.Close()
+		files[f.Name] = string(data)
+	}
+
+	var messages []*talkExportRecord
+	if err := json.Unmarshal([]byte(files["conversations/1_2/messages.json"]), &messages); err != nil {
+		t.Fatalf("messages.json err = %v", err)
+	}
+
+	if len(messages) != 3 || messages[1].File != "conversations/1_2/files/m2_report.pdf" {
+		t.Errorf("messages.json = %+v", messages)
+	}
+
+	if files["conversations/1_2/files/m2_report.pdf"] != "pdf" {
+		t.Errorf("bundled file missing")
+	}
+
+	transcript := files["conversations/1_2/transcript.html"]
+	if strings.Contains(transcript, "<b>hi</b>") || !strings.Contains(transcript, "&lt;b&gt;hi&lt;/b&gt;") || !strings.Contains(transcript, "&lt;Alice&gt;") {
+		t.Errorf("transcript.html should escape content: %s", transcript)
+	}
+
+	if !strings.Contains(files["conversations/1_2/messages.csv"], "m2,2024-01-01 00:01:00,2,Alice,6,report.pdf") {
+		t.Errorf("messages.csv = %s", files["conversations/1_2/messages.csv"])
+	}
+
+	var manifest struct {
+		Id            int                       `json:"id"`
+		Conversations []*talkExportConversation `json:"conversations"`
+		MissingFiles  []string                  `json:"missing_files"`
+	}
+
+	if err := json.Unmarshal([]byte(files["manifest.json"]), &manifest); err != nil {
+		t.Fatal(err)
+	}
+
+	if manifest.Id != 1 || len(manifest.Conversations) != 1 || manifest.Conversations[0].Count != 3 {
+		t.Errorf("manifest = %+v", manifest)
+	}
+
+	if len(manifest.MissingFiles) != 1 || manifest.MissingFiles[0] != missing {
+		t.Errorf("manifest missing files = %v", manifest.MissingFiles)
+	}
+
+	if _, ok := files["index.html"]; !ok {
+		t.Errorf("index.html missing")
+	}
+}
+
+func TestTalkExportFileName(t *testing.T) {
+	cases := map[string]string{
+		"report.pdf":        "report.pdf",
+		"../../etc/passwd":  "passwd",
+		`..\..\windows.ini`: "windows.ini",
+		"a:b*c?.txt":        "a_b_c_.txt",
+		"..":                "file",
+		"":                  "file",
+	}
+
+	for name, expect := range cases {
+		if got := talkExportFileName(name); got != expect {
+			t.Errorf("talkExportFileName(%q) = %q, expect %q", name, got, expect)
+		}
+	}
+}
+
+// 文本入库时已转义，导出内容需还原为原文，写入 transcript.html 时只转义一次
+func TestTalkExportText(t *testing.T) {
+	cases := []struct {
+		msgType int
+		extra   string
+		expect  string
+	}{
+		{msgType: 1, extra: `{"content":"&lt;b&gt;hi&lt;/b&gt; &amp; bye"}`, expect: "<b>hi</b> & bye"},
+		{msgType: 3, extra: `{"url":"a.png"}`, expect: "[图片消息]"},
+	}
+
+	for _, c := range cases {
+		if got := talkExportText(c.msgType, c.extra); got != c.expect {
+			t.Errorf("talkExportText(%d, %s) = %q, expect %q", c.msgType, c.extra, got, c.expect)
+		}
+	}
+}
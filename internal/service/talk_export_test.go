@@ -0,0 +1,82 @@
+package service
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/gzydong/go-chat/internal/pkg/filesystem"
+)
+
+// partFilesystem 记录分片上传的内容，未实现的方法调用时会 panic
+type partFilesystem struct {
+	filesystem.IFilesystem
+	parts    map[int][]byte
+	complete []filesystem.ObjectPart
+}
+
+func (p *partFilesystem) BucketPrivateName() string { return "private" }
+
+func (p *partFilesystem) InitiateMultipartUpload(_, _ string) (string, error) {
+	p.parts = make(map[int][]byte)
+	return "upload", nil
+}
+
+func (p *partFilesystem) PutObjectPart(_, _ string, _ string, index int, data io.Reader, size int64) (filesystem.ObjectPart, error) {
+	stream, err := io.ReadAll(data)
+	if err != nil {
+		return filesystem.ObjectPart{}, err
+	}
+
+	if int64(len(stream)) != size || size > talkExportPartSize {
+		return filesystem.ObjectPart{}, io.ErrShortWrite
+	}
+
+	p.parts[index] = stream
+	return filesystem.ObjectPart{PartNumber: index}, nil
+}
+
+func (p *partFilesystem) CompleteMultipartUpload(_, _ string, _ string, parts []filesystem.ObjectPart) error {
+	p.complete = parts
+	return nil
+}
+
+func TestTalkExportUpload(t *testing.T) {
+	tmp, err := os.CreateTemp("", "talk-export-test-*.zip")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer func() { _ = removeTempFile(tmp) }()
+
+	content := bytes.Repeat([]byte("0123456789"), talkExportPartSize/10*2+7)
+	if _, err := tmp.Write(content); err != nil {
+		t.Fatal(err)
+	}
+
+	fs := &partFilesystem{}
+	svc := &TalkExportService{Filesystem: fs}
+
+	size, err := svc.upload("talk-export/test.zip", tmp)
+	if err != nil {
+		t.Fatalf("upload() error = %v", err)
+	}
+
+	if size != int64(len(content)) {
+		t.Errorf("upload() size = %d, want %d", size, len(content))
+	}
+
+	if len(fs.complete) != 3 {
+		t.Fatalf("upload() parts = %d, want 3", len(fs.complete))
+	}
+
+	var merged []byte
+	for _, part := range fs.complete {
+		merged = append(merged, fs.parts[part.PartNumber]...)
+	}
+
+	if !bytes.Equal(merged, content) {
+		t.Error("upload() merged content mismatch")
+	}
+}
@@ -0,0 +1,479 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/gzydong/go-chat/internal/entity"
+	"github.com/gzydong/go-chat/internal/pkg/strutil"
+	"github.com/gzydong/go-chat/internal/repository/model"
+	"github.com/gzydong/go-chat/internal/repository/repo"
+	"github.com/gzydong/go-chat/internal/service/message"
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+)
+
+const (
+	talkFavoriteMaxTags       = 10  // 每条收藏最多的标签数
+	talkFavoriteMaxTagLength  = 20  // 单个标签最大长度
+	talkFavoriteMaxForward    = 10  // 单次最多转发的收藏数
+	talkFavoriteMaxTargets    = 10  // 单次最多转发的会话数
+	talkFavoriteDefaultLimit  = 20  // 默认每页条数
+	talkFavoriteMaxContentLen = 500 // 可检索文本保存的最大长度
+)
+
+// talkFavoriteMsgTypes 支持收藏的消息类型，投票、红包等有状态的消息不支持收藏
+var talkFavoriteMsgTypes = []int{
+	entity.ChatMsgTypeText,
+	entity.ChatMsgTypeCode,
+	entity.ChatMsgTypeImage,
+	entity.ChatMsgTypeAudio,
+	entity.ChatMsgTypeVideo,
+	entity.ChatMsgTypeFile,
+	entity.ChatMsgTypeLocation,
+	entity.ChatMsgTypeCard,
+	entity.ChatMsgTypeMixed,
+}
+
+var _ ITalkFavoriteService = (*TalkFavoriteService)(nil)
+
+type TalkFavoriteCreateOpt struct {
+	UserId   int
+	TalkMode int
+	ToFromId int
+	MsgId    string // 当前用户可见的消息ID
+	Tags     []string
+}
+
+type TalkFavoriteListOpt struct {
+	UserId  int
+	Keyword string // 按消息文本检索
+	Tag     string
+	MsgType int
+	Cursor  int // 上次查询的最后一条收藏ID
+	Limit   int
+}
+
+type TalkFavoriteForwardOpt struct {
+	UserId   int
+	Ids      []int // 收藏ID
+	UserIds  []int // 好友ID列表
+	GroupIds []int // 群ID列表
+}
+
+type ITalkFavoriteService interface {
+	// Create 收藏消息，保存消息内容快照
+	Create(ctx context.Context, opt *TalkFavoriteCreateOpt) (*model.TalkFavorite, error)
+	// Delete 取消收藏
+	Delete(ctx context.Context, uid int, id int) error
+	// UpdateTags 修改收藏的标签
+	UpdateTags(ctx context.Context, uid int, id int, tags []string) error
+	// List 收藏列表，按收藏时间倒序
+	List(ctx context.Context, opt *TalkFavoriteListOpt) ([]*model.TalkFavorite, error)
+	// Tags 获取用户使用过的标签
+	Tags(ctx context.Context, uid int) ([]string, error)
+	// Forward 将收藏逐条发送到指定会话
+	Forward(ctx context.Context, opt *TalkFavoriteForwardOpt) error
+}
+
+type TalkFavoriteService struct {
+	*repo.Source
+	TalkFavoriteRepo      *repo.TalkFavorite
+	GroupMemberRepo       *repo.GroupMember
+	TalkRecordsDeleteRepo *repo.TalkGroupMessageDel
+	TalkMessageExpireRepo *repo.TalkMessageExpire
+	TalkMessageArchive    *repo.TalkMessageArchive
+	AuthService           IAuthService
+	MessageService        message.IService
+	ModerationService     IModerationService
+}
+
+func (t *TalkFavoriteService) Create(ctx context.Context, opt *TalkFavoriteCreateOpt) (*model.TalkFavorite, error) {
+	tags, err := normalizeTalkFavoriteTags(opt.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	favorite, err := t.snapshot(ctx, opt)
+	if err != nil {
+		return nil, err
+	}
+
+	if !lo.Contains(talkFavoriteMsgTypes, favorite.MsgType) {
+		return nil, errors.New("该类型的消息不支持收藏")
+	}
+
+	// 限时消息及阅后即焚消息不允许收藏
+	exist, err := t.TalkMessageExpireRepo.IsExist(ctx, "msg_id = ?", opt.MsgId)
+	if err != nil {
+		return nil, err
+	}
+
+	if exist {
+		return nil, errors.New("限时消息不支持收藏")
+	}
+
+	exist, err = t.TalkFavoriteRepo.IsExist(ctx, "user_id = ? and msg_id = ?", opt.UserId, opt.MsgId)
+	if err != nil {
+		return nil, err
+	}
+
+	if exist {
+		return nil, errors.New("消息已收藏")
+	}
+
+	now := time.Now()
+	favorite.UserId = opt.UserId
+	favorite.Content = talkFavoriteContent(favorite.MsgType, favorite.Extra)
+	favorite.Tags = strings.Join(tags, ",")
+	favorite.CreatedAt = now
+	favorite.UpdatedAt = now
+
+	if err := t.TalkFavoriteRepo.Create(ctx, favorite); err != nil {
+		return nil, err
+	}
+
+	return favorite, nil
+}
+
+// snapshot 校验消息的可见性并生成收藏快照
+func (t *TalkFavoriteService) snapshot(ctx context.Context, opt *TalkFavoriteCreateOpt) (*model.TalkFavorite, error) {
+	favorite := &model.TalkFavorite{
+		TalkMode: opt.TalkMode,
+		ToFromId: opt.ToFromId,
+		MsgId:    opt.MsgId,
+	}
+
+	switch opt.TalkMode {
+	case entity.ChatPrivateMode:
+		var record model.TalkUserMessage
+
+		err := t.TalkMessageArchive.Take(ctx, opt.TalkMode, &record, "user_id = ? and to_from_id = ? and msg_id = ? and is_deleted = ?", opt.UserId, opt.ToFromId, opt.MsgId, model.No)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, errors.New("消息不存在")
+			}
+
+			return nil, err
+		}
+
+		if record.IsRevoked == model.Yes {
+			return nil, errors.New("消息已撤回")
+		}
+
+		favorite.MsgType = record.MsgType
+		favorite.Extra = record.Extra
+		favorite.FromId = record.FromId
+		favorite.SendTime = record.SendTime
+	case entity.ChatGroupMode:
+		if !t.GroupMemberRepo.IsMember(ctx, opt.ToFromId, opt.UserId, false) {
+			return nil, entity.ErrPermissionDenied
+		}
+
+		var record model.TalkGroupMessage
+
+		err := t.TalkMessageArchive.Take(ctx, opt.TalkMode, &record, "group_id = ? and msg_id = ?", opt.ToFromId, opt.MsgId)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, errors.New("消息不存在")
+			}
+
+			return nil, err
+		}
+
+		if record.IsRevoked == model.Yes {
+			return nil, errors.New("消息已撤回")
+		}
+
+		deleted, err := t.TalkRecordsDeleteRepo.FindAllMsgIds(ctx, opt.UserId, []string{opt.MsgId})
+		if err != nil {
+			return nil, err
+		}
+
+		if len(deleted) > 0 {
+			return nil, errors.New("消息不存在")
+		}
+
+		favorite.MsgType = record.MsgType
+		favorite.Extra = record.Extra
+		favorite.FromId = record.FromId
+		favorite.SendTime = record.SendTime
+	default:
+		return nil, errors.New("会话类型不正确")
+	}
+
+	return favorite, nil
+}
+
+func (t *TalkFavoriteService) Delete(ctx context.Context, uid int, id int) error {
+	res := t.Source.Db().WithContext(ctx).Where("id = ? and user_id = ?", id, uid).Delete(&model.TalkFavorite{})
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return errors.New("收藏不存在")
+	}
+
+	return nil
+}
+
+func (t *TalkFavoriteService) UpdateTags(ctx context.Context, uid int, id int, tags []string) error {
+	tags, err := normalizeTalkFavoriteTags(tags)
+	if err != nil {
+		return err
+	}
+
+	exist, err := t.TalkFavoriteRepo.IsExist(ctx, "id = ? and user_id = ?", id, uid)
+	if err != nil {
+		return err
+	}
+
+	if !exist {
+		return errors.New("收藏不存在")
+	}
+
+	_, err = t.TalkFavoriteRepo.UpdateByWhere(ctx, map[string]any{
+		"tags":       strings.Join(tags, ","),
+		"updated_at": time.Now(),
+	}, "id = ? and user_id = ?", id, uid)
+	return err
+}
+
+func (t *TalkFavoriteService) List(ctx context.Context, opt *TalkFavoriteListOpt) ([]*model.TalkFavorite, error) {
+	limit := opt.Limit
+	if limit <= 0 {
+		limit = talkFavoriteDefaultLimit
+	}
+
+	items, err := t.TalkFavoriteRepo.FindAll(ctx, func(db *gorm.DB) {
+		db.Where("user_id = ?", opt.UserId)
+
+		if opt.Cursor > 0 {
+			db.Where("id < ?", opt.Cursor)
+		}
+
+		if opt.MsgType > 0 {
+			db.Where("msg_type = ?", opt.MsgType)
+		}
+
+		if opt.Tag != "" {
+			db.Where("FIND_IN_SET(?, tags)", opt.Tag)
+		}
+
+		// 早期保存的可检索文本为转义后的内容，同时按转义后的关键词匹配
+		if keyword := strings.TrimSpace(opt.Keyword); keyword != "" {
+			escaped := html.EscapeString(keyword)
+			db.Where("(content like ? or content like ?)", "%"+escapeTalkFavoriteLike(keyword)+"%", "%"+escapeTalkFavoriteLike(escaped)+"%")
+		}
+
+		db.Order("id desc").Limit(limit)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 按消息快照重新生成可检索文本，兼容早期保存的转义内容
+	for _, item := range items {
+		item.Content = talkFavoriteContent(item.MsgType, item.Extra)
+	}
+
+	return items, nil
+}
+
+func (t *TalkFavoriteService) Tags(ctx context.Context, uid int) ([]string, error) {
+	var values []string
+	err := t.TalkFavoriteRepo.Model(ctx).
+		Where("user_id = ? and tags != ''", uid).
+		Distinct().Pluck("tags", &values).Error
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make([]string, 0)
+	for _, value := range values {
+		tags = append(tags, strings.Split(value, ",")...)
+	}
+
+	return lo.Uniq(tags), nil
+}
+
+// Forward 将收藏的快照内容作为新消息发送，发送前校验每个会话的发送权限
+func (t *TalkFavoriteService) Forward(ctx context.Context, opt *TalkFavoriteForwardOpt) error {
+	ids := lo.Uniq(opt.Ids)
+	if len(ids) == 0 || len(ids) > talkFavoriteMaxForward {
+		return fmt.Errorf("单次最多转发%d条收藏", talkFavoriteMaxForward)
+	}
+
+	targets := make([][2]int, 0, len(opt.UserIds)+len(opt.GroupIds))
+	for _, id := range lo.Uniq(opt.UserIds) {
+		targets = append(targets, [2]int{entity.ChatPrivateMode, id})
+	}
+
+	for _, id := range lo.Uniq(opt.GroupIds) {
+		targets = append(targets, [2]int{entity.ChatGroupMode, id})
+	}
+
+	if len(targets) == 0 {
+		return errors.New("请选择转发的会话")
+	}
+
+	if len(targets) > talkFavoriteMaxTargets {
+		return fmt.Errorf("单次最多转发到%d个会话", talkFavoriteMaxTargets)
+	}
+
+	favorites, err := t.TalkFavoriteRepo.FindAllByIds(ctx, opt.UserId, ids)
+	if err != nil {
+		return err
+	}
+
+	if len(favorites) != len(ids) {
+		return errors.New("收藏不存在")
+	}
+
+	// 按收藏的消息内容校验群聊的防刷屏限制
+	contents := make([]string, 0, len(favorites))
+	for _, favorite := range favorites {
+		contents = append(contents, message.SearchText(favorite.MsgType, favorite.Extra))
+	}
+
+	hasFile := lo.ContainsBy(favorites, func(favorite *model.TalkFavorite) bool {
+		return favorite.MsgType == entity.ChatMsgTypeFile
+	})
+
+	auths := make([]*AuthOption, 0, len(targets))
+	for _, target := range targets {
+		auth := &AuthOption{
+			TalkType:          target[0],
+			UserId:            opt.UserId,
+			ToFromId:          target[1],
+			IsVerifyGroupMute: true,
+			IsVerifyAntiSpam:  true,
+			MsgType:           lo.Ternary(hasFile, "file", "forward"),
+			Content:           strings.Join(contents, "\n"),
+		}
+
+		if err := t.AuthService.IsAuth(ctx, auth); err != nil {
+			return err
+		}
+
+		auths = append(auths, auth)
+	}
+
+	// 文本消息与直接发送时一样进行内容审核
+	moderations := make(map[int]*ModerationResult)
+	for _, favorite := range favorites {
+		if favorite.MsgType != entity.ChatMsgTypeText {
+			continue
+		}
+
+		result, err := t.ModerationService.Check(ctx, &ModerationCheckOpt{
+			Scene:   model.ModerationSceneMessage,
+			UserId:  opt.UserId,
+			Content: message.SearchText(favorite.MsgType, favorite.Extra),
+		})
+		if err != nil {
+			return err
+		}
+
+		moderations[favorite.Id] = result
+	}
+
+	for i, target := range targets {
+		t.AuthService.RecordSend(ctx, auths[i])
+
+		for _, favorite := range favorites {
+			var err error
+			if result, ok := moderations[favorite.Id]; ok {
+				err = t.forwardText(ctx, opt.UserId, target, result)
+			} else {
+				err = t.MessageService.CreateMessage(ctx, message.CreateMessageOption{
+					TalkMode: target[0],
+					FromId:   opt.UserId,
+					ToFromId: target[1],
+					MsgType:  favorite.MsgType,
+					Extra:    favorite.Extra,
+				})
+			}
+
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// forwardText 按审核后的内容发送文本收藏，不保留原消息中的 @ 用户
+func (t *TalkFavoriteService) forwardText(ctx context.Context, uid int, target [2]int, result *ModerationResult) error {
+	// 需人工审核的消息正常发送，审核驳回后撤回
+	msgId := lo.Ternary(result.IsReview(), strutil.NewMsgId(), "")
+
+	err := t.MessageService.CreateTextMessage(ctx, message.CreateTextMessage{
+		MsgId:    msgId,
+		TalkMode: target[0],
+		FromId:   uid,
+		ToFromId: target[1],
+		Content:  html.EscapeString(result.Content),
+	})
+	if err != nil {
+		return err
+	}
+
+	t.ModerationService.Flag(ctx, &ModerationFlagOpt{
+		Scene:    model.ModerationSceneMessage,
+		UserId:   uid,
+		TalkMode: target[0],
+		ToFromId: target[1],
+		MsgId:    msgId,
+		Result:   result,
+	})
+
+	return nil
+}
+
+// normalizeTalkFavoriteTags 去除空白及重复的标签并校验数量与长度
+func normalizeTalkFavoriteTags(tags []string) ([]string, error) {
+	items := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || lo.Contains(items, tag) {
+			continue
+		}
+
+		if strings.Contains(tag, ",") {
+			return nil, errors.New("标签不能包含逗号")
+		}
+
+		if len([]rune(tag)) > talkFavoriteMaxTagLength {
+			return nil, fmt.Errorf("标签长度不能超过%d个字符", talkFavoriteMaxTagLength)
+		}
+
+		items = append(items, tag)
+	}
+
+	if len(items) > talkFavoriteMaxTags {
+		return nil, fmt.Errorf("最多添加%d个标签", talkFavoriteMaxTags)
+	}
+
+	return items, nil
+}
+
+// talkFavoriteContent 收藏的可检索文本，超出长度时截断
+func talkFavoriteContent(msgType int, extra string) string {
+	content := []rune(message.SearchText(msgType, extra))
+	if len(content) > talkFavoriteMaxContentLen {
+		content = content[:talkFavoriteMaxContentLen]
+	}
+
+	return string(content)
+}
+
+func escapeTalkFavoriteLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
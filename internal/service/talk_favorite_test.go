@@ -0,0 +1,55 @@
+package service
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeTalkFavoriteTags(t *testing.T) {
+	tests := []struct {
+		name  string
+		tags  []string
+		want  []string
+		valid bool
+	}{
+		{"empty", nil, []string{}, true},
+		{"trim and dedupe", []string{" 工作 ", "工作", "", "学习"}, []string{"工作", "学习"}, true},
+		{"comma", []string{"a,b"}, nil, false},
+		{"too long", []string{strings.Repeat("标", 21)}, nil, false},
+		{"too many", []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10", "11"}, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizeTalkFavoriteTags(tt.tags)
+			if (err == nil) != tt.valid {
+				t.Fatalf("err = %v, valid = %v", err, tt.valid)
+			}
+
+			if tt.valid && !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTalkFavoriteContent(t *testing.T) {
+	if got := talkFavoriteContent(1, `{"content":"hello"}`); got != "hello" {
+		t.Fatalf("got %q", got)
+	}
+
+	if got := talkFavoriteContent(1, `{"content":"`+strings.Repeat("字", 600)+`"}`); len([]rune(got)) != talkFavoriteMaxContentLen {
+		t.Fatalf("content length = %d", len([]rune(got)))
+	}
+
+	if got := talkFavoriteContent(3, `{"url":"a.png"}`); got != "" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestEscapeTalkFavoriteLike(t *testing.T) {
+	if got := escapeTalkFavoriteLike(`50%_a\b`); got != `50\%\_a\\b` {
+		t.Fatalf("got %q", got)
+	}
+}
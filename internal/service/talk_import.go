@@ -0,0 +1,464 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gzydong/go-chat/internal/entity"
+	"github.com/gzydong/go-chat/internal/pkg/encrypt"
+	"github.com/gzydong/go-chat/internal/pkg/jsonutil"
+	"github.com/gzydong/go-chat/internal/repository/model"
+	"github.com/gzydong/go-chat/internal/repository/repo"
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+)
+
+const (
+	talkImportBatchSize = 500 // 每批写入的消息数
+	talkImportMaxErrors = 100 // 报告中最多记录的错误数
+)
+
+var _ ITalkImportService = (*TalkImportService)(nil)
+
+type TalkImportOpt struct {
+	Data    []byte         // 存档内容，格式见 talk_import_archive.go
+	Senders map[string]int // 发送者映射，不为空时覆盖存档中的映射
+	DryRun  bool           // 仅校验并统计，不写入数据
+}
+
+// TalkImportReport 导入结果
+type TalkImportReport struct {
+	DryRun        bool                            `json:"dry_run"`
+	Total         int                             `json:"total"`      // 存档中的消息数
+	Created       int                             `json:"created"`    // 导入的消息数（试运行时为可导入的消息数）
+	Duplicated    int                             `json:"duplicated"` // msg_id 已存在而跳过的消息数
+	Invalid       int                             `json:"invalid"`    // 校验失败的消息数
+	Conversations []*TalkImportConversationReport `json:"conversations"`
+	Errors        []string                        `json:"errors"`
+}
+
+type TalkImportConversationReport struct {
+	TalkMode   int    `json:"talk_mode"`
+	UserId     int    `json:"user_id"`
+	ToFromId   int    `json:"to_from_id"`
+	Total      int    `json:"total"`
+	Created    int    `json:"created"`
+	Duplicated int    `json:"duplicated"`
+	Invalid    int    `json:"invalid"`
+	Error      string `json:"error,omitempty"` // 会话校验失败的原因，此时会话内的消息均不导入
+}
+
+type ITalkImportService interface {
+	// Import 导入聊天记录，按原发送时间排序并重新分配时序ID，msg_id 已存在的消息跳过
+	Import(ctx context.Context, opt *TalkImportOpt) (*TalkImportReport, error)
+}
+
+type TalkImportService struct {
+	*repo.Source
+	UsersRepo *repo.Users
+	GroupRepo *repo.Group
+	Sequence  *repo.Sequence
+}
+
+func (t *TalkImportService) Import(ctx context.Context, opt *TalkImportOpt) (*TalkImportReport, error) {
+	archive, err := parseTalkImportArchive(opt.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	senders := archive.Senders
+	if len(opt.Senders) > 0 {
+		senders = opt.Senders
+	}
+
+	report := &TalkImportReport{
+		DryRun:        opt.DryRun,
+		Conversations: make([]*TalkImportConversationReport, 0, len(archive.Conversations)),
+		Errors:        make([]string, 0),
+	}
+
+	for _, conv := range archive.Conversations {
+		item, err := t.conversation(ctx, report, senders, conv, opt.DryRun)
+		if err != nil {
+			return nil, err
+		}
+
+		report.Total += item.Total
+		report.Created += item.Created
+		report.Duplicated += item.Duplicated
+		report.Invalid += item.Invalid
+		report.Conversations = append(report.Conversations, item)
+	}
+
+	return report, nil
+}
+
+// conversation 导入单个会话，返回的错误为数据库错误，校验失败记录到报告中
+func (t *TalkImportService) conversation(ctx context.Context, report *TalkImportReport, senders map[string]int, conv *talkImportConversation, dryRun bool) (*TalkImportConversationReport, error) {
+	item := &TalkImportConversationReport{
+		TalkMode: conv.TalkMode,
+		UserId:   conv.UserId,
+		ToFromId: conv.ToFromId,
+		Total:    len(conv.Messages),
+	}
+
+	if err := t.checkConversation(ctx, conv); err != nil {
+		item.Invalid = item.Total
+		item.Error = err.Error()
+		return item, nil
+	}
+
+	messages, err := t.resolve(ctx, report, item, senders, conv)
+	if err != nil {
+		return nil, err
+	}
+
+	// 单个会话在同一事务中写入，写入失败时整个会话回滚，重新导入时不会只导入部分消息
+	created := 0
+	err = t.Source.Db().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, chunk := range lo.Chunk(messages, talkImportBatchSize) {
+			chunk, err := t.excludeExists(tx, conv.TalkMode, chunk)
+			if err != nil {
+				return err
+			}
+
+			if len(chunk) == 0 {
+				continue
+			}
+
+			if !dryRun {
+				if err := t.create(ctx, tx, conv, chunk); err != nil {
+					return err
+				}
+			}
+
+			created += len(chunk)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	item.Created = created
+	item.Duplicated = item.Total - item.Invalid - item.Created
+	return item, nil
+}
+
+func (t *TalkImportService) checkConversation(ctx context.Context, conv *talkImportConversation) error {
+	switch conv.TalkMode {
+	case entity.ChatPrivateMode:
+		if conv.UserId <= 0 || conv.ToFromId <= 0 || conv.UserId == conv.ToFromId {
+			return errors.New("私聊会话双方用户ID不正确")
+		}
+
+		users, err := t.UsersRepo.FindByIds(ctx, []any{conv.UserId, conv.ToFromId})
+		if err != nil {
+			return err
+		}
+
+		if len(users) != 2 {
+			return errors.New("私聊会话用户不存在")
+		}
+	case entity.ChatGroupMode:
+		if _, err := t.GroupRepo.FindById(ctx, conv.ToFromId); err != nil {
+			return errors.New("群组不存在")
+		}
+	default:
+		return errors.New("会话类型不正确")
+	}
+
+	return nil
+}
+
+// talkImportItem 校验通过待导入的消息
+type talkImportItem struct {
+	*talkImportMessage
+	FromId   int
+	Extra    string
+	SendTime time.Time
+}
+
+// resolve 校验消息并映射发送者，按发送时间排序，存档内 msg_id 重复的消息只保留第一条
+func (t *TalkImportService) resolve(ctx context.Context, report *TalkImportReport, item *TalkImportConversationReport, senders map[string]int, conv *talkImportConversation) ([]*talkImportItem, error) {
+	userIds := make([]any, 0)
+	for _, msg := range conv.Messages {
+		if id, ok := talkImportSenderId(senders, msg.FromId); ok && id > 0 {
+			userIds = append(userIds, id)
+		}
+	}
+
+	exists := make(map[int]struct{})
+	if len(userIds) > 0 {
+		users, err := t.UsersRepo.FindByIds(ctx, lo.Uniq(userIds))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, user := range users {
+			exists[user.Id] = struct{}{}
+		}
+	}
+
+	invalid := func(msg *talkImportMessage, reason string) {
+		item.Invalid++
+		if len(report.Errors) < talkImportMaxErrors {
+			report.Errors = append(report.Errors, fmt.Sprintf("会话 %d_%d 消息 %s: %s", conv.TalkMode, conv.ToFromId, msg.MsgId, reason))
+		}
+	}
+
+	seen := make(map[string]struct{}, len(conv.Messages))
+	items := make([]*talkImportItem, 0, len(conv.Messages))
+	for _, msg := range conv.Messages {
+		value, reason := newTalkImportItem(senders, msg)
+		if reason != "" {
+			invalid(msg, reason)
+			continue
+		}
+
+		if value.FromId > 0 {
+			if _, ok := exists[value.FromId]; !ok {
+				invalid(msg, "发送者不存在")
+				continue
+			}
+		}
+
+		if conv.TalkMode == entity.ChatPrivateMode && value.FromId != conv.UserId && value.FromId != conv.ToFromId {
+			invalid(msg, "发送者不是私聊会话的参与者")
+			continue
+		}
+
+		if _, ok := seen[msg.MsgId]; ok {
+			continue
+		}
+
+		seen[msg.MsgId] = struct{}{}
+		items = append(items, value)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].SendTime.Before(items[j].SendTime)
+	})
+
+	return items, nil
+}
+
+// excludeExists 排除 msg_id 已存在的消息
+func (t *TalkImportService) excludeExists(tx *gorm.DB, talkMode int, items []*talkImportItem) ([]*talkImportItem, error) {
+	msgIds := lo.Map(items, func(item *talkImportItem, _ int) string {
+		return item.MsgId
+	})
+
+	var table any = &model.TalkUserMessage{}
+	if talkMode == entity.ChatGroupMode {
+		table = &model.TalkGroupMessage{}
+	}
+
+	var exists []string
+	err := tx.Model(table).Where("msg_id in ?", msgIds).Pluck("msg_id", &exists).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return lo.Filter(items, func(item *talkImportItem, _ int) bool {
+		return !lo.Contains(exists, item.MsgId)
+	}), nil
+}
+
+// create 在会话事务中写入一批消息，时序ID按批次重新分配
+// 时序ID由号段分配，事务回滚后仅留下未使用的空洞，不影响时序ID的唯一及递增
+func (t *TalkImportService) create(ctx context.Context, tx *gorm.DB, conv *talkImportConversation, items []*talkImportItem) error {
+	now := time.Now()
+
+	if conv.TalkMode == entity.ChatGroupMode {
+		sequences := t.Sequence.BatchGet(ctx, repo.SequenceTypeGroup, int32(conv.ToFromId), len(items))
+
+		records := make([]*model.TalkGroupMessage, 0, len(items))
+		for i, item := range items {
+			records = append(records, &model.TalkGroupMessage{
+				MsgId:     item.MsgId,
+				Sequence:  sequences[i],
+				MsgType:   item.MsgType,
+				GroupId:   conv.ToFromId,
+				FromId:    item.FromId,
+				IsRevoked: lo.Ternary(item.IsRevoked, model.Yes, model.No),
+				Extra:     item.Extra,
+				Quote:     "{}",
+				RootMsgId: item.RootMsgId,
+				SendTime:  item.SendTime,
+				CreatedAt: now,
+				UpdatedAt: now,
+			})
+		}
+
+		return tx.CreateInBatches(records, talkImportBatchSize).Error
+	}
+
+	// 私聊消息为会话双方各写入一条，对方的 msg_id 由原 msg_id 推导，重复导入时同样可去重
+	sequences1 := t.Sequence.BatchGet(ctx, repo.SequenceTypeUser, int32(conv.UserId), len(items))
+	sequences2 := t.Sequence.BatchGet(ctx, repo.SequenceTypeUser, int32(conv.ToFromId), len(items))
+
+	records := make([]*model.TalkUserMessage, 0, len(items)*2)
+	for i, item := range items {
+		isRevoked := lo.Ternary(item.IsRevoked, model.Yes, model.No)
+
+		records = append(records, &model.TalkUserMessage{
+			MsgId:     item.MsgId,
+			OrgMsgId:  item.MsgId,
+			Sequence:  sequences1[i],
+			MsgType:   item.MsgType,
+			UserId:    conv.UserId,
+			ToFromId:  conv.ToFromId,
+			FromId:    item.FromId,
+			IsRevoked: isRevoked,
+			IsDeleted: model.No,
+			Extra:     item.Extra,
+			Quote:     "{}",
+			SendTime:  item.SendTime,
+			CreatedAt: now,
+			UpdatedAt: now,
+		})
+
+		records = append(records, &model.TalkUserMessage{
+			MsgId:     encrypt.Md5(item.MsgId + ":" + strconv.Itoa(conv.ToFromId)),
+			OrgMsgId:  item.MsgId,
+			Sequence:  sequences2[i],
+			MsgType:   item.MsgType,
+			UserId:    conv.ToFromId,
+			ToFromId:  conv.UserId,
+			FromId:    item.FromId,
+			IsRevoked: isRevoked,
+			IsDeleted: model.No,
+			Extra:     item.Extra,
+			Quote:     "{}",
+			SendTime:  item.SendTime,
+			CreatedAt: now,
+			UpdatedAt: now,
+		})
+	}
+
+	return tx.CreateInBatches(records, talkImportBatchSize).Error
+}
+
+// newTalkImportItem 校验单条消息，返回不合法的原因
+func newTalkImportItem(senders map[string]int, msg *talkImportMessage) (*talkImportItem, string) {
+	if msg.MsgId == "" || len(msg.MsgId) > 64 {
+		return nil, "msg_id 不能为空且长度不能超过64"
+	}
+
+	if _, ok := talkImportMsgTypes[msg.MsgType]; !ok {
+		return nil, "msg_type 不支持导入"
+	}
+
+	fromId, ok := talkImportSenderId(senders, msg.FromId)
+	if !ok {
+		return nil, fmt.Sprintf("发送者 %s 未映射", msg.FromId)
+	}
+
+	sendTime, err := parseTalkImportTime(msg.SendTime)
+	if err != nil {
+		return nil, "send_time 格式错误"
+	}
+
+	extra, reason := talkImportExtra(msg.MsgType, msg.Extra)
+	if reason != "" {
+		return nil, reason
+	}
+
+	if len(msg.RootMsgId) > 64 {
+		return nil, "root_msg_id 长度不能超过64"
+	}
+
+	return &talkImportItem{
+		talkImportMessage: msg,
+		FromId:            fromId,
+		Extra:             extra,
+		SendTime:          sendTime,
+	}, ""
+}
+
+// talkImportMsgTypes 支持导入的消息类型，系统消息及依赖本系统数据的消息(转发、投票、红包等)不支持导入
+var talkImportMsgTypes = map[int]struct{}{
+	entity.ChatMsgTypeText:     {},
+	entity.ChatMsgTypeCode:     {},
+	entity.ChatMsgTypeImage:    {},
+	entity.ChatMsgTypeAudio:    {},
+	entity.ChatMsgTypeVideo:    {},
+	entity.ChatMsgTypeFile:     {},
+	entity.ChatMsgTypeLocation: {},
+	entity.ChatMsgTypeMixed:    {},
+}
+
+// talkImportExtra 按消息类型解析 extra 并重新编码，丢弃未定义的字段，返回不合法的原因
+// 文本内容与发送消息一致做 HTML 转义，导出文件中已转义的内容先还原，避免重复转义
+func talkImportExtra(msgType int, raw json.RawMessage) (string, string) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return "", "extra 不能为空"
+	}
+
+	var (
+		value any
+		valid bool
+	)
+
+	switch msgType {
+	case entity.ChatMsgTypeText:
+		var data model.TalkRecordExtraText
+		if valid = json.Unmarshal(raw, &data) == nil && strings.TrimSpace(data.Content) != ""; valid {
+			value = model.TalkRecordExtraText{
+				Content:  html.EscapeString(html.UnescapeString(data.Content)),
+				Mentions: data.Mentions,
+			}
+		}
+	case entity.ChatMsgTypeCode:
+		var data model.TalkRecordExtraCode
+		valid, value = json.Unmarshal(raw, &data) == nil && data.Code != "", data
+	case entity.ChatMsgTypeImage:
+		var data model.TalkRecordExtraImage
+		valid, value = json.Unmarshal(raw, &data) == nil && data.Url != "", data
+	case entity.ChatMsgTypeAudio:
+		var data model.TalkRecordExtraAudio
+		valid, value = json.Unmarshal(raw, &data) == nil && data.Url != "", data
+	case entity.ChatMsgTypeVideo:
+		var data model.TalkRecordExtraVideo
+		valid, value = json.Unmarshal(raw, &data) == nil && data.Url != "", data
+	case entity.ChatMsgTypeFile:
+		var data model.TalkRecordExtraFile
+		valid, value = json.Unmarshal(raw, &data) == nil && data.Name != "", data
+	case entity.ChatMsgTypeLocation:
+		var data model.TalkRecordExtraLocation
+		valid, value = json.Unmarshal(raw, &data) == nil && data.Longitude != "" && data.Latitude != "", data
+	case entity.ChatMsgTypeMixed:
+		var data model.TalkRecordExtraMixed
+		valid, value = json.Unmarshal(raw, &data) == nil && len(data.Items) > 0, data
+	}
+
+	if !valid {
+		return "", "extra 与消息类型不匹配"
+	}
+
+	return jsonutil.Encode(value), ""
+}
+
+// talkImportSenderId 映射发送者，映射表为空时原ID即本系统用户ID
+func talkImportSenderId(senders map[string]int, from talkImportSender) (int, bool) {
+	if from == "" || from == "0" {
+		return 0, true
+	}
+
+	if len(senders) > 0 {
+		id, ok := senders[string(from)]
+		return id, ok && id > 0
+	}
+
+	id, err := strconv.Atoi(string(from))
+	return id, err == nil && id > 0
+}
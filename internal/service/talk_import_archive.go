@@ -0,0 +1,200 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 聊天记录导入存档格式（JSON，UTF-8）：
+//
+//	{
+//	  "version": 1,
+//	  "senders": {"u_1001": 2054, "1002": 2055},
+//	  "conversations": [
+//	    {
+//	      "talk_mode": 2,
+//	      "user_id": 0,
+//	      "to_from_id": 1024,
+//	      "messages": [
+//	        {
+//	          "msg_id": "c4ca4238a0b923820dcc509a6f75849b",
+//	          "msg_type": 1,
+//	          "from_id": "u_1001",
+//	          "extra": {"content": "hello"},
+//	          "is_revoked": false,
+//	          "root_msg_id": "",
+//	          "send_time": "2024-01-02 15:04:05"
+//	        }
+//	      ]
+//	    }
+//	  ]
+//	}
+//
+// senders 为原系统发送者ID到本系统用户ID的映射，为空时发送者ID按本系统用户ID处理（备份恢复）
+// talk_mode 为 1 时 user_id 与 to_from_id 为私聊双方的用户ID，为 2 时 to_from_id 为群ID
+// from_id 支持字符串或数字，"0" 表示系统消息；send_time 支持 2006-01-02 15:04:05 及 RFC3339 格式
+//
+// 同时支持直接导入聊天记录导出生成的 ZIP 压缩包，会话及消息读取自 manifest.json 与各会话目录下的 messages.json
+
+const (
+	talkImportVersion     = 1
+	talkImportMaxMessages = 200000 // 单个存档最多导入的消息数
+)
+
+// talkImportArchive 聊天记录导入存档
+type talkImportArchive struct {
+	Version       int                       `json:"version"`
+	Senders       map[string]int            `json:"senders"`
+	Conversations []*talkImportConversation `json:"conversations"`
+}
+
+type talkImportConversation struct {
+	TalkMode int                  `json:"talk_mode"`
+	UserId   int                  `json:"user_id"`
+	ToFromId int                  `json:"to_from_id"`
+	Messages []*talkImportMessage `json:"messages"`
+}
+
+type talkImportMessage struct {
+	MsgId     string           `json:"msg_id"`
+	MsgType   int              `json:"msg_type"`
+	FromId    talkImportSender `json:"from_id"`
+	Extra     json.RawMessage  `json:"extra"`
+	IsRevoked bool             `json:"is_revoked"`
+	RootMsgId string           `json:"root_msg_id"`
+	SendTime  string           `json:"send_time"`
+}
+
+// talkImportSender 原系统发送者ID，兼容字符串及数字
+type talkImportSender string
+
+func (s *talkImportSender) UnmarshalJSON(data []byte) error {
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	switch v := value.(type) {
+	case nil:
+		*s = ""
+	case string:
+		*s = talkImportSender(strings.TrimSpace(v))
+	case float64:
+		*s = talkImportSender(strconv.FormatInt(int64(v), 10))
+	default:
+		return errors.New("from_id 格式错误")
+	}
+
+	return nil
+}
+
+// parseTalkImportArchive 解析导入存档，ZIP 压缩包按聊天记录导出格式读取
+func parseTalkImportArchive(data []byte) (*talkImportArchive, error) {
+	var archive *talkImportArchive
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		value, err := parseTalkExportZip(data)
+		if err != nil {
+			return nil, err
+		}
+
+		archive = value
+	} else {
+		archive = &talkImportArchive{}
+		if err := json.Unmarshal(data, archive); err != nil {
+			return nil, fmt.Errorf("存档格式错误: %s", err.Error())
+		}
+	}
+
+	if archive.Version != talkImportVersion {
+		return nil, fmt.Errorf("不支持的存档版本 %d", archive.Version)
+	}
+
+	if len(archive.Conversations) == 0 {
+		return nil, errors.New("存档中没有会话")
+	}
+
+	count := 0
+	for _, conv := range archive.Conversations {
+		count += len(conv.Messages)
+	}
+
+	if count > talkImportMaxMessages {
+		return nil, fmt.Errorf("单个存档最多导入%d条消息", talkImportMaxMessages)
+	}
+
+	return archive, nil
+}
+
+// parseTalkExportZip 读取聊天记录导出的压缩包
+func parseTalkExportZip(data []byte) (*talkImportArchive, error) {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("压缩包格式错误: %s", err.Error())
+	}
+
+	var manifest struct {
+		UserId        int                       `json:"user_id"`
+		Conversations []*talkExportConversation `json:"conversations"`
+	}
+
+	if err := readTalkImportZipFile(reader, "manifest.json", &manifest); err != nil {
+		return nil, err
+	}
+
+	archive := &talkImportArchive{
+		Version:       talkImportVersion,
+		Conversations: make([]*talkImportConversation, 0, len(manifest.Conversations)),
+	}
+
+	for _, item := range manifest.Conversations {
+		conv := &talkImportConversation{
+			TalkMode: item.TalkMode,
+			UserId:   manifest.UserId,
+			ToFromId: item.ToFromId,
+		}
+
+		if err := readTalkImportZipFile(reader, item.Dir+"/messages.json", &conv.Messages); err != nil {
+			return nil, err
+		}
+
+		archive.Conversations = append(archive.Conversations, conv)
+	}
+
+	return archive, nil
+}
+
+func readTalkImportZipFile(reader *zip.Reader, name string, value any) error {
+	file, err := reader.Open(name)
+	if err != nil {
+		return fmt.Errorf("压缩包中缺少 %s", name)
+	}
+
+	defer func() { _ = file.Close() }()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(data, value); err != nil {
+		return fmt.Errorf("%s 格式错误: %s", name, err.Error())
+	}
+
+	return nil
+}
+
+// parseTalkImportTime 解析消息发送时间
+func parseTalkImportTime(value string) (time.Time, error) {
+	if t, err := time.ParseInLocation(time.DateTime, value, time.Local); err == nil {
+		return t, nil
+	}
+
+	return time.Parse(time.RFC3339, value)
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestParseTalkImportArchive(t *testing.T) {
+	data := []byte(`{
+		"version": 1,
+		"senders": {"u_1": 10},
+		"conversations": [{
+			"talk_mode": 2,
+			"to_from_id": 5,
+			"messages": [
+				{"msg_id": "a", "msg_type": 1, "from_id": "u_1", "extra": {"content": "hi"}, "send_time": "2024-01-01 00:00:00"},
+				{"msg_id": "b", "msg_type": 1, "from_id": 2, "extra": {"content": "ok"}, "send_time": "2024-01-01T00:01:00Z"}
+			]
+		}]
+	}`)
+
+	archive, err := parseTalkImportArchive(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	messages := archive.Conversations[0].Messages
+	if len(messages) != 2 || messages[0].FromId != "u_1" || messages[1].FromId != "2" {
+		t.Fatalf("unexpected messages: %+v", messages)
+	}
+
+	if archive.Senders["u_1"] != 10 {
+		t.Fatalf("unexpected senders: %v", archive.Senders)
+	}
+
+	for _, value := range []string{`{"version": 2, "conversations": [{}]}`, `{"version": 1}`, `[]`} {
+		if _, err := parseTalkImportArchive([]byte(value)); err == nil {
+			t.Fatalf("parse %s: expected error", value)
+		}
+	}
+}
+
+func TestParseTalkImportArchiveZip(t *testing.T) {
+	buf := &bytes.Buffer{}
+	archive := newTalkExportArchive(buf)
+
+	section, err := archive.Begin(1, 2, "Alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = section.Write(&talkExportRecord{MsgId: "m1", MsgType: 1, FromId: 2, Extra: json.RawMessage(`{"content":"hi"}`), SendTime: "2024-01-01 00:00:00"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := section.Close(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := archive.Close(map[string]any{"user_id": 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	value, err := parseTalkImportArchive(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(value.Conversations) != 1 {
+		t.Fatalf("unexpected conversations: %d", len(value.Conversations))
+	}
+
+	conv := value.Conversations[0]
+	if conv.TalkMode != 1 || conv.UserId != 1 || conv.ToFromId != 2 || len(conv.Messages) != 1 {
+		t.Fatalf("unexpected conversation: %+v", conv)
+	}
+
+	if msg := conv.Messages[0]; msg.MsgId != "m1" || msg.FromId != "2" || string(msg.Extra) != `{"content":"hi"}` {
+		t.Fatalf("unexpected message: %+v", msg)
+	}
+}
+
+func TestNewTalkImportItem(t *testing.T) {
+	senders := map[string]int{"u_1": 10, "u_2": 0}
+
+	tests := []struct {
+		name   string
+		msg    *talkImportMessage
+		fromId int
+		extra  string
+		valid  bool
+	}{
+		{"mapped", &talkImportMessage{MsgId: "a", MsgType: 1, FromId: "u_1", Extra: json.RawMessage(`{"content":"hi"}`), SendTime: "2024-01-01 00:00:00"}, 10, `{"content":"hi"}`, true},
+		{"system", &talkImportMessage{MsgId: "a", MsgType: 1, FromId: "0", Extra: json.RawMessage(`{"content":"hi"}`), SendTime: "2024-01-01 00:00:00"}, 0, `{"content":"hi"}`, true},
+		{"escape text", &talkImportMessage{MsgId: "a", MsgType: 1, FromId: "u_1", Extra: json.RawMessage(`{"content":"<script>x</script> &amp; y","previews":[{"url":"https://example.com"}],"evil":1}`), SendTime: "2024-01-01 00:00:00"}, 10, `{"content":"\u0026lt;script\u0026gt;x\u0026lt;/script\u0026gt; \u0026amp; y"}`, true},
+		{"image", &talkImportMessage{MsgId: "a", MsgType: 3, FromId: "u_1", Extra: json.RawMessage(`{"url":"https://example.com/a.png","width":10,"evil":1}`), SendTime: "2024-01-01 00:00:00"}, 10, `{"name":"","size":0,"url":"https://example.com/a.png","width":10,"height":0}`, true},
+		{"empty extra", &talkImportMessage{MsgId: "a", MsgType: 1, FromId: "u_1", SendTime: "2024-01-01 00:00:00"}, 0, "", false},
+		{"mismatched extra", &talkImportMessage{MsgId: "a", MsgType: 3, FromId: "u_1", Extra: json.RawMessage(`{"content":"hi"}`), SendTime: "2024-01-01 00:00:00"}, 0, "", false},
+		{"unsupported msg_type", &talkImportMessage{MsgId: "a", MsgType: 15, FromId: "u_1", Extra: json.RawMessage(`{}`), SendTime: "2024-01-01 00:00:00"}, 0, "", false},
+		{"unmapped", &talkImportMessage{MsgId: "a", MsgType: 1, FromId: "u_3", Extra: json.RawMessage(`{"content":"hi"}`), SendTime: "2024-01-01 00:00:00"}, 0, "", false},
+		{"mapped to zero", &talkImportMessage{MsgId: "a", MsgType: 1, FromId: "u_2", SendTime: "2024-01-01 00:00:00"}, 0, "", false},
+		{"empty msg_id", &talkImportMessage{MsgType: 1, FromId: "u_1", SendTime: "2024-01-01 00:00:00"}, 0, "", false},
+		{"invalid msg_type", &talkImportMessage{MsgId: "a", FromId: "u_1", SendTime: "2024-01-01 00:00:00"}, 0, "", false},
+		{"invalid send_time", &talkImportMessage{MsgId: "a", MsgType: 1, FromId: "u_1", SendTime: "yesterday"}, 0, "", false},
+		{"invalid extra", &talkImportMessage{MsgId: "a", MsgType: 1, FromId: "u_1", Extra: json.RawMessage(`[1]`), SendTime: "2024-01-01 00:00:00"}, 0, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item, reason := newTalkImportItem(senders, tt.msg)
+			if (reason == "") != tt.valid {
+				t.Fatalf("valid = %v, reason = %q", tt.valid, reason)
+			}
+
+			if tt.valid && (item.FromId != tt.fromId || item.Extra != tt.extra) {
+				t.Fatalf("got from_id %d extra %s", item.FromId, item.Extra)
+			}
+		})
+	}
+}
+
+func TestTalkImportSenderId(t *testing.T) {
+	tests := []struct {
+		senders map[string]int
+		from    talkImportSender
+		id      int
+		ok      bool
+	}{
+		{nil, "12", 12, true},
+		{nil, "abc", 0, false},
+		{nil, "", 0, true},
+		{map[string]int{"abc": 3}, "abc", 3, true},
+		{map[string]int{"abc": 3}, "12", 0, false},
+	}
+
+	for _, tt := range tests {
+		id, ok := talkImportSenderId(tt.senders, tt.from)
+		if id != tt.id || ok != tt.ok {
+			t.Errorf("talkImportSenderId(%v, %q) = %d, %v", tt.senders, tt.from, id, ok)
+		}
+	}
+}
@@ -0,0 +1,467 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gzydong/go-chat/internal/entity"
+	"github.com/gzydong/go-chat/internal/logic"
+	"github.com/gzydong/go-chat/internal/pkg/filesystem"
+	"github.com/gzydong/go-chat/internal/pkg/jsonutil"
+	"github.com/gzydong/go-chat/internal/pkg/logger"
+	"github.com/gzydong/go-chat/internal/repository/model"
+	"github.com/gzydong/go-chat/internal/repository/repo"
+	"github.com/gzydong/go-chat/internal/service/message"
+	"gorm.io/gorm"
+)
+
+const (
+	TalkExpireMinTtl    = 5         // 最短有效时长(秒)
+	TalkExpireMaxTtl    = 7 * 86400 // 最长有效时长(秒)
+	talkExpireBatchSize = 200
+)
+
+var _ ITalkMessageExpireService = (*TalkMessageExpireService)(nil)
+
+type TalkExpireSettingOpt struct {
+	UserId   int // 操作人
+	TalkMode int // 对话类型
+	ToFromId int // 好友ID或群ID
+	Mode     int // 计时方式 1:发送后 2:首次阅读后
+	Ttl      int // 有效时长(秒)，0 表示关闭
+}
+
+type ITalkMessageExpireService interface {
+	// GetSetting 获取会话的消息过期设置，未设置时返回 nil
+	GetSetting(ctx context.Context, uid int, talkMode int, toFromId int) (*model.TalkExpireSetting, error)
+	// UpdateSetting 修改会话的消息过期设置
+	UpdateSetting(ctx context.Context, opt *TalkExpireSettingOpt) error
+	// MarkRead 标记阅后即焚消息已读并开始计时，msgIds 为空时标记会话内全部消息
+	MarkRead(ctx context.Context, uid int, talkMode int, toFromId int, msgIds []string) error
+	// PurgeExpired 销毁已过期的消息，返回销毁数量
+	PurgeExpired(ctx context.Context) (int, error)
+}
+
+type TalkMessageExpireService struct {
+	*repo.Source
+	TalkExpireSettingRepo *repo.TalkExpireSetting
+	TalkMessageExpireRepo *repo.TalkMessageExpire
+	GroupMemberRepo       *repo.GroupMember
+	UserRepo              *repo.Users
+	AuthService           IAuthService
+	MessageService        message.IService
+	TalkMessageArchive    *repo.TalkMessageArchive
+	TalkSyncService       ITalkSyncService
+	PushMessage           *logic.PushMessage
+	Filesystem            filesystem.IFilesystem
+}
+
+func (t *TalkMessageExpireService) GetSetting(ctx context.Context, uid int, talkMode int, toFromId int) (*model.TalkExpireSetting, error) {
+	if talkMode == entity.ChatGroupMode && !t.GroupMemberRepo.IsMember(ctx, toFromId, uid, true) {
+		return nil, entity.ErrPermissionDenied
+	}
+
+	return t.TalkExpireSettingRepo.FindByTalk(ctx, talkMode, uid, toFromId)
+}
+
+// UpdateSetting 修改会话的消息过期设置
+// 群聊仅群主及管理员可操作，私聊双方均可操作且对双方生效，只影响之后发送的消息
+func (t *TalkMessageExpireService) UpdateSetting(ctx context.Context, opt *TalkExpireSettingOpt) error {
+	if opt.Ttl != 0 && (opt.Ttl < TalkExpireMinTtl || opt.Ttl > TalkExpireMaxTtl) {
+		return fmt.Errorf("有效时长需在%d秒至%d天之间", TalkExpireMinTtl, TalkExpireMaxTtl/86400)
+	}
+
+	if opt.TalkMode == entity.ChatGroupMode && !t.GroupMemberRepo.IsLeader(ctx, opt.ToFromId, opt.UserId) {
+		return entity.ErrPermissionDenied
+	}
+
+	err := t.AuthService.IsAuth(ctx, &AuthOption{
+		TalkType: opt.TalkMode,
+		UserId:   opt.UserId,
+		ToFromId: opt.ToFromId,
+	})
+	if err != nil {
+		return err
+	}
+
+	userId, toFromId := repo.TalkPairKey(opt.TalkMode, opt.UserId, opt.ToFromId)
+
+	setting, err := t.TalkExpireSettingRepo.FindByTalk(ctx, opt.TalkMode, opt.UserId, opt.ToFromId)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	switch {
+	case opt.Ttl == 0 && setting == nil:
+		return nil
+	case opt.Ttl == 0:
+		err = t.TalkExpireSettingRepo.Delete(ctx, setting.Id)
+	case setting == nil:
+		err = t.TalkExpireSettingRepo.Create(ctx, &model.TalkExpireSetting{
+			TalkMode:  opt.TalkMode,
+			UserId:    userId,
+			ToFromId:  toFromId,
+			Mode:      opt.Mode,
+			Ttl:       opt.Ttl,
+			UpdatedBy: opt.UserId,
+			CreatedAt: now,
+			UpdatedAt: now,
+		})
+	default:
+		_, err = t.TalkExpireSettingRepo.UpdateById(ctx, setting.Id, map[string]any{
+			"mode":       opt.Mode,
+			"ttl":        opt.Ttl,
+			"updated_by": opt.UserId,
+			"updated_at": now,
+		})
+	}
+
+	if err != nil {
+		return err
+	}
+
+	t.notifySetting(ctx, opt)
+	return nil
+}
+
+// notifySetting 在会话中记录过期设置变更的系统消息
+func (t *TalkMessageExpireService) notifySetting(ctx context.Context, opt *TalkExpireSettingOpt) {
+	nickname := "对方"
+	if user, _ := t.UserRepo.FindByIdWithCache(ctx, opt.UserId); user != nil {
+		nickname = user.Nickname
+	}
+
+	content := fmt.Sprintf("【%s】关闭了消息定时销毁", nickname)
+	if opt.Ttl > 0 && opt.Mode == model.TalkExpireModeAfterRead {
+		content = fmt.Sprintf("【%s】开启了阅后即焚，消息阅读%s后销毁", nickname, formatExpireTtl(opt.Ttl))
+	} else if opt.Ttl > 0 {
+		content = fmt.Sprintf("【%s】开启了消息定时销毁，消息发送%s后销毁", nickname, formatExpireTtl(opt.Ttl))
+	}
+
+	if opt.TalkMode == entity.ChatGroupMode {
+		_ = t.MessageService.CreateGroupSysMessage(ctx, message.CreateGroupSysMessageOption{
+			GroupId: opt.ToFromId,
+			Content: content,
+		})
+		return
+	}
+
+	_ = t.MessageService.CreatePrivateSysMessage(ctx, message.CreatePrivateSysMessageOption{
+		FromId:   opt.UserId,
+		ToFromId: opt.ToFromId,
+		Content:  content,
+	})
+
+	_ = t.MessageService.CreatePrivateSysMessage(ctx, message.CreatePrivateSysMessageOption{
+		FromId:   opt.ToFromId,
+		ToFromId: opt.UserId,
+		Content:  content,
+	})
+}
+
+// MarkRead 标记阅后即焚消息已读
+// 接收方首次阅读后开始计时，私聊双方的消息同时开始计时
+func (t *TalkMessageExpireService) MarkRead(ctx context.Context, uid int, talkMode int, toFromId int, msgIds []string) error {
+	if talkMode == entity.ChatGroupMode && !t.GroupMemberRepo.IsMember(ctx, toFromId, uid, true) {
+		return entity.ErrPermissionDenied
+	}
+
+	tx := t.Source.Db().WithContext(ctx).Model(&model.TalkMessageExpire{}).
+		Where("talk_mode = ? and to_from_id = ?", talkMode, toFromId).
+		Where("mode = ? and expire_at = 0 and from_id <> ?", model.TalkExpireModeAfterRead, uid)
+
+	if talkMode == entity.ChatPrivateMode {
+		tx = tx.Where("user_id = ?", uid)
+	}
+
+	if len(msgIds) > 0 {
+		tx = tx.Where("msg_id in ?", msgIds)
+	}
+
+	var orgMsgIds []string
+	if err := tx.Pluck("org_msg_id", &orgMsgIds).Error; err != nil {
+		return err
+	}
+
+	if len(orgMsgIds) == 0 {
+		return nil
+	}
+
+	_, err := t.TalkMessageExpireRepo.UpdateByWhere(ctx, map[string]any{
+		"expire_at": gorm.Expr("? + ttl", time.Now().Unix()),
+	}, "org_msg_id in ? and expire_at = 0", orgMsgIds)
+
+	return err
+}
+
+// PurgeExpired 销毁已过期的消息及其附件
+func (t *TalkMessageExpireService) PurgeExpired(ctx context.Context) (int, error) {
+	total := 0
+	for {
+		items, err := t.TalkMessageExpireRepo.FindAllExpired(ctx, time.Now().Unix(), talkExpireBatchSize)
+		if err != nil {
+			return total, err
+		}
+
+		if len(items) > 0 {
+			if err := t.purge(ctx, items); err != nil {
+				return total, err
+			}
+		}
+
+		total += len(items)
+		if len(items) < talkExpireBatchSize {
+			return total, nil
+		}
+	}
+}
+
+type expireObject struct {
+	Bucket string
+	Path   string
+}
+
+func (t *TalkMessageExpireService) purge(ctx context.Context, items []*model.TalkMessageExpire) error {
+	var (
+		ids        = make([]int, 0, len(items))
+		privateIds = make([]string, 0)
+		groupIds   = make([]string, 0)
+		orgMsgIds  = make([]string, 0, len(items))
+		objects    = make(map[expireObject]string)
+	)
+
+	for _, item := range items {
+		ids = append(ids, item.Id)
+		orgMsgIds = append(orgMsgIds, item.OrgMsgId)
+
+		if item.TalkMode == entity.ChatGroupMode {
+			groupIds = append(groupIds, item.MsgId)
+		} else {
+			privateIds = append(privateIds, item.MsgId)
+		}
+	}
+
+	err := t.Source.Db().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(privateIds) > 0 {
+			var records []*model.TalkUserMessage
+			if err := tx.Select("msg_type", "extra").Where("msg_id in ?", privateIds).Find(&records).Error; err != nil {
+				return err
+			}
+
+			for _, record := range records {
+				t.collectObjects(objects, record.MsgType, record.Extra)
+			}
+
+			if err := tx.Where("msg_id in ?", privateIds).Delete(&model.TalkUserMessage{}).Error; err != nil {
+				return err
+			}
+		}
+
+		if len(groupIds) > 0 {
+			var records []*model.TalkGroupMessage
+			if err := tx.Select("msg_type", "extra").Where("msg_id in ?", groupIds).Find(&records).Error; err != nil {
+				return err
+			}
+
+			for _, record := range records {
+				t.collectObjects(objects, record.MsgType, record.Extra)
+			}
+
+			if err := tx.Where("msg_id in ?", groupIds).Delete(&model.TalkGroupMessage{}).Error; err != nil {
+				return err
+			}
+
+			if err := tx.Where("msg_id in ?", groupIds).Delete(&model.TalkGroupMessageDel{}).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Where("msg_id in ?", orgMsgIds).Delete(&model.TalkMessagePin{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Delete(&model.TalkMessageExpire{}, ids).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	for object, rawUrl := range objects {
+		t.deleteObject(ctx, object, rawUrl)
+	}
+
+	t.notifyExpired(ctx, items)
+	return nil
+}
+
+// collectObjects 收集消息引用的文件
+func (t *TalkMessageExpireService) collectObjects(objects map[expireObject]string, msgType int, extra string) {
+	publicUrls := make([]string, 0)
+
+	switch msgType {
+	case entity.ChatMsgTypeImage:
+		var data model.TalkRecordExtraImage
+		if err := jsonutil.Unmarshal(extra, &data); err == nil {
+			publicUrls = append(publicUrls, data.Url)
+		}
+	case entity.ChatMsgTypeAudio:
+		var data model.TalkRecordExtraAudio
+		if err := jsonutil.Unmarshal(extra, &data); err == nil {
+			publicUrls = append(publicUrls, data.Url)
+		}
+	case entity.ChatMsgTypeVideo:
+		var data model.TalkRecordExtraVideo
+		if err := jsonutil.Unmarshal(extra, &data); err == nil {
+			publicUrls = append(publicUrls, data.Url, data.Cover)
+		}
+	case entity.ChatMsgTypeMixed:
+		var data model.TalkRecordExtraMixed
+		if err := jsonutil.Unmarshal(extra, &data); err == nil {
+			for _, item := range data.Items {
+				if item.Type == entity.ChatMsgTypeImage {
+					publicUrls = append(publicUrls, item.Content)
+				}
+			}
+		}
+	case entity.ChatMsgTypeFile:
+		var data model.TalkRecordExtraFile
+		if err := jsonutil.Unmarshal(extra, &data); err == nil && data.Path != "" {
+			objects[expireObject{Bucket: t.Filesystem.BucketPrivateName(), Path: data.Path}] = ""
+		}
+	}
+
+	bucket := t.Filesystem.BucketPublicName()
+	for _, rawUrl := range publicUrls {
+		if path := objectNameFromUrl(bucket, rawUrl); path != "" {
+			objects[expireObject{Bucket: bucket, Path: path}] = rawUrl
+		}
+	}
+}
+
+// deleteObject 删除消息引用的文件
+// 仅删除存储中存在且不再被引用的文件，表情包、转发的消息副本及收藏仍在使用的文件不删除
+func (t *TalkMessageExpireService) deleteObject(ctx context.Context, object expireObject, rawUrl string) {
+	if rawUrl != "" {
+		var count int64
+		if err := t.Source.Db().WithContext(ctx).Model(&model.EmoticonItem{}).Where("url = ?", rawUrl).Count(&count).Error; err != nil || count > 0 {
+			return
+		}
+	}
+
+	if t.isObjectReferenced(ctx, object) {
+		return
+	}
+
+	if _, err := t.Filesystem.Stat(object.Bucket, object.Path); err != nil {
+		return
+	}
+
+	if err := t.Filesystem.Delete(object.Bucket, object.Path); err != nil {
+		logger.Errorf("expired message object %s/%s delete err: %s", object.Bucket, object.Path, err.Error())
+	}
+}
+
+// isObjectReferenced 文件是否仍被其它消息(含归档记录)或收藏引用，查询失败时按已引用处理
+func (t *TalkMessageExpireService) isObjectReferenced(ctx context.Context, object expireObject) bool {
+	tables := []string{
+		model.TalkUserMessage{}.TableName(),
+		model.TalkGroupMessage{}.TableName(),
+		model.TalkFavorite{}.TableName(),
+	}
+
+	for _, talkMode := range []int{entity.ChatPrivateMode, entity.ChatGroupMode} {
+		archives, err := t.TalkMessageArchive.FindTables(ctx, talkMode)
+		if err != nil {
+			return true
+		}
+
+		tables = append(tables, archives...)
+	}
+
+	pattern := "%" + escapeTalkFavoriteLike(object.Path) + "%"
+	for _, table := range tables {
+		var id int
+		err := t.Source.Db().WithContext(ctx).Table(table).Select("id").Where("extra like ?", pattern).Limit(1).Scan(&id).Error
+		if err != nil || id > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// notifyExpired 按会话推送消息销毁通知，并记录删除变更供其它设备同步
+func (t *TalkMessageExpireService) notifyExpired(ctx context.Context, items []*model.TalkMessageExpire) {
+	type talkKey struct {
+		TalkMode int
+		UserId   int
+		ToFromId int
+	}
+
+	keys := make([]talkKey, 0)
+	groups := make(map[talkKey][]string)
+	for _, item := range items {
+		key := talkKey{TalkMode: item.TalkMode, UserId: item.UserId, ToFromId: item.ToFromId}
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+
+		groups[key] = append(groups[key], item.MsgId)
+	}
+
+	for _, key := range keys {
+		if key.TalkMode == entity.ChatGroupMode {
+			t.TalkSyncService.RecordGroupEvent(ctx, key.ToFromId, model.TalkSyncEventDelete, &TalkSyncPayload{MsgIds: groups[key]})
+		} else {
+			t.TalkSyncService.RecordUserEvent(ctx, key.UserId, model.TalkSyncEventDelete, key.TalkMode, key.ToFromId, &TalkSyncPayload{MsgIds: groups[key]})
+		}
+
+		err := t.PushMessage.Push(ctx, entity.ImTopicChat, &entity.SubscribeMessage{
+			Event: entity.SubEventImMessageExpired,
+			Payload: jsonutil.Encode(entity.SubEventImMessageExpiredPayload{
+				TalkMode: key.TalkMode,
+				UserId:   key.UserId,
+				ToFromId: key.ToFromId,
+				MsgIds:   groups[key],
+			}),
+		})
+		if err != nil {
+			logger.Errorf("message expired push error: %s", err.Error())
+		}
+	}
+}
+
+// objectNameFromUrl 从公开文件的访问地址中解析文件路径
+func objectNameFromUrl(bucket string, rawUrl string) string {
+	if rawUrl == "" {
+		return ""
+	}
+
+	uri, err := url.Parse(rawUrl)
+	if err != nil || uri.Host == "" {
+		return ""
+	}
+
+	path := strings.TrimPrefix(uri.Path, "/")
+	path = strings.TrimPrefix(path, bucket+"/")
+
+	return path
+}
+
+// formatExpireTtl 格式化有效时长
+func formatExpireTtl(ttl int) string {
+	switch {
+	case ttl%86400 == 0:
+		return fmt.Sprintf("%d天", ttl/86400)
+	case ttl%3600 == 0:
+		return fmt.Sprintf("%d小时", ttl/3600)
+	case ttl%60 == 0:
+		return fmt.Sprintf("%d分钟", ttl/60)
+	}
+
+	return fmt.Sprintf("%d秒", ttl)
+}
@@ -0,0 +1,42 @@
+package service
+
+import "testing"
+
+func TestObjectNameFromUrl(t *testing.T) {
+	cases := []struct {
+		name   string
+		url    string
+		expect string
+	}{
+		{name: "empty", url: "", expect: ""},
+		{name: "relative", url: "/images/a.png", expect: ""},
+		{name: "path style", url: "http://127.0.0.1:9000/im-public/media/images/a.png", expect: "media/images/a.png"},
+		{name: "host style", url: "https://im-public.cos.ap-shanghai.myqcloud.com/media/images/a.png", expect: "media/images/a.png"},
+		{name: "signed", url: "https://s3.amazonaws.com/im-public/media/a.png?X-Amz-Signature=abc", expect: "media/a.png"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := objectNameFromUrl("im-public", c.url); got != c.expect {
+				t.Errorf("objectNameFromUrl(%q) = %q, want %q", c.url, got, c.expect)
+			}
+		})
+	}
+}
+
+func TestFormatExpireTtl(t *testing.T) {
+	cases := map[int]string{
+		30:     "30秒",
+		90:     "90秒",
+		300:    "5分钟",
+		7200:   "2小时",
+		86400:  "1天",
+		604800: "7天",
+	}
+
+	for ttl, expect := range cases {
+		if got := formatExpireTtl(ttl); got != expect {
+			t.Errorf("formatExpireTtl(%d) = %q, want %q", ttl, got, expect)
+		}
+	}
+}
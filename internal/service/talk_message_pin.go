@@ -0,0 +1,294 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gzydong/go-chat/internal/entity"
+	"github.com/gzydong/go-chat/internal/logic"
+	"github.com/gzydong/go-chat/internal/pkg/jsonutil"
+	"github.com/gzydong/go-chat/internal/pkg/logger"
+	"github.com/gzydong/go-chat/internal/repository/model"
+	"github.com/gzydong/go-chat/internal/repository/repo"
+	"github.com/gzydong/go-chat/internal/service/message"
+	"gorm.io/gorm"
+)
+
+// TalkMessagePinMaxNum 每个会话最多置顶的消息数
+const TalkMessagePinMaxNum = 10
+
+const (
+	TalkMessagePinTypePin   = 1 // 置顶
+	TalkMessagePinTypeUnpin = 2 // 取消置顶
+)
+
+var _ ITalkMessagePinService = (*TalkMessagePinService)(nil)
+
+type TalkMessagePinOption struct {
+	UserId   int    // 操作人
+	TalkMode int    // 对话类型
+	ToFromId int    // 好友ID或群ID
+	MsgId    string // 消息ID(当前用户可见的消息ID)
+}
+
+type TalkMessagePinItem struct {
+	*model.TalkMessageRecord
+	PinnedBy int       // 置顶操作人
+	PinnedAt time.Time // 置顶时间
+}
+
+type ITalkMessagePinService interface {
+	// Pin 置顶消息
+	Pin(ctx context.Context, opt *TalkMessagePinOption) error
+	// Unpin 取消置顶消息
+	Unpin(ctx context.Context, opt *TalkMessagePinOption) error
+	// List 获取会话的置顶消息
+	List(ctx context.Context, uid int, talkMode int, toFromId int) ([]*TalkMessagePinItem, error)
+}
+
+type TalkMessagePinService struct {
+	*repo.Source
+	TalkMessagePinRepo *repo.TalkMessagePin
+	GroupMemberRepo    *repo.GroupMember
+	UserRepo           *repo.Users
+	PushMessage        *logic.PushMessage
+	MessageService     message.IService
+	TalkRecordService  *TalkRecordService
+	GroupPermission    IGroupPermissionService
+}
+
+// Pin 置顶消息
+// 群聊仅群主及管理员可操作，私聊双方均可操作
+func (t *TalkMessagePinService) Pin(ctx context.Context, opt *TalkMessagePinOption) error {
+	msgId, err := t.findPinMsgId(ctx, opt, true)
+	if err != nil {
+		return err
+	}
+
+	userId, toFromId := repo.TalkPairKey(opt.TalkMode, opt.UserId, opt.ToFromId)
+
+	exist, err := t.TalkMessagePinRepo.IsExist(ctx, "talk_mode = ? and user_id = ? and to_from_id = ? and msg_id = ?", opt.TalkMode, userId, toFromId, msgId)
+	if err != nil {
+		return err
+	}
+
+	if exist {
+		return errors.New("消息已置顶")
+	}
+
+	count, err := t.TalkMessagePinRepo.FindCount(ctx, "talk_mode = ? and user_id = ? and to_from_id = ?", opt.TalkMode, userId, toFromId)
+	if err != nil {
+		return err
+	}
+
+	if count >= TalkMessagePinMaxNum {
+		return fmt.Errorf("每个会话最多置顶%d条消息", TalkMessagePinMaxNum)
+	}
+
+	err = t.TalkMessagePinRepo.Create(ctx, &model.TalkMessagePin{
+		TalkMode:  opt.TalkMode,
+		UserId:    userId,
+		ToFromId:  toFromId,
+		MsgId:     msgId,
+		PinnedBy:  opt.UserId,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	t.notify(ctx, opt, msgId, TalkMessagePinTypePin)
+	return nil
+}
+
+// Unpin 取消置顶消息
+func (t *TalkMessagePinService) Unpin(ctx context.Context, opt *TalkMessagePinOption) error {
+	msgId, err := t.findPinMsgId(ctx, opt, false)
+	if err != nil {
+		return err
+	}
+
+	userId, toFromId := repo.TalkPairKey(opt.TalkMode, opt.UserId, opt.ToFromId)
+
+	res := t.Source.Db().WithContext(ctx).
+		Where("talk_mode = ? and user_id = ? and to_from_id = ? and msg_id = ?", opt.TalkMode, userId, toFromId, msgId).
+		Delete(&model.TalkMessagePin{})
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return errors.New("消息未置顶")
+	}
+
+	t.notify(ctx, opt, msgId, TalkMessagePinTypeUnpin)
+	return nil
+}
+
+// List 获取会话的置顶消息，已撤回或已删除的消息不返回
+func (t *TalkMessagePinService) List(ctx context.Context, uid int, talkMode int, toFromId int) ([]*TalkMessagePinItem, error) {
+	if talkMode == entity.ChatGroupMode && !t.GroupMemberRepo.IsMember(ctx, toFromId, uid, true) {
+		return nil, entity.ErrPermissionDenied
+	}
+
+	pins, err := t.TalkMessagePinRepo.FindAllByTalk(ctx, talkMode, uid, toFromId)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(pins) == 0 {
+		return make([]*TalkMessagePinItem, 0), nil
+	}
+
+	msgIds := make([]string, 0, len(pins))
+	for _, pin := range pins {
+		msgIds = append(msgIds, pin.MsgId)
+	}
+
+	type pinRecord struct {
+		model.TalkMessageRecord
+		OrgMsgId string
+	}
+
+	fields := []string{"msg_id", "sequence", "msg_type", "is_revoked", "extra", "quote", "send_time", "from_id"}
+
+	// 置顶的消息可能已归档，未查到全部消息时继续查询归档表
+	records := make([]*pinRecord, 0, len(msgIds))
+	err = t.TalkRecordService.TalkMessageArchive.Scan(ctx, talkMode, func(tx *gorm.DB) (bool, error) {
+		var list []*pinRecord
+		if talkMode == entity.ChatGroupMode {
+			tx = tx.Select(append(fields, "msg_id as org_msg_id")).
+				Where("group_id = ? and msg_id in ? and is_revoked = ?", toFromId, msgIds, model.No).
+				Where("msg_id not in (?)", t.Source.Db().Table("talk_group_message_del").Select("msg_id").Where("user_id = ? and group_id = ?", uid, toFromId))
+		} else {
+			tx = tx.Select(append(fields, "org_msg_id")).
+				Where("user_id = ? and to_from_id = ? and org_msg_id in ?", uid, toFromId, msgIds).
+				Where("is_revoked = ? and is_deleted = ?", model.No, model.No)
+		}
+
+		if err := tx.Scan(&list).Error; err != nil {
+			return false, err
+		}
+
+		records = append(records, list...)
+		return len(records) >= len(msgIds), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	hash := make(map[string]*model.TalkMessageRecord)
+	for _, record := range records {
+		record.TalkMode = talkMode
+		record.ToFromId = toFromId
+		hash[record.OrgMsgId] = &record.TalkMessageRecord
+	}
+
+	items := make([]*TalkMessagePinItem, 0, len(pins))
+	list := make([]*model.TalkMessageRecord, 0, len(pins))
+	for _, pin := range pins {
+		record, ok := hash[pin.MsgId]
+		if !ok {
+			continue
+		}
+
+		list = append(list, record)
+		items = append(items, &TalkMessagePinItem{
+			TalkMessageRecord: record,
+			PinnedBy:          pin.PinnedBy,
+			PinnedAt:          pin.CreatedAt,
+		})
+	}
+
+	if _, err := t.TalkRecordService.handleTalkRecords(ctx, list); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// findPinMsgId 校验操作权限，返回置顶记录使用的消息ID
+func (t *TalkMessagePinService) findPinMsgId(ctx context.Context, opt *TalkMessagePinOption, checkRevoked bool) (string, error) {
+	archive := t.TalkRecordService.TalkMessageArchive
+
+	switch opt.TalkMode {
+	case entity.ChatPrivateMode:
+		var record model.TalkUserMessage
+
+		err := archive.Take(ctx, opt.TalkMode, &record, "user_id = ? and to_from_id = ? and msg_id = ?", opt.UserId, opt.ToFromId, opt.MsgId)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return "", errors.New("消息ID不存在")
+			}
+
+			return "", err
+		}
+
+		if checkRevoked && record.IsRevoked == model.Yes {
+			return "", errors.New("消息已撤回")
+		}
+
+		return record.OrgMsgId, nil
+	case entity.ChatGroupMode:
+		if err := t.GroupPermission.Check(ctx, opt.ToFromId, opt.UserId, model.GroupPermPin); err != nil {
+			return "", err
+		}
+
+		var record model.TalkGroupMessage
+
+		err := archive.Take(ctx, opt.TalkMode, &record, "group_id = ? and msg_id = ?", opt.ToFromId, opt.MsgId)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return "", errors.New("消息ID不存在")
+			}
+
+			return "", err
+		}
+
+		if checkRevoked && record.IsRevoked == model.Yes {
+			return "", errors.New("消息已撤回")
+		}
+
+		return record.MsgId, nil
+	}
+
+	return "", errors.New("暂不支持置顶消息")
+}
+
+// notify 推送置顶变更，群聊同时记录系统消息
+func (t *TalkMessagePinService) notify(ctx context.Context, opt *TalkMessagePinOption, msgId string, pinType int) {
+	err := t.PushMessage.Push(ctx, entity.ImTopicChat, &entity.SubscribeMessage{
+		Event: entity.SubEventImMessagePin,
+		Payload: jsonutil.Encode(entity.SubEventImMessagePinPayload{
+			TalkMode: opt.TalkMode,
+			UserId:   opt.UserId,
+			ToFromId: opt.ToFromId,
+			MsgId:    msgId,
+			Type:     pinType,
+		}),
+	})
+	if err != nil {
+		logger.Errorf("message pin push error: %s", err.Error())
+	}
+
+	if opt.TalkMode != entity.ChatGroupMode {
+		return
+	}
+
+	nickname := "管理员"
+	if user, _ := t.UserRepo.FindByIdWithCache(ctx, opt.UserId); user != nil {
+		nickname = user.Nickname
+	}
+
+	content := fmt.Sprintf("【%s】置顶了一条消息", nickname)
+	if pinType == TalkMessagePinTypeUnpin {
+		content = fmt.Sprintf("【%s】取消置顶了一条消息", nickname)
+	}
+
+	_ = t.MessageService.CreateGroupSysMessage(ctx, message.CreateGroupSysMessageOption{
+		GroupId: opt.ToFromId,
+		Content: content,
+	})
+}
@@ -1,8 +1,9 @@
 package service
 
 import (
+	"cmp"
 	"context"
-	"errors"
+	"slices"
 
 	"github.com/gzydong/go-chat/internal/entity"
 	"github.com/gzydong/go-chat/internal/pkg/sliceutil"
@@ -21,6 +22,10 @@ type ITalkRecordService interface {
 	FindTalkGroupRecord(ctx context.Context, msgId string) (*model.TalkMessageRecord, error)
 	FindAllTalkRecords(ctx context.Context, opt *FindAllTalkRecordsOpt) ([]*model.TalkMessageRecord, error)
 	FindForwardRecords(ctx context.Context, uid int, msgIds []string, talkType int) ([]*model.TalkMessageRecord, error)
+	// FindThreadRecords 获取话题回复消息
+	FindThreadRecords(ctx context.Context, opt *FindThreadRecordsOpt) ([]*model.TalkMessageRecord, error)
+	// FindThreadSummaries 批量获取根消息的话题回复汇总
+	FindThreadSummaries(ctx context.Context, groupId int, rootMsgIds []string) ([]*model.TalkGroupThread, error)
 }
 
 type TalkRecordService struct {
@@ -31,6 +36,8 @@ type TalkRecordService struct {
 	TalkRecordFriendRepo  *repo.TalkUserMessage
 	TalkRecordGroupRepo   *repo.TalkGroupMessage
 	TalkRecordsDeleteRepo *repo.TalkGroupMessageDel
+	TalkGroupThreadRepo   *repo.TalkGroupThread
+	TalkMessageArchive    *repo.TalkMessageArchive
 }
 
 func (s *TalkRecordService) FindPrivateRecordByMsgId(ctx context.Context, msgId string) (*model.TalkUserMessage, error) {
@@ -53,8 +60,8 @@ type FindAllTalkRecordsOpt struct {
 }
 
 func (s *TalkRecordService) FindTalkPrivateRecord(ctx context.Context, uid int, msgId string) (*model.TalkMessageRecord, error) {
-	talkRecordFriendInfo, err := s.TalkRecordFriendRepo.FindByWhere(ctx, "msg_id = ? and user_id = ?", msgId, uid)
-	if err != nil {
+	talkRecordFriendInfo := &model.TalkUserMessage{}
+	if err := s.TalkMessageArchive.Take(ctx, entity.ChatPrivateMode, talkRecordFriendInfo, "msg_id = ? and user_id = ?", msgId, uid); err != nil {
 		return nil, err
 	}
 
@@ -82,15 +89,11 @@ func (s *TalkRecordService) FindTalkPrivateRecord(ctx context.Context, uid int,
 }
 
 func (s *TalkRecordService) FindTalkGroupRecord(ctx context.Context, msgId string) (*model.TalkMessageRecord, error) {
-	talkRecordGroupInfo, err := s.TalkRecordGroupRepo.FindByMsgId(ctx, msgId)
-	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+	talkRecordGroupInfo := &model.TalkGroupMessage{}
+	if err := s.TalkMessageArchive.Take(ctx, entity.ChatGroupMode, talkRecordGroupInfo, "msg_id = ?", msgId); err != nil {
 		return nil, err
 	}
 
-	if talkRecordGroupInfo == nil {
-		return nil, gorm.ErrRecordNotFound
-	}
-
 	record := &model.TalkMessageRecord{
 		TalkMode:  entity.ChatGroupMode,
 		FromId:    talkRecordGroupInfo.FromId,
@@ -115,15 +118,22 @@ func (s *TalkRecordService) FindTalkGroupRecord(ctx context.Context, msgId strin
 }
 
 // FindAllTalkRecords 获取所有对话消息
+// 当前消息表查询完后按月份倒序继续查询归档表，归档记录的时序ID均小于当前消息表中的记录
 func (s *TalkRecordService) FindAllTalkRecords(ctx context.Context, opt *FindAllTalkRecordsOpt) ([]*model.TalkMessageRecord, error) {
 	var (
-		items  = make([]*model.TalkMessageRecord, 0, opt.Limit)
-		cursor = opt.Cursor
+		items    = make([]*model.TalkMessageRecord, 0, opt.Limit)
+		cursor   = opt.Cursor
+		tables   = []string{"talk_user_message"}
+		archived = false
 	)
 
-	for {
+	if opt.TalkType == entity.ChatGroupMode {
+		tables[0] = "talk_group_message"
+	}
+
+	for i := 0; i < len(tables); {
 		// 这里查询数据放弃了关联查询，所以这里需要查询多次，防止查询中存在用户已删除的数据需要过滤掉
-		list, err := s.findAllRecords(ctx, &FindAllTalkRecordsOpt{
+		list, err := s.findAllRecords(ctx, tables[i], &FindAllTalkRecordsOpt{
 			TalkType:   opt.TalkType,
 			UserId:     opt.UserId,
 			ReceiverId: opt.ReceiverId,
@@ -136,11 +146,7 @@ func (s *TalkRecordService) FindAllTalkRecords(ctx context.Context, opt *FindAll
 			return nil, err
 		}
 
-		if len(list) == 0 {
-			break
-		}
-
-		if opt.TalkType == entity.ChatGroupMode {
+		if opt.TalkType == entity.ChatGroupMode && len(list) > 0 {
 			tmpMsgIds := make([]string, 0, len(list))
 			for _, v := range list {
 				tmpMsgIds = append(tmpMsgIds, v.MsgId)
@@ -167,12 +173,30 @@ func (s *TalkRecordService) FindAllTalkRecords(ctx context.Context, opt *FindAll
 			items = append(items, list...)
 		}
 
-		if len(items) >= opt.Limit || len(list) < opt.Limit {
+		if len(items) >= opt.Limit {
 			break
 		}
 
-		// 设置游标继续往下执行
-		cursor = list[len(list)-1].Sequence
+		if len(list) > 0 {
+			// 设置游标继续往下执行
+			cursor = list[len(list)-1].Sequence
+		}
+
+		if len(list) < opt.Limit {
+			// 当前表已查询完，继续查询归档表
+			if !archived {
+				archived = true
+
+				archives, err := s.TalkMessageArchive.FindTables(ctx, opt.TalkType)
+				if err != nil {
+					return nil, err
+				}
+
+				tables = append(tables, archives...)
+			}
+
+			i++
+		}
 	}
 
 	if len(items) > opt.Limit {
@@ -182,8 +206,8 @@ func (s *TalkRecordService) FindAllTalkRecords(ctx context.Context, opt *FindAll
 	return s.handleTalkRecords(ctx, items)
 }
 
-func (s *TalkRecordService) findAllRecords(ctx context.Context, opt *FindAllTalkRecordsOpt) ([]*model.TalkMessageRecord, error) {
-	query := s.Source.Db().WithContext(ctx)
+func (s *TalkRecordService) findAllRecords(ctx context.Context, table string, opt *FindAllTalkRecordsOpt) ([]*model.TalkMessageRecord, error) {
+	query := s.Source.Db().WithContext(ctx).Table(table)
 
 	fields := []string{
 		"msg_id",
@@ -197,13 +221,12 @@ func (s *TalkRecordService) findAllRecords(ctx context.Context, opt *FindAllTalk
 	}
 
 	if opt.TalkType == 1 {
-		query = query.Table("talk_user_message")
 		query.Where("user_id = ?", opt.UserId)
 		query.Where("to_from_id = ?", opt.ReceiverId)
 		query.Where("is_deleted = ?", model.No)
 	} else {
-		query = query.Table("talk_group_message")
 		query.Where("group_id = ?", opt.ReceiverId)
+		query.Where("root_msg_id = ?", "") // 话题回复不出现在群聊主时间线
 	}
 
 	query.Select(fields)
@@ -244,26 +267,81 @@ func (s *TalkRecordService) FindForwardRecords(ctx context.Context, uid int, msg
 			"send_time",
 			"from_id",
 		}
-		items     = make([]*model.TalkMessageRecord, 0)
-		tableName = "talk_user_message"
+		items = make([]*model.TalkMessageRecord, 0)
 	)
 
-	if talkType == 2 {
-		tableName = "talk_group_message"
+	err := s.TalkMessageArchive.Scan(ctx, talkType, func(tx *gorm.DB) (bool, error) {
+		var list []*model.TalkMessageRecord
+		if err := tx.Select(fields).Where("msg_id in ?", msgIds).Scan(&list).Error; err != nil {
+			return false, err
+		}
+
+		items = append(items, list...)
+		return len(items) >= len(msgIds), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(items, func(a, b *model.TalkMessageRecord) int {
+		return cmp.Compare(a.Sequence, b.Sequence)
+	})
+
+	return s.handleTalkRecords(ctx, items)
+}
+
+type FindThreadRecordsOpt struct {
+	UserId    int    // 获取消息的用户
+	GroupId   int    // 群ID
+	RootMsgId string // 话题根消息ID
+	Cursor    int    // 上次查询的游标
+	Limit     int    // 数据行数
+}
+
+// FindThreadRecords 获取话题回复消息，按发送顺序正序返回
+func (s *TalkRecordService) FindThreadRecords(ctx context.Context, opt *FindThreadRecordsOpt) ([]*model.TalkMessageRecord, error) {
+	fields := []string{
+		"msg_id",
+		"sequence",
+		"msg_type",
+		"is_revoked",
+		"extra",
+		"quote",
+		"send_time",
+		"from_id",
 	}
 
-	query := s.Source.Db().Table(tableName)
+	deleted := s.Source.Db().Table("talk_group_message_del").Select("msg_id").Where("user_id = ? and group_id = ?", opt.UserId, opt.GroupId)
+
+	query := s.Source.Db().WithContext(ctx).Table("talk_group_message")
 	query.Select(fields)
-	query.Where("msg_id in ?", msgIds)
-	query.Order("sequence asc")
+	query.Where("group_id = ? and root_msg_id = ?", opt.GroupId, opt.RootMsgId)
+	query.Where("msg_id not in (?)", deleted)
+
+	if opt.Cursor > 0 {
+		query.Where("sequence > ?", opt.Cursor)
+	}
+
+	query.Order("sequence asc").Limit(opt.Limit)
 
+	var items []*model.TalkMessageRecord
 	if err := query.Scan(&items).Error; err != nil {
 		return nil, err
 	}
 
+	for i := 0; i < len(items); i++ {
+		items[i].TalkMode = entity.ChatGroupMode
+		items[i].ToFromId = opt.GroupId
+	}
+
 	return s.handleTalkRecords(ctx, items)
 }
 
+// FindThreadSummaries 批量获取根消息的话题回复汇总
+func (s *TalkRecordService) FindThreadSummaries(ctx context.Context, groupId int, rootMsgIds []string) ([]*model.TalkGroupThread, error) {
+	return s.TalkGroupThreadRepo.FindByRootMsgIds(ctx, groupId, rootMsgIds)
+}
+
 // HandleTalkRecords 处理消息
 func (s *TalkRecordService) handleTalkRecords(ctx context.Context, items []*model.TalkMessageRecord) ([]*model.TalkMessageRecord, error) {
 	if len(items) == 0 {
@@ -0,0 +1,411 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/gzydong/go-chat/internal/entity"
+	"github.com/gzydong/go-chat/internal/pkg/jsonutil"
+	"github.com/gzydong/go-chat/internal/pkg/logger"
+	"github.com/gzydong/go-chat/internal/pkg/strutil"
+	"github.com/gzydong/go-chat/internal/repository/model"
+	"github.com/gzydong/go-chat/internal/repository/repo"
+	"github.com/gzydong/go-chat/internal/service/message"
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+)
+
+const (
+	TalkScheduleMaxPending = 100                 // 每个用户最多待发送的定时消息数
+	TalkScheduleMaxAhead   = 30 * 24 * time.Hour // 最多可提前设置的时间
+	talkScheduleBatchSize  = 200
+	talkScheduleLease      = 5 * time.Minute // 发送租约时长，超时未完成的任务会被重新发送
+)
+
+var _ ITalkScheduleService = (*TalkScheduleService)(nil)
+
+type TalkScheduleCreateOpt struct {
+	UserId   int
+	TalkMode int
+	ToFromId int
+	MsgType  string // 消息类型(与发送接口的 type 一致)
+	Payload  any    // message.CreateXxxMessage 发送参数
+	SendAt   time.Time
+}
+
+type TalkScheduleListOpt struct {
+	UserId   int
+	Status   int // 0 表示全部
+	Page     int
+	PageSize int
+}
+
+type ITalkScheduleService interface {
+	// IsSupported 消息类型是否支持定时发送
+	IsSupported(msgType string) bool
+	// Create 创建定时消息
+	Create(ctx context.Context, opt *TalkScheduleCreateOpt) (int, error)
+	// List 定时消息列表
+	List(ctx context.Context, opt *TalkScheduleListOpt) (int64, []*model.TalkScheduledMessage, error)
+	// Cancel 取消定时消息
+	Cancel(ctx context.Context, uid int, id int) error
+	// Reschedule 修改发送时间
+	Reschedule(ctx context.Context, uid int, id int, sendAt time.Time) error
+	// DispatchDue 发送已到时间的定时消息，返回处理数量
+	DispatchDue(ctx context.Context) (int, error)
+}
+
+type TalkScheduleService struct {
+	*repo.Source
+	TalkScheduledMessageRepo *repo.TalkScheduledMessage
+	AuthService              IAuthService
+	MessageService           message.IService
+	ModerationService        IModerationService
+	GroupPermission          IGroupPermissionService
+}
+
+type scheduleSender func(ctx context.Context, payload string, patch map[string]any) error
+
+// handlers 定时消息发送方式
+// 文件消息依赖的上传文件会被定时清理，不支持定时发送
+func (t *TalkScheduleService) handlers() map[string]scheduleSender {
+	return map[string]scheduleSender{
+		"text":     scheduleHandler(t.MessageService.CreateTextMessage),
+		"code":     scheduleHandler(t.MessageService.CreateCodeMessage),
+		"image":    scheduleHandler(t.MessageService.CreateImageMessage),
+		"voice":    scheduleHandler(t.MessageService.CreateVoiceMessage),
+		"video":    scheduleHandler(t.MessageService.CreateVideoMessage),
+		"location": scheduleHandler(t.MessageService.CreateLocationMessage),
+		"emoticon": scheduleHandler(t.MessageService.CreateEmoticonMessage),
+		"card":     scheduleHandler(t.MessageService.CreateBusinessCardMessage),
+		"mixed":    scheduleHandler(t.MessageService.CreateMixedMessage),
+	}
+}
+
+// scheduleHandler 解析发送参数，patch 中的字段会覆盖保存的发送参数(如发送时生成的消息ID)
+func scheduleHandler[T any](fn func(ctx context.Context, option T) error) scheduleSender {
+	return func(ctx context.Context, payload string, patch map[string]any) error {
+		var option T
+		if err := jsonutil.Unmarshal(payload, &option); err != nil {
+			return err
+		}
+
+		if err := jsonutil.Unmarshal(jsonutil.Encode(patch), &option); err != nil {
+			return err
+		}
+
+		return fn(ctx, option)
+	}
+}
+
+func (t *TalkScheduleService) IsSupported(msgType string) bool {
+	_, ok := t.handlers()[msgType]
+	return ok
+}
+
+func (t *TalkScheduleService) Create(ctx context.Context, opt *TalkScheduleCreateOpt) (int, error) {
+	if !t.IsSupported(opt.MsgType) {
+		return 0, errors.New("该消息类型不支持定时发送")
+	}
+
+	if err := checkScheduleSendAt(opt.SendAt); err != nil {
+		return 0, err
+	}
+
+	count, err := t.TalkScheduledMessageRepo.FindCount(ctx, "user_id = ? and status = ?", opt.UserId, model.TalkScheduledStatusPending)
+	if err != nil {
+		return 0, err
+	}
+
+	if count >= TalkScheduleMaxPending {
+		return 0, fmt.Errorf("待发送的定时消息不能超过%d条", TalkScheduleMaxPending)
+	}
+
+	now := time.Now()
+	data := &model.TalkScheduledMessage{
+		UserId:    opt.UserId,
+		TalkMode:  opt.TalkMode,
+		ToFromId:  opt.ToFromId,
+		MsgType:   opt.MsgType,
+		Payload:   jsonutil.Encode(opt.Payload),
+		SendAt:    opt.SendAt,
+		Status:    model.TalkScheduledStatusPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := t.TalkScheduledMessageRepo.Create(ctx, data); err != nil {
+		return 0, err
+	}
+
+	return data.Id, nil
+}
+
+func (t *TalkScheduleService) List(ctx context.Context, opt *TalkScheduleListOpt) (int64, []*model.TalkScheduledMessage, error) {
+	return t.TalkScheduledMessageRepo.Pagination(ctx, opt.Page, opt.PageSize, func(tx *gorm.DB) *gorm.DB {
+		tx = tx.Where("user_id = ?", opt.UserId)
+		if opt.Status > 0 {
+			tx = tx.Where("status = ?", opt.Status)
+		}
+
+		return tx.Order("send_at desc, id desc")
+	})
+}
+
+func (t *TalkScheduleService) Cancel(ctx context.Context, uid int, id int) error {
+	if _, err := t.findPending(ctx, uid, id); err != nil {
+		return err
+	}
+
+	ok, err := t.TalkScheduledMessageRepo.UpdateStatus(ctx, id, model.TalkScheduledStatusPending, map[string]any{
+		"status": model.TalkScheduledStatusCanceled,
+	})
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return errors.New("定时消息已发送或已取消")
+	}
+
+	return nil
+}
+
+func (t *TalkScheduleService) Reschedule(ctx context.Context, uid int, id int, sendAt time.Time) error {
+	if err := checkScheduleSendAt(sendAt); err != nil {
+		return err
+	}
+
+	if _, err := t.findPending(ctx, uid, id); err != nil {
+		return err
+	}
+
+	ok, err := t.TalkScheduledMessageRepo.UpdateStatus(ctx, id, model.TalkScheduledStatusPending, map[string]any{
+		"send_at": sendAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return errors.New("定时消息已发送或已取消")
+	}
+
+	return nil
+}
+
+// DispatchDue 发送已到时间的定时消息
+// 发送前重新校验发送权限(如已被禁言、已退群、已解除好友关系)、@所有人权限及防刷屏限制，校验失败记为发送失败
+func (t *TalkScheduleService) DispatchDue(ctx context.Context) (int, error) {
+	handlers := t.handlers()
+
+	total := 0
+	for {
+		items, err := t.TalkScheduledMessageRepo.FindAllDue(ctx, time.Now(), talkScheduleLease, talkScheduleBatchSize)
+		if err != nil {
+			return total, err
+		}
+
+		for _, item := range items {
+			// 抢占任务，避免多个进程重复发送；消息ID在发送时生成，不使用客户端提交的消息ID
+			msgId := lo.Ternary(item.MsgId == "", strutil.NewMsgId(), item.MsgId)
+
+			ok, err := t.TalkScheduledMessageRepo.Claim(ctx, item, msgId, talkScheduleLease)
+			if err != nil {
+				return total, err
+			}
+
+			if !ok {
+				continue
+			}
+
+			total++
+			item.MsgId = msgId
+
+			status, reason := model.TalkScheduledStatusSent, ""
+			if err := t.dispatch(ctx, handlers, item); err != nil {
+				status, reason = model.TalkScheduledStatusFailed, strutil.MtSubstr(err.Error(), 0, 255)
+				logger.Errorf("scheduled message %d dispatch err: %s", item.Id, err.Error())
+			}
+
+			_, err = t.TalkScheduledMessageRepo.UpdateStatus(ctx, item.Id, model.TalkScheduledStatusSending, map[string]any{
+				"status":      status,
+				"fail_reason": reason,
+			})
+			if err != nil {
+				return total, err
+			}
+		}
+
+		if len(items) < talkScheduleBatchSize {
+			return total, nil
+		}
+	}
+}
+
+// dispatch 发送定时消息，发送时重新校验发送权限、@所有人权限、防刷屏限制及内容审核
+func (t *TalkScheduleService) dispatch(ctx context.Context, handlers map[string]scheduleSender, item *model.TalkScheduledMessage) error {
+	call, ok := handlers[item.MsgType]
+	if !ok {
+		return errors.New("该消息类型不支持定时发送")
+	}
+
+	// 重新抢占的任务可能已发送成功，按消息ID判断避免重复发送
+	if sent, err := t.isSent(ctx, item); err != nil || sent {
+		return err
+	}
+
+	content := scheduleContent(item.Payload)
+
+	auth := &AuthOption{
+		TalkType:          item.TalkMode,
+		UserId:            item.UserId,
+		ToFromId:          item.ToFromId,
+		IsVerifyGroupMute: true,
+		IsVerifyAntiSpam:  true,
+		MsgType:           item.MsgType,
+		Content:           content,
+	}
+
+	if err := t.AuthService.IsAuth(ctx, auth); err != nil {
+		return err
+	}
+
+	// @所有人的权限可能在设置定时消息后被收回
+	if item.TalkMode == entity.ChatGroupMode && lo.Contains(scheduleMentions(item.Payload), model.MentionAll) {
+		if err := t.GroupPermission.Check(ctx, item.ToFromId, item.UserId, model.GroupPermMentionAll); err != nil {
+			return err
+		}
+	}
+
+	patch := map[string]any{"msg_id": item.MsgId}
+
+	if item.MsgType != "text" {
+		if err := call(ctx, item.Payload, patch); err != nil {
+			return err
+		}
+
+		t.AuthService.RecordSend(ctx, auth)
+		return nil
+	}
+
+	moderation, err := t.ModerationService.Check(ctx, &ModerationCheckOpt{
+		Scene:   model.ModerationSceneMessage,
+		UserId:  item.UserId,
+		Content: content,
+	})
+	if err != nil {
+		return err
+	}
+
+	patch["content"] = html.EscapeString(moderation.Content)
+
+	if err := call(ctx, item.Payload, patch); err != nil {
+		return err
+	}
+
+	t.AuthService.RecordSend(ctx, auth)
+
+	t.ModerationService.Flag(ctx, &ModerationFlagOpt{
+		Scene:    model.ModerationSceneMessage,
+		UserId:   item.UserId,
+		TalkMode: item.TalkMode,
+		ToFromId: item.ToFromId,
+		MsgId:    item.MsgId,
+		Result:   moderation,
+	})
+
+	return nil
+}
+
+// isSent 判断定时消息是否已按发送时生成的消息ID写入
+func (t *TalkScheduleService) isSent(ctx context.Context, item *model.TalkScheduledMessage) (bool, error) {
+	var count int64
+
+	db := t.Source.Db().WithContext(ctx)
+	if item.TalkMode == entity.ChatGroupMode {
+		db = db.Model(&model.TalkGroupMessage{}).Where("msg_id = ?", item.MsgId)
+	} else {
+		db = db.Model(&model.TalkUserMessage{}).Where("user_id = ? and msg_id = ?", item.UserId, item.MsgId)
+	}
+
+	if err := db.Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (t *TalkScheduleService) findPending(ctx context.Context, uid int, id int) (*model.TalkScheduledMessage, error) {
+	item, err := t.TalkScheduledMessageRepo.FindByWhere(ctx, "id = ? and user_id = ?", id, uid)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("定时消息不存在")
+		}
+
+		return nil, err
+	}
+
+	if item.Status != model.TalkScheduledStatusPending {
+		return nil, errors.New("定时消息已发送或已取消")
+	}
+
+	return item, nil
+}
+
+func checkScheduleSendAt(sendAt time.Time) error {
+	now := time.Now()
+
+	if !sendAt.After(now) {
+		return errors.New("定时发送时间必须晚于当前时间")
+	}
+
+	if sendAt.After(now.Add(TalkScheduleMaxAhead)) {
+		return errors.New("定时发送时间不能超过30天")
+	}
+
+	return nil
+}
+
+// scheduleContent 提取发送参数中的文本内容，用于发送时的防刷屏校验及内容审核
+func scheduleContent(payload string) string {
+	var option struct {
+		Content     string `json:"content"`
+		Code        string `json:"code"`
+		MessageList []struct {
+			Type    int    `json:"type"`
+			Content string `json:"content"`
+		} `json:"message_list"`
+	}
+
+	if err := jsonutil.Unmarshal(payload, &option); err != nil {
+		return ""
+	}
+
+	contents := []string{option.Content, option.Code}
+	for _, item := range option.MessageList {
+		if item.Type == entity.ChatMsgTypeText {
+			contents = append(contents, item.Content)
+		}
+	}
+
+	// 文本消息保存时已转义
+	return html.UnescapeString(strings.Join(lo.Compact(contents), "\n"))
+}
+
+// scheduleMentions 提取定时消息中 @ 的用户ID
+func scheduleMentions(payload string) []int {
+	var option struct {
+		Mentions []int `json:"mentions"`
+	}
+
+	if err := jsonutil.Unmarshal(payload, &option); err != nil {
+		return nil
+	}
+
+	return option.Mentions
+}
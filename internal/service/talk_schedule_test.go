@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gzydong/go-chat/internal/pkg/jsonutil"
+	"github.com/gzydong/go-chat/internal/service/message"
+)
+
+func TestScheduleHandlerPatch(t *testing.T) {
+	var got message.CreateTextMessage
+
+	send := scheduleHandler(func(_ context.Context, option message.CreateTextMessage) error {
+		got = option
+		return nil
+	})
+
+	payload := jsonutil.Encode(message.CreateTextMessage{MsgId: "client-msg-id", ToFromId: 2, Content: "hello"})
+	if err := send(context.Background(), payload, map[string]any{"msg_id": "dispatch-msg-id"}); err != nil {
+		t.Fatalf("scheduleHandler() error = %v", err)
+	}
+
+	if got.MsgId != "dispatch-msg-id" || got.Content != "hello" || got.ToFromId != 2 {
+		t.Errorf("scheduleHandler() option = %+v, want msg_id replaced only", got)
+	}
+}
+
+func TestScheduleContent(t *testing.T) {
+	cases := []struct {
+		name    string
+		payload string
+		want    string
+	}{
+		{name: "text", payload: jsonutil.Encode(message.CreateTextMessage{Content: "a &amp; b"}), want: "a & b"},
+		{name: "code", payload: jsonutil.Encode(message.CreateCodeMessage{Code: "fmt.Println()"}), want: "fmt.Println()"},
+		{name: "mixed", payload: jsonutil.Encode(message.CreateMixedMessage{MessageList: []message.CreateMixedMessageItem{
+			{Type: 1, Content: "hi"},
+			{Type: 3, Content: "https://example.com/a.png"},
+		}}), want: "hi"},
+		{name: "image", payload: jsonutil.Encode(message.CreateImageMessage{Url: "https://example.com/a.png"}), want: ""},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := scheduleContent(c.payload); got != c.want {
+				t.Errorf("scheduleContent() = %q, want %q", got, c.want)
+			}
+		})
+	}
+}
+
+func TestScheduleMentions(t *testing.T) {
+	payload := jsonutil.Encode(message.CreateTextMessage{Content: "@所有人", Mentions: []int{0, 2}})
+	if got := scheduleMentions(payload); len(got) != 2 || got[0] != 0 || got[1] != 2 {
+		t.Errorf("scheduleMentions() = %v, want [0 2]", got)
+	}
+
+	if got := scheduleMentions(jsonutil.Encode(message.CreateImageMessage{Url: "https://example.com/a.png"})); len(got) != 0 {
+		t.Errorf("scheduleMentions() = %v, want empty", got)
+	}
+}
@@ -0,0 +1,385 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gzydong/go-chat/internal/entity"
+	"github.com/gzydong/go-chat/internal/pkg/logger"
+	"github.com/gzydong/go-chat/internal/pkg/search"
+	"github.com/gzydong/go-chat/internal/repository/model"
+	"github.com/gzydong/go-chat/internal/repository/repo"
+	"github.com/gzydong/go-chat/internal/service/message"
+	"gorm.io/gorm"
+)
+
+const (
+	searchSyncBatchSize = 1000
+	searchMaxRounds     = 5
+	searchFlushInterval = time.Minute
+	searchSyncInterval  = 5 * time.Second
+)
+
+var _ ITalkSearchService = (*TalkSearchService)(nil)
+
+type ITalkSearchService interface {
+	// Run 后台定时同步索引，直到 ctx 结束
+	Run(ctx context.Context)
+	// Sync 增量同步消息到检索引擎
+	Sync(ctx context.Context) error
+	// Search 检索当前用户可见的聊天记录
+	Search(ctx context.Context, opt *SearchTalkRecordsOpt) (*SearchTalkRecordsResult, error)
+}
+
+type SearchTalkRecordsOpt struct {
+	UserId    int       // 检索用户
+	Keyword   string    // 关键词
+	TalkMode  int       // 对话类型，0 表示不限
+	ToFromId  int       // 会话对象(好友ID或者群ID)
+	FromId    int       // 发送者
+	MsgTypes  []int     // 消息类型
+	StartTime time.Time // 发送时间起
+	EndTime   time.Time // 发送时间止
+	Cursor    string    // 上次查询的游标
+	Limit     int       // 数据行数
+}
+
+type SearchTalkRecordsResult struct {
+	Items  []*model.TalkMessageRecord
+	Cursor string // 下一页游标，为空表示没有更多数据
+}
+
+type TalkSearchService struct {
+	*repo.Source
+	Engine            search.IEngine
+	GroupMemberRepo   *repo.GroupMember
+	TalkRecordService *TalkRecordService
+}
+
+var (
+	// 同一进程内只允许一个同步任务，避免重复写入位点
+	searchSyncMutex sync.Mutex
+	searchFlushAt   time.Time
+	searchUnflushed int
+)
+
+type searchRecord struct {
+	Id        int64     `gorm:"column:id"`
+	MsgId     string    `gorm:"column:msg_id"`
+	Sequence  int64     `gorm:"column:sequence"`
+	MsgType   int       `gorm:"column:msg_type"`
+	UserId    int       `gorm:"column:user_id"`
+	ToFromId  int       `gorm:"column:to_from_id"`
+	FromId    int       `gorm:"column:from_id"`
+	IsRevoked int       `gorm:"column:is_revoked"`
+	IsDeleted int       `gorm:"column:is_deleted"`
+	Extra     string    `gorm:"column:extra"`
+	SendTime  time.Time `gorm:"column:send_time"`
+}
+
+// Run 定时增量同步索引，检索请求只查询索引不做同步
+// 退出前将未落盘的索引写入磁盘
+func (s *TalkSearchService) Run(ctx context.Context) {
+	ticker := time.NewTicker(searchSyncInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Sync(ctx); err != nil && ctx.Err() == nil {
+			logger.Errorf("TalkSearchService Sync err: %s", err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			if err := s.flush(context.Background()); err != nil {
+				logger.Errorf("TalkSearchService Flush err: %s", err.Error())
+			}
+
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sync 按自增ID增量同步私聊及群聊消息
+// 消息内容创建后不会变化，撤回及删除状态在检索时以数据库为准
+func (s *TalkSearchService) Sync(ctx context.Context) error {
+	searchSyncMutex.Lock()
+	defer searchSyncMutex.Unlock()
+
+	for _, talkMode := range []int{entity.ChatPrivateMode, entity.ChatGroupMode} {
+		num, err := s.sync(ctx, talkMode)
+		if err != nil {
+			return err
+		}
+
+		searchUnflushed += num
+	}
+
+	// 索引快照为全量写入，限制写盘频率
+	if time.Since(searchFlushAt) < searchFlushInterval {
+		return nil
+	}
+
+	return s.flushLocked(ctx)
+}
+
+func (s *TalkSearchService) flush(ctx context.Context) error {
+	searchSyncMutex.Lock()
+	defer searchSyncMutex.Unlock()
+
+	return s.flushLocked(ctx)
+}
+
+func (s *TalkSearchService) flushLocked(ctx context.Context) error {
+	if searchUnflushed == 0 {
+		return nil
+	}
+
+	if err := s.Engine.Flush(ctx); err != nil {
+		return err
+	}
+
+	searchFlushAt = time.Now()
+	searchUnflushed = 0
+
+	return nil
+}
+
+func (s *TalkSearchService) sync(ctx context.Context, talkMode int) (int, error) {
+	table := "talk_user_message"
+	fields := "id,msg_id,sequence,msg_type,user_id,to_from_id,from_id,is_revoked,is_deleted,extra,send_time"
+	if talkMode == entity.ChatGroupMode {
+		table = "talk_group_message"
+		fields = "id,msg_id,sequence,msg_type,0 as user_id,group_id as to_from_id,from_id,is_revoked,extra,send_time"
+	}
+
+	checkpoint, err := s.Engine.Checkpoint(ctx, table)
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for {
+		items := make([]*searchRecord, 0)
+		err := s.Source.Db().WithContext(ctx).Table(table).Select(fields).
+			Where("id > ?", checkpoint).Order("id asc").Limit(searchSyncBatchSize).Scan(&items).Error
+		if err != nil {
+			return total, err
+		}
+
+		if len(items) == 0 {
+			return total, nil
+		}
+
+		docs := make([]*search.Document, 0, len(items))
+		for _, item := range items {
+			if item.IsRevoked == model.Yes || item.IsDeleted == model.Yes {
+				continue
+			}
+
+			content := message.SearchText(item.MsgType, item.Extra)
+			if content == "" {
+				continue
+			}
+
+			docs = append(docs, &search.Document{
+				Id:       item.MsgId,
+				TalkMode: talkMode,
+				OwnerId:  item.UserId,
+				ToFromId: item.ToFromId,
+				FromId:   item.FromId,
+				MsgType:  item.MsgType,
+				Content:  content,
+				Sequence: item.Sequence,
+				SendTime: item.SendTime,
+			})
+		}
+
+		if err := s.Engine.Index(ctx, docs...); err != nil {
+			return total, err
+		}
+
+		checkpoint = items[len(items)-1].Id
+		if err := s.Engine.SetCheckpoint(ctx, table, checkpoint); err != nil {
+			return total, err
+		}
+
+		total += len(docs)
+
+		if len(items) < searchSyncBatchSize {
+			return total, nil
+		}
+	}
+}
+
+// Search 检索聊天记录
+// 索引由 Run 在后台同步，新消息可能有数秒延迟
+// 检索结果会再次与数据库核对，过滤已撤回、已删除的消息
+func (s *TalkSearchService) Search(ctx context.Context, opt *SearchTalkRecordsOpt) (*SearchTalkRecordsResult, error) {
+	query := &search.Query{
+		Keyword:   opt.Keyword,
+		UserId:    opt.UserId,
+		GroupIds:  s.GroupMemberRepo.GetUserGroupIds(ctx, opt.UserId),
+		TalkMode:  opt.TalkMode,
+		ToFromId:  opt.ToFromId,
+		FromId:    opt.FromId,
+		MsgTypes:  opt.MsgTypes,
+		StartTime: opt.StartTime,
+		EndTime:   opt.EndTime,
+		Cursor:    opt.Cursor,
+		Limit:     opt.Limit * 2,
+	}
+
+	result := &SearchTalkRecordsResult{Items: make([]*model.TalkMessageRecord, 0, opt.Limit)}
+
+	for round := 0; round < searchMaxRounds; round++ {
+		docs, err := s.Engine.Search(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(docs) == 0 {
+			result.Cursor = ""
+			break
+		}
+
+		records, err := s.findVisibleRecords(ctx, opt.UserId, docs)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, doc := range docs {
+			result.Cursor = doc.Cursor()
+
+			if record, ok := records[doc.Id]; ok {
+				result.Items = append(result.Items, record)
+				if len(result.Items) >= opt.Limit {
+					break
+				}
+			}
+		}
+
+		if len(result.Items) >= opt.Limit {
+			break
+		}
+
+		if len(docs) < query.Limit {
+			result.Cursor = ""
+			break
+		}
+
+		query.Cursor = result.Cursor
+	}
+
+	items, err := s.TalkRecordService.handleTalkRecords(ctx, result.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Items = items
+
+	return result, nil
+}
+
+// searchVisibleRecord 检索结果在数据库中的记录，包含撤回及删除状态
+type searchVisibleRecord struct {
+	model.TalkMessageRecord
+	IsDeleted int
+}
+
+// findVisibleRecords 核对检索结果在数据库中的状态，返回用户可见的消息
+// 消息可能已归档，当前消息表未查到的继续查询归档表
+func (s *TalkSearchService) findVisibleRecords(ctx context.Context, uid int, docs []*search.Document) (map[string]*model.TalkMessageRecord, error) {
+	var (
+		privateIds = make([]string, 0)
+		groupIds   = make([]string, 0)
+		items      = make(map[string]*model.TalkMessageRecord)
+	)
+
+	for _, doc := range docs {
+		if doc.TalkMode == entity.ChatGroupMode {
+			groupIds = append(groupIds, doc.Id)
+		} else {
+			privateIds = append(privateIds, doc.Id)
+		}
+	}
+
+	fields := "msg_id,sequence,msg_type,is_revoked,extra,quote,send_time,from_id"
+
+	find := func(talkMode int, msgIds []string, scan func(tx *gorm.DB) *gorm.DB) ([]*searchVisibleRecord, error) {
+		list := make([]*searchVisibleRecord, 0, len(msgIds))
+		err := s.TalkRecordService.TalkMessageArchive.Scan(ctx, talkMode, func(tx *gorm.DB) (bool, error) {
+			var rows []*searchVisibleRecord
+			if err := scan(tx).Scan(&rows).Error; err != nil {
+				return false, err
+			}
+
+			list = append(list, rows...)
+			return len(list) >= len(msgIds), nil
+		})
+
+		return list, err
+	}
+
+	if len(privateIds) > 0 {
+		list, err := find(entity.ChatPrivateMode, privateIds, func(tx *gorm.DB) *gorm.DB {
+			return tx.Select(fields+",to_from_id,is_deleted").Where("msg_id in ? and user_id = ?", privateIds, uid)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range list {
+			if item.IsRevoked == model.Yes || item.IsDeleted == model.Yes {
+				continue
+			}
+
+			item.TalkMode = entity.ChatPrivateMode
+			items[item.MsgId] = &item.TalkMessageRecord
+		}
+	}
+
+	// 仅对当前用户删除的群消息
+	deleted := make([]string, 0)
+
+	if len(groupIds) > 0 {
+		err := s.Source.Db().WithContext(ctx).Table("talk_group_message_del").
+			Where("user_id = ? and msg_id in ?", uid, groupIds).
+			Pluck("msg_id", &deleted).Error
+		if err != nil {
+			return nil, err
+		}
+
+		list, err := find(entity.ChatGroupMode, groupIds, func(tx *gorm.DB) *gorm.DB {
+			return tx.Select(fields+",group_id as to_from_id").Where("msg_id in ?", groupIds)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range list {
+			if item.IsRevoked == model.Yes || slices.Contains(deleted, item.MsgId) {
+				continue
+			}
+
+			item.TalkMode = entity.ChatGroupMode
+			items[item.MsgId] = &item.TalkMessageRecord
+		}
+	}
+
+	// 撤回、私聊删除及已清理的消息不可恢复，从索引中移除
+	removed := make([]string, 0)
+	for _, doc := range docs {
+		if _, ok := items[doc.Id]; !ok && !slices.Contains(deleted, doc.Id) {
+			removed = append(removed, doc.Id)
+		}
+	}
+
+	if len(removed) > 0 {
+		_ = s.Engine.Delete(ctx, removed...)
+	}
+
+	return items, nil
+}
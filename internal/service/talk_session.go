@@ -31,6 +31,7 @@ type ITalkSessionService interface {
 type TalkSessionService struct {
 	*repo.Source
 	TalkSessionRepo *repo.TalkSession
+	TalkSyncService ITalkSyncService
 }
 
 func (s *TalkSessionService) List(ctx context.Context, uid int) ([]*model.SearchTalkSession, error) {
@@ -125,7 +126,12 @@ func (s *TalkSessionService) Top(ctx context.Context, opt *TalkSessionTopOpt) (i
 		"is_top":     isTop,
 		"updated_at": time.Now(),
 	}, "user_id = ? and talk_mode = ? and to_from_id = ?", opt.UserId, opt.TalkMode, opt.ToFromId)
-	return isTop, err
+	if err != nil {
+		return isTop, err
+	}
+
+	s.TalkSyncService.RecordUserEvent(ctx, opt.UserId, model.TalkSyncEventTop, opt.TalkMode, opt.ToFromId, &TalkSyncPayload{Value: isTop})
+	return isTop, nil
 }
 
 type TalkSessionDisturbOpt struct {
@@ -142,7 +148,12 @@ func (s *TalkSessionService) Disturb(ctx context.Context, opt *TalkSessionDistur
 		"is_disturb": isDisturb,
 		"updated_at": time.Now(),
 	}, "user_id = ? and talk_mode = ? and to_from_id = ?", opt.UserId, opt.TalkMode, opt.ToFromId)
-	return isDisturb, err
+	if err != nil {
+		return isDisturb, err
+	}
+
+	s.TalkSyncService.RecordUserEvent(ctx, opt.UserId, model.TalkSyncEventDisturb, opt.TalkMode, opt.ToFromId, &TalkSyncPayload{Value: isDisturb})
+	return isDisturb, nil
 }
 
 // SessionDetail 会话详情
@@ -0,0 +1,403 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gzydong/go-chat/internal/entity"
+	"github.com/gzydong/go-chat/internal/pkg/jsonutil"
+	"github.com/gzydong/go-chat/internal/pkg/logger"
+	"github.com/gzydong/go-chat/internal/repository/model"
+	"github.com/gzydong/go-chat/internal/repository/repo"
+)
+
+// talkSyncSafetyLag 同步时忽略最近写入的数据，避免并发事务提交顺序与ID顺序不一致导致漏同步
+const talkSyncSafetyLag = 2 * time.Second
+
+const TalkSyncEventMessage = "message" // 新消息
+
+var _ ITalkSyncService = (*TalkSyncService)(nil)
+
+// TalkSyncPayload 变更内容
+type TalkSyncPayload struct {
+	MsgId    string   `json:"msg_id,omitempty"`   // 撤回的消息ID
+	MsgIds   []string `json:"msg_ids,omitempty"`  // 删除的消息ID
+	Value    int      `json:"value,omitempty"`    // 置顶/免打扰状态
+	Sequence int64    `json:"sequence,omitempty"` // 已读位置(会话内最后一条消息的时序ID)
+}
+
+type TalkSyncOpt struct {
+	UserId     int
+	Checkpoint string
+	Limit      int
+}
+
+type TalkSyncItem struct {
+	Event    string
+	TalkMode int
+	ToFromId int
+	Payload  *TalkSyncPayload
+	Message  *model.TalkMessageRecord // 新消息
+}
+
+type TalkSyncResult struct {
+	Items      []*TalkSyncItem
+	Checkpoint string
+	HasMore    bool
+}
+
+type ITalkSyncService interface {
+	// RecordUserEvent 记录用户级变更
+	RecordUserEvent(ctx context.Context, uid int, event string, talkMode int, toFromId int, payload *TalkSyncPayload)
+	// RecordGroupEvent 记录群级变更
+	RecordGroupEvent(ctx context.Context, groupId int, event string, payload *TalkSyncPayload)
+	// RecordRead 记录会话已读位置
+	RecordRead(ctx context.Context, uid int, talkMode int, toFromId int)
+	// Sync 获取同步位置之后的全部变更
+	Sync(ctx context.Context, opt *TalkSyncOpt) (*TalkSyncResult, error)
+}
+
+type TalkSyncService struct {
+	*repo.Source
+	TalkSyncEventRepo *repo.TalkSyncEvent
+	GroupMemberRepo   *repo.GroupMember
+	Sequence          *repo.Sequence
+	TalkRecordService *TalkRecordService
+}
+
+// talkSyncCursor 同步位置
+// Seq 为用户时序ID(私聊消息及用户级变更)，GroupMsgId 为群消息ID，GroupEventId 为群级变更ID
+type talkSyncCursor struct {
+	Seq          int64
+	GroupMsgId   int64
+	GroupEventId int64
+}
+
+func (c talkSyncCursor) String() string {
+	return fmt.Sprintf("%d.%d.%d", c.Seq, c.GroupMsgId, c.GroupEventId)
+}
+
+func parseTalkSyncCursor(value string) (talkSyncCursor, error) {
+	parts := strings.Split(value, ".")
+	if len(parts) != 3 {
+		return talkSyncCursor{}, errors.New("同步位置格式错误")
+	}
+
+	values := make([]int64, 0, 3)
+	for _, part := range parts {
+		v, err := strconv.ParseInt(part, 10, 64)
+		if err != nil || v < 0 {
+			return talkSyncCursor{}, errors.New("同步位置格式错误")
+		}
+
+		values = append(values, v)
+	}
+
+	return talkSyncCursor{Seq: values[0], GroupMsgId: values[1], GroupEventId: values[2]}, nil
+}
+
+func (t *TalkSyncService) RecordUserEvent(ctx context.Context, uid int, event string, talkMode int, toFromId int, payload *TalkSyncPayload) {
+	err := t.TalkSyncEventRepo.Create(ctx, &model.TalkSyncEvent{
+		UserId:    uid,
+		Sequence:  t.Sequence.Get(ctx, repo.SequenceTypeUser, int32(uid)),
+		Event:     event,
+		TalkMode:  talkMode,
+		ToFromId:  toFromId,
+		Payload:   jsonutil.Encode(payload),
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		logger.Errorf("record sync event %s err: %s", event, err.Error())
+	}
+}
+
+func (t *TalkSyncService) RecordGroupEvent(ctx context.Context, groupId int, event string, payload *TalkSyncPayload) {
+	err := t.TalkSyncEventRepo.Create(ctx, &model.TalkSyncEvent{
+		GroupId:   groupId,
+		Event:     event,
+		TalkMode:  entity.ChatGroupMode,
+		ToFromId:  groupId,
+		Payload:   jsonutil.Encode(payload),
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		logger.Errorf("record sync event %s err: %s", event, err.Error())
+	}
+}
+
+// RecordRead 记录会话已读位置，已读位置为会话内最后一条消息的时序ID
+func (t *TalkSyncService) RecordRead(ctx context.Context, uid int, talkMode int, toFromId int) {
+	db := t.Source.Db().WithContext(ctx)
+
+	var sequence int64
+	if talkMode == entity.ChatGroupMode {
+		db = db.Model(&model.TalkGroupMessage{}).Where("group_id = ?", toFromId)
+	} else {
+		db = db.Model(&model.TalkUserMessage{}).Where("user_id = ? and to_from_id = ?", uid, toFromId)
+	}
+
+	if err := db.Select("ifnull(max(sequence), 0)").Scan(&sequence).Error; err != nil {
+		logger.Errorf("record sync read err: %s", err.Error())
+		return
+	}
+
+	t.RecordUserEvent(ctx, uid, model.TalkSyncEventRead, talkMode, toFromId, &TalkSyncPayload{Sequence: sequence})
+}
+
+// Sync 获取同步位置之后的全部变更
+// 未传同步位置时返回当前最新位置，客户端应通过会话列表及聊天记录接口加载历史数据
+// 私聊数据、群消息、群级变更各自最多返回 Limit 条
+func (t *TalkSyncService) Sync(ctx context.Context, opt *TalkSyncOpt) (*TalkSyncResult, error) {
+	if opt.Checkpoint == "" {
+		cursor, err := t.head(ctx, opt.UserId)
+		if err != nil {
+			return nil, err
+		}
+
+		return &TalkSyncResult{Items: make([]*TalkSyncItem, 0), Checkpoint: cursor.String()}, nil
+	}
+
+	cursor, err := parseTalkSyncCursor(opt.Checkpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		deadline = time.Now().Add(-talkSyncSafetyLag)
+		groupIds = t.GroupMemberRepo.GetUserGroupIds(ctx, opt.UserId)
+		result   = &TalkSyncResult{Items: make([]*TalkSyncItem, 0)}
+		records  = make([]*model.TalkMessageRecord, 0)
+	)
+
+	privateItems, privateRecords, hasMore, err := t.syncPrivate(ctx, opt, deadline, &cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Items = append(result.Items, privateItems...)
+	result.HasMore = result.HasMore || hasMore
+	records = append(records, privateRecords...)
+
+	if len(groupIds) > 0 {
+		groupItems, groupRecords, hasMore, err := t.syncGroupMessages(ctx, opt, groupIds, deadline, &cursor)
+		if err != nil {
+			return nil, err
+		}
+
+		result.Items = append(result.Items, groupItems...)
+		result.HasMore = result.HasMore || hasMore
+		records = append(records, groupRecords...)
+
+		eventItems, hasMore, err := t.syncGroupEvents(ctx, opt, groupIds, deadline, &cursor)
+		if err != nil {
+			return nil, err
+		}
+
+		result.Items = append(result.Items, eventItems...)
+		result.HasMore = result.HasMore || hasMore
+	}
+
+	if _, err := t.TalkRecordService.handleTalkRecords(ctx, records); err != nil {
+		return nil, err
+	}
+
+	result.Checkpoint = cursor.String()
+	return result, nil
+}
+
+// head 获取当前最新的同步位置
+func (t *TalkSyncService) head(ctx context.Context, uid int) (talkSyncCursor, error) {
+	db := t.Source.Db().WithContext(ctx)
+
+	var cursor talkSyncCursor
+	var eventSeq int64
+
+	err := db.Model(&model.TalkUserMessage{}).Where("user_id = ?", uid).Select("ifnull(max(sequence), 0)").Scan(&cursor.Seq).Error
+	if err != nil {
+		return cursor, err
+	}
+
+	err = db.Model(&model.TalkSyncEvent{}).Where("user_id = ?", uid).Select("ifnull(max(sequence), 0)").Scan(&eventSeq).Error
+	if err != nil {
+		return cursor, err
+	}
+
+	cursor.Seq = max(cursor.Seq, eventSeq)
+
+	if err := db.Model(&model.TalkGroupMessage{}).Select("ifnull(max(id), 0)").Scan(&cursor.GroupMsgId).Error; err != nil {
+		return cursor, err
+	}
+
+	err = db.Model(&model.TalkSyncEvent{}).Where("user_id = 0").Select("ifnull(max(id), 0)").Scan(&cursor.GroupEventId).Error
+	return cursor, err
+}
+
+// syncPrivate 同步私聊消息及用户级变更，二者共用用户时序ID，按时序ID合并
+func (t *TalkSyncService) syncPrivate(ctx context.Context, opt *TalkSyncOpt, deadline time.Time, cursor *talkSyncCursor) ([]*TalkSyncItem, []*model.TalkMessageRecord, bool, error) {
+	db := t.Source.Db().WithContext(ctx)
+
+	var messages []*model.TalkUserMessage
+	err := db.Where("user_id = ? and sequence > ? and created_at <= ?", opt.UserId, cursor.Seq, deadline).
+		Order("sequence asc").Limit(opt.Limit + 1).Find(&messages).Error
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	var events []*model.TalkSyncEvent
+	err = db.Where("user_id = ? and sequence > ? and created_at <= ?", opt.UserId, cursor.Seq, deadline).
+		Order("sequence asc").Limit(opt.Limit + 1).Find(&events).Error
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	type entry struct {
+		sequence int64
+		message  *model.TalkUserMessage
+		event    *model.TalkSyncEvent
+	}
+
+	entries := make([]entry, 0, len(messages)+len(events))
+	for _, message := range messages {
+		entries = append(entries, entry{sequence: message.Sequence, message: message})
+	}
+
+	for _, event := range events {
+		entries = append(entries, entry{sequence: event.Sequence, event: event})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].sequence < entries[j].sequence
+	})
+
+	hasMore := len(entries) > opt.Limit
+	if hasMore {
+		entries = entries[:opt.Limit]
+	}
+
+	items := make([]*TalkSyncItem, 0, len(entries))
+	records := make([]*model.TalkMessageRecord, 0)
+	for _, value := range entries {
+		cursor.Seq = value.sequence
+
+		if value.event != nil {
+			items = append(items, toTalkSyncItem(value.event))
+			continue
+		}
+
+		// 已删除的消息无需同步，删除变更会单独同步
+		if value.message.IsDeleted == model.Yes {
+			continue
+		}
+
+		record := &model.TalkMessageRecord{
+			TalkMode:  entity.ChatPrivateMode,
+			FromId:    value.message.FromId,
+			ToFromId:  value.message.ToFromId,
+			MsgId:     value.message.MsgId,
+			Sequence:  int(value.message.Sequence),
+			MsgType:   value.message.MsgType,
+			IsRevoked: value.message.IsRevoked,
+			SendTime:  value.message.SendTime,
+			Extra:     value.message.Extra,
+			Quote:     value.message.Quote,
+		}
+
+		records = append(records, record)
+		items = append(items, &TalkSyncItem{
+			Event:    TalkSyncEventMessage,
+			TalkMode: entity.ChatPrivateMode,
+			ToFromId: record.ToFromId,
+			Message:  record,
+		})
+	}
+
+	return items, records, hasMore, nil
+}
+
+// syncGroupMessages 同步所在群的新消息，话题回复及已删除的消息不同步
+func (t *TalkSyncService) syncGroupMessages(ctx context.Context, opt *TalkSyncOpt, groupIds []int, deadline time.Time, cursor *talkSyncCursor) ([]*TalkSyncItem, []*model.TalkMessageRecord, bool, error) {
+	db := t.Source.Db().WithContext(ctx)
+
+	var messages []*model.TalkGroupMessage
+	err := db.Where("group_id in ? and id > ? and root_msg_id = '' and created_at <= ?", groupIds, cursor.GroupMsgId, deadline).
+		Where("msg_id not in (?)", db.Model(&model.TalkGroupMessageDel{}).Select("msg_id").Where("user_id = ?", opt.UserId)).
+		Order("id asc").Limit(opt.Limit + 1).Find(&messages).Error
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	hasMore := len(messages) > opt.Limit
+	if hasMore {
+		messages = messages[:opt.Limit]
+	}
+
+	items := make([]*TalkSyncItem, 0, len(messages))
+	records := make([]*model.TalkMessageRecord, 0, len(messages))
+	for _, message := range messages {
+		cursor.GroupMsgId = message.Id
+
+		record := &model.TalkMessageRecord{
+			TalkMode:  entity.ChatGroupMode,
+			FromId:    message.FromId,
+			ToFromId:  message.GroupId,
+			MsgId:     message.MsgId,
+			Sequence:  int(message.Sequence),
+			MsgType:   message.MsgType,
+			IsRevoked: message.IsRevoked,
+			SendTime:  message.SendTime,
+			Extra:     message.Extra,
+			Quote:     message.Quote,
+		}
+
+		records = append(records, record)
+		items = append(items, &TalkSyncItem{
+			Event:    TalkSyncEventMessage,
+			TalkMode: entity.ChatGroupMode,
+			ToFromId: message.GroupId,
+			Message:  record,
+		})
+	}
+
+	return items, records, hasMore, nil
+}
+
+// syncGroupEvents 同步所在群的群级变更
+func (t *TalkSyncService) syncGroupEvents(ctx context.Context, opt *TalkSyncOpt, groupIds []int, deadline time.Time, cursor *talkSyncCursor) ([]*TalkSyncItem, bool, error) {
+	var events []*model.TalkSyncEvent
+	err := t.Source.Db().WithContext(ctx).
+		Where("user_id = 0 and group_id in ? and id > ? and created_at <= ?", groupIds, cursor.GroupEventId, deadline).
+		Order("id asc").Limit(opt.Limit + 1).Find(&events).Error
+	if err != nil {
+		return nil, false, err
+	}
+
+	hasMore := len(events) > opt.Limit
+	if hasMore {
+		events = events[:opt.Limit]
+	}
+
+	items := make([]*TalkSyncItem, 0, len(events))
+	for _, event := range events {
+		cursor.GroupEventId = event.Id
+		items = append(items, toTalkSyncItem(event))
+	}
+
+	return items, hasMore, nil
+}
+
+func toTalkSyncItem(event *model.TalkSyncEvent) *TalkSyncItem {
+	payload := &TalkSyncPayload{}
+	_ = jsonutil.Unmarshal(event.Payload, payload)
+
+	return &TalkSyncItem{
+		Event:    event.Event,
+		TalkMode: event.TalkMode,
+		ToFromId: event.ToFromId,
+		Payload:  payload,
+	}
+}
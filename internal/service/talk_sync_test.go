@@ -0,0 +1,39 @@
+package service
+
+import "testing"
+
+func TestParseTalkSyncCursor(t *testing.T) {
+	cases := []struct {
+		name   string
+		value  string
+		expect talkSyncCursor
+		err    bool
+	}{
+		{name: "zero", value: "0.0.0", expect: talkSyncCursor{}},
+		{name: "normal", value: "12.345.6", expect: talkSyncCursor{Seq: 12, GroupMsgId: 345, GroupEventId: 6}},
+		{name: "missing part", value: "12.345", err: true},
+		{name: "not number", value: "12.a.6", err: true},
+		{name: "negative", value: "-1.0.0", err: true},
+		{name: "empty", value: "", err: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := parseTalkSyncCursor(c.value)
+			if c.err {
+				if err == nil {
+					t.Errorf("parseTalkSyncCursor(%q) expect error", c.value)
+				}
+				return
+			}
+
+			if err != nil || got != c.expect {
+				t.Errorf("parseTalkSyncCursor(%q) = %+v, %v, want %+v", c.value, got, err, c.expect)
+			}
+
+			if got.String() != c.value {
+				t.Errorf("String() = %q, want %q", got.String(), c.value)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gzydong/go-chat/internal/repository/model"
+	"github.com/gzydong/go-chat/internal/repository/repo"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var _ IUserBlockService = (*UserBlockService)(nil)
+
+type IUserBlockService interface {
+	// Block 将用户加入黑名单
+	Block(ctx context.Context, uid int, blockUserId int) error
+	// Unblock 将用户移出黑名单
+	Unblock(ctx context.Context, uid int, blockUserId int) error
+	// List 黑名单列表，按拉黑时间倒序
+	List(ctx context.Context, uid int) ([]*model.UserBlockItem, error)
+}
+
+type UserBlockService struct {
+	*repo.Source
+	UsersRepo     *repo.Users
+	UserBlockRepo *repo.UserBlock
+}
+
+func (s *UserBlockService) Block(ctx context.Context, uid int, blockUserId int) error {
+	if uid == blockUserId {
+		return errors.New("不能将自己加入黑名单")
+	}
+
+	if _, err := s.UsersRepo.FindById(ctx, blockUserId); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("用户不存在")
+		}
+
+		return err
+	}
+
+	err := s.Source.Db().WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&model.UserBlock{
+		UserId:      uid,
+		BlockUserId: blockUserId,
+		CreatedAt:   time.Now(),
+	}).Error
+	if err != nil {
+		return err
+	}
+
+	s.UserBlockRepo.ClearCache(ctx, uid)
+	return nil
+}
+
+func (s *UserBlockService) Unblock(ctx context.Context, uid int, blockUserId int) error {
+	err := s.Source.Db().WithContext(ctx).Delete(&model.UserBlock{}, "user_id = ? and block_user_id = ?", uid, blockUserId).Error
+	if err != nil {
+		return err
+	}
+
+	s.UserBlockRepo.ClearCache(ctx, uid)
+	return nil
+}
+
+func (s *UserBlockService) List(ctx context.Context, uid int) ([]*model.UserBlockItem, error) {
+	fields := []string{
+		"user_block.block_user_id as user_id",
+		"users.nickname",
+		"users.avatar",
+		"user_block.created_at",
+	}
+
+	tx := s.Source.Db().WithContext(ctx).Table("user_block")
+	tx.Joins("left join `users` ON `users`.id = user_block.block_user_id")
+	tx.Where("user_block.user_id = ?", uid)
+	tx.Order("user_block.id desc")
+
+	var items []*model.UserBlockItem
+	if err := tx.Select(fields).Scan(&items).Error; err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
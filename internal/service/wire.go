@@ -27,9 +27,23 @@ var ProviderSet = wire.NewSet(
 	wire.Struct(new(GroupMemberService), "*"),
 	wire.Bind(new(IGroupMemberService), new(*GroupMemberService)),
 
+	wire.Struct(new(GroupMuteService), "*"),
+	wire.Bind(new(IGroupMuteService), new(*GroupMuteService)),
+	wire.Struct(new(GroupAntiSpamService), "*"),
+	wire.Bind(new(IGroupAntiSpamService), new(*GroupAntiSpamService)),
+
+	wire.Struct(new(GroupPermissionService), "*"),
+	wire.Bind(new(IGroupPermissionService), new(*GroupPermissionService)),
+
 	wire.Struct(new(GroupApplyService), "*"),
 	wire.Bind(new(IGroupApplyService), new(*GroupApplyService)),
 
+	wire.Struct(new(GroupJoinService), "*"),
+	wire.Bind(new(IGroupJoinService), new(*GroupJoinService)),
+
+	wire.Struct(new(GroupNoticeService), "*"),
+	wire.Bind(new(IGroupNoticeService), new(*GroupNoticeService)),
+
 	wire.Struct(new(GroupVoteService), "*"),
 	wire.Bind(new(IGroupVoteService), new(*GroupVoteService)),
 
@@ -42,12 +56,54 @@ var ProviderSet = wire.NewSet(
 	wire.Struct(new(TalkRecordService), "*"),
 	wire.Bind(new(ITalkRecordService), new(*TalkRecordService)),
 
+	wire.Struct(new(TalkSearchService), "*"),
+	wire.Bind(new(ITalkSearchService), new(*TalkSearchService)),
+
+	wire.Struct(new(TalkMessagePinService), "*"),
+	wire.Bind(new(ITalkMessagePinService), new(*TalkMessagePinService)),
+
+	wire.Struct(new(TalkScheduleService), "*"),
+	wire.Bind(new(ITalkScheduleService), new(*TalkScheduleService)),
+
+	wire.Struct(new(TalkMessageExpireService), "*"),
+	wire.Bind(new(ITalkMessageExpireService), new(*TalkMessageExpireService)),
+
+	wire.Struct(new(TalkSyncService), "*"),
+	wire.Bind(new(ITalkSyncService), new(*TalkSyncService)),
+
+	wire.Struct(new(NoopModerationClassifier)),
+	wire.Bind(new(IModerationClassifier), new(*NoopModerationClassifier)),
+
+	wire.Struct(new(ModerationService), "*"),
+	wire.Bind(new(IModerationService), new(*ModerationService)),
+
+	wire.Struct(new(ReportService), "*"),
+	wire.Bind(new(IReportService), new(*ReportService)),
+
+	wire.Struct(new(TalkCardService), "*"),
+	wire.Bind(new(ITalkCardService), new(*TalkCardService)),
+
+	wire.Struct(new(BroadcastService), "*"),
+	wire.Bind(new(IBroadcastService), new(*BroadcastService)),
+
+	wire.Struct(new(TalkExportService), "*"),
+	wire.Bind(new(ITalkExportService), new(*TalkExportService)),
+
+	wire.Struct(new(TalkImportService), "*"),
+	wire.Bind(new(ITalkImportService), new(*TalkImportService)),
+
+	wire.Struct(new(TalkFavoriteService), "*"),
+	wire.Bind(new(ITalkFavoriteService), new(*TalkFavoriteService)),
+
 	wire.Struct(new(ContactService), "*"),
 	wire.Bind(new(IContactService), new(*ContactService)),
 
 	wire.Struct(new(ContactApplyService), "*"),
 	wire.Bind(new(IContactApplyService), new(*ContactApplyService)),
 
+	wire.Struct(new(UserBlockService), "*"),
+	wire.Bind(new(IUserBlockService), new(*UserBlockService)),
+
 	wire.Struct(new(ContactGroupService), "*"),
 	wire.Bind(new(IContactGroupService), new(*ContactGroupService)),
 